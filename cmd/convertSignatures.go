@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperConvertSignatures *viper.Viper
+
+// convertSignaturesCmd represents the convertSignatures command that turns the rules of other scanners into signatures
+var convertSignaturesCmd = &cobra.Command{
+	Use:   "convertSignatures",
+	Short: "Convert gitleaks and trufflehog rules into a signature file",
+	Long: "Convert the rules of a gitleaks config or trufflehog rule file into a signature file, reporting the rules " +
+		"that could not be converted and what was left out of those that were",
+	Run: func(cmd *cobra.Command, args []string) {
+		rulesFile := viperConvertSignatures.GetString("rules-file")
+		if rulesFile == "" {
+			fmt.Fprintf(os.Stderr, "Give the --rules-file to convert\n")
+			os.Exit(2)
+		}
+		format := viperConvertSignatures.GetString("rules-format")
+		if format == "" {
+			format = core.RuleFormat(rulesFile)
+		}
+
+		data, err := ioutil.ReadFile(core.SetHomeDir(rulesFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read the rules: %s\n", err)
+			os.Exit(2)
+		}
+		conversion, err := core.ConvertRules(data, format, viperConvertSignatures.GetInt("match-level"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to convert %s: %s\n", rulesFile, err)
+			os.Exit(2)
+		}
+		signatures, err := conversion.YAML(rulesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to write the signatures: %s\n", err)
+			os.Exit(2)
+		}
+
+		// without an output file the signatures are written to stdout, so the report goes to stderr either way
+		output := viperConvertSignatures.GetString("output")
+		if output == "" {
+			os.Stdout.Write(signatures)
+		} else if err := ioutil.WriteFile(core.SetHomeDir(output), signatures, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to write the signatures to %s: %s\n", output, err)
+			os.Exit(2)
+		}
+
+		for _, note := range conversion.Notes {
+			fmt.Fprintf(os.Stderr, "Converted %s\n", note)
+		}
+		for _, skipped := range conversion.Skipped {
+			fmt.Fprintf(os.Stderr, "Skipped %s\n", skipped)
+		}
+		fmt.Fprintf(os.Stderr, "Converted %d of %d %s rules into %d signatures\n", conversion.Rules-len(conversion.Skipped),
+			conversion.Rules, format, len(conversion.Signatures))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertSignaturesCmd)
+
+	viperConvertSignatures = core.SetConfig()
+
+	convertSignaturesCmd.Flags().String("rules-file", "", "The gitleaks config or trufflehog rule file to convert")
+	convertSignaturesCmd.Flags().String("rules-format", "", "The format of the rules, gitleaks or trufflehog, guessed from the extension of the file when not given")
+	convertSignaturesCmd.Flags().String("output", "", "The signature file to write, stdout when not given")
+	convertSignaturesCmd.Flags().Int("match-level", 3, "The match level of the signatures, which their severity and confidence are rated from")
+
+	err := viperConvertSignatures.BindPFlag("rules-file", convertSignaturesCmd.Flags().Lookup("rules-file"))
+	err = viperConvertSignatures.BindPFlag("rules-format", convertSignaturesCmd.Flags().Lookup("rules-format"))
+	err = viperConvertSignatures.BindPFlag("output", convertSignaturesCmd.Flags().Lookup("output"))
+	err = viperConvertSignatures.BindPFlag("match-level", convertSignaturesCmd.Flags().Lookup("match-level"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
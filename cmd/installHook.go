@@ -0,0 +1,57 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+)
+
+// installHookCmd represents the installHook command that sets up scanStaged as the pre-commit hook of a repository
+var installHookCmd = &cobra.Command{
+	Use:   "installHook [-- scanStaged flags]",
+	Short: "Install a git pre-commit hook that runs scanStaged in the current repository.",
+	Long: `Install a git pre-commit hook that runs scanStaged in the current repository, so a commit that adds a secret
+is stopped before it is made. Any arguments after -- are passed on to scanStaged.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Printf("Unable to find the wraith executable: %s\n", err)
+			os.Exit(1)
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		command := []string{shellQuote(exe), scanStagedCmd.Name()}
+		for _, arg := range args {
+			command = append(command, shellQuote(arg))
+		}
+
+		path, err := core.InstallPreCommitHook(dir, strings.Join(command, " "), force)
+		if err != nil {
+			fmt.Printf("Unable to install the pre-commit hook: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Installed the pre-commit hook at %s\n", path)
+	},
+}
+
+// shellQuote will quote an argument for the pre-commit hook so it reaches wraith as it was given
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(installHookCmd)
+
+	installHookCmd.Flags().Bool("force", false, "Replace a pre-commit hook that was not installed by wraith")
+}
@@ -0,0 +1,142 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperOperator *viper.Viper
+var viperOperatorRun *viper.Viper
+
+// operatorCmd represents the operator command that runs wraith as a kubernetes controller
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Run scans declared as ScanJob resources in kubernetes",
+	Long:  "Run scans declared as ScanJob resources in kubernetes - v" + version.AppVersion(),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := &core.Logger{}
+		out.SetDebug(viperOperator.GetBool("debug"))
+		if err := out.SetOutput(viperOperator.GetString("log-format"), core.SetHomeDir(viperOperator.GetString("log-file"))); err != nil {
+			out.Fatal("Unable to log to %s: %s\n", viperOperator.GetString("log-file"), err)
+		}
+		if address := viperOperator.GetString("debug-server"); address != "" {
+			core.StartDebugServer(address, viperOperator.GetBool("debug-server-public"), out)
+		}
+		if viperOperator.GetBool("debug") {
+			core.LogRuntimeStats(out)
+		}
+
+		client, err := core.NewKubeClient(viperOperator.GetString("kube-api"))
+		if err != nil {
+			out.Fatal("Unable to connect to kubernetes: %s\n", err)
+		}
+
+		operator := &core.ScanJobOperator{
+			Client:         client,
+			Image:          viperOperator.GetString("image"),
+			Namespace:      viperOperator.GetString("namespace"),
+			Resync:         time.Duration(viperOperator.GetInt("resync")) * time.Second,
+			ServiceAccount: viperOperator.GetString("service-account"),
+			Out:            out,
+			AllowedImages:  viperOperator.GetStringSlice("allowed-images"),
+			AllowExec:      viperOperator.GetBool("allow-exec"),
+		}
+
+		stop := make(chan struct{})
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sig
+			close(stop)
+		}()
+
+		namespace := operator.Namespace
+		if namespace == "" {
+			namespace = "all namespaces"
+		}
+		out.Important("Watching ScanJobs in %s\n", namespace)
+		operator.Run(stop)
+	},
+}
+
+// operatorRunCmd represents the command that runs a single ScanJob inside of the Job created by the operator
+var operatorRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a single ScanJob and write the result to its status",
+	Long:  "Run a single ScanJob and write the result to its status. This is used by the Jobs the operator creates.",
+	Run: func(cmd *cobra.Command, args []string) {
+		out := &core.Logger{}
+
+		client, err := core.NewKubeClient(viperOperatorRun.GetString("kube-api"))
+		if err != nil {
+			out.Fatal("Unable to connect to kubernetes: %s\n", err)
+		}
+
+		namespace := viperOperatorRun.GetString("namespace")
+		if namespace == "" {
+			namespace = core.KubeNamespace()
+		}
+
+		if err := core.RunScanJob(client, namespace, viperOperatorRun.GetString("name"), viperOperatorRun.GetBool("allow-exec")); err != nil {
+			out.Fatal("ScanJob %s/%s failed: %s\n", namespace, viperOperatorRun.GetString("name"), err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(operatorCmd)
+	operatorCmd.AddCommand(operatorRunCmd)
+
+	viperOperator = core.SetConfig()
+	viperOperatorRun = core.SetConfig()
+
+	operatorCmd.Flags().Bool("allow-exec", false, "Run the onFindingExec and onRepoCompleteExec hook commands of ScanJobs")
+	operatorCmd.Flags().StringSlice("allowed-images", nil, "Images ScanJobs can set besides --image")
+	operatorCmd.Flags().Bool("debug", false, "Print debugging information")
+	operatorCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	operatorCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	operatorCmd.Flags().String("kube-api", "", "The kubernetes api url, such as the one served by kubectl proxy. Defaults to the in-cluster service account")
+	operatorCmd.Flags().String("namespace", "", "Only watch ScanJobs in this namespace, defaults to all namespaces")
+	operatorCmd.Flags().String("image", "n0moresecr3ts/wraith:"+version.AppVersion(), "The wraith image used for scan Jobs when the ScanJob does not set one")
+	operatorCmd.Flags().String("service-account", "wraith-scanner", "The service account scan Jobs run as, it must be able to update ScanJob status")
+	operatorCmd.Flags().Int("resync", 30, "Seconds between each pass over the ScanJobs")
+	operatorCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	operatorCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+
+	operatorRunCmd.Flags().Bool("allow-exec", false, "Run the hook commands of the ScanJob, set by the operator when it allows them")
+	operatorRunCmd.Flags().String("kube-api", "", "The kubernetes api url, defaults to the in-cluster service account")
+	operatorRunCmd.Flags().String("namespace", "", "The namespace of the ScanJob, defaults to the namespace of the pod")
+	operatorRunCmd.Flags().String("name", "", "The name of the ScanJob")
+
+	err := viperOperator.BindPFlag("allow-exec", operatorCmd.Flags().Lookup("allow-exec"))
+	err = viperOperator.BindPFlag("allowed-images", operatorCmd.Flags().Lookup("allowed-images"))
+	err = viperOperator.BindPFlag("debug", operatorCmd.Flags().Lookup("debug"))
+	err = viperOperator.BindPFlag("debug-server", operatorCmd.Flags().Lookup("debug-server"))
+	err = viperOperator.BindPFlag("debug-server-public", operatorCmd.Flags().Lookup("debug-server-public"))
+	err = viperOperator.BindPFlag("kube-api", operatorCmd.Flags().Lookup("kube-api"))
+	err = viperOperator.BindPFlag("namespace", operatorCmd.Flags().Lookup("namespace"))
+	err = viperOperator.BindPFlag("image", operatorCmd.Flags().Lookup("image"))
+	err = viperOperator.BindPFlag("service-account", operatorCmd.Flags().Lookup("service-account"))
+	err = viperOperator.BindPFlag("resync", operatorCmd.Flags().Lookup("resync"))
+	err = viperOperator.BindPFlag("log-format", operatorCmd.Flags().Lookup("log-format"))
+	err = viperOperator.BindPFlag("log-file", operatorCmd.Flags().Lookup("log-file"))
+
+	err = viperOperatorRun.BindPFlag("allow-exec", operatorRunCmd.Flags().Lookup("allow-exec"))
+	err = viperOperatorRun.BindPFlag("kube-api", operatorRunCmd.Flags().Lookup("kube-api"))
+	err = viperOperatorRun.BindPFlag("namespace", operatorRunCmd.Flags().Lookup("namespace"))
+	err = viperOperatorRun.BindPFlag("name", operatorRunCmd.Flags().Lookup("name"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,373 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanAzureDevops *viper.Viper
+
+// scanAzureDevopsCmd represents the scanAzureDevops command that will enumerate and scan azure devops services or server
+var scanAzureDevopsCmd = &cobra.Command{
+	Use:   "scanAzureDevops",
+	Short: "Scan one or more Azure DevOps organizations or projects for secrets.",
+	Long:  `Scan the git repositories of one or more Azure DevOps organizations or projects for secrets, on dev.azure.com or an Azure DevOps Server.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "azureDevops"
+		sess := core.NewSession(viperScanAzureDevops, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		core.GatherTargets(sess)
+		core.GatherRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanAzureDevopsCmd)
+
+	viperScanAzureDevops = core.SetConfig()
+
+	scanAzureDevopsCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanAzureDevopsCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanAzureDevopsCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanAzureDevopsCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanAzureDevopsCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanAzureDevopsCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
+	scanAzureDevopsCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanAzureDevopsCmd.Flags().Bool("silent", false, "No output")
+	scanAzureDevopsCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanAzureDevopsCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanAzureDevopsCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanAzureDevopsCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanAzureDevopsCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanAzureDevopsCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanAzureDevopsCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanAzureDevopsCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanAzureDevopsCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanAzureDevopsCmd.Flags().String("azure-devops-targets", "", "A space separated list of Azure DevOps organizations, or organization/project, to scan")
+	scanAzureDevopsCmd.Flags().String("azure-devops-token", "", "A personal access token with the Code (Read) scope")
+	scanAzureDevopsCmd.Flags().String("azure-devops-url", "https://dev.azure.com", "The url of Azure DevOps, or of the collections of an Azure DevOps Server such as https://tfs.example.com/tfs")
+	scanAzureDevopsCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanAzureDevopsCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanAzureDevopsCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanAzureDevopsCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanAzureDevopsCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanAzureDevopsCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanAzureDevopsCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanAzureDevopsCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanAzureDevopsCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanAzureDevopsCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanAzureDevopsCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanAzureDevopsCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanAzureDevopsCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanAzureDevopsCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanAzureDevopsCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanAzureDevopsCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanAzureDevopsCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanAzureDevopsCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanAzureDevopsCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanAzureDevopsCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanAzureDevopsCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanAzureDevopsCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanAzureDevopsCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanAzureDevopsCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanAzureDevopsCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanAzureDevopsCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanAzureDevopsCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanAzureDevopsCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanAzureDevopsCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanAzureDevopsCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanAzureDevopsCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanAzureDevopsCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanAzureDevopsCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanAzureDevopsCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanAzureDevopsCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanAzureDevopsCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanAzureDevopsCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanAzureDevopsCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanAzureDevopsCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanAzureDevopsCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanAzureDevopsCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanAzureDevopsCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanAzureDevopsCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanAzureDevopsCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanAzureDevopsCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanAzureDevopsCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanAzureDevopsCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanAzureDevopsCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanAzureDevopsCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanAzureDevopsCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanAzureDevopsCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanAzureDevopsCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanAzureDevopsCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanAzureDevopsCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanAzureDevopsCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanAzureDevopsCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanAzureDevopsCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanAzureDevopsCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanAzureDevopsCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanAzureDevopsCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanAzureDevopsCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanAzureDevopsCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanAzureDevopsCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanAzureDevopsCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanAzureDevopsCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanAzureDevopsCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanAzureDevopsCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanAzureDevopsCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanAzureDevopsCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanAzureDevopsCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanAzureDevopsCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanAzureDevopsCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanAzureDevopsCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanAzureDevopsCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanAzureDevopsCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanAzureDevopsCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanAzureDevopsCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanAzureDevopsCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanAzureDevopsCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanAzureDevopsCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanAzureDevopsCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanAzureDevopsCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanAzureDevopsCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanAzureDevopsCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanAzureDevopsCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanAzureDevopsCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanAzureDevopsCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanAzureDevopsCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanAzureDevopsCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanAzureDevopsCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanAzureDevopsCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanAzureDevopsCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanAzureDevopsCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanAzureDevopsCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanAzureDevopsCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanAzureDevopsCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanAzureDevopsCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanAzureDevopsCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanAzureDevopsCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanAzureDevopsCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanAzureDevopsCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanAzureDevopsCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanAzureDevopsCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanAzureDevopsCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanAzureDevopsCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanAzureDevopsCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanAzureDevopsCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanAzureDevopsCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanAzureDevopsCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanAzureDevopsCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanAzureDevopsCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanAzureDevopsCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanAzureDevopsCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanAzureDevopsCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanAzureDevopsCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanAzureDevopsCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanAzureDevopsCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanAzureDevopsCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanAzureDevopsCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanAzureDevopsCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanAzureDevopsCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanAzureDevopsCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanAzureDevopsCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanAzureDevopsCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanAzureDevopsCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanAzureDevopsCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanAzureDevopsCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanAzureDevopsCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanAzureDevops.BindPFlag("bind-address", scanAzureDevopsCmd.Flags().Lookup("bind-address"))
+	err = viperScanAzureDevops.BindPFlag("bind-port", scanAzureDevopsCmd.Flags().Lookup("bind-port"))
+	err = viperScanAzureDevops.BindPFlag("commit-depth", scanAzureDevopsCmd.Flags().Lookup("commit-depth"))
+	err = viperScanAzureDevops.BindPFlag("debug", scanAzureDevopsCmd.Flags().Lookup("debug"))
+	err = viperScanAzureDevops.BindPFlag("debug-server", scanAzureDevopsCmd.Flags().Lookup("debug-server"))
+	err = viperScanAzureDevops.BindPFlag("debug-server-public", scanAzureDevopsCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanAzureDevops.BindPFlag("azure-devops-targets", scanAzureDevopsCmd.Flags().Lookup("azure-devops-targets"))
+	err = viperScanAzureDevops.BindPFlag("azure-devops-token", scanAzureDevopsCmd.Flags().Lookup("azure-devops-token"))
+	err = viperScanAzureDevops.BindPFlag("azure-devops-url", scanAzureDevopsCmd.Flags().Lookup("azure-devops-url"))
+	err = viperScanAzureDevops.BindPFlag("hide-secrets", scanAzureDevopsCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanAzureDevops.BindPFlag("ignore-extension", scanAzureDevopsCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanAzureDevops.BindPFlag("ignore-path", scanAzureDevopsCmd.Flags().Lookup("ignore-path"))
+	err = viperScanAzureDevops.BindPFlag("path-include", scanAzureDevopsCmd.Flags().Lookup("path-include"))
+	err = viperScanAzureDevops.BindPFlag("path-exclude", scanAzureDevopsCmd.Flags().Lookup("path-exclude"))
+	err = viperScanAzureDevops.BindPFlag("in-mem-clone", scanAzureDevopsCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanAzureDevops.BindPFlag("match-level", scanAzureDevopsCmd.Flags().Lookup("match-level"))
+	err = viperScanAzureDevops.BindPFlag("max-file-size", scanAzureDevopsCmd.Flags().Lookup("max-file-size"))
+	err = viperScanAzureDevops.BindPFlag("no-expand-orgs", scanAzureDevopsCmd.Flags().Lookup("no-expand-orgs"))
+	err = viperScanAzureDevops.BindPFlag("num-threads", scanAzureDevopsCmd.Flags().Lookup("num-threads"))
+	err = viperScanAzureDevops.BindPFlag("clone-threads", scanAzureDevopsCmd.Flags().Lookup("clone-threads"))
+	err = viperScanAzureDevops.BindPFlag("walk-threads", scanAzureDevopsCmd.Flags().Lookup("walk-threads"))
+	err = viperScanAzureDevops.BindPFlag("match-threads", scanAzureDevopsCmd.Flags().Lookup("match-threads"))
+	err = viperScanAzureDevops.BindPFlag("pipeline-queue-size", scanAzureDevopsCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanAzureDevops.BindPFlag("scan-tests", scanAzureDevopsCmd.Flags().Lookup("scan-tests"))
+	err = viperScanAzureDevops.BindPFlag("signature-file", scanAzureDevopsCmd.Flags().Lookup("signature-file"))
+	err = viperScanAzureDevops.BindPFlag("silent", scanAzureDevopsCmd.Flags().Lookup("silent"))
+	err = viperScanAzureDevops.BindPFlag("detector-plugins", scanAzureDevopsCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanAzureDevops.BindPFlag("plugin-timeout", scanAzureDevopsCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanAzureDevops.BindPFlag("wasm-plugin-dir", scanAzureDevopsCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanAzureDevops.BindPFlag("grpc-port", scanAzureDevopsCmd.Flags().Lookup("grpc-port"))
+	err = viperScanAzureDevops.BindPFlag("grpc-token", scanAzureDevopsCmd.Flags().Lookup("grpc-token"))
+	err = viperScanAzureDevops.BindPFlag("grpc-tls-cert", scanAzureDevopsCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanAzureDevops.BindPFlag("grpc-tls-key", scanAzureDevopsCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanAzureDevops.BindPFlag("grpc-client-ca", scanAzureDevopsCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanAzureDevops.BindPFlag("allow-local-scans", scanAzureDevopsCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanAzureDevops.BindPFlag("scan-retention", scanAzureDevopsCmd.Flags().Lookup("scan-retention"))
+	err = viperScanAzureDevops.BindPFlag("json", scanAzureDevopsCmd.Flags().Lookup("json"))
+	err = viperScanAzureDevops.BindPFlag("jsonl", scanAzureDevopsCmd.Flags().Lookup("jsonl"))
+	err = viperScanAzureDevops.BindPFlag("stream", scanAzureDevopsCmd.Flags().Lookup("stream"))
+	err = viperScanAzureDevops.BindPFlag("on-finding-exec", scanAzureDevopsCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanAzureDevops.BindPFlag("on-repo-complete-exec", scanAzureDevopsCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanAzureDevops.BindPFlag("finding-script", scanAzureDevopsCmd.Flags().Lookup("finding-script"))
+	err = viperScanAzureDevops.BindPFlag("redact", scanAzureDevopsCmd.Flags().Lookup("redact"))
+	err = viperScanAzureDevops.BindPFlag("history-file", scanAzureDevopsCmd.Flags().Lookup("history-file"))
+	err = viperScanAzureDevops.BindPFlag("alert-state-file", scanAzureDevopsCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanAzureDevops.BindPFlag("realert-interval", scanAzureDevopsCmd.Flags().Lookup("realert-interval"))
+	err = viperScanAzureDevops.BindPFlag("policy", scanAzureDevopsCmd.Flags().Lookup("policy"))
+	err = viperScanAzureDevops.BindPFlag("entropy", scanAzureDevopsCmd.Flags().Lookup("entropy"))
+	err = viperScanAzureDevops.BindPFlag("entropy-base64-threshold", scanAzureDevopsCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanAzureDevops.BindPFlag("entropy-base64-min-length", scanAzureDevopsCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanAzureDevops.BindPFlag("entropy-hex-threshold", scanAzureDevopsCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanAzureDevops.BindPFlag("entropy-hex-min-length", scanAzureDevopsCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanAzureDevops.BindPFlag("baseline", scanAzureDevopsCmd.Flags().Lookup("baseline"))
+	err = viperScanAzureDevops.BindPFlag("db-path", scanAzureDevopsCmd.Flags().Lookup("db-path"))
+	err = viperScanAzureDevops.BindPFlag("verify", scanAzureDevopsCmd.Flags().Lookup("verify"))
+	err = viperScanAzureDevops.BindPFlag("incremental", scanAzureDevopsCmd.Flags().Lookup("incremental"))
+	err = viperScanAzureDevops.BindPFlag("scan-state-file", scanAzureDevopsCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanAzureDevops.BindPFlag("checkpoint", scanAzureDevopsCmd.Flags().Lookup("checkpoint"))
+	err = viperScanAzureDevops.BindPFlag("checkpoint-interval", scanAzureDevopsCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanAzureDevops.BindPFlag("resume", scanAzureDevopsCmd.Flags().Lookup("resume"))
+	err = viperScanAzureDevops.BindPFlag("web-username", scanAzureDevopsCmd.Flags().Lookup("web-username"))
+	err = viperScanAzureDevops.BindPFlag("web-password", scanAzureDevopsCmd.Flags().Lookup("web-password"))
+	err = viperScanAzureDevops.BindPFlag("oidc-issuer", scanAzureDevopsCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanAzureDevops.BindPFlag("oidc-client-id", scanAzureDevopsCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanAzureDevops.BindPFlag("oidc-client-secret", scanAzureDevopsCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanAzureDevops.BindPFlag("oidc-redirect-url", scanAzureDevopsCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanAzureDevops.BindPFlag("oidc-allowed-emails", scanAzureDevopsCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanAzureDevops.BindPFlag("ci", scanAzureDevopsCmd.Flags().Lookup("ci"))
+	err = viperScanAzureDevops.BindPFlag("fail-on", scanAzureDevopsCmd.Flags().Lookup("fail-on"))
+	err = viperScanAzureDevops.BindPFlag("report-html", scanAzureDevopsCmd.Flags().Lookup("report-html"))
+	err = viperScanAzureDevops.BindPFlag("scan-branches", scanAzureDevopsCmd.Flags().Lookup("scan-branches"))
+	err = viperScanAzureDevops.BindPFlag("since-commit", scanAzureDevopsCmd.Flags().Lookup("since-commit"))
+	err = viperScanAzureDevops.BindPFlag("since-date", scanAzureDevopsCmd.Flags().Lookup("since-date"))
+	err = viperScanAzureDevops.BindPFlag("until-commit", scanAzureDevopsCmd.Flags().Lookup("until-commit"))
+	err = viperScanAzureDevops.BindPFlag("suppression-markers", scanAzureDevopsCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanAzureDevops.BindPFlag("webhook-url", scanAzureDevopsCmd.Flags().Lookup("webhook-url"))
+	err = viperScanAzureDevops.BindPFlag("webhook-header", scanAzureDevopsCmd.Flags().Lookup("webhook-header"))
+	err = viperScanAzureDevops.BindPFlag("webhook-secret", scanAzureDevopsCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanAzureDevops.BindPFlag("scan-commit-messages", scanAzureDevopsCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanAzureDevops.BindPFlag("scan-notes", scanAzureDevopsCmd.Flags().Lookup("scan-notes"))
+	err = viperScanAzureDevops.BindPFlag("scan-submodules", scanAzureDevopsCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanAzureDevops.BindPFlag("submodule-depth", scanAzureDevopsCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanAzureDevops.BindPFlag("csv", scanAzureDevopsCmd.Flags().Lookup("csv"))
+	err = viperScanAzureDevops.BindPFlag("triage-file", scanAzureDevopsCmd.Flags().Lookup("triage-file"))
+	err = viperScanAzureDevops.BindPFlag("min-severity", scanAzureDevopsCmd.Flags().Lookup("min-severity"))
+	err = viperScanAzureDevops.BindPFlag("min-confidence", scanAzureDevopsCmd.Flags().Lookup("min-confidence"))
+	err = viperScanAzureDevops.BindPFlag("queue-url", scanAzureDevopsCmd.Flags().Lookup("queue-url"))
+	err = viperScanAzureDevops.BindPFlag("queue-name", scanAzureDevopsCmd.Flags().Lookup("queue-name"))
+	err = viperScanAzureDevops.BindPFlag("queue-timeout", scanAzureDevopsCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanAzureDevops.BindPFlag("decode", scanAzureDevopsCmd.Flags().Lookup("decode"))
+	err = viperScanAzureDevops.BindPFlag("decode-min-length", scanAzureDevopsCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanAzureDevops.BindPFlag("slack-webhook", scanAzureDevopsCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanAzureDevops.BindPFlag("slack-token", scanAzureDevopsCmd.Flags().Lookup("slack-token"))
+	err = viperScanAzureDevops.BindPFlag("slack-channel", scanAzureDevopsCmd.Flags().Lookup("slack-channel"))
+	err = viperScanAzureDevops.BindPFlag("api-scans", scanAzureDevopsCmd.Flags().Lookup("api-scans"))
+	err = viperScanAzureDevops.BindPFlag("include-repos", scanAzureDevopsCmd.Flags().Lookup("include-repos"))
+	err = viperScanAzureDevops.BindPFlag("exclude-repos", scanAzureDevopsCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanAzureDevops.BindPFlag("exclude-archived", scanAzureDevopsCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanAzureDevops.BindPFlag("only-private", scanAzureDevopsCmd.Flags().Lookup("only-private"))
+	err = viperScanAzureDevops.BindPFlag("only-public", scanAzureDevopsCmd.Flags().Lookup("only-public"))
+	err = viperScanAzureDevops.BindPFlag("language", scanAzureDevopsCmd.Flags().Lookup("language"))
+	err = viperScanAzureDevops.BindPFlag("topic", scanAzureDevopsCmd.Flags().Lookup("topic"))
+	err = viperScanAzureDevops.BindPFlag("pushed-after", scanAzureDevopsCmd.Flags().Lookup("pushed-after"))
+	err = viperScanAzureDevops.BindPFlag("log-format", scanAzureDevopsCmd.Flags().Lookup("log-format"))
+	err = viperScanAzureDevops.BindPFlag("log-file", scanAzureDevopsCmd.Flags().Lookup("log-file"))
+	err = viperScanAzureDevops.BindPFlag("clone-cache", scanAzureDevopsCmd.Flags().Lookup("clone-cache"))
+	err = viperScanAzureDevops.BindPFlag("clone-over-ssh", scanAzureDevopsCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanAzureDevops.BindPFlag("ssh-key", scanAzureDevopsCmd.Flags().Lookup("ssh-key"))
+	err = viperScanAzureDevops.BindPFlag("ssh-key-passphrase", scanAzureDevopsCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanAzureDevops.BindPFlag("ssh-known-hosts", scanAzureDevopsCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanAzureDevops.BindPFlag("retries", scanAzureDevopsCmd.Flags().Lookup("retries"))
+	err = viperScanAzureDevops.BindPFlag("retry-backoff", scanAzureDevopsCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanAzureDevops.BindPFlag("gitlab-report", scanAzureDevopsCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanAzureDevops.BindPFlag("in-mem-clone-budget", scanAzureDevopsCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanAzureDevops.BindPFlag("elasticsearch-url", scanAzureDevopsCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanAzureDevops.BindPFlag("elasticsearch-index", scanAzureDevopsCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanAzureDevops.BindPFlag("elasticsearch-username", scanAzureDevopsCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanAzureDevops.BindPFlag("elasticsearch-password", scanAzureDevopsCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanAzureDevops.BindPFlag("elasticsearch-api-key", scanAzureDevopsCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanAzureDevops.BindPFlag("splunk-url", scanAzureDevopsCmd.Flags().Lookup("splunk-url"))
+	err = viperScanAzureDevops.BindPFlag("splunk-token", scanAzureDevopsCmd.Flags().Lookup("splunk-token"))
+	err = viperScanAzureDevops.BindPFlag("splunk-index", scanAzureDevopsCmd.Flags().Lookup("splunk-index"))
+	err = viperScanAzureDevops.BindPFlag("splunk-sourcetype", scanAzureDevopsCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-url", scanAzureDevopsCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-token", scanAzureDevopsCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-product", scanAzureDevopsCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-engagement", scanAzureDevopsCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-test-title", scanAzureDevopsCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanAzureDevops.BindPFlag("defectdojo-close-old", scanAzureDevopsCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanAzureDevops.BindPFlag("proxy", scanAzureDevopsCmd.Flags().Lookup("proxy"))
+	err = viperScanAzureDevops.BindPFlag("ca-bundle", scanAzureDevopsCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanAzureDevops.BindPFlag("insecure-skip-verify", scanAzureDevopsCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanAzureDevops.BindPFlag("list-targets", scanAzureDevopsCmd.Flags().Lookup("list-targets"))
+	err = viperScanAzureDevops.BindPFlag("context-lines", scanAzureDevopsCmd.Flags().Lookup("context-lines"))
+	err = viperScanAzureDevops.BindPFlag("dedup-findings", scanAzureDevopsCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanAzureDevops.BindPFlag("junit", scanAzureDevopsCmd.Flags().Lookup("junit"))
+	err = viperScanAzureDevops.BindPFlag("signature-tags", scanAzureDevopsCmd.Flags().Lookup("signature-tags"))
+	err = viperScanAzureDevops.BindPFlag("enable-signatures", scanAzureDevopsCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanAzureDevops.BindPFlag("disable-signatures", scanAzureDevopsCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanAzureDevops.BindPFlag("encrypt-report", scanAzureDevopsCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanAzureDevops.BindPFlag("report-s3", scanAzureDevopsCmd.Flags().Lookup("report-s3"))
+	err = viperScanAzureDevops.BindPFlag("report-s3-endpoint", scanAzureDevopsCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanAzureDevops.BindPFlag("report-s3-region", scanAzureDevopsCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanAzureDevops.BindPFlag("report-s3-sse", scanAzureDevopsCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanAzureDevops.BindPFlag("report-s3-kms-key-id", scanAzureDevopsCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanAzureDevops.BindPFlag("scan-terraform-state", scanAzureDevopsCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanAzureDevops.BindPFlag("scan-binaries", scanAzureDevopsCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanAzureDevops.BindPFlag("binary-min-length", scanAzureDevopsCmd.Flags().Lookup("binary-min-length"))
+	err = scanAzureDevopsCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
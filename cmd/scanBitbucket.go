@@ -0,0 +1,375 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanBitbucket *viper.Viper
+
+// scanBitbucketCmd represents the scanBitbucket command that will enumerate and scan bitbucket.org
+var scanBitbucketCmd = &cobra.Command{
+	Use:   "scanBitbucket",
+	Short: "Scan one or more bitbucket.org workspaces or projects for secrets.",
+	Long:  `Scan one or more bitbucket.org workspaces or projects for secrets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "bitbucket"
+		sess := core.NewSession(viperScanBitbucket, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		core.GatherTargets(sess)
+		core.GatherRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanBitbucketCmd)
+
+	viperScanBitbucket = core.SetConfig()
+
+	scanBitbucketCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanBitbucketCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanBitbucketCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanBitbucketCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanBitbucketCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanBitbucketCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
+	scanBitbucketCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanBitbucketCmd.Flags().Bool("silent", false, "No output")
+	scanBitbucketCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanBitbucketCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanBitbucketCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanBitbucketCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanBitbucketCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanBitbucketCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanBitbucketCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanBitbucketCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanBitbucketCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanBitbucketCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanBitbucketCmd.Flags().String("bitbucket-app-password", "", "An app password for the bitbucket username with read access to repositories")
+	scanBitbucketCmd.Flags().String("bitbucket-oauth-token", "", "An OAuth access token for bitbucket, used instead of a username and app password")
+	scanBitbucketCmd.Flags().String("bitbucket-targets", "", "A space separated list of bitbucket.org workspaces, or workspace/PROJECT, to scan")
+	scanBitbucketCmd.Flags().String("bitbucket-username", "", "The bitbucket username the app password belongs to")
+	scanBitbucketCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanBitbucketCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanBitbucketCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanBitbucketCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanBitbucketCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanBitbucketCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanBitbucketCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanBitbucketCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanBitbucketCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanBitbucketCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanBitbucketCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanBitbucketCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanBitbucketCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanBitbucketCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanBitbucketCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanBitbucketCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanBitbucketCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanBitbucketCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanBitbucketCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanBitbucketCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanBitbucketCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanBitbucketCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanBitbucketCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanBitbucketCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanBitbucketCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanBitbucketCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanBitbucketCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanBitbucketCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanBitbucketCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanBitbucketCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanBitbucketCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanBitbucketCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanBitbucketCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanBitbucketCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanBitbucketCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanBitbucketCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanBitbucketCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanBitbucketCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanBitbucketCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanBitbucketCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanBitbucketCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanBitbucketCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanBitbucketCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanBitbucketCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanBitbucketCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanBitbucketCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanBitbucketCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanBitbucketCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanBitbucketCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanBitbucketCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanBitbucketCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanBitbucketCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanBitbucketCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanBitbucketCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanBitbucketCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanBitbucketCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanBitbucketCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanBitbucketCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanBitbucketCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanBitbucketCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanBitbucketCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanBitbucketCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanBitbucketCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanBitbucketCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanBitbucketCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanBitbucketCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanBitbucketCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanBitbucketCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanBitbucketCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanBitbucketCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanBitbucketCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanBitbucketCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanBitbucketCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanBitbucketCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanBitbucketCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanBitbucketCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanBitbucketCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanBitbucketCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanBitbucketCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanBitbucketCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanBitbucketCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanBitbucketCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanBitbucketCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanBitbucketCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanBitbucketCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanBitbucketCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanBitbucketCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanBitbucketCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanBitbucketCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanBitbucketCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanBitbucketCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanBitbucketCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanBitbucketCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanBitbucketCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanBitbucketCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanBitbucketCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanBitbucketCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanBitbucketCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanBitbucketCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanBitbucketCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanBitbucketCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanBitbucketCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanBitbucketCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanBitbucketCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanBitbucketCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanBitbucketCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanBitbucketCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanBitbucketCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanBitbucketCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanBitbucketCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanBitbucketCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanBitbucketCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanBitbucketCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanBitbucketCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanBitbucketCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanBitbucketCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanBitbucketCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanBitbucketCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanBitbucketCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanBitbucketCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanBitbucketCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanBitbucketCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanBitbucketCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanBitbucketCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanBitbucketCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanBitbucketCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanBitbucketCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanBitbucket.BindPFlag("bind-address", scanBitbucketCmd.Flags().Lookup("bind-address"))
+	err = viperScanBitbucket.BindPFlag("bind-port", scanBitbucketCmd.Flags().Lookup("bind-port"))
+	err = viperScanBitbucket.BindPFlag("commit-depth", scanBitbucketCmd.Flags().Lookup("commit-depth"))
+	err = viperScanBitbucket.BindPFlag("debug", scanBitbucketCmd.Flags().Lookup("debug"))
+	err = viperScanBitbucket.BindPFlag("debug-server", scanBitbucketCmd.Flags().Lookup("debug-server"))
+	err = viperScanBitbucket.BindPFlag("debug-server-public", scanBitbucketCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanBitbucket.BindPFlag("bitbucket-app-password", scanBitbucketCmd.Flags().Lookup("bitbucket-app-password"))
+	err = viperScanBitbucket.BindPFlag("bitbucket-oauth-token", scanBitbucketCmd.Flags().Lookup("bitbucket-oauth-token"))
+	err = viperScanBitbucket.BindPFlag("bitbucket-targets", scanBitbucketCmd.Flags().Lookup("bitbucket-targets"))
+	err = viperScanBitbucket.BindPFlag("bitbucket-username", scanBitbucketCmd.Flags().Lookup("bitbucket-username"))
+	err = viperScanBitbucket.BindPFlag("hide-secrets", scanBitbucketCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanBitbucket.BindPFlag("ignore-extension", scanBitbucketCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanBitbucket.BindPFlag("ignore-path", scanBitbucketCmd.Flags().Lookup("ignore-path"))
+	err = viperScanBitbucket.BindPFlag("path-include", scanBitbucketCmd.Flags().Lookup("path-include"))
+	err = viperScanBitbucket.BindPFlag("path-exclude", scanBitbucketCmd.Flags().Lookup("path-exclude"))
+	err = viperScanBitbucket.BindPFlag("in-mem-clone", scanBitbucketCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanBitbucket.BindPFlag("match-level", scanBitbucketCmd.Flags().Lookup("match-level"))
+	err = viperScanBitbucket.BindPFlag("max-file-size", scanBitbucketCmd.Flags().Lookup("max-file-size"))
+	err = viperScanBitbucket.BindPFlag("no-expand-orgs", scanBitbucketCmd.Flags().Lookup("no-expand-orgs"))
+	err = viperScanBitbucket.BindPFlag("num-threads", scanBitbucketCmd.Flags().Lookup("num-threads"))
+	err = viperScanBitbucket.BindPFlag("clone-threads", scanBitbucketCmd.Flags().Lookup("clone-threads"))
+	err = viperScanBitbucket.BindPFlag("walk-threads", scanBitbucketCmd.Flags().Lookup("walk-threads"))
+	err = viperScanBitbucket.BindPFlag("match-threads", scanBitbucketCmd.Flags().Lookup("match-threads"))
+	err = viperScanBitbucket.BindPFlag("pipeline-queue-size", scanBitbucketCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanBitbucket.BindPFlag("scan-tests", scanBitbucketCmd.Flags().Lookup("scan-tests"))
+	err = viperScanBitbucket.BindPFlag("signature-file", scanBitbucketCmd.Flags().Lookup("signature-file"))
+	err = viperScanBitbucket.BindPFlag("silent", scanBitbucketCmd.Flags().Lookup("silent"))
+	err = viperScanBitbucket.BindPFlag("detector-plugins", scanBitbucketCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanBitbucket.BindPFlag("plugin-timeout", scanBitbucketCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanBitbucket.BindPFlag("wasm-plugin-dir", scanBitbucketCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanBitbucket.BindPFlag("grpc-port", scanBitbucketCmd.Flags().Lookup("grpc-port"))
+	err = viperScanBitbucket.BindPFlag("grpc-token", scanBitbucketCmd.Flags().Lookup("grpc-token"))
+	err = viperScanBitbucket.BindPFlag("grpc-tls-cert", scanBitbucketCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanBitbucket.BindPFlag("grpc-tls-key", scanBitbucketCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanBitbucket.BindPFlag("grpc-client-ca", scanBitbucketCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanBitbucket.BindPFlag("allow-local-scans", scanBitbucketCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanBitbucket.BindPFlag("scan-retention", scanBitbucketCmd.Flags().Lookup("scan-retention"))
+	err = viperScanBitbucket.BindPFlag("json", scanBitbucketCmd.Flags().Lookup("json"))
+	err = viperScanBitbucket.BindPFlag("jsonl", scanBitbucketCmd.Flags().Lookup("jsonl"))
+	err = viperScanBitbucket.BindPFlag("stream", scanBitbucketCmd.Flags().Lookup("stream"))
+	err = viperScanBitbucket.BindPFlag("on-finding-exec", scanBitbucketCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanBitbucket.BindPFlag("on-repo-complete-exec", scanBitbucketCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanBitbucket.BindPFlag("finding-script", scanBitbucketCmd.Flags().Lookup("finding-script"))
+	err = viperScanBitbucket.BindPFlag("redact", scanBitbucketCmd.Flags().Lookup("redact"))
+	err = viperScanBitbucket.BindPFlag("history-file", scanBitbucketCmd.Flags().Lookup("history-file"))
+	err = viperScanBitbucket.BindPFlag("alert-state-file", scanBitbucketCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanBitbucket.BindPFlag("realert-interval", scanBitbucketCmd.Flags().Lookup("realert-interval"))
+	err = viperScanBitbucket.BindPFlag("policy", scanBitbucketCmd.Flags().Lookup("policy"))
+	err = viperScanBitbucket.BindPFlag("entropy", scanBitbucketCmd.Flags().Lookup("entropy"))
+	err = viperScanBitbucket.BindPFlag("entropy-base64-threshold", scanBitbucketCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanBitbucket.BindPFlag("entropy-base64-min-length", scanBitbucketCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanBitbucket.BindPFlag("entropy-hex-threshold", scanBitbucketCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanBitbucket.BindPFlag("entropy-hex-min-length", scanBitbucketCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanBitbucket.BindPFlag("baseline", scanBitbucketCmd.Flags().Lookup("baseline"))
+	err = viperScanBitbucket.BindPFlag("db-path", scanBitbucketCmd.Flags().Lookup("db-path"))
+	err = viperScanBitbucket.BindPFlag("verify", scanBitbucketCmd.Flags().Lookup("verify"))
+	err = viperScanBitbucket.BindPFlag("incremental", scanBitbucketCmd.Flags().Lookup("incremental"))
+	err = viperScanBitbucket.BindPFlag("scan-state-file", scanBitbucketCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanBitbucket.BindPFlag("checkpoint", scanBitbucketCmd.Flags().Lookup("checkpoint"))
+	err = viperScanBitbucket.BindPFlag("checkpoint-interval", scanBitbucketCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanBitbucket.BindPFlag("resume", scanBitbucketCmd.Flags().Lookup("resume"))
+	err = viperScanBitbucket.BindPFlag("web-username", scanBitbucketCmd.Flags().Lookup("web-username"))
+	err = viperScanBitbucket.BindPFlag("web-password", scanBitbucketCmd.Flags().Lookup("web-password"))
+	err = viperScanBitbucket.BindPFlag("oidc-issuer", scanBitbucketCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanBitbucket.BindPFlag("oidc-client-id", scanBitbucketCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanBitbucket.BindPFlag("oidc-client-secret", scanBitbucketCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanBitbucket.BindPFlag("oidc-redirect-url", scanBitbucketCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanBitbucket.BindPFlag("oidc-allowed-emails", scanBitbucketCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanBitbucket.BindPFlag("ci", scanBitbucketCmd.Flags().Lookup("ci"))
+	err = viperScanBitbucket.BindPFlag("fail-on", scanBitbucketCmd.Flags().Lookup("fail-on"))
+	err = viperScanBitbucket.BindPFlag("report-html", scanBitbucketCmd.Flags().Lookup("report-html"))
+	err = viperScanBitbucket.BindPFlag("scan-branches", scanBitbucketCmd.Flags().Lookup("scan-branches"))
+	err = viperScanBitbucket.BindPFlag("since-commit", scanBitbucketCmd.Flags().Lookup("since-commit"))
+	err = viperScanBitbucket.BindPFlag("since-date", scanBitbucketCmd.Flags().Lookup("since-date"))
+	err = viperScanBitbucket.BindPFlag("until-commit", scanBitbucketCmd.Flags().Lookup("until-commit"))
+	err = viperScanBitbucket.BindPFlag("suppression-markers", scanBitbucketCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanBitbucket.BindPFlag("webhook-url", scanBitbucketCmd.Flags().Lookup("webhook-url"))
+	err = viperScanBitbucket.BindPFlag("webhook-header", scanBitbucketCmd.Flags().Lookup("webhook-header"))
+	err = viperScanBitbucket.BindPFlag("webhook-secret", scanBitbucketCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanBitbucket.BindPFlag("scan-commit-messages", scanBitbucketCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanBitbucket.BindPFlag("scan-notes", scanBitbucketCmd.Flags().Lookup("scan-notes"))
+	err = viperScanBitbucket.BindPFlag("scan-submodules", scanBitbucketCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanBitbucket.BindPFlag("submodule-depth", scanBitbucketCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanBitbucket.BindPFlag("csv", scanBitbucketCmd.Flags().Lookup("csv"))
+	err = viperScanBitbucket.BindPFlag("triage-file", scanBitbucketCmd.Flags().Lookup("triage-file"))
+	err = viperScanBitbucket.BindPFlag("min-severity", scanBitbucketCmd.Flags().Lookup("min-severity"))
+	err = viperScanBitbucket.BindPFlag("min-confidence", scanBitbucketCmd.Flags().Lookup("min-confidence"))
+	err = viperScanBitbucket.BindPFlag("queue-url", scanBitbucketCmd.Flags().Lookup("queue-url"))
+	err = viperScanBitbucket.BindPFlag("queue-name", scanBitbucketCmd.Flags().Lookup("queue-name"))
+	err = viperScanBitbucket.BindPFlag("queue-timeout", scanBitbucketCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanBitbucket.BindPFlag("decode", scanBitbucketCmd.Flags().Lookup("decode"))
+	err = viperScanBitbucket.BindPFlag("decode-min-length", scanBitbucketCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanBitbucket.BindPFlag("slack-webhook", scanBitbucketCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanBitbucket.BindPFlag("slack-token", scanBitbucketCmd.Flags().Lookup("slack-token"))
+	err = viperScanBitbucket.BindPFlag("slack-channel", scanBitbucketCmd.Flags().Lookup("slack-channel"))
+	err = viperScanBitbucket.BindPFlag("api-scans", scanBitbucketCmd.Flags().Lookup("api-scans"))
+	err = viperScanBitbucket.BindPFlag("include-repos", scanBitbucketCmd.Flags().Lookup("include-repos"))
+	err = viperScanBitbucket.BindPFlag("exclude-repos", scanBitbucketCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanBitbucket.BindPFlag("exclude-archived", scanBitbucketCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanBitbucket.BindPFlag("only-private", scanBitbucketCmd.Flags().Lookup("only-private"))
+	err = viperScanBitbucket.BindPFlag("only-public", scanBitbucketCmd.Flags().Lookup("only-public"))
+	err = viperScanBitbucket.BindPFlag("language", scanBitbucketCmd.Flags().Lookup("language"))
+	err = viperScanBitbucket.BindPFlag("topic", scanBitbucketCmd.Flags().Lookup("topic"))
+	err = viperScanBitbucket.BindPFlag("pushed-after", scanBitbucketCmd.Flags().Lookup("pushed-after"))
+	err = viperScanBitbucket.BindPFlag("log-format", scanBitbucketCmd.Flags().Lookup("log-format"))
+	err = viperScanBitbucket.BindPFlag("log-file", scanBitbucketCmd.Flags().Lookup("log-file"))
+	err = viperScanBitbucket.BindPFlag("clone-cache", scanBitbucketCmd.Flags().Lookup("clone-cache"))
+	err = viperScanBitbucket.BindPFlag("clone-over-ssh", scanBitbucketCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanBitbucket.BindPFlag("ssh-key", scanBitbucketCmd.Flags().Lookup("ssh-key"))
+	err = viperScanBitbucket.BindPFlag("ssh-key-passphrase", scanBitbucketCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanBitbucket.BindPFlag("ssh-known-hosts", scanBitbucketCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanBitbucket.BindPFlag("retries", scanBitbucketCmd.Flags().Lookup("retries"))
+	err = viperScanBitbucket.BindPFlag("retry-backoff", scanBitbucketCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanBitbucket.BindPFlag("gitlab-report", scanBitbucketCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanBitbucket.BindPFlag("in-mem-clone-budget", scanBitbucketCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanBitbucket.BindPFlag("elasticsearch-url", scanBitbucketCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanBitbucket.BindPFlag("elasticsearch-index", scanBitbucketCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanBitbucket.BindPFlag("elasticsearch-username", scanBitbucketCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanBitbucket.BindPFlag("elasticsearch-password", scanBitbucketCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanBitbucket.BindPFlag("elasticsearch-api-key", scanBitbucketCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanBitbucket.BindPFlag("splunk-url", scanBitbucketCmd.Flags().Lookup("splunk-url"))
+	err = viperScanBitbucket.BindPFlag("splunk-token", scanBitbucketCmd.Flags().Lookup("splunk-token"))
+	err = viperScanBitbucket.BindPFlag("splunk-index", scanBitbucketCmd.Flags().Lookup("splunk-index"))
+	err = viperScanBitbucket.BindPFlag("splunk-sourcetype", scanBitbucketCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-url", scanBitbucketCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-token", scanBitbucketCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-product", scanBitbucketCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-engagement", scanBitbucketCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-test-title", scanBitbucketCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanBitbucket.BindPFlag("defectdojo-close-old", scanBitbucketCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanBitbucket.BindPFlag("proxy", scanBitbucketCmd.Flags().Lookup("proxy"))
+	err = viperScanBitbucket.BindPFlag("ca-bundle", scanBitbucketCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanBitbucket.BindPFlag("insecure-skip-verify", scanBitbucketCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanBitbucket.BindPFlag("list-targets", scanBitbucketCmd.Flags().Lookup("list-targets"))
+	err = viperScanBitbucket.BindPFlag("context-lines", scanBitbucketCmd.Flags().Lookup("context-lines"))
+	err = viperScanBitbucket.BindPFlag("dedup-findings", scanBitbucketCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanBitbucket.BindPFlag("junit", scanBitbucketCmd.Flags().Lookup("junit"))
+	err = viperScanBitbucket.BindPFlag("signature-tags", scanBitbucketCmd.Flags().Lookup("signature-tags"))
+	err = viperScanBitbucket.BindPFlag("enable-signatures", scanBitbucketCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanBitbucket.BindPFlag("disable-signatures", scanBitbucketCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanBitbucket.BindPFlag("encrypt-report", scanBitbucketCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanBitbucket.BindPFlag("report-s3", scanBitbucketCmd.Flags().Lookup("report-s3"))
+	err = viperScanBitbucket.BindPFlag("report-s3-endpoint", scanBitbucketCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanBitbucket.BindPFlag("report-s3-region", scanBitbucketCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanBitbucket.BindPFlag("report-s3-sse", scanBitbucketCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanBitbucket.BindPFlag("report-s3-kms-key-id", scanBitbucketCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanBitbucket.BindPFlag("scan-terraform-state", scanBitbucketCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanBitbucket.BindPFlag("scan-binaries", scanBitbucketCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanBitbucket.BindPFlag("binary-min-length", scanBitbucketCmd.Flags().Lookup("binary-min-length"))
+	err = scanBitbucketCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
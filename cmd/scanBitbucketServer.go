@@ -0,0 +1,376 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanBitbucketServer *viper.Viper
+
+// scanBitbucketServerCmd represents the scanBitbucketServer command that will enumerate and scan a bitbucket server or
+// data center instance
+var scanBitbucketServerCmd = &cobra.Command{
+	Use:   "scanBitbucketServer",
+	Short: "Scan one or more Bitbucket Server or Data Center projects, users or repositories for secrets.",
+	Long:  `Scan the repositories of one or more projects, users or repositories on a self-hosted Bitbucket Server or Data Center instance for secrets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "bitbucketServer"
+		sess := core.NewSession(viperScanBitbucketServer, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		core.GatherTargets(sess)
+		core.GatherRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanBitbucketServerCmd)
+
+	viperScanBitbucketServer = core.SetConfig()
+
+	scanBitbucketServerCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanBitbucketServerCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanBitbucketServerCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanBitbucketServerCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanBitbucketServerCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanBitbucketServerCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
+	scanBitbucketServerCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanBitbucketServerCmd.Flags().Bool("silent", false, "No output")
+	scanBitbucketServerCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanBitbucketServerCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanBitbucketServerCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanBitbucketServerCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanBitbucketServerCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanBitbucketServerCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanBitbucketServerCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanBitbucketServerCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanBitbucketServerCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanBitbucketServerCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanBitbucketServerCmd.Flags().String("bitbucket-server-targets", "", "A space separated list of project keys, ~user, or PROJECT/repository, to scan, every project the token can read when empty")
+	scanBitbucketServerCmd.Flags().String("bitbucket-server-token", "", "A personal or project http access token with the project read or repository read permission")
+	scanBitbucketServerCmd.Flags().String("bitbucket-server-url", "", "The url of the Bitbucket Server or Data Center instance, such as https://bitbucket.example.com")
+	scanBitbucketServerCmd.Flags().String("bitbucket-server-username", "", "The user the token belongs to, repositories are cloned with the token as a bearer token when empty")
+	scanBitbucketServerCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanBitbucketServerCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanBitbucketServerCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanBitbucketServerCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanBitbucketServerCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanBitbucketServerCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanBitbucketServerCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanBitbucketServerCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanBitbucketServerCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanBitbucketServerCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanBitbucketServerCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanBitbucketServerCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanBitbucketServerCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanBitbucketServerCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanBitbucketServerCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanBitbucketServerCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanBitbucketServerCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanBitbucketServerCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanBitbucketServerCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanBitbucketServerCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanBitbucketServerCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanBitbucketServerCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanBitbucketServerCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanBitbucketServerCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanBitbucketServerCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanBitbucketServerCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanBitbucketServerCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanBitbucketServerCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanBitbucketServerCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanBitbucketServerCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanBitbucketServerCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanBitbucketServerCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanBitbucketServerCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanBitbucketServerCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanBitbucketServerCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanBitbucketServerCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanBitbucketServerCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanBitbucketServerCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanBitbucketServerCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanBitbucketServerCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanBitbucketServerCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanBitbucketServerCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanBitbucketServerCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanBitbucketServerCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanBitbucketServerCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanBitbucketServerCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanBitbucketServerCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanBitbucketServerCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanBitbucketServerCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanBitbucketServerCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanBitbucketServerCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanBitbucketServerCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanBitbucketServerCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanBitbucketServerCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanBitbucketServerCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanBitbucketServerCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanBitbucketServerCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanBitbucketServerCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanBitbucketServerCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanBitbucketServerCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanBitbucketServerCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanBitbucketServerCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanBitbucketServerCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanBitbucketServerCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanBitbucketServerCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanBitbucketServerCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanBitbucketServerCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanBitbucketServerCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanBitbucketServerCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanBitbucketServerCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanBitbucketServerCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanBitbucketServerCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanBitbucketServerCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanBitbucketServerCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanBitbucketServerCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanBitbucketServerCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanBitbucketServerCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanBitbucketServerCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanBitbucketServerCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanBitbucketServerCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanBitbucketServerCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanBitbucketServerCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanBitbucketServerCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanBitbucketServerCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanBitbucketServerCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanBitbucketServerCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanBitbucketServerCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanBitbucketServerCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanBitbucketServerCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanBitbucketServerCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanBitbucketServerCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanBitbucketServerCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanBitbucketServerCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanBitbucketServerCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanBitbucketServerCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanBitbucketServerCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanBitbucketServerCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanBitbucketServerCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanBitbucketServerCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanBitbucketServerCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanBitbucketServerCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanBitbucketServerCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanBitbucketServerCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanBitbucketServerCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanBitbucketServerCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanBitbucketServerCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanBitbucketServerCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanBitbucketServerCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanBitbucketServerCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanBitbucketServerCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanBitbucketServerCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanBitbucketServerCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanBitbucketServerCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanBitbucketServerCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanBitbucketServerCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanBitbucketServerCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanBitbucketServerCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanBitbucketServerCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanBitbucketServerCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanBitbucketServerCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanBitbucketServerCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanBitbucketServerCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanBitbucketServerCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanBitbucketServerCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanBitbucketServerCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanBitbucketServerCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanBitbucketServerCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanBitbucketServer.BindPFlag("bind-address", scanBitbucketServerCmd.Flags().Lookup("bind-address"))
+	err = viperScanBitbucketServer.BindPFlag("bind-port", scanBitbucketServerCmd.Flags().Lookup("bind-port"))
+	err = viperScanBitbucketServer.BindPFlag("commit-depth", scanBitbucketServerCmd.Flags().Lookup("commit-depth"))
+	err = viperScanBitbucketServer.BindPFlag("debug", scanBitbucketServerCmd.Flags().Lookup("debug"))
+	err = viperScanBitbucketServer.BindPFlag("debug-server", scanBitbucketServerCmd.Flags().Lookup("debug-server"))
+	err = viperScanBitbucketServer.BindPFlag("debug-server-public", scanBitbucketServerCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanBitbucketServer.BindPFlag("bitbucket-server-targets", scanBitbucketServerCmd.Flags().Lookup("bitbucket-server-targets"))
+	err = viperScanBitbucketServer.BindPFlag("bitbucket-server-token", scanBitbucketServerCmd.Flags().Lookup("bitbucket-server-token"))
+	err = viperScanBitbucketServer.BindPFlag("bitbucket-server-url", scanBitbucketServerCmd.Flags().Lookup("bitbucket-server-url"))
+	err = viperScanBitbucketServer.BindPFlag("bitbucket-server-username", scanBitbucketServerCmd.Flags().Lookup("bitbucket-server-username"))
+	err = viperScanBitbucketServer.BindPFlag("hide-secrets", scanBitbucketServerCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanBitbucketServer.BindPFlag("ignore-extension", scanBitbucketServerCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanBitbucketServer.BindPFlag("ignore-path", scanBitbucketServerCmd.Flags().Lookup("ignore-path"))
+	err = viperScanBitbucketServer.BindPFlag("path-include", scanBitbucketServerCmd.Flags().Lookup("path-include"))
+	err = viperScanBitbucketServer.BindPFlag("path-exclude", scanBitbucketServerCmd.Flags().Lookup("path-exclude"))
+	err = viperScanBitbucketServer.BindPFlag("in-mem-clone", scanBitbucketServerCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanBitbucketServer.BindPFlag("match-level", scanBitbucketServerCmd.Flags().Lookup("match-level"))
+	err = viperScanBitbucketServer.BindPFlag("max-file-size", scanBitbucketServerCmd.Flags().Lookup("max-file-size"))
+	err = viperScanBitbucketServer.BindPFlag("no-expand-orgs", scanBitbucketServerCmd.Flags().Lookup("no-expand-orgs"))
+	err = viperScanBitbucketServer.BindPFlag("num-threads", scanBitbucketServerCmd.Flags().Lookup("num-threads"))
+	err = viperScanBitbucketServer.BindPFlag("clone-threads", scanBitbucketServerCmd.Flags().Lookup("clone-threads"))
+	err = viperScanBitbucketServer.BindPFlag("walk-threads", scanBitbucketServerCmd.Flags().Lookup("walk-threads"))
+	err = viperScanBitbucketServer.BindPFlag("match-threads", scanBitbucketServerCmd.Flags().Lookup("match-threads"))
+	err = viperScanBitbucketServer.BindPFlag("pipeline-queue-size", scanBitbucketServerCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanBitbucketServer.BindPFlag("scan-tests", scanBitbucketServerCmd.Flags().Lookup("scan-tests"))
+	err = viperScanBitbucketServer.BindPFlag("signature-file", scanBitbucketServerCmd.Flags().Lookup("signature-file"))
+	err = viperScanBitbucketServer.BindPFlag("silent", scanBitbucketServerCmd.Flags().Lookup("silent"))
+	err = viperScanBitbucketServer.BindPFlag("detector-plugins", scanBitbucketServerCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanBitbucketServer.BindPFlag("plugin-timeout", scanBitbucketServerCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanBitbucketServer.BindPFlag("wasm-plugin-dir", scanBitbucketServerCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanBitbucketServer.BindPFlag("grpc-port", scanBitbucketServerCmd.Flags().Lookup("grpc-port"))
+	err = viperScanBitbucketServer.BindPFlag("grpc-token", scanBitbucketServerCmd.Flags().Lookup("grpc-token"))
+	err = viperScanBitbucketServer.BindPFlag("grpc-tls-cert", scanBitbucketServerCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanBitbucketServer.BindPFlag("grpc-tls-key", scanBitbucketServerCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanBitbucketServer.BindPFlag("grpc-client-ca", scanBitbucketServerCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanBitbucketServer.BindPFlag("allow-local-scans", scanBitbucketServerCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanBitbucketServer.BindPFlag("scan-retention", scanBitbucketServerCmd.Flags().Lookup("scan-retention"))
+	err = viperScanBitbucketServer.BindPFlag("json", scanBitbucketServerCmd.Flags().Lookup("json"))
+	err = viperScanBitbucketServer.BindPFlag("jsonl", scanBitbucketServerCmd.Flags().Lookup("jsonl"))
+	err = viperScanBitbucketServer.BindPFlag("stream", scanBitbucketServerCmd.Flags().Lookup("stream"))
+	err = viperScanBitbucketServer.BindPFlag("on-finding-exec", scanBitbucketServerCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanBitbucketServer.BindPFlag("on-repo-complete-exec", scanBitbucketServerCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanBitbucketServer.BindPFlag("finding-script", scanBitbucketServerCmd.Flags().Lookup("finding-script"))
+	err = viperScanBitbucketServer.BindPFlag("redact", scanBitbucketServerCmd.Flags().Lookup("redact"))
+	err = viperScanBitbucketServer.BindPFlag("history-file", scanBitbucketServerCmd.Flags().Lookup("history-file"))
+	err = viperScanBitbucketServer.BindPFlag("alert-state-file", scanBitbucketServerCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanBitbucketServer.BindPFlag("realert-interval", scanBitbucketServerCmd.Flags().Lookup("realert-interval"))
+	err = viperScanBitbucketServer.BindPFlag("policy", scanBitbucketServerCmd.Flags().Lookup("policy"))
+	err = viperScanBitbucketServer.BindPFlag("entropy", scanBitbucketServerCmd.Flags().Lookup("entropy"))
+	err = viperScanBitbucketServer.BindPFlag("entropy-base64-threshold", scanBitbucketServerCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanBitbucketServer.BindPFlag("entropy-base64-min-length", scanBitbucketServerCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanBitbucketServer.BindPFlag("entropy-hex-threshold", scanBitbucketServerCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanBitbucketServer.BindPFlag("entropy-hex-min-length", scanBitbucketServerCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanBitbucketServer.BindPFlag("baseline", scanBitbucketServerCmd.Flags().Lookup("baseline"))
+	err = viperScanBitbucketServer.BindPFlag("db-path", scanBitbucketServerCmd.Flags().Lookup("db-path"))
+	err = viperScanBitbucketServer.BindPFlag("verify", scanBitbucketServerCmd.Flags().Lookup("verify"))
+	err = viperScanBitbucketServer.BindPFlag("incremental", scanBitbucketServerCmd.Flags().Lookup("incremental"))
+	err = viperScanBitbucketServer.BindPFlag("scan-state-file", scanBitbucketServerCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanBitbucketServer.BindPFlag("checkpoint", scanBitbucketServerCmd.Flags().Lookup("checkpoint"))
+	err = viperScanBitbucketServer.BindPFlag("checkpoint-interval", scanBitbucketServerCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanBitbucketServer.BindPFlag("resume", scanBitbucketServerCmd.Flags().Lookup("resume"))
+	err = viperScanBitbucketServer.BindPFlag("web-username", scanBitbucketServerCmd.Flags().Lookup("web-username"))
+	err = viperScanBitbucketServer.BindPFlag("web-password", scanBitbucketServerCmd.Flags().Lookup("web-password"))
+	err = viperScanBitbucketServer.BindPFlag("oidc-issuer", scanBitbucketServerCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanBitbucketServer.BindPFlag("oidc-client-id", scanBitbucketServerCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanBitbucketServer.BindPFlag("oidc-client-secret", scanBitbucketServerCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanBitbucketServer.BindPFlag("oidc-redirect-url", scanBitbucketServerCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanBitbucketServer.BindPFlag("oidc-allowed-emails", scanBitbucketServerCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanBitbucketServer.BindPFlag("ci", scanBitbucketServerCmd.Flags().Lookup("ci"))
+	err = viperScanBitbucketServer.BindPFlag("fail-on", scanBitbucketServerCmd.Flags().Lookup("fail-on"))
+	err = viperScanBitbucketServer.BindPFlag("report-html", scanBitbucketServerCmd.Flags().Lookup("report-html"))
+	err = viperScanBitbucketServer.BindPFlag("scan-branches", scanBitbucketServerCmd.Flags().Lookup("scan-branches"))
+	err = viperScanBitbucketServer.BindPFlag("since-commit", scanBitbucketServerCmd.Flags().Lookup("since-commit"))
+	err = viperScanBitbucketServer.BindPFlag("since-date", scanBitbucketServerCmd.Flags().Lookup("since-date"))
+	err = viperScanBitbucketServer.BindPFlag("until-commit", scanBitbucketServerCmd.Flags().Lookup("until-commit"))
+	err = viperScanBitbucketServer.BindPFlag("suppression-markers", scanBitbucketServerCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanBitbucketServer.BindPFlag("webhook-url", scanBitbucketServerCmd.Flags().Lookup("webhook-url"))
+	err = viperScanBitbucketServer.BindPFlag("webhook-header", scanBitbucketServerCmd.Flags().Lookup("webhook-header"))
+	err = viperScanBitbucketServer.BindPFlag("webhook-secret", scanBitbucketServerCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanBitbucketServer.BindPFlag("scan-commit-messages", scanBitbucketServerCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanBitbucketServer.BindPFlag("scan-notes", scanBitbucketServerCmd.Flags().Lookup("scan-notes"))
+	err = viperScanBitbucketServer.BindPFlag("scan-submodules", scanBitbucketServerCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanBitbucketServer.BindPFlag("submodule-depth", scanBitbucketServerCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanBitbucketServer.BindPFlag("csv", scanBitbucketServerCmd.Flags().Lookup("csv"))
+	err = viperScanBitbucketServer.BindPFlag("triage-file", scanBitbucketServerCmd.Flags().Lookup("triage-file"))
+	err = viperScanBitbucketServer.BindPFlag("min-severity", scanBitbucketServerCmd.Flags().Lookup("min-severity"))
+	err = viperScanBitbucketServer.BindPFlag("min-confidence", scanBitbucketServerCmd.Flags().Lookup("min-confidence"))
+	err = viperScanBitbucketServer.BindPFlag("queue-url", scanBitbucketServerCmd.Flags().Lookup("queue-url"))
+	err = viperScanBitbucketServer.BindPFlag("queue-name", scanBitbucketServerCmd.Flags().Lookup("queue-name"))
+	err = viperScanBitbucketServer.BindPFlag("queue-timeout", scanBitbucketServerCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanBitbucketServer.BindPFlag("decode", scanBitbucketServerCmd.Flags().Lookup("decode"))
+	err = viperScanBitbucketServer.BindPFlag("decode-min-length", scanBitbucketServerCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanBitbucketServer.BindPFlag("slack-webhook", scanBitbucketServerCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanBitbucketServer.BindPFlag("slack-token", scanBitbucketServerCmd.Flags().Lookup("slack-token"))
+	err = viperScanBitbucketServer.BindPFlag("slack-channel", scanBitbucketServerCmd.Flags().Lookup("slack-channel"))
+	err = viperScanBitbucketServer.BindPFlag("api-scans", scanBitbucketServerCmd.Flags().Lookup("api-scans"))
+	err = viperScanBitbucketServer.BindPFlag("include-repos", scanBitbucketServerCmd.Flags().Lookup("include-repos"))
+	err = viperScanBitbucketServer.BindPFlag("exclude-repos", scanBitbucketServerCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanBitbucketServer.BindPFlag("exclude-archived", scanBitbucketServerCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanBitbucketServer.BindPFlag("only-private", scanBitbucketServerCmd.Flags().Lookup("only-private"))
+	err = viperScanBitbucketServer.BindPFlag("only-public", scanBitbucketServerCmd.Flags().Lookup("only-public"))
+	err = viperScanBitbucketServer.BindPFlag("language", scanBitbucketServerCmd.Flags().Lookup("language"))
+	err = viperScanBitbucketServer.BindPFlag("topic", scanBitbucketServerCmd.Flags().Lookup("topic"))
+	err = viperScanBitbucketServer.BindPFlag("pushed-after", scanBitbucketServerCmd.Flags().Lookup("pushed-after"))
+	err = viperScanBitbucketServer.BindPFlag("log-format", scanBitbucketServerCmd.Flags().Lookup("log-format"))
+	err = viperScanBitbucketServer.BindPFlag("log-file", scanBitbucketServerCmd.Flags().Lookup("log-file"))
+	err = viperScanBitbucketServer.BindPFlag("clone-cache", scanBitbucketServerCmd.Flags().Lookup("clone-cache"))
+	err = viperScanBitbucketServer.BindPFlag("clone-over-ssh", scanBitbucketServerCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanBitbucketServer.BindPFlag("ssh-key", scanBitbucketServerCmd.Flags().Lookup("ssh-key"))
+	err = viperScanBitbucketServer.BindPFlag("ssh-key-passphrase", scanBitbucketServerCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanBitbucketServer.BindPFlag("ssh-known-hosts", scanBitbucketServerCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanBitbucketServer.BindPFlag("retries", scanBitbucketServerCmd.Flags().Lookup("retries"))
+	err = viperScanBitbucketServer.BindPFlag("retry-backoff", scanBitbucketServerCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanBitbucketServer.BindPFlag("gitlab-report", scanBitbucketServerCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanBitbucketServer.BindPFlag("in-mem-clone-budget", scanBitbucketServerCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanBitbucketServer.BindPFlag("elasticsearch-url", scanBitbucketServerCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanBitbucketServer.BindPFlag("elasticsearch-index", scanBitbucketServerCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanBitbucketServer.BindPFlag("elasticsearch-username", scanBitbucketServerCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanBitbucketServer.BindPFlag("elasticsearch-password", scanBitbucketServerCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanBitbucketServer.BindPFlag("elasticsearch-api-key", scanBitbucketServerCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanBitbucketServer.BindPFlag("splunk-url", scanBitbucketServerCmd.Flags().Lookup("splunk-url"))
+	err = viperScanBitbucketServer.BindPFlag("splunk-token", scanBitbucketServerCmd.Flags().Lookup("splunk-token"))
+	err = viperScanBitbucketServer.BindPFlag("splunk-index", scanBitbucketServerCmd.Flags().Lookup("splunk-index"))
+	err = viperScanBitbucketServer.BindPFlag("splunk-sourcetype", scanBitbucketServerCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-url", scanBitbucketServerCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-token", scanBitbucketServerCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-product", scanBitbucketServerCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-engagement", scanBitbucketServerCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-test-title", scanBitbucketServerCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanBitbucketServer.BindPFlag("defectdojo-close-old", scanBitbucketServerCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanBitbucketServer.BindPFlag("proxy", scanBitbucketServerCmd.Flags().Lookup("proxy"))
+	err = viperScanBitbucketServer.BindPFlag("ca-bundle", scanBitbucketServerCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanBitbucketServer.BindPFlag("insecure-skip-verify", scanBitbucketServerCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanBitbucketServer.BindPFlag("list-targets", scanBitbucketServerCmd.Flags().Lookup("list-targets"))
+	err = viperScanBitbucketServer.BindPFlag("context-lines", scanBitbucketServerCmd.Flags().Lookup("context-lines"))
+	err = viperScanBitbucketServer.BindPFlag("dedup-findings", scanBitbucketServerCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanBitbucketServer.BindPFlag("junit", scanBitbucketServerCmd.Flags().Lookup("junit"))
+	err = viperScanBitbucketServer.BindPFlag("signature-tags", scanBitbucketServerCmd.Flags().Lookup("signature-tags"))
+	err = viperScanBitbucketServer.BindPFlag("enable-signatures", scanBitbucketServerCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanBitbucketServer.BindPFlag("disable-signatures", scanBitbucketServerCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanBitbucketServer.BindPFlag("encrypt-report", scanBitbucketServerCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanBitbucketServer.BindPFlag("report-s3", scanBitbucketServerCmd.Flags().Lookup("report-s3"))
+	err = viperScanBitbucketServer.BindPFlag("report-s3-endpoint", scanBitbucketServerCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanBitbucketServer.BindPFlag("report-s3-region", scanBitbucketServerCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanBitbucketServer.BindPFlag("report-s3-sse", scanBitbucketServerCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanBitbucketServer.BindPFlag("report-s3-kms-key-id", scanBitbucketServerCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanBitbucketServer.BindPFlag("scan-terraform-state", scanBitbucketServerCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanBitbucketServer.BindPFlag("scan-binaries", scanBitbucketServerCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanBitbucketServer.BindPFlag("binary-min-length", scanBitbucketServerCmd.Flags().Lookup("binary-min-length"))
+	err = scanBitbucketServerCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
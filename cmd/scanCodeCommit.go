@@ -0,0 +1,367 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanCodeCommit *viper.Viper
+
+// scanCodeCommitCmd represents the scanCodeCommit command that will enumerate and scan the repositories of aws codecommit
+var scanCodeCommitCmd = &cobra.Command{
+	Use:   "scanCodeCommit",
+	Short: "Scan the AWS CodeCommit repositories of one or more accounts and regions for secrets.",
+	Long:  `Scan the AWS CodeCommit repositories of one or more accounts and regions for secrets, with the aws credentials of the environment or a profile, and the roles they can assume in other accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "codeCommit"
+		sess := core.NewSession(viperScanCodeCommit, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		core.GatherTargets(sess)
+		core.GatherRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCodeCommitCmd)
+
+	viperScanCodeCommit = core.SetConfig()
+
+	scanCodeCommitCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanCodeCommitCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanCodeCommitCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanCodeCommitCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanCodeCommitCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanCodeCommitCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
+	scanCodeCommitCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanCodeCommitCmd.Flags().Bool("silent", false, "No output")
+	scanCodeCommitCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanCodeCommitCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanCodeCommitCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanCodeCommitCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanCodeCommitCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanCodeCommitCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanCodeCommitCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanCodeCommitCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanCodeCommitCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanCodeCommitCmd.Flags().String("aws-profile", "", "The profile in the shared aws credentials file to use, AWS_PROFILE or the environment when empty")
+	scanCodeCommitCmd.Flags().String("codecommit-targets", "", "A space separated list of regions, or account/region, to scan, the region of the environment when empty")
+	scanCodeCommitCmd.Flags().String("codecommit-role-arns", "", "A space separated list of roles to assume, each region is scanned in the account of every role")
+	scanCodeCommitCmd.Flags().String("codecommit-endpoint", "", "A custom endpoint for CodeCommit and STS, such as localstack")
+	scanCodeCommitCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanCodeCommitCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanCodeCommitCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanCodeCommitCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanCodeCommitCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanCodeCommitCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanCodeCommitCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanCodeCommitCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanCodeCommitCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanCodeCommitCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanCodeCommitCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanCodeCommitCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanCodeCommitCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanCodeCommitCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanCodeCommitCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanCodeCommitCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanCodeCommitCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanCodeCommitCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanCodeCommitCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanCodeCommitCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanCodeCommitCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanCodeCommitCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanCodeCommitCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanCodeCommitCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanCodeCommitCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanCodeCommitCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanCodeCommitCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanCodeCommitCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanCodeCommitCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanCodeCommitCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanCodeCommitCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanCodeCommitCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanCodeCommitCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanCodeCommitCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanCodeCommitCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanCodeCommitCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanCodeCommitCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanCodeCommitCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanCodeCommitCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanCodeCommitCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanCodeCommitCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanCodeCommitCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanCodeCommitCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanCodeCommitCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanCodeCommitCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanCodeCommitCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanCodeCommitCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanCodeCommitCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanCodeCommitCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanCodeCommitCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanCodeCommitCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanCodeCommitCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanCodeCommitCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanCodeCommitCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanCodeCommitCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanCodeCommitCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanCodeCommitCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanCodeCommitCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanCodeCommitCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanCodeCommitCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanCodeCommitCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanCodeCommitCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanCodeCommitCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanCodeCommitCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanCodeCommitCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanCodeCommitCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanCodeCommitCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanCodeCommitCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanCodeCommitCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanCodeCommitCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanCodeCommitCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanCodeCommitCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanCodeCommitCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanCodeCommitCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanCodeCommitCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanCodeCommitCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanCodeCommitCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanCodeCommitCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanCodeCommitCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanCodeCommitCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanCodeCommitCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanCodeCommitCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanCodeCommitCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanCodeCommitCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanCodeCommitCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanCodeCommitCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanCodeCommitCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanCodeCommitCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanCodeCommitCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanCodeCommitCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanCodeCommitCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanCodeCommitCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanCodeCommitCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanCodeCommitCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanCodeCommitCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanCodeCommitCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanCodeCommitCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanCodeCommitCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanCodeCommitCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanCodeCommitCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanCodeCommitCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanCodeCommitCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanCodeCommitCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanCodeCommitCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanCodeCommitCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanCodeCommitCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanCodeCommitCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanCodeCommitCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanCodeCommitCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanCodeCommitCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanCodeCommitCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanCodeCommitCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanCodeCommitCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanCodeCommitCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanCodeCommitCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanCodeCommitCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanCodeCommitCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanCodeCommitCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanCodeCommitCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanCodeCommitCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanCodeCommitCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanCodeCommitCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanCodeCommitCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanCodeCommitCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanCodeCommit.BindPFlag("bind-address", scanCodeCommitCmd.Flags().Lookup("bind-address"))
+	err = viperScanCodeCommit.BindPFlag("bind-port", scanCodeCommitCmd.Flags().Lookup("bind-port"))
+	err = viperScanCodeCommit.BindPFlag("commit-depth", scanCodeCommitCmd.Flags().Lookup("commit-depth"))
+	err = viperScanCodeCommit.BindPFlag("debug", scanCodeCommitCmd.Flags().Lookup("debug"))
+	err = viperScanCodeCommit.BindPFlag("debug-server", scanCodeCommitCmd.Flags().Lookup("debug-server"))
+	err = viperScanCodeCommit.BindPFlag("debug-server-public", scanCodeCommitCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanCodeCommit.BindPFlag("aws-profile", scanCodeCommitCmd.Flags().Lookup("aws-profile"))
+	err = viperScanCodeCommit.BindPFlag("codecommit-targets", scanCodeCommitCmd.Flags().Lookup("codecommit-targets"))
+	err = viperScanCodeCommit.BindPFlag("codecommit-role-arns", scanCodeCommitCmd.Flags().Lookup("codecommit-role-arns"))
+	err = viperScanCodeCommit.BindPFlag("codecommit-endpoint", scanCodeCommitCmd.Flags().Lookup("codecommit-endpoint"))
+	err = viperScanCodeCommit.BindPFlag("hide-secrets", scanCodeCommitCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanCodeCommit.BindPFlag("ignore-extension", scanCodeCommitCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanCodeCommit.BindPFlag("ignore-path", scanCodeCommitCmd.Flags().Lookup("ignore-path"))
+	err = viperScanCodeCommit.BindPFlag("path-include", scanCodeCommitCmd.Flags().Lookup("path-include"))
+	err = viperScanCodeCommit.BindPFlag("path-exclude", scanCodeCommitCmd.Flags().Lookup("path-exclude"))
+	err = viperScanCodeCommit.BindPFlag("in-mem-clone", scanCodeCommitCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanCodeCommit.BindPFlag("match-level", scanCodeCommitCmd.Flags().Lookup("match-level"))
+	err = viperScanCodeCommit.BindPFlag("max-file-size", scanCodeCommitCmd.Flags().Lookup("max-file-size"))
+	err = viperScanCodeCommit.BindPFlag("no-expand-orgs", scanCodeCommitCmd.Flags().Lookup("no-expand-orgs"))
+	err = viperScanCodeCommit.BindPFlag("num-threads", scanCodeCommitCmd.Flags().Lookup("num-threads"))
+	err = viperScanCodeCommit.BindPFlag("clone-threads", scanCodeCommitCmd.Flags().Lookup("clone-threads"))
+	err = viperScanCodeCommit.BindPFlag("walk-threads", scanCodeCommitCmd.Flags().Lookup("walk-threads"))
+	err = viperScanCodeCommit.BindPFlag("match-threads", scanCodeCommitCmd.Flags().Lookup("match-threads"))
+	err = viperScanCodeCommit.BindPFlag("pipeline-queue-size", scanCodeCommitCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanCodeCommit.BindPFlag("scan-tests", scanCodeCommitCmd.Flags().Lookup("scan-tests"))
+	err = viperScanCodeCommit.BindPFlag("signature-file", scanCodeCommitCmd.Flags().Lookup("signature-file"))
+	err = viperScanCodeCommit.BindPFlag("silent", scanCodeCommitCmd.Flags().Lookup("silent"))
+	err = viperScanCodeCommit.BindPFlag("detector-plugins", scanCodeCommitCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanCodeCommit.BindPFlag("plugin-timeout", scanCodeCommitCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanCodeCommit.BindPFlag("wasm-plugin-dir", scanCodeCommitCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanCodeCommit.BindPFlag("grpc-port", scanCodeCommitCmd.Flags().Lookup("grpc-port"))
+	err = viperScanCodeCommit.BindPFlag("grpc-token", scanCodeCommitCmd.Flags().Lookup("grpc-token"))
+	err = viperScanCodeCommit.BindPFlag("grpc-tls-cert", scanCodeCommitCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanCodeCommit.BindPFlag("grpc-tls-key", scanCodeCommitCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanCodeCommit.BindPFlag("grpc-client-ca", scanCodeCommitCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanCodeCommit.BindPFlag("allow-local-scans", scanCodeCommitCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanCodeCommit.BindPFlag("scan-retention", scanCodeCommitCmd.Flags().Lookup("scan-retention"))
+	err = viperScanCodeCommit.BindPFlag("json", scanCodeCommitCmd.Flags().Lookup("json"))
+	err = viperScanCodeCommit.BindPFlag("jsonl", scanCodeCommitCmd.Flags().Lookup("jsonl"))
+	err = viperScanCodeCommit.BindPFlag("stream", scanCodeCommitCmd.Flags().Lookup("stream"))
+	err = viperScanCodeCommit.BindPFlag("on-finding-exec", scanCodeCommitCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanCodeCommit.BindPFlag("on-repo-complete-exec", scanCodeCommitCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanCodeCommit.BindPFlag("finding-script", scanCodeCommitCmd.Flags().Lookup("finding-script"))
+	err = viperScanCodeCommit.BindPFlag("redact", scanCodeCommitCmd.Flags().Lookup("redact"))
+	err = viperScanCodeCommit.BindPFlag("history-file", scanCodeCommitCmd.Flags().Lookup("history-file"))
+	err = viperScanCodeCommit.BindPFlag("alert-state-file", scanCodeCommitCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanCodeCommit.BindPFlag("realert-interval", scanCodeCommitCmd.Flags().Lookup("realert-interval"))
+	err = viperScanCodeCommit.BindPFlag("policy", scanCodeCommitCmd.Flags().Lookup("policy"))
+	err = viperScanCodeCommit.BindPFlag("entropy", scanCodeCommitCmd.Flags().Lookup("entropy"))
+	err = viperScanCodeCommit.BindPFlag("entropy-base64-threshold", scanCodeCommitCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanCodeCommit.BindPFlag("entropy-base64-min-length", scanCodeCommitCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanCodeCommit.BindPFlag("entropy-hex-threshold", scanCodeCommitCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanCodeCommit.BindPFlag("entropy-hex-min-length", scanCodeCommitCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanCodeCommit.BindPFlag("baseline", scanCodeCommitCmd.Flags().Lookup("baseline"))
+	err = viperScanCodeCommit.BindPFlag("db-path", scanCodeCommitCmd.Flags().Lookup("db-path"))
+	err = viperScanCodeCommit.BindPFlag("verify", scanCodeCommitCmd.Flags().Lookup("verify"))
+	err = viperScanCodeCommit.BindPFlag("incremental", scanCodeCommitCmd.Flags().Lookup("incremental"))
+	err = viperScanCodeCommit.BindPFlag("scan-state-file", scanCodeCommitCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanCodeCommit.BindPFlag("checkpoint", scanCodeCommitCmd.Flags().Lookup("checkpoint"))
+	err = viperScanCodeCommit.BindPFlag("checkpoint-interval", scanCodeCommitCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanCodeCommit.BindPFlag("resume", scanCodeCommitCmd.Flags().Lookup("resume"))
+	err = viperScanCodeCommit.BindPFlag("web-username", scanCodeCommitCmd.Flags().Lookup("web-username"))
+	err = viperScanCodeCommit.BindPFlag("web-password", scanCodeCommitCmd.Flags().Lookup("web-password"))
+	err = viperScanCodeCommit.BindPFlag("oidc-issuer", scanCodeCommitCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanCodeCommit.BindPFlag("oidc-client-id", scanCodeCommitCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanCodeCommit.BindPFlag("oidc-client-secret", scanCodeCommitCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanCodeCommit.BindPFlag("oidc-redirect-url", scanCodeCommitCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanCodeCommit.BindPFlag("oidc-allowed-emails", scanCodeCommitCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanCodeCommit.BindPFlag("ci", scanCodeCommitCmd.Flags().Lookup("ci"))
+	err = viperScanCodeCommit.BindPFlag("fail-on", scanCodeCommitCmd.Flags().Lookup("fail-on"))
+	err = viperScanCodeCommit.BindPFlag("report-html", scanCodeCommitCmd.Flags().Lookup("report-html"))
+	err = viperScanCodeCommit.BindPFlag("scan-branches", scanCodeCommitCmd.Flags().Lookup("scan-branches"))
+	err = viperScanCodeCommit.BindPFlag("since-commit", scanCodeCommitCmd.Flags().Lookup("since-commit"))
+	err = viperScanCodeCommit.BindPFlag("since-date", scanCodeCommitCmd.Flags().Lookup("since-date"))
+	err = viperScanCodeCommit.BindPFlag("until-commit", scanCodeCommitCmd.Flags().Lookup("until-commit"))
+	err = viperScanCodeCommit.BindPFlag("suppression-markers", scanCodeCommitCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanCodeCommit.BindPFlag("webhook-url", scanCodeCommitCmd.Flags().Lookup("webhook-url"))
+	err = viperScanCodeCommit.BindPFlag("webhook-header", scanCodeCommitCmd.Flags().Lookup("webhook-header"))
+	err = viperScanCodeCommit.BindPFlag("webhook-secret", scanCodeCommitCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanCodeCommit.BindPFlag("scan-commit-messages", scanCodeCommitCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanCodeCommit.BindPFlag("scan-notes", scanCodeCommitCmd.Flags().Lookup("scan-notes"))
+	err = viperScanCodeCommit.BindPFlag("scan-submodules", scanCodeCommitCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanCodeCommit.BindPFlag("submodule-depth", scanCodeCommitCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanCodeCommit.BindPFlag("csv", scanCodeCommitCmd.Flags().Lookup("csv"))
+	err = viperScanCodeCommit.BindPFlag("triage-file", scanCodeCommitCmd.Flags().Lookup("triage-file"))
+	err = viperScanCodeCommit.BindPFlag("min-severity", scanCodeCommitCmd.Flags().Lookup("min-severity"))
+	err = viperScanCodeCommit.BindPFlag("min-confidence", scanCodeCommitCmd.Flags().Lookup("min-confidence"))
+	err = viperScanCodeCommit.BindPFlag("queue-url", scanCodeCommitCmd.Flags().Lookup("queue-url"))
+	err = viperScanCodeCommit.BindPFlag("queue-name", scanCodeCommitCmd.Flags().Lookup("queue-name"))
+	err = viperScanCodeCommit.BindPFlag("queue-timeout", scanCodeCommitCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanCodeCommit.BindPFlag("decode", scanCodeCommitCmd.Flags().Lookup("decode"))
+	err = viperScanCodeCommit.BindPFlag("decode-min-length", scanCodeCommitCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanCodeCommit.BindPFlag("slack-webhook", scanCodeCommitCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanCodeCommit.BindPFlag("slack-token", scanCodeCommitCmd.Flags().Lookup("slack-token"))
+	err = viperScanCodeCommit.BindPFlag("slack-channel", scanCodeCommitCmd.Flags().Lookup("slack-channel"))
+	err = viperScanCodeCommit.BindPFlag("api-scans", scanCodeCommitCmd.Flags().Lookup("api-scans"))
+	err = viperScanCodeCommit.BindPFlag("include-repos", scanCodeCommitCmd.Flags().Lookup("include-repos"))
+	err = viperScanCodeCommit.BindPFlag("exclude-repos", scanCodeCommitCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanCodeCommit.BindPFlag("exclude-archived", scanCodeCommitCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanCodeCommit.BindPFlag("only-private", scanCodeCommitCmd.Flags().Lookup("only-private"))
+	err = viperScanCodeCommit.BindPFlag("only-public", scanCodeCommitCmd.Flags().Lookup("only-public"))
+	err = viperScanCodeCommit.BindPFlag("language", scanCodeCommitCmd.Flags().Lookup("language"))
+	err = viperScanCodeCommit.BindPFlag("topic", scanCodeCommitCmd.Flags().Lookup("topic"))
+	err = viperScanCodeCommit.BindPFlag("pushed-after", scanCodeCommitCmd.Flags().Lookup("pushed-after"))
+	err = viperScanCodeCommit.BindPFlag("log-format", scanCodeCommitCmd.Flags().Lookup("log-format"))
+	err = viperScanCodeCommit.BindPFlag("log-file", scanCodeCommitCmd.Flags().Lookup("log-file"))
+	err = viperScanCodeCommit.BindPFlag("clone-cache", scanCodeCommitCmd.Flags().Lookup("clone-cache"))
+	err = viperScanCodeCommit.BindPFlag("retries", scanCodeCommitCmd.Flags().Lookup("retries"))
+	err = viperScanCodeCommit.BindPFlag("retry-backoff", scanCodeCommitCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanCodeCommit.BindPFlag("gitlab-report", scanCodeCommitCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanCodeCommit.BindPFlag("in-mem-clone-budget", scanCodeCommitCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanCodeCommit.BindPFlag("elasticsearch-url", scanCodeCommitCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanCodeCommit.BindPFlag("elasticsearch-index", scanCodeCommitCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanCodeCommit.BindPFlag("elasticsearch-username", scanCodeCommitCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanCodeCommit.BindPFlag("elasticsearch-password", scanCodeCommitCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanCodeCommit.BindPFlag("elasticsearch-api-key", scanCodeCommitCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanCodeCommit.BindPFlag("splunk-url", scanCodeCommitCmd.Flags().Lookup("splunk-url"))
+	err = viperScanCodeCommit.BindPFlag("splunk-token", scanCodeCommitCmd.Flags().Lookup("splunk-token"))
+	err = viperScanCodeCommit.BindPFlag("splunk-index", scanCodeCommitCmd.Flags().Lookup("splunk-index"))
+	err = viperScanCodeCommit.BindPFlag("splunk-sourcetype", scanCodeCommitCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-url", scanCodeCommitCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-token", scanCodeCommitCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-product", scanCodeCommitCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-engagement", scanCodeCommitCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-test-title", scanCodeCommitCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanCodeCommit.BindPFlag("defectdojo-close-old", scanCodeCommitCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanCodeCommit.BindPFlag("proxy", scanCodeCommitCmd.Flags().Lookup("proxy"))
+	err = viperScanCodeCommit.BindPFlag("ca-bundle", scanCodeCommitCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanCodeCommit.BindPFlag("insecure-skip-verify", scanCodeCommitCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanCodeCommit.BindPFlag("list-targets", scanCodeCommitCmd.Flags().Lookup("list-targets"))
+	err = viperScanCodeCommit.BindPFlag("context-lines", scanCodeCommitCmd.Flags().Lookup("context-lines"))
+	err = viperScanCodeCommit.BindPFlag("dedup-findings", scanCodeCommitCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanCodeCommit.BindPFlag("junit", scanCodeCommitCmd.Flags().Lookup("junit"))
+	err = viperScanCodeCommit.BindPFlag("signature-tags", scanCodeCommitCmd.Flags().Lookup("signature-tags"))
+	err = viperScanCodeCommit.BindPFlag("enable-signatures", scanCodeCommitCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanCodeCommit.BindPFlag("disable-signatures", scanCodeCommitCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanCodeCommit.BindPFlag("encrypt-report", scanCodeCommitCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanCodeCommit.BindPFlag("report-s3", scanCodeCommitCmd.Flags().Lookup("report-s3"))
+	err = viperScanCodeCommit.BindPFlag("report-s3-endpoint", scanCodeCommitCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanCodeCommit.BindPFlag("report-s3-region", scanCodeCommitCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanCodeCommit.BindPFlag("report-s3-sse", scanCodeCommitCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanCodeCommit.BindPFlag("report-s3-kms-key-id", scanCodeCommitCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanCodeCommit.BindPFlag("scan-terraform-state", scanCodeCommitCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanCodeCommit.BindPFlag("scan-binaries", scanCodeCommitCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanCodeCommit.BindPFlag("binary-min-length", scanCodeCommitCmd.Flags().Lookup("binary-min-length"))
+	err = scanCodeCommitCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
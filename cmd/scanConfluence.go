@@ -0,0 +1,282 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanConfluence *viper.Viper
+
+// scanConfluenceCmd represents the scanConfluence command that will scan the pages and attachments of confluence spaces
+var scanConfluenceCmd = &cobra.Command{
+	Use:   "scanConfluence",
+	Short: "Scan the pages and attachments in one or more confluence spaces for secrets.",
+	Long:  `Scan the body of every page in one or more confluence spaces, and the attachments of those pages, for secrets through the confluence rest api.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "confluence"
+		sess := core.NewSession(viperScanConfluence, scanType)
+		sess.HandleInterrupts()
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		if err := core.ScanConfluence(sess); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanConfluenceCmd)
+
+	viperScanConfluence = core.SetConfig()
+
+	scanConfluenceCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanConfluenceCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanConfluenceCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanConfluenceCmd.Flags().Bool("hide-secrets", false, "Show secrets in any supported output")
+	scanConfluenceCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanConfluenceCmd.Flags().Bool("silent", false, "Suppress all output except for errors")
+	scanConfluenceCmd.Flags().Int64("max-file-size", 50, "Max file size to scan")
+	scanConfluenceCmd.Flags().Int("match-level", 3, "The match level of the expressions used to find matches")
+	scanConfluenceCmd.Flags().String("ignore-extension", "", "a list of extensions to ignore during a scan")
+	scanConfluenceCmd.Flags().String("ignore-path", "", "a list of paths to ignore during a scan")
+	scanConfluenceCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanConfluenceCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanConfluenceCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanConfluenceCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanConfluenceCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanConfluenceCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanConfluenceCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanConfluenceCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanConfluenceCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanConfluenceCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanConfluenceCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanConfluenceCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanConfluenceCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanConfluenceCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanConfluenceCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanConfluenceCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanConfluenceCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanConfluenceCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanConfluenceCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanConfluenceCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanConfluenceCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanConfluenceCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanConfluenceCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanConfluenceCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanConfluenceCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanConfluenceCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanConfluenceCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanConfluenceCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanConfluenceCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanConfluenceCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanConfluenceCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanConfluenceCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanConfluenceCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanConfluenceCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanConfluenceCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanConfluenceCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanConfluenceCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanConfluenceCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanConfluenceCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanConfluenceCmd.Flags().String("confluence-url", "", "The url of the confluence site, such as https://acme.atlassian.net/wiki or https://confluence.example.com")
+	scanConfluenceCmd.Flags().String("confluence-spaces", "", "A space separated list of the keys of the spaces to scan")
+	scanConfluenceCmd.Flags().String("confluence-username", "", "The email or username to sign in to confluence with, along with --confluence-token")
+	scanConfluenceCmd.Flags().String("confluence-token", "", "An api token for the confluence username, or a personal access token on its own for confluence server and data center")
+	scanConfluenceCmd.Flags().Bool("confluence-attachments", true, "Scan the attachments of each page along with its body")
+	scanConfluenceCmd.Flags().Int("num-threads", 0, "The number of pages to scan at a time")
+	scanConfluenceCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanConfluenceCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanConfluenceCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanConfluenceCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanConfluenceCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanConfluenceCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanConfluenceCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanConfluenceCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanConfluenceCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanConfluenceCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanConfluenceCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanConfluenceCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanConfluenceCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanConfluenceCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanConfluenceCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanConfluenceCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanConfluenceCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanConfluenceCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanConfluenceCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanConfluenceCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanConfluenceCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanConfluenceCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanConfluenceCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanConfluenceCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanConfluenceCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanConfluenceCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanConfluenceCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanConfluenceCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanConfluenceCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanConfluenceCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanConfluenceCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanConfluenceCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanConfluenceCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanConfluenceCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanConfluenceCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanConfluenceCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanConfluenceCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanConfluenceCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanConfluenceCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanConfluenceCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanConfluenceCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanConfluenceCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanConfluenceCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanConfluenceCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanConfluenceCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanConfluenceCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanConfluenceCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanConfluenceCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanConfluenceCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanConfluenceCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+	scanConfluenceCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanConfluenceCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+
+	err := viperScanConfluence.BindPFlag("debug", scanConfluenceCmd.Flags().Lookup("debug"))
+	err = viperScanConfluence.BindPFlag("debug-server", scanConfluenceCmd.Flags().Lookup("debug-server"))
+	err = viperScanConfluence.BindPFlag("debug-server-public", scanConfluenceCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanConfluence.BindPFlag("hide-secrets", scanConfluenceCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanConfluence.BindPFlag("scan-tests", scanConfluenceCmd.Flags().Lookup("scan-tests"))
+	err = viperScanConfluence.BindPFlag("silent", scanConfluenceCmd.Flags().Lookup("silent"))
+	err = viperScanConfluence.BindPFlag("max-file-size", scanConfluenceCmd.Flags().Lookup("max-file-size"))
+	err = viperScanConfluence.BindPFlag("match-level", scanConfluenceCmd.Flags().Lookup("match-level"))
+	err = viperScanConfluence.BindPFlag("ignore-extension", scanConfluenceCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanConfluence.BindPFlag("ignore-path", scanConfluenceCmd.Flags().Lookup("ignore-path"))
+	err = viperScanConfluence.BindPFlag("signature-file", scanConfluenceCmd.Flags().Lookup("signature-file"))
+	err = viperScanConfluence.BindPFlag("detector-plugins", scanConfluenceCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanConfluence.BindPFlag("plugin-timeout", scanConfluenceCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanConfluence.BindPFlag("wasm-plugin-dir", scanConfluenceCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanConfluence.BindPFlag("grpc-port", scanConfluenceCmd.Flags().Lookup("grpc-port"))
+	err = viperScanConfluence.BindPFlag("grpc-token", scanConfluenceCmd.Flags().Lookup("grpc-token"))
+	err = viperScanConfluence.BindPFlag("grpc-tls-cert", scanConfluenceCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanConfluence.BindPFlag("grpc-tls-key", scanConfluenceCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanConfluence.BindPFlag("grpc-client-ca", scanConfluenceCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanConfluence.BindPFlag("allow-local-scans", scanConfluenceCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanConfluence.BindPFlag("scan-retention", scanConfluenceCmd.Flags().Lookup("scan-retention"))
+	err = viperScanConfluence.BindPFlag("json", scanConfluenceCmd.Flags().Lookup("json"))
+	err = viperScanConfluence.BindPFlag("jsonl", scanConfluenceCmd.Flags().Lookup("jsonl"))
+	err = viperScanConfluence.BindPFlag("stream", scanConfluenceCmd.Flags().Lookup("stream"))
+	err = viperScanConfluence.BindPFlag("on-finding-exec", scanConfluenceCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanConfluence.BindPFlag("finding-script", scanConfluenceCmd.Flags().Lookup("finding-script"))
+	err = viperScanConfluence.BindPFlag("redact", scanConfluenceCmd.Flags().Lookup("redact"))
+	err = viperScanConfluence.BindPFlag("history-file", scanConfluenceCmd.Flags().Lookup("history-file"))
+	err = viperScanConfluence.BindPFlag("alert-state-file", scanConfluenceCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanConfluence.BindPFlag("realert-interval", scanConfluenceCmd.Flags().Lookup("realert-interval"))
+	err = viperScanConfluence.BindPFlag("policy", scanConfluenceCmd.Flags().Lookup("policy"))
+	err = viperScanConfluence.BindPFlag("entropy", scanConfluenceCmd.Flags().Lookup("entropy"))
+	err = viperScanConfluence.BindPFlag("entropy-base64-threshold", scanConfluenceCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanConfluence.BindPFlag("entropy-base64-min-length", scanConfluenceCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanConfluence.BindPFlag("entropy-hex-threshold", scanConfluenceCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanConfluence.BindPFlag("entropy-hex-min-length", scanConfluenceCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanConfluence.BindPFlag("baseline", scanConfluenceCmd.Flags().Lookup("baseline"))
+	err = viperScanConfluence.BindPFlag("db-path", scanConfluenceCmd.Flags().Lookup("db-path"))
+	err = viperScanConfluence.BindPFlag("verify", scanConfluenceCmd.Flags().Lookup("verify"))
+	err = viperScanConfluence.BindPFlag("web-username", scanConfluenceCmd.Flags().Lookup("web-username"))
+	err = viperScanConfluence.BindPFlag("web-password", scanConfluenceCmd.Flags().Lookup("web-password"))
+	err = viperScanConfluence.BindPFlag("oidc-issuer", scanConfluenceCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanConfluence.BindPFlag("oidc-client-id", scanConfluenceCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanConfluence.BindPFlag("oidc-client-secret", scanConfluenceCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanConfluence.BindPFlag("oidc-redirect-url", scanConfluenceCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanConfluence.BindPFlag("oidc-allowed-emails", scanConfluenceCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanConfluence.BindPFlag("ci", scanConfluenceCmd.Flags().Lookup("ci"))
+	err = viperScanConfluence.BindPFlag("fail-on", scanConfluenceCmd.Flags().Lookup("fail-on"))
+	err = viperScanConfluence.BindPFlag("report-html", scanConfluenceCmd.Flags().Lookup("report-html"))
+	err = viperScanConfluence.BindPFlag("confluence-url", scanConfluenceCmd.Flags().Lookup("confluence-url"))
+	err = viperScanConfluence.BindPFlag("confluence-spaces", scanConfluenceCmd.Flags().Lookup("confluence-spaces"))
+	err = viperScanConfluence.BindPFlag("confluence-username", scanConfluenceCmd.Flags().Lookup("confluence-username"))
+	err = viperScanConfluence.BindPFlag("confluence-token", scanConfluenceCmd.Flags().Lookup("confluence-token"))
+	err = viperScanConfluence.BindPFlag("confluence-attachments", scanConfluenceCmd.Flags().Lookup("confluence-attachments"))
+	err = viperScanConfluence.BindPFlag("num-threads", scanConfluenceCmd.Flags().Lookup("num-threads"))
+	err = viperScanConfluence.BindPFlag("suppression-markers", scanConfluenceCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanConfluence.BindPFlag("webhook-url", scanConfluenceCmd.Flags().Lookup("webhook-url"))
+	err = viperScanConfluence.BindPFlag("webhook-header", scanConfluenceCmd.Flags().Lookup("webhook-header"))
+	err = viperScanConfluence.BindPFlag("webhook-secret", scanConfluenceCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanConfluence.BindPFlag("csv", scanConfluenceCmd.Flags().Lookup("csv"))
+	err = viperScanConfluence.BindPFlag("triage-file", scanConfluenceCmd.Flags().Lookup("triage-file"))
+	err = viperScanConfluence.BindPFlag("min-severity", scanConfluenceCmd.Flags().Lookup("min-severity"))
+	err = viperScanConfluence.BindPFlag("min-confidence", scanConfluenceCmd.Flags().Lookup("min-confidence"))
+	err = viperScanConfluence.BindPFlag("decode", scanConfluenceCmd.Flags().Lookup("decode"))
+	err = viperScanConfluence.BindPFlag("decode-min-length", scanConfluenceCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanConfluence.BindPFlag("slack-webhook", scanConfluenceCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanConfluence.BindPFlag("slack-token", scanConfluenceCmd.Flags().Lookup("slack-token"))
+	err = viperScanConfluence.BindPFlag("slack-channel", scanConfluenceCmd.Flags().Lookup("slack-channel"))
+	err = viperScanConfluence.BindPFlag("api-scans", scanConfluenceCmd.Flags().Lookup("api-scans"))
+	err = viperScanConfluence.BindPFlag("log-format", scanConfluenceCmd.Flags().Lookup("log-format"))
+	err = viperScanConfluence.BindPFlag("log-file", scanConfluenceCmd.Flags().Lookup("log-file"))
+	err = viperScanConfluence.BindPFlag("gitlab-report", scanConfluenceCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanConfluence.BindPFlag("elasticsearch-url", scanConfluenceCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanConfluence.BindPFlag("elasticsearch-index", scanConfluenceCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanConfluence.BindPFlag("elasticsearch-username", scanConfluenceCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanConfluence.BindPFlag("elasticsearch-password", scanConfluenceCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanConfluence.BindPFlag("elasticsearch-api-key", scanConfluenceCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanConfluence.BindPFlag("splunk-url", scanConfluenceCmd.Flags().Lookup("splunk-url"))
+	err = viperScanConfluence.BindPFlag("splunk-token", scanConfluenceCmd.Flags().Lookup("splunk-token"))
+	err = viperScanConfluence.BindPFlag("splunk-index", scanConfluenceCmd.Flags().Lookup("splunk-index"))
+	err = viperScanConfluence.BindPFlag("splunk-sourcetype", scanConfluenceCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanConfluence.BindPFlag("defectdojo-url", scanConfluenceCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanConfluence.BindPFlag("defectdojo-token", scanConfluenceCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanConfluence.BindPFlag("defectdojo-product", scanConfluenceCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanConfluence.BindPFlag("defectdojo-engagement", scanConfluenceCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanConfluence.BindPFlag("defectdojo-test-title", scanConfluenceCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanConfluence.BindPFlag("defectdojo-close-old", scanConfluenceCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanConfluence.BindPFlag("proxy", scanConfluenceCmd.Flags().Lookup("proxy"))
+	err = viperScanConfluence.BindPFlag("ca-bundle", scanConfluenceCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanConfluence.BindPFlag("insecure-skip-verify", scanConfluenceCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanConfluence.BindPFlag("context-lines", scanConfluenceCmd.Flags().Lookup("context-lines"))
+	err = viperScanConfluence.BindPFlag("dedup-findings", scanConfluenceCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanConfluence.BindPFlag("junit", scanConfluenceCmd.Flags().Lookup("junit"))
+	err = viperScanConfluence.BindPFlag("signature-tags", scanConfluenceCmd.Flags().Lookup("signature-tags"))
+	err = viperScanConfluence.BindPFlag("enable-signatures", scanConfluenceCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanConfluence.BindPFlag("disable-signatures", scanConfluenceCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanConfluence.BindPFlag("encrypt-report", scanConfluenceCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanConfluence.BindPFlag("report-s3", scanConfluenceCmd.Flags().Lookup("report-s3"))
+	err = viperScanConfluence.BindPFlag("report-s3-endpoint", scanConfluenceCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanConfluence.BindPFlag("report-s3-region", scanConfluenceCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanConfluence.BindPFlag("report-s3-sse", scanConfluenceCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanConfluence.BindPFlag("report-s3-kms-key-id", scanConfluenceCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanConfluence.BindPFlag("scan-terraform-state", scanConfluenceCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanConfluence.BindPFlag("scan-binaries", scanConfluenceCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanConfluence.BindPFlag("binary-min-length", scanConfluenceCmd.Flags().Lookup("binary-min-length"))
+	err = viperScanConfluence.BindPFlag("retries", scanConfluenceCmd.Flags().Lookup("retries"))
+	err = viperScanConfluence.BindPFlag("retry-backoff", scanConfluenceCmd.Flags().Lookup("retry-backoff"))
+	err = scanConfluenceCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,280 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanDockerImage *viper.Viper
+
+// scanDockerImageCmd represents the scanDockerImage command that will scan the layers of container images
+var scanDockerImageCmd = &cobra.Command{
+	Use:   "scanDockerImage",
+	Short: "Scan the layers of one or more docker images for secrets.",
+	Long:  `Scan every layer of one or more docker images for secrets, pulled from a registry or saved with docker save, along with the dockerfile instruction that created each layer.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "dockerImage"
+		sess := core.NewSession(viperScanDockerImage, scanType)
+		sess.HandleInterrupts()
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		if err := core.ScanDockerImages(sess); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanDockerImageCmd)
+
+	viperScanDockerImage = core.SetConfig()
+
+	scanDockerImageCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanDockerImageCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanDockerImageCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanDockerImageCmd.Flags().Bool("hide-secrets", false, "Show secrets in any supported output")
+	scanDockerImageCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanDockerImageCmd.Flags().Bool("silent", false, "Suppress all output except for errors")
+	scanDockerImageCmd.Flags().Int64("max-file-size", 50, "Max file size to scan")
+	scanDockerImageCmd.Flags().Int("match-level", 3, "The match level of the expressions used to find matches")
+	scanDockerImageCmd.Flags().String("ignore-extension", "", "a list of extensions to ignore during a scan")
+	scanDockerImageCmd.Flags().String("ignore-path", "", "a list of paths to ignore during a scan")
+	scanDockerImageCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanDockerImageCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanDockerImageCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanDockerImageCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanDockerImageCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanDockerImageCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanDockerImageCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanDockerImageCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanDockerImageCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanDockerImageCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanDockerImageCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanDockerImageCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanDockerImageCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanDockerImageCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanDockerImageCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanDockerImageCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanDockerImageCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanDockerImageCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanDockerImageCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanDockerImageCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanDockerImageCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanDockerImageCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanDockerImageCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanDockerImageCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanDockerImageCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanDockerImageCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanDockerImageCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanDockerImageCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanDockerImageCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanDockerImageCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanDockerImageCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanDockerImageCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanDockerImageCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanDockerImageCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanDockerImageCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanDockerImageCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanDockerImageCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanDockerImageCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanDockerImageCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanDockerImageCmd.Flags().String("docker-images", "", "Images to pull from a registry and scan, such as nginx:1.19 or ghcr.io/acme/api:1.2")
+	scanDockerImageCmd.Flags().String("docker-archives", "", "Images saved with docker save to scan")
+	scanDockerImageCmd.Flags().String("docker-username", "", "Username for registries that require one to pull")
+	scanDockerImageCmd.Flags().String("docker-password", "", "Password or token for registries that require one to pull")
+	scanDockerImageCmd.Flags().String("docker-platform", "linux/amd64", "The os/arch[/variant] to scan when an image is built for more than one platform")
+	scanDockerImageCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanDockerImageCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanDockerImageCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanDockerImageCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanDockerImageCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanDockerImageCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanDockerImageCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanDockerImageCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanDockerImageCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanDockerImageCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanDockerImageCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanDockerImageCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanDockerImageCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanDockerImageCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanDockerImageCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanDockerImageCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanDockerImageCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanDockerImageCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanDockerImageCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanDockerImageCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanDockerImageCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanDockerImageCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanDockerImageCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanDockerImageCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanDockerImageCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanDockerImageCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanDockerImageCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanDockerImageCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanDockerImageCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanDockerImageCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanDockerImageCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanDockerImageCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanDockerImageCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanDockerImageCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanDockerImageCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanDockerImageCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanDockerImageCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanDockerImageCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanDockerImageCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanDockerImageCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanDockerImageCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanDockerImageCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanDockerImageCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanDockerImageCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanDockerImageCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanDockerImageCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanDockerImageCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanDockerImageCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanDockerImageCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanDockerImageCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+	scanDockerImageCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanDockerImageCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+
+	err := viperScanDockerImage.BindPFlag("debug", scanDockerImageCmd.Flags().Lookup("debug"))
+	err = viperScanDockerImage.BindPFlag("debug-server", scanDockerImageCmd.Flags().Lookup("debug-server"))
+	err = viperScanDockerImage.BindPFlag("debug-server-public", scanDockerImageCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanDockerImage.BindPFlag("hide-secrets", scanDockerImageCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanDockerImage.BindPFlag("scan-tests", scanDockerImageCmd.Flags().Lookup("scan-tests"))
+	err = viperScanDockerImage.BindPFlag("silent", scanDockerImageCmd.Flags().Lookup("silent"))
+	err = viperScanDockerImage.BindPFlag("max-file-size", scanDockerImageCmd.Flags().Lookup("max-file-size"))
+	err = viperScanDockerImage.BindPFlag("match-level", scanDockerImageCmd.Flags().Lookup("match-level"))
+	err = viperScanDockerImage.BindPFlag("ignore-extension", scanDockerImageCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanDockerImage.BindPFlag("ignore-path", scanDockerImageCmd.Flags().Lookup("ignore-path"))
+	err = viperScanDockerImage.BindPFlag("signature-file", scanDockerImageCmd.Flags().Lookup("signature-file"))
+	err = viperScanDockerImage.BindPFlag("detector-plugins", scanDockerImageCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanDockerImage.BindPFlag("plugin-timeout", scanDockerImageCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanDockerImage.BindPFlag("wasm-plugin-dir", scanDockerImageCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanDockerImage.BindPFlag("grpc-port", scanDockerImageCmd.Flags().Lookup("grpc-port"))
+	err = viperScanDockerImage.BindPFlag("grpc-token", scanDockerImageCmd.Flags().Lookup("grpc-token"))
+	err = viperScanDockerImage.BindPFlag("grpc-tls-cert", scanDockerImageCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanDockerImage.BindPFlag("grpc-tls-key", scanDockerImageCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanDockerImage.BindPFlag("grpc-client-ca", scanDockerImageCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanDockerImage.BindPFlag("allow-local-scans", scanDockerImageCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanDockerImage.BindPFlag("scan-retention", scanDockerImageCmd.Flags().Lookup("scan-retention"))
+	err = viperScanDockerImage.BindPFlag("json", scanDockerImageCmd.Flags().Lookup("json"))
+	err = viperScanDockerImage.BindPFlag("jsonl", scanDockerImageCmd.Flags().Lookup("jsonl"))
+	err = viperScanDockerImage.BindPFlag("stream", scanDockerImageCmd.Flags().Lookup("stream"))
+	err = viperScanDockerImage.BindPFlag("on-finding-exec", scanDockerImageCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanDockerImage.BindPFlag("finding-script", scanDockerImageCmd.Flags().Lookup("finding-script"))
+	err = viperScanDockerImage.BindPFlag("redact", scanDockerImageCmd.Flags().Lookup("redact"))
+	err = viperScanDockerImage.BindPFlag("history-file", scanDockerImageCmd.Flags().Lookup("history-file"))
+	err = viperScanDockerImage.BindPFlag("alert-state-file", scanDockerImageCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanDockerImage.BindPFlag("realert-interval", scanDockerImageCmd.Flags().Lookup("realert-interval"))
+	err = viperScanDockerImage.BindPFlag("policy", scanDockerImageCmd.Flags().Lookup("policy"))
+	err = viperScanDockerImage.BindPFlag("entropy", scanDockerImageCmd.Flags().Lookup("entropy"))
+	err = viperScanDockerImage.BindPFlag("entropy-base64-threshold", scanDockerImageCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanDockerImage.BindPFlag("entropy-base64-min-length", scanDockerImageCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanDockerImage.BindPFlag("entropy-hex-threshold", scanDockerImageCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanDockerImage.BindPFlag("entropy-hex-min-length", scanDockerImageCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanDockerImage.BindPFlag("baseline", scanDockerImageCmd.Flags().Lookup("baseline"))
+	err = viperScanDockerImage.BindPFlag("db-path", scanDockerImageCmd.Flags().Lookup("db-path"))
+	err = viperScanDockerImage.BindPFlag("verify", scanDockerImageCmd.Flags().Lookup("verify"))
+	err = viperScanDockerImage.BindPFlag("web-username", scanDockerImageCmd.Flags().Lookup("web-username"))
+	err = viperScanDockerImage.BindPFlag("web-password", scanDockerImageCmd.Flags().Lookup("web-password"))
+	err = viperScanDockerImage.BindPFlag("oidc-issuer", scanDockerImageCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanDockerImage.BindPFlag("oidc-client-id", scanDockerImageCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanDockerImage.BindPFlag("oidc-client-secret", scanDockerImageCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanDockerImage.BindPFlag("oidc-redirect-url", scanDockerImageCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanDockerImage.BindPFlag("oidc-allowed-emails", scanDockerImageCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanDockerImage.BindPFlag("ci", scanDockerImageCmd.Flags().Lookup("ci"))
+	err = viperScanDockerImage.BindPFlag("fail-on", scanDockerImageCmd.Flags().Lookup("fail-on"))
+	err = viperScanDockerImage.BindPFlag("report-html", scanDockerImageCmd.Flags().Lookup("report-html"))
+	err = viperScanDockerImage.BindPFlag("docker-images", scanDockerImageCmd.Flags().Lookup("docker-images"))
+	err = viperScanDockerImage.BindPFlag("docker-archives", scanDockerImageCmd.Flags().Lookup("docker-archives"))
+	err = viperScanDockerImage.BindPFlag("docker-username", scanDockerImageCmd.Flags().Lookup("docker-username"))
+	err = viperScanDockerImage.BindPFlag("docker-password", scanDockerImageCmd.Flags().Lookup("docker-password"))
+	err = viperScanDockerImage.BindPFlag("docker-platform", scanDockerImageCmd.Flags().Lookup("docker-platform"))
+	err = viperScanDockerImage.BindPFlag("suppression-markers", scanDockerImageCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanDockerImage.BindPFlag("webhook-url", scanDockerImageCmd.Flags().Lookup("webhook-url"))
+	err = viperScanDockerImage.BindPFlag("webhook-header", scanDockerImageCmd.Flags().Lookup("webhook-header"))
+	err = viperScanDockerImage.BindPFlag("webhook-secret", scanDockerImageCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanDockerImage.BindPFlag("csv", scanDockerImageCmd.Flags().Lookup("csv"))
+	err = viperScanDockerImage.BindPFlag("triage-file", scanDockerImageCmd.Flags().Lookup("triage-file"))
+	err = viperScanDockerImage.BindPFlag("min-severity", scanDockerImageCmd.Flags().Lookup("min-severity"))
+	err = viperScanDockerImage.BindPFlag("min-confidence", scanDockerImageCmd.Flags().Lookup("min-confidence"))
+	err = viperScanDockerImage.BindPFlag("decode", scanDockerImageCmd.Flags().Lookup("decode"))
+	err = viperScanDockerImage.BindPFlag("decode-min-length", scanDockerImageCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanDockerImage.BindPFlag("slack-webhook", scanDockerImageCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanDockerImage.BindPFlag("slack-token", scanDockerImageCmd.Flags().Lookup("slack-token"))
+	err = viperScanDockerImage.BindPFlag("slack-channel", scanDockerImageCmd.Flags().Lookup("slack-channel"))
+	err = viperScanDockerImage.BindPFlag("api-scans", scanDockerImageCmd.Flags().Lookup("api-scans"))
+	err = viperScanDockerImage.BindPFlag("log-format", scanDockerImageCmd.Flags().Lookup("log-format"))
+	err = viperScanDockerImage.BindPFlag("log-file", scanDockerImageCmd.Flags().Lookup("log-file"))
+	err = viperScanDockerImage.BindPFlag("gitlab-report", scanDockerImageCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanDockerImage.BindPFlag("elasticsearch-url", scanDockerImageCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanDockerImage.BindPFlag("elasticsearch-index", scanDockerImageCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanDockerImage.BindPFlag("elasticsearch-username", scanDockerImageCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanDockerImage.BindPFlag("elasticsearch-password", scanDockerImageCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanDockerImage.BindPFlag("elasticsearch-api-key", scanDockerImageCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanDockerImage.BindPFlag("splunk-url", scanDockerImageCmd.Flags().Lookup("splunk-url"))
+	err = viperScanDockerImage.BindPFlag("splunk-token", scanDockerImageCmd.Flags().Lookup("splunk-token"))
+	err = viperScanDockerImage.BindPFlag("splunk-index", scanDockerImageCmd.Flags().Lookup("splunk-index"))
+	err = viperScanDockerImage.BindPFlag("splunk-sourcetype", scanDockerImageCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-url", scanDockerImageCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-token", scanDockerImageCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-product", scanDockerImageCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-engagement", scanDockerImageCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-test-title", scanDockerImageCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanDockerImage.BindPFlag("defectdojo-close-old", scanDockerImageCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanDockerImage.BindPFlag("proxy", scanDockerImageCmd.Flags().Lookup("proxy"))
+	err = viperScanDockerImage.BindPFlag("ca-bundle", scanDockerImageCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanDockerImage.BindPFlag("insecure-skip-verify", scanDockerImageCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanDockerImage.BindPFlag("context-lines", scanDockerImageCmd.Flags().Lookup("context-lines"))
+	err = viperScanDockerImage.BindPFlag("dedup-findings", scanDockerImageCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanDockerImage.BindPFlag("junit", scanDockerImageCmd.Flags().Lookup("junit"))
+	err = viperScanDockerImage.BindPFlag("signature-tags", scanDockerImageCmd.Flags().Lookup("signature-tags"))
+	err = viperScanDockerImage.BindPFlag("enable-signatures", scanDockerImageCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanDockerImage.BindPFlag("disable-signatures", scanDockerImageCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanDockerImage.BindPFlag("encrypt-report", scanDockerImageCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanDockerImage.BindPFlag("report-s3", scanDockerImageCmd.Flags().Lookup("report-s3"))
+	err = viperScanDockerImage.BindPFlag("report-s3-endpoint", scanDockerImageCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanDockerImage.BindPFlag("report-s3-region", scanDockerImageCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanDockerImage.BindPFlag("report-s3-sse", scanDockerImageCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanDockerImage.BindPFlag("report-s3-kms-key-id", scanDockerImageCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanDockerImage.BindPFlag("scan-terraform-state", scanDockerImageCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanDockerImage.BindPFlag("scan-binaries", scanDockerImageCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanDockerImage.BindPFlag("binary-min-length", scanDockerImageCmd.Flags().Lookup("binary-min-length"))
+	err = viperScanDockerImage.BindPFlag("retries", scanDockerImageCmd.Flags().Lookup("retries"))
+	err = viperScanDockerImage.BindPFlag("retry-backoff", scanDockerImageCmd.Flags().Lookup("retry-backoff"))
+	err = scanDockerImageCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
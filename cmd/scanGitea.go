@@ -0,0 +1,375 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanGitea *viper.Viper
+
+// scanGiteaCmd represents the scanGitea command that will enumerate and scan a gitea or forgejo instance
+var scanGiteaCmd = &cobra.Command{
+	Use:   "scanGitea",
+	Short: "Scan one or more Gitea or Forgejo organizations, users or repositories for secrets.",
+	Long:  `Scan the repositories of one or more organizations, users or repositories on a self-hosted Gitea or Forgejo instance for secrets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "gitea"
+		sess := core.NewSession(viperScanGitea, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		core.GatherTargets(sess)
+		core.GatherRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanGiteaCmd)
+
+	viperScanGitea = core.SetConfig()
+
+	scanGiteaCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanGiteaCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanGiteaCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanGiteaCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanGiteaCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanGiteaCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
+	scanGiteaCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanGiteaCmd.Flags().Bool("silent", false, "No output")
+	scanGiteaCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanGiteaCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanGiteaCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanGiteaCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanGiteaCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanGiteaCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanGiteaCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanGiteaCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanGiteaCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanGiteaCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanGiteaCmd.Flags().String("gitea-api-token", "", "An access token with the read:repository, read:organization and read:user scopes")
+	scanGiteaCmd.Flags().String("gitea-targets", "", "A space separated list of Gitea organizations, users, or owner/repository, to scan")
+	scanGiteaCmd.Flags().String("gitea-url", "", "The url of the Gitea or Forgejo instance, such as https://gitea.example.com")
+	scanGiteaCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanGiteaCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanGiteaCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanGiteaCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanGiteaCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanGiteaCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanGiteaCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanGiteaCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanGiteaCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanGiteaCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanGiteaCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanGiteaCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanGiteaCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanGiteaCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanGiteaCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanGiteaCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanGiteaCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanGiteaCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanGiteaCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanGiteaCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanGiteaCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanGiteaCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanGiteaCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanGiteaCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanGiteaCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanGiteaCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanGiteaCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanGiteaCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanGiteaCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanGiteaCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanGiteaCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanGiteaCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanGiteaCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanGiteaCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanGiteaCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanGiteaCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanGiteaCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanGiteaCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanGiteaCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanGiteaCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanGiteaCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanGiteaCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanGiteaCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanGiteaCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanGiteaCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanGiteaCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanGiteaCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanGiteaCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanGiteaCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanGiteaCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanGiteaCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanGiteaCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanGiteaCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanGiteaCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanGiteaCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanGiteaCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanGiteaCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanGiteaCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanGiteaCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanGiteaCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanGiteaCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanGiteaCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanGiteaCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanGiteaCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanGiteaCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanGiteaCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanGiteaCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanGiteaCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanGiteaCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanGiteaCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanGiteaCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanGiteaCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanGiteaCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanGiteaCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanGiteaCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanGiteaCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanGiteaCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanGiteaCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanGiteaCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanGiteaCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanGiteaCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanGiteaCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanGiteaCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanGiteaCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanGiteaCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanGiteaCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanGiteaCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanGiteaCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanGiteaCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanGiteaCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanGiteaCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanGiteaCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanGiteaCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanGiteaCmd.Flags().Bool("expand-members-repos", true, "Gather the repos of the members of organizations")
+	scanGiteaCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanGiteaCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanGiteaCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanGiteaCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanGiteaCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanGiteaCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanGiteaCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanGiteaCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanGiteaCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanGiteaCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanGiteaCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanGiteaCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanGiteaCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanGiteaCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanGiteaCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanGiteaCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanGiteaCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanGiteaCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanGiteaCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanGiteaCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanGiteaCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanGiteaCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanGiteaCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanGiteaCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanGiteaCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanGiteaCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanGiteaCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanGiteaCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanGiteaCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanGiteaCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanGiteaCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanGiteaCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanGiteaCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanGiteaCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanGitea.BindPFlag("bind-address", scanGiteaCmd.Flags().Lookup("bind-address"))
+	err = viperScanGitea.BindPFlag("bind-port", scanGiteaCmd.Flags().Lookup("bind-port"))
+	err = viperScanGitea.BindPFlag("commit-depth", scanGiteaCmd.Flags().Lookup("commit-depth"))
+	err = viperScanGitea.BindPFlag("debug", scanGiteaCmd.Flags().Lookup("debug"))
+	err = viperScanGitea.BindPFlag("debug-server", scanGiteaCmd.Flags().Lookup("debug-server"))
+	err = viperScanGitea.BindPFlag("debug-server-public", scanGiteaCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanGitea.BindPFlag("gitea-targets", scanGiteaCmd.Flags().Lookup("gitea-targets"))
+	err = viperScanGitea.BindPFlag("gitea-api-token", scanGiteaCmd.Flags().Lookup("gitea-api-token"))
+	err = viperScanGitea.BindPFlag("gitea-url", scanGiteaCmd.Flags().Lookup("gitea-url"))
+	err = viperScanGitea.BindPFlag("hide-secrets", scanGiteaCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanGitea.BindPFlag("ignore-extension", scanGiteaCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanGitea.BindPFlag("ignore-path", scanGiteaCmd.Flags().Lookup("ignore-path"))
+	err = viperScanGitea.BindPFlag("path-include", scanGiteaCmd.Flags().Lookup("path-include"))
+	err = viperScanGitea.BindPFlag("path-exclude", scanGiteaCmd.Flags().Lookup("path-exclude"))
+	err = viperScanGitea.BindPFlag("in-mem-clone", scanGiteaCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanGitea.BindPFlag("match-level", scanGiteaCmd.Flags().Lookup("match-level"))
+	err = viperScanGitea.BindPFlag("max-file-size", scanGiteaCmd.Flags().Lookup("max-file-size"))
+	err = viperScanGitea.BindPFlag("no-expand-orgs", scanGiteaCmd.Flags().Lookup("no-expand-orgs"))
+	err = viperScanGitea.BindPFlag("num-threads", scanGiteaCmd.Flags().Lookup("num-threads"))
+	err = viperScanGitea.BindPFlag("clone-threads", scanGiteaCmd.Flags().Lookup("clone-threads"))
+	err = viperScanGitea.BindPFlag("walk-threads", scanGiteaCmd.Flags().Lookup("walk-threads"))
+	err = viperScanGitea.BindPFlag("match-threads", scanGiteaCmd.Flags().Lookup("match-threads"))
+	err = viperScanGitea.BindPFlag("pipeline-queue-size", scanGiteaCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanGitea.BindPFlag("scan-tests", scanGiteaCmd.Flags().Lookup("scan-tests"))
+	err = viperScanGitea.BindPFlag("signature-file", scanGiteaCmd.Flags().Lookup("signature-file"))
+	err = viperScanGitea.BindPFlag("silent", scanGiteaCmd.Flags().Lookup("silent"))
+	err = viperScanGitea.BindPFlag("detector-plugins", scanGiteaCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanGitea.BindPFlag("plugin-timeout", scanGiteaCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanGitea.BindPFlag("wasm-plugin-dir", scanGiteaCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanGitea.BindPFlag("grpc-port", scanGiteaCmd.Flags().Lookup("grpc-port"))
+	err = viperScanGitea.BindPFlag("grpc-token", scanGiteaCmd.Flags().Lookup("grpc-token"))
+	err = viperScanGitea.BindPFlag("grpc-tls-cert", scanGiteaCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanGitea.BindPFlag("grpc-tls-key", scanGiteaCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanGitea.BindPFlag("grpc-client-ca", scanGiteaCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanGitea.BindPFlag("allow-local-scans", scanGiteaCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanGitea.BindPFlag("scan-retention", scanGiteaCmd.Flags().Lookup("scan-retention"))
+	err = viperScanGitea.BindPFlag("json", scanGiteaCmd.Flags().Lookup("json"))
+	err = viperScanGitea.BindPFlag("jsonl", scanGiteaCmd.Flags().Lookup("jsonl"))
+	err = viperScanGitea.BindPFlag("stream", scanGiteaCmd.Flags().Lookup("stream"))
+	err = viperScanGitea.BindPFlag("on-finding-exec", scanGiteaCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanGitea.BindPFlag("on-repo-complete-exec", scanGiteaCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanGitea.BindPFlag("finding-script", scanGiteaCmd.Flags().Lookup("finding-script"))
+	err = viperScanGitea.BindPFlag("redact", scanGiteaCmd.Flags().Lookup("redact"))
+	err = viperScanGitea.BindPFlag("history-file", scanGiteaCmd.Flags().Lookup("history-file"))
+	err = viperScanGitea.BindPFlag("alert-state-file", scanGiteaCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanGitea.BindPFlag("realert-interval", scanGiteaCmd.Flags().Lookup("realert-interval"))
+	err = viperScanGitea.BindPFlag("policy", scanGiteaCmd.Flags().Lookup("policy"))
+	err = viperScanGitea.BindPFlag("entropy", scanGiteaCmd.Flags().Lookup("entropy"))
+	err = viperScanGitea.BindPFlag("entropy-base64-threshold", scanGiteaCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanGitea.BindPFlag("entropy-base64-min-length", scanGiteaCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanGitea.BindPFlag("entropy-hex-threshold", scanGiteaCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanGitea.BindPFlag("entropy-hex-min-length", scanGiteaCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanGitea.BindPFlag("baseline", scanGiteaCmd.Flags().Lookup("baseline"))
+	err = viperScanGitea.BindPFlag("db-path", scanGiteaCmd.Flags().Lookup("db-path"))
+	err = viperScanGitea.BindPFlag("verify", scanGiteaCmd.Flags().Lookup("verify"))
+	err = viperScanGitea.BindPFlag("incremental", scanGiteaCmd.Flags().Lookup("incremental"))
+	err = viperScanGitea.BindPFlag("scan-state-file", scanGiteaCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanGitea.BindPFlag("checkpoint", scanGiteaCmd.Flags().Lookup("checkpoint"))
+	err = viperScanGitea.BindPFlag("checkpoint-interval", scanGiteaCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanGitea.BindPFlag("resume", scanGiteaCmd.Flags().Lookup("resume"))
+	err = viperScanGitea.BindPFlag("web-username", scanGiteaCmd.Flags().Lookup("web-username"))
+	err = viperScanGitea.BindPFlag("web-password", scanGiteaCmd.Flags().Lookup("web-password"))
+	err = viperScanGitea.BindPFlag("oidc-issuer", scanGiteaCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanGitea.BindPFlag("oidc-client-id", scanGiteaCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanGitea.BindPFlag("oidc-client-secret", scanGiteaCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanGitea.BindPFlag("oidc-redirect-url", scanGiteaCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanGitea.BindPFlag("oidc-allowed-emails", scanGiteaCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanGitea.BindPFlag("ci", scanGiteaCmd.Flags().Lookup("ci"))
+	err = viperScanGitea.BindPFlag("fail-on", scanGiteaCmd.Flags().Lookup("fail-on"))
+	err = viperScanGitea.BindPFlag("report-html", scanGiteaCmd.Flags().Lookup("report-html"))
+	err = viperScanGitea.BindPFlag("scan-branches", scanGiteaCmd.Flags().Lookup("scan-branches"))
+	err = viperScanGitea.BindPFlag("since-commit", scanGiteaCmd.Flags().Lookup("since-commit"))
+	err = viperScanGitea.BindPFlag("since-date", scanGiteaCmd.Flags().Lookup("since-date"))
+	err = viperScanGitea.BindPFlag("until-commit", scanGiteaCmd.Flags().Lookup("until-commit"))
+	err = viperScanGitea.BindPFlag("suppression-markers", scanGiteaCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanGitea.BindPFlag("webhook-url", scanGiteaCmd.Flags().Lookup("webhook-url"))
+	err = viperScanGitea.BindPFlag("webhook-header", scanGiteaCmd.Flags().Lookup("webhook-header"))
+	err = viperScanGitea.BindPFlag("webhook-secret", scanGiteaCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanGitea.BindPFlag("scan-commit-messages", scanGiteaCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanGitea.BindPFlag("scan-notes", scanGiteaCmd.Flags().Lookup("scan-notes"))
+	err = viperScanGitea.BindPFlag("scan-submodules", scanGiteaCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanGitea.BindPFlag("submodule-depth", scanGiteaCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanGitea.BindPFlag("csv", scanGiteaCmd.Flags().Lookup("csv"))
+	err = viperScanGitea.BindPFlag("triage-file", scanGiteaCmd.Flags().Lookup("triage-file"))
+	err = viperScanGitea.BindPFlag("min-severity", scanGiteaCmd.Flags().Lookup("min-severity"))
+	err = viperScanGitea.BindPFlag("min-confidence", scanGiteaCmd.Flags().Lookup("min-confidence"))
+	err = viperScanGitea.BindPFlag("queue-url", scanGiteaCmd.Flags().Lookup("queue-url"))
+	err = viperScanGitea.BindPFlag("queue-name", scanGiteaCmd.Flags().Lookup("queue-name"))
+	err = viperScanGitea.BindPFlag("queue-timeout", scanGiteaCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanGitea.BindPFlag("decode", scanGiteaCmd.Flags().Lookup("decode"))
+	err = viperScanGitea.BindPFlag("decode-min-length", scanGiteaCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanGitea.BindPFlag("slack-webhook", scanGiteaCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanGitea.BindPFlag("slack-token", scanGiteaCmd.Flags().Lookup("slack-token"))
+	err = viperScanGitea.BindPFlag("slack-channel", scanGiteaCmd.Flags().Lookup("slack-channel"))
+	err = viperScanGitea.BindPFlag("api-scans", scanGiteaCmd.Flags().Lookup("api-scans"))
+	err = viperScanGitea.BindPFlag("include-repos", scanGiteaCmd.Flags().Lookup("include-repos"))
+	err = viperScanGitea.BindPFlag("exclude-repos", scanGiteaCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanGitea.BindPFlag("exclude-archived", scanGiteaCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanGitea.BindPFlag("only-private", scanGiteaCmd.Flags().Lookup("only-private"))
+	err = viperScanGitea.BindPFlag("only-public", scanGiteaCmd.Flags().Lookup("only-public"))
+	err = viperScanGitea.BindPFlag("language", scanGiteaCmd.Flags().Lookup("language"))
+	err = viperScanGitea.BindPFlag("topic", scanGiteaCmd.Flags().Lookup("topic"))
+	err = viperScanGitea.BindPFlag("pushed-after", scanGiteaCmd.Flags().Lookup("pushed-after"))
+	err = viperScanGitea.BindPFlag("log-format", scanGiteaCmd.Flags().Lookup("log-format"))
+	err = viperScanGitea.BindPFlag("log-file", scanGiteaCmd.Flags().Lookup("log-file"))
+	err = viperScanGitea.BindPFlag("clone-cache", scanGiteaCmd.Flags().Lookup("clone-cache"))
+	err = viperScanGitea.BindPFlag("clone-over-ssh", scanGiteaCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanGitea.BindPFlag("ssh-key", scanGiteaCmd.Flags().Lookup("ssh-key"))
+	err = viperScanGitea.BindPFlag("ssh-key-passphrase", scanGiteaCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanGitea.BindPFlag("ssh-known-hosts", scanGiteaCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanGitea.BindPFlag("retries", scanGiteaCmd.Flags().Lookup("retries"))
+	err = viperScanGitea.BindPFlag("retry-backoff", scanGiteaCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanGitea.BindPFlag("gitlab-report", scanGiteaCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanGitea.BindPFlag("in-mem-clone-budget", scanGiteaCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanGitea.BindPFlag("expand-members-repos", scanGiteaCmd.Flags().Lookup("expand-members-repos"))
+	err = viperScanGitea.BindPFlag("elasticsearch-url", scanGiteaCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanGitea.BindPFlag("elasticsearch-index", scanGiteaCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanGitea.BindPFlag("elasticsearch-username", scanGiteaCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanGitea.BindPFlag("elasticsearch-password", scanGiteaCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanGitea.BindPFlag("elasticsearch-api-key", scanGiteaCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanGitea.BindPFlag("splunk-url", scanGiteaCmd.Flags().Lookup("splunk-url"))
+	err = viperScanGitea.BindPFlag("splunk-token", scanGiteaCmd.Flags().Lookup("splunk-token"))
+	err = viperScanGitea.BindPFlag("splunk-index", scanGiteaCmd.Flags().Lookup("splunk-index"))
+	err = viperScanGitea.BindPFlag("splunk-sourcetype", scanGiteaCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanGitea.BindPFlag("defectdojo-url", scanGiteaCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanGitea.BindPFlag("defectdojo-token", scanGiteaCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanGitea.BindPFlag("defectdojo-product", scanGiteaCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanGitea.BindPFlag("defectdojo-engagement", scanGiteaCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanGitea.BindPFlag("defectdojo-test-title", scanGiteaCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanGitea.BindPFlag("defectdojo-close-old", scanGiteaCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanGitea.BindPFlag("proxy", scanGiteaCmd.Flags().Lookup("proxy"))
+	err = viperScanGitea.BindPFlag("ca-bundle", scanGiteaCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanGitea.BindPFlag("insecure-skip-verify", scanGiteaCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanGitea.BindPFlag("list-targets", scanGiteaCmd.Flags().Lookup("list-targets"))
+	err = viperScanGitea.BindPFlag("context-lines", scanGiteaCmd.Flags().Lookup("context-lines"))
+	err = viperScanGitea.BindPFlag("dedup-findings", scanGiteaCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanGitea.BindPFlag("junit", scanGiteaCmd.Flags().Lookup("junit"))
+	err = viperScanGitea.BindPFlag("signature-tags", scanGiteaCmd.Flags().Lookup("signature-tags"))
+	err = viperScanGitea.BindPFlag("enable-signatures", scanGiteaCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanGitea.BindPFlag("disable-signatures", scanGiteaCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanGitea.BindPFlag("encrypt-report", scanGiteaCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanGitea.BindPFlag("report-s3", scanGiteaCmd.Flags().Lookup("report-s3"))
+	err = viperScanGitea.BindPFlag("report-s3-endpoint", scanGiteaCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanGitea.BindPFlag("report-s3-region", scanGiteaCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanGitea.BindPFlag("report-s3-sse", scanGiteaCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanGitea.BindPFlag("report-s3-kms-key-id", scanGiteaCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanGitea.BindPFlag("scan-terraform-state", scanGiteaCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanGitea.BindPFlag("scan-binaries", scanGiteaCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanGitea.BindPFlag("binary-min-length", scanGiteaCmd.Flags().Lookup("binary-min-length"))
+	err = scanGiteaCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -5,6 +5,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"os"
 	"time"
 	"wraith/core"
 	"wraith/version"
@@ -23,23 +24,42 @@ var scanGithubCmd = &cobra.Command{
 
 		scanType := "github"
 		sess := core.NewSession(viperScanGithub, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
 
 		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
 		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
-		sess.Out.Important("Loaded %d signatures.\n", len(core.Signatures))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
 		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
 
 		core.GatherTargets(sess)
 		core.GatherRepositories(sess)
-		core.AnalyzeRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
 		sess.Finish()
 
-		core.PrintSessionStats(sess)
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
 
-		if !sess.Silent {
+		if !sess.Silent && !sess.Interrupted() {
 			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
 			select {}
 		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
 	},
 }
 
@@ -49,6 +69,8 @@ func init() {
 	viperScanGithub = core.SetConfig()
 
 	scanGithubCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanGithubCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanGithubCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
 	scanGithubCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
 	scanGithubCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
 	scanGithubCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
@@ -59,30 +81,337 @@ func init() {
 	scanGithubCmd.Flags().Int("match-level", 3, "Signature match level")
 	scanGithubCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
 	scanGithubCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanGithubCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanGithubCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanGithubCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanGithubCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
 	scanGithubCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
 	scanGithubCmd.Flags().String("github-api-token", "", "API token for access to github, see doc for necessary scope")
+	scanGithubCmd.Flags().StringSlice("github-api-tokens", []string{}, "API tokens of several accounts to use in turn as their rate limits are used up, in place of github-api-token")
+	scanGithubCmd.Flags().String("github-api-url", "", "The api of a github enterprise instance, such as https://github.example.com/api/v3")
+	scanGithubCmd.Flags().Bool("all-orgs", false, "Scan every organization on the github enterprise instance, which needs a site admin token to read all of their repositories")
+	scanGithubCmd.Flags().Bool("all-users", false, "Scan every user on the github enterprise instance as well")
 	scanGithubCmd.Flags().String("github-targets", "", "A space separated list of github.com users or orgs to scan")
 	scanGithubCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
 	scanGithubCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
-	scanGithubCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "file(s) containing detection signatures.")
+	scanGithubCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanGithubCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanGithubCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanGithubCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanGithubCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanGithubCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanGithubCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanGithubCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanGithubCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanGithubCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanGithubCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanGithubCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanGithubCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanGithubCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanGithubCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanGithubCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanGithubCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanGithubCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanGithubCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanGithubCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanGithubCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanGithubCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanGithubCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanGithubCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanGithubCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanGithubCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanGithubCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanGithubCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanGithubCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanGithubCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanGithubCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanGithubCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanGithubCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanGithubCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanGithubCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanGithubCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanGithubCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanGithubCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanGithubCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanGithubCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanGithubCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanGithubCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanGithubCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanGithubCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanGithubCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanGithubCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanGithubCmd.Flags().Bool("no-gists", false, "Don't scan the gists of users and organization members")
+	scanGithubCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanGithubCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanGithubCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanGithubCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanGithubCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanGithubCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanGithubCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanGithubCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanGithubCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanGithubCmd.Flags().Int64("github-app-id", 0, "The id of a github app to authenticate as instead of using an api token")
+	scanGithubCmd.Flags().String("github-app-private-key", "", "The pem file of the private key of the github app")
+	scanGithubCmd.Flags().Int64("github-installation-id", 0, "The installation of the github app to use, only needed when it is installed more than once")
+	scanGithubCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanGithubCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanGithubCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanGithubCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanGithubCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanGithubCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanGithubCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanGithubCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanGithubCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanGithubCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanGithubCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanGithubCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanGithubCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanGithubCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanGithubCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanGithubCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanGithubCmd.Flags().Bool("file-issues", false, "Open an issue, with the secret redacted, in the repository of each verified finding or finding triaged as confirmed")
+	scanGithubCmd.Flags().String("issue-title-template", "", "A go template for the title of the issues opened with --file-issues")
+	scanGithubCmd.Flags().String("issue-body-template", "", "A file with a go template for the body of the issues opened with --file-issues")
+	scanGithubCmd.Flags().StringSlice("issue-labels", []string{}, "Labels to add to the issues opened with --file-issues")
+	scanGithubCmd.Flags().Bool("issue-public-repos", false, "Also open issues with --file-issues in public repositories, where anyone can read them")
+	scanGithubCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanGithubCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanGithubCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanGithubCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanGithubCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanGithubCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanGithubCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanGithubCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanGithubCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanGithubCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanGithubCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanGithubCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanGithubCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanGithubCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanGithubCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanGithubCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanGithubCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanGithubCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanGithubCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanGithubCmd.Flags().Bool("scan-issues", false, "Scan the issues, pull requests and discussions of each repository and the comments on them")
+	scanGithubCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanGithubCmd.Flags().Bool("expand-members-repos", true, "Gather the repos of the members of organizations")
+	scanGithubCmd.Flags().Bool("expand-members-gists", true, "Gather the gists of the members of organizations")
+	scanGithubCmd.Flags().String("member-affiliation", core.MemberAffiliationOwner, "Which repos of organization members to gather: owner, member or all")
+	scanGithubCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanGithubCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanGithubCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanGithubCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanGithubCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanGithubCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanGithubCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanGithubCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanGithubCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanGithubCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanGithubCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanGithubCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanGithubCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanGithubCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanGithubCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanGithubCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanGithubCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanGithubCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanGithubCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanGithubCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanGithubCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanGithubCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanGithubCmd.Flags().Bool("scan-workflow-logs", false, "Scan the logs of the recent github actions workflow runs of each repository")
+	scanGithubCmd.Flags().Int("workflow-log-runs", 10, "The number of the most recent workflow runs of each repository to scan the logs of with --scan-workflow-logs")
+	scanGithubCmd.Flags().Bool("scan-release-assets", false, "Download and scan the assets of the recent releases of each repository, opening the archives among them")
+	scanGithubCmd.Flags().Int("release-count", 5, "The number of the most recent releases of each repository to scan the assets of with --scan-release-assets")
+	scanGithubCmd.Flags().Int64("release-asset-max-size", 100, "Max size in MB of a release asset to download with --scan-release-assets")
+	scanGithubCmd.Flags().Int("archive-depth", 2, "How many archives deep to look inside zip, jar and tar files, 0 to not open archives")
+	scanGithubCmd.Flags().Int64("archive-max-size", 100, "Max size in MB of the files to take out of a single archive")
+	scanGithubCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanGithubCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanGithubCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanGithubCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanGithubCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanGithubCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanGithubCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanGithubCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanGithubCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanGithubCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanGithubCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanGithubCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
 
 	err := viperScanGithub.BindPFlag("bind-address", scanGithubCmd.Flags().Lookup("bind-address"))
 	err = viperScanGithub.BindPFlag("bind-port", scanGithubCmd.Flags().Lookup("bind-port"))
 	err = viperScanGithub.BindPFlag("commit-depth", scanGithubCmd.Flags().Lookup("commit-depth"))
 	err = viperScanGithub.BindPFlag("debug", scanGithubCmd.Flags().Lookup("debug"))
+	err = viperScanGithub.BindPFlag("debug-server", scanGithubCmd.Flags().Lookup("debug-server"))
+	err = viperScanGithub.BindPFlag("debug-server-public", scanGithubCmd.Flags().Lookup("debug-server-public"))
 	err = viperScanGithub.BindPFlag("github-api-token", scanGithubCmd.Flags().Lookup("github-api-token"))
+	err = viperScanGithub.BindPFlag("github-api-tokens", scanGithubCmd.Flags().Lookup("github-api-tokens"))
+	err = viperScanGithub.BindPFlag("github-api-url", scanGithubCmd.Flags().Lookup("github-api-url"))
+	err = viperScanGithub.BindPFlag("all-orgs", scanGithubCmd.Flags().Lookup("all-orgs"))
+	err = viperScanGithub.BindPFlag("all-users", scanGithubCmd.Flags().Lookup("all-users"))
 	err = viperScanGithub.BindPFlag("github-targets", scanGithubCmd.Flags().Lookup("github-targets"))
 	err = viperScanGithub.BindPFlag("hide-secrets", scanGithubCmd.Flags().Lookup("hide-secrets"))
 	err = viperScanGithub.BindPFlag("ignore-extension", scanGithubCmd.Flags().Lookup("ignore-extension"))
 	err = viperScanGithub.BindPFlag("ignore-path", scanGithubCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanGithub.BindPFlag("path-include", scanGithubCmd.Flags().Lookup("path-include"))
+	err = viperScanGithub.BindPFlag("path-exclude", scanGithubCmd.Flags().Lookup("path-exclude"))
 	err = viperScanGithub.BindPFlag("in-mem-clone", scanGithubCmd.Flags().Lookup("in-mem-clone"))
 	err = viperScanGithub.BindPFlag("match-level", scanGithubCmd.Flags().Lookup("match-level"))
 	err = viperScanGithub.BindPFlag("max-file-size", scanGithubCmd.Flags().Lookup("max-file-size"))
 	err = viperScanGithub.BindPFlag("no-expand-orgs", scanGithubCmd.Flags().Lookup("no-expand-orgs"))
 	err = viperScanGithub.BindPFlag("num-threads", scanGithubCmd.Flags().Lookup("num-threads"))
+	err = viperScanGithub.BindPFlag("clone-threads", scanGithubCmd.Flags().Lookup("clone-threads"))
+	err = viperScanGithub.BindPFlag("walk-threads", scanGithubCmd.Flags().Lookup("walk-threads"))
+	err = viperScanGithub.BindPFlag("match-threads", scanGithubCmd.Flags().Lookup("match-threads"))
+	err = viperScanGithub.BindPFlag("pipeline-queue-size", scanGithubCmd.Flags().Lookup("pipeline-queue-size"))
 	err = viperScanGithub.BindPFlag("scan-tests", scanGithubCmd.Flags().Lookup("scan-tests"))
 	err = viperScanGithub.BindPFlag("signature-file", scanGithubCmd.Flags().Lookup("signature-file"))
 	err = viperScanGithub.BindPFlag("silent", scanGithubCmd.Flags().Lookup("silent"))
+	err = viperScanGithub.BindPFlag("detector-plugins", scanGithubCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanGithub.BindPFlag("plugin-timeout", scanGithubCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanGithub.BindPFlag("wasm-plugin-dir", scanGithubCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanGithub.BindPFlag("grpc-port", scanGithubCmd.Flags().Lookup("grpc-port"))
+	err = viperScanGithub.BindPFlag("grpc-token", scanGithubCmd.Flags().Lookup("grpc-token"))
+	err = viperScanGithub.BindPFlag("grpc-tls-cert", scanGithubCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanGithub.BindPFlag("grpc-tls-key", scanGithubCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanGithub.BindPFlag("grpc-client-ca", scanGithubCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanGithub.BindPFlag("allow-local-scans", scanGithubCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanGithub.BindPFlag("scan-retention", scanGithubCmd.Flags().Lookup("scan-retention"))
+	err = viperScanGithub.BindPFlag("json", scanGithubCmd.Flags().Lookup("json"))
+	err = viperScanGithub.BindPFlag("jsonl", scanGithubCmd.Flags().Lookup("jsonl"))
+	err = viperScanGithub.BindPFlag("stream", scanGithubCmd.Flags().Lookup("stream"))
+	err = viperScanGithub.BindPFlag("on-finding-exec", scanGithubCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanGithub.BindPFlag("on-repo-complete-exec", scanGithubCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanGithub.BindPFlag("finding-script", scanGithubCmd.Flags().Lookup("finding-script"))
+	err = viperScanGithub.BindPFlag("redact", scanGithubCmd.Flags().Lookup("redact"))
+	err = viperScanGithub.BindPFlag("history-file", scanGithubCmd.Flags().Lookup("history-file"))
+	err = viperScanGithub.BindPFlag("alert-state-file", scanGithubCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanGithub.BindPFlag("realert-interval", scanGithubCmd.Flags().Lookup("realert-interval"))
+	err = viperScanGithub.BindPFlag("policy", scanGithubCmd.Flags().Lookup("policy"))
+	err = viperScanGithub.BindPFlag("entropy", scanGithubCmd.Flags().Lookup("entropy"))
+	err = viperScanGithub.BindPFlag("entropy-base64-threshold", scanGithubCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanGithub.BindPFlag("entropy-base64-min-length", scanGithubCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanGithub.BindPFlag("entropy-hex-threshold", scanGithubCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanGithub.BindPFlag("entropy-hex-min-length", scanGithubCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanGithub.BindPFlag("baseline", scanGithubCmd.Flags().Lookup("baseline"))
+	err = viperScanGithub.BindPFlag("db-path", scanGithubCmd.Flags().Lookup("db-path"))
+	err = viperScanGithub.BindPFlag("verify", scanGithubCmd.Flags().Lookup("verify"))
+	err = viperScanGithub.BindPFlag("incremental", scanGithubCmd.Flags().Lookup("incremental"))
+	err = viperScanGithub.BindPFlag("scan-state-file", scanGithubCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanGithub.BindPFlag("checkpoint", scanGithubCmd.Flags().Lookup("checkpoint"))
+	err = viperScanGithub.BindPFlag("checkpoint-interval", scanGithubCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanGithub.BindPFlag("resume", scanGithubCmd.Flags().Lookup("resume"))
+	err = viperScanGithub.BindPFlag("web-username", scanGithubCmd.Flags().Lookup("web-username"))
+	err = viperScanGithub.BindPFlag("web-password", scanGithubCmd.Flags().Lookup("web-password"))
+	err = viperScanGithub.BindPFlag("oidc-issuer", scanGithubCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanGithub.BindPFlag("oidc-client-id", scanGithubCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanGithub.BindPFlag("oidc-client-secret", scanGithubCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanGithub.BindPFlag("oidc-redirect-url", scanGithubCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanGithub.BindPFlag("oidc-allowed-emails", scanGithubCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanGithub.BindPFlag("ci", scanGithubCmd.Flags().Lookup("ci"))
+	err = viperScanGithub.BindPFlag("fail-on", scanGithubCmd.Flags().Lookup("fail-on"))
+	err = viperScanGithub.BindPFlag("no-gists", scanGithubCmd.Flags().Lookup("no-gists"))
+	err = viperScanGithub.BindPFlag("report-html", scanGithubCmd.Flags().Lookup("report-html"))
+	err = viperScanGithub.BindPFlag("scan-branches", scanGithubCmd.Flags().Lookup("scan-branches"))
+	err = viperScanGithub.BindPFlag("since-commit", scanGithubCmd.Flags().Lookup("since-commit"))
+	err = viperScanGithub.BindPFlag("since-date", scanGithubCmd.Flags().Lookup("since-date"))
+	err = viperScanGithub.BindPFlag("until-commit", scanGithubCmd.Flags().Lookup("until-commit"))
+	err = viperScanGithub.BindPFlag("suppression-markers", scanGithubCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanGithub.BindPFlag("webhook-url", scanGithubCmd.Flags().Lookup("webhook-url"))
+	err = viperScanGithub.BindPFlag("webhook-header", scanGithubCmd.Flags().Lookup("webhook-header"))
+	err = viperScanGithub.BindPFlag("webhook-secret", scanGithubCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanGithub.BindPFlag("github-app-id", scanGithubCmd.Flags().Lookup("github-app-id"))
+	err = viperScanGithub.BindPFlag("github-app-private-key", scanGithubCmd.Flags().Lookup("github-app-private-key"))
+	err = viperScanGithub.BindPFlag("github-installation-id", scanGithubCmd.Flags().Lookup("github-installation-id"))
+	err = viperScanGithub.BindPFlag("scan-commit-messages", scanGithubCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanGithub.BindPFlag("scan-notes", scanGithubCmd.Flags().Lookup("scan-notes"))
+	err = viperScanGithub.BindPFlag("scan-submodules", scanGithubCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanGithub.BindPFlag("submodule-depth", scanGithubCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanGithub.BindPFlag("csv", scanGithubCmd.Flags().Lookup("csv"))
+	err = viperScanGithub.BindPFlag("triage-file", scanGithubCmd.Flags().Lookup("triage-file"))
+	err = viperScanGithub.BindPFlag("min-severity", scanGithubCmd.Flags().Lookup("min-severity"))
+	err = viperScanGithub.BindPFlag("min-confidence", scanGithubCmd.Flags().Lookup("min-confidence"))
+	err = viperScanGithub.BindPFlag("queue-url", scanGithubCmd.Flags().Lookup("queue-url"))
+	err = viperScanGithub.BindPFlag("queue-name", scanGithubCmd.Flags().Lookup("queue-name"))
+	err = viperScanGithub.BindPFlag("queue-timeout", scanGithubCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanGithub.BindPFlag("decode", scanGithubCmd.Flags().Lookup("decode"))
+	err = viperScanGithub.BindPFlag("decode-min-length", scanGithubCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanGithub.BindPFlag("slack-webhook", scanGithubCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanGithub.BindPFlag("slack-token", scanGithubCmd.Flags().Lookup("slack-token"))
+	err = viperScanGithub.BindPFlag("slack-channel", scanGithubCmd.Flags().Lookup("slack-channel"))
+	err = viperScanGithub.BindPFlag("file-issues", scanGithubCmd.Flags().Lookup("file-issues"))
+	err = viperScanGithub.BindPFlag("issue-title-template", scanGithubCmd.Flags().Lookup("issue-title-template"))
+	err = viperScanGithub.BindPFlag("issue-body-template", scanGithubCmd.Flags().Lookup("issue-body-template"))
+	err = viperScanGithub.BindPFlag("issue-labels", scanGithubCmd.Flags().Lookup("issue-labels"))
+	err = viperScanGithub.BindPFlag("issue-public-repos", scanGithubCmd.Flags().Lookup("issue-public-repos"))
+	err = viperScanGithub.BindPFlag("api-scans", scanGithubCmd.Flags().Lookup("api-scans"))
+	err = viperScanGithub.BindPFlag("include-repos", scanGithubCmd.Flags().Lookup("include-repos"))
+	err = viperScanGithub.BindPFlag("exclude-repos", scanGithubCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanGithub.BindPFlag("exclude-archived", scanGithubCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanGithub.BindPFlag("only-private", scanGithubCmd.Flags().Lookup("only-private"))
+	err = viperScanGithub.BindPFlag("only-public", scanGithubCmd.Flags().Lookup("only-public"))
+	err = viperScanGithub.BindPFlag("language", scanGithubCmd.Flags().Lookup("language"))
+	err = viperScanGithub.BindPFlag("topic", scanGithubCmd.Flags().Lookup("topic"))
+	err = viperScanGithub.BindPFlag("pushed-after", scanGithubCmd.Flags().Lookup("pushed-after"))
+	err = viperScanGithub.BindPFlag("log-format", scanGithubCmd.Flags().Lookup("log-format"))
+	err = viperScanGithub.BindPFlag("log-file", scanGithubCmd.Flags().Lookup("log-file"))
+	err = viperScanGithub.BindPFlag("clone-cache", scanGithubCmd.Flags().Lookup("clone-cache"))
+	err = viperScanGithub.BindPFlag("clone-over-ssh", scanGithubCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanGithub.BindPFlag("ssh-key", scanGithubCmd.Flags().Lookup("ssh-key"))
+	err = viperScanGithub.BindPFlag("ssh-key-passphrase", scanGithubCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanGithub.BindPFlag("ssh-known-hosts", scanGithubCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanGithub.BindPFlag("retries", scanGithubCmd.Flags().Lookup("retries"))
+	err = viperScanGithub.BindPFlag("retry-backoff", scanGithubCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanGithub.BindPFlag("gitlab-report", scanGithubCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanGithub.BindPFlag("scan-issues", scanGithubCmd.Flags().Lookup("scan-issues"))
+	err = viperScanGithub.BindPFlag("in-mem-clone-budget", scanGithubCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanGithub.BindPFlag("expand-members-repos", scanGithubCmd.Flags().Lookup("expand-members-repos"))
+	err = viperScanGithub.BindPFlag("expand-members-gists", scanGithubCmd.Flags().Lookup("expand-members-gists"))
+	err = viperScanGithub.BindPFlag("member-affiliation", scanGithubCmd.Flags().Lookup("member-affiliation"))
+	err = viperScanGithub.BindPFlag("elasticsearch-url", scanGithubCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanGithub.BindPFlag("elasticsearch-index", scanGithubCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanGithub.BindPFlag("elasticsearch-username", scanGithubCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanGithub.BindPFlag("elasticsearch-password", scanGithubCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanGithub.BindPFlag("elasticsearch-api-key", scanGithubCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanGithub.BindPFlag("splunk-url", scanGithubCmd.Flags().Lookup("splunk-url"))
+	err = viperScanGithub.BindPFlag("splunk-token", scanGithubCmd.Flags().Lookup("splunk-token"))
+	err = viperScanGithub.BindPFlag("splunk-index", scanGithubCmd.Flags().Lookup("splunk-index"))
+	err = viperScanGithub.BindPFlag("splunk-sourcetype", scanGithubCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanGithub.BindPFlag("defectdojo-url", scanGithubCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanGithub.BindPFlag("defectdojo-token", scanGithubCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanGithub.BindPFlag("defectdojo-product", scanGithubCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanGithub.BindPFlag("defectdojo-engagement", scanGithubCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanGithub.BindPFlag("defectdojo-test-title", scanGithubCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanGithub.BindPFlag("defectdojo-close-old", scanGithubCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanGithub.BindPFlag("proxy", scanGithubCmd.Flags().Lookup("proxy"))
+	err = viperScanGithub.BindPFlag("ca-bundle", scanGithubCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanGithub.BindPFlag("insecure-skip-verify", scanGithubCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanGithub.BindPFlag("list-targets", scanGithubCmd.Flags().Lookup("list-targets"))
+	err = viperScanGithub.BindPFlag("context-lines", scanGithubCmd.Flags().Lookup("context-lines"))
+	err = viperScanGithub.BindPFlag("dedup-findings", scanGithubCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanGithub.BindPFlag("junit", scanGithubCmd.Flags().Lookup("junit"))
+	err = viperScanGithub.BindPFlag("scan-workflow-logs", scanGithubCmd.Flags().Lookup("scan-workflow-logs"))
+	err = viperScanGithub.BindPFlag("workflow-log-runs", scanGithubCmd.Flags().Lookup("workflow-log-runs"))
+	err = viperScanGithub.BindPFlag("scan-release-assets", scanGithubCmd.Flags().Lookup("scan-release-assets"))
+	err = viperScanGithub.BindPFlag("release-count", scanGithubCmd.Flags().Lookup("release-count"))
+	err = viperScanGithub.BindPFlag("release-asset-max-size", scanGithubCmd.Flags().Lookup("release-asset-max-size"))
+	err = viperScanGithub.BindPFlag("archive-depth", scanGithubCmd.Flags().Lookup("archive-depth"))
+	err = viperScanGithub.BindPFlag("archive-max-size", scanGithubCmd.Flags().Lookup("archive-max-size"))
+	err = viperScanGithub.BindPFlag("signature-tags", scanGithubCmd.Flags().Lookup("signature-tags"))
+	err = viperScanGithub.BindPFlag("enable-signatures", scanGithubCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanGithub.BindPFlag("disable-signatures", scanGithubCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanGithub.BindPFlag("encrypt-report", scanGithubCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanGithub.BindPFlag("report-s3", scanGithubCmd.Flags().Lookup("report-s3"))
+	err = viperScanGithub.BindPFlag("report-s3-endpoint", scanGithubCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanGithub.BindPFlag("report-s3-region", scanGithubCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanGithub.BindPFlag("report-s3-sse", scanGithubCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanGithub.BindPFlag("report-s3-kms-key-id", scanGithubCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanGithub.BindPFlag("scan-terraform-state", scanGithubCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanGithub.BindPFlag("scan-binaries", scanGithubCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanGithub.BindPFlag("binary-min-length", scanGithubCmd.Flags().Lookup("binary-min-length"))
+	err = scanGithubCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
 
 	if err != nil {
 		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
@@ -0,0 +1,299 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanGithubPR *viper.Viper
+
+// scanGithubPRCmd represents the scanGithubPR command that will scan the lines added by github.com pull requests
+var scanGithubPRCmd = &cobra.Command{
+	Use:   "scanGithubPR",
+	Short: "Scan the lines added by one or more github.com pull requests for secrets.",
+	Long:  `Scan the lines added by one or more github.com pull requests for secrets, without cloning the repository.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "githubPR"
+		sess := core.NewSession(viperScanGithubPR, scanType)
+		sess.HandleInterrupts()
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		if err := core.ScanGithubPullRequests(sess); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanGithubPRCmd)
+
+	viperScanGithubPR = core.SetConfig()
+
+	scanGithubPRCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanGithubPRCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanGithubPRCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanGithubPRCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanGithubPRCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanGithubPRCmd.Flags().Bool("silent", false, "No output")
+	scanGithubPRCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanGithubPRCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanGithubPRCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanGithubPRCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanGithubPRCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanGithubPRCmd.Flags().String("github-api-token", "", "API token for access to github, see doc for necessary scope")
+	scanGithubPRCmd.Flags().StringSlice("github-api-tokens", []string{}, "API tokens of several accounts to use in turn as their rate limits are used up, in place of github-api-token")
+	scanGithubPRCmd.Flags().String("github-api-url", "", "The api of a github enterprise instance, such as https://github.example.com/api/v3")
+	scanGithubPRCmd.Flags().String("github-pull-requests", "", "A space separated list of pull requests to scan as owner/repo#number or their url")
+	scanGithubPRCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanGithubPRCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanGithubPRCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanGithubPRCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanGithubPRCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanGithubPRCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanGithubPRCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanGithubPRCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanGithubPRCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanGithubPRCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanGithubPRCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanGithubPRCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanGithubPRCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanGithubPRCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanGithubPRCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanGithubPRCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanGithubPRCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanGithubPRCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanGithubPRCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanGithubPRCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanGithubPRCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanGithubPRCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanGithubPRCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanGithubPRCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanGithubPRCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanGithubPRCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanGithubPRCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanGithubPRCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanGithubPRCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanGithubPRCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanGithubPRCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanGithubPRCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanGithubPRCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanGithubPRCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanGithubPRCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanGithubPRCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanGithubPRCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanGithubPRCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanGithubPRCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanGithubPRCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanGithubPRCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanGithubPRCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanGithubPRCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanGithubPRCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanGithubPRCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanGithubPRCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanGithubPRCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanGithubPRCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanGithubPRCmd.Flags().Int64("github-app-id", 0, "The id of a github app to authenticate as instead of using an api token")
+	scanGithubPRCmd.Flags().String("github-app-private-key", "", "The pem file of the private key of the github app")
+	scanGithubPRCmd.Flags().Int64("github-installation-id", 0, "The installation of the github app to use, only needed when it is installed more than once")
+	scanGithubPRCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanGithubPRCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanGithubPRCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanGithubPRCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanGithubPRCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanGithubPRCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanGithubPRCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanGithubPRCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanGithubPRCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanGithubPRCmd.Flags().Bool("file-issues", false, "Open an issue, with the secret redacted, in the repository of each verified finding or finding triaged as confirmed")
+	scanGithubPRCmd.Flags().String("issue-title-template", "", "A go template for the title of the issues opened with --file-issues")
+	scanGithubPRCmd.Flags().String("issue-body-template", "", "A file with a go template for the body of the issues opened with --file-issues")
+	scanGithubPRCmd.Flags().StringSlice("issue-labels", []string{}, "Labels to add to the issues opened with --file-issues")
+	scanGithubPRCmd.Flags().Bool("issue-public-repos", false, "Also open issues with --file-issues in public repositories, where anyone can read them")
+	scanGithubPRCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanGithubPRCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanGithubPRCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanGithubPRCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanGithubPRCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanGithubPRCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanGithubPRCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanGithubPRCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanGithubPRCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanGithubPRCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanGithubPRCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanGithubPRCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanGithubPRCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanGithubPRCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanGithubPRCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanGithubPRCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanGithubPRCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanGithubPRCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanGithubPRCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanGithubPRCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanGithubPRCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanGithubPRCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanGithubPRCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanGithubPRCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanGithubPRCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanGithubPRCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanGithubPRCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanGithubPRCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanGithubPRCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanGithubPRCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanGithubPRCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanGithubPRCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanGithubPRCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanGithubPRCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanGithubPRCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanGithubPRCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+
+	err := viperScanGithubPR.BindPFlag("bind-address", scanGithubPRCmd.Flags().Lookup("bind-address"))
+	err = viperScanGithubPR.BindPFlag("bind-port", scanGithubPRCmd.Flags().Lookup("bind-port"))
+	err = viperScanGithubPR.BindPFlag("debug", scanGithubPRCmd.Flags().Lookup("debug"))
+	err = viperScanGithubPR.BindPFlag("debug-server", scanGithubPRCmd.Flags().Lookup("debug-server"))
+	err = viperScanGithubPR.BindPFlag("debug-server-public", scanGithubPRCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanGithubPR.BindPFlag("github-api-token", scanGithubPRCmd.Flags().Lookup("github-api-token"))
+	err = viperScanGithubPR.BindPFlag("github-api-tokens", scanGithubPRCmd.Flags().Lookup("github-api-tokens"))
+	err = viperScanGithubPR.BindPFlag("github-api-url", scanGithubPRCmd.Flags().Lookup("github-api-url"))
+	err = viperScanGithubPR.BindPFlag("github-pull-requests", scanGithubPRCmd.Flags().Lookup("github-pull-requests"))
+	err = viperScanGithubPR.BindPFlag("hide-secrets", scanGithubPRCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanGithubPR.BindPFlag("ignore-extension", scanGithubPRCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanGithubPR.BindPFlag("ignore-path", scanGithubPRCmd.Flags().Lookup("ignore-path"))
+	err = viperScanGithubPR.BindPFlag("path-include", scanGithubPRCmd.Flags().Lookup("path-include"))
+	err = viperScanGithubPR.BindPFlag("path-exclude", scanGithubPRCmd.Flags().Lookup("path-exclude"))
+	err = viperScanGithubPR.BindPFlag("match-level", scanGithubPRCmd.Flags().Lookup("match-level"))
+	err = viperScanGithubPR.BindPFlag("max-file-size", scanGithubPRCmd.Flags().Lookup("max-file-size"))
+	err = viperScanGithubPR.BindPFlag("num-threads", scanGithubPRCmd.Flags().Lookup("num-threads"))
+	err = viperScanGithubPR.BindPFlag("scan-tests", scanGithubPRCmd.Flags().Lookup("scan-tests"))
+	err = viperScanGithubPR.BindPFlag("signature-file", scanGithubPRCmd.Flags().Lookup("signature-file"))
+	err = viperScanGithubPR.BindPFlag("silent", scanGithubPRCmd.Flags().Lookup("silent"))
+	err = viperScanGithubPR.BindPFlag("detector-plugins", scanGithubPRCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanGithubPR.BindPFlag("plugin-timeout", scanGithubPRCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanGithubPR.BindPFlag("wasm-plugin-dir", scanGithubPRCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanGithubPR.BindPFlag("grpc-port", scanGithubPRCmd.Flags().Lookup("grpc-port"))
+	err = viperScanGithubPR.BindPFlag("grpc-token", scanGithubPRCmd.Flags().Lookup("grpc-token"))
+	err = viperScanGithubPR.BindPFlag("grpc-tls-cert", scanGithubPRCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanGithubPR.BindPFlag("grpc-tls-key", scanGithubPRCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanGithubPR.BindPFlag("grpc-client-ca", scanGithubPRCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanGithubPR.BindPFlag("allow-local-scans", scanGithubPRCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanGithubPR.BindPFlag("scan-retention", scanGithubPRCmd.Flags().Lookup("scan-retention"))
+	err = viperScanGithubPR.BindPFlag("json", scanGithubPRCmd.Flags().Lookup("json"))
+	err = viperScanGithubPR.BindPFlag("jsonl", scanGithubPRCmd.Flags().Lookup("jsonl"))
+	err = viperScanGithubPR.BindPFlag("stream", scanGithubPRCmd.Flags().Lookup("stream"))
+	err = viperScanGithubPR.BindPFlag("on-finding-exec", scanGithubPRCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanGithubPR.BindPFlag("on-repo-complete-exec", scanGithubPRCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanGithubPR.BindPFlag("finding-script", scanGithubPRCmd.Flags().Lookup("finding-script"))
+	err = viperScanGithubPR.BindPFlag("redact", scanGithubPRCmd.Flags().Lookup("redact"))
+	err = viperScanGithubPR.BindPFlag("history-file", scanGithubPRCmd.Flags().Lookup("history-file"))
+	err = viperScanGithubPR.BindPFlag("alert-state-file", scanGithubPRCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanGithubPR.BindPFlag("realert-interval", scanGithubPRCmd.Flags().Lookup("realert-interval"))
+	err = viperScanGithubPR.BindPFlag("policy", scanGithubPRCmd.Flags().Lookup("policy"))
+	err = viperScanGithubPR.BindPFlag("entropy", scanGithubPRCmd.Flags().Lookup("entropy"))
+	err = viperScanGithubPR.BindPFlag("entropy-base64-threshold", scanGithubPRCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanGithubPR.BindPFlag("entropy-base64-min-length", scanGithubPRCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanGithubPR.BindPFlag("entropy-hex-threshold", scanGithubPRCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanGithubPR.BindPFlag("entropy-hex-min-length", scanGithubPRCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanGithubPR.BindPFlag("baseline", scanGithubPRCmd.Flags().Lookup("baseline"))
+	err = viperScanGithubPR.BindPFlag("db-path", scanGithubPRCmd.Flags().Lookup("db-path"))
+	err = viperScanGithubPR.BindPFlag("verify", scanGithubPRCmd.Flags().Lookup("verify"))
+	err = viperScanGithubPR.BindPFlag("web-username", scanGithubPRCmd.Flags().Lookup("web-username"))
+	err = viperScanGithubPR.BindPFlag("web-password", scanGithubPRCmd.Flags().Lookup("web-password"))
+	err = viperScanGithubPR.BindPFlag("oidc-issuer", scanGithubPRCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanGithubPR.BindPFlag("oidc-client-id", scanGithubPRCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanGithubPR.BindPFlag("oidc-client-secret", scanGithubPRCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanGithubPR.BindPFlag("oidc-redirect-url", scanGithubPRCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanGithubPR.BindPFlag("oidc-allowed-emails", scanGithubPRCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanGithubPR.BindPFlag("ci", scanGithubPRCmd.Flags().Lookup("ci"))
+	err = viperScanGithubPR.BindPFlag("fail-on", scanGithubPRCmd.Flags().Lookup("fail-on"))
+	err = viperScanGithubPR.BindPFlag("report-html", scanGithubPRCmd.Flags().Lookup("report-html"))
+	err = viperScanGithubPR.BindPFlag("suppression-markers", scanGithubPRCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanGithubPR.BindPFlag("webhook-url", scanGithubPRCmd.Flags().Lookup("webhook-url"))
+	err = viperScanGithubPR.BindPFlag("webhook-header", scanGithubPRCmd.Flags().Lookup("webhook-header"))
+	err = viperScanGithubPR.BindPFlag("webhook-secret", scanGithubPRCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanGithubPR.BindPFlag("github-app-id", scanGithubPRCmd.Flags().Lookup("github-app-id"))
+	err = viperScanGithubPR.BindPFlag("github-app-private-key", scanGithubPRCmd.Flags().Lookup("github-app-private-key"))
+	err = viperScanGithubPR.BindPFlag("github-installation-id", scanGithubPRCmd.Flags().Lookup("github-installation-id"))
+	err = viperScanGithubPR.BindPFlag("csv", scanGithubPRCmd.Flags().Lookup("csv"))
+	err = viperScanGithubPR.BindPFlag("triage-file", scanGithubPRCmd.Flags().Lookup("triage-file"))
+	err = viperScanGithubPR.BindPFlag("min-severity", scanGithubPRCmd.Flags().Lookup("min-severity"))
+	err = viperScanGithubPR.BindPFlag("min-confidence", scanGithubPRCmd.Flags().Lookup("min-confidence"))
+	err = viperScanGithubPR.BindPFlag("decode", scanGithubPRCmd.Flags().Lookup("decode"))
+	err = viperScanGithubPR.BindPFlag("decode-min-length", scanGithubPRCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanGithubPR.BindPFlag("slack-webhook", scanGithubPRCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanGithubPR.BindPFlag("slack-token", scanGithubPRCmd.Flags().Lookup("slack-token"))
+	err = viperScanGithubPR.BindPFlag("slack-channel", scanGithubPRCmd.Flags().Lookup("slack-channel"))
+	err = viperScanGithubPR.BindPFlag("file-issues", scanGithubPRCmd.Flags().Lookup("file-issues"))
+	err = viperScanGithubPR.BindPFlag("issue-title-template", scanGithubPRCmd.Flags().Lookup("issue-title-template"))
+	err = viperScanGithubPR.BindPFlag("issue-body-template", scanGithubPRCmd.Flags().Lookup("issue-body-template"))
+	err = viperScanGithubPR.BindPFlag("issue-labels", scanGithubPRCmd.Flags().Lookup("issue-labels"))
+	err = viperScanGithubPR.BindPFlag("issue-public-repos", scanGithubPRCmd.Flags().Lookup("issue-public-repos"))
+	err = viperScanGithubPR.BindPFlag("api-scans", scanGithubPRCmd.Flags().Lookup("api-scans"))
+	err = viperScanGithubPR.BindPFlag("log-format", scanGithubPRCmd.Flags().Lookup("log-format"))
+	err = viperScanGithubPR.BindPFlag("log-file", scanGithubPRCmd.Flags().Lookup("log-file"))
+	err = viperScanGithubPR.BindPFlag("gitlab-report", scanGithubPRCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanGithubPR.BindPFlag("elasticsearch-url", scanGithubPRCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanGithubPR.BindPFlag("elasticsearch-index", scanGithubPRCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanGithubPR.BindPFlag("elasticsearch-username", scanGithubPRCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanGithubPR.BindPFlag("elasticsearch-password", scanGithubPRCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanGithubPR.BindPFlag("elasticsearch-api-key", scanGithubPRCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanGithubPR.BindPFlag("splunk-url", scanGithubPRCmd.Flags().Lookup("splunk-url"))
+	err = viperScanGithubPR.BindPFlag("splunk-token", scanGithubPRCmd.Flags().Lookup("splunk-token"))
+	err = viperScanGithubPR.BindPFlag("splunk-index", scanGithubPRCmd.Flags().Lookup("splunk-index"))
+	err = viperScanGithubPR.BindPFlag("splunk-sourcetype", scanGithubPRCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-url", scanGithubPRCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-token", scanGithubPRCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-product", scanGithubPRCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-engagement", scanGithubPRCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-test-title", scanGithubPRCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanGithubPR.BindPFlag("defectdojo-close-old", scanGithubPRCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanGithubPR.BindPFlag("proxy", scanGithubPRCmd.Flags().Lookup("proxy"))
+	err = viperScanGithubPR.BindPFlag("ca-bundle", scanGithubPRCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanGithubPR.BindPFlag("insecure-skip-verify", scanGithubPRCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanGithubPR.BindPFlag("context-lines", scanGithubPRCmd.Flags().Lookup("context-lines"))
+	err = viperScanGithubPR.BindPFlag("dedup-findings", scanGithubPRCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanGithubPR.BindPFlag("junit", scanGithubPRCmd.Flags().Lookup("junit"))
+	err = viperScanGithubPR.BindPFlag("signature-tags", scanGithubPRCmd.Flags().Lookup("signature-tags"))
+	err = viperScanGithubPR.BindPFlag("enable-signatures", scanGithubPRCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanGithubPR.BindPFlag("disable-signatures", scanGithubPRCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanGithubPR.BindPFlag("encrypt-report", scanGithubPRCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanGithubPR.BindPFlag("report-s3", scanGithubPRCmd.Flags().Lookup("report-s3"))
+	err = viperScanGithubPR.BindPFlag("report-s3-endpoint", scanGithubPRCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanGithubPR.BindPFlag("report-s3-region", scanGithubPRCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanGithubPR.BindPFlag("report-s3-sse", scanGithubPRCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanGithubPR.BindPFlag("report-s3-kms-key-id", scanGithubPRCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanGithubPR.BindPFlag("retries", scanGithubPRCmd.Flags().Lookup("retries"))
+	err = viperScanGithubPR.BindPFlag("retry-backoff", scanGithubPRCmd.Flags().Lookup("retry-backoff"))
+	err = scanGithubPRCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"github.com/spf13/viper"
+	"os"
 	"time"
 	"wraith/core"
 	"wraith/version"
@@ -23,24 +24,43 @@ var scanGitlabCmd = &cobra.Command{
 
 		scanType := "gitlab"
 		sess := core.NewSession(viperScanGitlab, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
 
 		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
 		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
-		sess.Out.Important("Loaded %d signatures.\n", len(core.Signatures))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
 		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
 
 		core.GatherTargets(sess)
 		core.GatherRepositories(sess)
-		core.AnalyzeRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
 		sess.Finish()
 
-		core.PrintSessionStats(sess)
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
 
-		if !sess.Silent {
+		if !sess.Silent && !sess.Interrupted() {
 			sess.Out.Important("%s", core.GitLabTanuki)
 			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
 			select {}
 		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
 	},
 }
 
@@ -50,6 +70,8 @@ func init() {
 	viperScanGitlab = core.SetConfig()
 
 	scanGitlabCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanGitlabCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanGitlabCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
 	scanGitlabCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
 	scanGitlabCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
 	scanGitlabCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
@@ -60,30 +82,295 @@ func init() {
 	scanGitlabCmd.Flags().Int("match-level", 3, "Signature match level")
 	scanGitlabCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
 	scanGitlabCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanGitlabCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanGitlabCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanGitlabCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanGitlabCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
 	scanGitlabCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
 	scanGitlabCmd.Flags().String("gitlab-api-token", "", "API token for access to Gitlab, see doc for necessary scope")
 	scanGitlabCmd.Flags().String("gitlab-targets", "", "A space separated list of Gitlab users, projects or groups to scan")
 	scanGitlabCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
 	scanGitlabCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
-	scanGitlabCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "file(s) containing detection signatures.")
+	scanGitlabCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanGitlabCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanGitlabCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanGitlabCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanGitlabCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanGitlabCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanGitlabCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanGitlabCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanGitlabCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanGitlabCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanGitlabCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanGitlabCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanGitlabCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanGitlabCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanGitlabCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanGitlabCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanGitlabCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanGitlabCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanGitlabCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanGitlabCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanGitlabCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanGitlabCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanGitlabCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanGitlabCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanGitlabCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanGitlabCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanGitlabCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanGitlabCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanGitlabCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanGitlabCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanGitlabCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanGitlabCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanGitlabCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanGitlabCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanGitlabCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanGitlabCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanGitlabCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanGitlabCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanGitlabCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanGitlabCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanGitlabCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanGitlabCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanGitlabCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanGitlabCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanGitlabCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanGitlabCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanGitlabCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanGitlabCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanGitlabCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanGitlabCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanGitlabCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanGitlabCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanGitlabCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanGitlabCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanGitlabCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanGitlabCmd.Flags().Bool("recurse-groups", true, "Scan the projects of every subgroup of a group target, not only those directly in it")
+	scanGitlabCmd.Flags().Bool("shared-projects", true, "Scan the projects that other groups have shared with a group target")
+	scanGitlabCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanGitlabCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanGitlabCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanGitlabCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanGitlabCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanGitlabCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanGitlabCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanGitlabCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanGitlabCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanGitlabCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanGitlabCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanGitlabCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanGitlabCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanGitlabCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanGitlabCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanGitlabCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanGitlabCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanGitlabCmd.Flags().String("include-repos", "", "Only scan repositories whose owner/name matches this regular expression")
+	scanGitlabCmd.Flags().String("exclude-repos", "", "Skip repositories whose owner/name matches this regular expression")
+	scanGitlabCmd.Flags().Bool("exclude-archived", false, "Skip archived repositories")
+	scanGitlabCmd.Flags().Bool("only-private", false, "Only scan private and internal repositories")
+	scanGitlabCmd.Flags().Bool("only-public", false, "Only scan public repositories")
+	scanGitlabCmd.Flags().StringSlice("language", []string{}, "Only scan repositories in one of these languages")
+	scanGitlabCmd.Flags().StringSlice("topic", []string{}, "Only scan repositories with one of these topics")
+	scanGitlabCmd.Flags().String("pushed-after", "", "Only scan repositories pushed to after this date, such as 2020-08-03 or 90d")
+	scanGitlabCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanGitlabCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanGitlabCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanGitlabCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	scanGitlabCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanGitlabCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanGitlabCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanGitlabCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanGitlabCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanGitlabCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanGitlabCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanGitlabCmd.Flags().Bool("expand-members-repos", true, "Gather the repos of the members of organizations")
+	scanGitlabCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanGitlabCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanGitlabCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanGitlabCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanGitlabCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanGitlabCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanGitlabCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanGitlabCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanGitlabCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanGitlabCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanGitlabCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanGitlabCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanGitlabCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanGitlabCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanGitlabCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanGitlabCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanGitlabCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanGitlabCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanGitlabCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanGitlabCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanGitlabCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanGitlabCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanGitlabCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanGitlabCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanGitlabCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanGitlabCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanGitlabCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanGitlabCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanGitlabCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanGitlabCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanGitlabCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanGitlabCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanGitlabCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanGitlabCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
 
 	err := viperScanGitlab.BindPFlag("bind-address", scanGitlabCmd.Flags().Lookup("bind-address"))
 	err = viperScanGitlab.BindPFlag("bind-port", scanGitlabCmd.Flags().Lookup("bind-port"))
 	err = viperScanGitlab.BindPFlag("commit-depth", scanGitlabCmd.Flags().Lookup("commit-depth"))
 	err = viperScanGitlab.BindPFlag("debug", scanGitlabCmd.Flags().Lookup("debug"))
+	err = viperScanGitlab.BindPFlag("debug-server", scanGitlabCmd.Flags().Lookup("debug-server"))
+	err = viperScanGitlab.BindPFlag("debug-server-public", scanGitlabCmd.Flags().Lookup("debug-server-public"))
 	err = viperScanGitlab.BindPFlag("gitlab-api-token", scanGitlabCmd.Flags().Lookup("gitlab-api-token"))
 	err = viperScanGitlab.BindPFlag("gitlab-targets", scanGitlabCmd.Flags().Lookup("gitlab-targets"))
 	err = viperScanGitlab.BindPFlag("hide-secrets", scanGitlabCmd.Flags().Lookup("hide-secrets"))
 	err = viperScanGitlab.BindPFlag("ignore-extension", scanGitlabCmd.Flags().Lookup("ignore-extension"))
 	err = viperScanGitlab.BindPFlag("ignore-path", scanGitlabCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanGitlab.BindPFlag("path-include", scanGitlabCmd.Flags().Lookup("path-include"))
+	err = viperScanGitlab.BindPFlag("path-exclude", scanGitlabCmd.Flags().Lookup("path-exclude"))
 	err = viperScanGitlab.BindPFlag("in-mem-clone", scanGitlabCmd.Flags().Lookup("in-mem-clone"))
 	err = viperScanGitlab.BindPFlag("match-level", scanGitlabCmd.Flags().Lookup("match-level"))
 	err = viperScanGitlab.BindPFlag("max-file-size", scanGitlabCmd.Flags().Lookup("max-file-size"))
 	err = viperScanGitlab.BindPFlag("no-expand-orgs", scanGitlabCmd.Flags().Lookup("no-expand-orgs"))
 	err = viperScanGitlab.BindPFlag("num-threads", scanGitlabCmd.Flags().Lookup("num-threads"))
+	err = viperScanGitlab.BindPFlag("clone-threads", scanGitlabCmd.Flags().Lookup("clone-threads"))
+	err = viperScanGitlab.BindPFlag("walk-threads", scanGitlabCmd.Flags().Lookup("walk-threads"))
+	err = viperScanGitlab.BindPFlag("match-threads", scanGitlabCmd.Flags().Lookup("match-threads"))
+	err = viperScanGitlab.BindPFlag("pipeline-queue-size", scanGitlabCmd.Flags().Lookup("pipeline-queue-size"))
 	err = viperScanGitlab.BindPFlag("scan-tests", scanGitlabCmd.Flags().Lookup("scan-tests"))
 	err = viperScanGitlab.BindPFlag("signature-file", scanGitlabCmd.Flags().Lookup("signature-file"))
 	err = viperScanGitlab.BindPFlag("silent", scanGitlabCmd.Flags().Lookup("silent"))
+	err = viperScanGitlab.BindPFlag("detector-plugins", scanGitlabCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanGitlab.BindPFlag("plugin-timeout", scanGitlabCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanGitlab.BindPFlag("wasm-plugin-dir", scanGitlabCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanGitlab.BindPFlag("grpc-port", scanGitlabCmd.Flags().Lookup("grpc-port"))
+	err = viperScanGitlab.BindPFlag("grpc-token", scanGitlabCmd.Flags().Lookup("grpc-token"))
+	err = viperScanGitlab.BindPFlag("grpc-tls-cert", scanGitlabCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanGitlab.BindPFlag("grpc-tls-key", scanGitlabCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanGitlab.BindPFlag("grpc-client-ca", scanGitlabCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanGitlab.BindPFlag("allow-local-scans", scanGitlabCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanGitlab.BindPFlag("scan-retention", scanGitlabCmd.Flags().Lookup("scan-retention"))
+	err = viperScanGitlab.BindPFlag("json", scanGitlabCmd.Flags().Lookup("json"))
+	err = viperScanGitlab.BindPFlag("jsonl", scanGitlabCmd.Flags().Lookup("jsonl"))
+	err = viperScanGitlab.BindPFlag("stream", scanGitlabCmd.Flags().Lookup("stream"))
+	err = viperScanGitlab.BindPFlag("on-finding-exec", scanGitlabCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanGitlab.BindPFlag("on-repo-complete-exec", scanGitlabCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanGitlab.BindPFlag("finding-script", scanGitlabCmd.Flags().Lookup("finding-script"))
+	err = viperScanGitlab.BindPFlag("redact", scanGitlabCmd.Flags().Lookup("redact"))
+	err = viperScanGitlab.BindPFlag("history-file", scanGitlabCmd.Flags().Lookup("history-file"))
+	err = viperScanGitlab.BindPFlag("alert-state-file", scanGitlabCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanGitlab.BindPFlag("realert-interval", scanGitlabCmd.Flags().Lookup("realert-interval"))
+	err = viperScanGitlab.BindPFlag("policy", scanGitlabCmd.Flags().Lookup("policy"))
+	err = viperScanGitlab.BindPFlag("entropy", scanGitlabCmd.Flags().Lookup("entropy"))
+	err = viperScanGitlab.BindPFlag("entropy-base64-threshold", scanGitlabCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanGitlab.BindPFlag("entropy-base64-min-length", scanGitlabCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanGitlab.BindPFlag("entropy-hex-threshold", scanGitlabCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanGitlab.BindPFlag("entropy-hex-min-length", scanGitlabCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanGitlab.BindPFlag("baseline", scanGitlabCmd.Flags().Lookup("baseline"))
+	err = viperScanGitlab.BindPFlag("db-path", scanGitlabCmd.Flags().Lookup("db-path"))
+	err = viperScanGitlab.BindPFlag("verify", scanGitlabCmd.Flags().Lookup("verify"))
+	err = viperScanGitlab.BindPFlag("incremental", scanGitlabCmd.Flags().Lookup("incremental"))
+	err = viperScanGitlab.BindPFlag("scan-state-file", scanGitlabCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanGitlab.BindPFlag("checkpoint", scanGitlabCmd.Flags().Lookup("checkpoint"))
+	err = viperScanGitlab.BindPFlag("checkpoint-interval", scanGitlabCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanGitlab.BindPFlag("resume", scanGitlabCmd.Flags().Lookup("resume"))
+	err = viperScanGitlab.BindPFlag("web-username", scanGitlabCmd.Flags().Lookup("web-username"))
+	err = viperScanGitlab.BindPFlag("web-password", scanGitlabCmd.Flags().Lookup("web-password"))
+	err = viperScanGitlab.BindPFlag("oidc-issuer", scanGitlabCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanGitlab.BindPFlag("oidc-client-id", scanGitlabCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanGitlab.BindPFlag("oidc-client-secret", scanGitlabCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanGitlab.BindPFlag("oidc-redirect-url", scanGitlabCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanGitlab.BindPFlag("oidc-allowed-emails", scanGitlabCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanGitlab.BindPFlag("ci", scanGitlabCmd.Flags().Lookup("ci"))
+	err = viperScanGitlab.BindPFlag("fail-on", scanGitlabCmd.Flags().Lookup("fail-on"))
+	err = viperScanGitlab.BindPFlag("report-html", scanGitlabCmd.Flags().Lookup("report-html"))
+	err = viperScanGitlab.BindPFlag("scan-branches", scanGitlabCmd.Flags().Lookup("scan-branches"))
+	err = viperScanGitlab.BindPFlag("since-commit", scanGitlabCmd.Flags().Lookup("since-commit"))
+	err = viperScanGitlab.BindPFlag("since-date", scanGitlabCmd.Flags().Lookup("since-date"))
+	err = viperScanGitlab.BindPFlag("until-commit", scanGitlabCmd.Flags().Lookup("until-commit"))
+	err = viperScanGitlab.BindPFlag("suppression-markers", scanGitlabCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanGitlab.BindPFlag("webhook-url", scanGitlabCmd.Flags().Lookup("webhook-url"))
+	err = viperScanGitlab.BindPFlag("webhook-header", scanGitlabCmd.Flags().Lookup("webhook-header"))
+	err = viperScanGitlab.BindPFlag("webhook-secret", scanGitlabCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanGitlab.BindPFlag("recurse-groups", scanGitlabCmd.Flags().Lookup("recurse-groups"))
+	err = viperScanGitlab.BindPFlag("shared-projects", scanGitlabCmd.Flags().Lookup("shared-projects"))
+	err = viperScanGitlab.BindPFlag("scan-commit-messages", scanGitlabCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanGitlab.BindPFlag("scan-notes", scanGitlabCmd.Flags().Lookup("scan-notes"))
+	err = viperScanGitlab.BindPFlag("scan-submodules", scanGitlabCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanGitlab.BindPFlag("submodule-depth", scanGitlabCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanGitlab.BindPFlag("csv", scanGitlabCmd.Flags().Lookup("csv"))
+	err = viperScanGitlab.BindPFlag("triage-file", scanGitlabCmd.Flags().Lookup("triage-file"))
+	err = viperScanGitlab.BindPFlag("min-severity", scanGitlabCmd.Flags().Lookup("min-severity"))
+	err = viperScanGitlab.BindPFlag("min-confidence", scanGitlabCmd.Flags().Lookup("min-confidence"))
+	err = viperScanGitlab.BindPFlag("queue-url", scanGitlabCmd.Flags().Lookup("queue-url"))
+	err = viperScanGitlab.BindPFlag("queue-name", scanGitlabCmd.Flags().Lookup("queue-name"))
+	err = viperScanGitlab.BindPFlag("queue-timeout", scanGitlabCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanGitlab.BindPFlag("decode", scanGitlabCmd.Flags().Lookup("decode"))
+	err = viperScanGitlab.BindPFlag("decode-min-length", scanGitlabCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanGitlab.BindPFlag("slack-webhook", scanGitlabCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanGitlab.BindPFlag("slack-token", scanGitlabCmd.Flags().Lookup("slack-token"))
+	err = viperScanGitlab.BindPFlag("slack-channel", scanGitlabCmd.Flags().Lookup("slack-channel"))
+	err = viperScanGitlab.BindPFlag("api-scans", scanGitlabCmd.Flags().Lookup("api-scans"))
+	err = viperScanGitlab.BindPFlag("include-repos", scanGitlabCmd.Flags().Lookup("include-repos"))
+	err = viperScanGitlab.BindPFlag("exclude-repos", scanGitlabCmd.Flags().Lookup("exclude-repos"))
+	err = viperScanGitlab.BindPFlag("exclude-archived", scanGitlabCmd.Flags().Lookup("exclude-archived"))
+	err = viperScanGitlab.BindPFlag("only-private", scanGitlabCmd.Flags().Lookup("only-private"))
+	err = viperScanGitlab.BindPFlag("only-public", scanGitlabCmd.Flags().Lookup("only-public"))
+	err = viperScanGitlab.BindPFlag("language", scanGitlabCmd.Flags().Lookup("language"))
+	err = viperScanGitlab.BindPFlag("topic", scanGitlabCmd.Flags().Lookup("topic"))
+	err = viperScanGitlab.BindPFlag("pushed-after", scanGitlabCmd.Flags().Lookup("pushed-after"))
+	err = viperScanGitlab.BindPFlag("log-format", scanGitlabCmd.Flags().Lookup("log-format"))
+	err = viperScanGitlab.BindPFlag("log-file", scanGitlabCmd.Flags().Lookup("log-file"))
+	err = viperScanGitlab.BindPFlag("clone-cache", scanGitlabCmd.Flags().Lookup("clone-cache"))
+	err = viperScanGitlab.BindPFlag("clone-over-ssh", scanGitlabCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperScanGitlab.BindPFlag("ssh-key", scanGitlabCmd.Flags().Lookup("ssh-key"))
+	err = viperScanGitlab.BindPFlag("ssh-key-passphrase", scanGitlabCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanGitlab.BindPFlag("ssh-known-hosts", scanGitlabCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanGitlab.BindPFlag("retries", scanGitlabCmd.Flags().Lookup("retries"))
+	err = viperScanGitlab.BindPFlag("retry-backoff", scanGitlabCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanGitlab.BindPFlag("gitlab-report", scanGitlabCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanGitlab.BindPFlag("in-mem-clone-budget", scanGitlabCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanGitlab.BindPFlag("expand-members-repos", scanGitlabCmd.Flags().Lookup("expand-members-repos"))
+	err = viperScanGitlab.BindPFlag("elasticsearch-url", scanGitlabCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanGitlab.BindPFlag("elasticsearch-index", scanGitlabCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanGitlab.BindPFlag("elasticsearch-username", scanGitlabCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanGitlab.BindPFlag("elasticsearch-password", scanGitlabCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanGitlab.BindPFlag("elasticsearch-api-key", scanGitlabCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanGitlab.BindPFlag("splunk-url", scanGitlabCmd.Flags().Lookup("splunk-url"))
+	err = viperScanGitlab.BindPFlag("splunk-token", scanGitlabCmd.Flags().Lookup("splunk-token"))
+	err = viperScanGitlab.BindPFlag("splunk-index", scanGitlabCmd.Flags().Lookup("splunk-index"))
+	err = viperScanGitlab.BindPFlag("splunk-sourcetype", scanGitlabCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanGitlab.BindPFlag("defectdojo-url", scanGitlabCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanGitlab.BindPFlag("defectdojo-token", scanGitlabCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanGitlab.BindPFlag("defectdojo-product", scanGitlabCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanGitlab.BindPFlag("defectdojo-engagement", scanGitlabCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanGitlab.BindPFlag("defectdojo-test-title", scanGitlabCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanGitlab.BindPFlag("defectdojo-close-old", scanGitlabCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanGitlab.BindPFlag("proxy", scanGitlabCmd.Flags().Lookup("proxy"))
+	err = viperScanGitlab.BindPFlag("ca-bundle", scanGitlabCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanGitlab.BindPFlag("insecure-skip-verify", scanGitlabCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanGitlab.BindPFlag("list-targets", scanGitlabCmd.Flags().Lookup("list-targets"))
+	err = viperScanGitlab.BindPFlag("context-lines", scanGitlabCmd.Flags().Lookup("context-lines"))
+	err = viperScanGitlab.BindPFlag("dedup-findings", scanGitlabCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanGitlab.BindPFlag("junit", scanGitlabCmd.Flags().Lookup("junit"))
+	err = viperScanGitlab.BindPFlag("signature-tags", scanGitlabCmd.Flags().Lookup("signature-tags"))
+	err = viperScanGitlab.BindPFlag("enable-signatures", scanGitlabCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanGitlab.BindPFlag("disable-signatures", scanGitlabCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanGitlab.BindPFlag("encrypt-report", scanGitlabCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanGitlab.BindPFlag("report-s3", scanGitlabCmd.Flags().Lookup("report-s3"))
+	err = viperScanGitlab.BindPFlag("report-s3-endpoint", scanGitlabCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanGitlab.BindPFlag("report-s3-region", scanGitlabCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanGitlab.BindPFlag("report-s3-sse", scanGitlabCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanGitlab.BindPFlag("report-s3-kms-key-id", scanGitlabCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanGitlab.BindPFlag("scan-terraform-state", scanGitlabCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanGitlab.BindPFlag("scan-binaries", scanGitlabCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanGitlab.BindPFlag("binary-min-length", scanGitlabCmd.Flags().Lookup("binary-min-length"))
+	err = scanGitlabCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
 
 	if err != nil {
 		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
@@ -0,0 +1,274 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanKubernetes *viper.Viper
+
+// scanKubernetesCmd represents the scanKubernetes command that will scan the secrets and config maps of a cluster
+var scanKubernetesCmd = &cobra.Command{
+	Use:   "scanKubernetes",
+	Short: "Scan the secrets and config maps in a kubernetes cluster for secrets.",
+	Long:  `Scan the values of the secrets and config maps in a kubernetes cluster, or in some of its namespaces, for secrets using the same kubeconfig as kubectl.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "kubernetes"
+		sess := core.NewSession(viperScanKubernetes, scanType)
+		sess.HandleInterrupts()
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		if err := core.ScanKubernetes(sess); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanKubernetesCmd)
+
+	viperScanKubernetes = core.SetConfig()
+
+	scanKubernetesCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanKubernetesCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanKubernetesCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanKubernetesCmd.Flags().Bool("hide-secrets", false, "Show secrets in any supported output")
+	scanKubernetesCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanKubernetesCmd.Flags().Bool("silent", false, "Suppress all output except for errors")
+	scanKubernetesCmd.Flags().Int64("max-file-size", 50, "Max file size to scan")
+	scanKubernetesCmd.Flags().Int("match-level", 3, "The match level of the expressions used to find matches")
+	scanKubernetesCmd.Flags().String("ignore-extension", "", "a list of extensions to ignore during a scan")
+	scanKubernetesCmd.Flags().String("ignore-path", "", "a list of paths to ignore during a scan")
+	scanKubernetesCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanKubernetesCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanKubernetesCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanKubernetesCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanKubernetesCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanKubernetesCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanKubernetesCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanKubernetesCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanKubernetesCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanKubernetesCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanKubernetesCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanKubernetesCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanKubernetesCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanKubernetesCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanKubernetesCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanKubernetesCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanKubernetesCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanKubernetesCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanKubernetesCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanKubernetesCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanKubernetesCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanKubernetesCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanKubernetesCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanKubernetesCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanKubernetesCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanKubernetesCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanKubernetesCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanKubernetesCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanKubernetesCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanKubernetesCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanKubernetesCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanKubernetesCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanKubernetesCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanKubernetesCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanKubernetesCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanKubernetesCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanKubernetesCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanKubernetesCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanKubernetesCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanKubernetesCmd.Flags().String("kubeconfig", "", "The kubeconfig file to connect with, KUBECONFIG or ~/.kube/config when empty and the in-cluster service account without either")
+	scanKubernetesCmd.Flags().String("kube-context", "", "The context of the kubeconfig to connect with, its current context when empty")
+	scanKubernetesCmd.Flags().String("kube-api", "", "The kubernetes api url, such as the one served by kubectl proxy, to connect to in place of a kubeconfig")
+	scanKubernetesCmd.Flags().String("kube-namespaces", "", "A space separated list of namespaces to scan, all of them when empty")
+	scanKubernetesCmd.Flags().String("kube-label-selector", "", "Only scan the secrets and config maps matching this label selector, such as app=api,tier!=cache")
+	scanKubernetesCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanKubernetesCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanKubernetesCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanKubernetesCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanKubernetesCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanKubernetesCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanKubernetesCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanKubernetesCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanKubernetesCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanKubernetesCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanKubernetesCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanKubernetesCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanKubernetesCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanKubernetesCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanKubernetesCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanKubernetesCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanKubernetesCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanKubernetesCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanKubernetesCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanKubernetesCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanKubernetesCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanKubernetesCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanKubernetesCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanKubernetesCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanKubernetesCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanKubernetesCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanKubernetesCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanKubernetesCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanKubernetesCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanKubernetesCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanKubernetesCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanKubernetesCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanKubernetesCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanKubernetesCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanKubernetesCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanKubernetesCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanKubernetesCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanKubernetesCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanKubernetesCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanKubernetesCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanKubernetesCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanKubernetesCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanKubernetesCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanKubernetesCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanKubernetesCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanKubernetesCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanKubernetesCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanKubernetesCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanKubernetesCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+
+	err := viperScanKubernetes.BindPFlag("debug", scanKubernetesCmd.Flags().Lookup("debug"))
+	err = viperScanKubernetes.BindPFlag("debug-server", scanKubernetesCmd.Flags().Lookup("debug-server"))
+	err = viperScanKubernetes.BindPFlag("debug-server-public", scanKubernetesCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanKubernetes.BindPFlag("hide-secrets", scanKubernetesCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanKubernetes.BindPFlag("scan-tests", scanKubernetesCmd.Flags().Lookup("scan-tests"))
+	err = viperScanKubernetes.BindPFlag("silent", scanKubernetesCmd.Flags().Lookup("silent"))
+	err = viperScanKubernetes.BindPFlag("max-file-size", scanKubernetesCmd.Flags().Lookup("max-file-size"))
+	err = viperScanKubernetes.BindPFlag("match-level", scanKubernetesCmd.Flags().Lookup("match-level"))
+	err = viperScanKubernetes.BindPFlag("ignore-extension", scanKubernetesCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanKubernetes.BindPFlag("ignore-path", scanKubernetesCmd.Flags().Lookup("ignore-path"))
+	err = viperScanKubernetes.BindPFlag("signature-file", scanKubernetesCmd.Flags().Lookup("signature-file"))
+	err = viperScanKubernetes.BindPFlag("detector-plugins", scanKubernetesCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanKubernetes.BindPFlag("plugin-timeout", scanKubernetesCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanKubernetes.BindPFlag("wasm-plugin-dir", scanKubernetesCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanKubernetes.BindPFlag("grpc-port", scanKubernetesCmd.Flags().Lookup("grpc-port"))
+	err = viperScanKubernetes.BindPFlag("grpc-token", scanKubernetesCmd.Flags().Lookup("grpc-token"))
+	err = viperScanKubernetes.BindPFlag("grpc-tls-cert", scanKubernetesCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanKubernetes.BindPFlag("grpc-tls-key", scanKubernetesCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanKubernetes.BindPFlag("grpc-client-ca", scanKubernetesCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanKubernetes.BindPFlag("allow-local-scans", scanKubernetesCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanKubernetes.BindPFlag("scan-retention", scanKubernetesCmd.Flags().Lookup("scan-retention"))
+	err = viperScanKubernetes.BindPFlag("json", scanKubernetesCmd.Flags().Lookup("json"))
+	err = viperScanKubernetes.BindPFlag("jsonl", scanKubernetesCmd.Flags().Lookup("jsonl"))
+	err = viperScanKubernetes.BindPFlag("stream", scanKubernetesCmd.Flags().Lookup("stream"))
+	err = viperScanKubernetes.BindPFlag("on-finding-exec", scanKubernetesCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanKubernetes.BindPFlag("finding-script", scanKubernetesCmd.Flags().Lookup("finding-script"))
+	err = viperScanKubernetes.BindPFlag("redact", scanKubernetesCmd.Flags().Lookup("redact"))
+	err = viperScanKubernetes.BindPFlag("history-file", scanKubernetesCmd.Flags().Lookup("history-file"))
+	err = viperScanKubernetes.BindPFlag("alert-state-file", scanKubernetesCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanKubernetes.BindPFlag("realert-interval", scanKubernetesCmd.Flags().Lookup("realert-interval"))
+	err = viperScanKubernetes.BindPFlag("policy", scanKubernetesCmd.Flags().Lookup("policy"))
+	err = viperScanKubernetes.BindPFlag("entropy", scanKubernetesCmd.Flags().Lookup("entropy"))
+	err = viperScanKubernetes.BindPFlag("entropy-base64-threshold", scanKubernetesCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanKubernetes.BindPFlag("entropy-base64-min-length", scanKubernetesCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanKubernetes.BindPFlag("entropy-hex-threshold", scanKubernetesCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanKubernetes.BindPFlag("entropy-hex-min-length", scanKubernetesCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanKubernetes.BindPFlag("baseline", scanKubernetesCmd.Flags().Lookup("baseline"))
+	err = viperScanKubernetes.BindPFlag("db-path", scanKubernetesCmd.Flags().Lookup("db-path"))
+	err = viperScanKubernetes.BindPFlag("verify", scanKubernetesCmd.Flags().Lookup("verify"))
+	err = viperScanKubernetes.BindPFlag("web-username", scanKubernetesCmd.Flags().Lookup("web-username"))
+	err = viperScanKubernetes.BindPFlag("web-password", scanKubernetesCmd.Flags().Lookup("web-password"))
+	err = viperScanKubernetes.BindPFlag("oidc-issuer", scanKubernetesCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanKubernetes.BindPFlag("oidc-client-id", scanKubernetesCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanKubernetes.BindPFlag("oidc-client-secret", scanKubernetesCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanKubernetes.BindPFlag("oidc-redirect-url", scanKubernetesCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanKubernetes.BindPFlag("oidc-allowed-emails", scanKubernetesCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanKubernetes.BindPFlag("ci", scanKubernetesCmd.Flags().Lookup("ci"))
+	err = viperScanKubernetes.BindPFlag("fail-on", scanKubernetesCmd.Flags().Lookup("fail-on"))
+	err = viperScanKubernetes.BindPFlag("report-html", scanKubernetesCmd.Flags().Lookup("report-html"))
+	err = viperScanKubernetes.BindPFlag("kubeconfig", scanKubernetesCmd.Flags().Lookup("kubeconfig"))
+	err = viperScanKubernetes.BindPFlag("kube-context", scanKubernetesCmd.Flags().Lookup("kube-context"))
+	err = viperScanKubernetes.BindPFlag("kube-api", scanKubernetesCmd.Flags().Lookup("kube-api"))
+	err = viperScanKubernetes.BindPFlag("kube-namespaces", scanKubernetesCmd.Flags().Lookup("kube-namespaces"))
+	err = viperScanKubernetes.BindPFlag("kube-label-selector", scanKubernetesCmd.Flags().Lookup("kube-label-selector"))
+	err = viperScanKubernetes.BindPFlag("suppression-markers", scanKubernetesCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanKubernetes.BindPFlag("webhook-url", scanKubernetesCmd.Flags().Lookup("webhook-url"))
+	err = viperScanKubernetes.BindPFlag("webhook-header", scanKubernetesCmd.Flags().Lookup("webhook-header"))
+	err = viperScanKubernetes.BindPFlag("webhook-secret", scanKubernetesCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanKubernetes.BindPFlag("csv", scanKubernetesCmd.Flags().Lookup("csv"))
+	err = viperScanKubernetes.BindPFlag("triage-file", scanKubernetesCmd.Flags().Lookup("triage-file"))
+	err = viperScanKubernetes.BindPFlag("min-severity", scanKubernetesCmd.Flags().Lookup("min-severity"))
+	err = viperScanKubernetes.BindPFlag("min-confidence", scanKubernetesCmd.Flags().Lookup("min-confidence"))
+	err = viperScanKubernetes.BindPFlag("decode", scanKubernetesCmd.Flags().Lookup("decode"))
+	err = viperScanKubernetes.BindPFlag("decode-min-length", scanKubernetesCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanKubernetes.BindPFlag("slack-webhook", scanKubernetesCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanKubernetes.BindPFlag("slack-token", scanKubernetesCmd.Flags().Lookup("slack-token"))
+	err = viperScanKubernetes.BindPFlag("slack-channel", scanKubernetesCmd.Flags().Lookup("slack-channel"))
+	err = viperScanKubernetes.BindPFlag("api-scans", scanKubernetesCmd.Flags().Lookup("api-scans"))
+	err = viperScanKubernetes.BindPFlag("log-format", scanKubernetesCmd.Flags().Lookup("log-format"))
+	err = viperScanKubernetes.BindPFlag("log-file", scanKubernetesCmd.Flags().Lookup("log-file"))
+	err = viperScanKubernetes.BindPFlag("gitlab-report", scanKubernetesCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanKubernetes.BindPFlag("elasticsearch-url", scanKubernetesCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanKubernetes.BindPFlag("elasticsearch-index", scanKubernetesCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanKubernetes.BindPFlag("elasticsearch-username", scanKubernetesCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanKubernetes.BindPFlag("elasticsearch-password", scanKubernetesCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanKubernetes.BindPFlag("elasticsearch-api-key", scanKubernetesCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanKubernetes.BindPFlag("splunk-url", scanKubernetesCmd.Flags().Lookup("splunk-url"))
+	err = viperScanKubernetes.BindPFlag("splunk-token", scanKubernetesCmd.Flags().Lookup("splunk-token"))
+	err = viperScanKubernetes.BindPFlag("splunk-index", scanKubernetesCmd.Flags().Lookup("splunk-index"))
+	err = viperScanKubernetes.BindPFlag("splunk-sourcetype", scanKubernetesCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-url", scanKubernetesCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-token", scanKubernetesCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-product", scanKubernetesCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-engagement", scanKubernetesCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-test-title", scanKubernetesCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanKubernetes.BindPFlag("defectdojo-close-old", scanKubernetesCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanKubernetes.BindPFlag("proxy", scanKubernetesCmd.Flags().Lookup("proxy"))
+	err = viperScanKubernetes.BindPFlag("ca-bundle", scanKubernetesCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanKubernetes.BindPFlag("insecure-skip-verify", scanKubernetesCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanKubernetes.BindPFlag("context-lines", scanKubernetesCmd.Flags().Lookup("context-lines"))
+	err = viperScanKubernetes.BindPFlag("dedup-findings", scanKubernetesCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanKubernetes.BindPFlag("junit", scanKubernetesCmd.Flags().Lookup("junit"))
+	err = viperScanKubernetes.BindPFlag("signature-tags", scanKubernetesCmd.Flags().Lookup("signature-tags"))
+	err = viperScanKubernetes.BindPFlag("enable-signatures", scanKubernetesCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanKubernetes.BindPFlag("disable-signatures", scanKubernetesCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanKubernetes.BindPFlag("encrypt-report", scanKubernetesCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanKubernetes.BindPFlag("report-s3", scanKubernetesCmd.Flags().Lookup("report-s3"))
+	err = viperScanKubernetes.BindPFlag("report-s3-endpoint", scanKubernetesCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanKubernetes.BindPFlag("report-s3-region", scanKubernetesCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanKubernetes.BindPFlag("report-s3-sse", scanKubernetesCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanKubernetes.BindPFlag("report-s3-kms-key-id", scanKubernetesCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanKubernetes.BindPFlag("retries", scanKubernetesCmd.Flags().Lookup("retries"))
+	err = viperScanKubernetes.BindPFlag("retry-backoff", scanKubernetesCmd.Flags().Lookup("retry-backoff"))
+	err = scanKubernetesCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"github.com/spf13/viper"
+	"os"
 	"time"
 	"wraith/core"
 	"wraith/version"
@@ -23,23 +24,42 @@ var scanLocalGitRepoCmd = &cobra.Command{
 
 		scanType := "localGit"
 		sess := core.NewSession(viperScanLocalGitRepo, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
 
 		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
 		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
-		sess.Out.Important("Loaded %d signatures.\n", len(core.Signatures))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
 		sess.Out.Important("Web interface available at http://%s:%d\n", "127.0.0.1", 9393)
 
 		core.GatherLocalRepositories(sess)
-		core.AnalyzeRepositories(sess)
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
 		sess.Finish()
 
-		core.PrintSessionStats(sess)
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
 
-		if !sess.Silent {
+		if !sess.Silent && !sess.Interrupted() {
 			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
 			select {}
 		}
 
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
 	},
 }
 
@@ -49,6 +69,8 @@ func init() {
 	viperScanLocalGitRepo = core.SetConfig()
 
 	scanLocalGitRepoCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanLocalGitRepoCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanLocalGitRepoCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
 	scanLocalGitRepoCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
 	scanLocalGitRepoCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
 	scanLocalGitRepoCmd.Flags().Bool("no-expand-orgs", false, "Don't add members to targets when processing organizations")
@@ -59,28 +81,267 @@ func init() {
 	scanLocalGitRepoCmd.Flags().Int("match-level", 3, "Signature match level")
 	scanLocalGitRepoCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
 	scanLocalGitRepoCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanLocalGitRepoCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanLocalGitRepoCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanLocalGitRepoCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanLocalGitRepoCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
 	scanLocalGitRepoCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
 	scanLocalGitRepoCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
 	scanLocalGitRepoCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanLocalGitRepoCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanLocalGitRepoCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
 	scanLocalGitRepoCmd.Flags().String("local-dirs", "", "local disk parent dir containing git repos")
-	scanLocalGitRepoCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "file(s) containing detection signatures.")
+	scanLocalGitRepoCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanLocalGitRepoCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanLocalGitRepoCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanLocalGitRepoCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanLocalGitRepoCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanLocalGitRepoCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanLocalGitRepoCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanLocalGitRepoCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanLocalGitRepoCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanLocalGitRepoCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanLocalGitRepoCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanLocalGitRepoCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanLocalGitRepoCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanLocalGitRepoCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanLocalGitRepoCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanLocalGitRepoCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanLocalGitRepoCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanLocalGitRepoCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanLocalGitRepoCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanLocalGitRepoCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanLocalGitRepoCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanLocalGitRepoCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanLocalGitRepoCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanLocalGitRepoCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanLocalGitRepoCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanLocalGitRepoCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanLocalGitRepoCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanLocalGitRepoCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanLocalGitRepoCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanLocalGitRepoCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanLocalGitRepoCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanLocalGitRepoCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanLocalGitRepoCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanLocalGitRepoCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanLocalGitRepoCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanLocalGitRepoCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanLocalGitRepoCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanLocalGitRepoCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanLocalGitRepoCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanLocalGitRepoCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanLocalGitRepoCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanLocalGitRepoCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanLocalGitRepoCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanLocalGitRepoCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanLocalGitRepoCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanLocalGitRepoCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanLocalGitRepoCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanLocalGitRepoCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanLocalGitRepoCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanLocalGitRepoCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanLocalGitRepoCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanLocalGitRepoCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanLocalGitRepoCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanLocalGitRepoCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanLocalGitRepoCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanLocalGitRepoCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanLocalGitRepoCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanLocalGitRepoCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanLocalGitRepoCmd.Flags().Bool("scan-working-tree", false, "Also scan the uncommitted changes and untracked files in the checkout of each repo")
+	scanLocalGitRepoCmd.Flags().Bool("scan-stash", false, "Also scan the entries of git stash in each repo")
+	scanLocalGitRepoCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanLocalGitRepoCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanLocalGitRepoCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanLocalGitRepoCmd.Flags().String("queue-url", "", "Put the repositories on this redis queue for wraith workers to scan, such as redis://:password@host:6379/0")
+	scanLocalGitRepoCmd.Flags().String("queue-name", "wraith", "The name of the queue the workers take repositories from")
+	scanLocalGitRepoCmd.Flags().Int("queue-timeout", 60, "Minutes to wait for a worker to send anything before giving up on the repositories left, never when 0")
+	scanLocalGitRepoCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanLocalGitRepoCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanLocalGitRepoCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanLocalGitRepoCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanLocalGitRepoCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanLocalGitRepoCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanLocalGitRepoCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanLocalGitRepoCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanLocalGitRepoCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanLocalGitRepoCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanLocalGitRepoCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanLocalGitRepoCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanLocalGitRepoCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanLocalGitRepoCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanLocalGitRepoCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanLocalGitRepoCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanLocalGitRepoCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanLocalGitRepoCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanLocalGitRepoCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanLocalGitRepoCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanLocalGitRepoCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanLocalGitRepoCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanLocalGitRepoCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanLocalGitRepoCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanLocalGitRepoCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanLocalGitRepoCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanLocalGitRepoCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanLocalGitRepoCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanLocalGitRepoCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanLocalGitRepoCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanLocalGitRepoCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanLocalGitRepoCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanLocalGitRepoCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanLocalGitRepoCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanLocalGitRepoCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanLocalGitRepoCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanLocalGitRepoCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanLocalGitRepoCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanLocalGitRepoCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanLocalGitRepoCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanLocalGitRepoCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanLocalGitRepoCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanLocalGitRepoCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanLocalGitRepoCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanLocalGitRepoCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanLocalGitRepoCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanLocalGitRepoCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
 
 	err := viperScanLocalGitRepo.BindPFlag("bind-address", scanLocalGitRepoCmd.Flags().Lookup("bind-address"))
 	err = viperScanLocalGitRepo.BindPFlag("bind-port", scanLocalGitRepoCmd.Flags().Lookup("bind-port"))
 	err = viperScanLocalGitRepo.BindPFlag("commit-depth", scanLocalGitRepoCmd.Flags().Lookup("commit-depth"))
 	err = viperScanLocalGitRepo.BindPFlag("debug", scanLocalGitRepoCmd.Flags().Lookup("debug"))
+	err = viperScanLocalGitRepo.BindPFlag("debug-server", scanLocalGitRepoCmd.Flags().Lookup("debug-server"))
+	err = viperScanLocalGitRepo.BindPFlag("debug-server-public", scanLocalGitRepoCmd.Flags().Lookup("debug-server-public"))
 	err = viperScanLocalGitRepo.BindPFlag("hide-secrets", scanLocalGitRepoCmd.Flags().Lookup("hide-secrets"))
 	err = viperScanLocalGitRepo.BindPFlag("ignore-extension", scanLocalGitRepoCmd.Flags().Lookup("ignore-extension"))
 	err = viperScanLocalGitRepo.BindPFlag("ignore-path", scanLocalGitRepoCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanLocalGitRepo.BindPFlag("path-include", scanLocalGitRepoCmd.Flags().Lookup("path-include"))
+	err = viperScanLocalGitRepo.BindPFlag("path-exclude", scanLocalGitRepoCmd.Flags().Lookup("path-exclude"))
 	err = viperScanLocalGitRepo.BindPFlag("in-mem-clone", scanLocalGitRepoCmd.Flags().Lookup("in-mem-clone"))
 	err = viperScanLocalGitRepo.BindPFlag("local-dirs", scanLocalGitRepoCmd.Flags().Lookup("local-dirs"))
 	err = viperScanLocalGitRepo.BindPFlag("match-level", scanLocalGitRepoCmd.Flags().Lookup("match-level"))
 	err = viperScanLocalGitRepo.BindPFlag("max-file-size", scanLocalGitRepoCmd.Flags().Lookup("max-file-size"))
 	err = viperScanLocalGitRepo.BindPFlag("no-expand-orgs", scanLocalGitRepoCmd.Flags().Lookup("no-expand-orgs"))
 	err = viperScanLocalGitRepo.BindPFlag("num-threads", scanLocalGitRepoCmd.Flags().Lookup("num-threads"))
+	err = viperScanLocalGitRepo.BindPFlag("clone-threads", scanLocalGitRepoCmd.Flags().Lookup("clone-threads"))
+	err = viperScanLocalGitRepo.BindPFlag("walk-threads", scanLocalGitRepoCmd.Flags().Lookup("walk-threads"))
+	err = viperScanLocalGitRepo.BindPFlag("match-threads", scanLocalGitRepoCmd.Flags().Lookup("match-threads"))
+	err = viperScanLocalGitRepo.BindPFlag("pipeline-queue-size", scanLocalGitRepoCmd.Flags().Lookup("pipeline-queue-size"))
 	err = viperScanLocalGitRepo.BindPFlag("scan-tests", scanLocalGitRepoCmd.Flags().Lookup("scan-tests"))
 	err = viperScanLocalGitRepo.BindPFlag("signature-file", scanLocalGitRepoCmd.Flags().Lookup("signature-file"))
 	err = viperScanLocalGitRepo.BindPFlag("silent", scanLocalGitRepoCmd.Flags().Lookup("silent"))
+	err = viperScanLocalGitRepo.BindPFlag("detector-plugins", scanLocalGitRepoCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanLocalGitRepo.BindPFlag("plugin-timeout", scanLocalGitRepoCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanLocalGitRepo.BindPFlag("wasm-plugin-dir", scanLocalGitRepoCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanLocalGitRepo.BindPFlag("grpc-port", scanLocalGitRepoCmd.Flags().Lookup("grpc-port"))
+	err = viperScanLocalGitRepo.BindPFlag("grpc-token", scanLocalGitRepoCmd.Flags().Lookup("grpc-token"))
+	err = viperScanLocalGitRepo.BindPFlag("grpc-tls-cert", scanLocalGitRepoCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanLocalGitRepo.BindPFlag("grpc-tls-key", scanLocalGitRepoCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanLocalGitRepo.BindPFlag("grpc-client-ca", scanLocalGitRepoCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanLocalGitRepo.BindPFlag("allow-local-scans", scanLocalGitRepoCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-retention", scanLocalGitRepoCmd.Flags().Lookup("scan-retention"))
+	err = viperScanLocalGitRepo.BindPFlag("json", scanLocalGitRepoCmd.Flags().Lookup("json"))
+	err = viperScanLocalGitRepo.BindPFlag("jsonl", scanLocalGitRepoCmd.Flags().Lookup("jsonl"))
+	err = viperScanLocalGitRepo.BindPFlag("stream", scanLocalGitRepoCmd.Flags().Lookup("stream"))
+	err = viperScanLocalGitRepo.BindPFlag("on-finding-exec", scanLocalGitRepoCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanLocalGitRepo.BindPFlag("on-repo-complete-exec", scanLocalGitRepoCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanLocalGitRepo.BindPFlag("finding-script", scanLocalGitRepoCmd.Flags().Lookup("finding-script"))
+	err = viperScanLocalGitRepo.BindPFlag("redact", scanLocalGitRepoCmd.Flags().Lookup("redact"))
+	err = viperScanLocalGitRepo.BindPFlag("history-file", scanLocalGitRepoCmd.Flags().Lookup("history-file"))
+	err = viperScanLocalGitRepo.BindPFlag("alert-state-file", scanLocalGitRepoCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanLocalGitRepo.BindPFlag("realert-interval", scanLocalGitRepoCmd.Flags().Lookup("realert-interval"))
+	err = viperScanLocalGitRepo.BindPFlag("policy", scanLocalGitRepoCmd.Flags().Lookup("policy"))
+	err = viperScanLocalGitRepo.BindPFlag("entropy", scanLocalGitRepoCmd.Flags().Lookup("entropy"))
+	err = viperScanLocalGitRepo.BindPFlag("entropy-base64-threshold", scanLocalGitRepoCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanLocalGitRepo.BindPFlag("entropy-base64-min-length", scanLocalGitRepoCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanLocalGitRepo.BindPFlag("entropy-hex-threshold", scanLocalGitRepoCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanLocalGitRepo.BindPFlag("entropy-hex-min-length", scanLocalGitRepoCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanLocalGitRepo.BindPFlag("baseline", scanLocalGitRepoCmd.Flags().Lookup("baseline"))
+	err = viperScanLocalGitRepo.BindPFlag("db-path", scanLocalGitRepoCmd.Flags().Lookup("db-path"))
+	err = viperScanLocalGitRepo.BindPFlag("verify", scanLocalGitRepoCmd.Flags().Lookup("verify"))
+	err = viperScanLocalGitRepo.BindPFlag("incremental", scanLocalGitRepoCmd.Flags().Lookup("incremental"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-state-file", scanLocalGitRepoCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanLocalGitRepo.BindPFlag("checkpoint", scanLocalGitRepoCmd.Flags().Lookup("checkpoint"))
+	err = viperScanLocalGitRepo.BindPFlag("checkpoint-interval", scanLocalGitRepoCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanLocalGitRepo.BindPFlag("resume", scanLocalGitRepoCmd.Flags().Lookup("resume"))
+	err = viperScanLocalGitRepo.BindPFlag("web-username", scanLocalGitRepoCmd.Flags().Lookup("web-username"))
+	err = viperScanLocalGitRepo.BindPFlag("web-password", scanLocalGitRepoCmd.Flags().Lookup("web-password"))
+	err = viperScanLocalGitRepo.BindPFlag("oidc-issuer", scanLocalGitRepoCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanLocalGitRepo.BindPFlag("oidc-client-id", scanLocalGitRepoCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanLocalGitRepo.BindPFlag("oidc-client-secret", scanLocalGitRepoCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanLocalGitRepo.BindPFlag("oidc-redirect-url", scanLocalGitRepoCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanLocalGitRepo.BindPFlag("oidc-allowed-emails", scanLocalGitRepoCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanLocalGitRepo.BindPFlag("ci", scanLocalGitRepoCmd.Flags().Lookup("ci"))
+	err = viperScanLocalGitRepo.BindPFlag("fail-on", scanLocalGitRepoCmd.Flags().Lookup("fail-on"))
+	err = viperScanLocalGitRepo.BindPFlag("report-html", scanLocalGitRepoCmd.Flags().Lookup("report-html"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-branches", scanLocalGitRepoCmd.Flags().Lookup("scan-branches"))
+	err = viperScanLocalGitRepo.BindPFlag("since-commit", scanLocalGitRepoCmd.Flags().Lookup("since-commit"))
+	err = viperScanLocalGitRepo.BindPFlag("since-date", scanLocalGitRepoCmd.Flags().Lookup("since-date"))
+	err = viperScanLocalGitRepo.BindPFlag("until-commit", scanLocalGitRepoCmd.Flags().Lookup("until-commit"))
+	err = viperScanLocalGitRepo.BindPFlag("suppression-markers", scanLocalGitRepoCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanLocalGitRepo.BindPFlag("webhook-url", scanLocalGitRepoCmd.Flags().Lookup("webhook-url"))
+	err = viperScanLocalGitRepo.BindPFlag("webhook-header", scanLocalGitRepoCmd.Flags().Lookup("webhook-header"))
+	err = viperScanLocalGitRepo.BindPFlag("webhook-secret", scanLocalGitRepoCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-commit-messages", scanLocalGitRepoCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-notes", scanLocalGitRepoCmd.Flags().Lookup("scan-notes"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-submodules", scanLocalGitRepoCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanLocalGitRepo.BindPFlag("submodule-depth", scanLocalGitRepoCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanLocalGitRepo.BindPFlag("csv", scanLocalGitRepoCmd.Flags().Lookup("csv"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-working-tree", scanLocalGitRepoCmd.Flags().Lookup("scan-working-tree"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-stash", scanLocalGitRepoCmd.Flags().Lookup("scan-stash"))
+	err = viperScanLocalGitRepo.BindPFlag("triage-file", scanLocalGitRepoCmd.Flags().Lookup("triage-file"))
+	err = viperScanLocalGitRepo.BindPFlag("min-severity", scanLocalGitRepoCmd.Flags().Lookup("min-severity"))
+	err = viperScanLocalGitRepo.BindPFlag("min-confidence", scanLocalGitRepoCmd.Flags().Lookup("min-confidence"))
+	err = viperScanLocalGitRepo.BindPFlag("queue-url", scanLocalGitRepoCmd.Flags().Lookup("queue-url"))
+	err = viperScanLocalGitRepo.BindPFlag("queue-name", scanLocalGitRepoCmd.Flags().Lookup("queue-name"))
+	err = viperScanLocalGitRepo.BindPFlag("queue-timeout", scanLocalGitRepoCmd.Flags().Lookup("queue-timeout"))
+	err = viperScanLocalGitRepo.BindPFlag("decode", scanLocalGitRepoCmd.Flags().Lookup("decode"))
+	err = viperScanLocalGitRepo.BindPFlag("decode-min-length", scanLocalGitRepoCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanLocalGitRepo.BindPFlag("slack-webhook", scanLocalGitRepoCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanLocalGitRepo.BindPFlag("slack-token", scanLocalGitRepoCmd.Flags().Lookup("slack-token"))
+	err = viperScanLocalGitRepo.BindPFlag("slack-channel", scanLocalGitRepoCmd.Flags().Lookup("slack-channel"))
+	err = viperScanLocalGitRepo.BindPFlag("api-scans", scanLocalGitRepoCmd.Flags().Lookup("api-scans"))
+	err = viperScanLocalGitRepo.BindPFlag("log-format", scanLocalGitRepoCmd.Flags().Lookup("log-format"))
+	err = viperScanLocalGitRepo.BindPFlag("log-file", scanLocalGitRepoCmd.Flags().Lookup("log-file"))
+	err = viperScanLocalGitRepo.BindPFlag("clone-cache", scanLocalGitRepoCmd.Flags().Lookup("clone-cache"))
+	err = viperScanLocalGitRepo.BindPFlag("retries", scanLocalGitRepoCmd.Flags().Lookup("retries"))
+	err = viperScanLocalGitRepo.BindPFlag("retry-backoff", scanLocalGitRepoCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanLocalGitRepo.BindPFlag("gitlab-report", scanLocalGitRepoCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanLocalGitRepo.BindPFlag("in-mem-clone-budget", scanLocalGitRepoCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanLocalGitRepo.BindPFlag("elasticsearch-url", scanLocalGitRepoCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanLocalGitRepo.BindPFlag("elasticsearch-index", scanLocalGitRepoCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanLocalGitRepo.BindPFlag("elasticsearch-username", scanLocalGitRepoCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanLocalGitRepo.BindPFlag("elasticsearch-password", scanLocalGitRepoCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanLocalGitRepo.BindPFlag("elasticsearch-api-key", scanLocalGitRepoCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanLocalGitRepo.BindPFlag("splunk-url", scanLocalGitRepoCmd.Flags().Lookup("splunk-url"))
+	err = viperScanLocalGitRepo.BindPFlag("splunk-token", scanLocalGitRepoCmd.Flags().Lookup("splunk-token"))
+	err = viperScanLocalGitRepo.BindPFlag("splunk-index", scanLocalGitRepoCmd.Flags().Lookup("splunk-index"))
+	err = viperScanLocalGitRepo.BindPFlag("splunk-sourcetype", scanLocalGitRepoCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-url", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-token", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-product", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-engagement", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-test-title", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanLocalGitRepo.BindPFlag("defectdojo-close-old", scanLocalGitRepoCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanLocalGitRepo.BindPFlag("proxy", scanLocalGitRepoCmd.Flags().Lookup("proxy"))
+	err = viperScanLocalGitRepo.BindPFlag("ca-bundle", scanLocalGitRepoCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanLocalGitRepo.BindPFlag("insecure-skip-verify", scanLocalGitRepoCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanLocalGitRepo.BindPFlag("list-targets", scanLocalGitRepoCmd.Flags().Lookup("list-targets"))
+	err = viperScanLocalGitRepo.BindPFlag("context-lines", scanLocalGitRepoCmd.Flags().Lookup("context-lines"))
+	err = viperScanLocalGitRepo.BindPFlag("dedup-findings", scanLocalGitRepoCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanLocalGitRepo.BindPFlag("junit", scanLocalGitRepoCmd.Flags().Lookup("junit"))
+	err = viperScanLocalGitRepo.BindPFlag("signature-tags", scanLocalGitRepoCmd.Flags().Lookup("signature-tags"))
+	err = viperScanLocalGitRepo.BindPFlag("enable-signatures", scanLocalGitRepoCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanLocalGitRepo.BindPFlag("disable-signatures", scanLocalGitRepoCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanLocalGitRepo.BindPFlag("encrypt-report", scanLocalGitRepoCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanLocalGitRepo.BindPFlag("report-s3", scanLocalGitRepoCmd.Flags().Lookup("report-s3"))
+	err = viperScanLocalGitRepo.BindPFlag("report-s3-endpoint", scanLocalGitRepoCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanLocalGitRepo.BindPFlag("report-s3-region", scanLocalGitRepoCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanLocalGitRepo.BindPFlag("report-s3-sse", scanLocalGitRepoCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanLocalGitRepo.BindPFlag("report-s3-kms-key-id", scanLocalGitRepoCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-terraform-state", scanLocalGitRepoCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanLocalGitRepo.BindPFlag("scan-binaries", scanLocalGitRepoCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanLocalGitRepo.BindPFlag("binary-min-length", scanLocalGitRepoCmd.Flags().Lookup("binary-min-length"))
+	err = scanLocalGitRepoCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
 
 	if err != nil {
 		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
@@ -5,6 +5,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"os"
 	"time"
 	"wraith/core"
 	"wraith/version"
@@ -23,6 +24,7 @@ var scanLocalPathCmd = &cobra.Command{
 
 		scanType := "localPath"
 		sess := core.NewSession(viperScanLocalPath, scanType)
+		sess.HandleInterrupts()
 
 		core.CheckArgs(sess.LocalFiles, sess.LocalDirs, sess)
 
@@ -31,7 +33,7 @@ var scanLocalPathCmd = &cobra.Command{
 
 		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
 		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
-		sess.Out.Important("Loaded %d signatures.\n", len(core.Signatures))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
 		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
 
 		// Run either a file scan directly, or if it is a directory then walk the path and gather eligible files and then run a scan against each of them
@@ -57,13 +59,21 @@ var scanLocalPathCmd = &cobra.Command{
 
 		sess.Finish()
 
-		core.PrintSessionStats(sess)
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
 
-		if !sess.Silent {
+		if !sess.Silent && !sess.Interrupted() {
 			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
 			select {}
 		}
 
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
 	},
 }
 
@@ -73,6 +83,8 @@ func init() {
 	viperScanLocalPath = core.SetConfig()
 
 	scanLocalPathCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanLocalPathCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanLocalPathCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
 	scanLocalPathCmd.Flags().Bool("hide-secrets", false, "Show secrets in any supported output")
 	scanLocalPathCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
 	scanLocalPathCmd.Flags().Bool("silent", false, "Suppress all output except for errors")
@@ -80,11 +92,105 @@ func init() {
 	scanLocalPathCmd.Flags().Int("match-level", 3, "The match level of the expressions used to find matches")
 	scanLocalPathCmd.Flags().String("ignore-extension", "", "a list of extensions to ignore during a scan")
 	scanLocalPathCmd.Flags().String("ignore-path", "", "a list of paths to ignore during a scan")
-	scanLocalPathCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "file(s) containing secrets detection signatures.")
+	scanLocalPathCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanLocalPathCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanLocalPathCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
 	scanLocalPathCmd.Flags().String("scan-dir", "", "scan a directory of files not from a git project")
 	scanLocalPathCmd.Flags().String("scan-file", "", "scan a single file")
+	scanLocalPathCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanLocalPathCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanLocalPathCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanLocalPathCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanLocalPathCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanLocalPathCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanLocalPathCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanLocalPathCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanLocalPathCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanLocalPathCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanLocalPathCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanLocalPathCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanLocalPathCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanLocalPathCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanLocalPathCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanLocalPathCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanLocalPathCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanLocalPathCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanLocalPathCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanLocalPathCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanLocalPathCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanLocalPathCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanLocalPathCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanLocalPathCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanLocalPathCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanLocalPathCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanLocalPathCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanLocalPathCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanLocalPathCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanLocalPathCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanLocalPathCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanLocalPathCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanLocalPathCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanLocalPathCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanLocalPathCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanLocalPathCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanLocalPathCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanLocalPathCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanLocalPathCmd.Flags().Int("archive-depth", 2, "How many archives deep to look inside zip, jar and tar files, 0 to not open archives")
+	scanLocalPathCmd.Flags().Int64("archive-max-size", 100, "Max size in MB of the files to take out of a single archive")
+	scanLocalPathCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanLocalPathCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanLocalPathCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanLocalPathCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanLocalPathCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanLocalPathCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanLocalPathCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanLocalPathCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanLocalPathCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanLocalPathCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanLocalPathCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanLocalPathCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanLocalPathCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanLocalPathCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanLocalPathCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanLocalPathCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanLocalPathCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanLocalPathCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanLocalPathCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanLocalPathCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanLocalPathCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanLocalPathCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanLocalPathCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanLocalPathCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanLocalPathCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanLocalPathCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanLocalPathCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanLocalPathCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanLocalPathCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanLocalPathCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanLocalPathCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanLocalPathCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanLocalPathCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanLocalPathCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanLocalPathCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanLocalPathCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanLocalPathCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanLocalPathCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanLocalPathCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanLocalPathCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanLocalPathCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanLocalPathCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanLocalPathCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanLocalPathCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanLocalPathCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanLocalPathCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanLocalPathCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanLocalPathCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanLocalPathCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanLocalPathCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
 
 	err := viperScanLocalPath.BindPFlag("debug", scanLocalPathCmd.Flags().Lookup("debug"))
+	err = viperScanLocalPath.BindPFlag("debug-server", scanLocalPathCmd.Flags().Lookup("debug-server"))
+	err = viperScanLocalPath.BindPFlag("debug-server-public", scanLocalPathCmd.Flags().Lookup("debug-server-public"))
 	err = viperScanLocalPath.BindPFlag("hide-secrets", scanLocalPathCmd.Flags().Lookup("hide-secrets"))
 	err = viperScanLocalPath.BindPFlag("scan-tests", scanLocalPathCmd.Flags().Lookup("scan-tests"))
 	err = viperScanLocalPath.BindPFlag("silent", scanLocalPathCmd.Flags().Lookup("silent"))
@@ -92,9 +198,102 @@ func init() {
 	err = viperScanLocalPath.BindPFlag("match-level", scanLocalPathCmd.Flags().Lookup("match-level"))
 	err = viperScanLocalPath.BindPFlag("ignore-extension", scanLocalPathCmd.Flags().Lookup("ignore-extension"))
 	err = viperScanLocalPath.BindPFlag("ignore-path", scanLocalPathCmd.Flags().Lookup("ignore-path"))
+	err = viperScanLocalPath.BindPFlag("path-include", scanLocalPathCmd.Flags().Lookup("path-include"))
+	err = viperScanLocalPath.BindPFlag("path-exclude", scanLocalPathCmd.Flags().Lookup("path-exclude"))
 	err = viperScanLocalPath.BindPFlag("signature-file", scanLocalPathCmd.Flags().Lookup("signature-file"))
 	err = viperScanLocalPath.BindPFlag("scan-dir", scanLocalPathCmd.Flags().Lookup("scan-dir"))
 	err = viperScanLocalPath.BindPFlag("scan-file", scanLocalPathCmd.Flags().Lookup("scan-file"))
+	err = viperScanLocalPath.BindPFlag("detector-plugins", scanLocalPathCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanLocalPath.BindPFlag("plugin-timeout", scanLocalPathCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanLocalPath.BindPFlag("wasm-plugin-dir", scanLocalPathCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanLocalPath.BindPFlag("grpc-port", scanLocalPathCmd.Flags().Lookup("grpc-port"))
+	err = viperScanLocalPath.BindPFlag("grpc-token", scanLocalPathCmd.Flags().Lookup("grpc-token"))
+	err = viperScanLocalPath.BindPFlag("grpc-tls-cert", scanLocalPathCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanLocalPath.BindPFlag("grpc-tls-key", scanLocalPathCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanLocalPath.BindPFlag("grpc-client-ca", scanLocalPathCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanLocalPath.BindPFlag("allow-local-scans", scanLocalPathCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanLocalPath.BindPFlag("scan-retention", scanLocalPathCmd.Flags().Lookup("scan-retention"))
+	err = viperScanLocalPath.BindPFlag("json", scanLocalPathCmd.Flags().Lookup("json"))
+	err = viperScanLocalPath.BindPFlag("jsonl", scanLocalPathCmd.Flags().Lookup("jsonl"))
+	err = viperScanLocalPath.BindPFlag("stream", scanLocalPathCmd.Flags().Lookup("stream"))
+	err = viperScanLocalPath.BindPFlag("on-finding-exec", scanLocalPathCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanLocalPath.BindPFlag("finding-script", scanLocalPathCmd.Flags().Lookup("finding-script"))
+	err = viperScanLocalPath.BindPFlag("redact", scanLocalPathCmd.Flags().Lookup("redact"))
+	err = viperScanLocalPath.BindPFlag("history-file", scanLocalPathCmd.Flags().Lookup("history-file"))
+	err = viperScanLocalPath.BindPFlag("alert-state-file", scanLocalPathCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanLocalPath.BindPFlag("realert-interval", scanLocalPathCmd.Flags().Lookup("realert-interval"))
+	err = viperScanLocalPath.BindPFlag("policy", scanLocalPathCmd.Flags().Lookup("policy"))
+	err = viperScanLocalPath.BindPFlag("entropy", scanLocalPathCmd.Flags().Lookup("entropy"))
+	err = viperScanLocalPath.BindPFlag("entropy-base64-threshold", scanLocalPathCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanLocalPath.BindPFlag("entropy-base64-min-length", scanLocalPathCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanLocalPath.BindPFlag("entropy-hex-threshold", scanLocalPathCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanLocalPath.BindPFlag("entropy-hex-min-length", scanLocalPathCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanLocalPath.BindPFlag("baseline", scanLocalPathCmd.Flags().Lookup("baseline"))
+	err = viperScanLocalPath.BindPFlag("db-path", scanLocalPathCmd.Flags().Lookup("db-path"))
+	err = viperScanLocalPath.BindPFlag("verify", scanLocalPathCmd.Flags().Lookup("verify"))
+	err = viperScanLocalPath.BindPFlag("web-username", scanLocalPathCmd.Flags().Lookup("web-username"))
+	err = viperScanLocalPath.BindPFlag("web-password", scanLocalPathCmd.Flags().Lookup("web-password"))
+	err = viperScanLocalPath.BindPFlag("oidc-issuer", scanLocalPathCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanLocalPath.BindPFlag("oidc-client-id", scanLocalPathCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanLocalPath.BindPFlag("oidc-client-secret", scanLocalPathCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanLocalPath.BindPFlag("oidc-redirect-url", scanLocalPathCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanLocalPath.BindPFlag("oidc-allowed-emails", scanLocalPathCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanLocalPath.BindPFlag("ci", scanLocalPathCmd.Flags().Lookup("ci"))
+	err = viperScanLocalPath.BindPFlag("fail-on", scanLocalPathCmd.Flags().Lookup("fail-on"))
+	err = viperScanLocalPath.BindPFlag("report-html", scanLocalPathCmd.Flags().Lookup("report-html"))
+	err = viperScanLocalPath.BindPFlag("archive-depth", scanLocalPathCmd.Flags().Lookup("archive-depth"))
+	err = viperScanLocalPath.BindPFlag("archive-max-size", scanLocalPathCmd.Flags().Lookup("archive-max-size"))
+	err = viperScanLocalPath.BindPFlag("suppression-markers", scanLocalPathCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanLocalPath.BindPFlag("webhook-url", scanLocalPathCmd.Flags().Lookup("webhook-url"))
+	err = viperScanLocalPath.BindPFlag("webhook-header", scanLocalPathCmd.Flags().Lookup("webhook-header"))
+	err = viperScanLocalPath.BindPFlag("webhook-secret", scanLocalPathCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanLocalPath.BindPFlag("csv", scanLocalPathCmd.Flags().Lookup("csv"))
+	err = viperScanLocalPath.BindPFlag("triage-file", scanLocalPathCmd.Flags().Lookup("triage-file"))
+	err = viperScanLocalPath.BindPFlag("min-severity", scanLocalPathCmd.Flags().Lookup("min-severity"))
+	err = viperScanLocalPath.BindPFlag("min-confidence", scanLocalPathCmd.Flags().Lookup("min-confidence"))
+	err = viperScanLocalPath.BindPFlag("decode", scanLocalPathCmd.Flags().Lookup("decode"))
+	err = viperScanLocalPath.BindPFlag("decode-min-length", scanLocalPathCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanLocalPath.BindPFlag("slack-webhook", scanLocalPathCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanLocalPath.BindPFlag("slack-token", scanLocalPathCmd.Flags().Lookup("slack-token"))
+	err = viperScanLocalPath.BindPFlag("slack-channel", scanLocalPathCmd.Flags().Lookup("slack-channel"))
+	err = viperScanLocalPath.BindPFlag("api-scans", scanLocalPathCmd.Flags().Lookup("api-scans"))
+	err = viperScanLocalPath.BindPFlag("log-format", scanLocalPathCmd.Flags().Lookup("log-format"))
+	err = viperScanLocalPath.BindPFlag("log-file", scanLocalPathCmd.Flags().Lookup("log-file"))
+	err = viperScanLocalPath.BindPFlag("gitlab-report", scanLocalPathCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanLocalPath.BindPFlag("elasticsearch-url", scanLocalPathCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanLocalPath.BindPFlag("elasticsearch-index", scanLocalPathCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanLocalPath.BindPFlag("elasticsearch-username", scanLocalPathCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanLocalPath.BindPFlag("elasticsearch-password", scanLocalPathCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanLocalPath.BindPFlag("elasticsearch-api-key", scanLocalPathCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanLocalPath.BindPFlag("splunk-url", scanLocalPathCmd.Flags().Lookup("splunk-url"))
+	err = viperScanLocalPath.BindPFlag("splunk-token", scanLocalPathCmd.Flags().Lookup("splunk-token"))
+	err = viperScanLocalPath.BindPFlag("splunk-index", scanLocalPathCmd.Flags().Lookup("splunk-index"))
+	err = viperScanLocalPath.BindPFlag("splunk-sourcetype", scanLocalPathCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-url", scanLocalPathCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-token", scanLocalPathCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-product", scanLocalPathCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-engagement", scanLocalPathCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-test-title", scanLocalPathCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanLocalPath.BindPFlag("defectdojo-close-old", scanLocalPathCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanLocalPath.BindPFlag("proxy", scanLocalPathCmd.Flags().Lookup("proxy"))
+	err = viperScanLocalPath.BindPFlag("ca-bundle", scanLocalPathCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanLocalPath.BindPFlag("insecure-skip-verify", scanLocalPathCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanLocalPath.BindPFlag("context-lines", scanLocalPathCmd.Flags().Lookup("context-lines"))
+	err = viperScanLocalPath.BindPFlag("dedup-findings", scanLocalPathCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanLocalPath.BindPFlag("junit", scanLocalPathCmd.Flags().Lookup("junit"))
+	err = viperScanLocalPath.BindPFlag("signature-tags", scanLocalPathCmd.Flags().Lookup("signature-tags"))
+	err = viperScanLocalPath.BindPFlag("enable-signatures", scanLocalPathCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanLocalPath.BindPFlag("disable-signatures", scanLocalPathCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanLocalPath.BindPFlag("encrypt-report", scanLocalPathCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanLocalPath.BindPFlag("report-s3", scanLocalPathCmd.Flags().Lookup("report-s3"))
+	err = viperScanLocalPath.BindPFlag("report-s3-endpoint", scanLocalPathCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanLocalPath.BindPFlag("report-s3-region", scanLocalPathCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanLocalPath.BindPFlag("report-s3-sse", scanLocalPathCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanLocalPath.BindPFlag("report-s3-kms-key-id", scanLocalPathCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanLocalPath.BindPFlag("scan-terraform-state", scanLocalPathCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanLocalPath.BindPFlag("scan-binaries", scanLocalPathCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanLocalPath.BindPFlag("binary-min-length", scanLocalPathCmd.Flags().Lookup("binary-min-length"))
+	err = scanLocalPathCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
 
 	if err != nil {
 		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
@@ -0,0 +1,345 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+var viperScanRepoList *viper.Viper
+
+// scanRepoListCmd represents the scanRepoList command
+var scanRepoListCmd = &cobra.Command{
+	Use:   "scanRepoList",
+	Short: "Scan a list of git repos from a file",
+	Long:  "Scan the git repos whose urls are listed in a file, one per line, on any host that can be cloned from",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "repoList"
+		sess := core.NewSession(viperScanRepoList, scanType)
+		sess.HandleInterrupts()
+
+		if sess.ListTargets {
+			if err := core.ListTargets(sess, os.Stdout); err != nil {
+				sess.Out.Fatal("Failed to list the targets: %s\n", err)
+			}
+			return
+		}
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", "127.0.0.1", 9393)
+
+		if err := core.GatherRepoList(sess); err != nil {
+			sess.Out.Fatal("Failed to read the repos in %s: %s\n", sess.InputFile, err)
+		}
+		if err := core.AnalyzeRepositories(sess); err != nil {
+			sess.Out.Error("%s\n", err)
+			sess.Out.Exit(2)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanRepoListCmd)
+
+	viperScanRepoList = core.SetConfig()
+
+	scanRepoListCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanRepoListCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanRepoListCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanRepoListCmd.Flags().Bool("hide-secrets", false, "Hide secrets from output")
+	scanRepoListCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	scanRepoListCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanRepoListCmd.Flags().Bool("silent", false, "No output")
+	scanRepoListCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	scanRepoListCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	scanRepoListCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanRepoListCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanRepoListCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	scanRepoListCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	scanRepoListCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	scanRepoListCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	scanRepoListCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	scanRepoListCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	scanRepoListCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanRepoListCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanRepoListCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanRepoListCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanRepoListCmd.Flags().String("input-file", "", "A file of git urls to scan, one per line, each optionally followed by branch=, username=, token-env= or ssh-key= hints")
+	scanRepoListCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanRepoListCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanRepoListCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanRepoListCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanRepoListCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanRepoListCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanRepoListCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanRepoListCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanRepoListCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanRepoListCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanRepoListCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanRepoListCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanRepoListCmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanRepoListCmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanRepoListCmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanRepoListCmd.Flags().String("on-repo-complete-exec", "", "A command to run when a repo is scanned with the repo and its findings as json on stdin")
+	scanRepoListCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanRepoListCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanRepoListCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanRepoListCmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanRepoListCmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanRepoListCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanRepoListCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanRepoListCmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanRepoListCmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanRepoListCmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanRepoListCmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanRepoListCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanRepoListCmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanRepoListCmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanRepoListCmd.Flags().Bool("incremental", false, "Only scan the commits added to each repository since the last incremental scan")
+	scanRepoListCmd.Flags().String("scan-state-file", "$HOME/.wraith/scan-state.json", "File the last commit scanned in each repository is kept in for incremental scans")
+	scanRepoListCmd.Flags().String("checkpoint", "", "Write the progress of the scan to this file as it goes, so it can be resumed if it is interrupted")
+	scanRepoListCmd.Flags().Int("checkpoint-interval", 60, "Seconds between writes of the checkpoint as repositories are finished")
+	scanRepoListCmd.Flags().String("resume", "", "Continue the interrupted scan whose checkpoint is in this file rather than starting over")
+	scanRepoListCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanRepoListCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanRepoListCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanRepoListCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanRepoListCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanRepoListCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanRepoListCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanRepoListCmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanRepoListCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanRepoListCmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanRepoListCmd.Flags().String("scan-branches", "", "Scan the commits of these comma separated branches, or all of them, along with the default branch")
+	scanRepoListCmd.Flags().String("since-commit", "", "Only scan the commits added after this commit, tag or branch")
+	scanRepoListCmd.Flags().String("since-date", "", "Only scan the commits committed after a date such as 2020-08-03, a time in RFC3339 or a number of days such as 7d")
+	scanRepoListCmd.Flags().String("until-commit", "", "Scan the history from this commit, tag or branch back in place of the heads of the branches")
+	scanRepoListCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanRepoListCmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanRepoListCmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanRepoListCmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanRepoListCmd.Flags().Bool("scan-commit-messages", true, "Match the signatures against commit messages as well as files")
+	scanRepoListCmd.Flags().Bool("scan-notes", true, "Fetch the git notes of each repository and match the signatures against them")
+	scanRepoListCmd.Flags().Bool("scan-submodules", false, "Clone the submodules of each repository and scan them along with it")
+	scanRepoListCmd.Flags().Int("submodule-depth", core.DefaultSubmoduleDepth, "How many levels of nested submodules to scan with scan-submodules")
+	scanRepoListCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanRepoListCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanRepoListCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanRepoListCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanRepoListCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanRepoListCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanRepoListCmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanRepoListCmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanRepoListCmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanRepoListCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanRepoListCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanRepoListCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanRepoListCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	scanRepoListCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	scanRepoListCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	scanRepoListCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	scanRepoListCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanRepoListCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	scanRepoListCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanRepoListCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	scanRepoListCmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanRepoListCmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanRepoListCmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanRepoListCmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanRepoListCmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanRepoListCmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanRepoListCmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanRepoListCmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanRepoListCmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanRepoListCmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanRepoListCmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanRepoListCmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanRepoListCmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanRepoListCmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanRepoListCmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanRepoListCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanRepoListCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanRepoListCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanRepoListCmd.Flags().Bool("list-targets", false, "Only list the targets and repositories that would be scanned, with their sizes, without cloning anything")
+	scanRepoListCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanRepoListCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanRepoListCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanRepoListCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanRepoListCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanRepoListCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanRepoListCmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanRepoListCmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanRepoListCmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanRepoListCmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanRepoListCmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanRepoListCmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanRepoListCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanRepoListCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanRepoListCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperScanRepoList.BindPFlag("bind-address", scanRepoListCmd.Flags().Lookup("bind-address"))
+	err = viperScanRepoList.BindPFlag("bind-port", scanRepoListCmd.Flags().Lookup("bind-port"))
+	err = viperScanRepoList.BindPFlag("commit-depth", scanRepoListCmd.Flags().Lookup("commit-depth"))
+	err = viperScanRepoList.BindPFlag("debug", scanRepoListCmd.Flags().Lookup("debug"))
+	err = viperScanRepoList.BindPFlag("debug-server", scanRepoListCmd.Flags().Lookup("debug-server"))
+	err = viperScanRepoList.BindPFlag("debug-server-public", scanRepoListCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanRepoList.BindPFlag("hide-secrets", scanRepoListCmd.Flags().Lookup("hide-secrets"))
+	err = viperScanRepoList.BindPFlag("ignore-extension", scanRepoListCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanRepoList.BindPFlag("ignore-path", scanRepoListCmd.Flags().Lookup("ignore-path"))
+	err = viperScanRepoList.BindPFlag("path-include", scanRepoListCmd.Flags().Lookup("path-include"))
+	err = viperScanRepoList.BindPFlag("path-exclude", scanRepoListCmd.Flags().Lookup("path-exclude"))
+	err = viperScanRepoList.BindPFlag("input-file", scanRepoListCmd.Flags().Lookup("input-file"))
+	err = viperScanRepoList.BindPFlag("in-mem-clone", scanRepoListCmd.Flags().Lookup("in-mem-clone"))
+	err = viperScanRepoList.BindPFlag("match-level", scanRepoListCmd.Flags().Lookup("match-level"))
+	err = viperScanRepoList.BindPFlag("max-file-size", scanRepoListCmd.Flags().Lookup("max-file-size"))
+	err = viperScanRepoList.BindPFlag("num-threads", scanRepoListCmd.Flags().Lookup("num-threads"))
+	err = viperScanRepoList.BindPFlag("clone-threads", scanRepoListCmd.Flags().Lookup("clone-threads"))
+	err = viperScanRepoList.BindPFlag("walk-threads", scanRepoListCmd.Flags().Lookup("walk-threads"))
+	err = viperScanRepoList.BindPFlag("match-threads", scanRepoListCmd.Flags().Lookup("match-threads"))
+	err = viperScanRepoList.BindPFlag("pipeline-queue-size", scanRepoListCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperScanRepoList.BindPFlag("scan-tests", scanRepoListCmd.Flags().Lookup("scan-tests"))
+	err = viperScanRepoList.BindPFlag("signature-file", scanRepoListCmd.Flags().Lookup("signature-file"))
+	err = viperScanRepoList.BindPFlag("silent", scanRepoListCmd.Flags().Lookup("silent"))
+	err = viperScanRepoList.BindPFlag("detector-plugins", scanRepoListCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanRepoList.BindPFlag("plugin-timeout", scanRepoListCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanRepoList.BindPFlag("wasm-plugin-dir", scanRepoListCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanRepoList.BindPFlag("grpc-port", scanRepoListCmd.Flags().Lookup("grpc-port"))
+	err = viperScanRepoList.BindPFlag("grpc-token", scanRepoListCmd.Flags().Lookup("grpc-token"))
+	err = viperScanRepoList.BindPFlag("grpc-tls-cert", scanRepoListCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanRepoList.BindPFlag("grpc-tls-key", scanRepoListCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanRepoList.BindPFlag("grpc-client-ca", scanRepoListCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanRepoList.BindPFlag("allow-local-scans", scanRepoListCmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanRepoList.BindPFlag("scan-retention", scanRepoListCmd.Flags().Lookup("scan-retention"))
+	err = viperScanRepoList.BindPFlag("json", scanRepoListCmd.Flags().Lookup("json"))
+	err = viperScanRepoList.BindPFlag("jsonl", scanRepoListCmd.Flags().Lookup("jsonl"))
+	err = viperScanRepoList.BindPFlag("stream", scanRepoListCmd.Flags().Lookup("stream"))
+	err = viperScanRepoList.BindPFlag("on-finding-exec", scanRepoListCmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanRepoList.BindPFlag("on-repo-complete-exec", scanRepoListCmd.Flags().Lookup("on-repo-complete-exec"))
+	err = viperScanRepoList.BindPFlag("finding-script", scanRepoListCmd.Flags().Lookup("finding-script"))
+	err = viperScanRepoList.BindPFlag("redact", scanRepoListCmd.Flags().Lookup("redact"))
+	err = viperScanRepoList.BindPFlag("history-file", scanRepoListCmd.Flags().Lookup("history-file"))
+	err = viperScanRepoList.BindPFlag("alert-state-file", scanRepoListCmd.Flags().Lookup("alert-state-file"))
+	err = viperScanRepoList.BindPFlag("realert-interval", scanRepoListCmd.Flags().Lookup("realert-interval"))
+	err = viperScanRepoList.BindPFlag("policy", scanRepoListCmd.Flags().Lookup("policy"))
+	err = viperScanRepoList.BindPFlag("entropy", scanRepoListCmd.Flags().Lookup("entropy"))
+	err = viperScanRepoList.BindPFlag("entropy-base64-threshold", scanRepoListCmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanRepoList.BindPFlag("entropy-base64-min-length", scanRepoListCmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanRepoList.BindPFlag("entropy-hex-threshold", scanRepoListCmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanRepoList.BindPFlag("entropy-hex-min-length", scanRepoListCmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanRepoList.BindPFlag("baseline", scanRepoListCmd.Flags().Lookup("baseline"))
+	err = viperScanRepoList.BindPFlag("db-path", scanRepoListCmd.Flags().Lookup("db-path"))
+	err = viperScanRepoList.BindPFlag("verify", scanRepoListCmd.Flags().Lookup("verify"))
+	err = viperScanRepoList.BindPFlag("incremental", scanRepoListCmd.Flags().Lookup("incremental"))
+	err = viperScanRepoList.BindPFlag("scan-state-file", scanRepoListCmd.Flags().Lookup("scan-state-file"))
+	err = viperScanRepoList.BindPFlag("checkpoint", scanRepoListCmd.Flags().Lookup("checkpoint"))
+	err = viperScanRepoList.BindPFlag("checkpoint-interval", scanRepoListCmd.Flags().Lookup("checkpoint-interval"))
+	err = viperScanRepoList.BindPFlag("resume", scanRepoListCmd.Flags().Lookup("resume"))
+	err = viperScanRepoList.BindPFlag("web-username", scanRepoListCmd.Flags().Lookup("web-username"))
+	err = viperScanRepoList.BindPFlag("web-password", scanRepoListCmd.Flags().Lookup("web-password"))
+	err = viperScanRepoList.BindPFlag("oidc-issuer", scanRepoListCmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanRepoList.BindPFlag("oidc-client-id", scanRepoListCmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanRepoList.BindPFlag("oidc-client-secret", scanRepoListCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanRepoList.BindPFlag("oidc-redirect-url", scanRepoListCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanRepoList.BindPFlag("oidc-allowed-emails", scanRepoListCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanRepoList.BindPFlag("ci", scanRepoListCmd.Flags().Lookup("ci"))
+	err = viperScanRepoList.BindPFlag("fail-on", scanRepoListCmd.Flags().Lookup("fail-on"))
+	err = viperScanRepoList.BindPFlag("report-html", scanRepoListCmd.Flags().Lookup("report-html"))
+	err = viperScanRepoList.BindPFlag("scan-branches", scanRepoListCmd.Flags().Lookup("scan-branches"))
+	err = viperScanRepoList.BindPFlag("since-commit", scanRepoListCmd.Flags().Lookup("since-commit"))
+	err = viperScanRepoList.BindPFlag("since-date", scanRepoListCmd.Flags().Lookup("since-date"))
+	err = viperScanRepoList.BindPFlag("until-commit", scanRepoListCmd.Flags().Lookup("until-commit"))
+	err = viperScanRepoList.BindPFlag("suppression-markers", scanRepoListCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanRepoList.BindPFlag("webhook-url", scanRepoListCmd.Flags().Lookup("webhook-url"))
+	err = viperScanRepoList.BindPFlag("webhook-header", scanRepoListCmd.Flags().Lookup("webhook-header"))
+	err = viperScanRepoList.BindPFlag("webhook-secret", scanRepoListCmd.Flags().Lookup("webhook-secret"))
+	err = viperScanRepoList.BindPFlag("scan-commit-messages", scanRepoListCmd.Flags().Lookup("scan-commit-messages"))
+	err = viperScanRepoList.BindPFlag("scan-notes", scanRepoListCmd.Flags().Lookup("scan-notes"))
+	err = viperScanRepoList.BindPFlag("scan-submodules", scanRepoListCmd.Flags().Lookup("scan-submodules"))
+	err = viperScanRepoList.BindPFlag("submodule-depth", scanRepoListCmd.Flags().Lookup("submodule-depth"))
+	err = viperScanRepoList.BindPFlag("csv", scanRepoListCmd.Flags().Lookup("csv"))
+	err = viperScanRepoList.BindPFlag("triage-file", scanRepoListCmd.Flags().Lookup("triage-file"))
+	err = viperScanRepoList.BindPFlag("min-severity", scanRepoListCmd.Flags().Lookup("min-severity"))
+	err = viperScanRepoList.BindPFlag("min-confidence", scanRepoListCmd.Flags().Lookup("min-confidence"))
+	err = viperScanRepoList.BindPFlag("decode", scanRepoListCmd.Flags().Lookup("decode"))
+	err = viperScanRepoList.BindPFlag("decode-min-length", scanRepoListCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanRepoList.BindPFlag("slack-webhook", scanRepoListCmd.Flags().Lookup("slack-webhook"))
+	err = viperScanRepoList.BindPFlag("slack-token", scanRepoListCmd.Flags().Lookup("slack-token"))
+	err = viperScanRepoList.BindPFlag("slack-channel", scanRepoListCmd.Flags().Lookup("slack-channel"))
+	err = viperScanRepoList.BindPFlag("api-scans", scanRepoListCmd.Flags().Lookup("api-scans"))
+	err = viperScanRepoList.BindPFlag("log-format", scanRepoListCmd.Flags().Lookup("log-format"))
+	err = viperScanRepoList.BindPFlag("log-file", scanRepoListCmd.Flags().Lookup("log-file"))
+	err = viperScanRepoList.BindPFlag("clone-cache", scanRepoListCmd.Flags().Lookup("clone-cache"))
+	err = viperScanRepoList.BindPFlag("ssh-key", scanRepoListCmd.Flags().Lookup("ssh-key"))
+	err = viperScanRepoList.BindPFlag("ssh-key-passphrase", scanRepoListCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperScanRepoList.BindPFlag("ssh-known-hosts", scanRepoListCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperScanRepoList.BindPFlag("retries", scanRepoListCmd.Flags().Lookup("retries"))
+	err = viperScanRepoList.BindPFlag("retry-backoff", scanRepoListCmd.Flags().Lookup("retry-backoff"))
+	err = viperScanRepoList.BindPFlag("gitlab-report", scanRepoListCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanRepoList.BindPFlag("in-mem-clone-budget", scanRepoListCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperScanRepoList.BindPFlag("elasticsearch-url", scanRepoListCmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanRepoList.BindPFlag("elasticsearch-index", scanRepoListCmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanRepoList.BindPFlag("elasticsearch-username", scanRepoListCmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanRepoList.BindPFlag("elasticsearch-password", scanRepoListCmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanRepoList.BindPFlag("elasticsearch-api-key", scanRepoListCmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanRepoList.BindPFlag("splunk-url", scanRepoListCmd.Flags().Lookup("splunk-url"))
+	err = viperScanRepoList.BindPFlag("splunk-token", scanRepoListCmd.Flags().Lookup("splunk-token"))
+	err = viperScanRepoList.BindPFlag("splunk-index", scanRepoListCmd.Flags().Lookup("splunk-index"))
+	err = viperScanRepoList.BindPFlag("splunk-sourcetype", scanRepoListCmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanRepoList.BindPFlag("defectdojo-url", scanRepoListCmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanRepoList.BindPFlag("defectdojo-token", scanRepoListCmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanRepoList.BindPFlag("defectdojo-product", scanRepoListCmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanRepoList.BindPFlag("defectdojo-engagement", scanRepoListCmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanRepoList.BindPFlag("defectdojo-test-title", scanRepoListCmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanRepoList.BindPFlag("defectdojo-close-old", scanRepoListCmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanRepoList.BindPFlag("proxy", scanRepoListCmd.Flags().Lookup("proxy"))
+	err = viperScanRepoList.BindPFlag("ca-bundle", scanRepoListCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanRepoList.BindPFlag("insecure-skip-verify", scanRepoListCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanRepoList.BindPFlag("list-targets", scanRepoListCmd.Flags().Lookup("list-targets"))
+	err = viperScanRepoList.BindPFlag("context-lines", scanRepoListCmd.Flags().Lookup("context-lines"))
+	err = viperScanRepoList.BindPFlag("dedup-findings", scanRepoListCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanRepoList.BindPFlag("junit", scanRepoListCmd.Flags().Lookup("junit"))
+	err = viperScanRepoList.BindPFlag("signature-tags", scanRepoListCmd.Flags().Lookup("signature-tags"))
+	err = viperScanRepoList.BindPFlag("enable-signatures", scanRepoListCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanRepoList.BindPFlag("disable-signatures", scanRepoListCmd.Flags().Lookup("disable-signatures"))
+	err = viperScanRepoList.BindPFlag("encrypt-report", scanRepoListCmd.Flags().Lookup("encrypt-report"))
+	err = viperScanRepoList.BindPFlag("report-s3", scanRepoListCmd.Flags().Lookup("report-s3"))
+	err = viperScanRepoList.BindPFlag("report-s3-endpoint", scanRepoListCmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanRepoList.BindPFlag("report-s3-region", scanRepoListCmd.Flags().Lookup("report-s3-region"))
+	err = viperScanRepoList.BindPFlag("report-s3-sse", scanRepoListCmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanRepoList.BindPFlag("report-s3-kms-key-id", scanRepoListCmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanRepoList.BindPFlag("scan-terraform-state", scanRepoListCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanRepoList.BindPFlag("scan-binaries", scanRepoListCmd.Flags().Lookup("scan-binaries"))
+	err = viperScanRepoList.BindPFlag("binary-min-length", scanRepoListCmd.Flags().Lookup("binary-min-length"))
+	err = scanRepoListCmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
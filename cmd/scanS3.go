@@ -0,0 +1,282 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanS3 *viper.Viper
+
+// scanS3Cmd represents the scanS3 command that will scan the objects in s3 buckets
+var scanS3Cmd = &cobra.Command{
+	Use:   "scanS3",
+	Short: "Scan the objects in one or more s3 buckets for secrets.",
+	Long:  `Scan the objects in one or more s3 buckets, or under a prefix of them, for secrets using the same aws credentials as the aws cli.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		scanType := "s3"
+		sess := core.NewSession(viperScanS3, scanType)
+		sess.HandleInterrupts()
+
+		//sess.Out.Info("%s\n\n", common.ASCIIBanner)
+		sess.Out.Important("%s v%s started at %s\n", core.Name, version.AppVersion(), sess.Stats.StartedAt.Format(time.RFC3339))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+
+		if err := core.ScanS3(sess); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		if sess.CI {
+			core.PrintCISummary(sess)
+		} else {
+			core.PrintSessionStats(sess)
+		}
+
+		if !sess.Silent && !sess.Interrupted() {
+			sess.Out.Important("Press Ctrl+C to stop web server and exit.")
+			select {}
+		}
+
+		if code := sess.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanS3Cmd)
+
+	viperScanS3 = core.SetConfig()
+
+	scanS3Cmd.Flags().Bool("debug", false, "Print debugging information")
+	scanS3Cmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanS3Cmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanS3Cmd.Flags().Bool("hide-secrets", false, "Show secrets in any supported output")
+	scanS3Cmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanS3Cmd.Flags().Bool("silent", false, "Suppress all output except for errors")
+	scanS3Cmd.Flags().Int64("max-file-size", 50, "Max file size to scan")
+	scanS3Cmd.Flags().Int("match-level", 3, "The match level of the expressions used to find matches")
+	scanS3Cmd.Flags().String("ignore-extension", "", "a list of extensions to ignore during a scan")
+	scanS3Cmd.Flags().String("ignore-path", "", "a list of paths to ignore during a scan")
+	scanS3Cmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanS3Cmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanS3Cmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanS3Cmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanS3Cmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	scanS3Cmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	scanS3Cmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	scanS3Cmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	scanS3Cmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	scanS3Cmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	scanS3Cmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	scanS3Cmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanS3Cmd.Flags().String("jsonl", "", "Write the findings to this file as json lines")
+	scanS3Cmd.Flags().Bool("stream", false, "Write each finding to stdout as a json line the moment it is found, with the logs going to stderr")
+	scanS3Cmd.Flags().String("on-finding-exec", "", "A command to run for every finding with the finding as json on stdin")
+	scanS3Cmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanS3Cmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanS3Cmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	scanS3Cmd.Flags().String("alert-state-file", "", "Remember which findings each hook has been sent in this file and only send new ones")
+	scanS3Cmd.Flags().Int("realert-interval", 0, "Hours before a hook is sent a finding it has already been sent again, never when 0")
+	scanS3Cmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanS3Cmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanS3Cmd.Flags().Float64("entropy-base64-threshold", 4.5, "The entropy a base64 string must reach to be reported")
+	scanS3Cmd.Flags().Int("entropy-base64-min-length", 20, "The shortest base64 string checked for entropy")
+	scanS3Cmd.Flags().Float64("entropy-hex-threshold", 3.0, "The entropy a hex string must reach to be reported")
+	scanS3Cmd.Flags().Int("entropy-hex-min-length", 20, "The shortest hex string checked for entropy")
+	scanS3Cmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanS3Cmd.Flags().String("db-path", "", "A sqlite database to keep this session, its targets and its findings in")
+	scanS3Cmd.Flags().Bool("verify", false, "Check found secrets against their service and tag them verified, inactive or unknown")
+	scanS3Cmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	scanS3Cmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	scanS3Cmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	scanS3Cmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	scanS3Cmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	scanS3Cmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	scanS3Cmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	scanS3Cmd.Flags().Bool("ci", false, "Run without the web interface, print a compact summary and exit non-zero when a fail-on threshold is reached")
+	scanS3Cmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 with --ci when empty")
+	scanS3Cmd.Flags().String("report-html", "", "Write a standalone html report of the findings, grouped by repository and signature, to this file")
+	scanS3Cmd.Flags().String("s3-targets", "", "A space separated list of buckets to scan, optionally with a prefix, such as s3://acme-backups/db/")
+	scanS3Cmd.Flags().String("s3-region", "", "The region of the buckets, AWS_REGION or us-east-1 when empty, buckets elsewhere are found anyway")
+	scanS3Cmd.Flags().String("s3-endpoint", "", "The url of an s3 compatible service, such as minio, to use in place of aws")
+	scanS3Cmd.Flags().Bool("s3-anonymous", false, "Send unsigned requests when no aws credentials are found, which can only list public buckets")
+	scanS3Cmd.Flags().String("aws-profile", "", "The profile in the shared aws credentials file to use, AWS_PROFILE or the environment when empty")
+	scanS3Cmd.Flags().Int("num-threads", 0, "The number of objects to download and scan at a time")
+	scanS3Cmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanS3Cmd.Flags().String("webhook-url", "", "Post each finding and a summary of the scan as json to this url")
+	scanS3Cmd.Flags().StringSlice("webhook-header", []string{}, "A header to send to the webhook as \"Name: value\", can be repeated")
+	scanS3Cmd.Flags().String("webhook-secret", "", "Sign the webhook payloads with HMAC-SHA256 using this secret")
+	scanS3Cmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanS3Cmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	scanS3Cmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanS3Cmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanS3Cmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanS3Cmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanS3Cmd.Flags().String("slack-webhook", "", "Post a summary of the findings in each repository, and a digest of the scan, to this slack incoming webhook")
+	scanS3Cmd.Flags().String("slack-token", "", "A slack bot token with the chat:write scope to post to --slack-channel with instead of a webhook")
+	scanS3Cmd.Flags().String("slack-channel", "", "The slack channel to post to with --slack-token")
+	scanS3Cmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	scanS3Cmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	scanS3Cmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	scanS3Cmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanS3Cmd.Flags().String("elasticsearch-url", "", "Index the findings and summary of the scan into the elasticsearch or opensearch cluster at this url")
+	scanS3Cmd.Flags().String("elasticsearch-index", "wraith", "The prefix of the elasticsearch indexes, findings go in <prefix>-findings and summaries in <prefix>-sessions")
+	scanS3Cmd.Flags().String("elasticsearch-username", "", "The username to authenticate to elasticsearch with")
+	scanS3Cmd.Flags().String("elasticsearch-password", "", "The password to authenticate to elasticsearch with")
+	scanS3Cmd.Flags().String("elasticsearch-api-key", "", "The api key to authenticate to elasticsearch with, in place of a username")
+	scanS3Cmd.Flags().String("splunk-url", "", "Send the findings and summary of the scan to the splunk http event collector at this url")
+	scanS3Cmd.Flags().String("splunk-token", "", "The http event collector token to send events to splunk with")
+	scanS3Cmd.Flags().String("splunk-index", "", "The splunk index to send events to, the default index of the token when not given")
+	scanS3Cmd.Flags().String("splunk-sourcetype", "wraith", "The prefix of the splunk sourcetypes, findings are sent as <prefix>:finding and summaries as <prefix>:session")
+	scanS3Cmd.Flags().String("defectdojo-url", "", "Import the findings of the scan into the defectdojo instance at this url")
+	scanS3Cmd.Flags().String("defectdojo-token", "", "The api key to import findings into defectdojo with")
+	scanS3Cmd.Flags().String("defectdojo-product", "", "The defectdojo product to import findings into")
+	scanS3Cmd.Flags().String("defectdojo-engagement", "", "The defectdojo engagement to import findings into, created in the product when it does not exist")
+	scanS3Cmd.Flags().String("defectdojo-test-title", "wraith", "The title of the defectdojo test findings are imported into, each import updates the same test")
+	scanS3Cmd.Flags().Bool("defectdojo-close-old", false, "Close the findings in the defectdojo test that the scan did not find again")
+	scanS3Cmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanS3Cmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanS3Cmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanS3Cmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanS3Cmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanS3Cmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanS3Cmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanS3Cmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanS3Cmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	scanS3Cmd.Flags().String("encrypt-report", "", "Encrypt the json, jsonl, csv and html reports to this age public key, or file of age or gpg public keys, adding .age or .gpg to their names")
+	scanS3Cmd.Flags().String("report-s3", "", "Upload the reports that are written to this s3 bucket and prefix, such as s3://acme-security/wraith, under the id of the scan")
+	scanS3Cmd.Flags().String("report-s3-endpoint", "", "The url of an s3 compatible service, such as minio, to upload the reports to in place of aws")
+	scanS3Cmd.Flags().String("report-s3-region", "", "The region of the report-s3 bucket, AWS_REGION or us-east-1 when empty")
+	scanS3Cmd.Flags().String("report-s3-sse", "", "The server side encryption the reports are stored with, AES256 or aws:kms, the default of the bucket when empty")
+	scanS3Cmd.Flags().String("report-s3-kms-key-id", "", "The kms key the reports are encrypted with when report-s3-sse is aws:kms, the aws managed key when empty")
+	scanS3Cmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	scanS3Cmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	scanS3Cmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+	scanS3Cmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	scanS3Cmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+
+	err := viperScanS3.BindPFlag("debug", scanS3Cmd.Flags().Lookup("debug"))
+	err = viperScanS3.BindPFlag("debug-server", scanS3Cmd.Flags().Lookup("debug-server"))
+	err = viperScanS3.BindPFlag("debug-server-public", scanS3Cmd.Flags().Lookup("debug-server-public"))
+	err = viperScanS3.BindPFlag("hide-secrets", scanS3Cmd.Flags().Lookup("hide-secrets"))
+	err = viperScanS3.BindPFlag("scan-tests", scanS3Cmd.Flags().Lookup("scan-tests"))
+	err = viperScanS3.BindPFlag("silent", scanS3Cmd.Flags().Lookup("silent"))
+	err = viperScanS3.BindPFlag("max-file-size", scanS3Cmd.Flags().Lookup("max-file-size"))
+	err = viperScanS3.BindPFlag("match-level", scanS3Cmd.Flags().Lookup("match-level"))
+	err = viperScanS3.BindPFlag("ignore-extension", scanS3Cmd.Flags().Lookup("ignore-extension"))
+	err = viperScanS3.BindPFlag("ignore-path", scanS3Cmd.Flags().Lookup("ignore-path"))
+	err = viperScanS3.BindPFlag("signature-file", scanS3Cmd.Flags().Lookup("signature-file"))
+	err = viperScanS3.BindPFlag("detector-plugins", scanS3Cmd.Flags().Lookup("detector-plugins"))
+	err = viperScanS3.BindPFlag("plugin-timeout", scanS3Cmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanS3.BindPFlag("wasm-plugin-dir", scanS3Cmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanS3.BindPFlag("grpc-port", scanS3Cmd.Flags().Lookup("grpc-port"))
+	err = viperScanS3.BindPFlag("grpc-token", scanS3Cmd.Flags().Lookup("grpc-token"))
+	err = viperScanS3.BindPFlag("grpc-tls-cert", scanS3Cmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperScanS3.BindPFlag("grpc-tls-key", scanS3Cmd.Flags().Lookup("grpc-tls-key"))
+	err = viperScanS3.BindPFlag("grpc-client-ca", scanS3Cmd.Flags().Lookup("grpc-client-ca"))
+	err = viperScanS3.BindPFlag("allow-local-scans", scanS3Cmd.Flags().Lookup("allow-local-scans"))
+	err = viperScanS3.BindPFlag("scan-retention", scanS3Cmd.Flags().Lookup("scan-retention"))
+	err = viperScanS3.BindPFlag("json", scanS3Cmd.Flags().Lookup("json"))
+	err = viperScanS3.BindPFlag("jsonl", scanS3Cmd.Flags().Lookup("jsonl"))
+	err = viperScanS3.BindPFlag("stream", scanS3Cmd.Flags().Lookup("stream"))
+	err = viperScanS3.BindPFlag("on-finding-exec", scanS3Cmd.Flags().Lookup("on-finding-exec"))
+	err = viperScanS3.BindPFlag("finding-script", scanS3Cmd.Flags().Lookup("finding-script"))
+	err = viperScanS3.BindPFlag("redact", scanS3Cmd.Flags().Lookup("redact"))
+	err = viperScanS3.BindPFlag("history-file", scanS3Cmd.Flags().Lookup("history-file"))
+	err = viperScanS3.BindPFlag("alert-state-file", scanS3Cmd.Flags().Lookup("alert-state-file"))
+	err = viperScanS3.BindPFlag("realert-interval", scanS3Cmd.Flags().Lookup("realert-interval"))
+	err = viperScanS3.BindPFlag("policy", scanS3Cmd.Flags().Lookup("policy"))
+	err = viperScanS3.BindPFlag("entropy", scanS3Cmd.Flags().Lookup("entropy"))
+	err = viperScanS3.BindPFlag("entropy-base64-threshold", scanS3Cmd.Flags().Lookup("entropy-base64-threshold"))
+	err = viperScanS3.BindPFlag("entropy-base64-min-length", scanS3Cmd.Flags().Lookup("entropy-base64-min-length"))
+	err = viperScanS3.BindPFlag("entropy-hex-threshold", scanS3Cmd.Flags().Lookup("entropy-hex-threshold"))
+	err = viperScanS3.BindPFlag("entropy-hex-min-length", scanS3Cmd.Flags().Lookup("entropy-hex-min-length"))
+	err = viperScanS3.BindPFlag("baseline", scanS3Cmd.Flags().Lookup("baseline"))
+	err = viperScanS3.BindPFlag("db-path", scanS3Cmd.Flags().Lookup("db-path"))
+	err = viperScanS3.BindPFlag("verify", scanS3Cmd.Flags().Lookup("verify"))
+	err = viperScanS3.BindPFlag("web-username", scanS3Cmd.Flags().Lookup("web-username"))
+	err = viperScanS3.BindPFlag("web-password", scanS3Cmd.Flags().Lookup("web-password"))
+	err = viperScanS3.BindPFlag("oidc-issuer", scanS3Cmd.Flags().Lookup("oidc-issuer"))
+	err = viperScanS3.BindPFlag("oidc-client-id", scanS3Cmd.Flags().Lookup("oidc-client-id"))
+	err = viperScanS3.BindPFlag("oidc-client-secret", scanS3Cmd.Flags().Lookup("oidc-client-secret"))
+	err = viperScanS3.BindPFlag("oidc-redirect-url", scanS3Cmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperScanS3.BindPFlag("oidc-allowed-emails", scanS3Cmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperScanS3.BindPFlag("ci", scanS3Cmd.Flags().Lookup("ci"))
+	err = viperScanS3.BindPFlag("fail-on", scanS3Cmd.Flags().Lookup("fail-on"))
+	err = viperScanS3.BindPFlag("report-html", scanS3Cmd.Flags().Lookup("report-html"))
+	err = viperScanS3.BindPFlag("s3-targets", scanS3Cmd.Flags().Lookup("s3-targets"))
+	err = viperScanS3.BindPFlag("s3-region", scanS3Cmd.Flags().Lookup("s3-region"))
+	err = viperScanS3.BindPFlag("s3-endpoint", scanS3Cmd.Flags().Lookup("s3-endpoint"))
+	err = viperScanS3.BindPFlag("s3-anonymous", scanS3Cmd.Flags().Lookup("s3-anonymous"))
+	err = viperScanS3.BindPFlag("aws-profile", scanS3Cmd.Flags().Lookup("aws-profile"))
+	err = viperScanS3.BindPFlag("num-threads", scanS3Cmd.Flags().Lookup("num-threads"))
+	err = viperScanS3.BindPFlag("suppression-markers", scanS3Cmd.Flags().Lookup("suppression-markers"))
+	err = viperScanS3.BindPFlag("webhook-url", scanS3Cmd.Flags().Lookup("webhook-url"))
+	err = viperScanS3.BindPFlag("webhook-header", scanS3Cmd.Flags().Lookup("webhook-header"))
+	err = viperScanS3.BindPFlag("webhook-secret", scanS3Cmd.Flags().Lookup("webhook-secret"))
+	err = viperScanS3.BindPFlag("csv", scanS3Cmd.Flags().Lookup("csv"))
+	err = viperScanS3.BindPFlag("triage-file", scanS3Cmd.Flags().Lookup("triage-file"))
+	err = viperScanS3.BindPFlag("min-severity", scanS3Cmd.Flags().Lookup("min-severity"))
+	err = viperScanS3.BindPFlag("min-confidence", scanS3Cmd.Flags().Lookup("min-confidence"))
+	err = viperScanS3.BindPFlag("decode", scanS3Cmd.Flags().Lookup("decode"))
+	err = viperScanS3.BindPFlag("decode-min-length", scanS3Cmd.Flags().Lookup("decode-min-length"))
+	err = viperScanS3.BindPFlag("slack-webhook", scanS3Cmd.Flags().Lookup("slack-webhook"))
+	err = viperScanS3.BindPFlag("slack-token", scanS3Cmd.Flags().Lookup("slack-token"))
+	err = viperScanS3.BindPFlag("slack-channel", scanS3Cmd.Flags().Lookup("slack-channel"))
+	err = viperScanS3.BindPFlag("api-scans", scanS3Cmd.Flags().Lookup("api-scans"))
+	err = viperScanS3.BindPFlag("log-format", scanS3Cmd.Flags().Lookup("log-format"))
+	err = viperScanS3.BindPFlag("log-file", scanS3Cmd.Flags().Lookup("log-file"))
+	err = viperScanS3.BindPFlag("gitlab-report", scanS3Cmd.Flags().Lookup("gitlab-report"))
+	err = viperScanS3.BindPFlag("elasticsearch-url", scanS3Cmd.Flags().Lookup("elasticsearch-url"))
+	err = viperScanS3.BindPFlag("elasticsearch-index", scanS3Cmd.Flags().Lookup("elasticsearch-index"))
+	err = viperScanS3.BindPFlag("elasticsearch-username", scanS3Cmd.Flags().Lookup("elasticsearch-username"))
+	err = viperScanS3.BindPFlag("elasticsearch-password", scanS3Cmd.Flags().Lookup("elasticsearch-password"))
+	err = viperScanS3.BindPFlag("elasticsearch-api-key", scanS3Cmd.Flags().Lookup("elasticsearch-api-key"))
+	err = viperScanS3.BindPFlag("splunk-url", scanS3Cmd.Flags().Lookup("splunk-url"))
+	err = viperScanS3.BindPFlag("splunk-token", scanS3Cmd.Flags().Lookup("splunk-token"))
+	err = viperScanS3.BindPFlag("splunk-index", scanS3Cmd.Flags().Lookup("splunk-index"))
+	err = viperScanS3.BindPFlag("splunk-sourcetype", scanS3Cmd.Flags().Lookup("splunk-sourcetype"))
+	err = viperScanS3.BindPFlag("defectdojo-url", scanS3Cmd.Flags().Lookup("defectdojo-url"))
+	err = viperScanS3.BindPFlag("defectdojo-token", scanS3Cmd.Flags().Lookup("defectdojo-token"))
+	err = viperScanS3.BindPFlag("defectdojo-product", scanS3Cmd.Flags().Lookup("defectdojo-product"))
+	err = viperScanS3.BindPFlag("defectdojo-engagement", scanS3Cmd.Flags().Lookup("defectdojo-engagement"))
+	err = viperScanS3.BindPFlag("defectdojo-test-title", scanS3Cmd.Flags().Lookup("defectdojo-test-title"))
+	err = viperScanS3.BindPFlag("defectdojo-close-old", scanS3Cmd.Flags().Lookup("defectdojo-close-old"))
+	err = viperScanS3.BindPFlag("proxy", scanS3Cmd.Flags().Lookup("proxy"))
+	err = viperScanS3.BindPFlag("ca-bundle", scanS3Cmd.Flags().Lookup("ca-bundle"))
+	err = viperScanS3.BindPFlag("insecure-skip-verify", scanS3Cmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanS3.BindPFlag("context-lines", scanS3Cmd.Flags().Lookup("context-lines"))
+	err = viperScanS3.BindPFlag("dedup-findings", scanS3Cmd.Flags().Lookup("dedup-findings"))
+	err = viperScanS3.BindPFlag("junit", scanS3Cmd.Flags().Lookup("junit"))
+	err = viperScanS3.BindPFlag("signature-tags", scanS3Cmd.Flags().Lookup("signature-tags"))
+	err = viperScanS3.BindPFlag("enable-signatures", scanS3Cmd.Flags().Lookup("enable-signatures"))
+	err = viperScanS3.BindPFlag("disable-signatures", scanS3Cmd.Flags().Lookup("disable-signatures"))
+	err = viperScanS3.BindPFlag("encrypt-report", scanS3Cmd.Flags().Lookup("encrypt-report"))
+	err = viperScanS3.BindPFlag("report-s3", scanS3Cmd.Flags().Lookup("report-s3"))
+	err = viperScanS3.BindPFlag("report-s3-endpoint", scanS3Cmd.Flags().Lookup("report-s3-endpoint"))
+	err = viperScanS3.BindPFlag("report-s3-region", scanS3Cmd.Flags().Lookup("report-s3-region"))
+	err = viperScanS3.BindPFlag("report-s3-sse", scanS3Cmd.Flags().Lookup("report-s3-sse"))
+	err = viperScanS3.BindPFlag("report-s3-kms-key-id", scanS3Cmd.Flags().Lookup("report-s3-kms-key-id"))
+	err = viperScanS3.BindPFlag("scan-terraform-state", scanS3Cmd.Flags().Lookup("scan-terraform-state"))
+	err = viperScanS3.BindPFlag("scan-binaries", scanS3Cmd.Flags().Lookup("scan-binaries"))
+	err = viperScanS3.BindPFlag("binary-min-length", scanS3Cmd.Flags().Lookup("binary-min-length"))
+	err = viperScanS3.BindPFlag("retries", scanS3Cmd.Flags().Lookup("retries"))
+	err = viperScanS3.BindPFlag("retry-backoff", scanS3Cmd.Flags().Lookup("retry-backoff"))
+	err = scanS3Cmd.Flags().MarkDeprecated("hide-secrets", "use --redact full instead")
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+)
+
+var viperScanStaged *viper.Viper
+
+// scanStagedCmd represents the scanStaged command that scans the changes staged for the next commit
+var scanStagedCmd = &cobra.Command{
+	Use:   "scanStaged",
+	Short: "Scan the changes staged for the next commit of the current git repository for secrets.",
+	Long: `Scan only the lines staged for the next commit of the current git repository for secrets and exit non-zero
+when any are found. It is meant to run as a pre-commit hook, which installHook will set up.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// a staged scan always runs like a ci scan, without the web interface and failing on any finding by default
+		viperScanStaged.Set("ci", true)
+
+		scanType := "staged"
+		sess := core.NewSession(viperScanStaged, scanType)
+
+		dir, err := os.Getwd()
+		if err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		if err := core.ScanStagedChanges(sess, dir); err != nil {
+			sess.Out.Fatal("%s\n", err)
+		}
+		sess.Finish()
+
+		// stay quiet on a clean commit so the hook does not clutter the output of git commit
+		if code := sess.ExitCode(); code != 0 {
+			core.PrintCISummary(sess)
+			fmt.Println("Remove the secrets from the staged changes, or commit with --no-verify to skip this check.")
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanStagedCmd)
+
+	viperScanStaged = core.SetConfig()
+
+	scanStagedCmd.Flags().Bool("debug", false, "Print debugging information")
+	scanStagedCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	scanStagedCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	scanStagedCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	scanStagedCmd.Flags().Int("match-level", 3, "Signature match level")
+	scanStagedCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	scanStagedCmd.Flags().String("ignore-extension", "", "a comma separated list of extensions to ignore")
+	scanStagedCmd.Flags().String("ignore-path", "", "a comma separated list of paths to ignore")
+	scanStagedCmd.Flags().StringSlice("path-include", []string{}, "Only scan the paths inside a repository or directory that match these gitignore style patterns, such as services/payments/**")
+	scanStagedCmd.Flags().StringSlice("path-exclude", []string{}, "Do not scan the paths inside a repository or directory that match these gitignore style patterns")
+	scanStagedCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	scanStagedCmd.Flags().String("detector-plugins", "", "a comma separated list of detector plugin executables")
+	scanStagedCmd.Flags().Int("plugin-timeout", 30, "Seconds to wait for a detector plugin to scan a file")
+	scanStagedCmd.Flags().String("wasm-plugin-dir", "", "a directory of WebAssembly detector modules to load")
+	scanStagedCmd.Flags().String("json", "", "Write a json report of the findings to this file")
+	scanStagedCmd.Flags().String("finding-script", "", "A lua script used to change, rescore, or suppress findings before they are output")
+	scanStagedCmd.Flags().String("redact", "none", "How secrets are redacted in all output: none, full, partial, hash, or format")
+	scanStagedCmd.Flags().String("policy", "", "A rego policy file or directory that can suppress findings, override their severity and fail the scan")
+	scanStagedCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	scanStagedCmd.Flags().String("baseline", "", "A file of triaged findings to leave out of the output, either a previous json report or one secret id per line")
+	scanStagedCmd.Flags().String("fail-on", "", "Fail when findings reach these severity:count thresholds, such as high:1,medium:10, any:1 when empty")
+	scanStagedCmd.Flags().String("suppression-markers", "wraith:ignore", "Findings on a line with one of these space separated markers, or on the line after it, are left out")
+	scanStagedCmd.Flags().String("csv", "", "Write the findings to this file as csv, one row per finding")
+	scanStagedCmd.Flags().String("min-severity", "", "Leave out findings below this severity: low, medium, high or critical")
+	scanStagedCmd.Flags().String("min-confidence", "", "Leave out findings below this confidence: low, medium or high")
+	scanStagedCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	scanStagedCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	scanStagedCmd.Flags().String("gitlab-report", "", "Write the findings to this file as a gitlab secret detection report for merge request security widgets")
+	scanStagedCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	scanStagedCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	scanStagedCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	scanStagedCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	scanStagedCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	scanStagedCmd.Flags().String("junit", "", "Write the findings to this file as a junit xml report for the test report ui of ci systems such as jenkins")
+	scanStagedCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	scanStagedCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	scanStagedCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+
+	err := viperScanStaged.BindPFlag("debug", scanStagedCmd.Flags().Lookup("debug"))
+	err = viperScanStaged.BindPFlag("debug-server", scanStagedCmd.Flags().Lookup("debug-server"))
+	err = viperScanStaged.BindPFlag("debug-server-public", scanStagedCmd.Flags().Lookup("debug-server-public"))
+	err = viperScanStaged.BindPFlag("scan-tests", scanStagedCmd.Flags().Lookup("scan-tests"))
+	err = viperScanStaged.BindPFlag("match-level", scanStagedCmd.Flags().Lookup("match-level"))
+	err = viperScanStaged.BindPFlag("max-file-size", scanStagedCmd.Flags().Lookup("max-file-size"))
+	err = viperScanStaged.BindPFlag("ignore-extension", scanStagedCmd.Flags().Lookup("ignore-extension"))
+	err = viperScanStaged.BindPFlag("ignore-path", scanStagedCmd.Flags().Lookup("ignore-path"))
+	err = viperScanStaged.BindPFlag("path-include", scanStagedCmd.Flags().Lookup("path-include"))
+	err = viperScanStaged.BindPFlag("path-exclude", scanStagedCmd.Flags().Lookup("path-exclude"))
+	err = viperScanStaged.BindPFlag("signature-file", scanStagedCmd.Flags().Lookup("signature-file"))
+	err = viperScanStaged.BindPFlag("detector-plugins", scanStagedCmd.Flags().Lookup("detector-plugins"))
+	err = viperScanStaged.BindPFlag("plugin-timeout", scanStagedCmd.Flags().Lookup("plugin-timeout"))
+	err = viperScanStaged.BindPFlag("wasm-plugin-dir", scanStagedCmd.Flags().Lookup("wasm-plugin-dir"))
+	err = viperScanStaged.BindPFlag("json", scanStagedCmd.Flags().Lookup("json"))
+	err = viperScanStaged.BindPFlag("finding-script", scanStagedCmd.Flags().Lookup("finding-script"))
+	err = viperScanStaged.BindPFlag("redact", scanStagedCmd.Flags().Lookup("redact"))
+	err = viperScanStaged.BindPFlag("policy", scanStagedCmd.Flags().Lookup("policy"))
+	err = viperScanStaged.BindPFlag("entropy", scanStagedCmd.Flags().Lookup("entropy"))
+	err = viperScanStaged.BindPFlag("baseline", scanStagedCmd.Flags().Lookup("baseline"))
+	err = viperScanStaged.BindPFlag("fail-on", scanStagedCmd.Flags().Lookup("fail-on"))
+	err = viperScanStaged.BindPFlag("suppression-markers", scanStagedCmd.Flags().Lookup("suppression-markers"))
+	err = viperScanStaged.BindPFlag("csv", scanStagedCmd.Flags().Lookup("csv"))
+	err = viperScanStaged.BindPFlag("min-severity", scanStagedCmd.Flags().Lookup("min-severity"))
+	err = viperScanStaged.BindPFlag("min-confidence", scanStagedCmd.Flags().Lookup("min-confidence"))
+	err = viperScanStaged.BindPFlag("decode", scanStagedCmd.Flags().Lookup("decode"))
+	err = viperScanStaged.BindPFlag("decode-min-length", scanStagedCmd.Flags().Lookup("decode-min-length"))
+	err = viperScanStaged.BindPFlag("gitlab-report", scanStagedCmd.Flags().Lookup("gitlab-report"))
+	err = viperScanStaged.BindPFlag("proxy", scanStagedCmd.Flags().Lookup("proxy"))
+	err = viperScanStaged.BindPFlag("ca-bundle", scanStagedCmd.Flags().Lookup("ca-bundle"))
+	err = viperScanStaged.BindPFlag("insecure-skip-verify", scanStagedCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperScanStaged.BindPFlag("context-lines", scanStagedCmd.Flags().Lookup("context-lines"))
+	err = viperScanStaged.BindPFlag("dedup-findings", scanStagedCmd.Flags().Lookup("dedup-findings"))
+	err = viperScanStaged.BindPFlag("junit", scanStagedCmd.Flags().Lookup("junit"))
+	err = viperScanStaged.BindPFlag("signature-tags", scanStagedCmd.Flags().Lookup("signature-tags"))
+	err = viperScanStaged.BindPFlag("enable-signatures", scanStagedCmd.Flags().Lookup("enable-signatures"))
+	err = viperScanStaged.BindPFlag("disable-signatures", scanStagedCmd.Flags().Lookup("disable-signatures"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
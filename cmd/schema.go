@@ -0,0 +1,24 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command that prints the JSON Schema of the json output
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the json and jsonl output",
+	Long:  "Print the JSON Schema of the json and jsonl output - schema v" + core.JSONSchemaVersion,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(core.JSONSchema)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,167 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperServe *viper.Viper
+
+// serveCmd represents the serve command that keeps running and scans on the schedules of the config file
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Keep running and scan on the cron schedules of the config file",
+	Long: "Keep running and scan on the cron schedules of the config file, showing the scans and their findings in " +
+		"the web interface and api - v" + version.AppVersion(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if configFile := core.SetHomeDir(viperServe.GetString("config-file")); configFile != "" {
+			if _, err := os.Stat(configFile); err == nil || cmd.Flags().Changed("config-file") {
+				viperServe.SetConfigFile(configFile)
+				if err := viperServe.ReadInConfig(); err != nil {
+					fmt.Printf("Unable to read the config file %s: %s\n", configFile, err)
+					os.Exit(2)
+				}
+			}
+		}
+
+		// the server itself is never silent, the web interface is how its scans are seen
+		viperServe.Set("silent", false)
+		sess := core.NewSession(viperServe, core.ScanTypeServe)
+
+		schedules, err := core.LoadSchedules(viperServe)
+		if err != nil {
+			sess.Out.Fatal("Unable to load the schedules: %s\n", err)
+		}
+		if len(schedules) == 0 && !sess.APIScans {
+			sess.Out.Warn("There are no schedules in the config file, nothing will be scanned\n")
+		}
+		if sess.DB == nil {
+			sess.Out.Warn("The findings of the scans are only kept until the server stops, set db-path to keep them\n")
+		}
+
+		sess.Scheduler = core.NewScheduler(viperServe, sess.Scans, schedules, sess.Out, time.Now())
+
+		stop := make(chan struct{})
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sig
+			sess.Out.Warn("Stopping once the scans that are running have finished what they are scanning\n")
+			close(stop)
+		}()
+
+		sess.Out.Important("%s v%s serving %d %s\n", core.Name, version.AppVersion(), len(schedules), core.Pluralize(len(schedules), "schedule", "schedules"))
+		sess.Out.Important("Loaded %d signatures.\n", len(core.CurrentSignatures()))
+		sess.Out.Important("Web interface available at http://%s:%d\n", sess.BindAddress, sess.BindPort)
+		for _, st := range sess.Scheduler.Schedules() {
+			if st.NextRun != nil {
+				sess.Out.Info(" %s scans %s next at %s\n", st.Name, st.ScanType, st.NextRun.Format(time.RFC3339))
+			} else {
+				sess.Out.Warn(" %s never runs, %s does not match any time\n", st.Name, st.Cron)
+			}
+		}
+
+		sess.Scheduler.Run(stop)
+		sess.Scans.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	viperServe = core.SetConfig()
+
+	serveCmd.Flags().String("config-file", "$HOME/.wraith/config.yaml", "The config file with the schedules to scan on, along with any other settings")
+	serveCmd.Flags().Bool("debug", false, "Print debugging information")
+	serveCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	serveCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	serveCmd.Flags().Int("bind-port", 9393, "The port for the webserver")
+	serveCmd.Flags().String("bind-address", "127.0.0.1", "The IP address for the webserver")
+	serveCmd.Flags().Int("grpc-port", 0, "The port for the gRPC scan api, disabled when 0")
+	serveCmd.Flags().String("grpc-token", "", "The token callers of the gRPC api have to send as a bearer token in the authorization metadata")
+	serveCmd.Flags().String("grpc-tls-cert", "", "The certificate the gRPC api is served over tls with")
+	serveCmd.Flags().String("grpc-tls-key", "", "The private key of grpc-tls-cert")
+	serveCmd.Flags().String("grpc-client-ca", "", "Only let callers of the gRPC api in with a client certificate signed by this ca")
+	serveCmd.Flags().Bool("allow-local-scans", false, "Allow localGit and localPath scans, which read the disk of the server, to be submitted through the apis")
+	serveCmd.Flags().Int("scan-retention", 50, "Number of finished api and scheduled scans to keep, 0 keeps every one")
+	serveCmd.Flags().Bool("api-scans", false, "Allow scans to be started and cancelled through the rest api")
+	serveCmd.Flags().String("db-path", "", "A sqlite database to keep the sessions, targets and findings of every scan in")
+	serveCmd.Flags().String("history-file", "", "Record a summary of each scan in this file to track trends over time")
+	serveCmd.Flags().String("triage-file", "", "Keep what is decided about each finding in the web interface in this file, and show it in later scans")
+	serveCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	serveCmd.Flags().Int("match-level", 3, "Signature match level")
+	serveCmd.Flags().Int("num-threads", 0, "The number of threads to execute with")
+	serveCmd.Flags().Int("clone-threads", 0, "The number of repositories to clone at a time, defaults to num-threads")
+	serveCmd.Flags().Int("walk-threads", 0, "The number of cloned repositories to read the commits of at a time, defaults to num-threads")
+	serveCmd.Flags().Int("match-threads", 0, "The number of changed files to match the signatures against at a time, defaults to num-threads")
+	serveCmd.Flags().Int("pipeline-queue-size", core.DefaultPipelineQueueSize, "The number of changed files that can wait to be matched, or reported, at a time")
+	serveCmd.Flags().String("web-username", "", "Username for basic auth on the web interface")
+	serveCmd.Flags().String("web-password", "", "Password for basic auth on the web interface")
+	serveCmd.Flags().String("oidc-issuer", "", "OIDC provider users sign in to the web interface with")
+	serveCmd.Flags().String("oidc-client-id", "", "Client id registered with the OIDC provider")
+	serveCmd.Flags().String("oidc-client-secret", "", "Client secret registered with the OIDC provider")
+	serveCmd.Flags().String("oidc-redirect-url", "", "Url the OIDC provider sends users back to, ending in /auth/callback")
+	serveCmd.Flags().String("oidc-allowed-emails", "", "A space separated list of emails or @domains allowed to sign in with OIDC, anyone when empty")
+	serveCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	serveCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	serveCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	serveCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	serveCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	serveCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	serveCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	serveCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+
+	err := viperServe.BindPFlag("config-file", serveCmd.Flags().Lookup("config-file"))
+	err = viperServe.BindPFlag("debug", serveCmd.Flags().Lookup("debug"))
+	err = viperServe.BindPFlag("debug-server", serveCmd.Flags().Lookup("debug-server"))
+	err = viperServe.BindPFlag("debug-server-public", serveCmd.Flags().Lookup("debug-server-public"))
+	err = viperServe.BindPFlag("bind-port", serveCmd.Flags().Lookup("bind-port"))
+	err = viperServe.BindPFlag("bind-address", serveCmd.Flags().Lookup("bind-address"))
+	err = viperServe.BindPFlag("grpc-port", serveCmd.Flags().Lookup("grpc-port"))
+	err = viperServe.BindPFlag("grpc-token", serveCmd.Flags().Lookup("grpc-token"))
+	err = viperServe.BindPFlag("grpc-tls-cert", serveCmd.Flags().Lookup("grpc-tls-cert"))
+	err = viperServe.BindPFlag("grpc-tls-key", serveCmd.Flags().Lookup("grpc-tls-key"))
+	err = viperServe.BindPFlag("grpc-client-ca", serveCmd.Flags().Lookup("grpc-client-ca"))
+	err = viperServe.BindPFlag("allow-local-scans", serveCmd.Flags().Lookup("allow-local-scans"))
+	err = viperServe.BindPFlag("scan-retention", serveCmd.Flags().Lookup("scan-retention"))
+	err = viperServe.BindPFlag("api-scans", serveCmd.Flags().Lookup("api-scans"))
+	err = viperServe.BindPFlag("db-path", serveCmd.Flags().Lookup("db-path"))
+	err = viperServe.BindPFlag("history-file", serveCmd.Flags().Lookup("history-file"))
+	err = viperServe.BindPFlag("triage-file", serveCmd.Flags().Lookup("triage-file"))
+	err = viperServe.BindPFlag("signature-file", serveCmd.Flags().Lookup("signature-file"))
+	err = viperServe.BindPFlag("match-level", serveCmd.Flags().Lookup("match-level"))
+	err = viperServe.BindPFlag("num-threads", serveCmd.Flags().Lookup("num-threads"))
+	err = viperServe.BindPFlag("clone-threads", serveCmd.Flags().Lookup("clone-threads"))
+	err = viperServe.BindPFlag("walk-threads", serveCmd.Flags().Lookup("walk-threads"))
+	err = viperServe.BindPFlag("match-threads", serveCmd.Flags().Lookup("match-threads"))
+	err = viperServe.BindPFlag("pipeline-queue-size", serveCmd.Flags().Lookup("pipeline-queue-size"))
+	err = viperServe.BindPFlag("web-username", serveCmd.Flags().Lookup("web-username"))
+	err = viperServe.BindPFlag("web-password", serveCmd.Flags().Lookup("web-password"))
+	err = viperServe.BindPFlag("oidc-issuer", serveCmd.Flags().Lookup("oidc-issuer"))
+	err = viperServe.BindPFlag("oidc-client-id", serveCmd.Flags().Lookup("oidc-client-id"))
+	err = viperServe.BindPFlag("oidc-client-secret", serveCmd.Flags().Lookup("oidc-client-secret"))
+	err = viperServe.BindPFlag("oidc-redirect-url", serveCmd.Flags().Lookup("oidc-redirect-url"))
+	err = viperServe.BindPFlag("oidc-allowed-emails", serveCmd.Flags().Lookup("oidc-allowed-emails"))
+	err = viperServe.BindPFlag("log-format", serveCmd.Flags().Lookup("log-format"))
+	err = viperServe.BindPFlag("log-file", serveCmd.Flags().Lookup("log-file"))
+	err = viperServe.BindPFlag("proxy", serveCmd.Flags().Lookup("proxy"))
+	err = viperServe.BindPFlag("ca-bundle", serveCmd.Flags().Lookup("ca-bundle"))
+	err = viperServe.BindPFlag("insecure-skip-verify", serveCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperServe.BindPFlag("signature-tags", serveCmd.Flags().Lookup("signature-tags"))
+	err = viperServe.BindPFlag("enable-signatures", serveCmd.Flags().Lookup("enable-signatures"))
+	err = viperServe.BindPFlag("disable-signatures", serveCmd.Flags().Lookup("disable-signatures"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperTriage *viper.Viper
+
+// triageCmd represents the triage command that works through the findings of a scan in the terminal
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Triage the findings of a scan one at a time in the terminal",
+	Long: "Show the findings of a json report or a session in the database one at a time, marking each as a false " +
+		"positive, confirmed or remediated with a key, and keep the decisions in the triage file, database and baseline",
+	Run: func(cmd *cobra.Command, args []string) {
+		resultsFile := viperTriage.GetString("results-file")
+		dbPath := core.SetHomeDir(viperTriage.GetString("db-path"))
+		sessionID := viperTriage.GetInt64("session-id")
+		if resultsFile == "" && sessionID == 0 {
+			fmt.Fprintf(os.Stderr, "Give the --results-file of a scan, or the --session-id of one in the --db-path, to triage\n")
+			os.Exit(2)
+		}
+		if viperTriage.GetString("triage-file") == "" && viperTriage.GetString("baseline") == "" && dbPath == "" {
+			fmt.Fprintf(os.Stderr, "Give a --triage-file, --baseline or --db-path to keep the decisions in\n")
+			os.Exit(2)
+		}
+
+		var db *core.Database
+		if dbPath != "" {
+			var err error
+			if db, err = core.OpenDatabase(dbPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to open the database %s: %s\n", dbPath, err)
+				os.Exit(2)
+			}
+		}
+
+		var findings []core.JSONFinding
+		if resultsFile != "" {
+			var err error
+			if findings, err = core.LoadJSONFindings(core.SetHomeDir(resultsFile)); err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to read the findings in %s: %s\n", resultsFile, err)
+				os.Exit(2)
+			}
+		} else {
+			if db == nil {
+				fmt.Fprintf(os.Stderr, "A --session-id needs the --db-path it is stored in\n")
+				os.Exit(2)
+			}
+			stored, err := db.Findings(sessionID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to read the findings of session %d: %s\n", sessionID, err)
+				os.Exit(2)
+			}
+			for _, f := range stored {
+				findings = append(findings, core.NewJSONFinding(f))
+			}
+		}
+
+		triage := core.NewTerminalTriage(findings)
+		triage.TriageFile = core.SetHomeDir(viperTriage.GetString("triage-file"))
+		triage.Baseline = core.SetHomeDir(viperTriage.GetString("baseline"))
+		triage.DB = db
+		triage.User = viperTriage.GetString("triage-user")
+		if triage.User == "" {
+			if u, err := user.Current(); err == nil {
+				triage.User = u.Username
+			}
+		}
+		triage.All = viperTriage.GetBool("all")
+		triage.In = os.Stdin
+		triage.Out = os.Stdout
+		triage.Fd = int(os.Stdin.Fd())
+
+		if err := triage.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nUnable to triage: %s\n", err)
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(triageCmd)
+
+	viperTriage = core.SetConfig()
+
+	triageCmd.Flags().String("results-file", "", "The json report, or json lines, of the scan to triage")
+	triageCmd.Flags().String("db-path", "", "The sqlite database to keep the triage in, and to read the --session-id from")
+	triageCmd.Flags().Int64("session-id", 0, "The session in the database to triage, in place of a --results-file")
+	triageCmd.Flags().String("triage-file", "", "The file to keep the triage in, the same file the scans are given")
+	triageCmd.Flags().String("baseline", "", "The baseline file to add the false positives to, so later scans leave them out")
+	triageCmd.Flags().String("triage-user", "", "The name to record with each triage, the user running wraith when not given")
+	triageCmd.Flags().Bool("all", false, "Show the findings that have already been triaged as well")
+
+	err := viperTriage.BindPFlag("results-file", triageCmd.Flags().Lookup("results-file"))
+	err = viperTriage.BindPFlag("db-path", triageCmd.Flags().Lookup("db-path"))
+	err = viperTriage.BindPFlag("session-id", triageCmd.Flags().Lookup("session-id"))
+	err = viperTriage.BindPFlag("triage-file", triageCmd.Flags().Lookup("triage-file"))
+	err = viperTriage.BindPFlag("baseline", triageCmd.Flags().Lookup("baseline"))
+	err = viperTriage.BindPFlag("triage-user", triageCmd.Flags().Lookup("triage-user"))
+	err = viperTriage.BindPFlag("all", triageCmd.Flags().Lookup("all"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperUpdate *viper.Viper
+
+// updateCmd represents the update command that replaces the running binary with the latest signed release
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update wraith to the latest release",
+	Long:  "Update wraith to the latest release after verifying its signature and checksum - v" + version.AppVersion(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.ConfigureHTTP(core.NewHTTPConfig(viperUpdate)); err != nil {
+			fmt.Printf("Unable to configure http: %s\n", err)
+			os.Exit(2)
+		}
+
+		current := version.AppVersion()
+
+		release, err := core.FetchLatestRelease(viperUpdate.GetString("release-url"))
+		if err != nil {
+			fmt.Printf("Unable to check for a new release: %s\n", err)
+			os.Exit(2)
+		}
+
+		if core.CompareVersions(release.Version(), current) <= 0 {
+			fmt.Printf("wraith v%s is the latest release\n", current)
+			return
+		}
+
+		fmt.Printf("wraith v%s is available, v%s is installed\n", release.Version(), current)
+		if release.HTMLURL != "" {
+			fmt.Printf("Release notes: %s\n", release.HTMLURL)
+		}
+		if viperUpdate.GetBool("check-only") {
+			return
+		}
+
+		publicKey := viperUpdate.GetString("public-key")
+		if publicKey == "" {
+			publicKey = core.ReleasePublicKey
+		}
+
+		binary, err := core.DownloadRelease(release, publicKey)
+		if err != nil {
+			fmt.Printf("Unable to download v%s: %s\n", release.Version(), err)
+			os.Exit(2)
+		}
+
+		path, err := core.ReplaceExecutable(binary)
+		if err != nil {
+			fmt.Printf("Unable to replace the wraith binary: %s\n", err)
+			os.Exit(2)
+		}
+		fmt.Printf("Updated %s to v%s\n", path, release.Version())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	viperUpdate = core.SetConfig()
+
+	updateCmd.Flags().Bool("check-only", false, "Only report if a new release is available")
+	updateCmd.Flags().String("release-url", core.DefaultReleaseURL, "The release document to check, this can point to an internal mirror")
+	updateCmd.Flags().String("public-key", "", "The base64 ed25519 key used to verify the release, defaults to the key built into wraith")
+	updateCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	updateCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	updateCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+
+	err := viperUpdate.BindPFlag("check-only", updateCmd.Flags().Lookup("check-only"))
+	err = viperUpdate.BindPFlag("release-url", updateCmd.Flags().Lookup("release-url"))
+	err = viperUpdate.BindPFlag("public-key", updateCmd.Flags().Lookup("public-key"))
+	err = viperUpdate.BindPFlag("proxy", updateCmd.Flags().Lookup("proxy"))
+	err = viperUpdate.BindPFlag("ca-bundle", updateCmd.Flags().Lookup("ca-bundle"))
+	err = viperUpdate.BindPFlag("insecure-skip-verify", updateCmd.Flags().Lookup("insecure-skip-verify"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"wraith/core"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperUpdateSignatures *viper.Viper
+
+// updateSignaturesCmd represents the updateSignatures command that fetches signatures from a git repository or over https
+var updateSignaturesCmd = &cobra.Command{
+	Use:     "updateSignatures",
+	Aliases: []string{"fetchSignatures"},
+	Short:   "Fetch signatures from a git repository or an https url",
+	Long: "Fetch signatures from a git repository or an https url into the signature directory, verifying them " +
+		"against a checksum or public key. A running server watching the directory loads them straight away.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.ConfigureHTTP(core.NewHTTPConfig(viperUpdateSignatures)); err != nil {
+			fmt.Printf("Unable to configure http: %s\n", err)
+			os.Exit(2)
+		}
+
+		src := core.SignatureSource{
+			URL:       viperUpdateSignatures.GetString("signatures-url"),
+			Ref:       viperUpdateSignatures.GetString("signatures-ref"),
+			Path:      viperUpdateSignatures.GetString("signatures-path"),
+			SHA256:    viperUpdateSignatures.GetString("signatures-sha256"),
+			PublicKey: viperUpdateSignatures.GetString("signatures-public-key"),
+		}
+
+		files, err := core.FetchSignatures(src)
+		if err != nil {
+			fmt.Printf("Unable to fetch signatures: %s\n", err)
+			os.Exit(2)
+		}
+		if src.SHA256 == "" && src.PublicKey == "" {
+			fmt.Printf("The signatures from %s are not verified, give a sha256 or public key to verify them\n", src.URL)
+		}
+
+		installed, err := core.InstallSignatures(files, viperUpdateSignatures.GetString("signatures-dir"))
+		if err != nil {
+			fmt.Printf("Unable to install signatures: %s\n", err)
+			os.Exit(2)
+		}
+		for _, file := range installed {
+			fmt.Printf("Installed %s\n", file)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateSignaturesCmd)
+
+	viperUpdateSignatures = core.SetConfig()
+
+	updateSignaturesCmd.Flags().String("signatures-url", "", "A git repository, or an https url of a single signature file, to fetch signatures from")
+	updateSignaturesCmd.Flags().String("signatures-ref", "", "The branch, tag or commit of the git repository to fetch, defaults to whatever HEAD points at")
+	updateSignaturesCmd.Flags().String("signatures-path", core.DefaultSignaturesPath, "The directory of the git repository the signature files are in")
+	updateSignaturesCmd.Flags().String("signatures-sha256", "", "The sha256 checksum the signature file fetched over https must have")
+	updateSignaturesCmd.Flags().String("signatures-public-key", "", "The base64 ed25519 key the signatures are signed with")
+	updateSignaturesCmd.Flags().String("signatures-dir", core.DefaultSignaturesDir, "The directory the signatures are installed into")
+	updateSignaturesCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	updateSignaturesCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	updateSignaturesCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+
+	err := viperUpdateSignatures.BindPFlag("signatures-url", updateSignaturesCmd.Flags().Lookup("signatures-url"))
+	err = viperUpdateSignatures.BindPFlag("signatures-ref", updateSignaturesCmd.Flags().Lookup("signatures-ref"))
+	err = viperUpdateSignatures.BindPFlag("signatures-path", updateSignaturesCmd.Flags().Lookup("signatures-path"))
+	err = viperUpdateSignatures.BindPFlag("signatures-sha256", updateSignaturesCmd.Flags().Lookup("signatures-sha256"))
+	err = viperUpdateSignatures.BindPFlag("signatures-public-key", updateSignaturesCmd.Flags().Lookup("signatures-public-key"))
+	err = viperUpdateSignatures.BindPFlag("signatures-dir", updateSignaturesCmd.Flags().Lookup("signatures-dir"))
+	err = viperUpdateSignatures.BindPFlag("proxy", updateSignaturesCmd.Flags().Lookup("proxy"))
+	err = viperUpdateSignatures.BindPFlag("ca-bundle", updateSignaturesCmd.Flags().Lookup("ca-bundle"))
+	err = viperUpdateSignatures.BindPFlag("insecure-skip-verify", updateSignaturesCmd.Flags().Lookup("insecure-skip-verify"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,161 @@
+// Package cmd represents the specific commands that the user will execute. Only specific code related to the command
+// should be in these files. As much of the code as possible should be pushed to other packages.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"wraith/core"
+	"wraith/version"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viperWorker *viper.Viper
+
+// workerCmd represents the worker command that scans the repositories a distributed scan puts on a queue
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Scan the repositories a scan with --queue-url puts on the queue",
+	Long:  "Scan the repositories a scan with --queue-url puts on the queue and send the findings back to it - v" + version.AppVersion(),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := &core.Logger{}
+		out.SetDebug(viperWorker.GetBool("debug"))
+		if err := out.SetOutput(viperWorker.GetString("log-format"), core.SetHomeDir(viperWorker.GetString("log-file"))); err != nil {
+			out.Fatal("Unable to log to %s: %s\n", viperWorker.GetString("log-file"), err)
+		}
+		if address := viperWorker.GetString("debug-server"); address != "" {
+			core.StartDebugServer(address, viperWorker.GetBool("debug-server-public"), out)
+		}
+		if viperWorker.GetBool("debug") {
+			core.LogRuntimeStats(out)
+		}
+
+		if viperWorker.GetString("queue-url") == "" {
+			out.Fatal("A worker needs the --queue-url of the scans it is taking repositories from\n")
+		}
+		worker, err := core.NewWorker(viperWorker, out)
+		if err != nil {
+			out.Fatal("Unable to connect to the queue: %s\n", err)
+		}
+
+		stop := make(chan struct{})
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sig
+			out.Warn("Stopping once the repository being scanned is done\n")
+			close(stop)
+		}()
+
+		out.Important("%s worker v%s taking repositories from %s\n", core.Name, version.AppVersion(), worker.Name)
+		if err := worker.Run(stop); err != nil {
+			out.Fatal("The worker stopped: %s\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	viperWorker = core.SetConfig()
+
+	workerCmd.Flags().Bool("debug", false, "Print debugging information")
+	workerCmd.Flags().String("debug-server", "", "Serve pprof profiles and runtime stats on this address, such as localhost:6060")
+	workerCmd.Flags().Bool("debug-server-public", false, "Allow --debug-server to listen on an address other than loopback")
+	workerCmd.Flags().String("queue-url", "", "The redis url of the queue to take repositories from, such as redis://:password@host:6379/0")
+	workerCmd.Flags().String("queue-name", core.QueueDefaultName, "The name of the queue, the same as the --queue-name of the scans")
+	workerCmd.Flags().String("signature-file", "$HOME/.wraith/signatures/default.yml", "Comma separated files or directories of signatures, later ones override signatures with the same id")
+	workerCmd.Flags().Int("match-level", 3, "Signature match level")
+	workerCmd.Flags().Int("max-file-size", 50, "Max file size to scan")
+	workerCmd.Flags().Int("commit-depth", 0, "Set the depth for commits")
+	workerCmd.Flags().Bool("in-mem-clone", false, "Clone repos in memory")
+	workerCmd.Flags().Bool("scan-tests", false, "Scan suspected test files")
+	workerCmd.Flags().Bool("entropy", false, "Find random strings such as generic api keys by their shannon entropy")
+	workerCmd.Flags().String("github-api-token", "", "API token for access to github, see doc for necessary scope")
+	workerCmd.Flags().StringSlice("github-api-tokens", []string{}, "API tokens of several accounts to use in turn as their rate limits are used up, in place of github-api-token")
+	workerCmd.Flags().String("github-api-url", "", "The api of a github enterprise instance, such as https://github.example.com/api/v3")
+	workerCmd.Flags().String("gitlab-api-token", "", "API token for access to Gitlab, see doc for necessary scope")
+	workerCmd.Flags().String("bitbucket-username", "", "The bitbucket username the app password belongs to")
+	workerCmd.Flags().String("bitbucket-app-password", "", "An app password for the bitbucket username with read access to repositories")
+	workerCmd.Flags().String("bitbucket-server-token", "", "A bitbucket server http access token with the repository read permission")
+	workerCmd.Flags().String("bitbucket-server-username", "", "The user the bitbucket server token belongs to, the token is sent as a bearer token when empty")
+	workerCmd.Flags().String("azure-devops-token", "", "A personal access token with the Code (Read) scope")
+	workerCmd.Flags().String("gitea-api-token", "", "An access token with the read:repository scope")
+	workerCmd.Flags().Bool("decode", false, "Decode base64, hex and url encoded text and match the signatures against what it decodes to")
+	workerCmd.Flags().Int("decode-min-length", 20, "The shortest encoded text that is decoded with --decode")
+	workerCmd.Flags().String("log-format", core.LogFormatText, "The format of the logs, text or json")
+	workerCmd.Flags().String("log-file", "", "Append the logs to this file rather than writing them to the terminal")
+	workerCmd.Flags().String("clone-cache", "", "Keep bare clones in this directory between scans and only fetch what is new")
+	workerCmd.Flags().Bool("clone-over-ssh", false, "Clone repositories from the ssh urls their provider gives rather than over https")
+	workerCmd.Flags().String("ssh-key", "", "A private key file to clone ssh urls with, rather than the keys of ssh-agent")
+	workerCmd.Flags().String("ssh-key-passphrase", "", "The passphrase of the ssh key")
+	workerCmd.Flags().String("ssh-known-hosts", "", "A known_hosts file to check the keys of ssh hosts against rather than ~/.ssh/known_hosts")
+	workerCmd.Flags().Int("retries", 3, "How many times a clone or api call that fails is tried again before what it was for is left out of the scan")
+	workerCmd.Flags().Int("retry-backoff", 2, "Seconds to wait before a clone or api call is tried again, doubled for each retry after the first")
+	workerCmd.Flags().Int("in-mem-clone-budget", 1024, "Megabytes of memory repos cloned in memory can take at once, larger repos are cloned to disk (0 for no limit)")
+	workerCmd.Flags().String("proxy", "", "A proxy url to send http and https traffic through in place of HTTP_PROXY and HTTPS_PROXY")
+	workerCmd.Flags().String("ca-bundle", "", "A pem file of certificate authorities to trust on top of those of the system, such as the one of a TLS intercepting proxy")
+	workerCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates, only for testing")
+	workerCmd.Flags().Int("context-lines", 2, "The number of lines before and after a secret to include with each finding")
+	workerCmd.Flags().Bool("dedup-findings", true, "Merge the findings of the same secret in the same place in more than one commit, branch or layer into one, keeping the first and last commit")
+	workerCmd.Flags().String("signature-tags", "", "Only use the signatures with one of these comma separated tags, such as aws,gcp")
+	workerCmd.Flags().String("enable-signatures", "", "Use the signatures with these comma separated ids even when they are turned off in their file")
+	workerCmd.Flags().String("disable-signatures", "", "Leave out the signatures with these comma separated ids")
+	workerCmd.Flags().Bool("scan-terraform-state", true, "Parse terraform state files and flag the values in them that are secrets, with the address of their resource")
+	workerCmd.Flags().Bool("scan-binaries", false, "Match the signatures against the printable strings in binary files rather than skipping them")
+	workerCmd.Flags().Int("binary-min-length", 8, "The shortest string in a binary file that is matched with --scan-binaries")
+
+	err := viperWorker.BindPFlag("debug", workerCmd.Flags().Lookup("debug"))
+	err = viperWorker.BindPFlag("debug-server", workerCmd.Flags().Lookup("debug-server"))
+	err = viperWorker.BindPFlag("debug-server-public", workerCmd.Flags().Lookup("debug-server-public"))
+	err = viperWorker.BindPFlag("queue-url", workerCmd.Flags().Lookup("queue-url"))
+	err = viperWorker.BindPFlag("queue-name", workerCmd.Flags().Lookup("queue-name"))
+	err = viperWorker.BindPFlag("signature-file", workerCmd.Flags().Lookup("signature-file"))
+	err = viperWorker.BindPFlag("match-level", workerCmd.Flags().Lookup("match-level"))
+	err = viperWorker.BindPFlag("max-file-size", workerCmd.Flags().Lookup("max-file-size"))
+	err = viperWorker.BindPFlag("commit-depth", workerCmd.Flags().Lookup("commit-depth"))
+	err = viperWorker.BindPFlag("in-mem-clone", workerCmd.Flags().Lookup("in-mem-clone"))
+	err = viperWorker.BindPFlag("scan-tests", workerCmd.Flags().Lookup("scan-tests"))
+	err = viperWorker.BindPFlag("entropy", workerCmd.Flags().Lookup("entropy"))
+	err = viperWorker.BindPFlag("github-api-token", workerCmd.Flags().Lookup("github-api-token"))
+	err = viperWorker.BindPFlag("github-api-tokens", workerCmd.Flags().Lookup("github-api-tokens"))
+	err = viperWorker.BindPFlag("github-api-url", workerCmd.Flags().Lookup("github-api-url"))
+	err = viperWorker.BindPFlag("gitlab-api-token", workerCmd.Flags().Lookup("gitlab-api-token"))
+	err = viperWorker.BindPFlag("bitbucket-username", workerCmd.Flags().Lookup("bitbucket-username"))
+	err = viperWorker.BindPFlag("bitbucket-app-password", workerCmd.Flags().Lookup("bitbucket-app-password"))
+	err = viperWorker.BindPFlag("bitbucket-server-token", workerCmd.Flags().Lookup("bitbucket-server-token"))
+	err = viperWorker.BindPFlag("bitbucket-server-username", workerCmd.Flags().Lookup("bitbucket-server-username"))
+	err = viperWorker.BindPFlag("azure-devops-token", workerCmd.Flags().Lookup("azure-devops-token"))
+	err = viperWorker.BindPFlag("gitea-api-token", workerCmd.Flags().Lookup("gitea-api-token"))
+	err = viperWorker.BindPFlag("decode", workerCmd.Flags().Lookup("decode"))
+	err = viperWorker.BindPFlag("decode-min-length", workerCmd.Flags().Lookup("decode-min-length"))
+	err = viperWorker.BindPFlag("log-format", workerCmd.Flags().Lookup("log-format"))
+	err = viperWorker.BindPFlag("log-file", workerCmd.Flags().Lookup("log-file"))
+	err = viperWorker.BindPFlag("clone-cache", workerCmd.Flags().Lookup("clone-cache"))
+	err = viperWorker.BindPFlag("clone-over-ssh", workerCmd.Flags().Lookup("clone-over-ssh"))
+	err = viperWorker.BindPFlag("ssh-key", workerCmd.Flags().Lookup("ssh-key"))
+	err = viperWorker.BindPFlag("ssh-key-passphrase", workerCmd.Flags().Lookup("ssh-key-passphrase"))
+	err = viperWorker.BindPFlag("ssh-known-hosts", workerCmd.Flags().Lookup("ssh-known-hosts"))
+	err = viperWorker.BindPFlag("retries", workerCmd.Flags().Lookup("retries"))
+	err = viperWorker.BindPFlag("retry-backoff", workerCmd.Flags().Lookup("retry-backoff"))
+	err = viperWorker.BindPFlag("in-mem-clone-budget", workerCmd.Flags().Lookup("in-mem-clone-budget"))
+	err = viperWorker.BindPFlag("proxy", workerCmd.Flags().Lookup("proxy"))
+	err = viperWorker.BindPFlag("ca-bundle", workerCmd.Flags().Lookup("ca-bundle"))
+	err = viperWorker.BindPFlag("insecure-skip-verify", workerCmd.Flags().Lookup("insecure-skip-verify"))
+	err = viperWorker.BindPFlag("context-lines", workerCmd.Flags().Lookup("context-lines"))
+	err = viperWorker.BindPFlag("dedup-findings", workerCmd.Flags().Lookup("dedup-findings"))
+	err = viperWorker.BindPFlag("signature-tags", workerCmd.Flags().Lookup("signature-tags"))
+	err = viperWorker.BindPFlag("enable-signatures", workerCmd.Flags().Lookup("enable-signatures"))
+	err = viperWorker.BindPFlag("disable-signatures", workerCmd.Flags().Lookup("disable-signatures"))
+	err = viperWorker.BindPFlag("scan-terraform-state", workerCmd.Flags().Lookup("scan-terraform-state"))
+	err = viperWorker.BindPFlag("scan-binaries", workerCmd.Flags().Lookup("scan-binaries"))
+	err = viperWorker.BindPFlag("binary-min-length", workerCmd.Flags().Lookup("binary-min-length"))
+
+	if err != nil {
+		fmt.Printf("There was an error binding a flag: %s\n", err.Error())
+	}
+}
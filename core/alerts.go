@@ -0,0 +1,137 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// alertLock keeps sessions in the same process from overwriting each others alerts when the state is saved
+var alertLock sync.Mutex
+
+// AlertState remembers which findings each sink has already been alerted to so a scheduled scan only notifies on
+// findings that are new to that sink. Findings are known by their fingerprint, so a secret that is still in the same
+// place is not new because it is in another commit or branch. A sink is a hook type and its command, changing the command starts it over.
+type AlertState struct {
+	sync.Mutex
+
+	// Interval is how long to wait before alerting a sink to the same finding again, never when 0
+	Interval time.Duration `json:"-"`
+
+	// Version is the alertStateVersion the state was written with
+	Version int `json:"version"`
+
+	// Sinks holds the last time each finding was sent to each sink, keyed by sink and then the finding fingerprint
+	Sinks map[string]map[string]time.Time `json:"sinks"`
+}
+
+// alertStateVersion is the version of the alert state file. Before version 1 findings were keyed by their secret id,
+// which is different in every commit, so those are dropped rather than kept around forever.
+const alertStateVersion = 1
+
+// LoadAlertState will read the alert state from a file, a file that does not exist yet has not alerted on anything
+func LoadAlertState(path string, interval time.Duration) (*AlertState, error) {
+	a := &AlertState{Interval: interval, Sinks: map[string]map[string]time.Time{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		a.Version = alertStateVersion
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	if a.Sinks == nil || a.Version < alertStateVersion {
+		a.Sinks = map[string]map[string]time.Time{}
+	}
+	a.Version = alertStateVersion
+	return a, nil
+}
+
+// AlertSink returns the name a hook command is tracked under
+func AlertSink(hookType, command string) string {
+	return hookType + ":" + command
+}
+
+// ShouldAlert will check if a sink has not been sent a finding yet, or was last sent it longer than the interval ago.
+// A nil state alerts on everything.
+func (a *AlertState) ShouldAlert(sink string, f *Finding, now time.Time) bool {
+	if a == nil {
+		return true
+	}
+	a.Lock()
+	defer a.Unlock()
+
+	last, ok := a.Sinks[sink][f.Fingerprint]
+	if !ok {
+		return true
+	}
+	return a.Interval > 0 && now.Sub(last) >= a.Interval
+}
+
+// Alerted will record that a sink has been sent a finding
+func (a *AlertState) Alerted(sink string, f *Finding, now time.Time) {
+	if a == nil {
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+
+	if a.Sinks[sink] == nil {
+		a.Sinks[sink] = map[string]time.Time{}
+	}
+	a.Sinks[sink][f.Fingerprint] = now
+}
+
+// Save will write the state to a file. Anything written to the file since it was loaded is merged in, keeping the
+// latest alert for each finding, so concurrent sessions do not lose each others alerts.
+func (a *AlertState) Save(path string) error {
+	alertLock.Lock()
+	defer alertLock.Unlock()
+
+	current, err := LoadAlertState(path, a.Interval)
+	if err != nil {
+		return err
+	}
+
+	a.Lock()
+	for sink, alerted := range a.Sinks {
+		if current.Sinks[sink] == nil {
+			current.Sinks[sink] = map[string]time.Time{}
+		}
+		for id, at := range alerted {
+			if at.After(current.Sinks[sink][id]) {
+				current.Sinks[sink][id] = at
+			}
+		}
+	}
+	a.Unlock()
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveAlertState will write the alert state of the session to its file, if one has been configured
+func (s *Session) SaveAlertState() {
+	if s.Alerts == nil {
+		return
+	}
+	if err := s.Alerts.Save(s.AlertStateFile); err != nil {
+		s.Out.Error("Failed to write the alert state to %s: %s\n", s.AlertStateFile, err)
+	}
+}
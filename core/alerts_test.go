@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestAlertState(t *testing.T) {
+
+	Convey("Given an alert state", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-alerts")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "alerts.json")
+
+		a, err := core.LoadAlertState(path, 0)
+		So(err, ShouldBeNil)
+
+		sink := core.AlertSink(core.HookFinding, "notify.sh")
+		f := &core.Finding{Fingerprint: "abc", SecretID: "abc-in-c1"}
+		now := time.Now()
+
+		Convey("A finding should only be alerted once per sink", func() {
+			So(a.ShouldAlert(sink, f, now), ShouldBeTrue)
+			a.Alerted(sink, f, now)
+			So(a.ShouldAlert(sink, f, now.Add(1000*time.Hour)), ShouldBeFalse)
+			So(a.ShouldAlert(core.AlertSink(core.HookFinding, "other.sh"), f, now), ShouldBeTrue)
+		})
+
+		Convey("The same secret in the same place should not be alerted again from another commit", func() {
+			a.Alerted(sink, f, now)
+			So(a.ShouldAlert(sink, &core.Finding{Fingerprint: "abc", SecretID: "abc-in-c2"}, now), ShouldBeFalse)
+		})
+
+		Convey("A state keyed by secret id should be started over", func() {
+			So(ioutil.WriteFile(path, []byte(`{"sinks": {"`+sink+`": {"abc": "2020-01-01T00:00:00Z"}}}`), 0600), ShouldBeNil)
+			old, err := core.LoadAlertState(path, 0)
+			So(err, ShouldBeNil)
+			So(old.Sinks, ShouldBeEmpty)
+			So(old.ShouldAlert(sink, f, now), ShouldBeTrue)
+		})
+
+		Convey("A finding should be alerted again once the interval has passed", func() {
+			a.Interval = 24 * time.Hour
+			a.Alerted(sink, f, now)
+			So(a.ShouldAlert(sink, f, now.Add(time.Hour)), ShouldBeFalse)
+			So(a.ShouldAlert(sink, f, now.Add(24*time.Hour)), ShouldBeTrue)
+		})
+
+		Convey("A nil state should alert on everything", func() {
+			var none *core.AlertState
+			none.Alerted(sink, f, now)
+			So(none.ShouldAlert(sink, f, now), ShouldBeTrue)
+		})
+
+		Convey("Saving should merge in alerts written by another session", func() {
+			other, err := core.LoadAlertState(path, 0)
+			So(err, ShouldBeNil)
+			other.Alerted(sink, &core.Finding{Fingerprint: "def"}, now)
+			So(other.Save(path), ShouldBeNil)
+
+			a.Alerted(sink, f, now)
+			So(a.Save(path), ShouldBeNil)
+
+			loaded, err := core.LoadAlertState(path, 0)
+			So(err, ShouldBeNil)
+			So(loaded.ShouldAlert(sink, f, now), ShouldBeFalse)
+			So(loaded.ShouldAlert(sink, &core.Finding{Fingerprint: "def"}, now), ShouldBeFalse)
+		})
+	})
+}
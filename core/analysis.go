@@ -3,6 +3,7 @@ package core
 
 import (
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,9 +11,9 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"wraith/version"
 
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 // PrintSessionStats will print the performance and sessions stats to stdout at the conclusion of a session scan
@@ -22,6 +23,15 @@ func PrintSessionStats(sess *Session) {
 	sess.Out.Important("\n")
 	sess.Out.Important("-------Findings------\n")
 	sess.Out.Info("Total Findings......: %d\n", sess.Stats.Findings)
+	if sess.Baseline != nil {
+		sess.Out.Info("Baselined Findings..: %d\n", sess.Stats.FindingsBaselined)
+	}
+	if sess.Stats.FindingsSuppressed > 0 {
+		sess.Out.Info("Suppressed Findings.: %d\n", sess.Stats.FindingsSuppressed)
+	}
+	if sess.Stats.FindingsDuplicate > 0 {
+		sess.Out.Info("Duplicate Findings..: %d\n", sess.Stats.FindingsDuplicate)
+	}
 	sess.Out.Important("\n")
 	sess.Out.Important("--------Files--------\n")
 	sess.Out.Info("Total Files.........: %d\n", sess.Stats.FilesTotal)
@@ -36,6 +46,7 @@ func PrintSessionStats(sess *Session) {
 	sess.Out.Info("Commits Scanned.....: %d\n", sess.Stats.Commits)
 	sess.Out.Info("Commits Dirty.......: %d\n", sess.Stats.CommitsDirty)
 	sess.Out.Important("\n")
+	PrintScanErrors(sess)
 	sess.Out.Important("-------General-------\n")
 	sess.Out.Info("Wraith Version......: %s\n", sess.Version)
 	sess.Out.Info("Signatures Version..: %s\n", sess.SignatureVersion)
@@ -44,35 +55,129 @@ func PrintSessionStats(sess *Session) {
 
 // GatherTargets will enumerate github orgs and members and add them to the running target list of a session
 func GatherTargets(sess *Session) {
-	sess.Stats.Status = StatusGathering
+	sess.SetStatus(StatusGathering)
 	sess.Out.Important("Gathering targets...\n")
 
+	// a resumed scan carries on with the targets it had gathered rather than listing them again
+	if sess.resumeTargets() {
+		return
+	}
+	// once they are all gathered they are checkpointed, so they are not listed again if the scan is interrupted
+	defer sess.checkpointTargets()
+
 	var targets []string
 
 	switch sess.ScanType {
 	case "github":
 		targets = sess.GithubTargets
+		// on github enterprise every organization and user on the instance can be gathered, so they do not have to be
+		// listed by hand
+		if sess.GithubAllOrgs {
+			if lister, ok := sess.Client.(interface{ ListOrganizations() ([]string, error) }); ok {
+				var orgs []string
+				_, err := sess.withRetries(sess.Out, "list the github organizations", func() (err error) {
+					orgs, err = lister.ListOrganizations()
+					return err
+				})
+				if err != nil {
+					sess.Out.Error(" Error listing github organizations: %s\n", err)
+				}
+				sess.Out.Info("Found %d organizations on the instance\n", len(orgs))
+				targets = append(targets, orgs...)
+			}
+		}
+		if sess.GithubAllUsers {
+			if lister, ok := sess.Client.(interface{ ListUsers() ([]string, error) }); ok {
+				var users []string
+				_, err := sess.withRetries(sess.Out, "list the github users", func() (err error) {
+					users, err = lister.ListUsers()
+					return err
+				})
+				if err != nil {
+					sess.Out.Error(" Error listing github users: %s\n", err)
+				}
+				sess.Out.Info("Found %d users on the instance\n", len(users))
+				targets = append(targets, users...)
+			}
+		}
 	case "gitlab":
 		targets = sess.GitlabTargets
+	case "azureDevops":
+		targets = sess.AzureDevopsTargets
+	case "gitea":
+		targets = sess.GiteaTargets
+	case "bitbucketServer":
+		targets = sess.BitbucketServerTargets
+		if len(targets) == 0 {
+			// without any targets every project the token can read is scanned
+			if lister, ok := sess.Client.(interface{ ListProjects() ([]string, error) }); ok {
+				var projects []string
+				_, err := sess.withRetries(sess.Out, "list the bitbucket server projects", func() (err error) {
+					projects, err = lister.ListProjects()
+					return err
+				})
+				if err != nil {
+					sess.Out.Error(" Error listing bitbucket server projects: %s\n", err)
+				}
+				targets = projects
+			}
+		}
+	case "codeCommit":
+		targets = CodeCommitTargets(sess)
+	case "bitbucket":
+		targets = sess.BitbucketTargets
+		if len(targets) == 0 {
+			// without any targets every workspace the credentials can see is scanned
+			if lister, ok := sess.Client.(interface{ ListWorkspaces() ([]string, error) }); ok {
+				var workspaces []string
+				_, err := sess.withRetries(sess.Out, "list the bitbucket workspaces", func() (err error) {
+					workspaces, err = lister.ListWorkspaces()
+					return err
+				})
+				if err != nil {
+					sess.Out.Error(" Error listing bitbucket workspaces: %s\n", err)
+				}
+				targets = workspaces
+			}
+		}
 	}
 
+	// members are only gathered when their repositories or gists are going to be
+	expandMembers := !sess.NoExpandOrgs && (sess.ExpandMembersRepos || sess.ExpandMembersGists && !sess.NoGists)
+
 	for _, loginOption := range targets {
-		target, err := sess.Client.GetUserOrganization(loginOption)
+		if sess.Interrupted() {
+			break
+		}
+		var target *Owner
+		attempts, err := sess.withRetries(sess.Out, "retrieve information on "+loginOption, func() (err error) {
+			target, err = sess.Client.GetUserOrganization(loginOption)
+			return err
+		})
 		if err != nil || target == nil {
 			sess.Out.Error(" Error retrieving information on %s: %s\n", loginOption, err)
+			if err != nil {
+				sess.recordScanError(loginOption, ScanErrorTarget, attempts, err)
+			}
 			continue
 		}
 		sess.Out.Debug("%s (ID: %d) type: %s\n", *target.Login, *target.ID, *target.Type)
 		sess.AddTarget(target)
-		if sess.NoExpandOrgs == false && *target.Type == TargetTypeOrganization {
+		if expandMembers && *target.Type == TargetTypeOrganization {
 			sess.Out.Debug("Gathering members of %s (ID: %d)...\n", *target.Login, *target.ID)
-			members, err := sess.Client.GetOrganizationMembers(*target)
+			var members []*Owner
+			attempts, err := sess.withRetries(sess.Out, "retrieve the members of "+*target.Login, func() (err error) {
+				members, err = sess.Client.GetOrganizationMembers(*target)
+				return err
+			})
 			if err != nil {
 				sess.Out.Error(" Error retrieving members of %s: %s\n", *target.Login, err)
+				sess.recordScanError(*target.Login, ScanErrorTarget, attempts, err)
 				continue
 			}
 			for _, member := range members {
 				sess.Out.Debug("Adding organization member %s (ID: %d) to targets\n", *member.Login, *member.ID)
+				member.member = true
 				sess.AddTarget(member)
 			}
 		}
@@ -87,7 +192,7 @@ func GatherLocalRepositories(sess *Session) {
 	// It will contain directorys, that will then be added to the repo count
 	// if they contain a .git directory
 	sess.Stats.Targets = len(sess.LocalDirs)
-	sess.Stats.Status = StatusGathering
+	sess.SetStatus(StatusGathering)
 	sess.Out.Important("Gathering Local Repositories...\n")
 
 	for _, pth := range sess.LocalDirs {
@@ -178,6 +283,18 @@ func GatherLocalRepositories(sess *Session) {
 	}
 }
 
+// getMemberRepositories will gather the repositories of an organization member that have the member-affiliation with
+// them. Providers that can not tell the repositories a member owns from those they were added to give those they own.
+func getMemberRepositories(sess *Session, target Owner) ([]*Repository, error) {
+	lister, ok := sess.Client.(interface {
+		GetMemberRepositories(target Owner, affiliation string) ([]*Repository, error)
+	})
+	if !ok {
+		return sess.Client.GetRepositoriesFromOwner(target)
+	}
+	return lister.GetMemberRepositories(target, sess.MemberAffiliation)
+}
+
 // Gather Repositories will gather all repositories associated with a given target during a scan session.
 // This is done using threads, whose count is set via commandline flag. Care much be taken to avoid rate
 // limiting associated with suspected DOS attacks.
@@ -203,18 +320,71 @@ func GatherRepositories(sess *Session) {
 					wg.Done()
 					return
 				}
-				repos, err := sess.Client.GetRepositoriesFromOwner(*target)
+				if sess.Interrupted() {
+					continue
+				}
+				var repos []*Repository
+				attempts, err := sess.withRetries(sess.Out, "retrieve the repositories of "+*target.Login, func() (err error) {
+					switch {
+					case target.member && !sess.ExpandMembersRepos:
+					case target.member:
+						repos, err = getMemberRepositories(sess, *target)
+					default:
+						repos, err = sess.Client.GetRepositoriesFromOwner(*target)
+					}
+					return err
+				})
 				if err != nil {
 					sess.Out.Error(" Failed to retrieve repositories from %s: %s\n", *target.Login, err)
+					sess.recordScanError(*target.Login, ScanErrorRepositories, attempts, err)
 				}
-				if len(repos) == 0 {
+				if len(repos) > 0 {
+					skipped := 0
+					for _, repo := range repos {
+						if reason := sess.skipRepository(repo); reason != "" {
+							sess.Out.Debug(" Skipping repository %s as %s\n", *repo.CloneURL, reason)
+							skipped++
+							continue
+						}
+						sess.Out.Debug(" Retrieved repository: %s\n", *repo.CloneURL)
+						sess.AddRepository(repo)
+					}
+					sess.Out.Info(" Retrieved %d %s from %s\n", len(repos), Pluralize(len(repos), "repository", "repositories"), *target.Login)
+					if skipped > 0 {
+						sess.Out.Info(" Left out %d of them that do not match the repository filters\n", skipped)
+					}
+				}
+
+				// gists belong to users, organizations have none of their own
+				if sess.NoGists || target.member && !sess.ExpandMembersGists || target.Type == nil || *target.Type != TargetTypeUser {
 					continue
 				}
-				for _, repo := range repos {
-					sess.Out.Debug(" Retrieved repository: %s\n", *repo.CloneURL)
-					sess.AddRepository(repo)
+				lister, ok := sess.Client.(interface {
+					GetGistsFromOwner(target Owner) ([]*Repository, error)
+				})
+				if !ok {
+					continue
+				}
+				var gists []*Repository
+				attempts, err = sess.withRetries(sess.Out, "retrieve the gists of "+*target.Login, func() (err error) {
+					gists, err = lister.GetGistsFromOwner(*target)
+					return err
+				})
+				if err != nil {
+					sess.Out.Error(" Failed to retrieve gists from %s: %s\n", *target.Login, err)
+					sess.recordScanError(*target.Login, ScanErrorRepositories, attempts, err)
+				}
+				for _, gist := range gists {
+					if reason := sess.skipRepository(gist); reason != "" {
+						sess.Out.Debug(" Skipping gist %s as %s\n", *gist.CloneURL, reason)
+						continue
+					}
+					sess.Out.Debug(" Retrieved gist: %s\n", *gist.CloneURL)
+					sess.AddRepository(gist)
+				}
+				if len(gists) > 0 {
+					sess.Out.Info(" Retrieved %d %s from %s\n", len(gists), Pluralize(len(gists), "gist", "gists"), *target.Login)
 				}
-				sess.Out.Info(" Retrieved %d %s from %s\n", len(repos), Pluralize(len(repos), "repository", "repositories"), *target.Login)
 			}
 		}()
 	}
@@ -232,60 +402,195 @@ func GatherRepositories(sess *Session) {
 //
 //			sess.Out.Error(fmt.Sprintf("Error while performing file match: %s\n", err))
 
-// cloneRepository will clone a given repository based upon a configured set or options a user provides
-func cloneRepository(sess *Session, repo *Repository, threadId int) (*git.Repository, string, error) {
-	sess.Out.Debug("[THREAD #%d][%s] Cloning repository...\n", threadId, *repo.CloneURL)
+// cloneConfiguration is how a repository is cloned, with the credentials of the provider it is from, and the function
+// that clones it
+func cloneConfiguration(sess *Session, repo *Repository, inMem bool) (CloneConfiguration, func(*CloneConfiguration) (*git.Repository, string, error), error) {
+	var cloneConfig CloneConfiguration
+	var cloner func(*CloneConfiguration) (*git.Repository, string, error)
 
-	var clone *git.Repository
-	var path string
-	var err error
+	// every branch is fetched when more than the default branch is going to be scanned
+	allBranches := len(sess.ScanBranches) > 0
 
 	switch sess.ScanType {
 	case "github":
-		cloneConfig := CloneConfiguration{
-			Url:        repo.CloneURL,
-			Branch:     repo.DefaultBranch,
-			Depth:      &sess.CommitDepth,
-			InMemClone: &sess.InMemClone,
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
 		}
-		clone, path, err = CloneGithubRepository(&cloneConfig)
+		if sess.GithubApp != nil {
+			token, err := sess.GithubApp.Token()
+			if err != nil {
+				return cloneConfig, nil, err
+			}
+			userName := GithubAppTokenUser
+			cloneConfig.Username = &userName
+			cloneConfig.Token = &token.AccessToken
+		}
+		cloner = CloneGithubRepository
 	case "gitlab":
 		userName := "oauth2"
-		cloneConfig := CloneConfiguration{
-			Url:        repo.CloneURL,
-			Branch:     repo.DefaultBranch,
-			Depth:      &sess.CommitDepth,
-			Token:      &sess.GitlabAccessToken, // TODO Is this need since we already have a client?
-			InMemClone: &sess.InMemClone,
-			Username:   &userName,
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &sess.GitlabAccessToken, // TODO Is this need since we already have a client?
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &userName,
+		}
+		cloner = CloneGitlabRepository
+	case "bitbucket":
+		userName, password := bitbucketCloneAuth(sess)
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &password,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &userName,
 		}
-		clone, path, err = CloneGitlabRepository(&cloneConfig)
+		cloner = CloneBitbucketRepository
+	case "azureDevops":
+		userName := AzureDevopsPATUser
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &sess.AzureDevopsToken,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &userName,
+		}
+		cloner = CloneAzureDevopsRepository
+	case "gitea":
+		userName := GiteaTokenUser
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &sess.GiteaAccessToken,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &userName,
+		}
+		cloner = CloneGiteaRepository
+	case "bitbucketServer":
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &sess.BitbucketServerToken,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &sess.BitbucketServerUsername,
+			Bearer:      sess.BitbucketServerUsername == "",
+		}
+		cloner = CloneBitbucketServerRepository
+	case "codeCommit":
+		userName, password, err := repo.codeCommit.gitCredentials(*repo.CloneURL, time.Now())
+		if err != nil {
+			return cloneConfig, nil, err
+		}
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &password,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &userName,
+		}
+		cloner = CloneCodeCommitRepository
 	case "localGit":
-		cloneConfig := CloneConfiguration{
-			Url:        repo.CloneURL,
-			Branch:     repo.DefaultBranch,
-			Depth:      &sess.CommitDepth,
-			InMemClone: &sess.InMemClone,
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
 		}
-		clone, path, err = CloneLocalRepository(&cloneConfig)
+		cloner = CloneLocalRepository
+	case "repoList":
+		cloneConfig = CloneConfiguration{
+			Url:         repo.CloneURL,
+			Branch:      repo.DefaultBranch,
+			Depth:       &sess.CommitDepth,
+			Token:       &repo.listed.token,
+			InMemClone:  &inMem,
+			AllBranches: &allBranches,
+			Username:    &repo.listed.username,
+			SSHKey:      &repo.listed.sshKey,
+		}
+		cloner = CloneRepoListRepository
+	}
 
+	if sess.sshClone(repo, &cloneConfig) {
+		cloner = CloneSSHRepository
 	}
+	return cloneConfig, cloner, nil
+}
+
+// cloneRepository will clone a given repository based upon a configured set or options a user provides
+func cloneRepository(sess *Session, repo *Repository, inMem bool, threadId int) (*git.Repository, string, error) {
+	log := sess.Out.With("repo", *repo.FullName)
+	log.Debug("[THREAD #%d][%s] Cloning repository...\n", threadId, *repo.CloneURL)
+
+	var clone *git.Repository
+	var path string
+	cloneConfig, cloner, err := cloneConfiguration(sess, repo, inMem)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// with a clone cache the bare clone kept from the last scan is fetched into, whichever provider it is from. A
+	// clone that fails is tried again, as a server that drops a connection now and then would otherwise leave holes in
+	// what is scanned.
+	attempts, err := sess.withRetries(log, "clone "+*repo.CloneURL, func() error {
+		var err error
+		switch {
+		case cloner == nil:
+		case sess.CloneCache != "":
+			clone, path, err = CloneCachedRepository(sess.CloneCache, &cloneConfig)
+		default:
+			clone, path, err = cloner(&cloneConfig)
+		}
+		if err != nil && path != "" {
+			// what was cloned before the clone failed is not used
+			os.RemoveAll(path)
+		}
+		return err
+	})
 	if err != nil {
 		switch err.Error() {
 		case "remote repository is empty":
-			sess.Out.Error("Repository %s is empty: %s\n", *repo.CloneURL, err)
+			log.Error("Repository %s is empty: %s\n", *repo.CloneURL, err)
 			sess.Stats.IncrementRepositoriesCloned()
 			//sess.Stats.UpdateProgress(sess.Stats.RepositoriesCloned, len(sess.Repositories))
 			return nil, "", err
 		default:
-			sess.Out.Error("Error cloning repository %s: %s\n", *repo.CloneURL, err)
+			log.Error("Error cloning repository %s: %s\n", *repo.CloneURL, err)
+			sess.recordScanError(*repo.FullName, ScanErrorClone, attempts, err)
 			//sess.Stats.UpdateProgress(sess.Stats.RepositoriesCloned, len(sess.Repositories))
 			return nil, "", err
 		}
 	}
 	sess.Stats.IncrementRepositoriesCloned()
 	//sess.Stats.UpdateProgress(sess.Stats.RepositoriesCloned, len(sess.Repositories))
-	sess.Out.Debug("[THREAD #%d][%s] Cloned repository to: %s\n", threadId, *repo.CloneURL, path)
+	log.Debug("[THREAD #%d][%s] Cloned repository to: %s\n", threadId, *repo.CloneURL, path)
+
+	// the tags and branches of a commit range are not cloned either
+	sess.CommitRange.Fetch(clone, cloneConfig.auth())
+
+	// notes are not fetched by a clone, not having them only means they are not scanned
+	if sess.ScanNotes {
+		if err := FetchNotes(clone, cloneConfig.auth()); err != nil {
+			log.Warn("[THREAD #%d][%s] Unable to fetch git notes: %s\n", threadId, *repo.CloneURL, err)
+		}
+	}
 	return clone, path, err
 }
 
@@ -301,229 +606,39 @@ func cloneRepository(sess *Session, repo *Repository, threadId int) (*git.Reposi
 //				sess.Out.Debug("[THREAD #%d][%s] Done analyzing commits\n", tid, *repo.CloneURL)
 //				sess.Out.Debug("[THREAD #%d][%s] Deleted %s\n", tid, *repo.CloneURL, path)
 
-func AnalyzeRepositories(sess *Session) {
-	sess.Stats.Status = StatusAnalyzing
+// AnalyzeRepositories will scan the gathered repositories, or hand them to the workers of a distributed scan. It is an
+// error when no repositories have been gathered.
+func AnalyzeRepositories(sess *Session) error {
+	if sess.Interrupted() {
+		return nil
+	}
+	sess.SetStatus(StatusAnalyzing)
 	if len(sess.Repositories) == 0 {
-		sess.Out.Error("No repositories have been gathered.")
-		os.Exit(2)
+		return errors.New("no repositories have been gathered")
 	}
 
-	var ch = make(chan *Repository, len(sess.Repositories))
-	var wg sync.WaitGroup
-
-	var threadNum int
-	if len(sess.Repositories) <= 1 {
-		threadNum = 1
-	} else if len(sess.Repositories) <= sess.Threads {
-		threadNum = len(sess.Repositories) - 1
-	} else {
-		threadNum = sess.Threads
+	// a distributed scan hands the repositories to the workers instead of scanning them here
+	if sess.QueueURL != "" {
+		DistributeRepositories(sess)
+		return nil
 	}
-	wg.Add(threadNum)
-	sess.Out.Debug("Threads for repository analysis: %d\n", threadNum)
-
-	sess.Out.Important("Analyzing %d %s...\n", len(sess.Repositories), Pluralize(len(sess.Repositories), "repository", "repositories"))
-
-	for i := 0; i < threadNum; i++ {
-		go func(tid int) {
-			for {
-
-				sess.Out.Debug("[THREAD #%d] Requesting new repository to analyze...\n", tid)
-				repo, ok := <-ch
-				if !ok {
-					sess.Out.Debug("[THREAD #%d] No more tasks, marking WaitGroup as done\n", tid)
-					wg.Done()
-					return
-				}
-
-				// Clone the repository from the remote source or if local from the path
-				// path is returning the path that the clone was done to, nothing inside that. The repo is clone directly to there
-				clone, path, err := cloneRepository(sess, repo, tid)
-				if err != nil {
-					if err.Error() != "remote repository is empty" {
-						sess.Out.Error("Error cloning repository %s: %s\n", *repo.FullName, err)
-					}
-					continue
-				}
-
-				// Get the commit history for the repo
-				history, err := GetRepositoryHistory(clone)
-				if err != nil {
-					sess.Out.Error("[THREAD #%d][%s] Error getting commit history: %s\n", tid, *repo.CloneURL, err)
-					if sess.InMemClone {
-						err := os.RemoveAll(path)
-						sess.Out.Error("[THREAD #%d][%s] Error removing path from memory: %s\n", tid, *repo.CloneURL, err)
-					} else {
-						err := os.RemoveAll(path)
-						sess.Out.Error("[THREAD #%d][%s] Error removing path from disk: %s\n", tid, *repo.CloneURL, err)
-					}
-					continue
-				}
-				//sess.Stats.IncrementRepositories()
-				//sess.Stats.UpdateProgress(sess.Stats.RepositoriesCloned, len(sess.Repositories))
-				sess.Out.Debug("[THREAD #%d][%s] Number of commits: %d\n", tid, *repo.CloneURL, len(history))
-
-				for _, commit := range history {
-					sess.Out.Debug("[THREAD #%d][%s] Analyzing commit: %s\n", tid, *repo.CloneURL, commit.Hash)
 
-					// Increment the total number of commits scanned
-					sess.Stats.IncrementCommits()
-					//sess.Stats.IncrementCommitsScanned() // TODO implement in stats
-
-					// This will be used to increment the dirty commit stat if any matches are found
-					dirtyCommit := false
-
-					changes, _ := GetChanges(commit, clone)
-					sess.Out.Debug("[THREAD #%d][%s] %s changes in %d\n", tid, *repo.CloneURL, commit.Hash, len(changes))
-
-					for _, change := range changes {
-
-						changeAction := GetChangeAction(change)
-						fPath := GetChangePath(change)
-
-						fullFilePath := path + "/" + fPath
-
-						sess.Stats.IncrementFilesTotal()
-
-						likelyTestFile := false
-
-						if !sess.ScanTests {
-							likelyTestFile = isTestFileOrPath(fullFilePath)
-						}
-
-						// If the file is likely a test then ignore it
-						if likelyTestFile {
-							// If we are not scanning the file then by definition we are ignoring it
-							sess.Stats.IncrementFilesIgnored()
-							sess.Out.Debug("%s is a test file and being ignored\n", fPath)
-
-							continue
-						}
-
-						if IsMaxFileSize(fullFilePath, sess) {
-
-							sess.Stats.IncrementFilesIgnored()
-							sess.Out.Debug("%s is too large and being ignored\n", fPath)
-
-							continue
-						}
-
-						// If the file matches a file extension or other method that precludes it from a scan
-						matchFile := newMatchFile(fullFilePath)
-						if matchFile.isSkippable(sess) {
-							// If we are not scanning the file then by definition we are ignoring it
-							sess.Stats.IncrementFilesIgnored()
-							sess.Out.Debug("%s is skippable and being ignored\n", fPath)
-
-							continue
-						}
-						sess.Stats.IncrementFilesTotal()
-
-						// We are now finally at the point where we are going to scan a file
-						sess.Stats.IncrementFilesScanned()
-
-						// for each signature that is loaded scan the file as a whole and generate a map of the match and the line number the match was found on
-						for _, signature := range Signatures {
-
-							bMatched, matchMap := signature.ExtractMatch(matchFile, sess, change)
-							if bMatched {
-
-								sess.Stats.IncrementFilesDirty()
-
-								var content string   // this is because file matches are puking
-								var genericID string // the generic id used in the finding
-
-								// for every instance of the secret that matched the specific signatures create a new finding
-								for k, v := range matchMap {
-
-									cleanK := strings.SplitAfterN(k, "_", 2)
-									if matchMap == nil {
-										content = ""
-										genericID = *repo.Name + "://" + fPath + "_" + generateGenericID(content)
-									} else {
-										content = cleanK[1]
-										genericID = *repo.Name + "://" + fPath + "_" + generateGenericID(content)
-
-									}
-
-									// destroy the secret if the flag is set
-									if sess.HideSecrets {
-										content = ""
-									}
-
-									finding := &Finding{
-										Action:            changeAction,
-										Comment:           content,
-										CommitAuthor:      commit.Author.String(),
-										CommitHash:        commit.Hash.String(),
-										CommitMessage:     strings.TrimSpace(commit.Message),
-										Description:       signature.Description(),
-										FilePath:          fPath,
-										WraithVersion:     version.AppVersion(),
-										LineNumber:        strconv.Itoa(v),
-										RepositoryName:    *repo.Name,
-										RepositoryOwner:   *repo.Owner,
-										Signatureid:       signature.Signatureid(),
-										SignaturesVersion: sess.SignatureVersion,
-										SecretID:          genericID,
-									}
-
-									// Get a proper uid for the finding
-									finding.Initialize(sess.ScanType)
-									fNew := true
-
-									for _, f := range sess.Findings {
-										if f.CommitHash == finding.CommitHash && f.SecretID == finding.SecretID && f.Description == finding.Description {
-											fNew = false
-											continue
-										}
-									}
-
-									if fNew {
-										// Add it to the session
-										sess.AddFinding(finding)
-										sess.Stats.IncrementCommits()
-										sess.Out.Debug("[THREAD #%d][%s] Done analyzing changes in %s\n", tid, *repo.CloneURL, commit.Hash)
-
-										dirtyCommit = true
-
-										//print realtime data to stdout
-										realTimeOutput(finding, sess)
-									}
-
-								}
-								sess.Out.Debug("[THREAD #%d][%s] Done analyzing commits\n", tid, *repo.CloneURL)
-								if sess.InMemClone {
-									err = os.RemoveAll(path)
-									if err != nil {
-										sess.Out.Error("Could not remove path from memory: %s", err.Error())
-									}
-								}
-								sess.Out.Debug("[THREAD #%d][%s] Deleted %s\n", tid, *repo.CloneURL, path)
-								//sess.Stats.IncrementRepositoriesScanned()
-								//sess.Stats.UpdateProgress(sess.Stats.RepositoriesScanned, len(sess.Repositories))
-							}
-						}
-					}
-					// Increment the number of commits that were found t be dirty
-					if dirtyCommit {
-						sess.Stats.IncrementCommitsDirty()
-					}
-				}
+	// the repositories a resumed scan finished before it was interrupted are not scanned again
+	repositories := sess.remainingRepositories()
+	sess.Out.Important("Analyzing %d %s...\n", len(repositories), Pluralize(len(repositories), "repository", "repositories"))
+	runPipeline(sess, repositories)
+	return nil
+}
 
-				err = os.RemoveAll(path)
-				if err != nil {
-					sess.Out.Error("Could not remove path from disk: %s", err.Error())
-				}
-				sess.Stats.IncrementRepositoriesScanned()
-			}
-		}(i)
+// isMaxChangeSize will check the size of a file in the checkout, or of the file in the commit when it is not checked
+// out, such as a file on another branch or one that has since been deleted
+func isMaxChangeSize(fullFilePath string, change *object.Change, sess *Session) bool {
+	if _, err := os.Stat(fullFilePath); err == nil {
+		return IsMaxFileSize(fullFilePath, sess)
 	}
-	for _, repo := range sess.Repositories {
-		ch <- repo
+	_, to, err := change.Files()
+	if err != nil || to == nil {
+		return true
 	}
-
-	close(ch)
-	wg.Wait()
-
+	return to.Size > sess.MaxFileSize*1024*1024
 }
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wraith/rpc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPath is where the rest api is served on the web interface
+const APIPath = "/api/v1"
+
+// These are the number of findings the api returns at once when no limit is asked for, and the most it returns
+const (
+	APIDefaultLimit = 100
+	APIMaximumLimit = 1000
+)
+
+// APIFinding is a finding as the api returns it, the stable json representation along with its triage
+type APIFinding struct {
+	JSONFinding
+	Triage *Triage `json:"triage,omitempty"`
+}
+
+// APIFindings is a page of the findings that match the filters of a request, total counts them all
+type APIFindings struct {
+	Total    int          `json:"total"`
+	Offset   int          `json:"offset"`
+	Limit    int          `json:"limit"`
+	Findings []APIFinding `json:"findings"`
+}
+
+// APIScan is the status and statistics of a scan known to the server
+type APIScan struct {
+	ID         string     `json:"id"`
+	ScanType   string     `json:"scan_type"`
+	Schedule   string     `json:"schedule,omitempty"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Stats      JSONStats  `json:"stats"`
+}
+
+// apiScan will build a snapshot of a scan for the api
+func (s *ManagedScan) apiScan() APIScan {
+	st := s.Status()
+	scan := APIScan{ID: st.ScanId, ScanType: st.ScanType, Schedule: st.Schedule, Status: st.Status, Error: st.Error}
+
+	s.Lock()
+	sess := s.Session
+	s.Unlock()
+	if sess == nil {
+		return scan
+	}
+
+	scan.Stats = NewJSONStats(sess.Stats)
+	if st.StartedAt > 0 {
+		startedAt := time.Unix(st.StartedAt, 0).UTC()
+		scan.StartedAt = &startedAt
+	}
+	if st.FinishedAt > 0 {
+		finishedAt := time.Unix(st.FinishedAt, 0).UTC()
+		scan.FinishedAt = &finishedAt
+	}
+	return scan
+}
+
+// filterFindings returns the findings that match the filters in the query of a request: the minimum severity and
+// confidence, the repository as owner/name, the signature id, the hash of the secret, the triage status, the
+// verification result, part of the file path and free text found in the path, commit, author, repository or signature of the finding
+func filterFindings(c *gin.Context, findings []*Finding) ([]*Finding, error) {
+	severity := c.Query("severity")
+	if severity != "" && !ValidSeverity(severity) {
+		return nil, fmt.Errorf("severity must be one of: %s", strings.Join(Severities, ", "))
+	}
+	confidence := c.Query("confidence")
+	if confidence != "" && !ValidConfidence(confidence) {
+		return nil, fmt.Errorf("confidence must be one of: %s", strings.Join(Confidences, ", "))
+	}
+	triage := c.Query("triage")
+	if triage != "" && triage != "untriaged" && !ValidTriageStatus(triage) {
+		return nil, fmt.Errorf("triage must be one of: %s, untriaged", strings.Join(TriageStatuses, ", "))
+	}
+	repository := c.Query("repository")
+	signature := c.Query("signature")
+	secret := c.Query("secret")
+	verification := c.Query("verification")
+	path := strings.ToLower(c.Query("path"))
+	text := strings.ToLower(strings.TrimSpace(c.Query("q")))
+
+	filtered := []*Finding{}
+	for _, f := range filterTriage(findings, triage) {
+		switch {
+		case severity != "" && severityRanks[FindingSeverity(f)] < severityRanks[severity]:
+		case confidence != "" && confidenceRanks[FindingConfidence(f)] < confidenceRanks[confidence]:
+		case repository != "" && f.RepositoryOwner+"/"+f.RepositoryName != repository:
+		case signature != "" && f.Signatureid != signature:
+		case secret != "" && f.SecretHash != secret:
+		case verification != "" && f.Verification != verification:
+		case path != "" && !strings.Contains(strings.ToLower(f.FilePath), path):
+		case text != "" && !findingContains(f, text):
+		default:
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered, nil
+}
+
+// findingContains reports whether the lowercase text is in the path, commit, author, repository or signature of a
+// finding. The secret itself is not searched, so a search can not be used to guess a redacted secret.
+func findingContains(f *Finding, text string) bool {
+	for _, field := range []string{f.FilePath, f.CommitHash, f.CommitAuthor, f.RepositoryOwner + "/" + f.RepositoryName,
+		f.Signatureid, f.Description} {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// findingsPage returns the offset and limit of the page of findings asked for in the query of a request
+func findingsPage(c *gin.Context) (offset, limit int, err error) {
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(APIDefaultLimit)))
+	if err != nil || limit < 1 || limit > APIMaximumLimit {
+		return 0, 0, fmt.Errorf("limit must be a number from 1 to %d", APIMaximumLimit)
+	}
+	offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, errors.New("offset must be a number of 0 or more")
+	}
+	return offset, limit, nil
+}
+
+// listFindings will respond with a page of the findings that match the filters of the request
+func listFindings(c *gin.Context, findings []*Finding) {
+	filtered, err := filterFindings(c, findings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	offset, limit, err := findingsPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	page := APIFindings{Total: len(filtered), Offset: offset, Limit: limit, Findings: []APIFinding{}}
+	for i := offset; i < len(filtered) && i < offset+limit; i++ {
+		page.Findings = append(page.Findings, APIFinding{JSONFinding: NewJSONFinding(filtered[i]), Triage: filtered[i].Triage})
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// apiFindings responds with the findings of the running session
+func apiFindings(c *gin.Context, s *Session) {
+	s.Lock()
+	findings := append([]*Finding(nil), s.Findings...)
+	s.Unlock()
+	listFindings(c, findings)
+}
+
+// apiSessionFindings responds with the findings of a session in the database
+func apiSessionFindings(c *gin.Context, s *Session) {
+	id, ok := storedSession(c, s)
+	if !ok {
+		return
+	}
+	findings, err := s.storedFindings(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	listFindings(c, findings)
+}
+
+// managedScan will look up the scan in the url, responding with an error when there is no such scan
+func managedScan(c *gin.Context, s *Session) (*ManagedScan, bool) {
+	if s.Scans == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Scans are not tracked by this session",
+		})
+		return nil, false
+	}
+	scan, ok := s.Scans.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": fmt.Sprintf("There is no scan %s", c.Param("id")),
+		})
+		return nil, false
+	}
+	return scan, true
+}
+
+// apiScans responds with every scan known to the server, the one started from the command line first
+func apiScans(c *gin.Context, s *Session) {
+	scans := []APIScan{}
+	if s.Scans != nil {
+		for _, scan := range s.Scans.List() {
+			scans = append(scans, scan.apiScan())
+		}
+	}
+	c.JSON(http.StatusOK, scans)
+}
+
+// apiSchedules responds with the schedules of the serve command, when each of them runs next and the last scan each
+// of them ran
+func apiSchedules(c *gin.Context, s *Session) {
+	if s.Scheduler == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Scans are not scheduled, run the serve command with schedules in its config file",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, s.Scheduler.Schedules())
+}
+
+// apiScan responds with the status of a scan
+func apiScan(c *gin.Context, s *Session) {
+	if scan, ok := managedScan(c, s); ok {
+		c.JSON(http.StatusOK, scan.apiScan())
+	}
+}
+
+// apiScanFindings responds with the findings a scan has made so far
+func apiScanFindings(c *gin.Context, s *Session) {
+	if scan, ok := managedScan(c, s); ok {
+		findings, _ := scan.findingsSince(0)
+		listFindings(c, findings)
+	}
+}
+
+// allowScanControl will check that scans can be started and cancelled through the api, responding with an error when
+// they can not. They run with the credentials of the server, so this has to be turned on with api-scans, and only
+// for a web interface that users have to sign in to.
+func allowScanControl(c *gin.Context, s *Session) bool {
+	if !s.APIScans || s.Scans == nil || s.WebAuth == nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Scans can not be started or cancelled through the api, set api-scans along with web-username or oidc-issuer to allow it",
+		})
+		return false
+	}
+	return true
+}
+
+// submitScan starts a scan described by the json of the request, which has the same fields as SubmitScan in the gRPC
+// api. Only json is accepted so a form on another site can not start scans for a signed in user.
+func submitScan(c *gin.Context, s *Session) {
+	if !allowScanControl(c, s) {
+		return
+	}
+	if c.ContentType() != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"message": "The scan must be sent as application/json",
+		})
+		return
+	}
+
+	var req rpc.SubmitScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	id, err := s.Scans.Submit(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	scan, _ := s.Scans.Get(id)
+	c.Header("Location", APIPath+"/scans/"+id)
+	c.JSON(http.StatusAccepted, scan.apiScan())
+}
+
+// cancelScan stops a scan, it finishes what it is scanning and keeps the findings made so far
+func cancelScan(c *gin.Context, s *Session) {
+	if !allowScanControl(c, s) {
+		return
+	}
+	scan, ok := managedScan(c, s)
+	if !ok {
+		return
+	}
+	if err := scan.Cancel(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	s.Out.Warn("Scan %s was cancelled through the api, the scans in progress are being finished\n", scan.ID)
+	c.JSON(http.StatusOK, scan.apiScan())
+}
@@ -0,0 +1,204 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wraith/core"
+
+	"github.com/spf13/viper"
+)
+
+func TestAPI(t *testing.T) {
+
+	Convey("Given the api of a running session", t, func() {
+		sess := &core.Session{
+			Out:      &core.Logger{},
+			ScanType: "github",
+			Stats:    &core.Stats{StartedAt: time.Now(), Status: core.StatusInitializing, FilesScanned: 12},
+			Findings: []*core.Finding{
+				{FilePath: "a.env", RepositoryOwner: "acme", RepositoryName: "api", Severity: core.SeverityCritical, Confidence: core.ConfidenceHigh, Signatureid: "aws",
+					SecretHash: "sha256:a1"},
+				{FilePath: "b.env", RepositoryOwner: "acme", RepositoryName: "api", Severity: core.SeverityLow, Confidence: core.ConfidenceHigh, Signatureid: "password"},
+				{FilePath: "c.env", RepositoryOwner: "acme", RepositoryName: "web", Severity: core.SeverityHigh, Confidence: core.ConfidenceLow, Signatureid: "aws",
+					SecretHash: "sha256:a1"},
+				{FilePath: "d.env", RepositoryOwner: "acme", RepositoryName: "web", Severity: core.SeverityMedium, Confidence: core.ConfidenceMedium, Signatureid: "slack",
+					Triage: &core.Triage{Status: core.TriageFalsePositive}},
+			},
+		}
+		sess.Scans = core.NewScanManager(viper.New())
+		sess.Scans.Add(sess)
+		auth, err := core.NewWebAuth(core.WebAuthConfig{Username: "admin", Password: "s3cret"})
+		So(err, ShouldBeNil)
+		sess.WebAuth = auth
+		router := core.NewRouter(sess)
+
+		request := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.SetBasicAuth("admin", "s3cret")
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+		findings := func(query string) (core.APIFindings, []string) {
+			w := request(http.MethodGet, "/api/v1/findings"+query, "", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var page core.APIFindings
+			So(json.Unmarshal(w.Body.Bytes(), &page), ShouldBeNil)
+			paths := []string{}
+			for _, f := range page.Findings {
+				paths = append(paths, f.FilePath)
+			}
+			return page, paths
+		}
+
+		Convey("Findings should be filtered by their severity, confidence, repository, signature and triage", func() {
+			_, paths := findings("")
+			So(paths, ShouldResemble, []string{"a.env", "b.env", "c.env", "d.env"})
+			_, paths = findings("?severity=high")
+			So(paths, ShouldResemble, []string{"a.env", "c.env"})
+			_, paths = findings("?severity=medium&confidence=medium")
+			So(paths, ShouldResemble, []string{"a.env", "d.env"})
+			_, paths = findings("?repository=acme/web")
+			So(paths, ShouldResemble, []string{"c.env", "d.env"})
+			_, paths = findings("?signature=aws&repository=acme/api")
+			So(paths, ShouldResemble, []string{"a.env"})
+			_, paths = findings("?path=C.ENV")
+			So(paths, ShouldResemble, []string{"c.env"})
+			_, paths = findings("?q=web&severity=high")
+			So(paths, ShouldResemble, []string{"c.env"})
+			_, paths = findings("?q=password")
+			So(paths, ShouldResemble, []string{"b.env"})
+			_, paths = findings("?secret=sha256:a1")
+			So(paths, ShouldResemble, []string{"a.env", "c.env"})
+			page, paths := findings("?triage=false_positive")
+			So(paths, ShouldResemble, []string{"d.env"})
+			So(page.Findings[0].Triage.Status, ShouldEqual, core.TriageFalsePositive)
+			So(page.Findings[0].Severity, ShouldEqual, core.SeverityMedium)
+		})
+
+		Convey("Findings of the same secret should be grouped", func() {
+			w := request(http.MethodGet, "/api/v1/secrets", "", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var secrets []core.SecretGroup
+			So(json.Unmarshal(w.Body.Bytes(), &secrets), ShouldBeNil)
+			So(secrets, ShouldHaveLength, 1)
+			So(secrets[0].Occurrences, ShouldEqual, 2)
+			So(secrets[0].Severity, ShouldEqual, core.SeverityCritical)
+			So(secrets[0].Repositories, ShouldResemble, []string{"acme/api", "acme/web"})
+		})
+
+		Convey("Findings should be returned a page at a time with the total that match", func() {
+			page, paths := findings("?limit=2&offset=1")
+			So(paths, ShouldResemble, []string{"b.env", "c.env"})
+			So(page.Total, ShouldEqual, 4)
+			page, paths = findings("?offset=10")
+			So(paths, ShouldBeEmpty)
+			So(page.Total, ShouldEqual, 4)
+		})
+
+		Convey("The web interface should be given a page of the findings along with the repositories and signatures", func() {
+			w := request(http.MethodGet, "/findings?offset=1&limit=1&repository=acme/api", "", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var page core.WebFindings
+			So(json.Unmarshal(w.Body.Bytes(), &page), ShouldBeNil)
+			So(page.Total, ShouldEqual, 2)
+			So(page.Findings, ShouldHaveLength, 1)
+			So(page.Findings[0].FilePath, ShouldEqual, "b.env")
+			So(page.Repositories, ShouldResemble, []string{"acme/api", "acme/web"})
+			So(page.Signatures, ShouldResemble, []string{"aws", "password", "slack"})
+
+			var all []*core.Finding
+			So(json.Unmarshal(request(http.MethodGet, "/findings?severity=high", "", "").Body.Bytes(), &all), ShouldBeNil)
+			So(all, ShouldHaveLength, 2)
+			So(request(http.MethodGet, "/findings?limit=5000", "", "").Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Filters and pages that do not make sense should be refused", func() {
+			So(request(http.MethodGet, "/api/v1/findings?severity=urgent", "", "").Code, ShouldEqual, http.StatusBadRequest)
+			So(request(http.MethodGet, "/api/v1/findings?triage=wontfix", "", "").Code, ShouldEqual, http.StatusBadRequest)
+			So(request(http.MethodGet, "/api/v1/findings?limit=0", "", "").Code, ShouldEqual, http.StatusBadRequest)
+			So(request(http.MethodGet, "/api/v1/findings?offset=-1", "", "").Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("The stats should use the names of the json output", func() {
+			w := request(http.MethodGet, "/api/v1/stats", "", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var stats core.JSONStats
+			So(json.Unmarshal(w.Body.Bytes(), &stats), ShouldBeNil)
+			So(stats.FilesScanned, ShouldEqual, 12)
+		})
+
+		Convey("The running session should be listed as scan 0", func() {
+			w := request(http.MethodGet, "/api/v1/scans", "", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var scans []core.APIScan
+			So(json.Unmarshal(w.Body.Bytes(), &scans), ShouldBeNil)
+			So(scans, ShouldHaveLength, 1)
+			So(scans[0].ID, ShouldEqual, "0")
+			So(scans[0].ScanType, ShouldEqual, "github")
+			So(scans[0].Stats.FilesScanned, ShouldEqual, 12)
+			So(scans[0].FinishedAt, ShouldBeNil)
+
+			So(request(http.MethodGet, "/api/v1/scans/0/findings?severity=critical", "", "").Body.String(), ShouldContainSubstring, `"total":1`)
+			So(request(http.MethodGet, "/api/v1/scans/7", "", "").Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Scans should not be started or cancelled unless api-scans is set", func() {
+			So(request(http.MethodPost, "/api/v1/scans", "application/json", `{"scan_type": "localPath", "targets": ["/"]}`).Code, ShouldEqual, http.StatusForbidden)
+			So(request(http.MethodDelete, "/api/v1/scans/0", "", "").Code, ShouldEqual, http.StatusForbidden)
+			So(sess.Interrupted(), ShouldBeFalse)
+		})
+
+		Convey("With api-scans set", func() {
+			sess.APIScans = true
+
+			Convey("a scan should only be started from json that describes one", func() {
+				So(request(http.MethodPost, "/api/v1/scans", "text/plain", `{"scan_type": "localPath", "targets": ["/"]}`).Code, ShouldEqual, http.StatusUnsupportedMediaType)
+				w := request(http.MethodPost, "/api/v1/scans", "application/json", `{"scan_type": "svn", "targets": ["/"]}`)
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, "unknown scan type svn")
+				So(request(http.MethodPost, "/api/v1/scans", "application/json", `{"scan_type": "localPath"}`).Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("a scan of the disk of the server should be refused", func() {
+				for _, scanType := range []string{"localGit", "localPath"} {
+					w := request(http.MethodPost, "/api/v1/scans", "application/json", `{"scan_type": "`+scanType+`", "targets": ["/"]}`)
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Body.String(), ShouldContainSubstring, "allow-local-scans")
+				}
+			})
+
+			Convey("scans should still not be started or cancelled without authentication for the web interface", func() {
+				sess.WebAuth = nil
+				router = core.NewRouter(sess)
+				So(request(http.MethodPost, "/api/v1/scans", "application/json", `{"scan_type": "github", "targets": ["acme"]}`).Code, ShouldEqual, http.StatusForbidden)
+				So(request(http.MethodDelete, "/api/v1/scans/0", "", "").Code, ShouldEqual, http.StatusForbidden)
+				So(sess.Interrupted(), ShouldBeFalse)
+			})
+
+			Convey("cancelling the running scan should interrupt it, and it can not be cancelled once it has finished", func() {
+				So(request(http.MethodDelete, "/api/v1/scans/0", "", "").Code, ShouldEqual, http.StatusOK)
+				So(sess.Interrupted(), ShouldBeTrue)
+
+				sess.Stats.FinishedAt = time.Now()
+				sess.Stats.Status = core.StatusInterrupted
+				w := request(http.MethodDelete, "/api/v1/scans/0", "", "")
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(w.Body.String(), ShouldContainSubstring, "it is interrupted")
+			})
+		})
+
+		Convey("Stored sessions should need the database", func() {
+			So(request(http.MethodGet, "/api/v1/sessions", "", "").Code, ShouldEqual, http.StatusNotFound)
+			So(request(http.MethodGet, "/api/v1/sessions/1/findings", "", "").Code, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
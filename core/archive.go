@@ -0,0 +1,219 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveSeparator is placed between the path of an archive and the path of a file inside of it, so a finding in a
+// nested archive is reported at a location such as build/app.zip!lib/core.jar!config.properties
+const ArchiveSeparator = "!"
+
+// These are the formats of archive that can be opened during a scan
+const (
+	ArchiveZip   = "zip"
+	ArchiveTar   = "tar"
+	ArchiveTarGz = "tar.gz"
+)
+
+// errArchiveBudget is returned once more than archive-max-size has been taken out of an archive
+var errArchiveBudget = errors.New("archive size budget reached")
+
+// archiveBudget is how many bytes can still be taken out of an archive, including the archives nested inside it
+type archiveBudget struct {
+	remaining int64
+}
+
+// archiveKind will return the format of an archive based on its name, or an empty string if it is not an archive
+func archiveKind(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveTar
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return ArchiveZip
+	}
+	return ""
+}
+
+// ScanArchive will take the files out of an archive and scan each of them, opening the archives inside of it until
+// archive-depth is reached. Files stop being taken out of it once archive-max-size is reached.
+func ScanArchive(filename string, sess *Session) {
+	budget := &archiveBudget{remaining: sess.ArchiveMaxSize * 1024 * 1024}
+	scanArchive(filename, filename, 1, budget, localFileFinding(filename), sess)
+}
+
+// scanArchive will scan the files of an archive on disk, reporting them under the location of the archive. The findings
+// in them are copied from base with the path of the file inside the archive.
+func scanArchive(fullFilePath string, location string, depth int, budget *archiveBudget, base Finding, sess *Session) {
+	dir, err := ioutil.TempDir("", "wraith-archive")
+	if err != nil {
+		sess.Out.Error("Unable to create a temp dir to open %s: %s\n", location, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// anything taken out before an error is still scanned
+	files, err := extractArchive(fullFilePath, dir, budget)
+	if err == errArchiveBudget {
+		sess.Out.Warn("%s is larger than the archive size budget, only part of it is being scanned\n", location)
+	} else if err != nil {
+		sess.Out.Error("Unable to open archive %s: %s\n", location, err)
+	}
+
+	for _, name := range files {
+		innerPath := filepath.Join(dir, filepath.FromSlash(name))
+		innerLocation := location + ArchiveSeparator + name
+
+		sess.Stats.IncrementFilesTotal()
+
+		matchFile := newMatchFile(innerLocation)
+		if matchFile.isSkippable(sess) {
+			sess.Out.Debug("%s is listed as skippable and is being ignored\n", innerLocation)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		if !sess.ScanTests && isTestFileOrPath(innerLocation) {
+			sess.Out.Debug("%s is a test file and being ignored\n", innerLocation)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		if archiveKind(name) != "" {
+			if depth < sess.ArchiveDepth {
+				scanArchive(innerPath, innerLocation, depth+1, budget, base, sess)
+			} else {
+				sess.Out.Debug("%s is nested deeper than the archive depth and being ignored\n", innerLocation)
+				sess.Stats.IncrementFilesIgnored()
+			}
+			continue
+		}
+
+		if IsMaxFileSize(innerPath, sess) {
+			sess.Out.Debug("%s is too large and being ignored\n", innerLocation)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		inner := base
+		inner.FilePath = innerLocation
+		scanFile(innerPath, inner, sess)
+	}
+}
+
+// extractArchive will write the regular files of an archive into a directory and return their paths inside the
+// archive. Links and devices are left out, as are any paths that would be written outside of the directory.
+func extractArchive(filename string, dir string, budget *archiveBudget) ([]string, error) {
+	var files []string
+
+	if archiveKind(filename) == ArchiveZip {
+		zr, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			name := archiveEntryPath(f.Name)
+			if !f.Mode().IsRegular() || name == "" {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			err = extractArchiveFile(rc, dir, name, budget)
+			rc.Close()
+			if err == errArchiveBudget {
+				return files, err
+			}
+			if err == nil {
+				files = append(files, name)
+			}
+		}
+		return files, nil
+	}
+
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	var r io.Reader = fh
+	if archiveKind(filename) == ArchiveTarGz {
+		gz, err := gzip.NewReader(fh)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+
+		name := archiveEntryPath(hdr.Name)
+		if (hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA) || name == "" {
+			continue
+		}
+		err = extractArchiveFile(tr, dir, name, budget)
+		if err == errArchiveBudget {
+			return files, err
+		}
+		if err == nil {
+			files = append(files, name)
+		}
+	}
+}
+
+// extractArchiveFile will write a single file from an archive into a directory, charging its size to the budget
+func extractArchiveFile(r io.Reader, dir string, name string, budget *archiveBudget) error {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	// read one byte past the budget so an archive that is exactly the size of it is not cut short
+	n, err := io.Copy(fh, io.LimitReader(r, budget.remaining+1))
+	fh.Close()
+	budget.remaining -= n
+	if budget.remaining < 0 {
+		os.Remove(target)
+		return errArchiveBudget
+	}
+	if err != nil {
+		os.Remove(target)
+	}
+	return err
+}
+
+// archiveEntryPath will clean the name of a file in an archive so that it stays inside the directory it is written
+// to, returning an empty string if nothing is left of it
+func archiveEntryPath(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
@@ -0,0 +1,460 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// These are the endpoints temporary credentials are fetched from. The container and instance metadata endpoints can
+// also be changed with AWS_CONTAINER_CREDENTIALS_FULL_URI and AWS_EC2_METADATA_SERVICE_ENDPOINT, as with the aws cli.
+var (
+	AWSSTSURL       = "https://sts.amazonaws.com/"
+	AWSContainerURL = "http://169.254.170.2"
+	AWSInstanceURL  = "http://169.254.169.254"
+	AWSSSOURL       = "https://portal.sso.%s.amazonaws.com"
+)
+
+// awsMetadataTimeout is how long the container and instance metadata endpoints have to answer, as they are not there
+// outside of AWS and a scan on a laptop should not hang waiting for them
+const awsMetadataTimeout = time.Second
+
+// awsProcessTimeout is the longest a credential_process is allowed to run
+const awsProcessTimeout = time.Minute
+
+// errNoAWSCredentials is returned when none of the places credentials are looked for has any
+var errNoAWSCredentials = errors.New("no aws credentials were found in the environment, the shared credentials and " +
+	"config files, a web identity token, the container credentials or the instance metadata")
+
+// LoadAWSCredentials will find credentials the same way the AWS cli and SDKs do:
+//
+//  1. the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables, unless a profile has been asked for
+//  2. the profile in the shared credentials and config files, with its keys, credential_process, SSO login or web
+//     identity token. The profile defaults to AWS_PROFILE or default.
+//  3. AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN, as set up for service accounts on EKS
+//  4. the container credentials of ECS tasks
+//  5. the role of an EC2 instance, from the instance metadata
+//
+// An error is returned when none of them has credentials, which IsNoAWSCredentials reports, so that requests are never
+// left unsigned without the caller knowing.
+func LoadAWSCredentials(profile string) (AWSCredentials, error) {
+	if profile == "" {
+		creds := AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+			return creds, nil
+		}
+	}
+
+	explicit := profile != "" || os.Getenv("AWS_PROFILE") != ""
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	providers := []func() (AWSCredentials, bool, error){
+		func() (AWSCredentials, bool, error) { return awsProfileCredentials(profile, explicit) },
+		awsWebIdentityCredentials,
+		awsContainerCredentials,
+		awsInstanceCredentials,
+	}
+	for _, provider := range providers {
+		creds, found, err := provider()
+		switch {
+		case err != nil:
+			return AWSCredentials{}, err
+		case found && (creds.AccessKeyID == "" || creds.SecretAccessKey == ""):
+			return AWSCredentials{}, errors.New("the aws credentials that were found have no access key")
+		case found:
+			return creds, nil
+		}
+	}
+	return AWSCredentials{}, errNoAWSCredentials
+}
+
+// IsNoAWSCredentials checks if an error of LoadAWSCredentials is that there are no credentials at all, rather than that
+// the ones that were found could not be used
+func IsNoAWSCredentials(err error) bool {
+	return errors.Is(err, errNoAWSCredentials)
+}
+
+// awsProfileCredentials will read the credentials of a profile, which can be in the shared credentials file, the config
+// file, or split between them. A profile that does not exist is only an error when it was asked for.
+func awsProfileCredentials(profile string, explicit bool) (AWSCredentials, bool, error) {
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = SetHomeDir("$HOME/.aws/credentials")
+	}
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = SetHomeDir("$HOME/.aws/config")
+	}
+
+	config, err := readAWSConfigFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return AWSCredentials{}, false, err
+	}
+	credentials, err := readAWSConfigFile(credentialsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return AWSCredentials{}, false, err
+	}
+
+	section := map[string]string{}
+	for _, sections := range []map[string]map[string]string{config, credentials} {
+		for k, v := range sections[profile] {
+			section[k] = v
+		}
+	}
+	if _, ok := config[profile]; !ok {
+		if _, ok := credentials[profile]; !ok {
+			if !explicit {
+				return AWSCredentials{}, false, nil
+			}
+			return AWSCredentials{}, false, fmt.Errorf("there is no %s profile in %s or %s", profile, credentialsFile, configFile)
+		}
+	}
+
+	var creds AWSCredentials
+	switch {
+	case section["aws_access_key_id"] != "" && section["aws_secret_access_key"] != "":
+		creds = AWSCredentials{
+			AccessKeyID:     section["aws_access_key_id"],
+			SecretAccessKey: section["aws_secret_access_key"],
+			SessionToken:    section["aws_session_token"],
+		}
+	case section["credential_process"] != "":
+		creds, err = awsProcessCredentials(section["credential_process"])
+	case section["sso_session"] != "" || section["sso_start_url"] != "":
+		creds, err = awsSSOCredentials(section, config)
+	case section["web_identity_token_file"] != "" && section["role_arn"] != "":
+		creds, err = assumeAWSRoleWithWebIdentity(section["web_identity_token_file"], section["role_arn"], section["role_session_name"])
+	case section["role_arn"] != "":
+		return AWSCredentials{}, false, fmt.Errorf("the %s profile assumes the role %s from other credentials, which is not "+
+			"supported, export its credentials with aws configure export-credentials --profile %s --format env", profile,
+			section["role_arn"], profile)
+	case explicit:
+		return AWSCredentials{}, false, fmt.Errorf("the %s profile has no credentials", profile)
+	default:
+		return AWSCredentials{}, false, nil
+	}
+	if err != nil {
+		return AWSCredentials{}, false, fmt.Errorf("unable to get the credentials of the %s profile: %s", profile, err)
+	}
+	return creds, true, nil
+}
+
+// readAWSConfigFile will read the keys of each profile in an ini file such as ~/.aws/credentials. Profiles in
+// ~/.aws/config lose their profile prefix, while other sections such as sso-session keep theirs.
+func readAWSConfigFile(file string) (map[string]map[string]string, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	sections := map[string]map[string]string{}
+	var section map[string]string
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(strings.TrimPrefix(line[1:len(line)-1], "profile "))
+			section = map[string]string{}
+			sections[name] = section
+		case section != nil && strings.Contains(line, "="):
+			kv := strings.SplitN(line, "=", 2)
+			section[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// awsProcessCredentials will run the credential_process of a profile and read the credentials it prints
+func awsProcessCredentials(command string) (AWSCredentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), awsProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("credential_process failed: %s %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result struct {
+		Version         int    `json:"Version"`
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return AWSCredentials{}, fmt.Errorf("credential_process did not print credentials: %s", err)
+	}
+	if result.Version != 1 {
+		return AWSCredentials{}, fmt.Errorf("credential_process printed version %d credentials, only version 1 is supported", result.Version)
+	}
+	if result.AccessKeyID == "" || result.SecretAccessKey == "" {
+		return AWSCredentials{}, errors.New("credential_process did not print an access key")
+	}
+	return AWSCredentials{AccessKeyID: result.AccessKeyID, SecretAccessKey: result.SecretAccessKey, SessionToken: result.SessionToken}, nil
+}
+
+// awsSSOCredentials will get the credentials of the role of an SSO profile with the token cached by aws sso login
+func awsSSOCredentials(section map[string]string, config map[string]map[string]string) (AWSCredentials, error) {
+	startURL, region, cacheKey := section["sso_start_url"], section["sso_region"], section["sso_start_url"]
+	if name := section["sso_session"]; name != "" {
+		session, ok := config["sso-session "+name]
+		if !ok {
+			return AWSCredentials{}, fmt.Errorf("there is no sso-session %s", name)
+		}
+		startURL, region, cacheKey = session["sso_start_url"], session["sso_region"], name
+	}
+	if startURL == "" || region == "" || section["sso_account_id"] == "" || section["sso_role_name"] == "" {
+		return AWSCredentials{}, errors.New("sso profiles need sso_start_url, sso_region, sso_account_id and sso_role_name")
+	}
+
+	sum := sha1.Sum([]byte(cacheKey))
+	data, err := ioutil.ReadFile(filepath.Join(SetHomeDir("$HOME/.aws/sso/cache"), hex.EncodeToString(sum[:])+".json"))
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("there is no sso token for %s, log in with aws sso login first", startURL)
+	}
+	var token struct {
+		AccessToken string    `json:"accessToken"`
+		ExpiresAt   time.Time `json:"expiresAt"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return AWSCredentials{}, fmt.Errorf("unable to read the sso token for %s: %s", startURL, err)
+	}
+	if token.AccessToken == "" || time.Now().After(token.ExpiresAt) {
+		return AWSCredentials{}, fmt.Errorf("the sso token for %s has expired, log in again with aws sso login", startURL)
+	}
+
+	query := url.Values{"account_id": {section["sso_account_id"]}, "role_name": {section["sso_role_name"]}}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(AWSSSOURL, region)+"/federation/credentials?"+query.Encode(), nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("X-Amz-Sso_bearer_token", token.AccessToken)
+	var resp struct {
+		RoleCredentials struct {
+			AccessKeyID     string `json:"accessKeyId"`
+			SecretAccessKey string `json:"secretAccessKey"`
+			SessionToken    string `json:"sessionToken"`
+		} `json:"roleCredentials"`
+	}
+	if err := awsCredentialsRequest(&http.Client{Timeout: VerifyTimeout}, req, &resp); err != nil {
+		return AWSCredentials{}, err
+	}
+	return AWSCredentials{
+		AccessKeyID:     resp.RoleCredentials.AccessKeyID,
+		SecretAccessKey: resp.RoleCredentials.SecretAccessKey,
+		SessionToken:    resp.RoleCredentials.SessionToken,
+	}, nil
+}
+
+// awsWebIdentityCredentials will assume AWS_ROLE_ARN with the token in AWS_WEB_IDENTITY_TOKEN_FILE
+func awsWebIdentityCredentials() (AWSCredentials, bool, error) {
+	file, role := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN")
+	if file == "" || role == "" {
+		return AWSCredentials{}, false, nil
+	}
+	creds, err := assumeAWSRoleWithWebIdentity(file, role, os.Getenv("AWS_ROLE_SESSION_NAME"))
+	if err != nil {
+		return AWSCredentials{}, false, fmt.Errorf("unable to assume %s with a web identity: %s", role, err)
+	}
+	return creds, true, nil
+}
+
+// assumeAWSRoleWithWebIdentity will exchange the token in a file for the credentials of a role, which needs no
+// credentials of its own
+func assumeAWSRoleWithWebIdentity(file, role, sessionName string) (AWSCredentials, error) {
+	token, err := ioutil.ReadFile(file)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	if sessionName == "" {
+		sessionName = codeCommitRoleSession
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {STSAPIVersion},
+		"RoleArn":          {role},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequest(http.MethodPost, AWSSTSURL, strings.NewReader(query.Encode()))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := (&http.Client{Timeout: VerifyTimeout}).Do(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+		Message string `xml:"Error>Message"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return AWSCredentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, fmt.Errorf("sts returned %s %s", resp.Status, result.Message)
+	}
+	return AWSCredentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+	}, nil
+}
+
+// awsContainerCredentials will get the credentials of the task role of an ECS task, or of an EKS pod identity
+func awsContainerCredentials() (AWSCredentials, bool, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = AWSContainerURL + relative
+	}
+	if endpoint == "" {
+		return AWSCredentials{}, false, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return AWSCredentials{}, false, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return AWSCredentials{}, false, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	var creds awsMetadataCredentials
+	if err := awsCredentialsRequest(&http.Client{Timeout: VerifyTimeout}, req, &creds); err != nil {
+		return AWSCredentials{}, false, fmt.Errorf("unable to get the container credentials: %s", err)
+	}
+	return creds.credentials(), true, nil
+}
+
+// awsInstanceCredentials will get the credentials of the role of an EC2 instance with IMDSv2. Not being on an instance
+// is not an error, only finding the instance without a role or being unable to read its credentials is.
+func awsInstanceCredentials() (AWSCredentials, bool, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return AWSCredentials{}, false, nil
+	}
+	endpoint := strings.TrimSuffix(AWSInstanceURL, "/")
+	if e := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"); e != "" {
+		endpoint = strings.TrimSuffix(e, "/")
+	}
+	client := &http.Client{Timeout: awsMetadataTimeout}
+
+	req, err := http.NewRequest(http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return AWSCredentials{}, false, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "300")
+	resp, err := client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, false, nil
+	}
+	token, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, false, nil
+	}
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, endpoint+"/latest/meta-data/iam/security-credentials/"+path, nil)
+		if err == nil {
+			req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		}
+		return req, err
+	}
+	req, err = get("")
+	if err != nil {
+		return AWSCredentials{}, false, err
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, false, fmt.Errorf("unable to get the role of the instance: %s", err)
+	}
+	roles, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if err != nil || resp.StatusCode != http.StatusOK || role == "" {
+		return AWSCredentials{}, false, errors.New("the instance has no role to get credentials from")
+	}
+
+	req, err = get(role)
+	if err != nil {
+		return AWSCredentials{}, false, err
+	}
+	var creds awsMetadataCredentials
+	if err := awsCredentialsRequest(client, req, &creds); err != nil {
+		return AWSCredentials{}, false, fmt.Errorf("unable to get the credentials of the instance role %s: %s", role, err)
+	}
+	return creds.credentials(), true, nil
+}
+
+// awsMetadataCredentials are the credentials returned by the container and instance metadata endpoints
+type awsMetadataCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+func (c awsMetadataCredentials) credentials() AWSCredentials {
+	return AWSCredentials{AccessKeyID: c.AccessKeyID, SecretAccessKey: c.SecretAccessKey, SessionToken: c.Token}
+}
+
+// awsCredentialsRequest will make a request for credentials and decode its json response into out
+func awsCredentialsRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
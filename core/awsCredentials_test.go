@@ -0,0 +1,201 @@
+package core_test
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+// awsEnv will set the environment variables credentials are looked for in, with nothing found outside of dir unless
+// vars sets it, and return a func that restores them
+func awsEnv(dir string, vars map[string]string) func() {
+	names := []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE",
+		"AWS_SHARED_CREDENTIALS_FILE", "AWS_CONFIG_FILE", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN",
+		"AWS_EC2_METADATA_DISABLED", "AWS_EC2_METADATA_SERVICE_ENDPOINT"}
+	saved := map[string]string{}
+	for _, name := range names {
+		if v, ok := os.LookupEnv(name); ok {
+			saved[name] = v
+		}
+		os.Unsetenv(name)
+	}
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	os.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for k, v := range vars {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for _, name := range names {
+			os.Unsetenv(name)
+			if v, ok := saved[name]; ok {
+				os.Setenv(name, v)
+			}
+		}
+	}
+}
+
+func TestAWSCredentials(t *testing.T) {
+	Convey("Given no aws credentials anywhere", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-aws")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		defer awsEnv(dir, nil)()
+
+		Convey("Loading them should fail rather than leave requests unsigned", func() {
+			_, err := core.LoadAWSCredentials("")
+			So(err, ShouldNotBeNil)
+			So(core.IsNoAWSCredentials(err), ShouldBeTrue)
+		})
+
+		Convey("A profile that was asked for should fail on its own", func() {
+			_, err := core.LoadAWSCredentials("audit")
+			So(err, ShouldNotBeNil)
+			So(core.IsNoAWSCredentials(err), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given profiles in the config file", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-aws")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		defer awsEnv(dir, nil)()
+
+		ioutil.WriteFile(filepath.Join(dir, "config"), []byte("[default]\nregion = eu-west-1\n\n"+
+			"[profile process]\ncredential_process = echo '{\"Version\": 1, \"AccessKeyId\": \"ASIAPROCESS\", \"SecretAccessKey\": \"s3cret\", \"SessionToken\": \"t0k3n\"}'\n\n"+
+			"[profile failing]\ncredential_process = exit 3\n\n"+
+			"[profile chained]\nrole_arn = arn:aws:iam::123456789012:role/wraith\nsource_profile = default\n\n"+
+			"[profile sso]\nsso_session = acme\nsso_account_id = 123456789012\nsso_role_name = audit\n\n"+
+			"[sso-session acme]\nsso_start_url = https://acme.awsapps.com/start\nsso_region = eu-west-1\n"), 0600)
+		ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte("[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = s3cret\n"), 0600)
+
+		Convey("Keys in the credentials file should be used for a profile of the config file", func() {
+			creds, err := core.LoadAWSCredentials("")
+			So(err, ShouldBeNil)
+			So(creds.AccessKeyID, ShouldEqual, "AKIDDEFAULT")
+		})
+
+		Convey("A credential_process should be run for its credentials", func() {
+			creds, err := core.LoadAWSCredentials("process")
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, core.AWSCredentials{AccessKeyID: "ASIAPROCESS", SecretAccessKey: "s3cret", SessionToken: "t0k3n"})
+
+			_, err = core.LoadAWSCredentials("failing")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("A role assumed from other credentials should fail loudly", func() {
+			_, err := core.LoadAWSCredentials("chained")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "export-credentials")
+		})
+
+		Convey("An sso profile without a cached login should say to log in", func() {
+			home := os.Getenv("HOME")
+			os.Setenv("HOME", dir)
+			defer os.Setenv("HOME", home)
+			_, err := core.LoadAWSCredentials("sso")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "aws sso login")
+		})
+	})
+
+	Convey("Given a web identity token for a role", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-aws")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		token := filepath.Join(dir, "token")
+		ioutil.WriteFile(token, []byte("eyJ.web.identity\n"), 0600)
+		defer awsEnv(dir, map[string]string{"AWS_WEB_IDENTITY_TOKEN_FILE": token, "AWS_ROLE_ARN": "arn:aws:iam::123456789012:role/wraith"})()
+
+		var form map[string][]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			if r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials><AccessKeyId>ASIAWEB</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>web-token</SessionToken></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`)
+		}))
+		defer server.Close()
+		url := core.AWSSTSURL
+		core.AWSSTSURL = server.URL
+		defer func() { core.AWSSTSURL = url }()
+
+		Convey("The role should be assumed with the token, without signing the request", func() {
+			creds, err := core.LoadAWSCredentials("")
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, core.AWSCredentials{AccessKeyID: "ASIAWEB", SecretAccessKey: "secret", SessionToken: "web-token"})
+			So(form["Action"], ShouldResemble, []string{"AssumeRoleWithWebIdentity"})
+			So(form["RoleArn"], ShouldResemble, []string{"arn:aws:iam::123456789012:role/wraith"})
+			So(form["WebIdentityToken"], ShouldResemble, []string{"eyJ.web.identity"})
+		})
+	})
+
+	Convey("Given a container with credentials", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-aws")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v2/credentials/task" || r.Header.Get("Authorization") != "secret-auth" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"AccessKeyId": "ASIATASK", "SecretAccessKey": "secret", "Token": "task-token", "Expiration": "2030-01-01T00:00:00Z"}`)
+		}))
+		defer server.Close()
+		defer awsEnv(dir, map[string]string{"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL + "/v2/credentials/task",
+			"AWS_CONTAINER_AUTHORIZATION_TOKEN": "secret-auth"})()
+
+		Convey("The credentials of the task role should be used", func() {
+			creds, err := core.LoadAWSCredentials("")
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, core.AWSCredentials{AccessKeyID: "ASIATASK", SecretAccessKey: "secret", SessionToken: "task-token"})
+		})
+
+		Convey("A container endpoint that refuses should be an error", func() {
+			os.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "wrong")
+			_, err := core.LoadAWSCredentials("")
+			So(err, ShouldNotBeNil)
+			So(core.IsNoAWSCredentials(err), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an instance with a role", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-aws")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+				fmt.Fprint(w, "imds-token")
+			case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-token":
+				w.WriteHeader(http.StatusUnauthorized)
+			case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+				fmt.Fprint(w, "scanner\n")
+			case r.URL.Path == "/latest/meta-data/iam/security-credentials/scanner":
+				fmt.Fprint(w, `{"Code": "Success", "AccessKeyId": "ASIAINSTANCE", "SecretAccessKey": "secret", "Token": "instance-token"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		defer awsEnv(dir, map[string]string{"AWS_EC2_METADATA_DISABLED": "", "AWS_EC2_METADATA_SERVICE_ENDPOINT": server.URL})()
+
+		Convey("The credentials of the instance role should be read with an IMDSv2 token", func() {
+			creds, err := core.LoadAWSCredentials("")
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, core.AWSCredentials{AccessKeyID: "ASIAINSTANCE", SecretAccessKey: "secret", SessionToken: "instance-token"})
+		})
+	})
+}
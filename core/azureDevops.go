@@ -0,0 +1,325 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// AzureDevopsCloudURL is the location of azure devops services, an on-prem azure devops server is reached at the url
+// of its collections instead, such as https://tfs.example.com/tfs
+const AzureDevopsCloudURL = "https://dev.azure.com"
+
+// AzureDevopsAPIVersion is the version of the api that is used, it is the newest one azure devops server 2019 supports
+const AzureDevopsAPIVersion = "5.0"
+
+// AzureDevopsPATUser is the username sent with a personal access token, azure devops ignores it but git needs one
+const AzureDevopsPATUser = "wraith"
+
+// CloneAzureDevopsRepository will create either an in memory clone of a given repository or clone to a temp dir.
+func CloneAzureDevopsRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
+
+// azureDevopsClient holds the personal access token used to talk to the api of azure devops services or server
+type azureDevopsClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	logger  *Logger
+}
+
+// NewClient creates an azure devops api client instance using a personal access token
+func (c azureDevopsClient) NewClient(baseURL, token string, logger *Logger) azureDevopsClient {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	if c.baseURL == "" {
+		c.baseURL = AzureDevopsCloudURL
+	}
+	c.token = token
+	c.client = NewHTTPClient(30 * time.Second)
+	c.logger = logger
+	return c
+}
+
+// NewAzureDevopsClient creates an azure devops api client for the organizations or collections at baseURL
+func NewAzureDevopsClient(baseURL, token string, logger *Logger) IClient {
+	return azureDevopsClient.NewClient(azureDevopsClient{}, baseURL, token, logger)
+}
+
+// CheckAzureDevopsCredentials will ensure we have a token and an organization to scan before talking to azure devops
+func CheckAzureDevopsCredentials(sess *Session) error {
+	if !ValidAzureDevopsToken(sess.AzureDevopsToken) {
+		return errors.New("Azure DevOps requires a personal access token with the Code (Read) scope")
+	}
+	if len(sess.AzureDevopsTargets) == 0 {
+		return errors.New("Azure DevOps requires at least one organization, or organization/project, to scan")
+	}
+	return nil
+}
+
+// ValidAzureDevopsToken will check that a personal access token was given
+func ValidAzureDevopsToken(token string) bool {
+	return strings.TrimSpace(token) != ""
+}
+
+// get will request a path below the base url and decode the json response. It returns the continuation token azure
+// devops sends when there is another page.
+func (c azureDevopsClient) get(path string, query url.Values, out interface{}) (string, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", AzureDevopsAPIVersion)
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.SetBasicAuth(AzureDevopsPATUser, c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	// a token that is not accepted is sent to the sign in page rather than refused
+	if resp.StatusCode == http.StatusNonAuthoritativeInfo ||
+		(resp.StatusCode == http.StatusOK && !strings.Contains(resp.Header.Get("Content-Type"), "application/json")) {
+		return "", errors.New("azure devops did not return json, check the personal access token and azure-devops-url")
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return "", fmt.Errorf("azure devops returned %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return "", fmt.Errorf("azure devops returned %d", resp.StatusCode)
+	}
+	return resp.Header.Get("X-MS-ContinuationToken"), json.Unmarshal(data, out)
+}
+
+// azureDevopsProject is a project as returned by the api
+type azureDevopsProject struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+// azureDevopsRepository is a git repository as returned by the api
+type azureDevopsRepository struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	DefaultBranch string             `json:"defaultBranch"`
+	RemoteURL     string             `json:"remoteUrl"`
+	SSHURL        string             `json:"sshUrl"`
+	WebURL        string             `json:"webUrl"`
+	IsDisabled    bool               `json:"isDisabled"`
+	IsFork        bool               `json:"isFork"`
+	Size          int64              `json:"size"`
+	Project       azureDevopsProject `json:"project"`
+}
+
+// splitAzureDevopsTarget will split a target into its organization and, when it is limited to one, its project
+func splitAzureDevopsTarget(login string) (string, string) {
+	parts := strings.SplitN(login, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
+
+// listProjects will return every project in an organization, walking each page of the list
+func (c azureDevopsClient) listProjects(org string) ([]azureDevopsProject, error) {
+	var projects []azureDevopsProject
+	continuation := ""
+	for {
+		query := url.Values{"$top": {"100"}}
+		if continuation != "" {
+			query.Set("continuationToken", continuation)
+		}
+		var page struct {
+			Value []azureDevopsProject `json:"value"`
+		}
+		next, err := c.get("/"+url.PathEscape(org)+"/_apis/projects", query, &page)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, page.Value...)
+		if next == "" || len(page.Value) == 0 {
+			return projects, nil
+		}
+		continuation = next
+	}
+}
+
+// GetUserOrganization will look up an organization, or a project within an organization when the target is
+// organization/project. On azure devops server the organization is a collection.
+func (c azureDevopsClient) GetUserOrganization(login string) (*Owner, error) {
+	org, project := splitAzureDevopsTarget(login)
+	if org == "" {
+		return nil, errors.New("an organization is required")
+	}
+
+	name := org
+	webURL := c.baseURL + "/" + url.PathEscape(org)
+	if project != "" {
+		var p azureDevopsProject
+		if _, err := c.get(fmt.Sprintf("/%s/_apis/projects/%s", url.PathEscape(org), url.PathEscape(project)), nil, &p); err != nil {
+			return nil, err
+		}
+		name = org + "/" + p.Name
+		webURL += "/" + url.PathEscape(p.Name)
+	} else {
+		// there is no api for an organization itself, listing a project checks it exists and can be read
+		var page struct {
+			Value []azureDevopsProject `json:"value"`
+		}
+		if _, err := c.get("/"+url.PathEscape(org)+"/_apis/projects", url.Values{"$top": {"1"}}, &page); err != nil {
+			return nil, err
+		}
+	}
+
+	id := stringID(c.baseURL + "/" + strings.ToLower(login))
+	emptyString := ""
+	return &Owner{
+		Login:     &login,
+		ID:        &id,
+		Type:      stringPointer(TargetTypeOrganization),
+		Name:      &name,
+		AvatarURL: &emptyString,
+		URL:       &webURL,
+		Company:   &emptyString,
+		Blog:      &emptyString,
+		Location:  &emptyString,
+		Email:     &emptyString,
+		Bio:       &emptyString,
+	}, nil
+}
+
+// GetRepositoriesFromOwner will gather every git repository in each project of an organization, or in one project
+func (c azureDevopsClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	var allRepos []*Repository
+	org, project := splitAzureDevopsTarget(*target.Login)
+
+	projects := []string{project}
+	if project == "" {
+		all, err := c.listProjects(org)
+		if err != nil {
+			return nil, err
+		}
+		projects = nil
+		for _, p := range all {
+			projects = append(projects, p.Name)
+		}
+	}
+
+	for _, p := range projects {
+		var list struct {
+			Value []azureDevopsRepository `json:"value"`
+		}
+		path := fmt.Sprintf("/%s/%s/_apis/git/repositories", url.PathEscape(org), url.PathEscape(p))
+		if _, err := c.get(path, nil, &list); err != nil {
+			// a project the token cannot read should not stop the rest of the organization from being scanned
+			if project == "" {
+				c.logger.Error(" Error retrieving repositories of %s/%s: %s\n", org, p, err)
+				continue
+			}
+			return nil, err
+		}
+		for _, repo := range list.Value {
+			// don't capture forks
+			if repo.IsFork {
+				continue
+			}
+			// a disabled repository cannot be cloned and one with no commits has no default branch to scan
+			if repo.IsDisabled || repo.DefaultBranch == "" {
+				c.logger.Debug(" Skipping %s/%s/%s as it is disabled or empty\n", org, p, repo.Name)
+				continue
+			}
+			allRepos = append(allRepos, newAzureDevopsRepository(org, repo))
+		}
+	}
+	return allRepos, nil
+}
+
+// newAzureDevopsRepository will convert a repository from the api into the repository wraith scans
+func newAzureDevopsRepository(org string, repo azureDevopsRepository) *Repository {
+	cloneURL := repo.RemoteURL
+	// the remote url carries the name of the organization as a user, the token is given separately
+	if u, err := url.Parse(repo.RemoteURL); err == nil {
+		u.User = nil
+		cloneURL = u.String()
+	}
+
+	owner := org + "/" + repo.Project.Name
+	id := stringID(repo.ID)
+	visibility := VisibilityPrivate
+	if strings.EqualFold(repo.Project.Visibility, VisibilityPublic) {
+		visibility = VisibilityPublic
+	}
+	fork := false
+	return &Repository{
+		Owner:         &owner,
+		ID:            &id,
+		Name:          stringPointer(repo.Name),
+		FullName:      stringPointer(owner + "/" + repo.Name),
+		CloneURL:      &cloneURL,
+		URL:           stringPointer(repo.WebURL),
+		DefaultBranch: stringPointer(strings.TrimPrefix(repo.DefaultBranch, "refs/heads/")),
+		Description:   stringPointer(repo.Project.Description),
+		Homepage:      stringPointer(""),
+		Visibility:    &visibility,
+		Fork:          &fork,
+		Topics:        []string{repo.Project.Name},
+		Size:          &repo.Size,
+		webURL:        repo.WebURL,
+		sshURL:        repo.SSHURL,
+	}
+}
+
+// GetOrganizationMembers returns no members. Repositories in azure devops belong to projects rather than to their
+// members, so there is nothing more to gather from them.
+func (c azureDevopsClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
+	return nil, nil
+}
+
+// setAzureDevopsUrls will set the links of a finding from the web url of its repository. Azure devops server can be
+// hosted anywhere, so unlike the other providers the links cannot be built from the owner of the repository alone.
+func (f *Finding) setAzureDevopsUrls(webURL string) {
+	f.RepositoryUrl = webURL
+	f.FileUrl = fmt.Sprintf("%s?path=%s&version=GC%s", webURL, url.QueryEscape("/"+f.FilePath), f.CommitHash)
+	f.CommitUrl = fmt.Sprintf("%s/commit/%s", webURL, f.CommitHash)
+}
+
+// azureDevopsFileURL returns the api url of a file at a commit. The owner of an azure devops repository is its
+// organization and project, so the file route of the web interface splits it over the owner and repo parameters.
+func azureDevopsFileURL(baseURL, route string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(route, "/"), "/", 5)
+	if len(parts) != 5 {
+		return "", fmt.Errorf("%s is not an organization/project/repository/commit/path", route)
+	}
+	query := url.Values{
+		"path":                          {"/" + parts[4]},
+		"versionDescriptor.version":     {parts[3]},
+		"versionDescriptor.versionType": {"commit"},
+		"$format":                       {"octetStream"},
+		"api-version":                   {AzureDevopsAPIVersion},
+	}
+	return fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/items?%s", strings.TrimSuffix(baseURL, "/"),
+		url.PathEscape(parts[0]), url.PathEscape(parts[1]), url.PathEscape(parts[2]), query.Encode()), nil
+}
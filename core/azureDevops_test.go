@@ -0,0 +1,146 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+// fakeAzureDevopsAPI serves a collection of an azure devops server with its projects split over two pages
+type fakeAzureDevopsAPI struct {
+	url string
+}
+
+func (f *fakeAzureDevopsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pass, ok := r.BasicAuth(); !ok || pass != "pat" {
+		// azure devops sends a token it does not accept to the sign in page
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		return
+	}
+	if r.URL.Query().Get("api-version") != core.AzureDevopsAPIVersion {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	repo := func(name, project string, extra map[string]interface{}) map[string]interface{} {
+		r := map[string]interface{}{
+			"id":            name + "-id",
+			"name":          name,
+			"defaultBranch": "refs/heads/main",
+			"remoteUrl":     "https://acme@tfs.example.com/tfs/acme/" + project + "/_git/" + name,
+			"webUrl":        "https://tfs.example.com/tfs/acme/" + project + "/_git/" + name,
+			"project":       map[string]string{"name": project, "visibility": "private", "description": project + " services"},
+		}
+		for k, v := range extra {
+			r[k] = v
+		}
+		return r
+	}
+
+	switch r.URL.Path {
+	case "/tfs/acme/_apis/projects":
+		if r.URL.Query().Get("continuationToken") == "next" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": []map[string]string{{"name": "Secret"}}})
+			return
+		}
+		if r.URL.Query().Get("$top") == "100" {
+			w.Header().Set("X-MS-ContinuationToken", "next")
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": []map[string]string{{"name": "Platform"}}})
+	case "/tfs/acme/_apis/projects/Platform":
+		json.NewEncoder(w).Encode(map[string]string{"name": "Platform"})
+	case "/tfs/acme/Platform/_apis/git/repositories":
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": []interface{}{
+			repo("api", "Platform", nil),
+			repo("api-fork", "Platform", map[string]interface{}{"isFork": true}),
+			repo("old", "Platform", map[string]interface{}{"isDisabled": true}),
+			repo("empty", "Platform", map[string]interface{}{"defaultBranch": ""}),
+			repo("site", "Platform", map[string]interface{}{"project": map[string]string{"name": "Platform", "visibility": "public"}}),
+		}})
+	case "/tfs/acme/Secret/_apis/git/repositories":
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "TF401019: no access"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+	}
+}
+
+func TestAzureDevopsClient(t *testing.T) {
+
+	Convey("Given the api of an azure devops server", t, func() {
+		api := &fakeAzureDevopsAPI{}
+		server := httptest.NewServer(api)
+		defer server.Close()
+		api.url = server.URL
+
+		client := core.NewAzureDevopsClient(server.URL+"/tfs/", "pat", &core.Logger{})
+
+		Convey("An organization should be an organization without members", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "acme")
+			So(*owner.URL, ShouldEqual, server.URL+"/tfs/acme")
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+
+			members, err := client.GetOrganizationMembers(*owner)
+			So(err, ShouldBeNil)
+			So(members, ShouldBeEmpty)
+		})
+
+		Convey("Every project should be gathered, skipping forks, disabled, empty and unreadable repositories", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 2)
+
+			So(*repos[0].Owner, ShouldEqual, "acme/Platform")
+			So(*repos[0].Name, ShouldEqual, "api")
+			So(*repos[0].FullName, ShouldEqual, "acme/Platform/api")
+			So(*repos[0].CloneURL, ShouldEqual, "https://tfs.example.com/tfs/acme/Platform/_git/api")
+			So(*repos[0].URL, ShouldEqual, "https://tfs.example.com/tfs/acme/Platform/_git/api")
+			So(*repos[0].DefaultBranch, ShouldEqual, "main")
+			So(*repos[0].Visibility, ShouldEqual, core.VisibilityPrivate)
+			So(repos[0].Topics, ShouldResemble, []string{"Platform"})
+
+			So(*repos[1].Name, ShouldEqual, "site")
+			So(*repos[1].Visibility, ShouldEqual, core.VisibilityPublic)
+			So(*repos[1].ID, ShouldNotEqual, *repos[0].ID)
+		})
+
+		Convey("A project should limit the repositories to those in it", func() {
+			owner, err := client.GetUserOrganization("acme/Platform")
+			So(err, ShouldBeNil)
+			So(*owner.Name, ShouldEqual, "acme/Platform")
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 2)
+		})
+
+		Convey("Errors from the api should be returned", func() {
+			_, err := client.GetUserOrganization("acme/Missing")
+			So(err.Error(), ShouldEqual, "azure devops returned 404: not found")
+
+			owner, _ := client.GetUserOrganization("acme/Secret")
+			So(owner, ShouldBeNil)
+
+			bad := core.NewAzureDevopsClient(server.URL+"/tfs", "wrong", &core.Logger{})
+			_, err = bad.GetUserOrganization("acme")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "did not return json")
+		})
+	})
+
+	Convey("An azure devops token should not be empty", t, func() {
+		So(core.ValidAzureDevopsToken("pat"), ShouldBeTrue)
+		So(core.ValidAzureDevopsToken(" "), ShouldBeFalse)
+	})
+}
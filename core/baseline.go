@@ -0,0 +1,115 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// Baseline is a set of findings that have already been triaged. Findings in it are left out of the output and the
+// stats so a team can adopt wraith on an old repository and only be told about new secrets.
+type Baseline struct {
+	fingerprints map[string]bool
+
+	// secretIDs counts the secret ids in the file. They are not matched, as every secret in the same file of a commit
+	// has the same one, but the file they are in has to be rebuilt.
+	secretIDs int
+}
+
+// LoadBaseline will read the fingerprints of triaged findings from a file. The file can be the json report of an
+// earlier scan, the json lines output of one, or a plain list with one fingerprint per line where lines starting with
+// # are comments.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Baseline{fingerprints: map[string]bool{}}
+
+	var report map[string]json.RawMessage
+	if json.Unmarshal(data, &report) == nil && report["findings"] != nil {
+		var findings []JSONFinding
+		if err := json.Unmarshal(report["findings"], &findings); err != nil {
+			return nil, err
+		}
+		for _, f := range findings {
+			b.addFinding(f)
+		}
+		return b, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "{"):
+			var f JSONFinding
+			if err := json.Unmarshal([]byte(line), &f); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+			b.addFinding(f)
+		case secretIDPattern.MatchString(line):
+			b.secretIDs++
+		default:
+			b.add(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// secretIDPattern matches a secret id, which is a sha1 where a fingerprint is a sha256
+var secretIDPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// addFinding will put the fingerprint of a finding from a report in the baseline
+func (b *Baseline) addFinding(f JSONFinding) {
+	if f.Fingerprint == "" && f.SecretID != "" {
+		b.secretIDs++
+	}
+	b.add(f.Fingerprint)
+}
+
+// add will put a fingerprint in the baseline
+func (b *Baseline) add(fingerprint string) {
+	if fingerprint != "" {
+		b.fingerprints[fingerprint] = true
+	}
+}
+
+// Len returns the number of fingerprints in the baseline
+func (b *Baseline) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.fingerprints)
+}
+
+// SecretIDs returns the number of secret ids in the baseline file, which are no longer matched
+func (b *Baseline) SecretIDs() int {
+	if b == nil {
+		return 0
+	}
+	return b.secretIDs
+}
+
+// Contains will check if a finding has already been triaged, a nil baseline contains nothing
+func (b *Baseline) Contains(f *Finding) bool {
+	return b.containsFingerprint(f.Fingerprint)
+}
+
+// containsFingerprint will check if the finding with the given fingerprint has already been triaged
+func (b *Baseline) containsFingerprint(fingerprint string) bool {
+	if b == nil {
+		return false
+	}
+	return b.fingerprints[fingerprint]
+}
@@ -0,0 +1,86 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+func TestBaseline(t *testing.T) {
+
+	Convey("Given a baseline file", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-baseline")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		load := func(content string) (*core.Baseline, error) {
+			path := filepath.Join(dir, "baseline")
+			So(ioutil.WriteFile(path, []byte(content), 0600), ShouldBeNil)
+			return core.LoadBaseline(path)
+		}
+		known := &core.Finding{Fingerprint: "aaa", SecretID: "89e6c98d92887913cadf06b2adb97f26cde4849b"}
+		unknown := &core.Finding{Fingerprint: "ccc", SecretID: "89e6c98d92887913cadf06b2adb97f26cde4849b"}
+
+		Convey("A json report should baseline each of its findings", func() {
+			b, err := load(`{
+  "schema_version": "1.4.0",
+  "findings": [
+    {"fingerprint": "aaa", "secret_id": "89e6c98d92887913cadf06b2adb97f26cde4849b", "description": "AWS key"},
+    {"fingerprint": "bbb", "secret_id": "9c1185a5c5e9fc54612808977ee8f548b2258d31", "description": "Password"}
+  ]
+}`)
+			So(err, ShouldBeNil)
+			So(b.Len(), ShouldEqual, 2)
+			So(b.Contains(known), ShouldBeTrue)
+			So(b.Contains(unknown), ShouldBeFalse)
+			So(b.SecretIDs(), ShouldEqual, 0)
+		})
+
+		Convey("A report without findings should be an empty baseline", func() {
+			b, err := load(`{"findings": []}`)
+			So(err, ShouldBeNil)
+			So(b.Len(), ShouldEqual, 0)
+		})
+
+		Convey("Json lines should baseline each line", func() {
+			b, err := load("{\"fingerprint\": \"aaa\"}\n{\"fingerprint\": \"bbb\"}\n")
+			So(err, ShouldBeNil)
+			So(b.Len(), ShouldEqual, 2)
+			So(b.Contains(known), ShouldBeTrue)
+		})
+
+		Convey("A list of fingerprints should skip comments and blank lines", func() {
+			b, err := load("# triaged 2020-08-01\naaa\n\n  bbb  \n")
+			So(err, ShouldBeNil)
+			So(b.Len(), ShouldEqual, 2)
+			So(b.Contains(known), ShouldBeTrue)
+		})
+
+		Convey("Secret ids should be counted but not matched, as every secret in a file of a commit shares one", func() {
+			b, err := load("89e6c98d92887913cadf06b2adb97f26cde4849b\n{\"secret_id\": \"9c1185a5c5e9fc54612808977ee8f548b2258d31\"}\n")
+			So(err, ShouldBeNil)
+			So(b.Len(), ShouldEqual, 0)
+			So(b.SecretIDs(), ShouldEqual, 2)
+			So(b.Contains(known), ShouldBeFalse)
+		})
+
+		Convey("A malformed json line should be an error", func() {
+			_, err := load("aaa\n{\"secret_id\": \n")
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("A missing baseline file should be an error", t, func() {
+		_, err := core.LoadBaseline(filepath.Join(os.TempDir(), "wraith-no-such-baseline"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("A nil baseline should contain nothing", t, func() {
+		var b *core.Baseline
+		So(b.Contains(&core.Finding{SecretID: "aaa"}), ShouldBeFalse)
+		So(b.Len(), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"wraith/version"
+)
+
+// EncodingUTF16 is the encoding of a secret found in a string of a binary file that was written as utf-16, the way
+// windows programs store their strings
+const EncodingUTF16 = "utf-16le"
+
+// BinaryOffsetLabel is the label with the offset in a binary file of a secret found in its strings
+const BinaryOffsetLabel = "binary_offset"
+
+// binarySniffLength is how much of the start of a file is looked at for a zero byte to tell that it is binary, as git
+// does
+const binarySniffLength = 8000
+
+// binaryString is a run of printable characters in a binary file
+type binaryString struct {
+	text     string
+	offset   int
+	encoding string
+}
+
+// isBinaryContent will check whether content is binary rather than text, by looking for a zero byte near its start
+func isBinaryContent(content string) bool {
+	if len(content) > binarySniffLength {
+		content = content[:binarySniffLength]
+	}
+	return strings.IndexByte(content, 0) >= 0
+}
+
+// isPrintableByte will check whether a byte is a printable ascii character or a tab, which is what strings looks for
+func isPrintableByte(b byte) bool {
+	return b == '\t' || (b >= 0x20 && b < 0x7f)
+}
+
+// extractStrings will find the runs of at least minLength printable characters in binary data, the way strings does.
+// Runs of utf-16 characters, each printable ascii byte followed by a zero byte, are found as well.
+func extractStrings(data []byte, minLength int) []binaryString {
+	var found []binaryString
+
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && isPrintableByte(data[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLength {
+			found = append(found, binaryString{text: string(data[start:i]), offset: start})
+		}
+		start = -1
+	}
+
+	// a utf-16 string can start on an odd byte as well as an even one
+	for align := 0; align < 2; align++ {
+		var run []byte
+		start := -1
+		for i := align; i <= len(data); i += 2 {
+			if i+1 < len(data) && isPrintableByte(data[i]) && data[i+1] == 0 {
+				if start < 0 {
+					start = i
+				}
+				run = append(run, data[i])
+				continue
+			}
+			if start >= 0 && len(run) >= minLength {
+				found = append(found, binaryString{text: string(run), offset: start, encoding: EncodingUTF16})
+			}
+			run, start = nil, -1
+		}
+	}
+	return found
+}
+
+// scanBinaryStrings will match the content signatures against the printable strings in a binary file, so a secret
+// compiled into a program or stored in a database file is found without matching the raw bytes around it. A secret
+// that was in the strings of the file before the change, in previous, is left out. The findings are copied from base,
+// have no line and are labeled with the offset of the secret in the file. They are not added to the session, this is left to
+// the caller so they can be handled like any other match.
+func scanBinaryStrings(sess *Session, content, previous string, base Finding) []*Finding {
+	strs := extractStrings([]byte(content), sess.BinaryMinLength)
+	if len(strs) == 0 {
+		return nil
+	}
+	var before []string
+	for _, s := range extractStrings([]byte(previous), sess.BinaryMinLength) {
+		before = append(before, s.text)
+	}
+	previousText := strings.Join(before, "\n")
+
+	// the strings are written out one to a line so the signatures match them the same way they match a file
+	var text bytes.Buffer
+	for _, s := range strs {
+		text.WriteString(s.text)
+		text.WriteByte('\n')
+	}
+	dir, err := ioutil.TempDir("", "wraith")
+	if err != nil {
+		sess.Out.Error("Unable to extract the strings of %s: %s\n", base.FilePath, err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+	fullFilePath := filepath.Join(dir, "STRINGS")
+	if err := ioutil.WriteFile(fullFilePath, text.Bytes(), 0600); err != nil {
+		sess.Out.Error("Unable to extract the strings of %s: %s\n", base.FilePath, err)
+		return nil
+	}
+	matchFile := newMatchFile(fullFilePath)
+
+	var findings []*Finding
+	for _, signature := range CurrentSignatures() {
+		if signature.Part() != PartContent {
+			continue
+		}
+		bMatched, matchMap := signature.ExtractMatch(matchFile, sess, nil)
+		if !bMatched {
+			continue
+		}
+
+		for k, line := range matchMap {
+			parts := strings.SplitAfterN(k, "_", 2)
+			if len(parts) != 2 || line < 1 || line > len(strs) {
+				continue
+			}
+			secret := parts[1]
+			if previous != "" && strings.Contains(previousText, secret) {
+				continue
+			}
+			str := strs[line-1]
+
+			finding := base
+			finding.Comment = Redact(secret, sess.Redact)
+			finding.Description = signature.Description()
+			finding.Encoding = str.encoding
+			finding.LineNumber = "0"
+			finding.Score = signature.MatchLevel()
+			finding.Severity = signature.Severity()
+			finding.Confidence = signature.Confidence()
+			finding.Signatureid = signature.Signatureid()
+			finding.SignaturesVersion = sess.SignatureVersion
+			finding.WraithVersion = version.AppVersion()
+			finding.secret = secret
+			finding.Labels = map[string]string{}
+			for k, v := range base.Labels {
+				finding.Labels[k] = v
+			}
+			finding.Labels[BinaryOffsetLabel] = fmt.Sprintf("0x%x", str.offset+strings.Index(str.text, secret)*binaryCharWidth(str.encoding))
+
+			finding.Initialize(sess.ScanType)
+			findings = append(findings, &finding)
+		}
+	}
+	return findings
+}
+
+// binaryCharWidth is how many bytes each character of a string takes in the file
+func binaryCharWidth(encoding string) int {
+	if encoding == EncodingUTF16 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,358 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// These are the locations of bitbucket cloud and its api
+const (
+	BitbucketBaseURL = "https://bitbucket.org"
+	BitbucketAPIURL  = "https://api.bitbucket.org/2.0"
+)
+
+// BitbucketOAuthUser is the username bitbucket expects when a repository is cloned with an OAuth access token
+const BitbucketOAuthUser = "x-token-auth"
+
+// CloneBitbucketRepository will create either an in memory clone of a given repository or clone to a temp dir.
+func CloneBitbucketRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
+
+// bitbucketClient holds the credentials used to talk to the bitbucket cloud api. Either a username and app password
+// or an OAuth access token is used.
+type bitbucketClient struct {
+	apiURL      string
+	username    string
+	appPassword string
+	token       string
+	client      *http.Client
+	logger      *Logger
+}
+
+// NewClient creates a bitbucket api client instance using an app password or an OAuth access token
+func (c bitbucketClient) NewClient(username, appPassword, token string, logger *Logger) bitbucketClient {
+	return newBitbucketClient(BitbucketAPIURL, username, appPassword, token, logger)
+}
+
+// NewBitbucketClient creates a bitbucket api client that talks to the api at apiURL rather than bitbucket cloud
+func NewBitbucketClient(apiURL, username, appPassword, token string, logger *Logger) IClient {
+	return newBitbucketClient(apiURL, username, appPassword, token, logger)
+}
+
+func newBitbucketClient(apiURL, username, appPassword, token string, logger *Logger) bitbucketClient {
+	c := bitbucketClient{}
+	c.apiURL = strings.TrimSuffix(apiURL, "/")
+	c.username = username
+	c.appPassword = appPassword
+	c.token = token
+	c.client = NewHTTPClient(30 * time.Second)
+	c.logger = logger
+	return c
+}
+
+// CheckBitbucketCredentials will ensure we have either an app password or an OAuth token to talk to bitbucket with
+func CheckBitbucketCredentials(sess *Session) error {
+	if !ValidBitbucketCredentials(sess.BitbucketUsername, sess.BitbucketAppPassword, sess.BitbucketOAuthToken) {
+		return errors.New("Bitbucket requires a username and app password, or an OAuth access token")
+	}
+	return nil
+}
+
+// ValidBitbucketCredentials will check that either a username and app password or an OAuth access token was given
+func ValidBitbucketCredentials(username, appPassword, token string) bool {
+	return token != "" || (username != "" && appPassword != "")
+}
+
+// bitbucketCloneAuth returns the username and password used to clone bitbucket repositories over https
+func bitbucketCloneAuth(sess *Session) (string, string) {
+	if sess.BitbucketOAuthToken != "" {
+		return BitbucketOAuthUser, sess.BitbucketOAuthToken
+	}
+	return sess.BitbucketUsername, sess.BitbucketAppPassword
+}
+
+// authorize will add the credentials of the client to a request
+func (c bitbucketClient) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else {
+		req.SetBasicAuth(c.username, c.appPassword)
+	}
+}
+
+// get will request a path from the api, or a full url such as the next page of a list, and decode the json response
+func (c bitbucketClient) get(path string, out interface{}) error {
+	u := path
+	if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		u = c.apiURL + path
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("bitbucket returned %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("bitbucket returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// getPages will walk every page of a list from the api, handing the values of each page to fn
+func (c bitbucketClient) getPages(path string, fn func(values json.RawMessage) error) error {
+	next := path
+	for next != "" {
+		var page struct {
+			Values json.RawMessage `json:"values"`
+			Next   string          `json:"next"`
+		}
+		if err := c.get(next, &page); err != nil {
+			return err
+		}
+		if err := fn(page.Values); err != nil {
+			return err
+		}
+		next = page.Next
+	}
+	return nil
+}
+
+// bitbucketLinks are the links the api includes with each object
+type bitbucketLinks struct {
+	HTML struct {
+		Href string `json:"href"`
+	} `json:"html"`
+	Avatar struct {
+		Href string `json:"href"`
+	} `json:"avatar"`
+	Clone []struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	} `json:"clone"`
+}
+
+// bitbucketWorkspace is a workspace as returned by the api
+type bitbucketWorkspace struct {
+	UUID  string         `json:"uuid"`
+	Name  string         `json:"name"`
+	Slug  string         `json:"slug"`
+	Links bitbucketLinks `json:"links"`
+}
+
+// bitbucketRepository is a repository as returned by the api
+type bitbucketRepository struct {
+	UUID        string         `json:"uuid"`
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	FullName    string         `json:"full_name"`
+	Description string         `json:"description"`
+	Website     string         `json:"website"`
+	IsPrivate   bool           `json:"is_private"`
+	Size        int64          `json:"size"`
+	UpdatedOn   *time.Time     `json:"updated_on"`
+	Links       bitbucketLinks `json:"links"`
+	Parent      *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	MainBranch *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Project *struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+}
+
+// stringID will turn the string ids bitbucket and azure devops use for everything, such as uuids, into the numeric id
+// wraith tracks repositories by
+func stringID(id string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return int64(h.Sum64() & (1<<63 - 1))
+}
+
+// splitBitbucketTarget will split a target into its workspace and, when it is limited to one, its project key
+func splitBitbucketTarget(login string) (string, string) {
+	parts := strings.SplitN(login, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
+
+// ListWorkspaces will return the slug of every workspace the credentials can access, these are scanned when no
+// targets are given
+func (c bitbucketClient) ListWorkspaces() ([]string, error) {
+	var slugs []string
+	err := c.getPages("/workspaces?pagelen=100", func(values json.RawMessage) error {
+		var workspaces []bitbucketWorkspace
+		if err := json.Unmarshal(values, &workspaces); err != nil {
+			return err
+		}
+		for _, w := range workspaces {
+			slugs = append(slugs, w.Slug)
+		}
+		return nil
+	})
+	return slugs, err
+}
+
+// GetUserOrganization will look up a workspace, or a project within a workspace when the target is workspace/PROJECT
+func (c bitbucketClient) GetUserOrganization(login string) (*Owner, error) {
+	slug, projectKey := splitBitbucketTarget(login)
+	if slug == "" {
+		return nil, errors.New("a workspace is required")
+	}
+
+	var w bitbucketWorkspace
+	if err := c.get("/workspaces/"+url.PathEscape(slug), &w); err != nil {
+		return nil, err
+	}
+
+	name := w.Name
+	if projectKey != "" {
+		var project struct {
+			Name string `json:"name"`
+		}
+		if err := c.get(fmt.Sprintf("/workspaces/%s/projects/%s", url.PathEscape(slug), url.PathEscape(projectKey)), &project); err != nil {
+			return nil, err
+		}
+		name = w.Name + "/" + project.Name
+	}
+
+	id := stringID(w.UUID + projectKey)
+	emptyString := ""
+	return &Owner{
+		Login:     &login,
+		ID:        &id,
+		Type:      stringPointer(TargetTypeOrganization),
+		Name:      &name,
+		AvatarURL: &w.Links.Avatar.Href,
+		URL:       &w.Links.HTML.Href,
+		Company:   &emptyString,
+		Blog:      &emptyString,
+		Location:  &emptyString,
+		Email:     &emptyString,
+		Bio:       &emptyString,
+	}, nil
+}
+
+// GetRepositoriesFromOwner will gather every repository in a workspace, or in one project of it
+func (c bitbucketClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	var allRepos []*Repository
+	slug, projectKey := splitBitbucketTarget(*target.Login)
+
+	path := fmt.Sprintf("/repositories/%s?pagelen=100", url.PathEscape(slug))
+	if projectKey != "" {
+		path += "&q=" + url.QueryEscape(fmt.Sprintf(`project.key="%s"`, projectKey))
+	}
+
+	err := c.getPages(path, func(values json.RawMessage) error {
+		var repos []bitbucketRepository
+		if err := json.Unmarshal(values, &repos); err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			// don't capture forks
+			if repo.Parent != nil {
+				continue
+			}
+			// a repository with no commits has no main branch and nothing to scan
+			if repo.MainBranch == nil {
+				c.logger.Debug(" Skipping %s as it is empty\n", repo.FullName)
+				continue
+			}
+			allRepos = append(allRepos, newBitbucketRepository(repo))
+		}
+		return nil
+	})
+	return allRepos, err
+}
+
+// newBitbucketRepository will convert a repository from the api into the repository wraith scans
+func newBitbucketRepository(repo bitbucketRepository) *Repository {
+	cloneURL, sshURL := "", ""
+	for _, l := range repo.Links.Clone {
+		switch l.Name {
+		case "https":
+			// the clone url carries the name of the user that made the request, the credentials are given separately
+			if u, err := url.Parse(l.Href); err == nil {
+				u.User = nil
+				cloneURL = u.String()
+			}
+		case "ssh":
+			sshURL = l.Href
+		}
+	}
+
+	id := stringID(repo.UUID)
+	visibility := VisibilityPublic
+	if repo.IsPrivate {
+		visibility = VisibilityPrivate
+	}
+	fork := false
+	r := &Repository{
+		Owner:         stringPointer(repo.Workspace.Slug),
+		ID:            &id,
+		Name:          stringPointer(repo.Slug),
+		FullName:      stringPointer(repo.FullName),
+		CloneURL:      &cloneURL,
+		URL:           stringPointer(repo.Links.HTML.Href),
+		DefaultBranch: stringPointer(repo.MainBranch.Name),
+		Description:   stringPointer(repo.Description),
+		Homepage:      stringPointer(repo.Website),
+		Visibility:    &visibility,
+		Fork:          &fork,
+		PushedAt:      repo.UpdatedOn,
+		Size:          &repo.Size,
+		sshURL:        sshURL,
+	}
+	if repo.Project != nil {
+		r.Topics = []string{repo.Project.Name}
+	}
+	return r
+}
+
+// GetOrganizationMembers returns no members. Repositories in bitbucket belong to workspaces rather than to their
+// members, so there is nothing more to gather from them.
+func (c bitbucketClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
+	return nil, nil
+}
+
+// stringPointer returns a pointer to a copy of s
+func stringPointer(s string) *string {
+	return &s
+}
@@ -0,0 +1,401 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// BitbucketServerAPIPath is where the rest api of a bitbucket server or data center instance is below its url
+const BitbucketServerAPIPath = "/rest/api/1.0"
+
+// bitbucketServerPageSize is how many items are asked for in each page of a list, servers limit it to 1000
+const bitbucketServerPageSize = 100
+
+// CloneBitbucketServerRepository will create either an in memory clone of a given repository or clone to a temp dir.
+func CloneBitbucketServerRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
+
+// bitbucketServerClient holds the http access token used to talk to the rest api of a bitbucket server or data center
+// instance, which is a different api to the one of bitbucket cloud
+type bitbucketServerClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	logger  *Logger
+}
+
+// NewClient creates a bitbucket server api client instance using an http access token
+func (c bitbucketServerClient) NewClient(baseURL, token string, logger *Logger) bitbucketServerClient {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	c.token = token
+	c.client = NewHTTPClient(30 * time.Second)
+	c.logger = logger
+	return c
+}
+
+// NewBitbucketServerClient creates a bitbucket server api client for the instance at baseURL
+func NewBitbucketServerClient(baseURL, token string, logger *Logger) IClient {
+	return bitbucketServerClient.NewClient(bitbucketServerClient{}, baseURL, token, logger)
+}
+
+// CheckBitbucketServerCredentials will ensure we have a token and a server before talking to bitbucket server. Every
+// project the token can read is scanned when there are no targets, so they are not required.
+func CheckBitbucketServerCredentials(sess *Session) error {
+	if !ValidBitbucketServerToken(sess.BitbucketServerToken) {
+		return errors.New("Bitbucket Server requires a personal or project http access token with read permission")
+	}
+	if strings.TrimSpace(sess.BitbucketServerURL) == "" {
+		return errors.New("Bitbucket Server requires the url of the server, such as --bitbucket-server-url https://bitbucket.example.com")
+	}
+	return nil
+}
+
+// ValidBitbucketServerToken will check that an http access token was given
+func ValidBitbucketServerToken(token string) bool {
+	return strings.TrimSpace(token) != ""
+}
+
+// get will request a path below the rest api of the server and decode the json response
+func (c bitbucketServerClient) get(path string, query url.Values, out interface{}) error {
+	u := c.baseURL + BitbucketServerAPIPath + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && len(apiErr.Errors) > 0 && apiErr.Errors[0].Message != "" {
+			return fmt.Errorf("bitbucket server returned %d: %s", resp.StatusCode, apiErr.Errors[0].Message)
+		}
+		return fmt.Errorf("bitbucket server returned %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		return errors.New("bitbucket server did not return json, check the bitbucket-server-url")
+	}
+	return json.Unmarshal(data, out)
+}
+
+// getPages will walk every page of a list from the api, handing the values of each page to fn
+func (c bitbucketServerClient) getPages(path string, fn func(values json.RawMessage) error) error {
+	start := 0
+	for {
+		var page struct {
+			Values        json.RawMessage `json:"values"`
+			IsLastPage    bool            `json:"isLastPage"`
+			NextPageStart int             `json:"nextPageStart"`
+		}
+		query := url.Values{"start": {strconv.Itoa(start)}, "limit": {strconv.Itoa(bitbucketServerPageSize)}}
+		if err := c.get(path, query, &page); err != nil {
+			return err
+		}
+		if err := fn(page.Values); err != nil {
+			return err
+		}
+		if page.IsLastPage || page.NextPageStart <= start {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// bitbucketServerLinks are the links the api includes with projects, users and repositories
+type bitbucketServerLinks struct {
+	Self []struct {
+		Href string `json:"href"`
+	} `json:"self"`
+	Clone []struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	} `json:"clone"`
+}
+
+// self returns the link to the page of an object in the web interface
+func (l bitbucketServerLinks) self() string {
+	if len(l.Self) == 0 {
+		return ""
+	}
+	return l.Self[0].Href
+}
+
+// bitbucketServerProject is a project as returned by the api, the personal project of a user has a key of ~ and
+// their slug
+type bitbucketServerProject struct {
+	ID          int64                `json:"id"`
+	Key         string               `json:"key"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Public      bool                 `json:"public"`
+	Links       bitbucketServerLinks `json:"links"`
+}
+
+// bitbucketServerUser is a user as returned by the api
+type bitbucketServerUser struct {
+	ID           int64                `json:"id"`
+	Name         string               `json:"name"`
+	Slug         string               `json:"slug"`
+	DisplayName  string               `json:"displayName"`
+	EmailAddress string               `json:"emailAddress"`
+	Links        bitbucketServerLinks `json:"links"`
+}
+
+// bitbucketServerRepository is a repository as returned by the api. Origin is the repository a fork was made from and
+// archived is only returned by data center 8 and later.
+type bitbucketServerRepository struct {
+	ID          int64                  `json:"id"`
+	Slug        string                 `json:"slug"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Public      bool                   `json:"public"`
+	Archived    bool                   `json:"archived"`
+	Project     bitbucketServerProject `json:"project"`
+	Links       bitbucketServerLinks   `json:"links"`
+	Origin      *struct {
+		Slug string `json:"slug"`
+	} `json:"origin"`
+}
+
+// ListProjects will return the key of every project the token can read, these are scanned when no targets are given
+func (c bitbucketServerClient) ListProjects() ([]string, error) {
+	var keys []string
+	err := c.getPages("/projects", func(values json.RawMessage) error {
+		var projects []bitbucketServerProject
+		if err := json.Unmarshal(values, &projects); err != nil {
+			return err
+		}
+		for _, p := range projects {
+			keys = append(keys, p.Key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// GetUserOrganization will look up a project by its key, a user when the target is ~user, or a single repository when
+// the target is PROJECT/repository
+func (c bitbucketServerClient) GetUserOrganization(login string) (*Owner, error) {
+	if login == "" {
+		return nil, errors.New("a project, user or repository is required")
+	}
+	emptyString := ""
+
+	if strings.Contains(login, "/") {
+		repo, err := c.getRepository(login)
+		if err != nil {
+			return nil, err
+		}
+		id := stringID(c.baseURL + "/" + strings.ToLower(login))
+		return &Owner{
+			Login:     &login,
+			ID:        &id,
+			Type:      stringPointer(TargetTypeRepository),
+			Name:      stringPointer(repo.Project.Key + "/" + repo.Slug),
+			AvatarURL: &emptyString,
+			URL:       stringPointer(repo.Links.self()),
+			Company:   &emptyString,
+			Blog:      &emptyString,
+			Location:  &emptyString,
+			Email:     &emptyString,
+			Bio:       stringPointer(repo.Description),
+		}, nil
+	}
+
+	if strings.HasPrefix(login, "~") {
+		var user bitbucketServerUser
+		if err := c.get("/users/"+url.PathEscape(strings.TrimPrefix(login, "~")), nil, &user); err != nil {
+			return nil, err
+		}
+		return &Owner{
+			Login:     &login,
+			ID:        &user.ID,
+			Type:      stringPointer(TargetTypeUser),
+			Name:      stringPointer(user.DisplayName),
+			AvatarURL: &emptyString,
+			URL:       stringPointer(user.Links.self()),
+			Company:   &emptyString,
+			Blog:      &emptyString,
+			Location:  &emptyString,
+			Email:     stringPointer(user.EmailAddress),
+			Bio:       &emptyString,
+		}, nil
+	}
+
+	var project bitbucketServerProject
+	if err := c.get("/projects/"+url.PathEscape(login), nil, &project); err != nil {
+		return nil, err
+	}
+	return &Owner{
+		Login:     stringPointer(project.Key),
+		ID:        &project.ID,
+		Type:      stringPointer(TargetTypeOrganization),
+		Name:      stringPointer(project.Name),
+		AvatarURL: &emptyString,
+		URL:       stringPointer(project.Links.self()),
+		Company:   &emptyString,
+		Blog:      &emptyString,
+		Location:  &emptyString,
+		Email:     &emptyString,
+		Bio:       stringPointer(project.Description),
+	}, nil
+}
+
+// getRepository will look up one repository from its PROJECT/repository target
+func (c bitbucketServerClient) getRepository(fullName string) (bitbucketServerRepository, error) {
+	var repo bitbucketServerRepository
+	parts := strings.SplitN(fullName, "/", 2)
+	err := c.get(bitbucketServerRepoPath(parts[0], parts[1]), nil, &repo)
+	return repo, err
+}
+
+// GetRepositoriesFromOwner will gather the repositories of a project or of the personal project of a user, or the one
+// repository a target is limited to
+func (c bitbucketServerClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	var repos []bitbucketServerRepository
+	if *target.Type == TargetTypeRepository {
+		repo, err := c.getRepository(*target.Login)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	} else {
+		err := c.getPages("/projects/"+url.PathEscape(*target.Login)+"/repos", func(values json.RawMessage) error {
+			var list []bitbucketServerRepository
+			if err := json.Unmarshal(values, &list); err != nil {
+				return err
+			}
+			repos = append(repos, list...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var allRepos []*Repository
+	for _, repo := range repos {
+		fullName := repo.Project.Key + "/" + repo.Slug
+		// don't capture forks, unless it is the one repository that was asked for
+		if repo.Origin != nil && *target.Type != TargetTypeRepository {
+			continue
+		}
+		// a repository with no commits has no default branch to scan
+		branch, err := c.defaultBranch(repo.Project.Key, repo.Slug)
+		if err != nil || branch == "" {
+			c.logger.Debug(" Skipping %s as it is empty\n", fullName)
+			continue
+		}
+		allRepos = append(allRepos, newBitbucketServerRepository(repo, branch))
+	}
+	return allRepos, nil
+}
+
+// defaultBranch will look up the default branch of a repository. The default-branch resource was added in bitbucket
+// server 7.5, older servers only have branches/default.
+func (c bitbucketServerClient) defaultBranch(projectKey, slug string) (string, error) {
+	var branch struct {
+		DisplayID string `json:"displayId"`
+	}
+	err := c.get(bitbucketServerRepoPath(projectKey, slug)+"/default-branch", nil, &branch)
+	if err != nil {
+		err = c.get(bitbucketServerRepoPath(projectKey, slug)+"/branches/default", nil, &branch)
+	}
+	return branch.DisplayID, err
+}
+
+// newBitbucketServerRepository will convert a repository from the api into the repository wraith scans
+func newBitbucketServerRepository(repo bitbucketServerRepository, defaultBranch string) *Repository {
+	cloneURL, sshURL := "", ""
+	for _, l := range repo.Links.Clone {
+		switch l.Name {
+		case "http":
+			// the http link is https when the server is, it carries the name of the user that made the request and
+			// the credentials are given separately
+			if u, err := url.Parse(l.Href); err == nil {
+				u.User = nil
+				cloneURL = u.String()
+			}
+		case "ssh":
+			sshURL = l.Href
+		}
+	}
+
+	visibility := VisibilityPrivate
+	if repo.Public || repo.Project.Public {
+		visibility = VisibilityPublic
+	}
+	fork := repo.Origin != nil
+	pageURL := repo.Links.self()
+	return &Repository{
+		Owner:           stringPointer(repo.Project.Key),
+		ID:              &repo.ID,
+		Name:            stringPointer(repo.Slug),
+		FullName:        stringPointer(repo.Project.Key + "/" + repo.Slug),
+		CloneURL:        &cloneURL,
+		URL:             &pageURL,
+		DefaultBranch:   &defaultBranch,
+		Description:     stringPointer(repo.Description),
+		Homepage:        stringPointer(""),
+		Visibility:      &visibility,
+		Fork:            &fork,
+		Archived:        &repo.Archived,
+		Topics:          []string{repo.Project.Name},
+		webURL:          strings.TrimSuffix(pageURL, "/browse"),
+		sshURL:          sshURL,
+		bitbucketServer: true,
+	}
+}
+
+// GetOrganizationMembers returns no members. The repositories of a project belong to it rather than to the users who
+// can read it, and the personal repositories of a user are scanned by targeting ~user.
+func (c bitbucketServerClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
+	return nil, nil
+}
+
+// bitbucketServerRepoPath is the api path of a repository in a project
+func bitbucketServerRepoPath(projectKey, slug string) string {
+	return "/projects/" + url.PathEscape(projectKey) + "/repos/" + url.PathEscape(slug)
+}
+
+// setBitbucketServerUrls will set the links of a finding from the web url of its repository, as bitbucket server is
+// self-hosted
+func (f *Finding) setBitbucketServerUrls(webURL string) {
+	f.RepositoryUrl = webURL + "/browse"
+	f.FileUrl = fmt.Sprintf("%s/browse/%s?at=%s", webURL, f.FilePath, f.CommitHash)
+	f.CommitUrl = fmt.Sprintf("%s/commits/%s", webURL, f.CommitHash)
+}
+
+// bitbucketServerFileURL returns the api url of the raw content of a file at a commit
+func bitbucketServerFileURL(baseURL, projectKey, slug, commit, path string) string {
+	return fmt.Sprintf("%s%s%s/raw/%s?at=%s", strings.TrimSuffix(baseURL, "/"), BitbucketServerAPIPath,
+		bitbucketServerRepoPath(projectKey, slug), strings.TrimPrefix(path, "/"), url.QueryEscape(commit))
+}
@@ -0,0 +1,181 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+// fakeBitbucketServerAPI serves a project with its repositories split over two pages, a user with a personal
+// repository, and a server too old to have the default-branch resource
+type fakeBitbucketServerAPI struct {
+	url string
+}
+
+func (f *fakeBitbucketServerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "Authentication failed"}}})
+		return
+	}
+
+	links := func(href string) map[string]interface{} {
+		return map[string]interface{}{"self": []map[string]string{{"href": href}}}
+	}
+	project := map[string]interface{}{"id": 3, "key": "PLAT", "name": "Platform", "description": "Platform services", "links": links(f.url + "/projects/PLAT")}
+	personal := map[string]interface{}{"id": 4, "key": "~JANE", "name": "Jane Doe", "type": "PERSONAL"}
+	repo := func(p map[string]interface{}, slug string, extra map[string]interface{}) map[string]interface{} {
+		key := p["key"].(string)
+		r := map[string]interface{}{
+			"id":      len(key + slug),
+			"slug":    slug,
+			"name":    slug,
+			"project": p,
+			"links": map[string]interface{}{
+				"self":  []map[string]string{{"href": f.url + "/projects/" + key + "/repos/" + slug + "/browse"}},
+				"clone": []map[string]string{{"name": "ssh", "href": "ssh://git@example.com:7999/" + key + "/" + slug + ".git"}, {"name": "http", "href": "http://jane@" + f.url[len("http://"):] + "/scm/" + key + "/" + slug + ".git"}},
+			},
+		}
+		for k, v := range extra {
+			r[k] = v
+		}
+		return r
+	}
+	page := func(values []interface{}, next int) map[string]interface{} {
+		p := map[string]interface{}{"values": values, "isLastPage": next == 0}
+		if next != 0 {
+			p["nextPageStart"] = next
+		}
+		return p
+	}
+
+	switch r.URL.Path {
+	case "/rest/api/1.0/projects":
+		json.NewEncoder(w).Encode(page([]interface{}{project}, 0))
+	case "/rest/api/1.0/projects/PLAT":
+		json.NewEncoder(w).Encode(project)
+	case "/rest/api/1.0/users/jane":
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 9, "name": "jane", "slug": "jane", "displayName": "Jane Doe", "emailAddress": "jane@example.com", "links": links(f.url + "/users/jane")})
+	case "/rest/api/1.0/projects/PLAT/repos":
+		if r.URL.Query().Get("start") == "100" {
+			json.NewEncoder(w).Encode(page([]interface{}{repo(project, "site", map[string]interface{}{"public": true})}, 0))
+			return
+		}
+		values := []interface{}{
+			repo(project, "api", nil),
+			repo(project, "api-fork", map[string]interface{}{"origin": map[string]string{"slug": "api"}}),
+			repo(project, "empty", nil),
+		}
+		for i := len(values); i < 100; i++ {
+			values = append(values, repo(project, fmt.Sprintf("fork-%d", i), map[string]interface{}{"origin": map[string]string{"slug": "api"}}))
+		}
+		json.NewEncoder(w).Encode(page(values, 100))
+	case "/rest/api/1.0/projects/~jane/repos":
+		json.NewEncoder(w).Encode(page([]interface{}{repo(personal, "dotfiles", map[string]interface{}{"archived": true})}, 0))
+	case "/rest/api/1.0/projects/PLAT/repos/api-fork":
+		json.NewEncoder(w).Encode(repo(project, "api-fork", map[string]interface{}{"origin": map[string]string{"slug": "api"}}))
+	case "/rest/api/1.0/projects/PLAT/repos/api/default-branch", "/rest/api/1.0/projects/PLAT/repos/site/default-branch",
+		"/rest/api/1.0/projects/PLAT/repos/api-fork/default-branch":
+		json.NewEncoder(w).Encode(map[string]string{"id": "refs/heads/main", "displayId": "main"})
+	case "/rest/api/1.0/projects/~JANE/repos/dotfiles/branches/default":
+		json.NewEncoder(w).Encode(map[string]string{"id": "refs/heads/master", "displayId": "master"})
+	case "/rest/api/1.0/projects/PLAT/repos/empty/branches/default":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "Project does not exist."}}})
+	}
+}
+
+func TestBitbucketServerClient(t *testing.T) {
+
+	Convey("Given the api of a bitbucket server", t, func() {
+		api := &fakeBitbucketServerAPI{}
+		server := httptest.NewServer(api)
+		defer server.Close()
+		api.url = server.URL
+
+		client := core.NewBitbucketServerClient(server.URL+"/", "secret", &core.Logger{})
+
+		Convey("Every project the token can read should be listed", func() {
+			lister, ok := client.(interface{ ListProjects() ([]string, error) })
+			So(ok, ShouldBeTrue)
+			projects, err := lister.ListProjects()
+			So(err, ShouldBeNil)
+			So(projects, ShouldResemble, []string{"PLAT"})
+		})
+
+		Convey("Every page of a project should be gathered, skipping forks and empty repositories", func() {
+			owner, err := client.GetUserOrganization("PLAT")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "PLAT")
+			So(*owner.Name, ShouldEqual, "Platform")
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 2)
+
+			So(*repos[0].Owner, ShouldEqual, "PLAT")
+			So(*repos[0].Name, ShouldEqual, "api")
+			So(*repos[0].FullName, ShouldEqual, "PLAT/api")
+			So(*repos[0].CloneURL, ShouldEqual, server.URL+"/scm/PLAT/api.git")
+			So(*repos[0].URL, ShouldEqual, server.URL+"/projects/PLAT/repos/api/browse")
+			So(*repos[0].DefaultBranch, ShouldEqual, "main")
+			So(*repos[0].Visibility, ShouldEqual, core.VisibilityPrivate)
+			So(repos[0].Topics, ShouldResemble, []string{"Platform"})
+
+			So(*repos[1].Name, ShouldEqual, "site")
+			So(*repos[1].Visibility, ShouldEqual, core.VisibilityPublic)
+
+			members, err := client.GetOrganizationMembers(*owner)
+			So(err, ShouldBeNil)
+			So(members, ShouldBeEmpty)
+		})
+
+		Convey("A ~user target should gather their personal repositories", func() {
+			owner, err := client.GetUserOrganization("~jane")
+			So(err, ShouldBeNil)
+			So(*owner.Type, ShouldEqual, core.TargetTypeUser)
+			So(*owner.Name, ShouldEqual, "Jane Doe")
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 1)
+			So(*repos[0].FullName, ShouldEqual, "~JANE/dotfiles")
+			So(*repos[0].DefaultBranch, ShouldEqual, "master")
+			So(*repos[0].Archived, ShouldBeTrue)
+		})
+
+		Convey("A repository target should be scanned alone, even when it is a fork", func() {
+			owner, err := client.GetUserOrganization("PLAT/api-fork")
+			So(err, ShouldBeNil)
+			So(*owner.Type, ShouldEqual, core.TargetTypeRepository)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 1)
+			So(*repos[0].FullName, ShouldEqual, "PLAT/api-fork")
+			So(*repos[0].Fork, ShouldBeTrue)
+		})
+
+		Convey("Errors from the api should be returned", func() {
+			_, err := client.GetUserOrganization("NOPE")
+			So(err.Error(), ShouldEqual, "bitbucket server returned 404: Project does not exist.")
+
+			bad := core.NewBitbucketServerClient(server.URL, "wrong", &core.Logger{})
+			_, err = bad.GetUserOrganization("PLAT")
+			So(err.Error(), ShouldEqual, "bitbucket server returned 401: Authentication failed")
+		})
+	})
+
+	Convey("A bitbucket server token should not be empty", t, func() {
+		So(core.ValidBitbucketServerToken("secret"), ShouldBeTrue)
+		So(core.ValidBitbucketServerToken(" "), ShouldBeFalse)
+	})
+}
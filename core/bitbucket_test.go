@@ -0,0 +1,156 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+// fakeBitbucketAPI serves a workspace with a project and a list of repositories split over two pages
+type fakeBitbucketAPI struct {
+	url     string
+	queries []string
+}
+
+func (f *fakeBitbucketAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "jane" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "Unauthorized"}})
+		return
+	}
+
+	repo := func(slug string, private bool, extra map[string]interface{}) map[string]interface{} {
+		r := map[string]interface{}{
+			"uuid":       "{" + slug + "}",
+			"slug":       slug,
+			"full_name":  "acme/" + slug,
+			"is_private": private,
+			"updated_on": "2020-08-01T10:00:00.000000+00:00",
+			"mainbranch": map[string]string{"name": "main"},
+			"workspace":  map[string]string{"slug": "acme"},
+			"project":    map[string]string{"key": "OPS", "name": "Operations"},
+			"links": map[string]interface{}{
+				"html": map[string]string{"href": "https://bitbucket.org/acme/" + slug},
+				"clone": []map[string]string{
+					{"name": "https", "href": "https://jane@bitbucket.org/acme/" + slug + ".git"},
+					{"name": "ssh", "href": "git@bitbucket.org:acme/" + slug + ".git"},
+				},
+			},
+		}
+		for k, v := range extra {
+			r[k] = v
+		}
+		return r
+	}
+
+	switch r.URL.Path {
+	case "/workspaces/acme":
+		json.NewEncoder(w).Encode(map[string]interface{}{"uuid": "{acme}", "slug": "acme", "name": "Acme"})
+	case "/workspaces/acme/projects/OPS":
+		json.NewEncoder(w).Encode(map[string]interface{}{"key": "OPS", "name": "Operations"})
+	case "/workspaces":
+		json.NewEncoder(w).Encode(map[string]interface{}{"values": []map[string]string{{"slug": "acme"}, {"slug": "jane"}}})
+	case "/repositories/acme":
+		f.queries = append(f.queries, r.URL.Query().Get("q"))
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": []interface{}{
+				repo("infra", true, nil),
+			}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"next": f.url + "/repositories/acme?page=2",
+			"values": []interface{}{
+				repo("api", false, nil),
+				repo("api-fork", false, map[string]interface{}{"parent": map[string]string{"full_name": "other/api"}}),
+				repo("empty", false, map[string]interface{}{"mainbranch": nil}),
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestBitbucketClient(t *testing.T) {
+
+	Convey("Given a bitbucket api", t, func() {
+		api := &fakeBitbucketAPI{}
+		server := httptest.NewServer(api)
+		defer server.Close()
+		api.url = server.URL
+
+		client := core.NewBitbucketClient(server.URL, "jane", "secret", "", &core.Logger{})
+
+		Convey("A workspace should be an organization without members", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "acme")
+			So(*owner.Name, ShouldEqual, "Acme")
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+
+			members, err := client.GetOrganizationMembers(*owner)
+			So(err, ShouldBeNil)
+			So(members, ShouldBeEmpty)
+		})
+
+		Convey("Every page of repositories should be gathered, skipping forks and empty repositories", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 2)
+
+			So(*repos[0].Owner, ShouldEqual, "acme")
+			So(*repos[0].Name, ShouldEqual, "api")
+			So(*repos[0].FullName, ShouldEqual, "acme/api")
+			So(*repos[0].CloneURL, ShouldEqual, "https://bitbucket.org/acme/api.git")
+			So(*repos[0].DefaultBranch, ShouldEqual, "main")
+			So(*repos[0].Visibility, ShouldEqual, core.VisibilityPublic)
+			So(*repos[0].Fork, ShouldBeFalse)
+			So(repos[0].PushedAt, ShouldNotBeNil)
+			So(repos[0].Topics, ShouldResemble, []string{"Operations"})
+
+			So(*repos[1].Name, ShouldEqual, "infra")
+			So(*repos[1].Visibility, ShouldEqual, core.VisibilityPrivate)
+			So(*repos[1].ID, ShouldNotEqual, *repos[0].ID)
+		})
+
+		Convey("A project should limit the repositories to those in it", func() {
+			owner, err := client.GetUserOrganization("acme/OPS")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "acme/OPS")
+			So(*owner.Name, ShouldEqual, "Acme/Operations")
+
+			_, err = client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(api.queries[0], ShouldEqual, `project.key="OPS"`)
+		})
+
+		Convey("Without targets every workspace should be listed", func() {
+			lister := client.(interface{ ListWorkspaces() ([]string, error) })
+			workspaces, err := lister.ListWorkspaces()
+			So(err, ShouldBeNil)
+			So(workspaces, ShouldResemble, []string{"acme", "jane"})
+		})
+
+		Convey("Errors from the api should be returned", func() {
+			_, err := client.GetUserOrganization("missing")
+			So(err, ShouldNotBeNil)
+
+			bad := core.NewBitbucketClient(server.URL, "jane", "wrong", "", &core.Logger{})
+			_, err = bad.GetUserOrganization("acme")
+			So(err.Error(), ShouldEqual, "bitbucket returned 401: Unauthorized")
+		})
+	})
+
+	Convey("Bitbucket credentials should be an app password or an oauth token", t, func() {
+		So(core.ValidBitbucketCredentials("jane", "secret", ""), ShouldBeTrue)
+		So(core.ValidBitbucketCredentials("", "", "token"), ShouldBeTrue)
+		So(core.ValidBitbucketCredentials("jane", "", ""), ShouldBeFalse)
+		So(core.ValidBitbucketCredentials("", "secret", ""), ShouldBeFalse)
+	})
+}
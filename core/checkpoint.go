@@ -0,0 +1,225 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointLock keeps the threads of a scan from writing the checkpoint at the same time
+var checkpointLock sync.Mutex
+
+// DefaultCheckpointInterval is how often the checkpoint of a scan is written as repositories are finished
+const DefaultCheckpointInterval = 60 * time.Second
+
+// Checkpoint is the progress of a scan written to disk as it goes, so a scan that was interrupted can be resumed
+// with --resume rather than started over. The targets are not gathered again, the repositories that were finished
+// are not scanned again and the findings in them are carried over.
+type Checkpoint struct {
+	ScanType     string                  `json:"scan_type"`
+	StartedAt    time.Time               `json:"started_at"`
+	SavedAt      time.Time               `json:"saved_at"`
+	Targets      []*CheckpointTarget     `json:"targets"`
+	Repositories []*CheckpointRepository `json:"repositories"`
+	Findings     []*Finding              `json:"findings"`
+}
+
+// CheckpointTarget is a target that was gathered, along with whether it was gathered as a member of an organization
+type CheckpointTarget struct {
+	*Owner
+	Member bool `json:"member,omitempty"`
+}
+
+// CheckpointRepository is a repository that was scanned to the end
+type CheckpointRepository struct {
+	CloneURL string `json:"clone_url"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+}
+
+// LoadCheckpoint will read the checkpoint of a scan from a file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Checkpoint{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s is not a checkpoint: %s", path, err)
+	}
+	return c, nil
+}
+
+// Save will write the checkpoint to a file. It is written next to the file and moved over it, so a scan that is
+// killed while writing leaves the last checkpoint whole.
+func (c *Checkpoint) Save(path string) error {
+	checkpointLock.Lock()
+	defer checkpointLock.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Resume will take up the checkpoint of an interrupted scan, carrying over the repositories that were finished and
+// the findings in them
+func (s *Session) Resume(c *Checkpoint) {
+	s.Resumed = c
+	s.Lock()
+	defer s.Unlock()
+	s.completed = append(s.completed, c.Repositories...)
+	for _, f := range c.Findings {
+		// the findings were published before the scan was interrupted, so what is merged into them from now on is
+		// applied straight away
+		if entry, _ := s.dedupFinding(f); entry != nil {
+			entry.published = true
+		}
+		s.Findings = append(s.Findings, f)
+		s.Stats.IncrementFindingsTotal()
+	}
+}
+
+// resumeTargets will add the targets gathered before the scan was interrupted, reporting whether there were any. They
+// are not gathered again, which for a scan of every organization on an instance saves hundreds of requests.
+func (s *Session) resumeTargets() bool {
+	if s.Resumed == nil || len(s.Resumed.Targets) == 0 {
+		return false
+	}
+	s.targetsGathered = true
+	for _, t := range s.Resumed.Targets {
+		if t.Owner == nil || t.ID == nil {
+			continue
+		}
+		t.Owner.member = t.Member
+		s.AddTarget(t.Owner)
+	}
+	s.Out.Important("Resuming with the %d %s gathered before the scan was interrupted\n", len(s.Targets), Pluralize(len(s.Targets), "target", "targets"))
+	return true
+}
+
+// checkpointTargets will write the checkpoint once every target has been gathered. Targets are only kept in it when
+// gathering them was not interrupted, as a resumed scan does not gather any more.
+func (s *Session) checkpointTargets() {
+	if s.Interrupted() {
+		return
+	}
+	s.Lock()
+	s.targetsGathered = true
+	s.Unlock()
+	s.SaveCheckpoint()
+}
+
+// remainingRepositories are the repositories of the session that were not finished before the scan was interrupted
+func (s *Session) remainingRepositories() []*Repository {
+	if s.Resumed == nil {
+		return s.Repositories
+	}
+	s.Lock()
+	done := map[string]bool{}
+	for _, r := range s.completed {
+		done[r.CloneURL] = true
+	}
+	s.Unlock()
+
+	var remaining []*Repository
+	for _, repo := range s.Repositories {
+		if !done[*repo.CloneURL] {
+			remaining = append(remaining, repo)
+		}
+	}
+	if skipped := len(s.Repositories) - len(remaining); skipped > 0 {
+		s.Out.Important("Skipping %d %s already scanned before the scan was interrupted\n", skipped, Pluralize(skipped, "repository", "repositories"))
+	}
+	return remaining
+}
+
+// checkpointRepository will record a repository that was scanned to the end, writing the checkpoint when it was last
+// written longer ago than the checkpoint interval
+func (s *Session) checkpointRepository(repo *Repository) {
+	if s.CheckpointFile == "" {
+		return
+	}
+	s.Lock()
+	s.completed = append(s.completed, &CheckpointRepository{CloneURL: *repo.CloneURL, Owner: *repo.Owner, Name: *repo.Name})
+	due := time.Since(s.checkpointSaved) >= s.CheckpointInterval
+	s.Unlock()
+	if due {
+		s.SaveCheckpoint()
+	}
+}
+
+// checkpoint will take the progress of the session as it is now. Only the findings in repositories that were scanned
+// to the end are kept, as the others are scanned again when the scan is resumed.
+func (s *Session) checkpoint() *Checkpoint {
+	s.Lock()
+	defer s.Unlock()
+	c := &Checkpoint{
+		ScanType:     s.ScanType,
+		StartedAt:    s.Stats.StartedAt,
+		SavedAt:      time.Now(),
+		Repositories: append([]*CheckpointRepository{}, s.completed...),
+	}
+	if s.Resumed != nil {
+		c.StartedAt = s.Resumed.StartedAt
+	}
+	if s.targetsGathered {
+		for _, t := range s.Targets {
+			c.Targets = append(c.Targets, &CheckpointTarget{Owner: t, Member: t.member})
+		}
+	}
+	done := map[string]bool{}
+	for _, r := range s.completed {
+		done[r.Owner+"/"+r.Name] = true
+	}
+	for _, f := range s.Findings {
+		if done[f.RepositoryOwner+"/"+f.RepositoryName] {
+			// a copy is written, as a finding can still be merged with a duplicate while the checkpoint is being written
+			finding := *f
+			c.Findings = append(c.Findings, &finding)
+		}
+	}
+	return c
+}
+
+// SaveCheckpoint will write the progress of the session to its checkpoint file, if it has one
+func (s *Session) SaveCheckpoint() {
+	if s.CheckpointFile == "" {
+		return
+	}
+	c := s.checkpoint()
+	s.Lock()
+	s.checkpointSaved = c.SavedAt
+	s.Unlock()
+	if err := c.Save(s.CheckpointFile); err != nil {
+		s.Out.Error("Failed to write the checkpoint to %s: %s\n", s.CheckpointFile, err)
+	}
+}
+
+// finishCheckpoint will write the checkpoint one last time when the scan was interrupted, so it can be resumed, and
+// remove it once the scan has finished, as there is nothing left to resume
+func (s *Session) finishCheckpoint() {
+	if s.CheckpointFile == "" {
+		return
+	}
+	if s.Interrupted() {
+		s.SaveCheckpoint()
+		s.Out.Important("Run the scan again with --resume %s to continue where it left off\n", s.CheckpointFile)
+		return
+	}
+	if err := os.Remove(s.CheckpointFile); err != nil && !os.IsNotExist(err) {
+		s.Out.Error("Failed to remove the checkpoint %s: %s\n", s.CheckpointFile, err)
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// cloneCacheLocks keeps scans in the same process from fetching into the same cached clone at once
+var cloneCacheLocks sync.Map
+
+// cloneCacheName leaves only the characters that are safe in a directory name
+var cloneCacheName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// cloneCachePath is where the clone of a repository is kept in the cache. It is named after the repository and a hash
+// of its url, so repositories with the same name under another owner or on another host do not share a clone.
+func cloneCachePath(cacheDir string, url string) string {
+	name := strings.TrimSuffix(filepath.Base(strings.TrimRight(url, "/")), ".git")
+	name = cloneCacheName.ReplaceAllString(name, "_")
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%x.git", name, sum[:6]))
+}
+
+// CloneCachedRepository will open the bare clone of a repository kept in the cache directory and fetch what has been
+// pushed since it was last scanned, or make the clone when there is none yet. There is no checkout, so the path that is
+// returned is empty and the clone is kept once the repository has been scanned.
+func CloneCachedRepository(cacheDir string, cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	dir := cloneCachePath(cacheDir, *cloneConfig.Url)
+	lock, _ := cloneCacheLocks.LoadOrStore(dir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if repository, err := git.PlainOpen(dir); err == nil {
+		if err := fetchCachedRepository(repository, cloneConfig); err != nil {
+			return nil, "", err
+		}
+		return repository, "", nil
+	}
+
+	// whatever is left of a clone that could not be opened, such as one that was interrupted, is started over
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, "", err
+	}
+	cloneOptions := &git.CloneOptions{
+		URL:          *cloneConfig.Url,
+		Depth:        *cloneConfig.Depth,
+		SingleBranch: cloneConfig.singleBranch(),
+		Tags:         git.NoTags,
+		Auth:         cloneConfig.auth(),
+	}
+	if cloneConfig.Branch != nil && *cloneConfig.Branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(*cloneConfig.Branch)
+	}
+	repository, err := git.PlainClone(dir, true, cloneOptions)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", err
+	}
+	return repository, "", nil
+}
+
+// fetchCachedRepository will fetch the branches being scanned into a cached clone and point HEAD at the branch that is
+// scanned first, the default branch of the repository. When every branch is fetched, those that have since been
+// deleted from the remote are removed so they are not scanned again.
+func fetchCachedRepository(repository *git.Repository, cloneConfig *CloneConfiguration) error {
+	branch := ""
+	if cloneConfig.Branch != nil {
+		branch = *cloneConfig.Branch
+	}
+	if branch == "" {
+		// without a branch, such as for a gist, the branch HEAD pointed at when it was cloned is fetched
+		head, err := repository.Storer.Reference(plumbing.HEAD)
+		if err != nil {
+			return err
+		}
+		branch = head.Target().Short()
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+	if !cloneConfig.singleBranch() {
+		refSpec = "+refs/heads/*:refs/remotes/origin/*"
+	}
+	err := repository.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{refSpec},
+		Depth:    *cloneConfig.Depth,
+		Auth:     cloneConfig.auth(),
+		Tags:     git.NoTags,
+		Force:    true,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+
+	if !cloneConfig.singleBranch() {
+		if err := pruneCachedBranches(repository, cloneConfig, branch); err != nil {
+			return err
+		}
+	}
+
+	fetched, err := repository.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return err
+	}
+	local := plumbing.NewBranchReferenceName(branch)
+	if err := repository.Storer.SetReference(plumbing.NewHashReference(local, fetched.Hash())); err != nil {
+		return err
+	}
+	return repository.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, local))
+}
+
+// pruneCachedBranches will remove the branches of a cached clone that are no longer on the remote
+func pruneCachedBranches(repository *git.Repository, cloneConfig *CloneConfiguration, branch string) error {
+	remote, err := repository.Remote("origin")
+	if err != nil {
+		return err
+	}
+	advertised, err := remote.List(&git.ListOptions{Auth: cloneConfig.auth()})
+	if err != nil {
+		return err
+	}
+	live := map[string]bool{branch: true, "HEAD": true}
+	for _, ref := range advertised {
+		if ref.Name().IsBranch() {
+			live[ref.Name().Short()] = true
+		}
+	}
+
+	refs, err := repository.References()
+	if err != nil {
+		return err
+	}
+	var stale []plumbing.ReferenceName
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := strings.TrimPrefix(ref.Name().String(), "refs/remotes/origin/")
+		if ref.Name().IsBranch() {
+			name = ref.Name().Short()
+		} else if name == ref.Name().String() {
+			return nil
+		}
+		if !live[name] {
+			stale = append(stale, ref.Name())
+		}
+		return nil
+	})
+	for _, name := range stale {
+		if err := repository.Storer.RemoveReference(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
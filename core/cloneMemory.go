@@ -0,0 +1,102 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// inMemCloneOverhead is how many times the size of a repository an in memory clone is estimated to take. Providers
+// give the size of the compressed packs of a repository, while go-git keeps each object uncompressed in memory.
+const inMemCloneOverhead = 4
+
+// cloneInMemory will decide whether a repository is cloned in memory, setting aside what the clone is estimated to take
+// from the in-mem-clone-budget. A repository that would go over the budget, or whose size is not known, is cloned to
+// disk instead. What was set aside is returned so it can be given back once the repository has been scanned.
+func cloneInMemory(sess *Session, repo *Repository) (bool, int64) {
+	if !sess.InMemClone {
+		return false, 0
+	}
+	if sess.InMemCloneBudget <= 0 {
+		return true, 0
+	}
+
+	log := sess.Out.With("repo", *repo.FullName)
+	size := repositorySize(sess, repo)
+	if size == nil {
+		log.Info("The size of %s is not known, cloning it to disk rather than in memory\n", *repo.FullName)
+		return false, 0
+	}
+	estimate := *size * inMemCloneOverhead
+	if !sess.reserveCloneMemory(estimate) {
+		log.Info("%s is estimated to take %d MB in memory, more than is left of the in memory clone budget, cloning it to disk\n", *repo.FullName, estimate/1024/1024)
+		return false, 0
+	}
+	return true, estimate
+}
+
+// repositorySize is the size of a repository in bytes, from the provider it was gathered from or, for a local
+// repository, from disk. It is nil when there is no way to know it before the repository is cloned.
+func repositorySize(sess *Session, repo *Repository) *int64 {
+	if repo.Size != nil {
+		return repo.Size
+	}
+	if sess.ScanType == "localGit" {
+		repo.Size = localRepositorySize(*repo.CloneURL)
+		return repo.Size
+	}
+
+	// some providers only give the size of a repository when it is asked for on its own
+	sizer, ok := sess.Client.(interface {
+		GetRepositorySize(repo *Repository) (*int64, error)
+	})
+	if !ok {
+		return nil
+	}
+	size, err := sizer.GetRepositorySize(repo)
+	if err != nil {
+		sess.Out.With("repo", *repo.FullName).Warn("Unable to look up the size of %s: %s\n", *repo.FullName, err)
+	}
+	repo.Size = size
+	return size
+}
+
+// localRepositorySize is how much the git directory of a local repository takes on disk
+func localRepositorySize(path string) *int64 {
+	gitDir := filepath.Join(path, ".git")
+	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		path = gitDir
+	}
+
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return &size
+}
+
+// reserveCloneMemory will set aside memory for a clone, unless it would take the clones in memory over the budget
+func (s *Session) reserveCloneMemory(size int64) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.cloneMemory+size > s.InMemCloneBudget*1024*1024 {
+		return false
+	}
+	s.cloneMemory += size
+	return true
+}
+
+// releaseCloneMemory will give back the memory set aside for a clone once it has been scanned
+func (s *Session) releaseCloneMemory(size int64) {
+	s.Lock()
+	s.cloneMemory -= size
+	s.Unlock()
+}
@@ -0,0 +1,441 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// These are the versions of the CodeCommit and STS apis that are used
+const (
+	CodeCommitAPIVersion = "CodeCommit_20150413"
+	STSAPIVersion        = "2011-06-15"
+)
+
+// codeCommitBatchSize is the most repositories BatchGetRepositories returns at once
+const codeCommitBatchSize = 25
+
+// codeCommitRoleSession is the name the sessions of the roles that are assumed are given, which shows in CloudTrail
+const codeCommitRoleSession = "wraith"
+
+// CloneCodeCommitRepository will create either an in memory clone of a given repository or clone to a temp dir.
+func CloneCodeCommitRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
+
+// codeCommitRole is a role that is assumed to list and clone the repositories of its account, along with the
+// credentials of its current session
+type codeCommitRole struct {
+	arn     string
+	creds   AWSCredentials
+	expires time.Time
+}
+
+// codeCommitClient lists the repositories of CodeCommit in each account and region it is given. The repositories of
+// the account of the credentials are listed with them directly, those of other accounts with a role assumed in each.
+type codeCommitClient struct {
+	sync.Mutex
+	endpoint string
+	creds    AWSCredentials
+	roles    map[string]*codeCommitRole
+	account  string
+	client   *http.Client
+	logger   *Logger
+}
+
+// NewCodeCommitClient creates a client for the CodeCommit repositories the credentials can reach, in their own account
+// and in the accounts of the roles. The endpoint is only set to reach a service such as localstack in place of AWS.
+func NewCodeCommitClient(endpoint string, creds AWSCredentials, roleARNs []string, logger *Logger) (IClient, error) {
+	c := &codeCommitClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		creds:    creds,
+		roles:    map[string]*codeCommitRole{},
+		client:   NewHTTPClient(30 * time.Second),
+		logger:   logger,
+	}
+	for _, arn := range roleARNs {
+		account, err := roleAccount(arn)
+		if err != nil {
+			return nil, err
+		}
+		c.roles[account] = &codeCommitRole{arn: arn}
+	}
+	return c, nil
+}
+
+// CheckCodeCommitCredentials will ensure there are aws credentials to sign the requests to CodeCommit with
+func CheckCodeCommitCredentials(sess *Session) (AWSCredentials, error) {
+	creds, err := LoadAWSCredentials(sess.AWSProfile)
+	if err != nil {
+		return creds, fmt.Errorf("CodeCommit requires aws credentials: %s", err)
+	}
+	return creds, nil
+}
+
+// roleAccount returns the account of a role from its arn, such as arn:aws:iam::123456789012:role/wraith
+func roleAccount(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || parts[4] == "" || !strings.HasPrefix(parts[5], "role/") {
+		return "", fmt.Errorf("%s is not the arn of a role", arn)
+	}
+	return parts[4], nil
+}
+
+// CodeCommitTargets are the account/region pairs the repositories of a scan are listed in. Each region is scanned in
+// the account of the credentials, or in the account of every role when there are roles to assume. A target can also
+// name its account itself. The region of the environment, or us-east-1, is scanned when no regions are given.
+func CodeCommitTargets(sess *Session) []string {
+	regions := sess.CodeCommitTargets
+	if len(regions) == 0 {
+		region := S3DefaultRegion
+		for _, env := range []string{"AWS_DEFAULT_REGION", "AWS_REGION"} {
+			if os.Getenv(env) != "" {
+				region = os.Getenv(env)
+			}
+		}
+		regions = []string{region}
+	}
+
+	var targets []string
+	for _, region := range regions {
+		if strings.Contains(region, "/") || len(sess.CodeCommitRoleARNs) == 0 {
+			targets = append(targets, region)
+			continue
+		}
+		for _, arn := range sess.CodeCommitRoleARNs {
+			if account, err := roleAccount(arn); err == nil {
+				targets = append(targets, account+"/"+region)
+			}
+		}
+	}
+	return targets
+}
+
+// splitCodeCommitTarget will split a target into its account, which is empty for the account of the credentials, and
+// its region
+func splitCodeCommitTarget(login string) (string, string) {
+	parts := strings.SplitN(login, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", parts[0]
+}
+
+// url is where the requests for a service in a region are sent
+func (c *codeCommitClient) url(service, region string) string {
+	switch {
+	case c.endpoint != "":
+		return c.endpoint + "/"
+	case service == "sts":
+		return "https://sts.amazonaws.com/"
+	default:
+		return fmt.Sprintf("https://%s.%s.amazonaws.com/", service, region)
+	}
+}
+
+// credentials are the keys the requests for an account are signed with, assuming its role again shortly before the
+// session of the role runs out
+func (c *codeCommitClient) credentials(account string) (AWSCredentials, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	role, ok := c.roles[account]
+	switch {
+	case account == "" || account == c.account && !ok:
+		return c.creds, nil
+	case !ok:
+		return AWSCredentials{}, fmt.Errorf("none of the codecommit-role-arns are in account %s", account)
+	case time.Until(role.expires) > 5*time.Minute:
+		return role.creds, nil
+	}
+
+	var resp struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleResult>Credentials"`
+	}
+	err := c.sts(c.creds, url.Values{
+		"Action":          {"AssumeRole"},
+		"RoleArn":         {role.arn},
+		"RoleSessionName": {codeCommitRoleSession},
+	}, &resp)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("unable to assume %s: %s", role.arn, err)
+	}
+	role.creds = AWSCredentials{
+		AccessKeyID:     resp.Credentials.AccessKeyID,
+		SecretAccessKey: resp.Credentials.SecretAccessKey,
+		SessionToken:    resp.Credentials.SessionToken,
+	}
+	role.expires = resp.Credentials.Expiration
+	return role.creds, nil
+}
+
+// sts will make a signed request to STS and decode its xml response
+func (c *codeCommitClient) sts(creds AWSCredentials, query url.Values, out interface{}) error {
+	query.Set("Version", STSAPIVersion)
+	body := []byte(query.Encode())
+	req, err := http.NewRequest(http.MethodPost, c.url("sts", S3DefaultRegion), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("User-Agent", UserAgent)
+	signAWSServiceRequest(req, "sts", S3DefaultRegion, creds, body, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code    string `xml:"Error>Code"`
+			Message string `xml:"Error>Message"`
+		}
+		if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			return fmt.Errorf("sts returned %s: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("sts returned %s", resp.Status)
+	}
+	return xml.Unmarshal(data, out)
+}
+
+// call will make a signed request to an action of the CodeCommit api in a region and decode its json response
+func (c *codeCommitClient) call(creds AWSCredentials, region, action string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url("codecommit", region), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", CodeCommitAPIVersion+"."+action)
+	req.Header.Set("User-Agent", UserAgent)
+	signAWSServiceRequest(req, "codecommit", region, creds, body, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Type != "" {
+			kind := apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+			return fmt.Errorf("codecommit returned %s in %s: %s", kind, region, apiErr.Message)
+		}
+		return fmt.Errorf("codecommit returned %s in %s", resp.Status, region)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// GetUserOrganization will look up the account of a target and check that its credentials work. A target is a
+// region, or account/region for the account of one of the roles.
+func (c *codeCommitClient) GetUserOrganization(login string) (*Owner, error) {
+	account, region := splitCodeCommitTarget(login)
+	if region == "" {
+		return nil, errors.New("a region is required")
+	}
+	creds, err := c.credentials(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var identity struct {
+		Account string `xml:"GetCallerIdentityResult>Account"`
+	}
+	if err := c.sts(creds, url.Values{"Action": {"GetCallerIdentity"}}, &identity); err != nil {
+		return nil, err
+	}
+	if account == "" {
+		c.Lock()
+		c.account = identity.Account
+		c.Unlock()
+	}
+
+	name := identity.Account + "/" + region
+	id := stringID("codecommit/" + name)
+	webURL := fmt.Sprintf("https://%s.console.aws.amazon.com/codesuite/codecommit/repositories?region=%s", region, region)
+	emptyString := ""
+	return &Owner{
+		Login:     &login,
+		ID:        &id,
+		Type:      stringPointer(TargetTypeOrganization),
+		Name:      &name,
+		AvatarURL: &emptyString,
+		URL:       &webURL,
+		Company:   &emptyString,
+		Blog:      &emptyString,
+		Location:  &region,
+		Email:     &emptyString,
+		Bio:       &emptyString,
+	}, nil
+}
+
+// codeCommitRepository is a repository as returned by BatchGetRepositories
+type codeCommitRepository struct {
+	ID               string  `json:"repositoryId"`
+	Name             string  `json:"repositoryName"`
+	Description      string  `json:"repositoryDescription"`
+	DefaultBranch    string  `json:"defaultBranch"`
+	LastModifiedDate float64 `json:"lastModifiedDate"`
+	CloneURLHTTP     string  `json:"cloneUrlHttp"`
+}
+
+// GetRepositoriesFromOwner will gather every repository of the account and region of a target
+func (c *codeCommitClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	account, region := splitCodeCommitTarget(*target.Login)
+	creds, err := c.credentials(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	next := ""
+	for {
+		in := map[string]string{"sortBy": "repositoryName", "order": "ascending"}
+		if next != "" {
+			in["nextToken"] = next
+		}
+		var page struct {
+			Repositories []struct {
+				Name string `json:"repositoryName"`
+			} `json:"repositories"`
+			NextToken string `json:"nextToken"`
+		}
+		if err := c.call(creds, region, "ListRepositories", in, &page); err != nil {
+			return nil, err
+		}
+		for _, repo := range page.Repositories {
+			names = append(names, repo.Name)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		next = page.NextToken
+	}
+
+	var allRepos []*Repository
+	for start := 0; start < len(names); start += codeCommitBatchSize {
+		end := start + codeCommitBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		var batch struct {
+			Repositories []codeCommitRepository `json:"repositories"`
+		}
+		if err := c.call(creds, region, "BatchGetRepositories", map[string][]string{"repositoryNames": names[start:end]}, &batch); err != nil {
+			return nil, err
+		}
+		for _, repo := range batch.Repositories {
+			// a repository with no commits has no default branch to scan
+			if repo.DefaultBranch == "" {
+				c.logger.Debug(" Skipping %s in %s as it is empty\n", repo.Name, region)
+				continue
+			}
+			allRepos = append(allRepos, c.newRepository(*target.Name, account, region, repo))
+		}
+	}
+	return allRepos, nil
+}
+
+// newRepository will convert a repository from the api into the repository wraith scans. Its owner is its account and
+// region, as repositories in different regions can have the same name.
+func (c *codeCommitClient) newRepository(owner, account, region string, repo codeCommitRepository) *Repository {
+	id := stringID(repo.ID)
+	visibility := VisibilityPrivate
+	fork := false
+	pushedAt := time.Unix(int64(repo.LastModifiedDate), 0).UTC()
+	webURL := fmt.Sprintf("https://%s.console.aws.amazon.com/codesuite/codecommit/repositories/%s", region, url.PathEscape(repo.Name))
+	return &Repository{
+		Owner:         &owner,
+		ID:            &id,
+		Name:          stringPointer(repo.Name),
+		FullName:      stringPointer(owner + "/" + repo.Name),
+		CloneURL:      stringPointer(repo.CloneURLHTTP),
+		URL:           stringPointer(webURL + "/browse?region=" + region),
+		DefaultBranch: stringPointer(repo.DefaultBranch),
+		Description:   stringPointer(repo.Description),
+		Homepage:      stringPointer(""),
+		Visibility:    &visibility,
+		Fork:          &fork,
+		PushedAt:      &pushedAt,
+		webURL:        webURL,
+		codeCommit:    &codeCommitSource{client: c, account: account, region: region},
+	}
+}
+
+// GetOrganizationMembers returns no members, repositories in CodeCommit belong to an account rather than to its users
+func (c *codeCommitClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
+	return nil, nil
+}
+
+// codeCommitSource is where a repository in CodeCommit was listed, which the credentials it is cloned with are for
+type codeCommitSource struct {
+	client  *codeCommitClient
+	account string
+	region  string
+}
+
+// gitCredentials are the username and password a repository is cloned with over https, signed with the credentials
+// of its account the way the git-remote-codecommit helper does rather than with git credentials of an IAM user
+func (s *codeCommitSource) gitCredentials(cloneURL string, now time.Time) (string, string, error) {
+	creds, err := s.client.credentials(s.account)
+	if err != nil {
+		return "", "", err
+	}
+	return CodeCommitGitCredentials(creds, cloneURL, s.region, now)
+}
+
+// CodeCommitGitCredentials will sign the https clone url of a CodeCommit repository in a region, returning the
+// username and password git can clone it with until the signature expires
+func CodeCommitGitCredentials(creds AWSCredentials, cloneURL, region string, now time.Time) (string, string, error) {
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", "", err
+	}
+	amzDate := now.UTC().Format("20060102T150405")
+	canonicalRequest := fmt.Sprintf("GIT\n%s\n\nhost:%s\n\nhost\n", u.EscapedPath(), u.Host)
+	_, signature := awsSignature(canonicalRequest, "codecommit", region, creds, amzDate)
+
+	username := creds.AccessKeyID
+	if creds.SessionToken != "" {
+		username += "%" + creds.SessionToken
+	}
+	return username, amzDate + "Z" + signature, nil
+}
+
+// setCodeCommitUrls will set the links of a finding to the CodeCommit console of the region of its repository
+func (f *Finding) setCodeCommitUrls(webURL, region string) {
+	f.RepositoryUrl = webURL + "/browse?region=" + region
+	f.FileUrl = fmt.Sprintf("%s/browse/%s/--/%s?region=%s", webURL, f.CommitHash, f.FilePath, region)
+	f.CommitUrl = fmt.Sprintf("%s/commit/%s?region=%s", webURL, f.CommitHash, region)
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"bufio"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// CodeOwnersFiles are the locations github and gitlab read a CODEOWNERS file from, the first one found is used
+var CodeOwnersFiles = []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersRule is a single line of a CODEOWNERS file
+type codeOwnersRule struct {
+	pattern gitignore.Pattern
+	owners  []string
+}
+
+// CodeOwners are the rules of a CODEOWNERS file, used to route a finding to the team that owns the file it is in
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners will read the rules from the contents of a CODEOWNERS file. The patterns follow the same rules as
+// a .gitignore file. Gitlab sections are read as if they were one list of rules.
+func ParseCodeOwners(content string) *CodeOwners {
+	c := &CodeOwners{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		c.rules = append(c.rules, codeOwnersRule{
+			pattern: gitignore.ParsePattern(fields[0], nil),
+			owners:  fields[1:],
+		})
+	}
+	return c
+}
+
+// LoadCodeOwners will read the CODEOWNERS file from the latest commit of a repository, it returns nil if there is none
+func LoadCodeOwners(clone *git.Repository) *CodeOwners {
+	ref, err := clone.Head()
+	if err != nil {
+		return nil
+	}
+	commit, err := clone.CommitObject(ref.Hash())
+	if err != nil {
+		return nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+
+	for _, name := range CodeOwnersFiles {
+		file, err := tree.File(name)
+		if err != nil {
+			continue
+		}
+		content, err := file.Contents()
+		if err != nil {
+			continue
+		}
+		return ParseCodeOwners(content)
+	}
+	return nil
+}
+
+// Owners returns the owners of a path. As with github and gitlab the last rule that matches the path wins.
+func (c *CodeOwners) Owners(path string) []string {
+	if c == nil {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.Match(parts, false) == gitignore.Exclude {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"wraith/core"
+)
+
+func TestCodeOwners(t *testing.T) {
+
+	Convey("Given a CODEOWNERS file", t, func() {
+		c := core.ParseCodeOwners(`# the default owners
+*       @acme/everyone
+
+/docs/  @acme/docs
+*.js    @acme/frontend @jane # inline comment
+[Deploy]
+/build/ @acme/ops
+`)
+
+		Convey("The last rule that matches should win", func() {
+			So(c.Owners("README.md"), ShouldResemble, []string{"@acme/everyone"})
+			So(c.Owners("docs/index.md"), ShouldResemble, []string{"@acme/docs"})
+			So(c.Owners("docs/app.js"), ShouldResemble, []string{"@acme/frontend", "@jane"})
+		})
+
+		Convey("Anchored patterns should only match from the root", func() {
+			So(c.Owners("build/key.pem"), ShouldResemble, []string{"@acme/ops"})
+			So(c.Owners("src/build/key.pem"), ShouldResemble, []string{"@acme/everyone"})
+		})
+	})
+
+	Convey("Given no CODEOWNERS file there should be no owners", t, func() {
+		var c *core.CodeOwners
+		So(c.Owners("README.md"), ShouldBeNil)
+		So(core.ParseCodeOwners("").Owners("README.md"), ShouldBeNil)
+	})
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// CommitRange is the part of the history of each repository that is scanned, so a CI pipeline can scan only what
+// changed since its last green build. Since is a revision whose history is left out and SinceTime a time commits
+// before are left out. Until is the revision the history is walked back from in place of the heads of the branches.
+type CommitRange struct {
+	Since     string
+	SinceTime time.Time
+	Until     string
+}
+
+// NewCommitRange will read --since-commit, --since-date and --until-commit. The commits are revisions, such as a
+// commit, tag or branch, and the date is a date such as 2020-08-03, a time in RFC3339 or a number of days before now
+// such as 7d. They are separate flags as a short commit such as 1234567d reads as a number of days. It returns nil
+// when none is set, so the whole history is scanned.
+func NewCommitRange(since, sinceDate, until string, now time.Time) (*CommitRange, error) {
+	if since == "" && sinceDate == "" && until == "" {
+		return nil, nil
+	}
+	r := &CommitRange{Since: since, Until: until}
+	if sinceDate != "" {
+		t, err := parsePushedAfter(sinceDate, now)
+		if err != nil {
+			return nil, fmt.Errorf("since-date %s is not a date, a time or a number of days", sinceDate)
+		}
+		r.SinceTime = t
+	}
+	return r, nil
+}
+
+// resolveCommit will find the commit a revision is in a clone. Branches other than the one checked out are only
+// fetched as remote branches, so a branch is looked for as one of those as well.
+func resolveCommit(clone *git.Repository, revision string) (plumbing.Hash, error) {
+	hash, err := clone.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		hash, err = clone.ResolveRevision(plumbing.Revision("origin/" + revision))
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%s is not a commit, tag or branch of the repository", revision)
+	}
+	if _, err := clone.CommitObject(*hash); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%s is not a commit of the repository: %s", revision, err)
+	}
+	return *hash, nil
+}
+
+// Fetch will fetch the tags and branches the range names that are not in a clone, as tags are not cloned and only the
+// branches being scanned are. A revision that is neither is left for Heads to report.
+func (r *CommitRange) Fetch(clone *git.Repository, auth transport.AuthMethod) {
+	if r == nil {
+		return
+	}
+	for _, revision := range []string{r.Since, r.Until} {
+		if revision == "" {
+			continue
+		}
+		if _, err := resolveCommit(clone, revision); err == nil {
+			continue
+		}
+		for _, refSpec := range []string{"+refs/tags/%[1]s:refs/tags/%[1]s", "+refs/heads/%[1]s:refs/remotes/origin/%[1]s"} {
+			err := clone.Fetch(&git.FetchOptions{
+				RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf(refSpec, revision))},
+				Auth:     auth,
+			})
+			if err == nil || err == git.NoErrAlreadyUpToDate {
+				break
+			}
+		}
+	}
+}
+
+// Heads will give the heads the history of a clone is walked back from and the commits whose history is left out,
+// adding the range to those of the branches and the commits an incremental scan has already seen. A since or until
+// commit that is not in the repository is an error, as scanning the whole history in place of a range would be slow
+// and report what was meant to be left out.
+func (r *CommitRange) Heads(clone *git.Repository, heads []BranchHead, since []string) ([]BranchHead, []string, error) {
+	if r == nil {
+		return heads, since, nil
+	}
+	if r.Until != "" {
+		hash, err := resolveCommit(clone, r.Until)
+		if err != nil {
+			return nil, nil, err
+		}
+		heads = []BranchHead{{Name: r.Until, Hash: hash}}
+	}
+	if r.Since != "" {
+		hash, err := resolveCommit(clone, r.Since)
+		if err != nil {
+			return nil, nil, err
+		}
+		since = append(since, hash.String())
+	}
+	return heads, since, nil
+}
+
+// Filter will leave out the commits committed before the since time of the range
+func (r *CommitRange) Filter(history []*object.Commit) []*object.Commit {
+	if r == nil || r.SinceTime.IsZero() {
+		return history
+	}
+	var commits []*object.Commit
+	for _, c := range history {
+		if !c.Committer.When.Before(r.SinceTime) {
+			commits = append(commits, c)
+		}
+	}
+	return commits
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"wraith/version"
+)
+
+// These are the actions of findings made in the text of a commit rather than in one of its files
+const (
+	ActionCommitMessage = "Commit Message"
+	ActionGitNote       = "Git Note"
+)
+
+// NotesRefSpec fetches every notes ref of a remote, which a clone leaves out
+const NotesRefSpec = "+refs/notes/*:refs/notes/*"
+
+// auth is the ssh auth a configuration clones an ssh url with, or the basic or bearer auth of its token, or nil when it
+// has none
+func (c *CloneConfiguration) auth() transport.AuthMethod {
+	if c.Url != nil && isSSHURL(*c.Url) {
+		return c.sshAuth()
+	}
+	if c.Token == nil || *c.Token == "" {
+		return nil
+	}
+	if c.Bearer {
+		return &githttp.TokenAuth{Token: *c.Token}
+	}
+	userName := ""
+	if c.Username != nil {
+		userName = *c.Username
+	}
+	return &githttp.BasicAuth{Username: userName, Password: *c.Token}
+}
+
+// FetchNotes will fetch the git notes of the remote a repository was cloned from. A remote without notes is not an
+// error.
+func FetchNotes(repository *git.Repository, auth transport.AuthMethod) error {
+	err := repository.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{NotesRefSpec},
+		Auth:     auth,
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
+
+// GetNotes will return the text of the git notes of a repository by the commit they are attached to. A commit with
+// notes under more than one notes ref gets them all, one after the other.
+func GetNotes(repository *git.Repository) (map[plumbing.Hash]string, error) {
+	refs, err := repository.References()
+	if err != nil {
+		return nil, err
+	}
+
+	notes := map[plumbing.Hash]string{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !strings.HasPrefix(ref.Name().String(), "refs/notes/") || ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		commit, err := repository.CommitObject(ref.Hash())
+		if err != nil {
+			return err
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		return tree.Files().ForEach(func(f *object.File) error {
+			// notes are stored under the hash of their commit, split into directories once there are many of them
+			name := strings.Replace(f.Name, "/", "", -1)
+			if len(name) != 40 {
+				return nil
+			}
+			content, err := f.Contents()
+			if err != nil {
+				return err
+			}
+			hash := plumbing.NewHash(name)
+			notes[hash] += content
+			return nil
+		})
+	})
+	return notes, err
+}
+
+// scanText will match text that is not in a file, such as a commit message or an issue, against the signatures that
+// look at the content of files. The findings link to webURL, or to their commit when it is empty.
+func scanText(sess *Session, repo *Repository, text string, base Finding, webURL string) bool {
+	dirty := false
+	for _, finding := range matchText(sess, repo, text, base, webURL) {
+		if sess.AddFinding(finding) {
+			dirty = true
+			realTimeOutput(finding, sess)
+		}
+	}
+	return dirty
+}
+
+// matchText will return the findings of scanText without adding them to the session
+func matchText(sess *Session, repo *Repository, text string, base Finding, webURL string) []*Finding {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	what := "the " + strings.ToLower(base.Action) + " of " + base.CommitHash
+	if webURL != "" {
+		what = webURL
+	}
+	dir, err := ioutil.TempDir("", "wraith")
+	if err != nil {
+		sess.Out.Error("Unable to scan %s: %s\n", what, err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+
+	// the text is written out so the signatures match it the same way they match a file
+	fullFilePath := filepath.Join(dir, "COMMIT_TEXT")
+	if err := ioutil.WriteFile(fullFilePath, []byte(text), 0600); err != nil {
+		sess.Out.Error("Unable to scan %s: %s\n", what, err)
+		return nil
+	}
+	matchFile := newMatchFile(fullFilePath)
+
+	var findings []*Finding
+	for _, signature := range CurrentSignatures() {
+		if signature.Part() != PartContent {
+			continue
+		}
+		bMatched, matchMap := signature.ExtractMatch(matchFile, sess, nil)
+		if !bMatched {
+			continue
+		}
+
+		for k, v := range matchMap {
+			content := strings.SplitAfterN(k, "_", 2)[1]
+
+			finding := base
+			finding.Comment = Redact(content, sess.Redact)
+			finding.Description = signature.Description()
+			finding.LineNumber = strconv.Itoa(v)
+			finding.Score = signature.MatchLevel()
+			finding.Severity = signature.Severity()
+			finding.Confidence = signature.Confidence()
+			finding.Signatureid = signature.Signatureid()
+			finding.SignaturesVersion = sess.SignatureVersion
+			finding.WraithVersion = version.AppVersion()
+			finding.secret = content
+			finding.setLine(fullFilePath, nil, v, content, sess)
+
+			finding.Initialize(sess.ScanType)
+			finding.setRepositoryMetadata(repo)
+			// there is no file to link to, the commit is the closest thing unless the text is on a page of its own
+			if webURL != "" {
+				finding.FileUrl = webURL
+				finding.CommitUrl = ""
+			} else {
+				finding.FileUrl = finding.CommitUrl
+			}
+			findings = append(findings, &finding)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,466 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// These are the actions of findings made in confluence
+const (
+	ActionConfluencePage       = "Confluence Page"
+	ActionConfluenceAttachment = "Confluence Attachment"
+)
+
+// confluencePageLimit is how many pages are asked for in each page of a list, each with its body
+const confluencePageLimit = 25
+
+// confluenceAttachmentLimit is how many attachments of a page are asked for at once
+const confluenceAttachmentLimit = 100
+
+// confluencePageFile is the name the text of a page body is saved under to be scanned. It is not the name of anything
+// in confluence, so it is plain text that the signatures matching on file names leave alone.
+const confluencePageFile = "page.txt"
+
+// ConfluenceLinks are the links of an object of the confluence api. They are relative to the base url of the site,
+// which on confluence cloud includes /wiki.
+type ConfluenceLinks struct {
+	WebUI    string `json:"webui"`
+	Download string `json:"download"`
+	Next     string `json:"next"`
+}
+
+// ConfluenceVersion is a version of a page or attachment and who made it
+type ConfluenceVersion struct {
+	Number int    `json:"number"`
+	When   string `json:"when"`
+	By     struct {
+		DisplayName string `json:"displayName"`
+		Email       string `json:"email"`
+	} `json:"by"`
+}
+
+// ConfluenceSpace is a space of a confluence site
+type ConfluenceSpace struct {
+	Key   string          `json:"key"`
+	Name  string          `json:"name"`
+	Links ConfluenceLinks `json:"_links"`
+}
+
+// ConfluencePage is a page in a space, with its body in the storage format, the xhtml confluence keeps pages in
+type ConfluencePage struct {
+	ID      string            `json:"id"`
+	Title   string            `json:"title"`
+	Version ConfluenceVersion `json:"version"`
+	Body    struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links ConfluenceLinks `json:"_links"`
+}
+
+// ConfluenceAttachment is a file attached to a page
+type ConfluenceAttachment struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Version    ConfluenceVersion `json:"version"`
+	Extensions struct {
+		MediaType string `json:"mediaType"`
+		FileSize  int64  `json:"fileSize"`
+	} `json:"extensions"`
+	Links ConfluenceLinks `json:"_links"`
+}
+
+// ConfluenceClient reads the spaces, pages and attachments of a confluence site through its rest api. It signs in with
+// an email and api token on confluence cloud, with a personal access token on confluence server and data center, or
+// not at all for spaces anyone can read.
+type ConfluenceClient struct {
+	URL      string
+	Username string
+	Token    string
+	client   *http.Client
+}
+
+// NewConfluenceClient will create a client for the confluence site at baseURL
+func NewConfluenceClient(baseURL, username, token string) (*ConfluenceClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", baseURL)
+	}
+	if username != "" && token == "" {
+		return nil, errors.New("an api token is needed along with the confluence username")
+	}
+	return &ConfluenceClient{
+		URL:      strings.TrimSuffix(u.String(), "/"),
+		Username: username,
+		Token:    token,
+		client:   NewHTTPClient(5 * time.Minute),
+	}, nil
+}
+
+// Site is the host of the confluence site, which its findings are reported under
+func (c *ConfluenceClient) Site() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return c.URL
+	}
+	return u.Host
+}
+
+// get will send a request for a path relative to the base url of the site and return the response when it succeeds
+func (c *ConfluenceClient) get(path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	switch {
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Token)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Message == "" {
+		body.Message = strings.TrimSpace(string(data))
+	}
+	return nil, fmt.Errorf("confluence returned %s for %s: %s", resp.Status, strings.SplitN(path, "?", 2)[0], body.Message)
+}
+
+// getJSON will send a request for a path and decode the json it returns into out
+func (c *ConfluenceClient) getJSON(path string, out interface{}) error {
+	resp, err := c.get(path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// GetSpace will read a space by its key
+func (c *ConfluenceClient) GetSpace(key string) (*ConfluenceSpace, error) {
+	space := &ConfluenceSpace{}
+	if err := c.getJSON("/rest/api/space/"+url.PathEscape(key), space); err != nil {
+		return nil, err
+	}
+	return space, nil
+}
+
+// ListPages will list every page in a space with its body and latest version. Each list links to the next, which on
+// confluence cloud is paged by a cursor rather than by where it starts.
+func (c *ConfluenceClient) ListPages(key string) ([]ConfluencePage, error) {
+	query := url.Values{
+		"spaceKey": {key},
+		"type":     {"page"},
+		"expand":   {"body.storage,version"},
+		"limit":    {fmt.Sprint(confluencePageLimit)},
+	}
+	var pages []ConfluencePage
+	path := "/rest/api/content?" + query.Encode()
+	for path != "" {
+		var list struct {
+			Results []ConfluencePage `json:"results"`
+			Links   ConfluenceLinks  `json:"_links"`
+		}
+		if err := c.getJSON(path, &list); err != nil {
+			return nil, err
+		}
+		pages = append(pages, list.Results...)
+		path = list.Links.Next
+	}
+	return pages, nil
+}
+
+// ListAttachments will list the latest version of every file attached to a page
+func (c *ConfluenceClient) ListAttachments(pageID string) ([]ConfluenceAttachment, error) {
+	query := url.Values{"expand": {"version"}, "limit": {fmt.Sprint(confluenceAttachmentLimit)}}
+	var attachments []ConfluenceAttachment
+	path := "/rest/api/content/" + url.PathEscape(pageID) + "/child/attachment?" + query.Encode()
+	for path != "" {
+		var list struct {
+			Results []ConfluenceAttachment `json:"results"`
+			Links   ConfluenceLinks        `json:"_links"`
+		}
+		if err := c.getJSON(path, &list); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, list.Results...)
+		path = list.Links.Next
+	}
+	return attachments, nil
+}
+
+// Download will copy the content of an attachment to w, failing once more than limit bytes have been copied
+func (c *ConfluenceClient) Download(attachment ConfluenceAttachment, w io.Writer, limit int64) error {
+	resp, err := c.get(attachment.Links.Download)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("%s is larger than %d bytes", attachment.Title, limit)
+	}
+	return nil
+}
+
+// confluenceLineTags are the tags of the storage format that end a line of text, the cells of a table are kept apart
+// by a tab rather than run together
+var confluenceLineTags = map[string]string{
+	"br": "\n", "p": "\n", "div": "\n", "li": "\n", "tr": "\n", "pre": "\n", "blockquote": "\n",
+	"h1": "\n", "h2": "\n", "h3": "\n", "h4": "\n", "h5": "\n", "h6": "\n",
+	"ac:plain-text-body": "\n", "td": "\t", "th": "\t",
+}
+
+// ConfluenceText will turn the body of a page in the storage format into the text it shows, so that secrets are matched
+// as they read rather than with the markup and entities around them. The contents of code blocks and other macros are
+// kept as they are.
+func ConfluenceText(storage string) string {
+	const cdataStart, cdataEnd = "<![CDATA[", "]]>"
+	var b strings.Builder
+	for storage != "" {
+		switch {
+		case strings.HasPrefix(storage, cdataStart):
+			storage = storage[len(cdataStart):]
+			end := strings.Index(storage, cdataEnd)
+			if end == -1 {
+				end = len(storage)
+			}
+			b.WriteString(storage[:end])
+			storage = strings.TrimPrefix(storage[end:], cdataEnd)
+		case storage[0] == '<':
+			end := strings.IndexByte(storage, '>')
+			if end == -1 {
+				b.WriteString(html.UnescapeString(storage))
+				return b.String()
+			}
+			// a line ends where its tag is closed, or at a tag such as <br/> that is never opened
+			tag := storage[1:end]
+			fields := strings.Fields(strings.Trim(tag, "/"))
+			if len(fields) > 0 && (strings.HasPrefix(tag, "/") || strings.HasSuffix(tag, "/")) {
+				b.WriteString(confluenceLineTags[strings.ToLower(fields[0])])
+			}
+			storage = storage[end+1:]
+		default:
+			end := strings.IndexByte(storage, '<')
+			if end == -1 {
+				end = len(storage)
+			}
+			// a non-breaking space is a space to the signatures, which do not match it with \s
+			b.WriteString(strings.Replace(html.UnescapeString(storage[:end]), "\u00a0", " ", -1))
+			storage = storage[end:]
+		}
+	}
+	return b.String()
+}
+
+// ScanConfluence will scan the pages, and the attachments of the pages, of every space of the session. Each space is
+// scanned as a repository of the site.
+func ScanConfluence(sess *Session) error {
+	if sess.ConfluenceURL == "" {
+		return errors.New("you must set the confluence-url of the site to scan")
+	}
+	if len(sess.ConfluenceSpaces) == 0 {
+		return errors.New("you must set at least one confluence space to scan")
+	}
+	client, err := NewConfluenceClient(sess.ConfluenceURL, sess.ConfluenceUsername, sess.ConfluenceToken)
+	if err != nil {
+		return err
+	}
+	if client.Token == "" {
+		sess.Out.Warn("No confluence token was given, only spaces anyone can read can be scanned\n")
+	}
+
+	dir, err := ioutil.TempDir("", "wraith-confluence")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	sess.SetStatus(StatusAnalyzing)
+	scanned := 0
+	for _, key := range sess.ConfluenceSpaces {
+		if sess.Interrupted() {
+			break
+		}
+		if err := scanConfluenceSpace(client, key, dir, sess); err != nil {
+			sess.Out.Error("Unable to scan the %s space: %s\n", key, err)
+			continue
+		}
+		scanned++
+	}
+
+	if scanned == 0 && !sess.Interrupted() {
+		return errors.New("no spaces could be scanned")
+	}
+	return nil
+}
+
+// scanConfluenceSpace will list the pages of a space and scan each of them, with as many pages at a time as there are
+// threads
+func scanConfluenceSpace(client *ConfluenceClient, key, dir string, sess *Session) error {
+	sess.Out.Important("Listing the pages of the %s space\n", key)
+	var space *ConfluenceSpace
+	var pages []ConfluencePage
+	_, err := sess.withRetries(sess.Out, "list the pages of the "+key+" space", func() (err error) {
+		if space, err = client.GetSpace(key); err != nil {
+			return err
+		}
+		pages, err = client.ListPages(space.Key)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	sess.Stats.IncrementTargets()
+	sess.Stats.IncrementRepositoriesTotal()
+	sess.Out.Info(" Retrieved %d %s from the %s space\n", len(pages), Pluralize(len(pages), "page", "pages"), space.Key)
+
+	base := Finding{
+		AtHead:          true,
+		RepositoryOwner: client.Site(),
+		RepositoryName:  space.Key,
+		RepositoryUrl:   client.URL + space.Links.WebUI,
+	}
+	sem := make(chan struct{}, sess.Threads)
+	var wg sync.WaitGroup
+	for _, page := range pages {
+		if sess.Interrupted() {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(page ConfluencePage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			scanConfluencePage(client, page, base, filepath.Join(dir, page.ID), sess)
+		}(page)
+	}
+	wg.Wait()
+
+	sess.Stats.IncrementRepositoriesScanned()
+	return nil
+}
+
+// scanConfluencePage will scan the text of the body of a page and then each of its attachments. The findings are
+// reported with the title of the page as their path, its version as their commit and the last person to edit it as
+// their author.
+func scanConfluencePage(client *ConfluenceClient, page ConfluencePage, base Finding, dir string, sess *Session) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		sess.Out.Error("Unable to scan the %s page: %s\n", page.Title, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	pageURL := client.URL + page.Links.WebUI
+	sess.Stats.IncrementFilesTotal()
+	fullFilePath := filepath.Join(dir, confluencePageFile)
+	if err := ioutil.WriteFile(fullFilePath, []byte(ConfluenceText(page.Body.Storage.Value)), 0600); err != nil {
+		sess.Out.Error("Unable to scan the %s page: %s\n", page.Title, err)
+		return
+	}
+	finding := base
+	finding.Action = ActionConfluencePage
+	finding.FilePath = page.Title
+	finding.setConfluenceVersion(page.Version)
+	finding.webURL = pageURL
+	scanFile(fullFilePath, finding, sess)
+
+	if !sess.ConfluenceAttachments {
+		return
+	}
+	attachments, err := client.ListAttachments(page.ID)
+	if err != nil {
+		sess.Out.Error("Unable to list the attachments of the %s page: %s\n", page.Title, err)
+		return
+	}
+	maxSize := sess.MaxFileSize * 1024 * 1024
+	for i, attachment := range attachments {
+		if sess.Interrupted() {
+			return
+		}
+		sess.Stats.IncrementFilesTotal()
+		path := page.Title + "/" + attachment.Title
+
+		matchFile := newMatchFile(attachment.Title)
+		if matchFile.isSkippable(sess) {
+			sess.Out.Debug("%s is listed as skippable and is being ignored\n", path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if attachment.Extensions.FileSize > maxSize {
+			sess.Out.Debug("%s is too large and being ignored\n", path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		// the attachment is saved under its own name so the signatures that match on file names still work
+		fullFilePath := filepath.Join(dir, fmt.Sprint(i), filepath.Base(attachment.Title))
+		if err := downloadConfluenceAttachment(client, attachment, fullFilePath, maxSize); err != nil {
+			sess.Out.Error("Unable to scan %s: %s\n", path, err)
+			continue
+		}
+		finding := base
+		finding.Action = ActionConfluenceAttachment
+		finding.FilePath = path
+		finding.setConfluenceVersion(attachment.Version)
+		finding.webURL = pageURL
+		scanFile(fullFilePath, finding, sess)
+		os.RemoveAll(filepath.Dir(fullFilePath))
+	}
+}
+
+// downloadConfluenceAttachment will save an attachment to a file
+func downloadConfluenceAttachment(client *ConfluenceClient, attachment ConfluenceAttachment, fullFilePath string, maxSize int64) error {
+	if err := os.MkdirAll(filepath.Dir(fullFilePath), 0700); err != nil {
+		return err
+	}
+	fh, err := os.OpenFile(fullFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	return client.Download(attachment, fh, maxSize)
+}
+
+// setConfluenceVersion will attribute a finding to the version of the page or attachment it was found in, and to the
+// person who made that version
+func (f *Finding) setConfluenceVersion(version ConfluenceVersion) {
+	f.CommitHash = fmt.Sprint(version.Number)
+	f.CommitAuthor = version.By.DisplayName
+	f.CommitAuthorName = version.By.DisplayName
+	f.CommitAuthorEmail = version.By.Email
+	f.CommitDate = version.When
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// ContextLine is a line of the file a finding is in, the matched line or one of those around it
+type ContextLine struct {
+	LineNumber int
+	Content    string
+}
+
+// matchedLines will return the lines of the content a secret was found in, from the file on disk or otherwise from
+// the change it was found in, as the line number can belong to either. It is nil when the secret is not on that line
+// of either.
+func matchedLines(path string, change *object.Change, lineNumber int, secret string) []string {
+	linesOf := func(content string) []string {
+		lines := strings.Split(content, "\n")
+		if lineNumber < 1 || lineNumber > len(lines) || !strings.Contains(lines[lineNumber-1], secret) {
+			return nil
+		}
+		return lines
+	}
+
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if lines := linesOf(string(data)); lines != nil {
+			return lines
+		}
+	}
+	if change != nil {
+		if content, err := GetChangeContent(change); err == nil {
+			return linesOf(content)
+		}
+	}
+	return nil
+}
+
+// setLine will find the line a finding's secret is on, in the file on disk or the change it was found in, and set it
+// along with the lines of context around it
+func (f *Finding) setLine(path string, change *object.Change, lineNumber int, secret string, sess *Session) {
+	f.setContext(matchedLines(path, change, lineNumber, secret), lineNumber, sess)
+}
+
+// setContext will set the line a finding is on from the lines of its file, along with the context lines of the
+// session before and after it. Secrets in the context are redacted the same way the finding is, anything else hidden
+// only has to be given as well, such as the encoded text a secret was decoded from.
+func (f *Finding) setContext(lines []string, lineNumber int, sess *Session, hidden ...string) {
+	f.line = ""
+	f.Context = nil
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return
+	}
+	f.line = lines[lineNumber-1]
+
+	first, last := lineNumber-sess.ContextLines, lineNumber+sess.ContextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	// a file that ends with a new line does not have an empty line after it
+	if last == len(lines) && last > lineNumber && lines[last-1] == "" {
+		last--
+	}
+	for n := first; n <= last; n++ {
+		f.Context = append(f.Context, ContextLine{
+			LineNumber: n,
+			Content:    redactLine(strings.TrimRight(lines[n-1], "\r"), sess, append(hidden, f.secret)...),
+		})
+	}
+}
+
+// redactLine will redact the given secrets in a line of context, along with anything else in it that a content
+// signature matches, as the lines around a secret often hold more of them
+func redactLine(line string, sess *Session, secrets ...string) string {
+	if sess.Redact == RedactNone {
+		return line
+	}
+	for _, sig := range CurrentSignatures() {
+		if s, ok := sig.(PatternSignature); ok && s.part == PartContent {
+			line = s.match.ReplaceAllStringFunc(line, func(m string) string { return Redact(m, sess.Redact) })
+		}
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			line = strings.ReplaceAll(line, secret, Redact(secret, sess.Redact))
+		}
+	}
+	return line
+}
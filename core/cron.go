@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the shorthands a cron expression can be given as in place of its five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronMonths and cronDays are the names that can be used in the month and day of the week fields
+var (
+	cronMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDays   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// cronField describes one of the five fields of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for the values starting at min, such as jan for 1
+	// sunday is set for the day of the week, where 7 can be used for sunday as well as 0, so ranges such as 1-7 and
+	// mon-sun end on it
+	sunday bool
+}
+
+// cronFields are the minute, hour, day of the month, month and day of the week fields, in that order
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of the month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: cronMonths},
+	{name: "day of the week", min: 0, max: 7, names: cronDays, sunday: true},
+}
+
+// CronSchedule is a parsed cron expression, with a bit set for each value of a field that matches
+type CronSchedule struct {
+	Expression string
+
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set when the day fields are *, as a day then only has to match the other one
+	domAny, dowAny bool
+}
+
+// ParseCron will parse a standard five field cron expression, such as "30 2 * * mon-fri", or one of the @daily style
+// macros. Fields can be lists, ranges and steps, and months and days of the week can be given by their names.
+func ParseCron(expr string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%s is not a cron expression, it should have 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("the %s of %s is not valid: %s", cronFields[i].name, expr, err)
+		}
+		bits[i] = b
+	}
+
+	return &CronSchedule{
+		Expression: expr,
+		minute:     bits[0],
+		hour:       bits[1],
+		dom:        bits[2],
+		month:      bits[3],
+		dow:        bits[4],
+		domAny:     fields[2] == "*" || fields[2] == "?",
+		dowAny:     fields[4] == "*" || fields[4] == "?",
+	}, nil
+}
+
+// parseCronField will parse a comma separated list of values, ranges and steps into a bit set
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s is not a step", part[i+1:])
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			ends := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = cronValue(ends[0], f); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(ends[1], f); err != nil {
+				return 0, err
+			}
+			if f.sunday && hi == 0 && lo > 0 {
+				hi = 7
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s starts after it ends", rng)
+			}
+		default:
+			v, err := cronValue(rng, f)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// a single value with a step, such as 5/15, runs from that value to the end of the field
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	if f.sunday && bits&(1<<7) != 0 {
+		bits = bits&^(1<<7) | 1
+	}
+	return bits, nil
+}
+
+// cronValue will parse a number or name in a field
+func cronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a number", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d is not from %d to %d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// matchesDay will check the day fields, where a day matches either of them when both are given
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next is the first time after t that the schedule matches, in the location of t. It is the zero time when the
+// schedule never matches, such as on the 31st of february.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestCron(t *testing.T) {
+
+	Convey("Given a time on a wednesday afternoon", t, func() {
+		now := time.Date(2024, time.May, 15, 14, 7, 30, 0, time.UTC)
+		next := func(expr string) string {
+			c, err := core.ParseCron(expr)
+			So(err, ShouldBeNil)
+			return c.Next(now).Format("Mon 2006-01-02 15:04")
+		}
+
+		Convey("Each field should narrow down the next time it matches", func() {
+			So(next("* * * * *"), ShouldEqual, "Wed 2024-05-15 14:08")
+			So(next("*/15 * * * *"), ShouldEqual, "Wed 2024-05-15 14:15")
+			So(next("30 2 * * *"), ShouldEqual, "Thu 2024-05-16 02:30")
+			So(next("0 9-17/4 * * *"), ShouldEqual, "Wed 2024-05-15 17:00")
+			So(next("0 0 1 * *"), ShouldEqual, "Sat 2024-06-01 00:00")
+			So(next("0 6 * feb,mar *"), ShouldEqual, "Sat 2025-02-01 06:00")
+		})
+
+		Convey("Days of the week should be given by number or name, with 7 as sunday", func() {
+			So(next("0 3 * * 0"), ShouldEqual, "Sun 2024-05-19 03:00")
+			So(next("0 3 * * 7"), ShouldEqual, "Sun 2024-05-19 03:00")
+			So(next("0 3 * * mon-fri"), ShouldEqual, "Thu 2024-05-16 03:00")
+			So(next("0 3 * * 6-7"), ShouldEqual, "Sat 2024-05-18 03:00")
+			So(next("0 3 * * fri-sun"), ShouldEqual, "Fri 2024-05-17 03:00")
+			So(next("0 3 16 * mon-sun"), ShouldEqual, "Thu 2024-05-16 03:00")
+			So(next("0 3 * * 1-7/3"), ShouldEqual, "Thu 2024-05-16 03:00")
+			So(next("0 3 * * 7-7"), ShouldEqual, "Sun 2024-05-19 03:00")
+		})
+
+		Convey("A day should match when either day field does once both are given", func() {
+			So(next("0 0 20 * mon"), ShouldEqual, "Mon 2024-05-20 00:00")
+			So(next("0 0 17 * mon"), ShouldEqual, "Fri 2024-05-17 00:00")
+		})
+
+		Convey("Macros should stand for the expressions they are short for", func() {
+			So(next("@daily"), ShouldEqual, "Thu 2024-05-16 00:00")
+			So(next("@weekly"), ShouldEqual, "Sun 2024-05-19 00:00")
+			So(next("@hourly"), ShouldEqual, "Wed 2024-05-15 15:00")
+		})
+
+		Convey("A schedule that never matches should have no next time", func() {
+			c, err := core.ParseCron("0 0 31 2 *")
+			So(err, ShouldBeNil)
+			So(c.Next(now).IsZero(), ShouldBeTrue)
+		})
+	})
+
+	Convey("Expressions that are not cron should fail", t, func() {
+		for expr, msg := range map[string]string{
+			"* * * *":         "should have 5 fields",
+			"60 * * * *":      "the minute of 60 * * * * is not valid: 60 is not from 0 to 59",
+			"* * * foo *":     "foo is not a number",
+			"5-1 * * * *":     "5-1 starts after it ends",
+			"* * * * 8":       "8 is not from 0 to 7",
+			"* * * * sat-fri": "sat-fri starts after it ends",
+			"*/0 * * * *":     "0 is not a step",
+			"@fortnightly":    "should have 5 fields",
+		} {
+			_, err := core.ParseCron(expr)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, msg)
+		}
+	})
+}
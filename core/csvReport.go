@@ -0,0 +1,69 @@
+package core
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// CSVHeader is the first row of a csv report, naming its columns
+var CSVHeader = []string{
+	"repository", "file_path", "line_number", "commit_hash", "commit_author",
+	"signature_id", "description", "severity", "match", "fingerprint", "confidence", "encoding",
+	"commit_author_email", "commit_date", "committer",
+}
+
+// csvFormulaPrefixes start a cell that a spreadsheet would run as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvCell will make a value safe to open in a spreadsheet by quoting a value it would otherwise run as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// NewCSVRow will convert a finding into a row of a csv report. The match is the comment of the finding, which is
+// redacted the same way as in every other output.
+func NewCSVRow(f *Finding) []string {
+	repository := f.RepositoryName
+	if f.RepositoryOwner != "" && f.RepositoryOwner != "not-a-repo" {
+		repository = f.RepositoryOwner + "/" + f.RepositoryName
+	}
+	row := []string{
+		repository, f.FilePath, f.LineNumber, f.CommitHash, f.CommitAuthor,
+		f.Signatureid, f.Description, FindingSeverity(f), f.Comment, f.Fingerprint, FindingConfidence(f), f.Encoding,
+		f.CommitAuthorEmail, f.CommitDate, f.Committer(),
+	}
+	for i, value := range row {
+		row[i] = csvCell(value)
+	}
+	return row
+}
+
+// WriteCSVReport will write a header and then one row per finding, for triaging findings in a spreadsheet
+func WriteCSVReport(location string, s *Session) error {
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	w := csv.NewWriter(fh)
+	if err := w.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range s.Findings {
+		if err := w.Write(NewCSVRow(f)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return fh.Close()
+}
@@ -0,0 +1,359 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	// the sqlite driver needs cgo, without it opening a database fails with an error that says so
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// databaseSchema creates the tables sessions are kept in, it is safe to run against an existing database
+const databaseSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	scan_type   TEXT NOT NULL,
+	scope       TEXT NOT NULL,
+	version     TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT,
+	stats       TEXT
+);
+CREATE TABLE IF NOT EXISTS targets (
+	session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	id         INTEGER NOT NULL,
+	login      TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	data       TEXT NOT NULL,
+	PRIMARY KEY (session_id, id)
+);
+CREATE TABLE IF NOT EXISTS findings (
+	session_id       INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	seq              INTEGER NOT NULL,
+	secret_id        TEXT NOT NULL,
+	description      TEXT NOT NULL,
+	file_path        TEXT NOT NULL,
+	repository_owner TEXT NOT NULL,
+	repository_name  TEXT NOT NULL,
+	score            INTEGER NOT NULL,
+	data             TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+);
+CREATE INDEX IF NOT EXISTS findings_secret_id ON findings (secret_id);
+CREATE TABLE IF NOT EXISTS triage (
+	fingerprint TEXT PRIMARY KEY,
+	status      TEXT NOT NULL,
+	note        TEXT NOT NULL,
+	user        TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+`
+
+// databases holds the open database for each path so sessions in the same process, such as those started over
+// gRPC, share one connection rather than each opening their own
+var databases = struct {
+	sync.Mutex
+	open map[string]*Database
+}{open: map[string]*Database{}}
+
+// Database keeps sessions, their targets and their findings in sqlite so they outlive the process that ran the scan
+type Database struct {
+	db *sql.DB
+}
+
+// StoredSession is a session as it is kept in the database
+type StoredSession struct {
+	ID         int64           `json:"id"`
+	ScanType   string          `json:"scan_type"`
+	Scope      string          `json:"scope"`
+	Version    string          `json:"version"`
+	Status     string          `json:"status"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Findings   int             `json:"findings"`
+	Stats      json.RawMessage `json:"stats,omitempty"`
+}
+
+// OpenDatabase will open the sqlite database at path, creating it and its tables if they do not exist yet
+func OpenDatabase(path string) (*Database, error) {
+	databases.Lock()
+	defer databases.Unlock()
+
+	if d, ok := databases.open[path]; ok {
+		return d, nil
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	// sqlite only allows one writer at a time, a single connection keeps the threads of a scan from tripping over it
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(databaseSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	d := &Database{db: db}
+	databases.open[path] = d
+	return d, nil
+}
+
+// StartSession will add a new session to the database and return its id. The session stays initializing until it is
+// finished, so one that was interrupted can be told apart.
+func (d *Database) StartSession(s *Session) (int64, error) {
+	res, err := d.db.Exec(`INSERT INTO sessions (scan_type, scope, version, status, started_at) VALUES (?, ?, ?, ?, ?)`,
+		s.ScanType, s.Scope(), s.Version, StatusInitializing, s.Stats.StartedAt.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// SaveTarget will add a target to a session, or update it if it is already there
+func (d *Database) SaveTarget(sessionID int64, target *Owner) error {
+	return saveTarget(d.db, sessionID, target)
+}
+
+// SaveFinding will add a finding to a session at its position in the session, or update it if it is already there
+func (d *Database) SaveFinding(sessionID int64, seq int, f *Finding) error {
+	return saveFinding(d.db, sessionID, seq, f)
+}
+
+// FinishSession will record the final stats, targets and findings of a session. Findings are saved again as they can
+// still change after they are found, such as being marked as no longer at HEAD.
+func (d *Database) FinishSession(sessionID int64, s *Session) error {
+	s.Lock()
+	targets := append([]*Owner(nil), s.Targets...)
+	findings := append([]*Finding(nil), s.Findings...)
+	s.Unlock()
+
+	s.Stats.Lock()
+	stats, err := json.Marshal(s.Stats)
+	status := s.Stats.Status
+	finishedAt := s.Stats.FinishedAt
+	s.Stats.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE sessions SET status = ?, finished_at = ?, stats = ? WHERE id = ?`,
+		status, finishedAt.UTC().Format(time.RFC3339Nano), string(stats), sessionID); err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if err := saveTarget(tx, sessionID, t); err != nil {
+			return err
+		}
+	}
+	for i, f := range findings {
+		if err := saveFinding(tx, sessionID, i, f); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// execer is implemented by both a database and a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveTarget(db execer, sessionID int64, target *Owner) error {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO targets (session_id, id, login, type, data) VALUES (?, ?, ?, ?, ?)`,
+		sessionID, *target.ID, *target.Login, *target.Type, string(data))
+	return err
+}
+
+func saveFinding(db execer, sessionID int64, seq int, f *Finding) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO findings (session_id, seq, secret_id, description, file_path, repository_owner, repository_name, score, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sessionID, seq, f.SecretID, f.Description, f.FilePath, f.RepositoryOwner, f.RepositoryName, f.Score, string(data))
+	return err
+}
+
+// Sessions returns every session in the database, the most recent first
+func (d *Database) Sessions() ([]StoredSession, error) {
+	rows, err := d.db.Query(`SELECT s.id, s.scan_type, s.scope, s.version, s.status, s.started_at, s.finished_at, s.stats,
+		(SELECT COUNT(*) FROM findings f WHERE f.session_id = s.id) FROM sessions s ORDER BY s.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []StoredSession{}
+	for rows.Next() {
+		ss, err := scanStoredSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *ss)
+	}
+	return sessions, rows.Err()
+}
+
+// Session returns a single session, or nil if there is no session with the id
+func (d *Database) Session(id int64) (*StoredSession, error) {
+	row := d.db.QueryRow(`SELECT s.id, s.scan_type, s.scope, s.version, s.status, s.started_at, s.finished_at, s.stats,
+		(SELECT COUNT(*) FROM findings f WHERE f.session_id = s.id) FROM sessions s WHERE s.id = ?`, id)
+	ss, err := scanStoredSession(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ss, err
+}
+
+// Findings returns the findings of a session in the order they were found
+func (d *Database) Findings(sessionID int64) ([]*Finding, error) {
+	rows, err := d.db.Query(`SELECT data FROM findings WHERE session_id = ? ORDER BY seq`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := []*Finding{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		f := &Finding{}
+		if err := json.Unmarshal([]byte(data), f); err != nil {
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
+
+// Targets returns the targets of a session
+func (d *Database) Targets(sessionID int64) ([]*Owner, error) {
+	rows, err := d.db.Query(`SELECT data FROM targets WHERE session_id = ? ORDER BY login`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := []*Owner{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		t := &Owner{}
+		if err := json.Unmarshal([]byte(data), t); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// SaveTriage will record the triage of a finding by its fingerprint, a triage without a status clears it
+func (d *Database) SaveTriage(fingerprint string, t *Triage) error {
+	if t.Status == "" {
+		_, err := d.db.Exec(`DELETE FROM triage WHERE fingerprint = ?`, fingerprint)
+		return err
+	}
+	_, err := d.db.Exec(`INSERT OR REPLACE INTO triage (fingerprint, status, note, user, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		fingerprint, t.Status, t.Note, t.User, t.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+// Triage returns the triage of every finding that has been triaged, by fingerprint
+func (d *Database) Triage() (map[string]*Triage, error) {
+	rows, err := d.db.Query(`SELECT fingerprint, status, note, user, updated_at FROM triage`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	triage := map[string]*Triage{}
+	for rows.Next() {
+		var fingerprint, updatedAt string
+		t := &Triage{}
+		if err := rows.Scan(&fingerprint, &t.Status, &t.Note, &t.User, &updatedAt); err != nil {
+			return nil, err
+		}
+		if t.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			return nil, err
+		}
+		triage[fingerprint] = t
+	}
+	return triage, rows.Err()
+}
+
+// scanStoredSession will read a session from a row selected by Sessions or Session
+func scanStoredSession(row interface{ Scan(...interface{}) error }) (*StoredSession, error) {
+	var ss StoredSession
+	var startedAt string
+	var finishedAt, stats sql.NullString
+	if err := row.Scan(&ss.ID, &ss.ScanType, &ss.Scope, &ss.Version, &ss.Status, &startedAt, &finishedAt, &stats, &ss.Findings); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if ss.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		t, err := time.Parse(time.RFC3339Nano, finishedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		ss.FinishedAt = &t
+	}
+	if stats.Valid {
+		ss.Stats = json.RawMessage(stats.String)
+	}
+	return &ss, nil
+}
+
+// recordTarget will save a target of the session to the database, if one has been configured
+func (s *Session) recordTarget(target *Owner) {
+	if s.DB == nil {
+		return
+	}
+	if err := s.DB.SaveTarget(s.DBSessionID, target); err != nil {
+		s.Out.Error("Failed to save target %s to the database: %s\n", *target.Login, err)
+	}
+}
+
+// recordFinding will save a finding of the session to the database, if one has been configured
+func (s *Session) recordFinding(seq int, f *Finding) {
+	if s.DB == nil {
+		return
+	}
+	if err := s.DB.SaveFinding(s.DBSessionID, seq, f); err != nil {
+		s.Out.Error("Failed to save a finding in %s to the database: %s\n", f.FilePath, err)
+	}
+}
+
+// FinishDatabase will record the final state of the session in the database, if one has been configured
+func (s *Session) FinishDatabase() {
+	if s.DB == nil {
+		return
+	}
+	if err := s.DB.FinishSession(s.DBSessionID, s); err != nil {
+		s.Out.Error("Failed to save the session to %s: %s\n", s.DBPath, err)
+		return
+	}
+	s.Out.Important("Session %d saved to %s\n", s.DBSessionID, s.DBPath)
+}
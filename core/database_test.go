@@ -0,0 +1,106 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestDatabase(t *testing.T) {
+
+	Convey("Given a database", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-db")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		db, err := core.OpenDatabase(filepath.Join(dir, "wraith.db"))
+		So(err, ShouldBeNil)
+
+		started := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			ScanType:      "github",
+			GithubTargets: []string{"acme"},
+			Version:       "0.0.1",
+			Stats:         &core.Stats{StartedAt: started, Status: core.StatusInitializing},
+		}
+
+		id, err := db.StartSession(sess)
+		So(err, ShouldBeNil)
+
+		Convey("A session that has not finished should still be initializing", func() {
+			ss, err := db.Session(id)
+			So(err, ShouldBeNil)
+			So(ss.Status, ShouldEqual, core.StatusInitializing)
+			So(ss.Scope, ShouldEqual, "github:acme")
+			So(ss.StartedAt.Equal(started), ShouldBeTrue)
+			So(ss.FinishedAt, ShouldBeNil)
+		})
+
+		Convey("Findings saved as they are found should be kept", func() {
+			So(db.SaveFinding(id, 0, &core.Finding{SecretID: "aaa", FilePath: "config.yml"}), ShouldBeNil)
+			So(db.SaveFinding(id, 1, &core.Finding{SecretID: "bbb", FilePath: ".env"}), ShouldBeNil)
+
+			findings, err := db.Findings(id)
+			So(err, ShouldBeNil)
+			So(findings, ShouldHaveLength, 2)
+			So(findings[0].FilePath, ShouldEqual, "config.yml")
+			So(findings[1].SecretID, ShouldEqual, "bbb")
+		})
+
+		Convey("When the session finishes", func() {
+			login, ownerID, kind := "acme", int64(7), core.TargetTypeOrganization
+			sess.Targets = []*core.Owner{{Login: &login, ID: &ownerID, Type: &kind}}
+			sess.Findings = []*core.Finding{
+				{SecretID: "aaa", FilePath: "config.yml", AtHead: true},
+				{SecretID: "bbb", FilePath: ".env"},
+			}
+			So(db.SaveFinding(id, 0, &core.Finding{SecretID: "aaa", FilePath: "config.yml"}), ShouldBeNil)
+
+			sess.Stats.Status = core.StatusFinished
+			sess.Stats.FinishedAt = started.Add(time.Minute)
+			sess.Stats.FindingsTotal = 2
+			So(db.FinishSession(id, sess), ShouldBeNil)
+
+			Convey("Its stats, targets and final findings should be saved", func() {
+				ss, err := db.Session(id)
+				So(err, ShouldBeNil)
+				So(ss.Status, ShouldEqual, core.StatusFinished)
+				So(ss.FinishedAt.Equal(started.Add(time.Minute)), ShouldBeTrue)
+				So(ss.Findings, ShouldEqual, 2)
+				So(string(ss.Stats), ShouldContainSubstring, `"FindingsTotal":2`)
+
+				targets, err := db.Targets(id)
+				So(err, ShouldBeNil)
+				So(targets, ShouldHaveLength, 1)
+				So(*targets[0].Login, ShouldEqual, "acme")
+
+				findings, err := db.Findings(id)
+				So(err, ShouldBeNil)
+				So(findings, ShouldHaveLength, 2)
+				So(findings[0].AtHead, ShouldBeTrue)
+			})
+
+			Convey("It should be listed before older sessions", func() {
+				next, err := db.StartSession(sess)
+				So(err, ShouldBeNil)
+
+				sessions, err := db.Sessions()
+				So(err, ShouldBeNil)
+				So(sessions, ShouldHaveLength, 2)
+				So(sessions[0].ID, ShouldEqual, next)
+				So(sessions[1].ID, ShouldEqual, id)
+				So(sessions[1].Findings, ShouldEqual, 2)
+			})
+		})
+
+		Convey("A session that does not exist should be nil", func() {
+			ss, err := db.Session(id + 100)
+			So(err, ShouldBeNil)
+			So(ss, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"wraith/version"
+)
+
+// These are the encodings a secret can be decoded from, they are set on the findings made in what a blob decodes to
+const (
+	EncodingBase64 = "base64"
+	EncodingHex    = "hex"
+	EncodingURL    = "url"
+)
+
+// base64BlobRegex finds runs of base64 text, which is also where hex is found. The url safe alphabet is included as
+// it is used in tokens and urls.
+var base64BlobRegex = regexp.MustCompile(`[A-Za-z0-9+/_-]+={0,2}`)
+
+// urlEncodedBlobRegex finds runs of text with at least one percent encoded character
+var urlEncodedBlobRegex = regexp.MustCompile(`[A-Za-z0-9._~+%-]*%[0-9A-Fa-f]{2}[A-Za-z0-9._~+%-]*`)
+
+// hexRegex matches text that is only hex digits
+var hexRegex = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+// encodedBlob is a run of encoded text in the content of a file, along with what it decodes to
+type encodedBlob struct {
+	encoding string
+	decoded  string
+	text     string // the encoded text itself
+	line     int    // the line the blob is on, starting at 1
+}
+
+// isPrintableText will check that decoded bytes are text, a blob that decodes to anything else is not encoded text
+// but an identifier, a hash or a binary that happens to use the same characters
+func isPrintableText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeBlob will decode a run of hex or base64 text, returning the encoding it was in. Hex is tried first as every
+// hex string is also base64.
+func decodeBlob(blob string) (string, string, bool) {
+	if len(blob)%2 == 0 && hexRegex.MatchString(blob) {
+		if data, err := hex.DecodeString(blob); err == nil && isPrintableText(data) {
+			return string(data), EncodingHex, true
+		}
+	}
+
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(blob, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	data, err := encoding.DecodeString(strings.TrimRight(blob, "="))
+	if err != nil || !isPrintableText(data) {
+		return "", "", false
+	}
+	return string(data), EncodingBase64, true
+}
+
+// findEncodedBlobs will find the runs of base64, hex and url encoded text in content of at least minLength characters
+// that decode to text
+func findEncodedBlobs(content string, minLength int) []encodedBlob {
+	var blobs []encodedBlob
+	for i, line := range strings.Split(content, "\n") {
+		for _, blob := range base64BlobRegex.FindAllString(line, -1) {
+			if len(blob) < minLength {
+				continue
+			}
+			if decoded, encoding, ok := decodeBlob(blob); ok {
+				blobs = append(blobs, encodedBlob{encoding: encoding, decoded: decoded, text: blob, line: i + 1})
+			}
+		}
+		for _, blob := range urlEncodedBlobRegex.FindAllString(line, -1) {
+			if len(blob) < minLength {
+				continue
+			}
+			if decoded, err := url.QueryUnescape(blob); err == nil && decoded != blob && isPrintableText([]byte(decoded)) {
+				blobs = append(blobs, encodedBlob{encoding: EncodingURL, decoded: decoded, text: blob, line: i + 1})
+			}
+		}
+	}
+	return blobs
+}
+
+// scanDecoded will match the content signatures against what the encoded blobs in content decode to, so a secret that
+// was base64, hex or url encoded, such as in the data of a kubernetes secret, is found as well. The findings are copied
+// from base, put on the line of their blob and flagged with its encoding. They are not added to the session, this is
+// left to the caller so they can be handled like any other match.
+func scanDecoded(sess *Session, content string, base Finding) []*Finding {
+	blobs := findEncodedBlobs(content, sess.DecodeMinLength)
+	if len(blobs) == 0 {
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "wraith")
+	if err != nil {
+		sess.Out.Error("Unable to decode %s: %s\n", base.FilePath, err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+
+	var findings []*Finding
+	lines := strings.Split(content, "\n")
+	for _, blob := range blobs {
+		if isSuppressed(lines, blob.line, sess) {
+			continue
+		}
+
+		// the decoded text is written out so the signatures match it the same way they match a file
+		fullFilePath := filepath.Join(dir, "DECODED")
+		if err := ioutil.WriteFile(fullFilePath, []byte(blob.decoded), 0600); err != nil {
+			sess.Out.Error("Unable to decode %s: %s\n", base.FilePath, err)
+			return findings
+		}
+		matchFile := newMatchFile(fullFilePath)
+
+		for _, signature := range CurrentSignatures() {
+			if signature.Part() != PartContent {
+				continue
+			}
+			bMatched, matchMap := signature.ExtractMatch(matchFile, sess, nil)
+			if !bMatched {
+				continue
+			}
+
+			for k := range matchMap {
+				parts := strings.SplitAfterN(k, "_", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				content := parts[1]
+
+				finding := base
+				finding.Comment = Redact(content, sess.Redact)
+				finding.Description = signature.Description()
+				finding.Encoding = blob.encoding
+				finding.LineNumber = strconv.Itoa(blob.line)
+				finding.Score = signature.MatchLevel()
+				finding.Severity = signature.Severity()
+				finding.Confidence = signature.Confidence()
+				finding.Signatureid = signature.Signatureid()
+				finding.SignaturesVersion = sess.SignatureVersion
+				finding.WraithVersion = version.AppVersion()
+				finding.secret = content
+				// the encoded text is the secret as it is written in the file, so it is hidden as well
+				finding.setContext(lines, blob.line, sess, blob.text)
+
+				finding.Initialize(sess.ScanType)
+				findings = append(findings, &finding)
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,146 @@
+package core
+
+import "time"
+
+// SeenCommit is a commit a finding was seen in, kept for the first and last commits of a finding that was found in more
+// than one
+type SeenCommit struct {
+	CommitHash   string `json:"commit_hash"`
+	CommitDate   string `json:"commit_date,omitempty"`
+	CommitAuthor string `json:"commit_author,omitempty"`
+	CommitURL    string `json:"commit_url,omitempty"`
+	FileURL      string `json:"file_url,omitempty"`
+}
+
+// before reports whether the commit was written before another, which is only known when both have a date
+func (c *SeenCommit) before(other *SeenCommit) bool {
+	date, err := time.Parse(time.RFC3339, c.CommitDate)
+	if err != nil {
+		return false
+	}
+	otherDate, err := time.Parse(time.RFC3339, other.CommitDate)
+	return err == nil && date.Before(otherDate)
+}
+
+// seenCommit is the commit the finding was made in
+func (f *Finding) seenCommit() *SeenCommit {
+	return &SeenCommit{
+		CommitHash:   f.CommitHash,
+		CommitDate:   f.CommitDate,
+		CommitAuthor: f.CommitAuthor,
+		CommitURL:    f.CommitUrl,
+		FileURL:      f.FileUrl,
+	}
+}
+
+// occurrences is how many times the finding was found, which is only counted when findings are deduplicated
+func (f *Finding) occurrences() int {
+	if f.Occurrences < 1 {
+		return 1
+	}
+	return f.Occurrences
+}
+
+// dedupEntry is the first finding of a fingerprint along with what the findings of it that came after were merged
+// into. The finding is verified, scripted and handed to the outputs and hooks outside of the lock once it is found,
+// so what is merged is only applied to it under the lock once it has been published, and the finding is otherwise
+// left as it was found.
+type dedupEntry struct {
+	finding     *Finding
+	published   bool
+	occurrences int
+	atHead      bool
+	first       *SeenCommit
+	last        *SeenCommit
+}
+
+// newDedupEntry will start merging findings into the first finding of a fingerprint
+func newDedupEntry(f *Finding) *dedupEntry {
+	e := &dedupEntry{finding: f, occurrences: f.occurrences(), atHead: f.AtHead, first: f.FirstSeen, last: f.LastSeen}
+	if e.first == nil {
+		e.first = f.seenCommit()
+	}
+	if e.last == nil {
+		e.last = f.seenCommit()
+	}
+	return e
+}
+
+// merge will fold another finding of the same secret in the same place into the entry. The earliest commit is kept
+// as where the secret was introduced and the latest as where it was last seen. A commit without a date is taken to be
+// later than the ones already seen, as history is scanned from the newest.
+func (e *dedupEntry) merge(other *Finding) {
+	e.occurrences += other.occurrences()
+	e.atHead = e.atHead || other.AtHead
+	seen := other.seenCommit()
+	switch {
+	case seen.before(e.first):
+		e.first = seen
+	case seen.CommitHash != "" && seen.CommitHash != e.last.CommitHash && !seen.before(e.last):
+		e.last = seen
+	}
+	if e.published {
+		e.apply()
+	}
+}
+
+// apply will set the occurrences, at head and first and last commits of the finding from what was merged into it.
+// The first and last commits are left out when they are the commit of the finding. It is called with the session
+// locked.
+func (e *dedupEntry) apply() {
+	f := e.finding
+	f.Occurrences = e.occurrences
+	f.AtHead = e.atHead
+	f.FirstSeen, f.LastSeen = nil, nil
+	if e.first.CommitHash != f.CommitHash {
+		f.FirstSeen = e.first
+	}
+	if e.last.CommitHash != f.CommitHash {
+		f.LastSeen = e.last
+	}
+}
+
+// dedupFinding will merge a finding into the one already found with the same fingerprint, reporting whether there
+// was one. The fingerprint includes the file, so only the same secret in the same file is merged. The first finding
+// of each fingerprint is kept for those that come after it, and the entry it is kept in is returned for it to be
+// published once it has been added. It is called with the session locked.
+func (s *Session) dedupFinding(finding *Finding) (*dedupEntry, bool) {
+	if !s.DedupFindings {
+		return nil, false
+	}
+	if s.findingIndex == nil {
+		s.findingIndex = map[string]*dedupEntry{}
+	}
+	if kept, ok := s.findingIndex[finding.Fingerprint]; ok {
+		kept.merge(finding)
+		return nil, true
+	}
+	finding.Occurrences = finding.occurrences()
+	entry := newDedupEntry(finding)
+	s.findingIndex[finding.Fingerprint] = entry
+	return entry, false
+}
+
+// dropDedup will forget the first finding of a fingerprint when it was left out by a finding script, policy or the
+// minimums, so the next finding of it is checked on its own
+func (s *Session) dropDedup(entry *dedupEntry) {
+	if entry == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.findingIndex[entry.finding.Fingerprint] == entry {
+		delete(s.findingIndex, entry.finding.Fingerprint)
+	}
+}
+
+// publishDedup will apply what was merged into a finding while it was being added, and every merge after it
+func (s *Session) publishDedup(entry *dedupEntry) {
+	if entry == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	entry.published = true
+	entry.apply()
+}
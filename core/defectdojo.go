@@ -0,0 +1,268 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefectDojoReimportPath is the path of the api endpoint findings are imported with. A reimport updates the findings of
+// the test it is sent to rather than adding a new test, so importing the next scan does not duplicate them.
+const DefectDojoReimportPath = "/api/v2/reimport-scan/"
+
+// DefectDojoScanType is the parser defectdojo reads the findings with
+const DefectDojoScanType = "Generic Findings Import"
+
+// DefectDojoCWE is the weakness every finding is filed under, the use of hard-coded credentials
+const DefectDojoCWE = 798
+
+// defectDojoSeverities are the severities defectdojo shows for each severity of a finding, anything else is Info
+var defectDojoSeverities = map[string]string{
+	SeverityCritical: "Critical",
+	SeverityHigh:     "High",
+	SeverityMedium:   "Medium",
+	SeverityLow:      "Low",
+}
+
+// DefectDojoFinding is a finding in the generic findings format of defectdojo
+type DefectDojoFinding struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Severity         string   `json:"severity"`
+	Mitigation       string   `json:"mitigation"`
+	References       string   `json:"references,omitempty"`
+	Date             string   `json:"date"`
+	CWE              int      `json:"cwe"`
+	FilePath         string   `json:"file_path"`
+	Line             int      `json:"line,omitempty"`
+	ComponentName    string   `json:"component_name,omitempty"`
+	UniqueIDFromTool string   `json:"unique_id_from_tool"`
+	VulnIDFromTool   string   `json:"vuln_id_from_tool"`
+	StaticFinding    bool     `json:"static_finding"`
+	DynamicFinding   bool     `json:"dynamic_finding"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// DefectDojoReport is the file imported into defectdojo
+type DefectDojoReport struct {
+	Findings []DefectDojoFinding `json:"findings"`
+}
+
+// DefectDojo imports the findings of a scan into a test of a defectdojo engagement once it is done
+type DefectDojo struct {
+	URL              string
+	Token            string
+	Product          string
+	Engagement       string
+	TestTitle        string
+	CloseOldFindings bool
+	client           *http.Client
+}
+
+// NewDefectDojo will create an exporter for a defectdojo instance. The url can be the instance itself, such as
+// https://defectdojo.example.com, or the full url of its reimport endpoint. The engagement is created in the product
+// when it does not exist yet, and the test in the engagement.
+func NewDefectDojo(endpoint, token, product, engagement, testTitle string, closeOldFindings bool) (*DefectDojo, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", endpoint)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("an api key is needed to import findings into defectdojo")
+	}
+	if product == "" || engagement == "" {
+		return nil, fmt.Errorf("the product and engagement to import findings into are needed")
+	}
+	if testTitle == "" {
+		return nil, fmt.Errorf("the test title can not be empty")
+	}
+	if !strings.Contains(u.Path, "/api/") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + DefectDojoReimportPath
+	}
+
+	return &DefectDojo{
+		URL:              u.String(),
+		Token:            token,
+		Product:          product,
+		Engagement:       engagement,
+		TestTitle:        testTitle,
+		CloseOldFindings: closeOldFindings,
+		client:           NewHTTPClient(WebhookTimeout * 3),
+	}, nil
+}
+
+// NewDefectDojoFinding will convert a finding into the generic findings format. Its fingerprint is the id defectdojo
+// tells findings apart by, and the title, file, line and description are kept the same from one scan to the next, so
+// a reimport matches the findings whichever way the instance deduplicates them.
+func NewDefectDojoFinding(f *Finding, scannedAt time.Time) DefectDojoFinding {
+	repository := f.RepositoryOwner + "/" + f.RepositoryName
+	description := fmt.Sprintf("%s found in %s of %s", f.Description, f.FilePath, repository)
+	switch f.Action {
+	case ActionCommitMessage:
+		description = fmt.Sprintf("%s found in a commit message of %s", f.Description, repository)
+	case ActionGitNote:
+		description = fmt.Sprintf("%s found in a git note of %s", f.Description, repository)
+	}
+	if f.Encoding != "" {
+		description += fmt.Sprintf(", %s encoded", f.Encoding)
+	}
+	description += fmt.Sprintf(".\n\nSignature: %s", f.Signatureid)
+
+	mitigation := "Revoke the secret and replace it, then remove it from the repository and its history."
+	if f.Remediation != nil {
+		mitigation += " " + f.Remediation.Solution()
+	}
+
+	var references []string
+	for _, link := range []string{f.FileUrl, f.CommitUrl, f.RepositoryUrl} {
+		if link != "" {
+			references = append(references, link)
+		}
+	}
+
+	// the date is when the secret was committed, or when it was found for anything that is not a commit
+	date := scannedAt
+	if committed, err := time.Parse(time.RFC3339, f.CommitDate); err == nil {
+		date = committed
+	}
+
+	severity, ok := defectDojoSeverities[FindingSeverity(f)]
+	if !ok {
+		severity = "Info"
+	}
+	line, _ := strconv.Atoi(f.LineNumber)
+	return DefectDojoFinding{
+		Title:            f.Description,
+		Description:      description,
+		Severity:         severity,
+		Mitigation:       strings.TrimSpace(mitigation),
+		References:       strings.Join(references, "\n"),
+		Date:             date.UTC().Format("2006-01-02"),
+		CWE:              DefectDojoCWE,
+		FilePath:         f.FilePath,
+		Line:             line,
+		ComponentName:    repository,
+		UniqueIDFromTool: f.Fingerprint,
+		VulnIDFromTool:   f.Signatureid,
+		StaticFinding:    true,
+		Tags:             []string{Name, f.Signatureid},
+	}
+}
+
+// NewDefectDojoReport will build the file imported into defectdojo from the findings of a session
+func NewDefectDojoReport(s *Session) DefectDojoReport {
+	s.Stats.Lock()
+	scannedAt := s.Stats.StartedAt
+	s.Stats.Unlock()
+
+	report := DefectDojoReport{Findings: []DefectDojoFinding{}}
+	s.Lock()
+	for _, f := range s.Findings {
+		report.Findings = append(report.Findings, NewDefectDojoFinding(f, scannedAt))
+	}
+	s.Unlock()
+	return report
+}
+
+// Import will send a report to the reimport endpoint, trying again when the request fails or defectdojo returns a 429
+// or 5xx. Findings that were imported before and are not in the report are only closed when closeOld is set.
+func (d *DefectDojo) Import(report DefectDojoReport, scannedAt time.Time, closeOld bool) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{
+		"scan_type":           DefectDojoScanType,
+		"product_name":        d.Product,
+		"engagement_name":     d.Engagement,
+		"test_title":          d.TestTitle,
+		"auto_create_context": "true",
+		"scan_date":           scannedAt.UTC().Format("2006-01-02"),
+		"minimum_severity":    "Info",
+		"active":              "true",
+		"verified":            "false",
+		"close_old_findings":  strconv.FormatBool(closeOld),
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := d.request(fields, data)
+		if err == nil || !retry || attempt == WebhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// request will send a single import, returning whether it is worth trying again when it fails
+func (d *DefectDojo) request(fields map[string]string, report []byte) (bool, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return false, err
+		}
+	}
+	file, err := form.CreateFormFile("file", "wraith.json")
+	if err != nil {
+		return false, err
+	}
+	file.Write(report)
+	if err := form.Close(); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.URL, &body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Authorization", "Token "+d.Token)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("defectdojo returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+}
+
+// ExportDefectDojo will import the findings of a finished scan into defectdojo if it is configured. Old findings are
+// never closed after an interrupted scan, as it did not look at everything they could still be in.
+func (s *Session) ExportDefectDojo() {
+	if s.DefectDojo == nil {
+		return
+	}
+	report := NewDefectDojoReport(s)
+	s.Stats.Lock()
+	scannedAt := s.Stats.StartedAt
+	s.Stats.Unlock()
+	// findings are only closed after a scan that looked at everything it could have found them in again
+	closeOld := s.DefectDojo.CloseOldFindings && !s.narrowedScan() && len(s.ScanErrors()) == 0
+	if s.DefectDojo.CloseOldFindings && !closeOld {
+		s.Out.Warn("Not closing old findings in defectdojo, the scan did not look at everything again\n")
+	}
+	if err := s.DefectDojo.Import(report, scannedAt, closeOld); err != nil {
+		s.Out.Error("Failed to import into defectdojo: %s\n", err)
+		return
+	}
+	s.Out.Info("Imported %d %s into defectdojo\n", len(report.Findings), Pluralize(len(report.Findings), "finding", "findings"))
+}
@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestDefectDojo(t *testing.T) {
+
+	Convey("Given a defectdojo instance", t, func() {
+		var mu sync.Mutex
+		var paths []string
+		var auth string
+		var fields map[string]string
+		var report core.DefectDojoReport
+		failures, status := 0, http.StatusBadGateway
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			paths = append(paths, r.Method+" "+r.URL.Path)
+			auth = r.Header.Get("Authorization")
+			if failures > 0 {
+				failures--
+				w.WriteHeader(status)
+				w.Write([]byte(`{"detail":"Product 'payments' does not exist"}`))
+				return
+			}
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fields = map[string]string{}
+			for name, values := range r.MultipartForm.Value {
+				fields[name] = values[0]
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := ioutil.ReadAll(file)
+			json.Unmarshal(data, &report)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"test": 12}`))
+		}))
+		defer server.Close()
+
+		defectDojo, err := core.NewDefectDojo(server.URL, "5f1e9c", "payments", "secrets", "wraith", true)
+		So(err, ShouldBeNil)
+
+		startedAt := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			Stats:      &core.Stats{StartedAt: startedAt, FinishedAt: startedAt.Add(time.Minute)},
+			Out:        &core.Logger{},
+			Silent:     true,
+			DefectDojo: defectDojo,
+		}
+		sess.Findings = []*core.Finding{
+			{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "config/prod.env", LineNumber: "3",
+				Signatureid: "aws-id", Description: "AWS Access Key ID", Severity: core.SeverityCritical,
+				Fingerprint: "9f86d081", CommitDate: "2020-07-21T14:02:00+02:00",
+				FileUrl: "https://github.com/acme/api/blob/c0ffee/config/prod.env", CommitUrl: "https://github.com/acme/api/commit/c0ffee",
+				Remediation: &core.Remediation{RotationURL: "https://console.aws.amazon.com/iam"}},
+			{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "notes.txt", Signatureid: "password-in-file",
+				Description: "Potential password", Severity: "info", Fingerprint: "2c26b46b"},
+		}
+
+		Convey("The findings should be reimported into the test of the engagement", func() {
+			sess.ExportDefectDojo()
+			So(paths, ShouldResemble, []string{"POST " + core.DefectDojoReimportPath})
+			So(auth, ShouldEqual, "Token 5f1e9c")
+			So(fields["scan_type"], ShouldEqual, "Generic Findings Import")
+			So(fields["product_name"], ShouldEqual, "payments")
+			So(fields["engagement_name"], ShouldEqual, "secrets")
+			So(fields["test_title"], ShouldEqual, "wraith")
+			So(fields["auto_create_context"], ShouldEqual, "true")
+			So(fields["scan_date"], ShouldEqual, "2020-08-03")
+			So(fields["close_old_findings"], ShouldEqual, "true")
+
+			So(report.Findings, ShouldHaveLength, 2)
+			f := report.Findings[0]
+			So(f.Title, ShouldEqual, "AWS Access Key ID")
+			So(f.Severity, ShouldEqual, "Critical")
+			So(f.UniqueIDFromTool, ShouldEqual, "9f86d081")
+			So(f.VulnIDFromTool, ShouldEqual, "aws-id")
+			So(f.FilePath, ShouldEqual, "config/prod.env")
+			So(f.Line, ShouldEqual, 3)
+			So(f.ComponentName, ShouldEqual, "acme/api")
+			So(f.Date, ShouldEqual, "2020-07-21")
+			So(f.CWE, ShouldEqual, 798)
+			So(f.Description, ShouldEqual, "AWS Access Key ID found in config/prod.env of acme/api.\n\nSignature: aws-id")
+			So(f.Mitigation, ShouldContainSubstring, "Rotate it at https://console.aws.amazon.com/iam.")
+			So(f.References, ShouldEqual, "https://github.com/acme/api/blob/c0ffee/config/prod.env\nhttps://github.com/acme/api/commit/c0ffee")
+
+			So(report.Findings[1].Severity, ShouldEqual, "Info")
+			So(report.Findings[1].Date, ShouldEqual, "2020-08-03")
+		})
+
+		filter, _ := core.NewPathFilter([]string{"src/"}, nil)
+		for _, narrowed := range []struct {
+			name   string
+			narrow func()
+		}{
+			{"an interrupted", func() { sess.Interrupt() }},
+			{"an incremental", func() { sess.ScanState = &core.ScanState{} }},
+			{"a path filtered", func() { sess.PathFilter = filter }},
+			{"a commit range", func() { sess.CommitRange = &core.CommitRange{Since: "v1.0.0"} }},
+		} {
+			narrow := narrowed.narrow
+			Convey("Old findings should be left open after "+narrowed.name+" scan", func() {
+				narrow()
+				sess.ExportDefectDojo()
+				So(fields["close_old_findings"], ShouldEqual, "false")
+			})
+		}
+
+		Convey("An instance that is unavailable should be sent the import again", func() {
+			failures = 1
+			So(defectDojo.Import(core.NewDefectDojoReport(sess), startedAt, false), ShouldBeNil)
+			So(paths, ShouldHaveLength, 2)
+			So(report.Findings, ShouldHaveLength, 2)
+		})
+
+		Convey("An import that is refused should fail without trying again", func() {
+			failures, status = 1, http.StatusBadRequest
+			err := defectDojo.Import(core.NewDefectDojoReport(sess), startedAt, false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `defectdojo returned 400 Bad Request: {"detail":"Product 'payments' does not exist"}`)
+			So(paths, ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Settings that can not work should be refused", t, func() {
+		_, err := core.NewDefectDojo("defectdojo.example.com", "key", "payments", "secrets", "wraith", false)
+		So(err, ShouldNotBeNil)
+		_, err = core.NewDefectDojo("https://defectdojo.example.com", "", "payments", "secrets", "wraith", false)
+		So(err, ShouldNotBeNil)
+		_, err = core.NewDefectDojo("https://defectdojo.example.com", "key", "payments", "", "wraith", false)
+		So(err, ShouldNotBeNil)
+		defectDojo, err := core.NewDefectDojo("https://defectdojo.example.com/dojo/", "key", "payments", "secrets", "wraith", false)
+		So(err, ShouldBeNil)
+		So(defectDojo.URL, ShouldEqual, "https://defectdojo.example.com/dojo/api/v2/reimport-scan/")
+	})
+}
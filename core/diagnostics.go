@@ -0,0 +1,133 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// RuntimeStatsInterval is how often the memory and goroutines of wraith are logged when debugging
+const RuntimeStatsInterval = 30 * time.Second
+
+// the debug server and the runtime stats belong to the process, not a session, so they are only started once however
+// many sessions a serve, worker or operator process runs
+var (
+	debugServerOnce  sync.Once
+	runtimeStatsOnce sync.Once
+)
+
+// RuntimeStats is a snapshot of the memory and goroutines of the process, to tell what a large scan is holding on to
+type RuntimeStats struct {
+	Goroutines   int    `json:"goroutines"`
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+}
+
+// ReadRuntimeStats will take a snapshot of the memory and goroutines of the process
+func ReadRuntimeStats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		HeapObjects:  m.HeapObjects,
+		StackInuse:   m.StackInuse,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+	}
+}
+
+// String is the snapshot the way it is written to the debug log
+func (r RuntimeStats) String() string {
+	return fmt.Sprintf("%d goroutines, %s allocated in %d objects, %s of heap in use, %s from the os, %d garbage collections",
+		r.Goroutines, FormatSize(int64(r.HeapAlloc)), r.HeapObjects, FormatSize(int64(r.HeapInuse)), FormatSize(int64(r.Sys)), r.NumGC)
+}
+
+// NewDebugHandler serves the pprof profiles under /debug/pprof/ and a snapshot of the runtime stats as json from
+// /debug/runtime. It has a mux of its own so the profiles are never served by the web interface. The command line of
+// the process is not served, as tokens are often given as flags.
+func NewDebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/debug/pprof/cmdline" {
+			http.NotFound(w, r)
+			return
+		}
+		pprof.Index(w, r)
+	})
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReadRuntimeStats())
+	})
+	return mux
+}
+
+// CheckDebugServerAddress will check that the debug server is only reachable from the host it runs on, unless public
+// is set with --debug-server-public. The profiles show the memory of the process, secrets and all, with no
+// authentication.
+func CheckDebugServerAddress(address string, public bool) error {
+	if public {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("the debug server can only listen on a loopback address such as localhost:6060 unless --debug-server-public is set, %s is not one", address)
+}
+
+// StartDebugServer will serve the profiles of NewDebugHandler on the address given to --debug-server, such as
+// localhost:6060. The profiles show the memory and goroutines of the process, so an address other than loopback is
+// refused unless public is set.
+func StartDebugServer(address string, public bool, out *Logger) {
+	debugServerOnce.Do(func() {
+		if err := CheckDebugServerAddress(address, public); err != nil {
+			out.Fatal("Error when starting the debug server: %s\n", err)
+		}
+		lis, err := net.Listen("tcp", address)
+		if err != nil {
+			out.Fatal("Error when starting the debug server: %s\n", err)
+		}
+		go func() {
+			if err := http.Serve(lis, NewDebugHandler()); err != nil {
+				out.Error("Error when running the debug server: %s\n", err)
+			}
+		}()
+		out.Important("Debug server with pprof profiles available at http://%s/debug/pprof/\n", lis.Addr())
+	})
+}
+
+// LogRuntimeStats will write the runtime stats to the debug log every RuntimeStatsInterval for as long as the process
+// runs, so the debug log of a scan that grows or stalls shows when it started to
+func LogRuntimeStats(out *Logger) {
+	runtimeStatsOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(RuntimeStatsInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				out.Debug("Runtime: %s\n", ReadRuntimeStats())
+			}
+		}()
+	})
+}
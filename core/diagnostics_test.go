@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+func TestDiagnostics(t *testing.T) {
+
+	Convey("Given the debug handler", t, func() {
+		handler := core.NewDebugHandler()
+		get := func(path string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			return w
+		}
+
+		Convey("The pprof profiles should be listed", func() {
+			w := get("/debug/pprof/")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldContainSubstring, "goroutine")
+			So(w.Body.String(), ShouldContainSubstring, "heap")
+
+			So(get("/debug/pprof/goroutine?debug=1").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("The runtime stats should be served as json", func() {
+			w := get("/debug/runtime")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			var stats core.RuntimeStats
+			So(json.Unmarshal(w.Body.Bytes(), &stats), ShouldBeNil)
+			So(stats.Goroutines, ShouldBeGreaterThan, 0)
+			So(stats.HeapAlloc, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("The command line of the process should not be served", func() {
+			So(get("/debug/pprof/cmdline").Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Nothing else should be served", func() {
+			So(get("/").Code, ShouldEqual, http.StatusNotFound)
+		})
+	})
+
+	Convey("The debug server should only listen on loopback unless it is made public", t, func() {
+		for _, address := range []string{"localhost:6060", "127.0.0.1:6060", "[::1]:6060"} {
+			So(core.CheckDebugServerAddress(address, false), ShouldBeNil)
+		}
+		for _, address := range []string{":6060", "0.0.0.0:6060", "10.0.0.5:6060", "debug.acme.com:6060"} {
+			So(core.CheckDebugServerAddress(address, false), ShouldNotBeNil)
+			So(core.CheckDebugServerAddress(address, true), ShouldBeNil)
+		}
+	})
+
+	Convey("Runtime stats should be logged the way a person reads them", t, func() {
+		stats := core.RuntimeStats{Goroutines: 12, HeapAlloc: 3 << 20, HeapObjects: 4000, HeapInuse: 4 << 20, Sys: 5 << 30, NumGC: 7}
+		So(stats.String(), ShouldEqual, "12 goroutines, 3.0 MB allocated in 4000 objects, 4.0 MB of heap in use, 5.0 GB from the os, 7 garbage collections")
+	})
+}
@@ -0,0 +1,708 @@
+package core
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// These are the defaults used to pull images from a registry
+const (
+	DockerHubName         = "docker.io"
+	DockerHubRegistry     = "registry-1.docker.io"
+	DockerDefaultPlatform = "linux/amd64"
+)
+
+// dockerManifestTypes are the manifests and manifest lists that can be pulled, in order of preference
+var dockerManifestTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// DockerReference is an image in a registry, such as ghcr.io/acme/api:1.2 or nginx@sha256:...
+type DockerReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseDockerReference will split an image reference into its registry, repository and tag or digest, filling in
+// docker hub and the latest tag the same way the docker cli does
+func ParseDockerReference(ref string) (DockerReference, error) {
+	var r DockerReference
+	name := strings.TrimSpace(ref)
+
+	if i := strings.Index(name, "@"); i != -1 {
+		r.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		r.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if name == "" {
+		return r, fmt.Errorf("%s is not an image reference", ref)
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.Registry = parts[0]
+		r.Repository = parts[1]
+	} else {
+		r.Registry = DockerHubName
+		r.Repository = name
+	}
+	if r.Registry == DockerHubName && !strings.Contains(r.Repository, "/") {
+		r.Repository = "library/" + r.Repository
+	}
+	if r.Repository != strings.ToLower(r.Repository) {
+		return r, fmt.Errorf("%s is not an image reference, repositories are lower case", ref)
+	}
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+	return r, nil
+}
+
+// reference is the tag or digest of the image, as it is asked for from the registry
+func (r DockerReference) reference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// String is the full name of the image
+func (r DockerReference) String() string {
+	if r.Digest != "" {
+		return r.Registry + "/" + r.Repository + "@" + r.Digest
+	}
+	return r.Registry + "/" + r.Repository + ":" + r.Tag
+}
+
+// dockerImage is an image to be scanned along with where the findings in it are attributed
+type dockerImage struct {
+	owner  string
+	name   string
+	layers []dockerLayer
+}
+
+// dockerLayer is a layer of an image along with the instruction in the dockerfile that created it
+type dockerLayer struct {
+	digest    string
+	createdBy string
+	open      func() (io.ReadCloser, error)
+}
+
+// newDockerImage will name an image after its reference, with the repository as the owner of its findings and
+// the last part of the repository, along with the tag, as the name
+func newDockerImage(ref DockerReference) *dockerImage {
+	full := ref.String()
+	i := strings.LastIndex(full[:len(full)-len(ref.reference())], "/")
+	return &dockerImage{
+		owner: full[:i],
+		name:  full[i+1:],
+	}
+}
+
+// dockerConfig is the part of the config of an image that has the history of how it was built
+type dockerConfig struct {
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+	RootFS struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// instructions are the dockerfile instructions that created each layer, in the same order as the layers. Steps
+// such as ENV and LABEL do not create a layer and are left out.
+func (c dockerConfig) instructions() []string {
+	var steps []string
+	for _, h := range c.History {
+		if !h.EmptyLayer {
+			steps = append(steps, dockerInstruction(h.CreatedBy))
+		}
+	}
+	return steps
+}
+
+// dockerInstruction will turn the command docker records for a layer back into the dockerfile instruction
+func dockerInstruction(createdBy string) string {
+	step := strings.TrimSpace(createdBy)
+	if strings.HasPrefix(step, "/bin/sh -c #(nop) ") {
+		return strings.TrimSpace(strings.TrimPrefix(step, "/bin/sh -c #(nop) "))
+	}
+	if strings.HasPrefix(step, "/bin/sh -c ") {
+		return "RUN " + strings.TrimPrefix(step, "/bin/sh -c ")
+	}
+	return step
+}
+
+// dockerDescriptor points to a manifest, config or layer in a registry
+type dockerDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+// dockerManifest is either the manifest of an image or a list of the manifests for each platform it is built for
+type dockerManifest struct {
+	MediaType string             `json:"mediaType"`
+	Config    dockerDescriptor   `json:"config"`
+	Layers    []dockerDescriptor `json:"layers"`
+	Manifests []dockerDescriptor `json:"manifests"`
+}
+
+// dockerRegistry holds the credentials and token used to pull a single repository from a registry
+type dockerRegistry struct {
+	url        string
+	repository string
+	username   string
+	password   string
+	token      string
+	basic      bool
+	client     *http.Client
+}
+
+// newDockerRegistry will create a client for the repository of an image. Registries on this machine are talked to
+// over http, any others over https.
+func newDockerRegistry(ref DockerReference, username, password string) *dockerRegistry {
+	host := ref.Registry
+	if host == DockerHubName {
+		host = DockerHubRegistry
+	}
+	scheme := "https"
+	if h := strings.Split(host, ":")[0]; h == "localhost" || h == "127.0.0.1" {
+		scheme = "http"
+	}
+	return &dockerRegistry{
+		url:        scheme + "://" + host,
+		repository: ref.Repository,
+		username:   username,
+		password:   password,
+		client:     NewHTTPClient(30 * time.Minute),
+	}
+}
+
+// get will request a path of the repository from the registry, signing in once if the registry asks for it
+func (r *dockerRegistry) get(kind, reference string, accept ...string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/%s/%s/%s", r.url, r.repository, kind, reference), nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		switch {
+		case r.token != "":
+			req.Header.Set("Authorization", "Bearer "+r.token)
+		case r.basic:
+			req.SetBasicAuth(r.username, r.password)
+		}
+
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := r.authenticate(challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s returned %d for %s %s: %s", r.url, resp.StatusCode, kind, reference, strings.TrimSpace(string(body)))
+		}
+		return resp, nil
+	}
+}
+
+// dockerChallengeParam matches the parameters of a WWW-Authenticate challenge, such as realm="https://auth.docker.io"
+var dockerChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate will answer the challenge of a registry, either with basic auth or by getting a bearer token from
+// the auth server it points to. Public images on docker hub need a token even when no credentials are set.
+func (r *dockerRegistry) authenticate(challenge string) error {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	if scheme == "basic" {
+		if r.username == "" {
+			return errors.New("the registry requires a docker username and password")
+		}
+		r.basic = true
+		return nil
+	}
+	if scheme != "bearer" {
+		return fmt.Errorf("the registry asked for unsupported authentication: %s", challenge)
+	}
+
+	params := map[string]string{}
+	for _, m := range dockerChallengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	if params["realm"] == "" {
+		return fmt.Errorf("the registry did not say where to get a token: %s", challenge)
+	}
+
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	} else {
+		query.Set("scope", "repository:"+r.repository+":pull")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to get a token for %s, the auth server returned %d", r.repository, resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	if r.token == "" {
+		return fmt.Errorf("the auth server did not return a token for %s", r.repository)
+	}
+	return nil
+}
+
+// manifest will get the manifest of an image, choosing the one for the platform from a manifest list
+func (r *dockerRegistry) manifest(reference string, platform string) (*dockerManifest, error) {
+	resp, err := r.get("manifests", reference, dockerManifestTypes...)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var m dockerManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("unable to read the manifest of %s: %s", reference, err)
+	}
+	if len(m.Manifests) == 0 {
+		if m.Config.Digest == "" {
+			return nil, fmt.Errorf("the manifest of %s is not a supported version", reference)
+		}
+		return &m, nil
+	}
+
+	parts := strings.Split(platform, "/")
+	for _, d := range m.Manifests {
+		if d.Platform == nil || d.Platform.OS != parts[0] || len(parts) < 2 || d.Platform.Architecture != parts[1] {
+			continue
+		}
+		if len(parts) > 2 && d.Platform.Variant != parts[2] {
+			continue
+		}
+		return r.manifest(d.Digest, platform)
+	}
+	return nil, fmt.Errorf("%s is not built for %s", reference, platform)
+}
+
+// pullDockerImage will get the manifest and config of an image from its registry. The layers are not downloaded
+// until they are scanned.
+func pullDockerImage(ref DockerReference, sess *Session) (*dockerImage, error) {
+	registry := newDockerRegistry(ref, sess.DockerUsername, sess.DockerPassword)
+
+	m, err := registry.manifest(ref.reference(), sess.DockerPlatform)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registry.get("blobs", m.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	var config dockerConfig
+	err = json.NewDecoder(resp.Body).Decode(&config)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the config of %s: %s", ref, err)
+	}
+
+	image := newDockerImage(ref)
+	steps := config.instructions()
+	for i, l := range m.Layers {
+		digest := l.Digest
+		layer := dockerLayer{
+			digest: digest,
+			open: func() (io.ReadCloser, error) {
+				resp, err := registry.get("blobs", digest)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Body, nil
+			},
+		}
+		if len(steps) == len(m.Layers) {
+			layer.createdBy = steps[i]
+		}
+		image.layers = append(image.layers, layer)
+	}
+	return image, nil
+}
+
+// openDockerArchive will read an image saved with docker save. The layers are read from the archive when they are
+// scanned.
+func openDockerArchive(filename string) (*dockerImage, error) {
+	var manifest []struct {
+		Config   string   `json:"Config"`
+		RepoTags []string `json:"RepoTags"`
+		Layers   []string `json:"Layers"`
+	}
+	if err := readDockerArchiveJSON(filename, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest) == 0 {
+		return nil, fmt.Errorf("%s does not have any images in it", filename)
+	}
+	m := manifest[0]
+
+	var config dockerConfig
+	if err := readDockerArchiveJSON(filename, m.Config, &config); err != nil {
+		return nil, err
+	}
+
+	image := &dockerImage{owner: filepath.Base(filename), name: strings.TrimSuffix(m.Config, ".json")}
+	if len(m.RepoTags) > 0 {
+		if ref, err := ParseDockerReference(m.RepoTags[0]); err == nil {
+			image = newDockerImage(ref)
+		}
+	}
+
+	steps := config.instructions()
+	for i, name := range m.Layers {
+		entry := name
+		layer := dockerLayer{
+			digest: entry,
+			open: func() (io.ReadCloser, error) {
+				return openDockerArchiveEntry(filename, entry)
+			},
+		}
+		if i < len(config.RootFS.DiffIDs) {
+			layer.digest = config.RootFS.DiffIDs[i]
+		}
+		if len(steps) == len(m.Layers) {
+			layer.createdBy = steps[i]
+		}
+		image.layers = append(image.layers, layer)
+	}
+	return image, nil
+}
+
+// readDockerArchiveJSON will decode a json file that is inside an image saved with docker save
+func readDockerArchiveJSON(filename, name string, out interface{}) error {
+	rc, err := openDockerArchiveEntry(filename, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(out); err != nil {
+		return fmt.Errorf("unable to read %s from %s: %s", name, filename, err)
+	}
+	return nil
+}
+
+// dockerArchiveEntry is a file inside an image saved with docker save, closing the archive once it has been read
+type dockerArchiveEntry struct {
+	io.Reader
+	fh *os.File
+}
+
+// Close will close the archive the entry is in
+func (e dockerArchiveEntry) Close() error {
+	return e.fh.Close()
+}
+
+// openDockerArchiveEntry will find a file inside an image saved with docker save, following the links docker save
+// uses for layers that are shared between images
+func openDockerArchiveEntry(filename, name string) (io.ReadCloser, error) {
+	for hops := 0; hops < 10; hops++ {
+		fh, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var link string
+		tr := tar.NewReader(fh)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				fh.Close()
+				return nil, fmt.Errorf("%s is not in %s", name, filename)
+			}
+			if err != nil {
+				fh.Close()
+				return nil, err
+			}
+			if path.Clean(hdr.Name) != path.Clean(name) {
+				continue
+			}
+			if hdr.Typeflag == tar.TypeSymlink {
+				link = path.Join(path.Dir(path.Clean(name)), hdr.Linkname)
+				break
+			}
+			return dockerArchiveEntry{Reader: tr, fh: fh}, nil
+		}
+		fh.Close()
+		name = link
+	}
+	return nil, fmt.Errorf("%s links too many times in %s", name, filename)
+}
+
+// dockerWhiteout is the prefix of the files a layer uses to delete files from the layers below it
+const dockerWhiteout = ".wh."
+
+// dockerShadow is what the layers above the one being scanned have replaced or deleted, so it is known whether a
+// file is still in the image once it is built
+type dockerShadow struct {
+	paths map[string]bool
+	dirs  []string
+}
+
+// hidden will check if a file has been replaced or deleted by a layer above it
+func (s *dockerShadow) hidden(name string) bool {
+	if s.paths[name] {
+		return true
+	}
+	for _, dir := range s.dirs {
+		if dir == "" || strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanDockerImages will scan each layer of every image and docker archive in the session
+func ScanDockerImages(sess *Session) error {
+	sess.SetStatus(StatusAnalyzing)
+
+	if len(sess.DockerImages) == 0 && len(sess.DockerArchives) == 0 {
+		return errors.New("you must set an image or a docker archive to scan")
+	}
+
+	scanned := 0
+	for _, name := range sess.DockerImages {
+		if sess.Interrupted() {
+			break
+		}
+		ref, err := ParseDockerReference(name)
+		if err != nil {
+			sess.Out.Error("%s\n", err)
+			continue
+		}
+		sess.Out.Important("Pulling %s\n", ref)
+		var image *dockerImage
+		_, err = sess.withRetries(sess.Out, "pull "+ref.String(), func() (err error) {
+			image, err = pullDockerImage(ref, sess)
+			return err
+		})
+		if err != nil {
+			sess.Out.Error("Unable to pull %s: %s\n", ref, err)
+			continue
+		}
+		scanDockerImage(image, sess)
+		scanned++
+	}
+
+	for _, filename := range sess.DockerArchives {
+		if sess.Interrupted() {
+			break
+		}
+		image, err := openDockerArchive(filename)
+		if err != nil {
+			sess.Out.Error("Unable to open %s: %s\n", filename, err)
+			continue
+		}
+		scanDockerImage(image, sess)
+		scanned++
+	}
+
+	if scanned == 0 && !sess.Interrupted() {
+		return errors.New("no images could be scanned")
+	}
+	return nil
+}
+
+// scanDockerImage will scan the layers of an image from the top down, so a file can be marked as at head when no
+// layer above it has replaced or deleted it
+func scanDockerImage(image *dockerImage, sess *Session) {
+	sess.Stats.IncrementTargets()
+	sess.Stats.IncrementRepositoriesTotal()
+
+	dir, err := ioutil.TempDir("", "wraith-docker")
+	if err != nil {
+		sess.Out.Error("Unable to create a temp dir to scan %s/%s: %s\n", image.owner, image.name, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	shadow := &dockerShadow{paths: map[string]bool{}}
+	for i := len(image.layers) - 1; i >= 0; i-- {
+		layer := image.layers[i]
+		sess.Out.Debug("Scanning layer %s of %s/%s: %s\n", layer.digest, image.owner, image.name, layer.createdBy)
+
+		rc, err := layer.open()
+		if err != nil {
+			sess.Out.Error("Unable to read layer %s of %s/%s: %s\n", layer.digest, image.owner, image.name, err)
+			continue
+		}
+		err = scanDockerLayer(rc, image, layer, shadow, dir, sess)
+		rc.Close()
+		if err != nil {
+			sess.Out.Error("Unable to read layer %s of %s/%s: %s\n", layer.digest, image.owner, image.name, err)
+		}
+		sess.Stats.IncrementCommitsScanned()
+	}
+
+	sess.Stats.IncrementRepositoriesScanned()
+}
+
+// scanDockerLayer will scan every file in a layer, adding what it replaces or deletes to the shadow of the layers
+// below it once it is done
+func scanDockerLayer(r io.Reader, image *dockerImage, layer dockerLayer, shadow *dockerShadow, dir string, sess *Session) error {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	var layerReader io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		layerReader = gz
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return errors.New("zstd compressed layers are not supported")
+	}
+
+	var replaced []string
+	var deleted []string
+
+	tr := tar.NewReader(layerReader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := archiveEntryPath(hdr.Name)
+		if name == "" {
+			continue
+		}
+		base := path.Base(name)
+		if strings.HasPrefix(base, dockerWhiteout) {
+			if base == dockerWhiteout+dockerWhiteout+".opq" {
+				// an opaque directory hides everything below it, which is the whole image at the root
+				dir := path.Dir(name)
+				if dir == "." {
+					dir = ""
+				}
+				deleted = append(deleted, dir)
+			} else {
+				deleted = append(deleted, path.Join(path.Dir(name), strings.TrimPrefix(base, dockerWhiteout)))
+			}
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		replaced = append(replaced, name)
+
+		sess.Stats.IncrementFilesTotal()
+
+		matchFile := newMatchFile(name)
+		if matchFile.isSkippable(sess) {
+			sess.Out.Debug("%s is listed as skippable and is being ignored\n", name)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if !sess.ScanTests && isTestFileOrPath(name) {
+			sess.Out.Debug("%s is a test file and being ignored\n", name)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if hdr.Size > sess.MaxFileSize*1024*1024 {
+			sess.Out.Debug("%s is too large and being ignored\n", name)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		fullFilePath := filepath.Join(dir, filepath.FromSlash(name))
+		budget := &archiveBudget{remaining: hdr.Size}
+		if err := extractArchiveFile(tr, dir, name, budget); err != nil {
+			sess.Out.Debug("Unable to read %s from layer %s: %s\n", name, layer.digest, err)
+			continue
+		}
+
+		scanFile(fullFilePath, Finding{
+			Action:          `Layer Scan`,
+			AtHead:          !shadow.hidden(name),
+			FilePath:        name,
+			RepositoryName:  image.name,
+			RepositoryOwner: image.owner,
+			CommitHash:      layer.digest,
+			CommitMessage:   layer.createdBy,
+		}, sess)
+		os.Remove(fullFilePath)
+	}
+
+	for _, name := range replaced {
+		shadow.paths[name] = true
+	}
+	for _, name := range deleted {
+		shadow.paths[name] = true
+		shadow.dirs = append(shadow.dirs, name)
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ElasticsearchBulkSize is the most documents sent to elasticsearch in a single bulk request
+const ElasticsearchBulkSize = 500
+
+// These are the suffixes added to elasticsearch-index for the index of findings and the index of sessions
+const (
+	ElasticsearchFindingsSuffix = "-findings"
+	ElasticsearchSessionsSuffix = "-sessions"
+)
+
+// ElasticsearchFindingsMapping is the mapping the index of findings is created with. Strings that are not mapped,
+// such as labels, are keywords.
+const ElasticsearchFindingsMapping = `{
+  "mappings": {
+    "dynamic_templates": [
+      {"strings": {"match_mapping_type": "string", "mapping": {"type": "keyword"}}}
+    ],
+    "properties": {
+      "@timestamp": {"type": "date"},
+      "session_id": {"type": "keyword"},
+      "scan_type": {"type": "keyword"},
+      "action": {"type": "keyword"},
+      "at_head": {"type": "boolean"},
+      "code_owners": {"type": "keyword"},
+      "comment": {"type": "keyword", "ignore_above": 1024},
+      "confidence": {"type": "keyword"},
+      "context": {"properties": {"line_number": {"type": "integer"}, "content": {"type": "text", "index": false}}},
+      "commit_author": {"type": "keyword"},
+      "commit_author_email": {"type": "keyword"},
+      "commit_author_name": {"type": "keyword"},
+      "commit_date": {"type": "date"},
+      "commit_hash": {"type": "keyword"},
+      "commit_message": {"type": "text"},
+      "commit_url": {"type": "keyword", "index": false},
+      "committer_email": {"type": "keyword"},
+      "committer_name": {"type": "keyword"},
+      "description": {"type": "keyword"},
+      "encoding": {"type": "keyword"},
+      "file_path": {"type": "keyword"},
+      "file_url": {"type": "keyword", "index": false},
+      "fingerprint": {"type": "keyword"},
+      "jwt": {"properties": {"expired": {"type": "boolean"}, "expires_at": {"type": "date"}, "issued_at": {"type": "date"}}},
+      "labels": {"type": "object"},
+      "line_number": {"type": "keyword"},
+      "repository_archived": {"type": "boolean"},
+      "repository_fork": {"type": "boolean"},
+      "repository_name": {"type": "keyword"},
+      "repository_owner": {"type": "keyword"},
+      "repository_pushed_at": {"type": "date"},
+      "repository_topics": {"type": "keyword"},
+      "repository_url": {"type": "keyword"},
+      "repository_visibility": {"type": "keyword"},
+      "score": {"type": "integer"},
+      "secret_id": {"type": "keyword"},
+      "severity": {"type": "keyword"},
+      "signature_id": {"type": "keyword"},
+      "signatures_version": {"type": "keyword"},
+      "verification": {"type": "keyword"},
+      "wraith_version": {"type": "keyword"}
+    }
+  }
+}`
+
+// ElasticsearchSessionsMapping is the mapping the index of sessions is created with
+const ElasticsearchSessionsMapping = `{
+  "mappings": {
+    "dynamic_templates": [
+      {"strings": {"match_mapping_type": "string", "mapping": {"type": "keyword"}}}
+    ],
+    "properties": {
+      "@timestamp": {"type": "date"},
+      "session_id": {"type": "keyword"},
+      "schema_version": {"type": "keyword"},
+      "wraith_version": {"type": "keyword"},
+      "signatures_version": {"type": "keyword"},
+      "scan_type": {"type": "keyword"},
+      "started_at": {"type": "date"},
+      "finished_at": {"type": "date"},
+      "duration_seconds": {"type": "long"},
+      "interrupted": {"type": "boolean"},
+      "stats": {"properties": {
+        "commits_dirty": {"type": "long"},
+        "commits_scanned": {"type": "long"},
+        "files_dirty": {"type": "long"},
+        "files_ignored": {"type": "long"},
+        "files_scanned": {"type": "long"},
+        "files_total": {"type": "long"},
+        "findings_total": {"type": "long"},
+        "repositories_cloned": {"type": "long"},
+        "repositories_scanned": {"type": "long"},
+        "repositories_total": {"type": "long"},
+        "targets": {"type": "long"}
+      }},
+      "risk": {"properties": {
+        "score": {"type": "long"},
+        "organizations": {"type": "object", "enabled": false},
+        "repositories": {"type": "object", "enabled": false}
+      }},
+      "threshold_failures": {"type": "keyword"}
+    }
+  }
+}`
+
+// ElasticsearchFinding is a finding as it is indexed, with the session it was found in
+type ElasticsearchFinding struct {
+	Timestamp string `json:"@timestamp"`
+	SessionID string `json:"session_id"`
+	ScanType  string `json:"scan_type"`
+	JSONFinding
+}
+
+// ElasticsearchSession is the summary of a session as it is indexed
+type ElasticsearchSession struct {
+	Timestamp       string `json:"@timestamp"`
+	SessionID       string `json:"session_id"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Interrupted     bool   `json:"interrupted"`
+	JSONSummary
+}
+
+// elasticsearchDocument is a document to index and the index and id it is indexed under
+type elasticsearchDocument struct {
+	index string
+	id    string
+	body  interface{}
+}
+
+// Elasticsearch bulk indexes the findings and the summary of a scan into elasticsearch or opensearch once it is done
+type Elasticsearch struct {
+	URL      string
+	Index    string
+	Username string
+	Password string
+	APIKey   string
+	client   *http.Client
+}
+
+// NewElasticsearch will create an exporter for a cluster, authenticating with a username and password or an api key
+func NewElasticsearch(endpoint, index, username, password, apiKey string) (*Elasticsearch, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", endpoint)
+	}
+	if index == "" || index != strings.ToLower(index) || strings.ContainsAny(index, `/\*?"<>| ,#`) {
+		return nil, fmt.Errorf("%q is not a valid index name", index)
+	}
+	if apiKey != "" && username != "" {
+		return nil, fmt.Errorf("an api key and a username can not both be used")
+	}
+
+	return &Elasticsearch{
+		URL:      strings.TrimRight(endpoint, "/"),
+		Index:    index,
+		Username: username,
+		Password: password,
+		APIKey:   apiKey,
+		client:   NewHTTPClient(WebhookTimeout * 3),
+	}, nil
+}
+
+// FindingsIndex is the index findings are indexed into
+func (e *Elasticsearch) FindingsIndex() string {
+	return e.Index + ElasticsearchFindingsSuffix
+}
+
+// SessionsIndex is the index the summaries of sessions are indexed into
+func (e *Elasticsearch) SessionsIndex() string {
+	return e.Index + ElasticsearchSessionsSuffix
+}
+
+// Send will make a request to the cluster, trying again when the request fails or the cluster returns a 429 or 5xx.
+// The body of the response is returned along with its status.
+func (e *Elasticsearch) Send(method, path, contentType string, body []byte) (int, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		status, data, retry, err := e.request(method, path, contentType, body)
+		if err == nil || !retry || attempt == WebhookAttempts {
+			return status, data, err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// request will make a single request to the cluster, returning whether it is worth trying again when it fails
+func (e *Elasticsearch) request(method, path, contentType string, body []byte) (int, []byte, bool, error) {
+	req, err := http.NewRequest(method, e.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	switch {
+	case e.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.APIKey)
+	case e.Username != "":
+		req.SetBasicAuth(e.Username, e.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, nil, true, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, true, err
+	}
+
+	// a missing index is an answer, not a failure
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, data, false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, data, retry, fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, elasticsearchError(data))
+}
+
+// elasticsearchError is the reason given in the body of an error response, or the body itself
+func elasticsearchError(data []byte) string {
+	var body struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error.Reason != "" {
+		return body.Error.Type + ": " + body.Error.Reason
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// EnsureIndex will create an index with a mapping unless it already exists, so an index set up by hand is left alone
+func (e *Elasticsearch) EnsureIndex(index, mapping string) error {
+	status, _, err := e.Send(http.MethodHead, "/"+index, "", nil)
+	if err != nil || status != http.StatusNotFound {
+		return err
+	}
+	_, data, err := e.Send(http.MethodPut, "/"+index, "application/json", []byte(mapping))
+	if err != nil && strings.Contains(string(data), "resource_already_exists_exception") {
+		// another scan created it in the meantime
+		return nil
+	}
+	return err
+}
+
+// Bulk will index documents in batches, returning the first document that could not be indexed
+func (e *Elasticsearch) Bulk(docs []elasticsearchDocument) error {
+	for start := 0; start < len(docs); start += ElasticsearchBulkSize {
+		end := start + ElasticsearchBulkSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		var body bytes.Buffer
+		for _, doc := range docs[start:end] {
+			action := map[string]map[string]string{"index": {"_index": doc.index, "_id": doc.id}}
+			for _, part := range []interface{}{action, doc.body} {
+				line, err := json.Marshal(part)
+				if err != nil {
+					return err
+				}
+				body.Write(line)
+				body.WriteByte('\n')
+			}
+		}
+
+		_, data, err := e.Send(http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+		if err != nil {
+			return err
+		}
+		if err := bulkError(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bulkError will return why the first document of a bulk request that failed was not indexed
+func bulkError(data []byte) error {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string `json:"_id"`
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("unable to read the bulk response: %s", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+	failed := 0
+	var first error
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				failed++
+				if first == nil {
+					first = fmt.Errorf("document %s: %s: %s", result.ID, result.Error.Type, result.Error.Reason)
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d %s not indexed, %s", failed, Pluralize(failed, "document was", "documents were"), first)
+}
+
+// elasticsearchFindingID is the id of a finding in the index, made from the session and where the finding is
+func elasticsearchFindingID(session string, f *Finding) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		session, f.RepositoryOwner, f.RepositoryName, f.Action, f.FilePath, f.LineNumber, f.CommitHash, f.Signatureid, f.Fingerprint,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewElasticsearchDocuments will build the documents of a finished session, a document for each finding and one for
+// the summary of the session. Findings are indexed under the session and where they are, so exporting a session again
+// replaces its documents rather than adding to them.
+func NewElasticsearchDocuments(e *Elasticsearch, s *Session) []elasticsearchDocument {
+	summary := NewJSONSummary(s)
+	s.Stats.Lock()
+	timestamp := s.Stats.FinishedAt.UTC().Format(time.RFC3339)
+	duration := int64(s.Stats.FinishedAt.Sub(s.Stats.StartedAt).Seconds())
+	s.Stats.Unlock()
+
+	var docs []elasticsearchDocument
+	s.Lock()
+	for _, f := range s.Findings {
+		jf := NewJSONFinding(f)
+		jf.SchemaVersion = JSONSchemaVersion
+		docs = append(docs, elasticsearchDocument{
+			index: e.FindingsIndex(),
+			id:    elasticsearchFindingID(s.ID, f),
+			body:  ElasticsearchFinding{Timestamp: timestamp, SessionID: s.ID, ScanType: s.ScanType, JSONFinding: jf},
+		})
+	}
+	s.Unlock()
+
+	return append(docs, elasticsearchDocument{
+		index: e.SessionsIndex(),
+		id:    s.ID,
+		body: ElasticsearchSession{
+			Timestamp:       timestamp,
+			SessionID:       s.ID,
+			DurationSeconds: duration,
+			Interrupted:     s.Interrupted(),
+			JSONSummary:     summary,
+		},
+	})
+}
+
+// Export will create the indexes when they do not exist yet and index the findings and summary of a session
+func (e *Elasticsearch) Export(s *Session) error {
+	if err := e.EnsureIndex(e.FindingsIndex(), ElasticsearchFindingsMapping); err != nil {
+		return err
+	}
+	if err := e.EnsureIndex(e.SessionsIndex(), ElasticsearchSessionsMapping); err != nil {
+		return err
+	}
+	return e.Bulk(NewElasticsearchDocuments(e, s))
+}
+
+// ExportElasticsearch will index the findings and summary of a finished scan if elasticsearch is configured
+func (s *Session) ExportElasticsearch() {
+	if s.Elasticsearch == nil {
+		return
+	}
+	if err := s.Elasticsearch.Export(s); err != nil {
+		s.Out.Error("Failed to export to elasticsearch: %s\n", err)
+		return
+	}
+	s.Out.Info("Indexed %d %s into %s\n", len(s.Findings), Pluralize(len(s.Findings), "finding", "findings"), s.Elasticsearch.FindingsIndex())
+}
@@ -0,0 +1,140 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestElasticsearch(t *testing.T) {
+
+	Convey("Given an elasticsearch cluster without the wraith indexes", t, func() {
+		var mu sync.Mutex
+		indexes := map[string]string{}
+		var requests []string
+		var bulk []string
+		var auth, contentType string
+		bulkResponse := `{"errors": false, "items": []}`
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			auth = r.Header.Get("Authorization")
+			switch {
+			case r.URL.Path == "/_bulk":
+				contentType = r.Header.Get("Content-Type")
+				bulk = strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+				w.Write([]byte(bulkResponse))
+			case r.Method == http.MethodHead:
+				if _, ok := indexes[r.URL.Path]; !ok {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			case r.Method == http.MethodPut:
+				indexes[r.URL.Path] = string(body)
+				w.Write([]byte(`{"acknowledged": true}`))
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		defer server.Close()
+
+		es, err := core.NewElasticsearch(server.URL+"/", "security", "", "", "a2V5OnNlY3JldA==")
+		So(err, ShouldBeNil)
+
+		startedAt := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			ID:            "0a1b2c3d",
+			Version:       "1.2.3",
+			ScanType:      "github",
+			Stats:         &core.Stats{StartedAt: startedAt, FinishedAt: startedAt.Add(90 * time.Second)},
+			Out:           &core.Logger{},
+			Silent:        true,
+			Elasticsearch: es,
+			Findings: []*core.Finding{
+				{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "config/prod.env", LineNumber: "3", CommitHash: "1111111111",
+					Signatureid: "aws-id", Description: "AWS Access Key ID", Severity: core.SeverityCritical, Fingerprint: "abc",
+					Labels: map[string]string{"team": "payments"}},
+				{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "config/prod.env", LineNumber: "9", CommitHash: "1111111111",
+					Signatureid: "aws-id", Description: "AWS Access Key ID", Fingerprint: "def"},
+			},
+		}
+
+		Convey("The indexes should be created with their mappings and every finding and the session indexed in bulk", func() {
+			sess.ExportElasticsearch()
+			So(requests, ShouldResemble, []string{
+				"HEAD /security-findings", "PUT /security-findings",
+				"HEAD /security-sessions", "PUT /security-sessions",
+				"POST /_bulk",
+			})
+			So(auth, ShouldEqual, "ApiKey a2V5OnNlY3JldA==")
+			So(contentType, ShouldEqual, "application/x-ndjson")
+			So(indexes["/security-findings"], ShouldEqual, core.ElasticsearchFindingsMapping)
+			So(indexes["/security-sessions"], ShouldEqual, core.ElasticsearchSessionsMapping)
+
+			So(bulk, ShouldHaveLength, 6)
+			var action map[string]map[string]string
+			So(json.Unmarshal([]byte(bulk[0]), &action), ShouldBeNil)
+			So(action["index"]["_index"], ShouldEqual, "security-findings")
+			So(action["index"]["_id"], ShouldNotBeEmpty)
+
+			var finding map[string]interface{}
+			So(json.Unmarshal([]byte(bulk[1]), &finding), ShouldBeNil)
+			So(finding["@timestamp"], ShouldEqual, "2020-08-03T09:01:30Z")
+			So(finding["session_id"], ShouldEqual, "0a1b2c3d")
+			So(finding["scan_type"], ShouldEqual, "github")
+			So(finding["signature_id"], ShouldEqual, "aws-id")
+			So(finding["file_path"], ShouldEqual, "config/prod.env")
+			So(finding["labels"], ShouldResemble, map[string]interface{}{"team": "payments"})
+
+			var second map[string]map[string]string
+			So(json.Unmarshal([]byte(bulk[2]), &second), ShouldBeNil)
+			So(second["index"]["_id"], ShouldNotEqual, action["index"]["_id"])
+
+			So(json.Unmarshal([]byte(bulk[4]), &action), ShouldBeNil)
+			So(action["index"], ShouldResemble, map[string]string{"_index": "security-sessions", "_id": "0a1b2c3d"})
+			var session map[string]interface{}
+			So(json.Unmarshal([]byte(bulk[5]), &session), ShouldBeNil)
+			So(session["duration_seconds"], ShouldEqual, 90)
+			So(session["wraith_version"], ShouldEqual, "1.2.3")
+			So(session["stats"], ShouldContainKey, "findings_total")
+		})
+
+		Convey("Indexes that already exist should be left alone", func() {
+			indexes["/security-findings"] = "{}"
+			indexes["/security-sessions"] = "{}"
+			So(es.Export(sess), ShouldBeNil)
+			So(requests, ShouldResemble, []string{"HEAD /security-findings", "HEAD /security-sessions", "POST /_bulk"})
+			So(indexes["/security-findings"], ShouldEqual, "{}")
+		})
+
+		Convey("Documents the cluster refused should fail the export", func() {
+			bulkResponse = `{"errors": true, "items": [
+  {"index": {"_id": "a", "status": 201}},
+  {"index": {"_id": "b", "status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [score]"}}}
+]}`
+			err := es.Export(sess)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "1 document was not indexed, document b: mapper_parsing_exception: failed to parse field [score]")
+		})
+	})
+
+	Convey("Settings that can not work should be refused", t, func() {
+		_, err := core.NewElasticsearch("localhost:9200", "wraith", "", "", "")
+		So(err, ShouldNotBeNil)
+		_, err = core.NewElasticsearch("http://localhost:9200", "Wraith", "", "", "")
+		So(err, ShouldNotBeNil)
+		_, err = core.NewElasticsearch("http://localhost:9200", "wraith", "elastic", "changeme", "a2V5OnNlY3JldA==")
+		So(err, ShouldNotBeNil)
+		_, err = core.NewElasticsearch("http://localhost:9200", "wraith", "elastic", "changeme", "")
+		So(err, ShouldBeNil)
+	})
+}
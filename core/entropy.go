@@ -0,0 +1,165 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// These are the character sets that entropy detection looks for random strings in
+const (
+	EntropyCharsetBase64 = "base64"
+	EntropyCharsetHex    = "hex"
+)
+
+// EntropyMatchLevel is the match level given to findings from entropy detection
+const EntropyMatchLevel = 3
+
+// entropyCandidates finds the runs of each character set that could be a secret. The base64 set includes the url safe
+// alphabet so tokens in either form are found whole.
+var entropyCandidates = map[string]*regexp.Regexp{
+	EntropyCharsetBase64: regexp.MustCompile(`[A-Za-z0-9+/_\-]+={0,2}`),
+	EntropyCharsetHex:    regexp.MustCompile(`[0-9a-fA-F]+`),
+}
+
+// hexString is used to leave strings that are entirely hex to the hex signature, they would otherwise match both
+var hexString = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// EntropySignature finds random looking strings of a character set, such as generic api keys and tokens, that no
+// pattern signature describes. A string is a match when it is at least minLength long and its shannon entropy is at
+// least the threshold.
+type EntropySignature struct {
+	charset   string
+	threshold float64
+	minLength int
+}
+
+// NewEntropySignature creates an entropy signature for either the base64 or the hex character set
+func NewEntropySignature(charset string, threshold float64, minLength int) EntropySignature {
+	return EntropySignature{
+		charset:   charset,
+		threshold: threshold,
+		minLength: minLength,
+	}
+}
+
+// FindHighEntropyStrings returns each string in content that is a match for the signature along with the line it is on
+func (s EntropySignature) FindHighEntropyStrings(content string) map[string]int {
+	results := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for n, line := range strings.Split(content, "\n") {
+		for _, candidate := range entropyCandidates[s.charset].FindAllString(line, -1) {
+			if len(candidate) < s.minLength || seen[candidate] {
+				continue
+			}
+			if s.charset == EntropyCharsetBase64 && hexString.MatchString(strings.TrimRight(candidate, "=")) {
+				continue
+			}
+			if getEntropyInt(candidate) < s.threshold || IsSafeText(&candidate) {
+				continue
+			}
+			seen[candidate] = true
+			results[strconv.Itoa(len(results))+"_"+candidate] = n + 1
+		}
+	}
+	return results
+}
+
+// ExtractMatch will look for high entropy strings in the content of the file and, when there is one, the change
+func (s EntropySignature) ExtractMatch(file MatchFile, sess *Session, change *object.Change) (bool, map[string]int) {
+	var content string
+
+	if change != nil {
+		c, err := GetChangeContent(change)
+		if err != nil {
+			sess.Out.Error("Error retrieving content in change %s: %s\n", change.String(), err)
+		}
+		content = c
+	} else if _, err := os.Stat(file.Path); err == nil {
+		data, err := ioutil.ReadFile(file.Path)
+		if err != nil {
+			sess.Out.Error("Unable to open %s for scanning: %s\n", file.Path, err)
+			return false, nil
+		}
+		content = string(data)
+	}
+
+	results := s.FindHighEntropyStrings(content)
+	if len(results) > 0 && len(sess.SuppressionMarkers) > 0 {
+		lines := strings.Split(content, "\n")
+		for k, n := range results {
+			if isSuppressed(lines, n, sess) {
+				delete(results, k)
+				sess.countSuppressed(strings.SplitAfterN(k, "_", 2)[1])
+			}
+		}
+	}
+	return len(results) > 0, results
+}
+
+// Enable sets whether as signature is active or not
+func (s EntropySignature) Enable() int {
+	return 1
+}
+
+// MatchLevel sets the confidence level of the pattern
+func (s EntropySignature) MatchLevel() int {
+	return EntropyMatchLevel
+}
+
+// Part sets the part of the file/path that is matched [ filename content extension ]
+func (s EntropySignature) Part() string {
+	return PartContent
+}
+
+// Description sets the user comment of the signature
+func (s EntropySignature) Description() string {
+	if s.charset == EntropyCharsetHex {
+		return "High entropy hex string"
+	}
+	return "High entropy base64 string"
+}
+
+// Signatureid sets the id used to identify the signature, it is the same for every scan so findings can be tracked
+func (s EntropySignature) Signatureid() string {
+	return "entropy-" + s.charset
+}
+
+// Severity is how much harm the secrets the signature finds can do, which is unknown for a random string
+func (s EntropySignature) Severity() string {
+	return ScoreSeverity(EntropyMatchLevel)
+}
+
+// Confidence is how likely a match is to be a real secret, plenty of random strings such as hashes are not
+func (s EntropySignature) Confidence() string {
+	return ConfidenceLow
+}
+
+// Tags puts both entropy signatures in the entropy category
+func (s EntropySignature) Tags() []string {
+	return []string{"entropy"}
+}
+
+// Remediation is nil for the entropy signatures, as a random string could be any kind of secret
+func (s EntropySignature) Remediation() *Remediation {
+	return nil
+}
+
+// loadEntropySignatures will create the entropy signatures the session has been configured with
+func (s *Session) loadEntropySignatures(base64Threshold float64, base64MinLength int, hexThreshold float64, hexMinLength int) []Signature {
+	if base64Threshold <= 0 || hexThreshold <= 0 {
+		s.Out.Fatal("Entropy thresholds must be greater than 0\n")
+	}
+	if base64MinLength < 1 || hexMinLength < 1 {
+		s.Out.Fatal("Entropy minimum lengths must be at least 1\n")
+	}
+	return []Signature{
+		NewEntropySignature(EntropyCharsetBase64, base64Threshold, base64MinLength),
+		NewEntropySignature(EntropyCharsetHex, hexThreshold, hexMinLength),
+	}
+}
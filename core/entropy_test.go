@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"wraith/core"
+)
+
+func TestEntropySignature(t *testing.T) {
+
+	content := `# settings for the deploy
+api_key = "tH9x2LqZ8vR4mK7pW1nB5cY3jD6fG0sA"
+checksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b"
+path = "src/main/resources/application.properties"
+short = "aB3$xY9"
+repeated = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+`
+
+	Convey("Given a base64 entropy signature", t, func() {
+		s := core.NewEntropySignature(core.EntropyCharsetBase64, 4.5, 20)
+
+		Convey("Only random base64 strings should match", func() {
+			So(s.FindHighEntropyStrings(content), ShouldResemble, map[string]int{
+				"0_tH9x2LqZ8vR4mK7pW1nB5cY3jD6fG0sA": 2,
+			})
+		})
+
+		Convey("It should describe itself as a content signature", func() {
+			So(s.Description(), ShouldEqual, "High entropy base64 string")
+			So(s.Signatureid(), ShouldEqual, "entropy-base64")
+			So(s.Part(), ShouldEqual, core.PartContent)
+			So(s.MatchLevel(), ShouldEqual, core.EntropyMatchLevel)
+		})
+
+		Convey("Strings shorter than the minimum length should be skipped", func() {
+			long := core.NewEntropySignature(core.EntropyCharsetBase64, 4.5, 40)
+			So(long.FindHighEntropyStrings(content), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a hex entropy signature", t, func() {
+		s := core.NewEntropySignature(core.EntropyCharsetHex, 3.0, 20)
+
+		Convey("Only random hex strings should match", func() {
+			So(s.FindHighEntropyStrings(content), ShouldResemble, map[string]int{
+				"0_9f86d081884c7d659a2feaa0c55ad015a3bf4f1b": 3,
+			})
+		})
+
+		Convey("A higher threshold should skip them", func() {
+			strict := core.NewEntropySignature(core.EntropyCharsetHex, 4.1, 20)
+			So(strict.FindHighEntropyStrings(content), ShouldBeEmpty)
+		})
+	})
+}
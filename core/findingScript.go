@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// FindingScriptFunction is the global function a finding script must define. It is called with a table holding the
+// finding and may change it in place. Returning false will suppress the finding.
+const FindingScriptFunction = "process"
+
+// FindingScriptTimeout is the longest a script is allowed to spend on a single finding
+const FindingScriptTimeout = 5 * time.Second
+
+// FindingScript is a user supplied lua script that post-processes every finding before it is recorded or output.
+// Only the base, string, table and math libraries are available so a script cannot touch the filesystem or network.
+type FindingScript struct {
+	sync.Mutex
+
+	path  string
+	state *lua.LState
+}
+
+// NewFindingScript will load the script and make sure it defines the process function
+func NewFindingScript(path string, logger *Logger) (*FindingScript, error) {
+	path = SetHomeDir(strings.TrimSpace(path))
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	// the base library can still reach the filesystem through these
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	L.SetGlobal("log", L.NewFunction(func(L *lua.LState) int {
+		logger.Debug("[LUA] %s\n", L.CheckString(1))
+		return 0
+	}))
+
+	if err := L.DoFile(path); err != nil {
+		L.Close()
+		return nil, err
+	}
+	if L.GetGlobal(FindingScriptFunction).Type() != lua.LTFunction {
+		L.Close()
+		return nil, fmt.Errorf("%s does not define a %s function", path, FindingScriptFunction)
+	}
+
+	return &FindingScript{path: path, state: L}, nil
+}
+
+// Process will hand the finding to the script and copy back any changes. It returns false if the script
+// suppressed the finding.
+func (fs *FindingScript) Process(f *Finding) (bool, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	L := fs.state
+	ctx, cancel := context.WithTimeout(context.Background(), FindingScriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+
+	tbl := findingToTable(L, f)
+	err := L.CallByParam(lua.P{
+		Fn:      L.GetGlobal(FindingScriptFunction),
+		NRet:    1,
+		Protect: true,
+	}, tbl)
+	if err != nil {
+		return true, err
+	}
+	ret := L.Get(-1)
+	L.Pop(1)
+
+	tableToFinding(tbl, f)
+	return ret != lua.LFalse, nil
+}
+
+// findingToTable will build the table handed to the script. The keys match the json output.
+func findingToTable(L *lua.LState, f *Finding) *lua.LTable {
+	tbl := L.NewTable()
+	jf := NewJSONFinding(f)
+	for k, v := range map[string]string{
+		"action":                jf.Action,
+		"comment":               jf.Comment,
+		"confidence":            jf.Confidence,
+		"commit_author":         jf.CommitAuthor,
+		"commit_author_email":   jf.CommitAuthorEmail,
+		"commit_author_name":    jf.CommitAuthorName,
+		"commit_date":           jf.CommitDate,
+		"commit_hash":           jf.CommitHash,
+		"commit_message":        jf.CommitMessage,
+		"commit_url":            jf.CommitURL,
+		"committer_email":       jf.CommitterEmail,
+		"committer_name":        jf.CommitterName,
+		"description":           jf.Description,
+		"encoding":              jf.Encoding,
+		"file_path":             jf.FilePath,
+		"file_url":              jf.FileURL,
+		"fingerprint":           jf.Fingerprint,
+		"line_number":           jf.LineNumber,
+		"repository_name":       jf.RepositoryName,
+		"repository_owner":      jf.RepositoryOwner,
+		"repository_url":        jf.RepositoryURL,
+		"repository_pushed_at":  jf.RepositoryPushedAt,
+		"repository_visibility": jf.RepositoryVisibility,
+		"secret_id":             jf.SecretID,
+		"severity":              jf.Severity,
+		"signature_id":          jf.SignatureID,
+		"signatures_version":    jf.SignaturesVersion,
+		"wraith_version":        jf.WraithVersion,
+	} {
+		tbl.RawSetString(k, lua.LString(v))
+	}
+	tbl.RawSetString("score", lua.LNumber(f.Score))
+	tbl.RawSetString("repository_archived", lua.LBool(f.RepositoryArchived))
+	tbl.RawSetString("repository_fork", lua.LBool(f.RepositoryFork))
+	tbl.RawSetString("code_owners", stringsToTable(L, f.CodeOwners))
+	tbl.RawSetString("repository_topics", stringsToTable(L, f.RepositoryTopics))
+
+	labels := L.NewTable()
+	for k, v := range f.Labels {
+		labels.RawSetString(k, lua.LString(v))
+	}
+	tbl.RawSetString("labels", labels)
+	return tbl
+}
+
+// stringsToTable will build a lua array from a slice of strings
+func stringsToTable(L *lua.LState, values []string) *lua.LTable {
+	tbl := L.NewTable()
+	for _, v := range values {
+		tbl.Append(lua.LString(v))
+	}
+	return tbl
+}
+
+// tableToFinding will copy the fields a script is allowed to change back onto the finding
+func tableToFinding(tbl *lua.LTable, f *Finding) {
+	if v, ok := tbl.RawGetString("comment").(lua.LString); ok {
+		f.Comment = string(v)
+	}
+	if v, ok := tbl.RawGetString("description").(lua.LString); ok {
+		f.Description = string(v)
+	}
+	// a new score rates the finding again unless the script also changes the severity itself
+	severity := FindingSeverity(f)
+	if v, ok := tbl.RawGetString("score").(lua.LNumber); ok && int(v) != f.Score {
+		f.Score = int(v)
+		f.Severity = ScoreSeverity(f.Score)
+	}
+	if v, ok := tbl.RawGetString("severity").(lua.LString); ok && string(v) != severity && ValidSeverity(string(v)) {
+		f.Severity = string(v)
+	}
+	if v, ok := tbl.RawGetString("confidence").(lua.LString); ok && ValidConfidence(string(v)) {
+		f.Confidence = string(v)
+	}
+	if labels, ok := tbl.RawGetString("labels").(*lua.LTable); ok {
+		f.Labels = map[string]string{}
+		labels.ForEach(func(k, v lua.LValue) {
+			f.Labels[k.String()] = v.String()
+		})
+		if len(f.Labels) == 0 {
+			f.Labels = nil
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+const testFindingScript = `
+function process(f)
+  if f.file_path == "docs/example.env" then
+    return false
+  end
+  f.score = f.score + 1
+  f.description = "[triaged] " .. f.description
+  f.labels.team = "platform"
+  f.repository_name = "changed"
+end
+`
+
+func TestFindingScript(t *testing.T) {
+
+	Convey("Given a finding script", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-script")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "triage.lua")
+		So(ioutil.WriteFile(path, []byte(testFindingScript), 0644), ShouldBeNil)
+
+		script, err := core.NewFindingScript(path, &core.Logger{})
+		So(err, ShouldBeNil)
+
+		Convey("When a finding is processed", func() {
+			f := &core.Finding{Description: "AWS key", FilePath: "config.yml", RepositoryName: "repo", Score: 2}
+			keep, err := script.Process(f)
+
+			Convey("It should be kept and changed by the script", func() {
+				So(err, ShouldBeNil)
+				So(keep, ShouldBeTrue)
+				So(f.Score, ShouldEqual, 3)
+				So(f.Description, ShouldEqual, "[triaged] AWS key")
+				So(f.Labels["team"], ShouldEqual, "platform")
+			})
+
+			Convey("It should not change read only fields", func() {
+				So(f.RepositoryName, ShouldEqual, "repo")
+			})
+		})
+
+		Convey("When the script returns false", func() {
+			keep, err := script.Process(&core.Finding{FilePath: "docs/example.env"})
+
+			Convey("The finding should be suppressed", func() {
+				So(err, ShouldBeNil)
+				So(keep, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a script without a process function", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-script")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "empty.lua")
+		So(ioutil.WriteFile(path, []byte("x = 1\n"), 0644), ShouldBeNil)
+
+		Convey("Loading it should fail", func() {
+			_, err := core.NewFindingScript(path, &core.Logger{})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
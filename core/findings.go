@@ -3,36 +3,78 @@ package core
 
 import (
 	"crypto/sha1"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 // Finding is a secret that has been discovered within a target by a discovery method
 type Finding struct {
-	Action            string
-	Comment           string
-	CommitAuthor      string
-	CommitHash        string
-	CommitMessage     string
-	CommitUrl         string
-	Description       string
-	FilePath          string
-	FileUrl           string
-	WraithVersion     string
-	Hash              string
-	LineNumber        string
-	RepositoryName    string
-	RepositoryOwner   string
-	RepositoryUrl     string
-	Signatureid       string
-	SignaturesVersion string
-	SecretID          string
+	Action               string
+	AtHead               bool
+	CodeOwners           []string
+	Comment              string
+	Confidence           string
+	Context              []ContextLine
+	CommitAuthor         string
+	CommitAuthorEmail    string
+	CommitAuthorName     string
+	CommitDate           string
+	CommitHash           string
+	CommitMessage        string
+	CommitUrl            string
+	CommitterEmail       string
+	CommitterName        string
+	Description          string
+	Encoding             string
+	FilePath             string
+	FileUrl              string
+	Fingerprint          string
+	FirstSeen            *SeenCommit
+	WraithVersion        string
+	Hash                 string
+	JWT                  *JWT
+	Labels               map[string]string
+	LastSeen             *SeenCommit
+	LineNumber           string
+	Occurrences          int
+	Remediation          *Remediation
+	RepositoryName       string
+	RepositoryArchived   bool
+	RepositoryFork       bool
+	RepositoryOwner      string
+	RepositoryPushedAt   string
+	RepositoryTopics     []string
+	RepositoryVisibility string
+	RepositoryUrl        string
+	Score                int
+	Signatureid          string
+	SignaturesVersion    string
+	SecretHash           string
+	SecretID             string
+	Severity             string
+	Triage               *Triage
+	Verification         string
+
+	// secret is the unredacted match, it is never output and only used to redact the same secret elsewhere
+	secret string
+	// line is the line of the file the secret was found on, it is only used for the fingerprint
+	line string
+	// originalPath is the path the file had before git detected it was renamed, it is only used for the fingerprint
+	originalPath string
+	// webURL is the page a finding that is not in the files of its repository links to, such as a github release
+	webURL string
 }
 
-// setupUrls will set the urls used to search through either github or gitlab for inclusion in the finding data
+// setupUrls will set the urls used to search through either github, gitlab or bitbucket for inclusion in the finding data
 func (f *Finding) setupUrls(scanType string) {
 	switch scanType {
-	case "github":
+	case "github", "githubPR":
 		f.RepositoryUrl = fmt.Sprintf("https://github.com/%s/%s", f.RepositoryOwner, f.RepositoryName)
 		f.FileUrl = fmt.Sprintf("%s/blob/%s/%s", f.RepositoryUrl, f.CommitHash, f.FilePath)
 		f.CommitUrl = fmt.Sprintf("%s/commit/%s", f.RepositoryUrl, f.CommitHash)
@@ -41,6 +83,13 @@ func (f *Finding) setupUrls(scanType string) {
 		f.RepositoryUrl = fmt.Sprintf("https://gitlab.com/%s/%s", results[0], results[1])
 		f.FileUrl = fmt.Sprintf("%s/blob/%s/%s", f.RepositoryUrl, f.CommitHash, f.FilePath)
 		f.CommitUrl = fmt.Sprintf("%s/commit/%s", f.RepositoryUrl, f.CommitHash)
+	case "bitbucket":
+		f.RepositoryUrl = fmt.Sprintf("%s/%s/%s", BitbucketBaseURL, f.RepositoryOwner, f.RepositoryName)
+		f.FileUrl = fmt.Sprintf("%s/src/%s/%s", f.RepositoryUrl, f.CommitHash, f.FilePath)
+		f.CommitUrl = fmt.Sprintf("%s/commits/%s", f.RepositoryUrl, f.CommitHash)
+	case "s3":
+		f.RepositoryUrl = fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s", f.RepositoryName)
+		f.FileUrl = fmt.Sprintf("https://s3.console.aws.amazon.com/s3/object/%s?prefix=%s", f.RepositoryName, url.QueryEscape(f.FilePath))
 	}
 
 }
@@ -63,8 +112,104 @@ func (f *Finding) generateID() {
 	f.SecretID = fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// setFingerprint will identify a finding by the repository, file and signature it was found with, the secret and the
+// line it is on. Unlike the secret id it leaves out the commit, so the same secret in the same place has the same
+// fingerprint in every commit, branch and scan, and after history is rewritten. A file git detects was renamed is
+// fingerprinted by the path it had before, so its findings keep their fingerprint when it is renamed or moved.
+func (f *Finding) setFingerprint() {
+	secret := strings.Trim(strings.TrimSpace(f.secret), `"'`)
+	line := strings.Join(strings.Fields(f.line), " ")
+	filePath := f.FilePath
+	if f.originalPath != "" {
+		filePath = f.originalPath
+	}
+
+	h := sha256.New()
+	for _, part := range []string{f.RepositoryOwner, f.RepositoryName, filePath, f.Signatureid, secret, line} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	f.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// setRepositoryMetadata will copy what is known about the repository a finding was made in onto the finding, along
+// with the owners of its file, so it can be triaged by exposure and routed to the right team
+func (f *Finding) setRepositoryMetadata(repo *Repository) {
+	if repo.Visibility != nil {
+		f.RepositoryVisibility = *repo.Visibility
+	}
+	if repo.Fork != nil {
+		f.RepositoryFork = *repo.Fork
+	}
+	if repo.Archived != nil {
+		f.RepositoryArchived = *repo.Archived
+	}
+	if repo.PushedAt != nil {
+		f.RepositoryPushedAt = repo.PushedAt.UTC().Format(time.RFC3339)
+	}
+	f.RepositoryTopics = repo.Topics
+	f.CodeOwners = repo.codeOwners.Owners(f.FilePath)
+	switch {
+	case repo.gist:
+		f.setGistUrls(repo.webURL)
+	case repo.githubEnterprise:
+		f.setGithubEnterpriseUrls(repo.webURL)
+	case repo.gitea:
+		f.setGiteaUrls(repo.webURL)
+	case repo.bitbucketServer:
+		f.setBitbucketServerUrls(repo.webURL)
+	case repo.listed != nil:
+		f.setRepoListUrls(repo.webURL)
+	case repo.codeCommit != nil:
+		f.setCodeCommitUrls(repo.webURL, repo.codeCommit.region)
+	case repo.webURL != "":
+		f.setAzureDevopsUrls(repo.webURL)
+	}
+	if f.webURL != "" {
+		f.FileUrl = f.webURL
+		f.CommitUrl = ""
+	}
+}
+
+// setCommit will set the commit a finding was introduced in, along with who wrote it, who committed it and when it
+// was written, so whoever has to remove the secret is known without a git blame
+func (f *Finding) setCommit(commit *object.Commit) {
+	f.CommitAuthor = commit.Author.String()
+	f.CommitAuthorEmail = commit.Author.Email
+	f.CommitAuthorName = commit.Author.Name
+	f.CommitDate = commit.Author.When.Format(time.RFC3339)
+	f.CommitHash = commit.Hash.String()
+	f.CommitMessage = strings.TrimSpace(commit.Message)
+	f.CommitterEmail = commit.Committer.Email
+	f.CommitterName = commit.Committer.Name
+}
+
+// Committer is the name and email of whoever committed the commit of a finding, written the way git writes them
+func (f *Finding) Committer() string {
+	if f.CommitterEmail == "" {
+		return f.CommitterName
+	}
+	return fmt.Sprintf("%s <%s>", f.CommitterName, f.CommitterEmail)
+}
+
+// BranchLabel is the label a finding made on a branch other than the default branch is given, naming the branch
+const BranchLabel = "branch"
+
+// setBranch will label a finding with the branch its commit was found on, when that is not the default branch. The
+// default branch is always the first of the heads that were scanned.
+func (f *Finding) setBranch(branch string, heads []BranchHead) {
+	if branch == "" || len(heads) == 0 || branch == heads[0].Name {
+		return
+	}
+	f.Labels = map[string]string{BranchLabel: branch}
+}
+
 // Initialize will set the urls and create an ID for inclusion within the finding
 func (f *Finding) Initialize(scanType string) {
 	f.setupUrls(scanType)
+	if f.webURL != "" {
+		f.FileUrl = f.webURL
+		f.CommitUrl = ""
+	}
 	f.generateID()
 }
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThresholdFailedExitCode is the exit code of a silent scan whose findings reached one of its --fail-on thresholds.
+// It is kept apart from PolicyFailedExitCode so a pipeline can tell which check failed it.
+const ThresholdFailedExitCode = 4
+
+// These are the severities a finding can be counted under in a --fail-on threshold, from the least to the most
+// severe. SeverityAny counts every finding.
+const (
+	SeverityAny      = "any"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities are the named severities from the least to the most severe
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// severityRanks orders the severities so a threshold can count the findings at or above its severity
+var severityRanks = map[string]int{SeverityAny: 0, SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+
+// These are how likely a finding is to be a real secret, from the least to the most likely
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// Confidences are the named confidences from the least to the most likely
+var Confidences = []string{ConfidenceLow, ConfidenceMedium, ConfidenceHigh}
+
+// confidenceRanks orders the confidences so findings below --min-confidence can be left out
+var confidenceRanks = map[string]int{ConfidenceLow: 1, ConfidenceMedium: 2, ConfidenceHigh: 3}
+
+// ValidSeverity will check if a severity is one of the named severities
+func ValidSeverity(severity string) bool {
+	return severityRanks[severity] > 0
+}
+
+// ValidConfidence will check if a confidence is one of the named confidences
+func ValidConfidence(confidence string) bool {
+	return confidenceRanks[confidence] > 0
+}
+
+// Threshold fails a scan once it has Count findings at or above Severity
+type Threshold struct {
+	Severity string
+	Count    int
+}
+
+// ParseThresholds will read thresholds written as severity:count pairs separated by commas, such as high:1,medium:10
+func ParseThresholds(spec string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pair := strings.SplitN(part, ":", 2)
+		severity := strings.ToLower(strings.TrimSpace(pair[0]))
+		if _, ok := severityRanks[severity]; !ok {
+			return nil, fmt.Errorf("unknown severity %s in %s, it must be one of: %s, %s", pair[0], part, SeverityAny, strings.Join(Severities, ", "))
+		}
+		count := 1
+		if len(pair) == 2 {
+			n, err := strconv.Atoi(strings.TrimSpace(pair[1]))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("the count in %s must be a whole number of at least 1", part)
+			}
+			count = n
+		}
+		thresholds = append(thresholds, Threshold{Severity: severity, Count: count})
+	}
+	return thresholds, nil
+}
+
+// FindingSeverity returns the named severity of a finding, which is the severity of its signature unless a finding
+// script or policy has changed it. Findings without one, such as those from plugins, are rated by their score.
+func FindingSeverity(f *Finding) string {
+	if f.Severity != "" {
+		return f.Severity
+	}
+	return ScoreSeverity(f.Score)
+}
+
+// FindingConfidence returns the named confidence of a finding, which is the confidence of its signature. Findings
+// without one, such as those from plugins, are rated by their score.
+func FindingConfidence(f *Finding) string {
+	if f.Confidence != "" {
+		return f.Confidence
+	}
+	return ScoreConfidence(f.Score)
+}
+
+// ScoreSeverity returns the named severity of a finding score
+func ScoreSeverity(score int) string {
+	if score <= 0 {
+		score = RiskDefaultSeverity
+	}
+	switch {
+	case score >= 5:
+		return SeverityCritical
+	case score == 4:
+		return SeverityHigh
+	case score == 3:
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+// meetsMinimums will check if a finding is rated at or above the --min-severity and --min-confidence of the session
+func (s *Session) meetsMinimums(f *Finding) bool {
+	if s.MinSeverity != "" && severityRanks[FindingSeverity(f)] < severityRanks[s.MinSeverity] {
+		return false
+	}
+	if s.MinConfidence != "" && confidenceRanks[FindingConfidence(f)] < confidenceRanks[s.MinConfidence] {
+		return false
+	}
+	return true
+}
+
+// ScoreConfidence returns the named confidence of a match level, for signatures that do not set one
+func ScoreConfidence(score int) string {
+	if score <= 0 {
+		score = RiskDefaultSeverity
+	}
+	switch {
+	case score >= 4:
+		return ConfidenceHigh
+	case score == 3:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// SeverityCounts returns the number of findings of each severity
+func SeverityCounts(findings []*Finding) map[string]int {
+	counts := map[string]int{}
+	for _, s := range Severities {
+		counts[s] = 0
+	}
+	for _, f := range findings {
+		counts[FindingSeverity(f)]++
+	}
+	return counts
+}
+
+// CheckThresholds returns a message for each threshold the findings have reached
+func CheckThresholds(thresholds []Threshold, findings []*Finding) []string {
+	var failures []string
+	for _, t := range thresholds {
+		n := 0
+		for _, f := range findings {
+			if severityRanks[FindingSeverity(f)] >= severityRanks[t.Severity] {
+				n++
+			}
+		}
+		if n < t.Count {
+			continue
+		}
+		if t.Severity == SeverityAny {
+			failures = append(failures, fmt.Sprintf("%d %s, the limit is %d", n, Pluralize(n, "finding", "findings"), t.Count))
+		} else {
+			failures = append(failures, fmt.Sprintf("%d %s or above %s, the limit is %d", n, t.Severity, Pluralize(n, "finding", "findings"), t.Count))
+		}
+	}
+	return failures
+}
+
+// EvaluateThresholds will check the findings of a finished scan against its --fail-on thresholds
+func (s *Session) EvaluateThresholds() {
+	if len(s.Thresholds) == 0 {
+		return
+	}
+
+	s.Lock()
+	s.ThresholdFailures = CheckThresholds(s.Thresholds, s.Findings)
+	s.Unlock()
+}
+
+// ExitCode returns the code a silent scan should exit with, InterruptedExitCode if it was stopped before it finished,
+// PolicyFailedExitCode if its policy failed it or ThresholdFailedExitCode if its findings reached a threshold
+func (s *Session) ExitCode() int {
+	switch {
+	case s.Interrupted():
+		return InterruptedExitCode
+	case len(s.PolicyFailures) > 0:
+		return PolicyFailedExitCode
+	case len(s.ThresholdFailures) > 0:
+		return ThresholdFailedExitCode
+	default:
+		return 0
+	}
+}
+
+// PrintCISummary will print a compact summary of a finished scan for pipeline logs, with one line for each finding
+// from the most to the least severe and whether the scan passed. It is printed even though a CI scan is silent.
+func PrintCISummary(sess *Session) {
+	w := sess.stdout()
+	sess.Lock()
+	findings := append([]*Finding(nil), sess.Findings...)
+	sess.Unlock()
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRanks[FindingSeverity(findings[i])] > severityRanks[FindingSeverity(findings[j])]
+	})
+
+	counts := SeverityCounts(findings)
+	fmt.Fprintf(w, "%s: %d %s (critical %d, high %d, medium %d, low %d) in %d scanned %s\n",
+		Name, len(findings), Pluralize(len(findings), "finding", "findings"),
+		counts[SeverityCritical], counts[SeverityHigh], counts[SeverityMedium], counts[SeverityLow],
+		sess.Stats.FilesScanned, Pluralize(sess.Stats.FilesScanned, "file", "files"))
+	if sess.Baseline != nil {
+		fmt.Fprintf(w, "%s: %d baselined %s left out\n", Name, sess.Stats.FindingsBaselined, Pluralize(sess.Stats.FindingsBaselined, "finding", "findings"))
+	}
+
+	for _, f := range findings {
+		location := f.FilePath
+		if location == "" {
+			// findings in a commit message or note have no file
+			location = strings.ToLower(f.Action)
+		}
+		if f.LineNumber != "" && f.LineNumber != "0" {
+			location += ":" + f.LineNumber
+		}
+		if f.RepositoryOwner != "" && f.RepositoryOwner != "not-a-repo" {
+			location = f.RepositoryOwner + "/" + f.RepositoryName + " " + location
+		}
+		line := fmt.Sprintf("%-8s %s %s", strings.ToUpper(FindingSeverity(f)), location, f.Description)
+		if len(f.CommitHash) >= 8 {
+			line += " " + f.CommitHash[:8]
+		}
+		if f.Verification != "" {
+			line += " [" + f.Verification + "]"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	for _, msg := range sess.PolicyFailures {
+		fmt.Fprintf(w, "FAIL policy: %s\n", msg)
+	}
+	for _, msg := range sess.ThresholdFailures {
+		fmt.Fprintf(w, "FAIL threshold: %s\n", msg)
+	}
+	if sess.Interrupted() {
+		fmt.Fprintln(w, "INTERRUPTED: the scan was stopped before it finished, these are only the findings made until then")
+	}
+	if sess.ExitCode() == 0 {
+		fmt.Fprintln(w, "PASS")
+	}
+}
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"wraith/core"
+)
+
+func TestThresholds(t *testing.T) {
+
+	Convey("Thresholds should be parsed from severity and count pairs", t, func() {
+		thresholds, err := core.ParseThresholds("high:1, Medium:10,any")
+		So(err, ShouldBeNil)
+		So(thresholds, ShouldResemble, []core.Threshold{
+			{Severity: core.SeverityHigh, Count: 1},
+			{Severity: core.SeverityMedium, Count: 10},
+			{Severity: core.SeverityAny, Count: 1},
+		})
+
+		thresholds, err = core.ParseThresholds("")
+		So(err, ShouldBeNil)
+		So(thresholds, ShouldBeEmpty)
+	})
+
+	Convey("An unknown severity or a count below 1 should be an error", t, func() {
+		_, err := core.ParseThresholds("severe:1")
+		So(err, ShouldNotBeNil)
+		_, err = core.ParseThresholds("high:0")
+		So(err, ShouldNotBeNil)
+		_, err = core.ParseThresholds("high:lots")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("The severity of a finding should come from its score", t, func() {
+		So(core.FindingSeverity(&core.Finding{Score: 1}), ShouldEqual, core.SeverityLow)
+		So(core.FindingSeverity(&core.Finding{Score: 3}), ShouldEqual, core.SeverityMedium)
+		So(core.FindingSeverity(&core.Finding{Score: 4}), ShouldEqual, core.SeverityHigh)
+		So(core.FindingSeverity(&core.Finding{Score: 9}), ShouldEqual, core.SeverityCritical)
+		So(core.FindingSeverity(&core.Finding{}), ShouldEqual, core.SeverityMedium)
+	})
+
+	Convey("Given findings of each severity", t, func() {
+		findings := []*core.Finding{{Score: 5}, {Score: 4}, {Score: 3}, {Score: 3}, {Score: 1}}
+
+		Convey("They should be counted by severity", func() {
+			So(core.SeverityCounts(findings), ShouldResemble, map[string]int{
+				core.SeverityLow: 1, core.SeverityMedium: 2, core.SeverityHigh: 1, core.SeverityCritical: 1,
+			})
+		})
+
+		Convey("A threshold should count the findings at or above its severity", func() {
+			thresholds, _ := core.ParseThresholds("high:2,medium:5,low:5")
+			failures := core.CheckThresholds(thresholds, findings)
+			So(failures, ShouldHaveLength, 2)
+			So(failures[0], ShouldEqual, "2 high or above findings, the limit is 2")
+			So(failures[1], ShouldEqual, "5 low or above findings, the limit is 5")
+		})
+
+		Convey("A scan whose findings reached a threshold should exit with its own code", func() {
+			sess := &core.Session{Findings: findings}
+			sess.Thresholds, _ = core.ParseThresholds("critical:1")
+			sess.EvaluateThresholds()
+			So(sess.ExitCode(), ShouldEqual, core.ThresholdFailedExitCode)
+
+			sess.PolicyFailures = []string{"no secrets in production"}
+			So(sess.ExitCode(), ShouldEqual, core.PolicyFailedExitCode)
+		})
+
+		Convey("A scan without thresholds should pass", func() {
+			sess := &core.Session{Findings: findings}
+			sess.EvaluateThresholds()
+			So(sess.ExitCode(), ShouldEqual, 0)
+		})
+	})
+}
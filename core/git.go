@@ -9,13 +9,19 @@ import (
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Set easier names to refer to
 const (
 	TargetTypeUser         = "User"
 	TargetTypeOrganization = "Organization"
+	TargetTypeRepository   = "Repository"
 )
 
 // CloneConfiguration holds the configurations for cloning a repo
@@ -26,6 +32,57 @@ type CloneConfiguration struct {
 	Token      *string
 	Branch     *string
 	Depth      *int
+
+	// AllBranches fetches every branch rather than only Branch, so they can all be scanned
+	AllBranches *bool
+
+	// SSHKey is a private key file an ssh url is cloned with, rather than the keys of ssh-agent, and SSHKeyPassphrase
+	// decrypts it. SSHKnownHosts is checked for the key of the host rather than ~/.ssh/known_hosts.
+	SSHKey           *string
+	SSHKeyPassphrase *string
+	SSHKnownHosts    *string
+
+	// Bearer sends Token as a bearer token rather than as the password of Username, as bitbucket server takes its
+	// http access tokens
+	Bearer bool
+}
+
+// singleBranch is whether only the branch of the configuration is fetched when cloning
+func (c *CloneConfiguration) singleBranch() bool {
+	return c.AllBranches == nil || !*c.AllBranches
+}
+
+// gitClone will create either an in memory clone of the repository of a configuration or clone it to a temp dir, with
+// the auth of the configuration. Without a branch whatever HEAD points at is cloned, as gists and the lines of a repo
+// list do not say which branch they use.
+func gitClone(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	cloneOptions := &git.CloneOptions{
+		URL:          *cloneConfig.Url,
+		Depth:        *cloneConfig.Depth,
+		SingleBranch: cloneConfig.singleBranch(),
+		Tags:         git.NoTags,
+		Auth:         cloneConfig.auth(),
+	}
+	if cloneConfig.Branch != nil && *cloneConfig.Branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(*cloneConfig.Branch)
+	}
+
+	var repository *git.Repository
+	var err error
+	var dir string
+	if !*cloneConfig.InMemClone {
+		dir, err = ioutil.TempDir("", "wraith")
+		if err != nil {
+			return nil, "", err
+		}
+		repository, err = git.PlainClone(dir, false, cloneOptions)
+	} else {
+		repository, err = git.Clone(memory.NewStorage(), nil, cloneOptions)
+	}
+	if err != nil {
+		return nil, dir, err
+	}
+	return repository, dir, nil
 }
 
 // Owner holds the info that we want for a repo owner
@@ -41,6 +98,30 @@ type Owner struct {
 	Location  *string
 	Email     *string
 	Bio       *string
+
+	// member is set for the members an organization was expanded into, rather than the targets that were asked for
+	member bool
+}
+
+// These are the repositories of an organization member that are gathered, those they own, those they have been added
+// to as a collaborator or both
+const (
+	MemberAffiliationOwner  = "owner"
+	MemberAffiliationMember = "member"
+	MemberAffiliationAll    = "all"
+)
+
+// MemberAffiliations are the affiliations a member can have with the repositories that are gathered from them
+var MemberAffiliations = []string{MemberAffiliationOwner, MemberAffiliationMember, MemberAffiliationAll}
+
+// ValidMemberAffiliation will check if the repositories of members can be gathered by an affiliation
+func ValidMemberAffiliation(affiliation string) bool {
+	for _, a := range MemberAffiliations {
+		if affiliation == a {
+			return true
+		}
+	}
+	return false
 }
 
 // Repository holds the info we want for a repo itself
@@ -54,8 +135,42 @@ type Repository struct {
 	DefaultBranch *string
 	Description   *string
 	Homepage      *string
+	Visibility    *string
+	Fork          *bool
+	Archived      *bool
+	PushedAt      *time.Time
+	Topics        []string
+	Languages     []string // the languages of the repository, the main one first
+	Size          *int64   // the size of the repository in bytes, when the provider says how large it is
+
+	// codeOwners are read from the clone so findings can be routed to the owners of their file
+	codeOwners *CodeOwners
+
+	// webURL is set for repositories whose findings cannot be linked from their owner and name alone, such as gists
+	// and azure devops, gitea and bitbucket server repositories, which can be hosted anywhere
+	webURL           string
+	gist             bool
+	githubEnterprise bool
+	gitea            bool
+	bitbucketServer  bool
+
+	// sshURL is the ssh url the provider gave for the repository, which it is cloned from with clone-over-ssh
+	sshURL string
+
+	// listed is set for repositories read from a list by scanRepoList, with what each of them is cloned with
+	listed *repoListAuth
+
+	// codeCommit is set for repositories in CodeCommit, with the account and region their clone url is signed for
+	codeCommit *codeCommitSource
 }
 
+// These are the visibilities a repository can have, internal is only used by gitlab and gitea
+const (
+	VisibilityPublic   = "public"
+	VisibilityPrivate  = "private"
+	VisibilityInternal = "internal"
+)
+
 // EmptyTreeCommit is a dummy commit id used as a placeholder and for testing
 const (
 	EmptyTreeCommitId = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
@@ -96,6 +211,209 @@ func GetRepositoryHistory(repository *git.Repository) ([]*object.Commit, error)
 	return commits, nil
 }
 
+// GetRepositoryHistorySince gets the commits of a repository that were added after since, which is every commit that
+// is not an ancestor of it. The whole history is returned if since is empty or is no longer in the repository, such as
+// after a force push.
+func GetRepositoryHistorySince(repository *git.Repository, since string) ([]*object.Commit, error) {
+	if since == "" {
+		return GetRepositoryHistory(repository)
+	}
+	heads, err := GetBranchHeads(repository, nil)
+	if err != nil {
+		return nil, err
+	}
+	commits, _, err := GetBranchHistory(repository, heads, []string{since})
+	return commits, err
+}
+
+// ScanBranchesAll is given to scan-branches to scan every branch of a repository
+const ScanBranchesAll = "all"
+
+// BranchHead is the commit at the tip of a branch
+type BranchHead struct {
+	Name string
+	Hash plumbing.Hash
+}
+
+// GetBranchHeads returns the tips of the branches of a clone that are to be scanned. The branch HEAD points at is
+// always first, followed by every other branch when branches is all, or by those named in it, in order of their name.
+func GetBranchHeads(repository *git.Repository, branches []string) ([]BranchHead, error) {
+	head, err := repository.Head()
+	if err != nil {
+		return nil, err
+	}
+	heads := []BranchHead{{Name: head.Name().Short(), Hash: head.Hash()}}
+	if len(branches) == 0 {
+		return heads, nil
+	}
+
+	all := false
+	wanted := map[string]bool{}
+	for _, b := range branches {
+		b = strings.TrimSpace(b)
+		all = all || b == ScanBranchesAll
+		wanted[b] = true
+	}
+
+	// branches other than the one that was checked out are only fetched as remote branches
+	found := map[string]plumbing.Hash{}
+	refs, err := repository.References()
+	if err != nil {
+		return nil, err
+	}
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		var name string
+		switch {
+		case ref.Name().IsBranch():
+			name = ref.Name().Short()
+		case strings.HasPrefix(ref.Name().String(), "refs/remotes/origin/"):
+			name = strings.TrimPrefix(ref.Name().String(), "refs/remotes/origin/")
+		default:
+			return nil
+		}
+		if name != "HEAD" && name != heads[0].Name && (all || wanted[name]) {
+			found[name] = ref.Hash()
+		}
+		return nil
+	})
+
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		heads = append(heads, BranchHead{Name: name, Hash: found[name]})
+	}
+	return heads, nil
+}
+
+// GetBranchHistory gets the commits that can be reached from any of the branch heads but not from a commit in since,
+// along with the branch each of them was first reached from. Commits in since that are no longer in the repository,
+// such as after a force push, are ignored.
+func GetBranchHistory(repository *git.Repository, heads []BranchHead, since []string) ([]*object.Commit, map[plumbing.Hash]string, error) {
+	seen := map[plumbing.Hash]bool{}
+	for _, s := range since {
+		if s == "" {
+			continue
+		}
+		hash := plumbing.NewHash(s)
+		if _, err := repository.CommitObject(hash); err != nil {
+			continue
+		}
+		sIter, err := repository.Log(&git.LogOptions{From: hash})
+		if err != nil {
+			return nil, nil, err
+		}
+		_ = sIter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+	}
+
+	var commits []*object.Commit
+	branches := map[plumbing.Hash]string{}
+	for _, head := range heads {
+		cIter, err := repository.Log(&git.LogOptions{From: head.Hash})
+		if err != nil {
+			return nil, nil, err
+		}
+		_ = cIter.ForEach(func(c *object.Commit) error {
+			if !seen[c.Hash] {
+				seen[c.Hash] = true
+				commits = append(commits, c)
+				branches[c.Hash] = head.Name
+			}
+			return nil
+		})
+	}
+	return commits, branches, nil
+}
+
+// renamedFiles will return the files a list of changes renames the way git detects an exact rename, a file deleted
+// and another added with the same content, as the path each was renamed from keyed by the path it was renamed to
+func renamedFiles(changes object.Changes) map[string]string {
+	deleted := map[plumbing.Hash][]string{}
+	var inserted []object.ChangeEntry
+	for _, change := range changes {
+		switch {
+		case change.From.Name == "" && change.To.Name != "":
+			inserted = append(inserted, change.To)
+		case change.To.Name == "" && change.From.Name != "":
+			deleted[change.From.TreeEntry.Hash] = append(deleted[change.From.TreeEntry.Hash], change.From.Name)
+		}
+	}
+
+	// when several files with the same content are moved at once they are paired in the order of their paths
+	renamed := map[string]string{}
+	sort.Slice(inserted, func(i, j int) bool { return inserted[i].Name < inserted[j].Name })
+	for _, from := range deleted {
+		sort.Strings(from)
+	}
+	for _, to := range inserted {
+		from := deleted[to.TreeEntry.Hash]
+		if len(from) == 0 {
+			continue
+		}
+		renamed[to.Name] = from[0]
+		deleted[to.TreeEntry.Hash] = from[1:]
+	}
+	return renamed
+}
+
+// renameOrigins will follow the renames in a history from its oldest commit, and return the path each renamed file
+// was first added under for every commit it has another path in. A path that is deleted or added again outside of a
+// rename is another file from then on.
+func renameOrigins(repo *git.Repository, history []*object.Commit) map[plumbing.Hash]map[string]string {
+	byCommit := map[plumbing.Hash]map[string]string{}
+	origins := map[string]string{}
+	for i := len(history) - 1; i >= 0; i-- {
+		changes, err := GetChanges(history[i], repo)
+		if err != nil {
+			continue
+		}
+		renamed := renamedFiles(changes)
+
+		// the origins of a commit are shared with the commits after it until one of them changes them
+		var next map[string]string
+		edit := func() map[string]string {
+			if next == nil {
+				next = make(map[string]string, len(origins)+len(renamed))
+				for to, from := range origins {
+					next[to] = from
+				}
+			}
+			return next
+		}
+		for _, change := range changes {
+			name := GetChangePath(change)
+			if _, ok := renamed[name]; ok {
+				continue
+			}
+			if _, ok := origins[name]; ok && (change.From.Name == "" || change.To.Name == "") {
+				delete(edit(), name)
+			}
+		}
+		for to, from := range renamed {
+			origin := from
+			if o, ok := origins[from]; ok {
+				origin = o
+			}
+			edit()[to] = origin
+		}
+		if next != nil {
+			origins = next
+		}
+		if len(origins) > 0 {
+			byCommit[history[i].Hash] = origins
+		}
+	}
+	return byCommit
+}
+
 // GetChanges will get the changes between to specific commits
 func GetChanges(commit *object.Commit, repo *git.Repository) (object.Changes, error) {
 	parentCommit, err := getParentCommit(commit, repo)
@@ -174,3 +492,24 @@ func GetChangeContent(change *object.Change) (result string, contentError error)
 	}
 	return result, nil
 }
+
+// changeContents returns the content of the file of a change before and after the change, either is empty when the
+// file was added or deleted by it
+func changeContents(change *object.Change) (string, string, error) {
+	from, to, err := change.Files()
+	if err != nil {
+		return "", "", err
+	}
+	var before, after string
+	if from != nil {
+		if before, err = from.Contents(); err != nil {
+			return "", "", err
+		}
+	}
+	if to != nil {
+		if after, err = to.Contents(); err != nil {
+			return "", "", err
+		}
+	}
+	return before, after, nil
+}
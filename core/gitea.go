@@ -0,0 +1,338 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// GiteaTokenUser is the username sent with an access token when cloning, gitea and forgejo ignore it but git needs one
+const GiteaTokenUser = "wraith"
+
+// giteaPageSize is how many items are asked for in each page of a list, it is the most gitea returns by default
+const giteaPageSize = 50
+
+// CloneGiteaRepository will create either an in memory clone of a given repository or clone to a temp dir.
+func CloneGiteaRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
+
+// giteaClient holds the access token used to talk to the api of a gitea or forgejo instance, forgejo keeps the api of
+// gitea so one client serves both
+type giteaClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	logger  *Logger
+}
+
+// NewClient creates a gitea api client instance using an access token
+func (c giteaClient) NewClient(baseURL, token string, logger *Logger) giteaClient {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	c.token = token
+	c.client = NewHTTPClient(30 * time.Second)
+	c.logger = logger
+	return c
+}
+
+// NewGiteaClient creates a gitea api client for the instance at baseURL
+func NewGiteaClient(baseURL, token string, logger *Logger) IClient {
+	return giteaClient.NewClient(giteaClient{}, baseURL, token, logger)
+}
+
+// CheckGiteaCredentials will ensure we have a token, a server and something to scan before talking to gitea
+func CheckGiteaCredentials(sess *Session) error {
+	if !ValidGiteaToken(sess.GiteaAccessToken) {
+		return errors.New("Gitea requires an access token with the read:repository, read:organization and read:user scopes")
+	}
+	if strings.TrimSpace(sess.GiteaURL) == "" {
+		return errors.New("Gitea requires the url of the server, such as --gitea-url https://gitea.example.com")
+	}
+	if len(sess.GiteaTargets) == 0 {
+		return errors.New("Gitea requires at least one organization, user, or owner/repository, to scan")
+	}
+	return nil
+}
+
+// ValidGiteaToken will check that an access token was given
+func ValidGiteaToken(token string) bool {
+	return strings.TrimSpace(token) != ""
+}
+
+// get will request a path below the api of the server and decode the json response
+func (c giteaClient) get(path string, query url.Values, out interface{}) error {
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Authorization", "token "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("gitea returned %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("gitea returned %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		return errors.New("gitea did not return json, check the gitea-url")
+	}
+	return json.Unmarshal(data, out)
+}
+
+// giteaOwner is a user or an organization as returned by the api. Users have a login and organizations a username,
+// which gitea returns as both for organizations.
+type giteaOwner struct {
+	ID          int64  `json:"id"`
+	Login       string `json:"login"`
+	UserName    string `json:"username"`
+	FullName    string `json:"full_name"`
+	Email       string `json:"email"`
+	AvatarURL   string `json:"avatar_url"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+	Location    string `json:"location"`
+}
+
+// giteaRepository is a repository as returned by the api
+type giteaRepository struct {
+	ID            int64      `json:"id"`
+	Owner         giteaOwner `json:"owner"`
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"`
+	Description   string     `json:"description"`
+	Website       string     `json:"website"`
+	Empty         bool       `json:"empty"`
+	Private       bool       `json:"private"`
+	Internal      bool       `json:"internal"`
+	Fork          bool       `json:"fork"`
+	Archived      bool       `json:"archived"`
+	HTMLURL       string     `json:"html_url"`
+	CloneURL      string     `json:"clone_url"`
+	SSHURL        string     `json:"ssh_url"`
+	DefaultBranch string     `json:"default_branch"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Topics        []string   `json:"topics"`
+	Language      string     `json:"language"`
+	Size          int64      `json:"size"`
+}
+
+// GetUserOrganization will look up an organization or a user, or a single repository when the target is
+// owner/repository
+func (c giteaClient) GetUserOrganization(login string) (*Owner, error) {
+	if login == "" {
+		return nil, errors.New("an organization, user or repository is required")
+	}
+	emptyString := ""
+
+	if strings.Contains(login, "/") {
+		var repo giteaRepository
+		if err := c.get("/repos/"+giteaEscapeFullName(login), nil, &repo); err != nil {
+			return nil, err
+		}
+		id := stringID(c.baseURL + "/" + strings.ToLower(repo.FullName))
+		return &Owner{
+			Login:     &login,
+			ID:        &id,
+			Type:      stringPointer(TargetTypeRepository),
+			Name:      stringPointer(repo.FullName),
+			AvatarURL: &emptyString,
+			URL:       stringPointer(repo.HTMLURL),
+			Company:   &emptyString,
+			Blog:      stringPointer(repo.Website),
+			Location:  &emptyString,
+			Email:     &emptyString,
+			Bio:       stringPointer(repo.Description),
+		}, nil
+	}
+
+	var owner giteaOwner
+	ownerType := TargetTypeOrganization
+	if err := c.get("/orgs/"+url.PathEscape(login), nil, &owner); err != nil {
+		// there is no api to look up an owner without knowing what it is, so one that is not an organization is a user
+		ownerType = TargetTypeUser
+		if userErr := c.get("/users/"+url.PathEscape(login), nil, &owner); userErr != nil {
+			return nil, userErr
+		}
+	}
+	if owner.Login == "" {
+		owner.Login = owner.UserName
+	}
+
+	return &Owner{
+		Login:     stringPointer(owner.Login),
+		ID:        &owner.ID,
+		Type:      &ownerType,
+		Name:      stringPointer(owner.FullName),
+		AvatarURL: stringPointer(owner.AvatarURL),
+		URL:       stringPointer(c.baseURL + "/" + url.PathEscape(owner.Login)),
+		Company:   &emptyString,
+		Blog:      stringPointer(owner.Website),
+		Location:  stringPointer(owner.Location),
+		Email:     stringPointer(owner.Email),
+		Bio:       stringPointer(owner.Description),
+	}, nil
+}
+
+// GetRepositoriesFromOwner will gather the repositories of an organization or a user, or the one repository a target
+// is limited to
+func (c giteaClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	var repos []giteaRepository
+	switch *target.Type {
+	case TargetTypeRepository:
+		var repo giteaRepository
+		if err := c.get("/repos/"+giteaEscapeFullName(*target.Login), nil, &repo); err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	default:
+		path := "/users/" + url.PathEscape(*target.Login) + "/repos"
+		if *target.Type == TargetTypeOrganization {
+			path = "/orgs/" + url.PathEscape(*target.Login) + "/repos"
+		}
+		for page := 1; ; page++ {
+			var list []giteaRepository
+			query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+			if err := c.get(path, query, &list); err != nil {
+				return nil, err
+			}
+			repos = append(repos, list...)
+			if len(list) < giteaPageSize {
+				break
+			}
+		}
+	}
+
+	var allRepos []*Repository
+	for _, repo := range repos {
+		// don't capture forks, unless it is the one repository that was asked for
+		if repo.Fork && *target.Type != TargetTypeRepository {
+			continue
+		}
+		// a repository with no commits has no default branch to scan
+		if repo.Empty || repo.DefaultBranch == "" {
+			c.logger.Debug(" Skipping %s as it is empty\n", repo.FullName)
+			continue
+		}
+		allRepos = append(allRepos, newGiteaRepository(repo))
+	}
+	return allRepos, nil
+}
+
+// newGiteaRepository will convert a repository from the api into the repository wraith scans
+func newGiteaRepository(repo giteaRepository) *Repository {
+	owner := repo.Owner.Login
+	if owner == "" {
+		owner = repo.Owner.UserName
+	}
+	visibility := VisibilityPublic
+	switch {
+	case repo.Private:
+		visibility = VisibilityPrivate
+	case repo.Internal:
+		visibility = VisibilityInternal
+	}
+	pushedAt := repo.UpdatedAt
+	languages := []string{}
+	if repo.Language != "" {
+		languages = []string{repo.Language}
+	}
+	// gitea gives the size in kilobytes
+	size := repo.Size * 1024
+	return &Repository{
+		Owner:         &owner,
+		ID:            &repo.ID,
+		Name:          stringPointer(repo.Name),
+		FullName:      stringPointer(repo.FullName),
+		CloneURL:      stringPointer(repo.CloneURL),
+		URL:           stringPointer(repo.HTMLURL),
+		DefaultBranch: stringPointer(repo.DefaultBranch),
+		Description:   stringPointer(repo.Description),
+		Homepage:      stringPointer(repo.Website),
+		Visibility:    &visibility,
+		Fork:          &repo.Fork,
+		Archived:      &repo.Archived,
+		PushedAt:      &pushedAt,
+		Topics:        repo.Topics,
+		Languages:     languages,
+		Size:          &size,
+		webURL:        repo.HTMLURL,
+		sshURL:        repo.SSHURL,
+		gitea:         true,
+	}
+}
+
+// GetOrganizationMembers will gather the members of an organization the token can see, so their own repositories
+// are scanned as well
+func (c giteaClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
+	var allMembers []*Owner
+	for page := 1; ; page++ {
+		var list []giteaOwner
+		query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+		if err := c.get("/orgs/"+url.PathEscape(*target.Login)+"/members", query, &list); err != nil {
+			return nil, err
+		}
+		for _, member := range list {
+			member := member
+			allMembers = append(allMembers, &Owner{
+				Login:     stringPointer(member.Login),
+				ID:        &member.ID,
+				Type:      stringPointer(TargetTypeUser),
+				Name:      stringPointer(member.FullName),
+				AvatarURL: stringPointer(member.AvatarURL),
+				URL:       stringPointer(c.baseURL + "/" + url.PathEscape(member.Login)),
+			})
+		}
+		if len(list) < giteaPageSize {
+			return allMembers, nil
+		}
+	}
+}
+
+// giteaEscapeFullName will escape the owner and name of an owner/repository target for use in an api path
+func giteaEscapeFullName(fullName string) string {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return url.PathEscape(fullName)
+	}
+	return url.PathEscape(parts[0]) + "/" + url.PathEscape(parts[1])
+}
+
+// setGiteaUrls will set the links of a finding from the web url of its repository, as gitea is self-hosted
+func (f *Finding) setGiteaUrls(webURL string) {
+	f.RepositoryUrl = webURL
+	f.FileUrl = fmt.Sprintf("%s/src/commit/%s/%s", webURL, f.CommitHash, f.FilePath)
+	f.CommitUrl = fmt.Sprintf("%s/commit/%s", webURL, f.CommitHash)
+}
+
+// giteaFileURL returns the api url of the raw content of a file at a commit
+func giteaFileURL(baseURL, owner, repo, commit, path string) string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s/raw/%s?ref=%s", strings.TrimSuffix(baseURL, "/"),
+		url.PathEscape(owner), url.PathEscape(repo), strings.TrimPrefix(path, "/"), url.QueryEscape(commit))
+}
@@ -0,0 +1,159 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+// fakeGiteaAPI serves an organization with its repositories split over two pages, a member, and a user
+type fakeGiteaAPI struct {
+	url string
+}
+
+func (f *fakeGiteaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token secret" {
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "token is required"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+
+	repo := func(owner, name string, extra map[string]interface{}) map[string]interface{} {
+		r := map[string]interface{}{
+			"id":             len(owner + name),
+			"owner":          map[string]interface{}{"login": owner, "username": owner},
+			"name":           name,
+			"full_name":      owner + "/" + name,
+			"html_url":       f.url + "/" + owner + "/" + name,
+			"clone_url":      f.url + "/" + owner + "/" + name + ".git",
+			"default_branch": "main",
+			"updated_at":     "2024-03-01T10:00:00Z",
+			"topics":         []string{"payments"},
+		}
+		for k, v := range extra {
+			r[k] = v
+		}
+		return r
+	}
+
+	switch r.URL.Path {
+	case "/api/v1/orgs/acme":
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "username": "acme", "full_name": "Acme Corp", "website": "https://acme.example.com"})
+	case "/api/v1/users/jane":
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 9, "login": "jane", "full_name": "Jane Doe"})
+	case "/api/v1/orgs/acme/repos":
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]interface{}{repo("acme", "site", nil)})
+			return
+		}
+		page := []interface{}{
+			repo("acme", "api", map[string]interface{}{"private": true}),
+			repo("acme", "api-fork", map[string]interface{}{"fork": true}),
+			repo("acme", "empty", map[string]interface{}{"empty": true, "default_branch": ""}),
+		}
+		for i := len(page); i < 50; i++ {
+			page = append(page, repo("acme", fmt.Sprintf("fork-%d", i), map[string]interface{}{"fork": true}))
+		}
+		json.NewEncoder(w).Encode(page)
+	case "/api/v1/orgs/acme/members":
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 9, "login": "jane"}})
+	case "/api/v1/users/jane/repos":
+		json.NewEncoder(w).Encode([]interface{}{repo("jane", "dotfiles", map[string]interface{}{"internal": true})})
+	case "/api/v1/repos/acme/api-fork":
+		json.NewEncoder(w).Encode(repo("acme", "api-fork", map[string]interface{}{"fork": true}))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+	}
+}
+
+func TestGiteaClient(t *testing.T) {
+
+	Convey("Given the api of a gitea server", t, func() {
+		api := &fakeGiteaAPI{}
+		server := httptest.NewServer(api)
+		defer server.Close()
+		api.url = server.URL
+
+		client := core.NewGiteaClient(server.URL+"/", "secret", &core.Logger{})
+
+		Convey("An organization should be found with its members", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "acme")
+			So(*owner.Name, ShouldEqual, "Acme Corp")
+			So(*owner.URL, ShouldEqual, server.URL+"/acme")
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+
+			members, err := client.GetOrganizationMembers(*owner)
+			So(err, ShouldBeNil)
+			So(members, ShouldHaveLength, 1)
+			So(*members[0].Login, ShouldEqual, "jane")
+			So(*members[0].Type, ShouldEqual, core.TargetTypeUser)
+		})
+
+		Convey("Every page of an organization should be gathered, skipping forks and empty repositories", func() {
+			owner, err := client.GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 2)
+
+			So(*repos[0].Owner, ShouldEqual, "acme")
+			So(*repos[0].Name, ShouldEqual, "api")
+			So(*repos[0].FullName, ShouldEqual, "acme/api")
+			So(*repos[0].CloneURL, ShouldEqual, server.URL+"/acme/api.git")
+			So(*repos[0].URL, ShouldEqual, server.URL+"/acme/api")
+			So(*repos[0].DefaultBranch, ShouldEqual, "main")
+			So(*repos[0].Visibility, ShouldEqual, core.VisibilityPrivate)
+			So(repos[0].Topics, ShouldResemble, []string{"payments"})
+			So(repos[0].PushedAt.Year(), ShouldEqual, 2024)
+
+			So(*repos[1].Name, ShouldEqual, "site")
+			So(*repos[1].Visibility, ShouldEqual, core.VisibilityPublic)
+		})
+
+		Convey("A target that is not an organization should be a user", func() {
+			owner, err := client.GetUserOrganization("jane")
+			So(err, ShouldBeNil)
+			So(*owner.Type, ShouldEqual, core.TargetTypeUser)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 1)
+			So(*repos[0].Visibility, ShouldEqual, core.VisibilityInternal)
+		})
+
+		Convey("A repository target should be scanned alone, even when it is a fork", func() {
+			owner, err := client.GetUserOrganization("acme/api-fork")
+			So(err, ShouldBeNil)
+			So(*owner.Type, ShouldEqual, core.TargetTypeRepository)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repos, ShouldHaveLength, 1)
+			So(*repos[0].FullName, ShouldEqual, "acme/api-fork")
+		})
+
+		Convey("Errors from the api should be returned", func() {
+			_, err := client.GetUserOrganization("nobody")
+			So(err.Error(), ShouldEqual, "gitea returned 404: not found")
+
+			bad := core.NewGiteaClient(server.URL, "wrong", &core.Logger{})
+			_, err = bad.GetUserOrganization("acme/api")
+			So(err.Error(), ShouldEqual, "gitea returned 401: token is required")
+		})
+	})
+
+	Convey("A gitea token should not be empty", t, func() {
+		So(core.ValidGiteaToken("secret"), ShouldBeTrue)
+		So(core.ValidGiteaToken(" "), ShouldBeFalse)
+	})
+}
@@ -2,84 +2,172 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
-	"io/ioutil"
-	"os"
+	"io"
+	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
 
 	"gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// These are where gists are shown and where the files in them are served from
+const (
+	GistBaseURL    = "https://gist.github.com"
+	GistRawBaseURL = "https://gist.githubusercontent.com"
 )
 
 // CloneRepository will crete either an in memory clone of a given repository or clone to a temp dir.
 func CloneGithubRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
-
-	cloneOptions := &git.CloneOptions{
-		URL:           *cloneConfig.Url,
-		Depth:         *cloneConfig.Depth,
-		ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", *cloneConfig.Branch)),
-		SingleBranch:  true,
-		Tags:          git.NoTags,
-	}
-
-	var repository *git.Repository
-	var err error
-	var dir string
-
-	if !*cloneConfig.InMemClone {
-		dir, err = ioutil.TempDir("", "wraith") //TODO need to remove this when we are done with it
-		if err != nil {
-			return nil, "", err
-		}
-		repository, err = git.PlainClone(dir, false, cloneOptions)
-	} else {
-		repository, err = git.Clone(memory.NewStorage(), nil, cloneOptions)
-	}
-	if err != nil {
-		return nil, dir, err
-	}
-	return repository, dir, nil
+	return gitClone(cloneConfig)
 }
 
 // Client holds a github api client instance
 type githubClient struct {
-	apiClient *github.Client
+	apiClient  *github.Client
+	httpClient *http.Client // what the api client sends its requests with, nil for http.DefaultClient
+	viewer     *githubViewer
+}
+
+// githubViewer is the user the token belongs to. It is looked up once, as only they can list their own secret gists.
+type githubViewer struct {
+	once  sync.Once
+	login string
 }
 
 // TODO make this a single function
-// CheckAPIToken will ensure we have a valid github api token
-func CheckGithubAPIToken(t string, sess *Session) {
+// CheckGithubAPIToken will ensure we have a valid github api token
+func CheckGithubAPIToken(t string) error {
+	if !ValidGithubAPIToken(t) {
+		return errors.New("the token is invalid, please use a valid Github token")
+	}
+	return nil
+}
+
+// ValidGithubAPIToken will check that a github api token is the proper length and format
+func ValidGithubAPIToken(t string) bool {
 
 	// check to make sure the length is proper
 	if len(t) != 40 {
-		sess.Out.Error("The token is invalid. Please use a valid Github token")
-		os.Exit(2)
+		return false
 	}
 
 	// match only letters and numbers and ensure you match 40
 	exp1 := regexp.MustCompile(`^[A-Za-z0-9]{40}`)
-	if !exp1.MatchString(t) {
-		sess.Out.Error("The token is invalid. Please use a valid Github token")
-		os.Exit(2)
-	}
+	return exp1.MatchString(t)
+}
+
+// githubContext is the context the api clients are created with, so their requests wait out rate limits
+func githubContext(logger *Logger) context.Context {
+	return context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: NewGithubTransport(nil, logger)})
 }
 
 // NewClient creates a github api client instance using oauth2 credentials
-func (c githubClient) NewClient(token string) (apiClient githubClient) {
-	ctx := context.Background()
+func (c githubClient) NewClient(token string, logger *Logger) (apiClient githubClient) {
+	ctx := githubContext(logger)
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 	c.apiClient = github.NewClient(tc)
+	c.httpClient = tc
+	c.apiClient.UserAgent = UserAgent
+	c.viewer = &githubViewer{}
+	return c
+}
+
+// NewGithubTokenPoolClient creates a github api client that sends each request with whichever of several tokens has the
+// most of its rate limit left. The tokens may belong to different users, so there is no single user whose secret gists
+// can be listed.
+func NewGithubTokenPoolClient(tokens []string, logger *Logger) IClient {
+	tc := &http.Client{Transport: NewGithubTransport(NewGithubTokenPool(tokens, nil, logger), logger)}
+	c := githubClient{apiClient: github.NewClient(tc), httpClient: tc}
 	c.apiClient.UserAgent = UserAgent
 	return c
 }
 
+// NewGithubAppClient creates a github api client that authenticates as an installation of a github app. An
+// installation is not a user, so it has no secret gists of its own.
+func NewGithubAppClient(app *GithubApp, logger *Logger) IClient {
+	tc := oauth2.NewClient(githubContext(logger), app)
+	c := githubClient{apiClient: github.NewClient(tc), httpClient: tc}
+	c.apiClient.UserAgent = UserAgent
+	if app.APIURL != GithubAPIURL {
+		c.apiClient.BaseURL, _ = url.Parse(app.APIURL + "/")
+	}
+	return c
+}
+
+// NewGithubClient creates a github api client from an existing go-github client, such as one for github enterprise,
+// and the http client it was created with, nil as with go-github for http.DefaultClient
+func NewGithubClient(apiClient *github.Client, httpClient *http.Client) IClient {
+	return githubClient{apiClient: apiClient, httpClient: httpClient, viewer: &githubViewer{}}
+}
+
+// errDownloadTooLarge is returned when what is downloaded from github is larger than it may be
+var errDownloadTooLarge = errors.New("the download is larger than the maximum size")
+
+// download will send a request to the api for something it redirects to, such as the logs of a workflow run, and
+// write what it is redirected to to w. The redirect is not followed by the api client, as it would send the token on
+// to the storage github redirects to; it is downloaded without the token instead, as go-github does for release
+// assets. No more than maxSize bytes are written, errDownloadTooLarge is returned when there are more.
+func (c githubClient) download(ctx context.Context, req *http.Request, w io.Writer, maxSize int64) error {
+	client := http.DefaultClient
+	if c.httpClient != nil {
+		client = c.httpClient
+	}
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := noRedirect.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := resp.Body
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != "" {
+		location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+		if err != nil {
+			return err
+		}
+		redirectReq, err := http.NewRequest("GET", location.String(), nil)
+		if err != nil {
+			return err
+		}
+		redirected, err := NewHTTPClient(30 * time.Minute).Do(redirectReq.WithContext(ctx))
+		if err != nil {
+			// the url is signed, so only where it is on is given
+			return fmt.Errorf("unable to download from %s", location.Host)
+		}
+		defer redirected.Body.Close()
+		if redirected.StatusCode != http.StatusOK {
+			return fmt.Errorf("unable to download from %s: %s", location.Host, redirected.Status)
+		}
+		body = redirected.Body
+	} else if err := github.CheckResponse(resp); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxSize {
+		return errDownloadTooLarge
+	}
+	return nil
+}
+
 // GetUserOrganization is used to enumerate the owner in a given org
 func (c githubClient) GetUserOrganization(login string) (*Owner, error) {
 	ctx := context.Background()
@@ -104,31 +192,31 @@ func (c githubClient) GetUserOrganization(login string) (*Owner, error) {
 
 // GetRepositoriesFromOwner is used gather all the repos associated with the org owner or other user
 func (c githubClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, error) {
+	return c.listRepositories(*target.Login, "sources")
+}
+
+// GetMemberRepositories will gather the repositories of an organization member that they own, that they have been
+// added to as a collaborator, or both
+func (c githubClient) GetMemberRepositories(target Owner, affiliation string) ([]*Repository, error) {
+	return c.listRepositories(*target.Login, affiliation)
+}
+
+// listRepositories will gather the repositories of an organization or user of a type, leaving out forks
+func (c githubClient) listRepositories(login string, repoType string) ([]*Repository, error) {
 	var allRepos []*Repository
 	ctx := context.Background()
 	opt := &github.RepositoryListOptions{
-		Type: "sources",
+		Type: repoType,
 	}
 
 	for {
-		repos, resp, err := c.apiClient.Repositories.List(ctx, *target.Login, opt)
+		repos, resp, err := c.apiClient.Repositories.List(ctx, login, opt)
 		if err != nil {
 			return allRepos, err
 		}
 		for _, repo := range repos {
 			if !*repo.Fork {
-				r := Repository{
-					Owner:         repo.Owner.Login,
-					ID:            repo.ID,
-					Name:          repo.Name,
-					FullName:      repo.FullName,
-					CloneURL:      repo.CloneURL,
-					URL:           repo.HTMLURL,
-					DefaultBranch: repo.DefaultBranch,
-					Description:   repo.Description,
-					Homepage:      repo.Homepage,
-				}
-				allRepos = append(allRepos, &r)
+				allRepos = append(allRepos, newGithubRepository(repo))
 			}
 		}
 		if resp.NextPage == 0 {
@@ -140,6 +228,48 @@ func (c githubClient) GetRepositoriesFromOwner(target Owner) ([]*Repository, err
 	return allRepos, nil
 }
 
+// newGithubRepository will map a repository from the github api to the repository scanned by a session
+func newGithubRepository(repo *github.Repository) *Repository {
+	r := &Repository{
+		Owner:         repo.Owner.Login,
+		ID:            repo.ID,
+		Name:          repo.Name,
+		FullName:      repo.FullName,
+		CloneURL:      repo.CloneURL,
+		URL:           repo.HTMLURL,
+		DefaultBranch: repo.DefaultBranch,
+		Description:   repo.Description,
+		Homepage:      repo.Homepage,
+		Fork:          repo.Fork,
+		Archived:      repo.Archived,
+		Topics:        repo.Topics,
+		Languages:     []string{},
+	}
+	if repo.GetLanguage() != "" {
+		r.Languages = []string{repo.GetLanguage()}
+	}
+	visibility := VisibilityPublic
+	if repo.GetPrivate() {
+		visibility = VisibilityPrivate
+	}
+	r.Visibility = &visibility
+	r.sshURL = repo.GetSSHURL()
+	// the findings of repositories on github enterprise are linked from their own host rather than github.com
+	if u, err := url.Parse(repo.GetHTMLURL()); err == nil && u.Host != "" && u.Host != "github.com" {
+		r.webURL = repo.GetHTMLURL()
+		r.githubEnterprise = true
+	}
+	if repo.PushedAt != nil {
+		r.PushedAt = &repo.PushedAt.Time
+	}
+	if repo.Size != nil {
+		// github gives the size in kilobytes
+		size := int64(repo.GetSize()) * 1024
+		r.Size = &size
+	}
+	return r
+}
+
 // GetOrganizationMembers will gather all the members of a given organization
 func (c githubClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
 	var allMembers []*Owner
@@ -160,3 +290,103 @@ func (c githubClient) GetOrganizationMembers(target Owner) ([]*Owner, error) {
 	}
 	return allMembers, nil
 }
+
+// viewerLogin returns the login of the user the token belongs to, or an empty string if it cannot be looked up
+func (c githubClient) viewerLogin(ctx context.Context) string {
+	if c.viewer == nil {
+		return ""
+	}
+	c.viewer.once.Do(func() {
+		if user, _, err := c.apiClient.Users.Get(ctx, ""); err == nil {
+			c.viewer.login = user.GetLogin()
+		}
+	})
+	return c.viewer.login
+}
+
+// GetGistsFromOwner will gather the gists of a user. Github only lists secret gists to the user they belong to, so
+// they are included for the owner of the token and only public gists are gathered for everyone else.
+func (c githubClient) GetGistsFromOwner(target Owner) ([]*Repository, error) {
+	var allGists []*Repository
+	ctx := context.Background()
+
+	user := *target.Login
+	if strings.EqualFold(user, c.viewerLogin(ctx)) {
+		// listing the gists of the authenticated user rather than of a named user includes the secret ones
+		user = ""
+	}
+
+	opt := &github.GistListOptions{}
+	for {
+		gists, resp, err := c.apiClient.Gists.List(ctx, user, opt)
+		if err != nil {
+			return allGists, err
+		}
+		for _, gist := range gists {
+			allGists = append(allGists, newGithubGist(gist))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return allGists, nil
+}
+
+// newGithubGist will map a gist from the github api to the repository scanned by a session. A gist is a git
+// repository named by its id, and is scanned like any other repository.
+func newGithubGist(gist *github.Gist) *Repository {
+	owner := gist.GetOwner().GetLogin()
+	name := gist.GetID()
+	id := stringID(GistBaseURL + "/" + name)
+	visibility := VisibilityPublic
+	if !gist.GetPublic() {
+		visibility = VisibilityPrivate
+	}
+	fork := false
+	var size int64
+	for _, file := range gist.Files {
+		size += int64(file.GetSize())
+	}
+	return &Repository{
+		Owner:         &owner,
+		ID:            &id,
+		Name:          &name,
+		FullName:      stringPointer(owner + "/" + name),
+		CloneURL:      gist.GitPullURL,
+		URL:           gist.HTMLURL,
+		DefaultBranch: stringPointer(""),
+		Description:   gist.Description,
+		Homepage:      stringPointer(""),
+		Visibility:    &visibility,
+		Fork:          &fork,
+		PushedAt:      gist.UpdatedAt,
+		Topics:        []string{"gist"},
+		Size:          &size,
+		webURL:        fmt.Sprintf("%s/%s/%s", GistBaseURL, owner, name),
+		gist:          true,
+	}
+}
+
+// setGistUrls will link a finding to the revision of the gist it was found in
+func (f *Finding) setGistUrls(webURL string) {
+	f.RepositoryUrl = webURL
+	f.CommitUrl = fmt.Sprintf("%s/%s", webURL, f.CommitHash)
+	f.FileUrl = fmt.Sprintf("%s#%s", f.CommitUrl, gistFileAnchor(f.FilePath))
+}
+
+// gistFileAnchor returns the anchor github gives a file on the page of a gist, such as file-config-yml
+func gistFileAnchor(filename string) string {
+	return "file-" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, filename)
+}
+
+// isGist checks if the repository a finding was made in is a gist
+func (s *Session) isGist(owner, name string) bool {
+	repo := s.findRepository(&Finding{RepositoryOwner: owner, RepositoryName: name})
+	return repo != nil && repo.gist
+}
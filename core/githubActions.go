@@ -0,0 +1,191 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// ActionWorkflowLog is the action of findings in the log of a github actions workflow run
+const ActionWorkflowLog = "Workflow Log"
+
+// workflowLogTimestamp is the time github puts at the start of every line of a workflow log
+var workflowLogTimestamp = regexp.MustCompile(`(?m)^\x{FEFF}?\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z `)
+
+// WorkflowLog is the log of a job of a github actions workflow run, which is scanned with --scan-workflow-logs
+type WorkflowLog struct {
+	Path     string // where the log is on the repository, such as actions/runs/1234/build.txt
+	URL      string
+	Actor    string // who started the run
+	Workflow string // the name and number of the run, such as CI #56
+	HeadSHA  string
+	Body     string
+}
+
+// githubWorkflowRuns is a page of the workflow runs of a repository, which the version of go-github in use predates
+type githubWorkflowRuns struct {
+	WorkflowRuns []struct {
+		ID        int64         `json:"id"`
+		Name      string        `json:"name"`
+		RunNumber int           `json:"run_number"`
+		HeadSHA   string        `json:"head_sha"`
+		HTMLURL   string        `json:"html_url"`
+		Actor     *githubAuthor `json:"actor"`
+	} `json:"workflow_runs"`
+}
+
+// GetWorkflowLogs will download the logs of the most recent completed workflow runs of a repository, up to runs of
+// them. Logs larger than maxSize bytes are left out, as are runs whose archive of logs is larger than that and runs
+// whose logs have expired. What was gathered is returned along with the first error.
+func (c githubClient) GetWorkflowLogs(repo *Repository, runs int, maxSize int64) ([]*WorkflowLog, error) {
+	ctx := context.Background()
+	u := fmt.Sprintf("repos/%s/%s/actions/runs?status=completed&per_page=%d", *repo.Owner, *repo.Name, runs)
+	req, err := c.apiClient.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var page githubWorkflowRuns
+	if _, err := c.apiClient.Do(ctx, req, &page); err != nil {
+		return nil, err
+	}
+
+	var logs []*WorkflowLog
+	for i, run := range page.WorkflowRuns {
+		if i == runs {
+			break
+		}
+		u := fmt.Sprintf("repos/%s/%s/actions/runs/%d/logs", *repo.Owner, *repo.Name, run.ID)
+		req, err := c.apiClient.NewRequest("GET", u, nil)
+		if err != nil {
+			return logs, err
+		}
+		// the logs are a zip archive that the api redirects to, runs whose archive is too large are left out
+		var archive bytes.Buffer
+		err = c.download(ctx, req, &archive, maxSize)
+		if e, ok := err.(*github.ErrorResponse); ok && (e.Response.StatusCode == http.StatusNotFound || e.Response.StatusCode == http.StatusGone) {
+			continue
+		}
+		if err == errDownloadTooLarge {
+			continue
+		}
+		if err != nil {
+			return logs, err
+		}
+
+		jobs, err := workflowLogFiles(archive.Bytes(), maxSize)
+		if err != nil {
+			return logs, fmt.Errorf("unable to read the logs of %s: %s", run.HTMLURL, err)
+		}
+		for _, job := range jobs {
+			logs = append(logs, &WorkflowLog{
+				Path:     githubTextPath(repo, run.HTMLURL) + "/" + job.name,
+				URL:      run.HTMLURL,
+				Actor:    run.Actor.login(),
+				Workflow: fmt.Sprintf("%s #%d", run.Name, run.RunNumber),
+				HeadSHA:  run.HeadSHA,
+				Body:     job.body,
+			})
+		}
+	}
+	return logs, nil
+}
+
+// workflowLogFile is a log taken out of the archive of the logs of a workflow run
+type workflowLogFile struct {
+	name string
+	body string
+}
+
+// workflowLogFiles will take the logs out of the archive of a workflow run, with the time at the start of each line
+// removed. The archive has the log of each job at its top level and the same log split up by step in a directory for
+// the job, so only the logs of the jobs are taken unless there are none.
+func workflowLogFiles(data []byte, maxSize int64) ([]workflowLogFile, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs, steps []*zip.File
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || path.Ext(f.Name) != ".txt" {
+			continue
+		}
+		if strings.Contains(f.Name, "/") {
+			steps = append(steps, f)
+		} else {
+			jobs = append(jobs, f)
+		}
+	}
+	if len(jobs) == 0 {
+		jobs = steps
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+
+	var files []workflowLogFile
+	for _, f := range jobs {
+		if int64(f.UncompressedSize64) > maxSize {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return files, err
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(rc, maxSize))
+		rc.Close()
+		if err != nil {
+			return files, err
+		}
+		files = append(files, workflowLogFile{
+			name: f.Name,
+			body: workflowLogTimestamp.ReplaceAllString(string(body), ""),
+		})
+	}
+	return files, nil
+}
+
+// scanWorkflowLogs will match the logs of the recent workflow runs of a repository against the signatures that look
+// at the content of files. Tokens that are printed by debug logging are often only ever in these logs.
+func scanWorkflowLogs(sess *Session, repo *Repository) {
+	lister, ok := sess.Client.(interface {
+		GetWorkflowLogs(repo *Repository, runs int, maxSize int64) ([]*WorkflowLog, error)
+	})
+	if !ok || sess.isGist(*repo.Owner, *repo.Name) {
+		return
+	}
+
+	log := sess.Out.With("repo", *repo.FullName)
+	logs, err := lister.GetWorkflowLogs(repo, sess.WorkflowLogRuns, sess.MaxFileSize*1024*1024)
+	if err != nil {
+		if e, ok := err.(*github.ErrorResponse); ok && e.Response.StatusCode == http.StatusForbidden {
+			log.Warn("Unable to read the workflow logs of %s, the token needs the actions read permission\n", *repo.FullName)
+		} else {
+			log.Error("Failed to retrieve the workflow logs of %s: %s\n", *repo.FullName, err)
+		}
+	}
+	log.Debug("Scanning %d workflow logs of %s\n", len(logs), *repo.FullName)
+
+	for _, l := range logs {
+		if sess.Interrupted() {
+			return
+		}
+		scanText(sess, repo, l.Body, Finding{
+			Action:          ActionWorkflowLog,
+			CommitAuthor:    l.Actor,
+			CommitHash:      l.HeadSHA,
+			CommitMessage:   l.Workflow,
+			FilePath:        l.Path,
+			RepositoryName:  *repo.Name,
+			RepositoryOwner: *repo.Owner,
+		}, l.URL)
+	}
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// GithubAPIURL is the api of github.com that a github app authenticates against
+const GithubAPIURL = "https://api.github.com"
+
+// GithubAppTokenUser is the user name an installation token is given with when cloning over https
+const GithubAppTokenUser = "x-access-token"
+
+// githubAppJWTLifetime is how long the jwt an app authenticates itself with is valid for, github allows at most 10
+// minutes. It is backdated by a minute so small differences between clocks do not make it invalid.
+const githubAppJWTLifetime = 9 * time.Minute
+
+// githubAppTokenRefresh is how long before an installation token expires that a new one is requested
+const githubAppTokenRefresh = 5 * time.Minute
+
+// GithubApp authenticates as an installation of a github app. It hands out installation tokens, requesting a new one
+// shortly before the current one expires, and can be used wherever an oauth2 token source is.
+type GithubApp struct {
+	sync.Mutex
+	APIURL         string
+	ID             int64
+	InstallationID int64
+	key            *rsa.PrivateKey
+	client         *http.Client
+	token          *oauth2.Token
+}
+
+// githubInstallation is an installation of a github app on a user or organization
+type githubInstallation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+	} `json:"account"`
+}
+
+// NewGithubApp will create a github app from its id and pem encoded private key. When the installation id is 0 the
+// app must only be installed once, and that installation is used.
+func NewGithubApp(apiURL string, id int64, privateKey []byte, installationID int64) (*GithubApp, error) {
+	if id <= 0 {
+		return nil, errors.New("a github app id is required")
+	}
+
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("the private key is not pem encoded")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return nil, fmt.Errorf("unable to parse the private key: %s", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("the private key is not an rsa key")
+		}
+		key = rsaKey
+	}
+
+	if apiURL == "" {
+		apiURL = GithubAPIURL
+	}
+	return &GithubApp{
+		APIURL:         strings.TrimSuffix(apiURL, "/"),
+		ID:             id,
+		InstallationID: installationID,
+		key:            key,
+		client:         NewHTTPClient(30 * time.Second),
+	}, nil
+}
+
+// LoadGithubApp will create a github app with the private key in a file, as downloaded from the settings of the app.
+// The api url is that of a github enterprise instance, or empty for github.com.
+func LoadGithubApp(apiURL string, id int64, privateKeyFile string, installationID int64) (*GithubApp, error) {
+	key, err := ioutil.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewGithubApp(apiURL, id, key, installationID)
+}
+
+// JWT will create the token the app authenticates itself with when it asks for installation tokens
+func (a *GithubApp) JWT(now time.Time) (string, error) {
+	encode := func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	}
+
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(githubAppJWTLifetime).Unix(),
+		"iss": a.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + claims
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Token will return an installation token, requesting a new one when there is none or it is about to expire
+func (a *GithubApp) Token() (*oauth2.Token, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.token != nil && time.Until(a.token.Expiry) > githubAppTokenRefresh {
+		return a.token, nil
+	}
+
+	if a.InstallationID == 0 {
+		id, err := a.findInstallation()
+		if err != nil {
+			return nil, err
+		}
+		a.InstallationID = id
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := a.do(http.MethodPost, fmt.Sprintf("/app/installations/%d/access_tokens", a.InstallationID), &resp); err != nil {
+		return nil, fmt.Errorf("unable to get a token for installation %d: %s", a.InstallationID, err)
+	}
+	a.token = &oauth2.Token{AccessToken: resp.Token, Expiry: resp.ExpiresAt}
+	return a.token, nil
+}
+
+// findInstallation will return the id of the only installation of the app
+func (a *GithubApp) findInstallation() (int64, error) {
+	var installations []githubInstallation
+	if err := a.do(http.MethodGet, "/app/installations", &installations); err != nil {
+		return 0, fmt.Errorf("unable to list the installations of github app %d: %s", a.ID, err)
+	}
+	switch len(installations) {
+	case 0:
+		return 0, fmt.Errorf("github app %d is not installed anywhere", a.ID)
+	case 1:
+		return installations[0].ID, nil
+	}
+	var accounts []string
+	for _, i := range installations {
+		accounts = append(accounts, fmt.Sprintf("%s (%d)", i.Account.Login, i.ID))
+	}
+	return 0, fmt.Errorf("github app %d is installed on %s, set the installation id to pick one", a.ID, strings.Join(accounts, ", "))
+}
+
+// do will make a request to the api as the app itself and decode the json response into v
+func (a *GithubApp) do(method, path string, v interface{}) error {
+	jwt, err := a.JWT(time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, a.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("github returned %s: %s", resp.Status, apiErr.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,154 @@
+package core_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+// verifyAppJWT will check the signature of a jwt from a github app and return its claims
+func verifyAppJWT(token string, key *rsa.PublicKey) (map[string]int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed jwt")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]int64{}
+	return claims, json.Unmarshal(data, &claims)
+}
+
+func TestGithubApp(t *testing.T) {
+
+	Convey("Given a github app installed on one organization", t, func() {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		So(err, ShouldBeNil)
+		privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+		var mu sync.Mutex
+		installations := `[{"id": 42, "account": {"login": "acme"}}]`
+		expiresIn := time.Hour
+		tokensIssued := 0
+
+		mux := http.NewServeMux()
+		app := func(handler http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				claims, err := verifyAppJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+				if err != nil || claims["iss"] != 7 || claims["exp"]-claims["iat"] > 600 {
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprint(w, `{"message": "A JSON web token could not be decoded"}`)
+					return
+				}
+				handler(w, r)
+			}
+		}
+		mux.HandleFunc("/app/installations", app(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, installations)
+		}))
+		mux.HandleFunc("/app/installations/42/access_tokens", app(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			mu.Lock()
+			tokensIssued++
+			token := fmt.Sprintf("ghs_%d", tokensIssued)
+			mu.Unlock()
+			fmt.Fprintf(w, `{"token": "%s", "expires_at": "%s"}`, token, time.Now().Add(expiresIn).UTC().Format(time.RFC3339))
+		}))
+		mux.HandleFunc("/users/acme", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer ghs_1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"login": "acme", "id": 1, "type": "Organization"}`)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		Convey("An installation token should be requested once and reused until it is about to expire", func() {
+			gh, err := core.NewGithubApp(server.URL, 7, privateKey, 0)
+			So(err, ShouldBeNil)
+
+			token, err := gh.Token()
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "ghs_1")
+			So(gh.InstallationID, ShouldEqual, 42)
+
+			token, err = gh.Token()
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "ghs_1")
+			So(tokensIssued, ShouldEqual, 1)
+
+			// a token that expires within a few minutes is replaced before it is used again
+			expiresIn = 2 * time.Minute
+			short, err := core.NewGithubApp(server.URL, 7, privateKey, 42)
+			So(err, ShouldBeNil)
+			short.Token()
+			token, err = short.Token()
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "ghs_3")
+		})
+
+		Convey("The api client should authenticate as the installation", func() {
+			gh, err := core.NewGithubApp(server.URL, 7, privateKey, 0)
+			So(err, ShouldBeNil)
+
+			owner, err := core.NewGithubAppClient(gh, &core.Logger{}).GetUserOrganization("acme")
+			So(err, ShouldBeNil)
+			So(*owner.Login, ShouldEqual, "acme")
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+		})
+
+		Convey("An app installed more than once should need the installation to be picked", func() {
+			installations = `[{"id": 42, "account": {"login": "acme"}}, {"id": 43, "account": {"login": "globex"}}]`
+			gh, err := core.NewGithubApp(server.URL, 7, privateKey, 0)
+			So(err, ShouldBeNil)
+
+			_, err = gh.Token()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "acme (42), globex (43)")
+		})
+
+		Convey("A token should not be handed out for another app", func() {
+			gh, err := core.NewGithubApp(server.URL, 8, privateKey, 42)
+			So(err, ShouldBeNil)
+
+			_, err = gh.Token()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "A JSON web token could not be decoded")
+		})
+	})
+
+	Convey("A github app should need an id and a pem encoded rsa key", t, func() {
+		_, err := core.NewGithubApp("", 0, nil, 0)
+		So(err, ShouldNotBeNil)
+
+		_, err = core.NewGithubApp("", 7, []byte("not a key"), 0)
+		So(err, ShouldNotBeNil)
+	})
+}
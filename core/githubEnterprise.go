@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// githubListAllPageSize is how many organizations or users are asked for at once when every one of them on an
+// instance is listed
+const githubListAllPageSize = 100
+
+// githubBaseURL will parse the api url of a github instance, such as https://github.example.com/api/v3, into the base
+// url of a go-github client, which has to end with a slash
+func githubBaseURL(apiURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSuffix(apiURL, "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", apiURL)
+	}
+	return u, nil
+}
+
+// withGithubAPIURL will point a github client at the api of a github enterprise instance, when it is given one
+func withGithubAPIURL(client IClient, apiURL string) IClient {
+	c, ok := client.(githubClient)
+	if !ok || apiURL == "" {
+		return client
+	}
+	if u, err := githubBaseURL(apiURL); err == nil {
+		c.apiClient.BaseURL = u
+	}
+	return c
+}
+
+// isGithubEnterprise reports whether an api url is for a github enterprise instance rather than github.com
+func isGithubEnterprise(apiURL string) bool {
+	return apiURL != "" && strings.TrimSuffix(apiURL, "/") != GithubAPIURL
+}
+
+// ListOrganizations will list the login of every organization on the instance. On github enterprise server every
+// organization is listed to any user, but only a site admin can read the repositories of all of them.
+func (c githubClient) ListOrganizations() ([]string, error) {
+	var logins []string
+	ctx := context.Background()
+	opt := &github.OrganizationsListOptions{ListOptions: github.ListOptions{PerPage: githubListAllPageSize}}
+	for {
+		orgs, _, err := c.apiClient.Organizations.ListAll(ctx, opt)
+		if err != nil {
+			return logins, err
+		}
+		if len(orgs) == 0 {
+			break
+		}
+		for _, org := range orgs {
+			logins = append(logins, org.GetLogin())
+		}
+		// the list is paged by the id of the last organization rather than by page number
+		opt.Since = orgs[len(orgs)-1].GetID()
+	}
+	return logins, nil
+}
+
+// ListUsers will list the login of every user on the instance, leaving out organizations
+func (c githubClient) ListUsers() ([]string, error) {
+	var logins []string
+	ctx := context.Background()
+	opt := &github.UserListOptions{ListOptions: github.ListOptions{PerPage: githubListAllPageSize}}
+	for {
+		users, _, err := c.apiClient.Users.ListAll(ctx, opt)
+		if err != nil {
+			return logins, err
+		}
+		if len(users) == 0 {
+			break
+		}
+		for _, user := range users {
+			if user.GetType() == TargetTypeOrganization {
+				continue
+			}
+			logins = append(logins, user.GetLogin())
+		}
+		opt.Since = users[len(users)-1].GetID()
+	}
+	return logins, nil
+}
+
+// setGithubEnterpriseUrls will set the links of a finding from the web url of its repository, as github enterprise
+// is not on github.com
+func (f *Finding) setGithubEnterpriseUrls(webURL string) {
+	f.RepositoryUrl = webURL
+	f.FileUrl = fmt.Sprintf("%s/blob/%s/%s", webURL, f.CommitHash, f.FilePath)
+	f.CommitUrl = fmt.Sprintf("%s/commit/%s", webURL, f.CommitHash)
+}
@@ -0,0 +1,291 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/github"
+)
+
+// These are the templates an issue is written with when --issue-title-template and --issue-body-template are not set
+const (
+	DefaultIssueTitleTemplate = `{{upper .Finding.Severity}}: {{.Finding.Description}}{{with .Finding.FilePath}} in {{.}}{{end}}`
+	DefaultIssueBodyTemplate  = `{{.Name}} found a {{.Finding.Severity}} severity secret in this repository: **{{.Finding.Description}}**
+{{- with .Finding.Comment}} ` + "`{{.}}`" + `{{end}}.
+
+Anyone who can read the history of this repository can use it, so it should be revoked and replaced rather than only removed from the code. Once it has been rotated this issue can be closed.
+{{- with .Finding.Remediation}}
+{{with .RotationURL}}
+- Rotate it at {{.}}{{end}}{{with .Vault}}
+- {{.}}{{end}}{{with .DocsURL}}
+- How to remediate it: {{.}}{{end}}
+{{- end}}
+
+| Where | Commit | Author |
+|-------|--------|--------|
+{{- range .Findings}}
+| {{if .FileURL}}[{{.FilePath}}{{with .LineNumber}}:{{.}}{{end}}]({{.FileURL}}){{else}}{{.FilePath}}{{with .LineNumber}}:{{.}}{{end}}{{end}} | {{if .CommitURL}}[{{short .CommitHash}}]({{.CommitURL}}){{else}}{{short .CommitHash}}{{end}} | {{.CommitAuthor}} |
+{{- end}}
+
+Signature: ` + "`{{.Finding.SignatureID}}`" + `{{with .Finding.Verification}}, verification: {{.}}{{end}}, fingerprint: ` + "`{{.Finding.Fingerprint}}`"
+)
+
+// githubIssueTitleLength is how long the title of an issue is allowed to get before it is cut short, with room for the
+// dots that end it within the 256 characters github accepts
+const githubIssueTitleLength = 250
+
+// issueMarker is the comment left at the end of an issue so it is found again for the same secret in a later scan
+var issueMarker = regexp.MustCompile(`<!-- wraith-fingerprint: ([0-9a-f]+) -->`)
+
+// IssueFiler opens an issue in the repository of each confirmed finding, so whoever owns the repository is told about
+// the secret in it. Secrets are always redacted in the issue, and the issue opened for a secret in an earlier scan is
+// updated rather than opened again. Anyone can read the issues of a public repository, so they are only filed in one
+// when PublicRepos is set.
+type IssueFiler struct {
+	Labels      []string
+	PublicRepos bool
+	title       *template.Template
+	body        *template.Template
+}
+
+// IssueTemplateData is what the templates of an issue are executed with. The findings are every place the secret was
+// found in the repository, as they are in the json output but with the secret redacted and no context lines.
+type IssueTemplateData struct {
+	Name       string
+	Repository string
+	Finding    JSONFinding
+	Findings   []JSONFinding
+}
+
+// FiledIssue is an issue a finding was filed as, found again by the fingerprint marked in its body
+type FiledIssue struct {
+	Number int
+	State  string
+	Title  string
+	Body   string
+}
+
+// githubIssueTracker is a client that can open issues for findings, which only github clients are
+type githubIssueTracker interface {
+	FindingIssues(repo *Repository, labels []string) (map[string]*FiledIssue, error)
+	CreateFindingIssue(repo *Repository, title, body string, labels []string) (int, error)
+	EditFindingIssue(repo *Repository, number int, title, body string) error
+}
+
+// NewIssueFiler will create an issue filer from a title template and the file of a body template, the defaults are
+// used for either one when it is empty
+func NewIssueFiler(titleTemplate, bodyFile string, labels []string) (*IssueFiler, error) {
+	if titleTemplate == "" {
+		titleTemplate = DefaultIssueTitleTemplate
+	}
+	bodyTemplate := DefaultIssueBodyTemplate
+	if bodyFile != "" {
+		data, err := ioutil.ReadFile(bodyFile)
+		if err != nil {
+			return nil, err
+		}
+		bodyTemplate = string(data)
+	}
+
+	funcs := template.FuncMap{
+		"upper": strings.ToUpper,
+		"short": func(hash string) string {
+			if len(hash) > 8 {
+				return hash[:8]
+			}
+			return hash
+		},
+	}
+	title, err := template.New("title").Funcs(funcs).Parse(titleTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue title template: %s", err)
+	}
+	body, err := template.New("body").Funcs(funcs).Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue body template: %s", err)
+	}
+	return &IssueFiler{Labels: labels, title: title, body: body}, nil
+}
+
+// Render will write the title and body of the issue for the findings of a secret in a repository, with the marker of
+// its fingerprint at the end of the body
+func (i *IssueFiler) Render(repo string, findings []*Finding, redact string) (string, string, error) {
+	data := IssueTemplateData{Name: Name, Repository: repo}
+	for _, f := range findings {
+		data.Findings = append(data.Findings, issueFinding(f, redact))
+	}
+	data.Finding = data.Findings[0]
+
+	var title, body bytes.Buffer
+	if err := i.title.Execute(&title, data); err != nil {
+		return "", "", err
+	}
+	if err := i.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	marker := fmt.Sprintf("<!-- wraith-fingerprint: %s -->", findings[0].Fingerprint)
+	return TruncateString(title.String(), githubIssueTitleLength), strings.TrimSpace(body.String()) + "\n\n" + marker, nil
+}
+
+// issueFinding is a finding as it is shown in an issue, with the secret redacted wherever it is, even when the rest of
+// the output is not redacted, and without the lines around it. Issues are read by more people than the output, so the
+// secret is only ever partly shown, when the output shows at least that much of it, and masked otherwise. A hash
+// could be checked against a guess and a format gives away the shape of the secret.
+func issueFinding(f *Finding, redact string) JSONFinding {
+	jf := NewJSONFinding(f)
+	jf.Context = nil
+	mode := RedactFull
+	if redact == RedactNone || redact == RedactPartial {
+		mode = RedactPartial
+	}
+
+	secret := f.secret
+	if secret == "" && redact == RedactNone {
+		// a finding that was made without the secret kept has it as its comment when nothing is redacted
+		secret = f.Comment
+	}
+	switch {
+	case secret != "":
+		jf.Comment = Redact(secret, mode)
+		jf.CommitMessage = strings.ReplaceAll(jf.CommitMessage, secret, jf.Comment)
+		// the commit message already has the secret redacted the way the output is
+		if shown := Redact(secret, redact); redact != RedactNone {
+			jf.CommitMessage = strings.ReplaceAll(jf.CommitMessage, shown, jf.Comment)
+		}
+	case mode == RedactFull && jf.Comment != "":
+		// without the secret kept the comment is the secret redacted the way the output is, such as its hash
+		jf.CommitMessage = strings.ReplaceAll(jf.CommitMessage, jf.Comment, RedactMask)
+		jf.Comment = RedactMask
+	}
+	return jf
+}
+
+// confirmedFinding will check whether a finding is known to be a real secret, because its service accepted it or it
+// was triaged as confirmed. Triage has the last word, so a verified secret triaged as a false positive is left out.
+func confirmedFinding(f *Finding) bool {
+	if f.Triage != nil {
+		return f.Triage.Status == TriageConfirmed
+	}
+	return f.Verification == VerificationVerified
+}
+
+// fileGithubIssues will open an issue in a repository for each confirmed secret found in it, if issues are filed. An
+// open issue filed for the secret before is updated with where the secret is now, a closed one is left closed.
+func (s *Session) fileGithubIssues(repo *Repository) {
+	if s.IssueFiler == nil {
+		return
+	}
+	tracker, ok := s.Client.(githubIssueTracker)
+	if !ok || s.isGist(*repo.Owner, *repo.Name) {
+		return
+	}
+	if repo.Visibility != nil && *repo.Visibility == VisibilityPublic && !s.IssueFiler.PublicRepos {
+		s.Out.Debug("Not filing issues in %s, it is public and issue-public-repos is not set\n", *repo.FullName)
+		return
+	}
+
+	// a secret found in several commits or files is filed once, the findings of it are listed in the issue
+	var fingerprints []string
+	bySecret := map[string][]*Finding{}
+	s.Lock()
+	for _, f := range s.Findings {
+		if f.RepositoryOwner != *repo.Owner || f.RepositoryName != *repo.Name || !confirmedFinding(f) {
+			continue
+		}
+		if _, ok := bySecret[f.Fingerprint]; !ok {
+			fingerprints = append(fingerprints, f.Fingerprint)
+		}
+		bySecret[f.Fingerprint] = append(bySecret[f.Fingerprint], f)
+	}
+	s.Unlock()
+	if len(fingerprints) == 0 {
+		return
+	}
+
+	log := s.Out.With("repo", *repo.FullName)
+	// without the issues filed before a secret would be filed again on every scan
+	filed, err := tracker.FindingIssues(repo, s.IssueFiler.Labels)
+	if err != nil {
+		log.Error("Failed to list the issues of %s: %s\n", *repo.FullName, err)
+		return
+	}
+
+	for _, fingerprint := range fingerprints {
+		title, body, err := s.IssueFiler.Render(*repo.FullName, bySecret[fingerprint], s.Redact)
+		if err != nil {
+			log.Error("Failed to write the issue for a finding in %s: %s\n", *repo.FullName, err)
+			continue
+		}
+
+		issue := filed[fingerprint]
+		switch {
+		case issue == nil:
+			number, err := tracker.CreateFindingIssue(repo, title, body, s.IssueFiler.Labels)
+			if err != nil {
+				log.Error("Failed to open an issue in %s: %s\n", *repo.FullName, err)
+				continue
+			}
+			log.Important("Opened issue #%d in %s: %s\n", number, *repo.FullName, title)
+		case issue.State == "closed":
+			log.Debug("Issue #%d in %s is closed, leaving it closed\n", issue.Number, *repo.FullName)
+		case issue.Title != title || issue.Body != body:
+			if err := tracker.EditFindingIssue(repo, issue.Number, title, body); err != nil {
+				log.Error("Failed to update issue #%d in %s: %s\n", issue.Number, *repo.FullName, err)
+				continue
+			}
+			log.Info("Updated issue #%d in %s\n", issue.Number, *repo.FullName)
+		}
+	}
+}
+
+// FindingIssues will find the issues of a repository that were filed for findings, by the fingerprint marked in their
+// body. Only issues with all of the labels are looked at, when there are any.
+func (c githubClient) FindingIssues(repo *Repository, labels []string) (map[string]*FiledIssue, error) {
+	filed := map[string]*FiledIssue{}
+	ctx := context.Background()
+	opt := &github.IssueListByRepoOptions{State: "all", Labels: labels, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		issues, resp, err := c.apiClient.Issues.ListByRepo(ctx, *repo.Owner, *repo.Name, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			m := issueMarker.FindStringSubmatch(issue.GetBody())
+			if issue.IsPullRequest() || m == nil {
+				continue
+			}
+			// issues are listed newest first, so an older issue for the same secret does not take the place of a newer one
+			if _, ok := filed[m[1]]; !ok {
+				filed[m[1]] = &FiledIssue{Number: issue.GetNumber(), State: issue.GetState(), Title: issue.GetTitle(), Body: issue.GetBody()}
+			}
+		}
+		if resp.NextPage == 0 {
+			return filed, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// CreateFindingIssue will open an issue in a repository, returning its number
+func (c githubClient) CreateFindingIssue(repo *Repository, title, body string, labels []string) (int, error) {
+	req := &github.IssueRequest{Title: &title, Body: &body}
+	if len(labels) > 0 {
+		req.Labels = &labels
+	}
+	issue, _, err := c.apiClient.Issues.Create(context.Background(), *repo.Owner, *repo.Name, req)
+	if err != nil {
+		return 0, err
+	}
+	return issue.GetNumber(), nil
+}
+
+// EditFindingIssue will replace the title and body of an issue
+func (c githubClient) EditFindingIssue(repo *Repository, number int, title, body string) error {
+	_, _, err := c.apiClient.Issues.Edit(context.Background(), *repo.Owner, *repo.Name, number, &github.IssueRequest{Title: &title, Body: &body})
+	return err
+}
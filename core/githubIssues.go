@@ -0,0 +1,278 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// These are the actions of findings in the text written on a github repository outside of git
+const (
+	ActionIssue         = "Issue"
+	ActionPullRequest   = "Pull Request"
+	ActionComment       = "Comment"
+	ActionReviewComment = "Review Comment"
+	ActionDiscussion    = "Discussion"
+)
+
+// githubDiscussionsPerPage is how many discussions are asked for at once, with up to 100 comments each
+const githubDiscussionsPerPage = 25
+
+// RepositoryText is text written on a repository outside of git, such as the body of an issue or a comment on a pull
+// request, which is scanned with --scan-issues
+type RepositoryText struct {
+	Action string
+	Path   string // where the text is on the repository, such as issues/12 or pull/34#discussion_r5678
+	URL    string
+	Author string
+	Title  string // the title of the issue, pull request or discussion the text is in
+	Body   string
+}
+
+// githubTextPath is where a page is on a repository, the part of its url after the repository
+func githubTextPath(repo *Repository, htmlURL string) string {
+	prefix := "/" + *repo.FullName + "/"
+	if i := strings.Index(htmlURL, prefix); i >= 0 {
+		return htmlURL[i+len(prefix):]
+	}
+	return htmlURL
+}
+
+// githubNumber is the number of the issue or pull request an api url points at
+func githubNumber(apiURL string) int {
+	n, _ := strconv.Atoi(path.Base(apiURL))
+	return n
+}
+
+// GetRepositoryTexts will gather the bodies of the issues, pull requests and discussions of a repository and the
+// comments on them. What was gathered is returned along with the first error, so a repository without discussions
+// still has its issues scanned.
+func (c githubClient) GetRepositoryTexts(repo *Repository) ([]*RepositoryText, error) {
+	var texts []*RepositoryText
+	ctx := context.Background()
+	owner, name := *repo.Owner, *repo.Name
+
+	// issues and pull requests are listed together, and the comments on them only say the number they are on
+	titles := map[int]string{}
+	pulls := map[int]bool{}
+	issueOpt := &github.IssueListByRepoOptions{State: "all", Sort: "created", Direction: "asc", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		issues, resp, err := c.apiClient.Issues.ListByRepo(ctx, owner, name, issueOpt)
+		if err != nil {
+			return texts, err
+		}
+		for _, issue := range issues {
+			action := ActionIssue
+			if issue.IsPullRequest() {
+				action = ActionPullRequest
+				pulls[issue.GetNumber()] = true
+			}
+			titles[issue.GetNumber()] = issue.GetTitle()
+			texts = append(texts, &RepositoryText{
+				Action: action,
+				Path:   githubTextPath(repo, issue.GetHTMLURL()),
+				URL:    issue.GetHTMLURL(),
+				Author: issue.GetUser().GetLogin(),
+				Title:  issue.GetTitle(),
+				// the title is scanned along with the body, tokens get pasted into both
+				Body: issue.GetTitle() + "\n" + issue.GetBody(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		issueOpt.Page = resp.NextPage
+	}
+
+	commentOpt := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.apiClient.Issues.ListComments(ctx, owner, name, 0, commentOpt)
+		if err != nil {
+			return texts, err
+		}
+		for _, comment := range comments {
+			texts = append(texts, &RepositoryText{
+				Action: ActionComment,
+				Path:   githubTextPath(repo, comment.GetHTMLURL()),
+				URL:    comment.GetHTMLURL(),
+				Author: comment.GetUser().GetLogin(),
+				Title:  titles[githubNumber(comment.GetIssueURL())],
+				Body:   comment.GetBody(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		commentOpt.Page = resp.NextPage
+	}
+
+	if len(pulls) > 0 {
+		reviewOpt := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			comments, resp, err := c.apiClient.PullRequests.ListComments(ctx, owner, name, 0, reviewOpt)
+			if err != nil {
+				return texts, err
+			}
+			for _, comment := range comments {
+				texts = append(texts, &RepositoryText{
+					Action: ActionReviewComment,
+					Path:   githubTextPath(repo, comment.GetHTMLURL()),
+					URL:    comment.GetHTMLURL(),
+					Author: comment.GetUser().GetLogin(),
+					Title:  titles[githubNumber(comment.GetPullRequestURL())],
+					Body:   comment.GetBody(),
+				})
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			reviewOpt.Page = resp.NextPage
+		}
+	}
+
+	discussions, err := c.getDiscussions(ctx, repo)
+	return append(texts, discussions...), err
+}
+
+// githubAuthor is the author of a discussion or comment in the graphql api, which is missing for deleted users
+type githubAuthor struct {
+	Login string `json:"login"`
+}
+
+// githubDiscussionsResponse is the page of discussions returned by the graphql api
+type githubDiscussionsResponse struct {
+	Data struct {
+		Repository struct {
+			Discussions struct {
+				Nodes []struct {
+					Title    string        `json:"title"`
+					Body     string        `json:"body"`
+					URL      string        `json:"url"`
+					Author   *githubAuthor `json:"author"`
+					Comments struct {
+						Nodes []struct {
+							Body   string        `json:"body"`
+							URL    string        `json:"url"`
+							Author *githubAuthor `json:"author"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// githubDiscussionsQuery asks for a page of the discussions of a repository with the first comments on each of them
+const githubDiscussionsQuery = `query($owner: String!, $name: String!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: $first, after: $after) {
+      nodes { title body url author { login } comments(first: 100) { nodes { body url author { login } } } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// login is the login of an author, or nothing for a deleted user
+func (a *githubAuthor) login() string {
+	if a == nil {
+		return ""
+	}
+	return a.Login
+}
+
+// getDiscussions will gather the discussions of a repository and the comments on them. Discussions are only in the
+// graphql api, which github enterprise serves next to the rest api rather than under it.
+func (c githubClient) getDiscussions(ctx context.Context, repo *Repository) ([]*RepositoryText, error) {
+	endpoint := "graphql"
+	if strings.HasSuffix(c.apiClient.BaseURL.Path, "/api/v3/") {
+		endpoint = "../graphql"
+	}
+
+	var texts []*RepositoryText
+	variables := map[string]interface{}{"owner": *repo.Owner, "name": *repo.Name, "first": githubDiscussionsPerPage}
+	for {
+		req, err := c.apiClient.NewRequest("POST", endpoint, map[string]interface{}{
+			"query":     githubDiscussionsQuery,
+			"variables": variables,
+		})
+		if err != nil {
+			return texts, err
+		}
+		var page githubDiscussionsResponse
+		if _, err := c.apiClient.Do(ctx, req, &page); err != nil {
+			return texts, err
+		}
+		if len(page.Errors) > 0 {
+			return texts, fmt.Errorf("unable to list discussions: %s", page.Errors[0].Message)
+		}
+
+		discussions := page.Data.Repository.Discussions
+		for _, d := range discussions.Nodes {
+			texts = append(texts, &RepositoryText{
+				Action: ActionDiscussion,
+				Path:   githubTextPath(repo, d.URL),
+				URL:    d.URL,
+				Author: d.Author.login(),
+				Title:  d.Title,
+				Body:   d.Title + "\n" + d.Body,
+			})
+			for _, comment := range d.Comments.Nodes {
+				texts = append(texts, &RepositoryText{
+					Action: ActionComment,
+					Path:   githubTextPath(repo, comment.URL),
+					URL:    comment.URL,
+					Author: comment.Author.login(),
+					Title:  d.Title,
+					Body:   comment.Body,
+				})
+			}
+		}
+		if !discussions.PageInfo.HasNextPage {
+			return texts, nil
+		}
+		variables["after"] = discussions.PageInfo.EndCursor
+	}
+}
+
+// scanRepositoryTexts will match the issues, pull requests and discussions of a repository, and the comments on them,
+// against the signatures that look at the content of files
+func scanRepositoryTexts(sess *Session, repo *Repository) {
+	lister, ok := sess.Client.(interface {
+		GetRepositoryTexts(repo *Repository) ([]*RepositoryText, error)
+	})
+	if !ok || sess.isGist(*repo.Owner, *repo.Name) {
+		return
+	}
+
+	log := sess.Out.With("repo", *repo.FullName)
+	texts, err := lister.GetRepositoryTexts(repo)
+	if err != nil {
+		log.Error("Failed to retrieve the issues and comments of %s: %s\n", *repo.FullName, err)
+	}
+	log.Debug("Scanning %d issues, pull requests, discussions and comments of %s\n", len(texts), *repo.FullName)
+
+	for _, text := range texts {
+		if sess.Interrupted() {
+			return
+		}
+		scanText(sess, repo, text.Body, Finding{
+			Action:          text.Action,
+			CommitAuthor:    text.Author,
+			CommitMessage:   text.Title,
+			FilePath:        text.Path,
+			RepositoryName:  *repo.Name,
+			RepositoryOwner: *repo.Owner,
+		}, text.URL)
+	}
+}
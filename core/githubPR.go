@@ -0,0 +1,342 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"wraith/version"
+
+	"github.com/google/go-github/github"
+)
+
+// githubPullRequestPattern matches owner/repo#12, owner/repo/pull/12 and the url of a pull request
+var githubPullRequestPattern = regexp.MustCompile(`^(?:https?://[^/]+/)?([^/\s]+)/([^/#\s]+)(?:#|/pulls?/)(\d+)/?$`)
+
+// hunkHeaderPattern matches the header of a hunk in a unified diff and captures the first line it covers in the new file
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// PullRequest is a github pull request and the lines it adds to each file
+type PullRequest struct {
+	Owner      string
+	Name       string
+	Number     int
+	Title      string
+	Author     string
+	HeadSHA    string
+	URL        string
+	Repository *Repository
+	Files      []*PullRequestFile
+}
+
+// PullRequestFile is a file changed by a pull request, or by the changes staged for a commit. Patch is empty when the
+// diff is left out, such as for a binary or very large file.
+type PullRequestFile struct {
+	Path   string
+	Status string
+	Patch  string
+	// PreviousPath is the path a file git detected was renamed had before, which its findings are fingerprinted by
+	PreviousPath string
+}
+
+// ParseGithubPullRequest will split a reference to a pull request into the owner and name of its repository and its
+// number. The reference can be owner/repo#12, owner/repo/pull/12 or the url of the pull request.
+func ParseGithubPullRequest(ref string) (string, string, int, error) {
+	m := githubPullRequestPattern.FindStringSubmatch(strings.TrimSpace(ref))
+	if m == nil {
+		return "", "", 0, fmt.Errorf("%s is not a pull request, use owner/repo#number or the pull request url", ref)
+	}
+	number, err := strconv.Atoi(m[3])
+	if err != nil {
+		return "", "", 0, err
+	}
+	return m[1], m[2], number, nil
+}
+
+// FetchGithubPullRequest will get a pull request and the diff of every file it changes from the github api
+func FetchGithubPullRequest(client *github.Client, owner, name string, number int) (*PullRequest, error) {
+	ctx := context.Background()
+	p, _, err := client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &PullRequest{
+		Owner:   owner,
+		Name:    name,
+		Number:  number,
+		Title:   p.GetTitle(),
+		Author:  p.GetUser().GetLogin(),
+		HeadSHA: p.GetHead().GetSHA(),
+		URL:     p.GetHTMLURL(),
+	}
+	if p.GetBase().GetRepo() != nil {
+		pr.Repository = newGithubRepository(p.GetBase().GetRepo())
+	} else {
+		fullName := owner + "/" + name
+		pr.Repository = &Repository{Owner: &pr.Owner, Name: &pr.Name, FullName: &fullName, CloneURL: &pr.URL, URL: &pr.URL}
+	}
+
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		files, resp, err := client.PullRequests.ListFiles(ctx, owner, name, number, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			pr.Files = append(pr.Files, &PullRequestFile{Path: f.GetFilename(), Status: f.GetStatus(), Patch: f.GetPatch()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return pr, nil
+}
+
+// AddedLines returns the lines a unified diff adds, keyed by their line number in the new file
+func AddedLines(patch string) map[int]string {
+	added := map[int]string{}
+	line := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if m := hunkHeaderPattern.FindStringSubmatch(text); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if line == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "+"):
+			added[line] = text[1:]
+			line++
+		case strings.HasPrefix(text, " "), text == "":
+			line++
+		}
+	}
+	return added
+}
+
+// addedContent will lay the added lines out at their line numbers with every other line left blank, so a match in
+// them is reported on the line it has in the new file
+func addedContent(added map[int]string) string {
+	last := 0
+	for n := range added {
+		if n > last {
+			last = n
+		}
+	}
+	lines := make([]string, last)
+	for n, text := range added {
+		lines[n-1] = text
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// pullRequestAction will turn the status github gives a changed file into the action of a finding
+func pullRequestAction(status string) string {
+	if status == "added" {
+		return "Insert"
+	}
+	return "Modify"
+}
+
+// ScanGithubPullRequests will fetch each pull request of the session and scan the lines it adds
+func ScanGithubPullRequests(sess *Session) error {
+	client, ok := sess.Client.(githubClient)
+	if !ok {
+		return fmt.Errorf("scanning pull requests needs a github client")
+	}
+
+	sess.SetStatus(StatusGathering)
+	sess.Stats.Targets = len(sess.GithubPullRequests)
+	sess.Out.Important("Fetching %d pull %s...\n", len(sess.GithubPullRequests), Pluralize(len(sess.GithubPullRequests), "request", "requests"))
+
+	var prs []*PullRequest
+	for _, ref := range sess.GithubPullRequests {
+		owner, name, number, err := ParseGithubPullRequest(ref)
+		if err != nil {
+			return err
+		}
+		var pr *PullRequest
+		_, err = sess.withRetries(sess.Out, "fetch pull request "+ref, func() (err error) {
+			pr, err = FetchGithubPullRequest(client.apiClient, owner, name, number)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch pull request %s: %s", ref, err)
+		}
+		sess.AddRepository(pr.Repository)
+		prs = append(prs, pr)
+	}
+
+	sess.SetStatus(StatusAnalyzing)
+	for _, pr := range prs {
+		if sess.Interrupted() {
+			break
+		}
+		if err := ScanPullRequest(sess, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanPullRequest will scan only the lines a pull request adds, rather than the history of its repository
+func ScanPullRequest(sess *Session, pr *PullRequest) error {
+	sess.Out.Debug("[%s#%d] Scanning %d changed files at %s\n", *pr.Repository.FullName, pr.Number, len(pr.Files), pr.HeadSHA)
+	base := Finding{
+		AtHead:          true,
+		CommitAuthor:    pr.Author,
+		CommitHash:      pr.HeadSHA,
+		CommitMessage:   pr.Title,
+		RepositoryName:  pr.Name,
+		RepositoryOwner: pr.Owner,
+	}
+	return scanAddedLines(sess, pr.Repository, pr.Files, base)
+}
+
+// scanAddedLines will scan the lines each file adds as a single commit of a repository. Each file is written to a
+// temporary directory with just its added lines so it can be matched like a file in a clone, and every finding starts
+// out as a copy of base. The action of each finding is an insert or modify unless base already has one.
+func scanAddedLines(sess *Session, repo *Repository, files []*PullRequestFile, base Finding) error {
+	dir, err := ioutil.TempDir("", "wraith")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	sess.Stats.IncrementCommits()
+	dirtyCommit := false
+
+	for _, file := range files {
+		if file.Status == "removed" {
+			continue
+		}
+		sess.Stats.IncrementFilesTotal()
+		if !sess.PathFilter.Scans(file.Path) {
+			sess.Stats.IncrementFilesIgnored()
+			sess.Out.Debug("%s is outside of the paths being scanned and being ignored\n", file.Path)
+			continue
+		}
+
+		added := AddedLines(file.Patch)
+		if len(added) == 0 {
+			sess.Out.Debug("%s adds no lines that can be scanned\n", file.Path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		fullFilePath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(fullFilePath), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(fullFilePath, []byte(addedContent(added)), 0600); err != nil {
+			return err
+		}
+
+		if !sess.ScanTests && isTestFileOrPath(fullFilePath) {
+			sess.Stats.IncrementFilesIgnored()
+			sess.Out.Debug("%s is a test file and being ignored\n", file.Path)
+			continue
+		}
+		if IsMaxFileSize(fullFilePath, sess) {
+			sess.Stats.IncrementFilesIgnored()
+			sess.Out.Debug("%s is too large and being ignored\n", file.Path)
+			continue
+		}
+		matchFile := newMatchFile(fullFilePath)
+		if matchFile.isSkippable(sess) {
+			sess.Stats.IncrementFilesIgnored()
+			sess.Out.Debug("%s is skippable and being ignored\n", file.Path)
+			continue
+		}
+		sess.Stats.IncrementFilesScanned()
+
+		base := base
+		if base.Action == "" {
+			base.Action = pullRequestAction(file.Status)
+		}
+		base.FilePath = file.Path
+		base.originalPath = file.PreviousPath
+
+		for _, signature := range CurrentSignatures() {
+			bMatched, matchMap := signature.ExtractMatch(matchFile, sess, nil)
+			if !bMatched {
+				continue
+			}
+			sess.Stats.IncrementFilesDirty()
+
+			for k, v := range matchMap {
+				content := strings.SplitAfterN(k, "_", 2)[1]
+
+				finding := base
+				finding.Comment = Redact(content, sess.Redact)
+				finding.Description = signature.Description()
+				finding.LineNumber = strconv.Itoa(v)
+				finding.Score = signature.MatchLevel()
+				finding.Severity = signature.Severity()
+				finding.Confidence = signature.Confidence()
+				finding.Signatureid = signature.Signatureid()
+				finding.SignaturesVersion = sess.SignatureVersion
+				finding.WraithVersion = version.AppVersion()
+				finding.secret = content
+				finding.setLine(fullFilePath, nil, v, content, sess)
+
+				finding.Initialize(sess.ScanType)
+				finding.setRepositoryMetadata(repo)
+				if sess.AddFinding(&finding) {
+					dirtyCommit = true
+					realTimeOutput(&finding, sess)
+				}
+			}
+		}
+
+		// match the signatures against what the encoded blobs in the added lines decode to
+		if sess.Decode {
+			for _, finding := range scanDecoded(sess, pluginContent(fullFilePath, nil), base) {
+				finding.setRepositoryMetadata(repo)
+				if sess.AddFinding(finding) {
+					dirtyCommit = true
+					realTimeOutput(finding, sess)
+				}
+			}
+		}
+
+		// hand the added lines off to any external detector plugins the user has loaded
+		if len(sess.DetectorPlugins) > 0 {
+			req := &PluginRequest{
+				CommitHash: base.CommitHash,
+				Content:    pluginContent(fullFilePath, nil),
+				FilePath:   file.Path,
+				Repository: *repo.FullName,
+				ScanType:   sess.ScanType,
+			}
+			base.setRepositoryMetadata(repo)
+			for _, finding := range RunDetectorPlugins(req, base, sess) {
+				if sess.AddFinding(finding) {
+					dirtyCommit = true
+					realTimeOutput(finding, sess)
+				}
+			}
+		}
+	}
+
+	if dirtyCommit {
+		sess.Stats.IncrementCommitsDirty()
+	}
+	sess.Stats.IncrementRepositoriesScanned()
+	sess.RunRepoCompleteHook(repo)
+	sess.sendSlackRepoSummary(repo)
+	sess.fileGithubIssues(repo)
+	return nil
+}
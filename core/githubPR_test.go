@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"fmt"
+	"github.com/google/go-github/github"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"wraith/core"
+)
+
+func TestGithubPullRequests(t *testing.T) {
+
+	Convey("A pull request should be parsed from each form of reference", t, func() {
+		for _, ref := range []string{"acme/api#12", "acme/api/pull/12", "https://github.com/acme/api/pull/12/"} {
+			owner, name, number, err := core.ParseGithubPullRequest(ref)
+			So(err, ShouldBeNil)
+			So(owner, ShouldEqual, "acme")
+			So(name, ShouldEqual, "api")
+			So(number, ShouldEqual, 12)
+		}
+
+		_, _, _, err := core.ParseGithubPullRequest("acme/api")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Only added lines should be taken from a diff, at their line in the new file", t, func() {
+		patch := "@@ -1,3 +1,4 @@\n" +
+			" host = example.com\n" +
+			"-password = old\n" +
+			"+password = hunter2\n" +
+			"+token = abc\n" +
+			" port = 80\n" +
+			"@@ -20,2 +21,2 @@ section\n" +
+			" [db]\n" +
+			"+user = admin\n" +
+			"\\ No newline at end of file"
+
+		added := core.AddedLines(patch)
+		So(added, ShouldResemble, map[int]string{2: "password = hunter2", 3: "token = abc", 22: "user = admin"})
+		So(core.AddedLines(""), ShouldBeEmpty)
+	})
+
+	Convey("Given a github api with a pull request whose files span two pages", t, func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/acme/api/pulls/12", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{
+  "number": 12,
+  "title": "Add config",
+  "html_url": "https://github.com/acme/api/pull/12",
+  "user": {"login": "jane"},
+  "head": {"sha": "abc123"},
+  "base": {"repo": {"name": "api", "full_name": "acme/api", "private": true, "owner": {"login": "acme"}}}
+}`)
+		})
+		mux.HandleFunc("/repos/acme/api/pulls/12/files", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"filename": "old.env", "status": "removed"}]`)
+				return
+			}
+			w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"filename": "config.yml", "status": "added", "patch": "@@ -0,0 +1 @@\n+key: value"}]`)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		Convey("The pull request and every file should be fetched", func() {
+			pr, err := core.FetchGithubPullRequest(client, "acme", "api", 12)
+			So(err, ShouldBeNil)
+			So(pr.Title, ShouldEqual, "Add config")
+			So(pr.Author, ShouldEqual, "jane")
+			So(pr.HeadSHA, ShouldEqual, "abc123")
+			So(*pr.Repository.FullName, ShouldEqual, "acme/api")
+			So(*pr.Repository.Visibility, ShouldEqual, core.VisibilityPrivate)
+			So(pr.Files, ShouldHaveLength, 2)
+			So(pr.Files[0].Patch, ShouldEqual, "@@ -0,0 +1 @@\n+key: value")
+			So(pr.Files[1].Status, ShouldEqual, "removed")
+		})
+
+		Convey("A pull request that does not exist should be an error", func() {
+			_, err := core.FetchGithubPullRequest(client, "acme", "api", 13)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are the defaults of a GithubTransport
+const (
+	GithubMaxRetries = 5
+	GithubMinBackoff = time.Second
+	GithubMaxWait    = 65 * time.Minute
+)
+
+// GithubTransport sends requests to the github api and waits out rate limits. When the primary rate limit is used up
+// it sleeps until the limit resets, when a secondary rate limit is hit it waits as long as github asks, and server
+// errors and dropped connections are retried with exponential backoff.
+type GithubTransport struct {
+	Base       http.RoundTripper
+	Out        *Logger
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxWait    time.Duration
+}
+
+// NewGithubTransport will create a transport with the default retries and waits. The logger may be nil.
+func NewGithubTransport(base http.RoundTripper, logger *Logger) *GithubTransport {
+	if base == nil {
+		base = HTTPTransport()
+	}
+	return &GithubTransport{
+		Base:       base,
+		Out:        logger,
+		MaxRetries: GithubMaxRetries,
+		MinBackoff: GithubMinBackoff,
+		MaxWait:    GithubMaxWait,
+	}
+}
+
+// replayable will return a request whose body can be read again to send it again. A body that can not be read again
+// is read into memory, the same as the request bodies the http package builds from a buffer or string.
+func replayable(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	r.Body, _ = r.GetBody()
+	return r, nil
+}
+
+// RoundTrip will send a request, sending it again for as long as github says to wait and retries are left
+func (t *GithubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := replayable(req)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(r)
+		if attempt == t.MaxRetries {
+			return resp, err
+		}
+
+		var wait time.Duration
+		var retry bool
+		if err != nil {
+			wait, retry = t.backoff(attempt), true
+		} else {
+			wait, retry = t.RetryWait(resp, attempt, time.Now())
+		}
+		if !retry {
+			return resp, err
+		}
+
+		reason := "the request failed"
+		if err == nil {
+			reason = "github returned " + resp.Status
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if t.Out != nil {
+			t.Out.Warn("%s for %s, waiting %s before trying again\n", reason, req.URL.Path, wait.Round(time.Second))
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RetryWait will return how long to wait before a request is sent again after a response, and whether it should be
+// sent again at all. A wait longer than MaxWait is not waited out.
+func (t *GithubTransport) RetryWait(resp *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	var wait time.Duration
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			// a second more is waited so the reset has happened on github's clock too
+			wait = time.Unix(reset, 0).Sub(now) + time.Second
+		} else if isSecondaryRateLimit(resp) {
+			// github asks for at least a minute between attempts when it does not say how long to wait
+			wait = time.Minute + t.backoff(attempt)
+		} else {
+			// a 403 that is not a rate limit is a missing permission, which waiting does not fix
+			return 0, false
+		}
+	case resp.StatusCode >= 500:
+		wait = t.backoff(attempt)
+	default:
+		return 0, false
+	}
+
+	if wait < t.MinBackoff {
+		wait = t.MinBackoff
+	}
+	if wait > t.MaxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// backoff is the exponential wait before the next attempt
+func (t *GithubTransport) backoff(attempt int) time.Duration {
+	return t.MinBackoff << uint(attempt)
+}
+
+// isSecondaryRateLimit will check the message of a 403 for a secondary rate limit that came without a Retry-After
+// header. The body is put back so it can still be read.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	msg := strings.ToLower(string(body))
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
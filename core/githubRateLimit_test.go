@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestGithubTransport(t *testing.T) {
+
+	Convey("Given a github api that answers with a sequence of responses", t, func() {
+		var responses []func(w http.ResponseWriter)
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if len(responses) == 0 {
+				fmt.Fprint(w, `{"login": "acme"}`)
+				return
+			}
+			respond := responses[0]
+			responses = responses[1:]
+			respond(w)
+		}))
+		defer server.Close()
+
+		transport := core.NewGithubTransport(nil, nil)
+		transport.MinBackoff = time.Millisecond
+		client := &http.Client{Transport: transport}
+
+		get := func() (*http.Response, string) {
+			resp, err := client.Get(server.URL + "/users/acme")
+			So(err, ShouldBeNil)
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			return resp, string(body)
+		}
+
+		Convey("A used up rate limit should be waited out until it resets", func() {
+			responses = append(responses, func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+			})
+			resp, body := get()
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(body, ShouldContainSubstring, "acme")
+			So(requests, ShouldEqual, 2)
+		})
+
+		Convey("Secondary rate limits and server errors should be retried", func() {
+			responses = append(responses,
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			)
+			resp, _ := get()
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(requests, ShouldEqual, 4)
+		})
+
+		Convey("A missing permission or resource should not be retried", func() {
+			responses = append(responses, func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+			})
+			resp, body := get()
+			So(resp.StatusCode, ShouldEqual, http.StatusForbidden)
+			So(body, ShouldContainSubstring, "Resource not accessible")
+			So(requests, ShouldEqual, 1)
+		})
+
+		Convey("The last response should be returned once the retries are used up", func() {
+			transport.MaxRetries = 2
+			for i := 0; i < 5; i++ {
+				responses = append(responses, func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) })
+			}
+			resp, _ := get()
+			So(resp.StatusCode, ShouldEqual, http.StatusInternalServerError)
+			So(requests, ShouldEqual, 3)
+		})
+
+		Convey("A request with a body should be sent with it again", func() {
+			var bodies []string
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			})
+			resp, err := client.Post(server.URL+"/app/installations/1/access_tokens", "application/json", strings.NewReader(`{"a": 1}`))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(bodies, ShouldResemble, []string{`{"a": 1}`, `{"a": 1}`})
+
+			Convey("even when the body can only be read once", func() {
+				bodies = nil
+				once := struct{ io.Reader }{strings.NewReader(`{"b": 2}`)}
+				resp, err := client.Post(server.URL+"/app/installations/1/access_tokens", "application/json", once)
+				So(err, ShouldBeNil)
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(bodies, ShouldResemble, []string{`{"b": 2}`, `{"b": 2}`})
+			})
+		})
+	})
+
+	Convey("The wait should follow what github asks for", t, func() {
+		transport := core.NewGithubTransport(nil, nil)
+		now := time.Unix(1600000000, 0)
+		response := func(status int, header map[string]string, body string) *http.Response {
+			resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}
+			for k, v := range header {
+				resp.Header.Set(k, v)
+			}
+			return resp
+		}
+
+		wait, retry := transport.RetryWait(response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1600000600"}, ""), 0, now)
+		So(retry, ShouldBeTrue)
+		So(wait, ShouldEqual, 10*time.Minute+time.Second)
+
+		wait, retry = transport.RetryWait(response(http.StatusForbidden, map[string]string{"Retry-After": "30"}, ""), 0, now)
+		So(retry, ShouldBeTrue)
+		So(wait, ShouldEqual, 30*time.Second)
+
+		wait, retry = transport.RetryWait(response(http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit"}`), 1, now)
+		So(retry, ShouldBeTrue)
+		So(wait, ShouldEqual, time.Minute+2*time.Second)
+
+		wait, retry = transport.RetryWait(response(http.StatusInternalServerError, nil, ""), 3, now)
+		So(retry, ShouldBeTrue)
+		So(wait, ShouldEqual, 8*time.Second)
+
+		_, retry = transport.RetryWait(response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1600090000"}, ""), 0, now)
+		So(retry, ShouldBeFalse)
+
+		_, retry = transport.RetryWait(response(http.StatusNotFound, nil, ""), 0, now)
+		So(retry, ShouldBeFalse)
+	})
+}
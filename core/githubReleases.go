@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+)
+
+// ActionReleaseAsset is the action of findings in the assets of a github release
+const ActionReleaseAsset = "Release Asset"
+
+// GithubReleaseAsset is a file uploaded to a github release, which is scanned with --scan-release-assets
+type GithubReleaseAsset struct {
+	ID      int64
+	Name    string
+	Path    string // where the asset is on the repository, such as releases/v1.2.0/app.zip
+	URL     string // where the asset is downloaded from in a browser
+	Release string // the tag of the release
+	Title   string // the name of the release
+	Author  string // who uploaded the asset
+	Size    int64
+}
+
+// GetReleaseAssets will list the assets of the most recent releases of a repository, up to releases of them
+func (c githubClient) GetReleaseAssets(repo *Repository, releases int) ([]*GithubReleaseAsset, error) {
+	ctx := context.Background()
+	page, _, err := c.apiClient.Repositories.ListReleases(ctx, *repo.Owner, *repo.Name, &github.ListOptions{PerPage: releases})
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []*GithubReleaseAsset
+	for i, release := range page {
+		if i == releases {
+			break
+		}
+		for _, a := range release.Assets {
+			asset := &GithubReleaseAsset{
+				ID:      a.GetID(),
+				Name:    a.GetName(),
+				Path:    "releases/" + release.GetTagName() + "/" + a.GetName(),
+				URL:     a.GetBrowserDownloadURL(),
+				Release: release.GetTagName(),
+				Title:   release.GetName(),
+				Author:  a.GetUploader().GetLogin(),
+				Size:    int64(a.GetSize()),
+			}
+			if asset.URL == "" {
+				asset.URL = release.GetHTMLURL()
+			}
+			if asset.Title == "" {
+				asset.Title = asset.Release
+			}
+			assets = append(assets, asset)
+		}
+	}
+	return assets, nil
+}
+
+// DownloadReleaseAsset will write the content of a release asset to w, returning errDownloadTooLarge once more than
+// maxSize bytes of it have been read. The size of the asset that is listed is only what the uploader claimed.
+func (c githubClient) DownloadReleaseAsset(repo *Repository, asset *GithubReleaseAsset, w io.Writer, maxSize int64) error {
+	u := fmt.Sprintf("repos/%s/%s/releases/assets/%d", *repo.Owner, *repo.Name, asset.ID)
+	req, err := c.apiClient.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	// the content is sent, or redirected to, rather than the json describing the asset
+	req.Header.Set("Accept", "application/octet-stream")
+	return c.download(context.Background(), req, w, maxSize)
+}
+
+// scanReleaseAssets will download the assets of the recent releases of a repository and scan them as files, opening
+// the archives among them. Packaged releases often have config and .env files in them that were never committed.
+func scanReleaseAssets(sess *Session, repo *Repository) {
+	client, ok := sess.Client.(interface {
+		GetReleaseAssets(repo *Repository, releases int) ([]*GithubReleaseAsset, error)
+		DownloadReleaseAsset(repo *Repository, asset *GithubReleaseAsset, w io.Writer, maxSize int64) error
+	})
+	if !ok || sess.isGist(*repo.Owner, *repo.Name) {
+		return
+	}
+
+	log := sess.Out.With("repo", *repo.FullName)
+	assets, err := client.GetReleaseAssets(repo, sess.ReleaseCount)
+	if err != nil {
+		log.Error("Failed to retrieve the releases of %s: %s\n", *repo.FullName, err)
+		return
+	}
+	log.Debug("Scanning %d release assets of %s\n", len(assets), *repo.FullName)
+
+	dir, err := ioutil.TempDir("", "wraith-release")
+	if err != nil {
+		log.Error("Unable to create a temp dir for the release assets of %s: %s\n", *repo.FullName, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	for _, asset := range assets {
+		if sess.Interrupted() {
+			return
+		}
+		sess.Stats.IncrementFilesTotal()
+
+		if asset.Size > sess.ReleaseAssetMaxSize*1024*1024 {
+			log.Debug("%s is larger than the release asset max size and being ignored\n", asset.Path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		matchFile := newMatchFile(asset.Path)
+		if matchFile.isSkippable(sess) {
+			log.Debug("%s is listed as skippable and is being ignored\n", asset.Path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		fullFilePath := filepath.Join(dir, filepath.Base(asset.Name))
+		err := downloadReleaseAsset(client.DownloadReleaseAsset, repo, asset, fullFilePath, sess.ReleaseAssetMaxSize*1024*1024)
+		if err != nil {
+			if e, ok := err.(*github.ErrorResponse); ok && e.Response.StatusCode == http.StatusNotFound {
+				log.Debug("%s was deleted before it could be downloaded\n", asset.Path)
+			} else if err == errDownloadTooLarge {
+				log.Debug("%s is larger than the release asset max size and being ignored\n", asset.Path)
+				sess.Stats.IncrementFilesIgnored()
+			} else {
+				log.Error("Failed to download %s of %s: %s\n", asset.Path, *repo.FullName, err)
+			}
+			os.Remove(fullFilePath)
+			continue
+		}
+
+		base := Finding{
+			Action:          ActionReleaseAsset,
+			AtHead:          true,
+			CommitAuthor:    asset.Author,
+			CommitHash:      asset.Release,
+			CommitMessage:   asset.Title,
+			FilePath:        asset.Path,
+			RepositoryName:  *repo.Name,
+			RepositoryOwner: *repo.Owner,
+			webURL:          asset.URL,
+		}
+		base.setRepositoryMetadata(repo)
+
+		if sess.ArchiveDepth > 0 && archiveKind(asset.Name) != "" {
+			budget := &archiveBudget{remaining: sess.ArchiveMaxSize * 1024 * 1024}
+			scanArchive(fullFilePath, asset.Path, 1, budget, base, sess)
+		} else {
+			scanFile(fullFilePath, base, sess)
+		}
+		os.Remove(fullFilePath)
+	}
+}
+
+// downloadReleaseAsset will write a release asset of no more than maxSize bytes to a file on disk with download
+func downloadReleaseAsset(download func(*Repository, *GithubReleaseAsset, io.Writer, int64) error, repo *Repository, asset *GithubReleaseAsset, fullFilePath string, maxSize int64) error {
+	fh, err := os.Create(fullFilePath)
+	if err != nil {
+		return err
+	}
+	err = download(repo, asset, fh, maxSize)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
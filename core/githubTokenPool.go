@@ -0,0 +1,198 @@
+package core
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GithubTokenPool sends requests to the github api with the tokens of several accounts, each request with the token
+// that has the most of its rate limit left. When the rate limit of a token is used up the request is sent again with
+// the next one, so a scan only has to wait once the limits of all of them are used up.
+type GithubTokenPool struct {
+	Base http.RoundTripper
+	Out  *Logger
+
+	mu     sync.Mutex
+	tokens []*githubToken
+}
+
+// githubToken is a token of the pool and what is left of its rate limit for each resource, such as core or search
+type githubToken struct {
+	value  string
+	quotas map[string]githubQuota
+}
+
+// githubQuota is the rate limit of a token for a resource, as last reported by github
+type githubQuota struct {
+	remaining int
+	reset     time.Time
+}
+
+// NewGithubTokenPool will create a pool of tokens. The base transport and logger may be nil.
+func NewGithubTokenPool(tokens []string, base http.RoundTripper, logger *Logger) *GithubTokenPool {
+	if base == nil {
+		base = HTTPTransport()
+	}
+	p := &GithubTokenPool{Base: base, Out: logger}
+	for _, t := range tokens {
+		p.tokens = append(p.tokens, &githubToken{value: t, quotas: map[string]githubQuota{}})
+	}
+	return p
+}
+
+// RoundTrip will send a request with the token with the most of its rate limit left, sending it again with another
+// token for as long as the rate limit of the one it was sent with is used up. When all of them are used up the
+// response says the limit resets when the first of them does, so a GithubTransport waits no longer than it has to.
+func (p *GithubTokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := replayable(req)
+	if err != nil {
+		return nil, err
+	}
+	resource := githubResource(req.URL.Path)
+	tried := map[*githubToken]bool{}
+	for {
+		token := p.next(resource, tried, time.Now())
+		r := req.Clone(req.Context())
+		if len(tried) > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		r.Header.Set("Authorization", "Bearer "+token.value)
+
+		resp, err := p.Base.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		p.update(token, resource, resp)
+		if !rateLimitUsedUp(resp) {
+			return resp, nil
+		}
+
+		tried[token] = true
+		if p.next(resource, tried, time.Now()) == nil {
+			if reset := p.firstReset(resource); !reset.IsZero() {
+				resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			}
+			return resp, nil
+		}
+		if p.Out != nil {
+			p.Out.Debug("The %s rate limit of github token %d is used up, switching to another token\n", resource, p.index(token)+1)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// Remaining is what is left of the rate limit of a resource for each token, in the order the tokens were given. A
+// token that has not been used yet, or whose limit has reset since, is -1.
+func (p *GithubTokenPool) Remaining(resource string) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var remaining []int
+	for _, t := range p.tokens {
+		q, ok := t.quotas[resource]
+		if !ok || now.After(q.reset) {
+			remaining = append(remaining, -1)
+			continue
+		}
+		remaining = append(remaining, q.remaining)
+	}
+	return remaining
+}
+
+// next is the token with the most of its rate limit for a resource left that has not been tried. Once a token has been
+// tried and only tokens that are known to be used up are left it is nil.
+func (p *GithubTokenPool) next(resource string, tried map[*githubToken]bool, now time.Time) *githubToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var best *githubToken
+	bestRemaining := -1
+	for _, t := range p.tokens {
+		if tried[t] {
+			continue
+		}
+		// a token that has not been used is expected to have all of its limit left
+		remaining := math.MaxInt32
+		if q, ok := t.quotas[resource]; ok && now.Before(q.reset) {
+			remaining = q.remaining
+		}
+		if remaining > bestRemaining {
+			best, bestRemaining = t, remaining
+		}
+	}
+	if bestRemaining == 0 && len(tried) > 0 {
+		return nil
+	}
+	return best
+}
+
+// update will record the rate limit a response reports for the token it was sent with
+func (p *GithubTokenPool) update(t *githubToken, resource string, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t.quotas[resource] = githubQuota{remaining: remaining, reset: time.Unix(reset, 0)}
+}
+
+// firstReset is when the first of the used up rate limits of a resource resets
+func (p *GithubTokenPool) firstReset(resource string) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var first time.Time
+	for _, t := range p.tokens {
+		if q, ok := t.quotas[resource]; ok && q.remaining == 0 && (first.IsZero() || q.reset.Before(first)) {
+			first = q.reset
+		}
+	}
+	return first
+}
+
+// index is the position of a token in the pool, which is how it is referred to in the log without giving it away
+func (p *GithubTokenPool) index(t *githubToken) int {
+	for i, token := range p.tokens {
+		if token == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// githubResource is the rate limit a request to the github api counts against
+func githubResource(path string) string {
+	// github enterprise serves the api under a prefix
+	path = strings.TrimPrefix(path, "/api/v3")
+	switch {
+	case strings.HasPrefix(path, "/search/code"):
+		return "code_search"
+	case strings.HasPrefix(path, "/search/"):
+		return "search"
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	}
+	return "core"
+}
+
+// rateLimitUsedUp will check whether a response was refused because the primary rate limit of its token is used up
+func rateLimitUsedUp(resp *http.Response) bool {
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestGithubTokenPool(t *testing.T) {
+
+	Convey("Given a github api where each token has a rate limit", t, func() {
+		var mu sync.Mutex
+		limits := map[string]int{"token-a": 2, "token-b": 3}
+		resets := map[string]time.Time{"token-a": time.Now().Add(time.Hour), "token-b": time.Now().Add(30 * time.Minute)}
+		var used []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			used = append(used, token)
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resets[token].Unix(), 10))
+			w.Header().Set("X-RateLimit-Resource", "core")
+			if limits[token] == 0 {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+				return
+			}
+			limits[token]--
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limits[token]))
+			fmt.Fprint(w, `{"login": "acme"}`)
+		}))
+		defer server.Close()
+
+		pool := core.NewGithubTokenPool([]string{"token-a", "token-b"}, nil, nil)
+		client := &http.Client{Transport: pool}
+		get := func(path string) *http.Response {
+			resp, err := client.Get(server.URL + path)
+			So(err, ShouldBeNil)
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			return resp
+		}
+
+		Convey("Each request should be sent with the token with the most of its limit left", func() {
+			So(pool.Remaining("core"), ShouldResemble, []int{-1, -1})
+			for i := 0; i < 4; i++ {
+				So(get("/users/acme").StatusCode, ShouldEqual, http.StatusOK)
+			}
+			So(used, ShouldResemble, []string{"token-a", "token-b", "token-b", "token-a"})
+			So(pool.Remaining("core"), ShouldResemble, []int{0, 1})
+			So(pool.Remaining("search"), ShouldResemble, []int{-1, -1})
+		})
+
+		Convey("A request refused for a used up limit should be sent again with another token", func() {
+			limits["token-a"] = 0
+			So(get("/users/acme").StatusCode, ShouldEqual, http.StatusOK)
+			So(used, ShouldResemble, []string{"token-a", "token-b"})
+
+			// the used up token is not tried again until its limit resets
+			So(get("/users/acme").StatusCode, ShouldEqual, http.StatusOK)
+			So(used[2], ShouldEqual, "token-b")
+		})
+
+		Convey("Once every limit is used up the response should say when the first of them resets", func() {
+			limits["token-a"], limits["token-b"] = 0, 0
+			resp := get("/users/acme")
+			So(resp.StatusCode, ShouldEqual, http.StatusForbidden)
+			So(used, ShouldHaveLength, 2)
+			So(resp.Header.Get("X-RateLimit-Reset"), ShouldEqual, strconv.FormatInt(resets["token-b"].Unix(), 10))
+		})
+
+		Convey("A request with a body should be sent with it to the next token", func() {
+			limits["token-a"] = 0
+			var bodies []string
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if strings.HasSuffix(r.Header.Get("Authorization"), "token-a") {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resets["token-a"].Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				}
+			})
+			resp, err := client.Post(server.URL+"/graphql", "application/json", strings.NewReader(`{"query": "{viewer {login}}"}`))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(bodies, ShouldResemble, []string{`{"query": "{viewer {login}}"}`, `{"query": "{viewer {login}}"}`})
+		})
+	})
+}
@@ -0,0 +1,73 @@
+package core_test
+
+import (
+	"fmt"
+	"github.com/google/go-github/github"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"wraith/core"
+)
+
+func TestGithubGists(t *testing.T) {
+
+	Convey("Given a github api where the token belongs to jane", t, func() {
+		gist := func(id, owner string, public bool) string {
+			return fmt.Sprintf(`{"id": "%s", "public": %t, "description": "notes", "owner": {"login": "%s"},
+  "html_url": "https://gist.github.com/%s", "git_pull_url": "https://gist.github.com/%s.git",
+  "updated_at": "2020-08-01T10:00:00Z"}`, id, public, owner, id, id)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"login": "Jane"}`)
+		})
+		mux.HandleFunc("/gists", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "["+gist("aa11", "jane", true)+","+gist("bb22", "jane", false)+"]")
+		})
+		mux.HandleFunc("/users/bob/gists", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, "["+gist("dd44", "bob", true)+"]")
+				return
+			}
+			w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+			fmt.Fprint(w, "["+gist("cc33", "bob", true)+"]")
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		apiClient := github.NewClient(nil)
+		apiClient.BaseURL, _ = url.Parse(server.URL + "/")
+		client := core.NewGithubClient(apiClient, nil).(interface {
+			GetGistsFromOwner(target core.Owner) ([]*core.Repository, error)
+		})
+
+		Convey("Every page of the public gists of another user should be gathered", func() {
+			login := "bob"
+			gists, err := client.GetGistsFromOwner(core.Owner{Login: &login})
+			So(err, ShouldBeNil)
+			So(gists, ShouldHaveLength, 2)
+
+			So(*gists[0].Owner, ShouldEqual, "bob")
+			So(*gists[0].Name, ShouldEqual, "cc33")
+			So(*gists[0].FullName, ShouldEqual, "bob/cc33")
+			So(*gists[0].CloneURL, ShouldEqual, "https://gist.github.com/cc33.git")
+			So(*gists[0].DefaultBranch, ShouldEqual, "")
+			So(*gists[0].Visibility, ShouldEqual, core.VisibilityPublic)
+			So(gists[0].Topics, ShouldResemble, []string{"gist"})
+			So(gists[0].PushedAt, ShouldNotBeNil)
+			So(*gists[1].ID, ShouldNotEqual, *gists[0].ID)
+		})
+
+		Convey("The secret gists of the owner of the token should be included", func() {
+			login := "jane"
+			gists, err := client.GetGistsFromOwner(core.Owner{Login: &login})
+			So(err, ShouldBeNil)
+			So(gists, ShouldHaveLength, 2)
+			So(*gists[1].Name, ShouldEqual, "bb22")
+			So(*gists[1].Visibility, ShouldEqual, core.VisibilityPrivate)
+		})
+	})
+}
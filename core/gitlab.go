@@ -1,88 +1,80 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
-	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
-	"io/ioutil"
-	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // CloneRepository will create either an in memory clone of a given repository or clone to a temp dir.
 func CloneGitlabRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
-
-	cloneOptions := &git.CloneOptions{
-		URL:           *cloneConfig.Url,
-		Depth:         *cloneConfig.Depth,
-		ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", *cloneConfig.Branch)),
-		SingleBranch:  true,
-		Tags:          git.NoTags,
-		Auth: &http.BasicAuth{
-			Username: *cloneConfig.Username,
-			Password: *cloneConfig.Token,
-		},
-	}
-
-	var repository *git.Repository
-	var err error
-	var dir string
-	if !*cloneConfig.InMemClone {
-		dir, err = ioutil.TempDir("", "wraith")
-		if err != nil {
-			return nil, "", err
-		}
-		repository, err = git.PlainClone(dir, false, cloneOptions)
-	} else {
-		repository, err = git.Clone(memory.NewStorage(), nil, cloneOptions)
-	}
-	if err != nil {
-		return nil, dir, err
-	}
-	return repository, dir, nil
-
+	return gitClone(cloneConfig)
 }
 
-// Client holds a gitlab api client instance
+// Client holds a gitlab api client instance. When recurseGroups is set the projects of a group include those of all
+// of its subgroups, and when sharedProjects is set those shared with the group by others.
 type gitlabClient struct {
-	apiClient *gitlab.Client
-	logger    *Logger
+	apiClient      *gitlab.Client
+	logger         *Logger
+	recurseGroups  bool
+	sharedProjects bool
 }
 
 // NewClient creates a gitlab api client instance using a token
 func (c gitlabClient) NewClient(token string, logger *Logger) (gitlabClient, error) {
+	return newGitlabClient("", token, c.recurseGroups, c.sharedProjects, logger)
+}
+
+// NewGitlabClient creates a gitlab api client that talks to the api at apiURL rather than gitlab.com
+func NewGitlabClient(apiURL, token string, recurseGroups, sharedProjects bool, logger *Logger) (IClient, error) {
+	c, err := newGitlabClient(apiURL, token, recurseGroups, sharedProjects, logger)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newGitlabClient(apiURL, token string, recurseGroups, sharedProjects bool, logger *Logger) (gitlabClient, error) {
+	options := []gitlab.ClientOptionFunc{gitlab.WithHTTPClient(NewHTTPClient(0))}
+	if apiURL != "" {
+		options = append(options, gitlab.WithBaseURL(apiURL))
+	}
+
+	c := gitlabClient{recurseGroups: recurseGroups, sharedProjects: sharedProjects, logger: logger}
 	var err error
-	c.apiClient, err = gitlab.NewClient(token)
+	c.apiClient, err = gitlab.NewClient(token, options...)
 	if err != nil {
 		return gitlabClient{}, err
 	}
 	c.apiClient.UserAgent = UserAgent
-	c.logger = logger
 	return c, nil
 }
 
 //TODO make this a single function
-// CheckAPIToken will ensure we have a valid github api token
-func CheckGitlabAPIToken(t string, sess *Session) {
+// CheckGitlabAPIToken will ensure we have a valid gitlab api token
+func CheckGitlabAPIToken(t string) error {
+	if !ValidGitlabAPIToken(t) {
+		return errors.New("Gitlab token is invalid")
+	}
+	return nil
+}
+
+// ValidGitlabAPIToken will check that a gitlab api token is the proper length and format
+func ValidGitlabAPIToken(t string) bool {
 
 	// check to make sure the length is proper
 	if len(t) != 20 {
-		sess.Out.Error("Gitlab token is invalid\n")
-		os.Exit(2)
+		return false
 	}
 
-	// match only letters and numbers and ensure you match 40
+	// match only letters and numbers and ensure you match 20
 	exp1 := regexp.MustCompile(`^[A-Za-z0-9]{20}`)
-	if !exp1.MatchString(t) {
-		sess.Out.Error("Gitlab token is invalid\n")
-		os.Exit(2)
-	}
-	//return t
+	return exp1.MatchString(t)
 }
 
 // GetUserOrganization is used to enumerate the owner in a given org
@@ -208,7 +200,7 @@ func (c gitlabClient) getOrganization(login string) (*gitlab.Group, error) {
 // getUserProjects will gather the projects associated with a given user
 func (c gitlabClient) getUserProjects(id int) ([]*Repository, error) {
 	var allUserProjects []*Repository
-	listUserProjectsOps := &gitlab.ListProjectsOptions{}
+	listUserProjectsOps := &gitlab.ListProjectsOptions{Statistics: gitlab.Bool(true)}
 	for {
 		projects, response, err := c.apiClient.Projects.ListUserProjects(id, listUserProjectsOps)
 		if err != nil {
@@ -228,6 +220,13 @@ func (c gitlabClient) getUserProjects(id int) ([]*Repository, error) {
 					DefaultBranch: gitlab.String(project.DefaultBranch),
 					Description:   gitlab.String(project.Description),
 					Homepage:      gitlab.String(project.WebURL),
+					Visibility:    gitlab.String(string(project.Visibility)),
+					Fork:          gitlab.Bool(project.ForkedFromProject != nil),
+					Archived:      gitlab.Bool(project.Archived),
+					PushedAt:      project.LastActivityAt,
+					Topics:        project.TagList,
+					Size:          gitlabProjectSize(project),
+					sshURL:        project.SSHURLToRepo,
 				}
 				allUserProjects = append(allUserProjects, &p)
 			}
@@ -240,38 +239,122 @@ func (c gitlabClient) getUserProjects(id int) ([]*Repository, error) {
 	return allUserProjects, nil
 }
 
-// getGroupProjects will gather the projects associated with a given group
+// getGroupProjects will gather the projects associated with a given group, along with the projects shared with it
+// unless that is turned off. When subgroups are recursed the projects of every subgroup below it are gathered as well.
 func (c gitlabClient) getGroupProjects(target Owner) ([]*Repository, error) {
-	var allGroupProjects []*Repository
-	listGroupProjectsOps := &gitlab.ListGroupProjectsOptions{}
-	id := strconv.FormatInt(*target.ID, 10)
-	for {
-		projects, response, err := c.apiClient.Groups.ListGroupProjects(id, listGroupProjectsOps)
+	groups := []int{int(*target.ID)}
+	if c.recurseGroups {
+		subgroups, err := c.getSubgroups(int(*target.ID))
 		if err != nil {
 			return nil, err
 		}
-		for _, project := range projects {
-			//don't capture forks
-			if project.ForkedFromProject == nil {
-				id := int64(project.ID)
-				p := Repository{
-					Owner:         gitlab.String(project.Namespace.FullPath),
-					ID:            &id,
-					Name:          gitlab.String(project.Name),
-					FullName:      gitlab.String(project.NameWithNamespace),
-					CloneURL:      gitlab.String(project.HTTPURLToRepo),
-					URL:           gitlab.String(project.WebURL),
-					DefaultBranch: gitlab.String(project.DefaultBranch),
-					Description:   gitlab.String(project.Description),
-					Homepage:      gitlab.String(project.WebURL),
+		groups = append(groups, subgroups...)
+	}
+
+	// a project shared with more than one of the groups is only gathered once
+	var allGroupProjects []*Repository
+	seen := map[int]bool{}
+	for _, group := range groups {
+		listGroupProjectsOps := &gitlab.ListGroupProjectsOptions{WithShared: gitlab.Bool(c.sharedProjects)}
+		for {
+			projects, response, err := c.apiClient.Groups.ListGroupProjects(group, listGroupProjectsOps)
+			if err != nil {
+				return nil, err
+			}
+			for _, project := range projects {
+				//don't capture forks
+				if project.ForkedFromProject == nil && !seen[project.ID] {
+					seen[project.ID] = true
+					id := int64(project.ID)
+					p := Repository{
+						Owner:         gitlab.String(project.Namespace.FullPath),
+						ID:            &id,
+						Name:          gitlab.String(project.Name),
+						FullName:      gitlab.String(project.NameWithNamespace),
+						CloneURL:      gitlab.String(project.HTTPURLToRepo),
+						URL:           gitlab.String(project.WebURL),
+						DefaultBranch: gitlab.String(project.DefaultBranch),
+						Description:   gitlab.String(project.Description),
+						Homepage:      gitlab.String(project.WebURL),
+						Visibility:    gitlab.String(string(project.Visibility)),
+						Fork:          gitlab.Bool(project.ForkedFromProject != nil),
+						Archived:      gitlab.Bool(project.Archived),
+						PushedAt:      project.LastActivityAt,
+						Topics:        project.TagList,
+						Size:          gitlabProjectSize(project),
+						sshURL:        project.SSHURLToRepo,
+					}
+					allGroupProjects = append(allGroupProjects, &p)
 				}
-				allGroupProjects = append(allGroupProjects, &p)
 			}
+			if response.NextPage == 0 {
+				break
+			}
+			listGroupProjectsOps.Page = response.NextPage
 		}
-		if response.NextPage == 0 {
-			break
-		}
-		listGroupProjectsOps.Page = response.NextPage
 	}
 	return allGroupProjects, nil
 }
+
+// getSubgroups will gather the ids of every group nested below a given group, at any depth
+func (c gitlabClient) getSubgroups(id int) ([]int, error) {
+	var allSubgroups []int
+	pending := []int{id}
+	for len(pending) > 0 {
+		parent := pending[0]
+		pending = pending[1:]
+
+		listSubgroupsOps := &gitlab.ListSubgroupsOptions{}
+		for {
+			subgroups, response, err := c.apiClient.Groups.ListSubgroups(parent, listSubgroupsOps)
+			if err != nil {
+				return nil, err
+			}
+			for _, subgroup := range subgroups {
+				c.logger.Debug("Found subgroup %s\n", subgroup.FullPath)
+				allSubgroups = append(allSubgroups, subgroup.ID)
+				pending = append(pending, subgroup.ID)
+			}
+			if response.NextPage == 0 {
+				break
+			}
+			listSubgroupsOps.Page = response.NextPage
+		}
+	}
+	return allSubgroups, nil
+}
+
+// gitlabProjectSize is the size of the repository of a project, which is only given to those who can see its statistics
+func gitlabProjectSize(project *gitlab.Project) *int64 {
+	if project.Statistics == nil {
+		return nil
+	}
+	return &project.Statistics.RepositorySize
+}
+
+// GetRepositorySize will look up the size of the repository of a project. The projects of a group are listed without
+// their statistics, so the size is only looked up for those that are going to be cloned in memory.
+func (c gitlabClient) GetRepositorySize(repo *Repository) (*int64, error) {
+	project, _, err := c.apiClient.Projects.GetProject(int(*repo.ID), &gitlab.GetProjectOptions{Statistics: gitlab.Bool(true)})
+	if err != nil {
+		return nil, err
+	}
+	return gitlabProjectSize(project), nil
+}
+
+// GetRepositoryLanguages will get the languages of a project, from the one most of it is written in down. Projects are
+// listed without their languages, so they are only looked up for those filtered by language.
+func (c gitlabClient) GetRepositoryLanguages(repo *Repository) ([]string, error) {
+	languages, _, err := c.apiClient.Projects.GetProjectLanguages(int(*repo.ID))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range *languages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return (*languages)[names[i]] > (*languages)[names[j]]
+	})
+	return names, nil
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GitlabReportVersion is the version of the gitlab secret detection report schema the gitlab report follows
+const GitlabReportVersion = "15.0.6"
+
+// gitlabReportTime is the layout of the times in a gitlab report, which have no time zone
+const gitlabReportTime = "2006-01-02T15:04:05"
+
+// gitlabSeverities are the severities gitlab shows for each severity of a finding
+var gitlabSeverities = map[string]string{
+	SeverityCritical: "Critical",
+	SeverityHigh:     "High",
+	SeverityMedium:   "Medium",
+	SeverityLow:      "Low",
+}
+
+// GitlabReport is the document written by --gitlab-report, a gitlab secret detection report that is shown in the
+// security widget of merge requests when it is uploaded as the secret_detection report of a job
+type GitlabReport struct {
+	Version         string                `json:"version"`
+	Vulnerabilities []GitlabVulnerability `json:"vulnerabilities"`
+	Scan            GitlabScan            `json:"scan"`
+}
+
+// GitlabVulnerability is a finding in a gitlab report
+type GitlabVulnerability struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	Description          string             `json:"description"`
+	Severity             string             `json:"severity"`
+	Solution             string             `json:"solution"`
+	RawSourceCodeExtract string             `json:"raw_source_code_extract,omitempty"`
+	Identifiers          []GitlabIdentifier `json:"identifiers"`
+	Location             GitlabLocation     `json:"location"`
+}
+
+// GitlabIdentifier names the signature that made a finding
+type GitlabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// GitlabLocation is where in a repository a finding is
+type GitlabLocation struct {
+	File      string       `json:"file"`
+	StartLine int          `json:"start_line,omitempty"`
+	Commit    GitlabCommit `json:"commit"`
+}
+
+// GitlabCommit is the commit a finding was made in
+type GitlabCommit struct {
+	SHA     string `json:"sha"`
+	Author  string `json:"author,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// GitlabScan describes the scan that made a gitlab report
+type GitlabScan struct {
+	Analyzer  GitlabScanner `json:"analyzer"`
+	Scanner   GitlabScanner `json:"scanner"`
+	Type      string        `json:"type"`
+	StartTime string        `json:"start_time"`
+	EndTime   string        `json:"end_time"`
+	Status    string        `json:"status"`
+}
+
+// GitlabScanner is the tool that made a gitlab report
+type GitlabScanner struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Vendor  GitlabVendor `json:"vendor"`
+}
+
+// GitlabVendor is who makes the tool that made a gitlab report
+type GitlabVendor struct {
+	Name string `json:"name"`
+}
+
+// gitlabVulnerabilityID is a uuid made from where a finding is, so gitlab sees the same finding in every pipeline
+func gitlabVulnerabilityID(f *Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		f.RepositoryOwner, f.RepositoryName, f.Action, f.FilePath, f.LineNumber, f.CommitHash, f.Signatureid, f.Fingerprint,
+	}, "\x00")))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// NewGitlabVulnerability will convert a finding into a vulnerability of a gitlab report. The secret is the comment of
+// the finding, which is redacted the same way as in every other output.
+func NewGitlabVulnerability(f *Finding) GitlabVulnerability {
+	description := fmt.Sprintf("%s found in %s", f.Description, f.FilePath)
+	switch f.Action {
+	case ActionCommitMessage:
+		description = fmt.Sprintf("%s found in the message of commit %s", f.Description, f.CommitHash)
+	case ActionGitNote:
+		description = fmt.Sprintf("%s found in the git note of commit %s", f.Description, f.CommitHash)
+	}
+	if f.Encoding != "" {
+		description += fmt.Sprintf(", %s encoded", f.Encoding)
+	}
+
+	solution := "Revoke the secret and replace it, then remove it from the repository and its history."
+	if f.Remediation != nil {
+		solution += " " + f.Remediation.Solution()
+	}
+
+	line, _ := strconv.Atoi(f.LineNumber)
+	return GitlabVulnerability{
+		ID:                   gitlabVulnerabilityID(f),
+		Name:                 f.Description,
+		Description:          description,
+		Severity:             gitlabSeverities[FindingSeverity(f)],
+		Solution:             solution,
+		RawSourceCodeExtract: f.Comment,
+		Identifiers: []GitlabIdentifier{{
+			Type:  "wraith_signature_id",
+			Name:  fmt.Sprintf("%s signature %s", Name, f.Signatureid),
+			Value: f.Signatureid,
+		}},
+		Location: GitlabLocation{
+			File:      f.FilePath,
+			StartLine: line,
+			Commit:    GitlabCommit{SHA: f.CommitHash, Author: f.CommitAuthor, Date: f.CommitDate, Message: f.CommitMessage},
+		},
+	}
+}
+
+// NewGitlabReport will build a gitlab secret detection report from the current state of the session
+func NewGitlabReport(s *Session) GitlabReport {
+	scanner := GitlabScanner{ID: Name, Name: Name, Version: s.Version, Vendor: GitlabVendor{Name: Name}}
+	s.Stats.Lock()
+	scan := GitlabScan{
+		Analyzer:  scanner,
+		Scanner:   scanner,
+		Type:      "secret_detection",
+		StartTime: s.Stats.StartedAt.UTC().Format(gitlabReportTime),
+		EndTime:   s.Stats.FinishedAt.UTC().Format(gitlabReportTime),
+		Status:    "success",
+	}
+	s.Stats.Unlock()
+	// an interrupted scan did not look at everything, so its report is not taken as the full set of findings
+	if s.Interrupted() {
+		scan.Status = "failure"
+	}
+
+	report := GitlabReport{Version: GitlabReportVersion, Vulnerabilities: []GitlabVulnerability{}, Scan: scan}
+	s.Lock()
+	for _, f := range s.Findings {
+		report.Vulnerabilities = append(report.Vulnerabilities, NewGitlabVulnerability(f))
+	}
+	s.Unlock()
+	return report
+}
+
+// WriteGitlabReport will write the findings of the session as a gitlab secret detection report, encrypted like the
+// other reports when they are
+func WriteGitlabReport(location string, s *Session) error {
+	data, err := json.MarshalIndent(NewGitlabReport(s), "", "  ")
+	if err != nil {
+		return err
+	}
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	if _, err := fh.Write(data); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
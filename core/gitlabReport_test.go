@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestGitlabReport(t *testing.T) {
+
+	Convey("Given a session with findings in a file and a commit message", t, func() {
+		startedAt := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			Version: "1.2.3",
+			Stats:   &core.Stats{StartedAt: startedAt, FinishedAt: startedAt.Add(90 * time.Second)},
+			Findings: []*core.Finding{
+				{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "config/prod.env", LineNumber: "3", CommitHash: "1111111111",
+					CommitAuthor: "Jane <jane@example.com>", CommitMessage: "add config", Signatureid: "aws-id", Description: "AWS Access Key ID",
+					Severity: core.SeverityCritical, Comment: "AKIA****************", Fingerprint: "abc"},
+				{RepositoryOwner: "acme", RepositoryName: "api", Action: core.ActionCommitMessage, LineNumber: "1", CommitHash: "2222222222",
+					Signatureid: "slack-token", Description: "Slack Token", Score: 2, Fingerprint: "def"},
+			},
+		}
+
+		dir, err := ioutil.TempDir("", "wraith-gitlab-report")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		location := filepath.Join(dir, "gl-secret-detection-report.json")
+
+		Convey("Each finding should be a vulnerability with its signature, location and gitlab severity", func() {
+			So(core.WriteGitlabReport(location, sess), ShouldBeNil)
+			data, err := ioutil.ReadFile(location)
+			So(err, ShouldBeNil)
+			var report core.GitlabReport
+			So(json.Unmarshal(data, &report), ShouldBeNil)
+
+			So(report.Version, ShouldEqual, core.GitlabReportVersion)
+			So(report.Vulnerabilities, ShouldHaveLength, 2)
+			v := report.Vulnerabilities[0]
+			So(v.Name, ShouldEqual, "AWS Access Key ID")
+			So(v.Severity, ShouldEqual, "Critical")
+			So(v.RawSourceCodeExtract, ShouldEqual, "AKIA****************")
+			So(v.Identifiers, ShouldResemble, []core.GitlabIdentifier{{Type: "wraith_signature_id", Name: "wraith signature aws-id", Value: "aws-id"}})
+			So(v.Location, ShouldResemble, core.GitlabLocation{File: "config/prod.env", StartLine: 3,
+				Commit: core.GitlabCommit{SHA: "1111111111", Author: "Jane <jane@example.com>", Message: "add config"}})
+			So(v.ID, ShouldHaveLength, 36)
+
+			So(report.Vulnerabilities[1].Severity, ShouldEqual, "Low")
+			So(report.Vulnerabilities[1].Description, ShouldEqual, "Slack Token found in the message of commit 2222222222")
+
+			So(report.Scan.Type, ShouldEqual, "secret_detection")
+			So(report.Scan.Scanner.Version, ShouldEqual, "1.2.3")
+			So(report.Scan.StartTime, ShouldEqual, "2020-08-03T09:00:00")
+			So(report.Scan.EndTime, ShouldEqual, "2020-08-03T09:01:30")
+			So(report.Scan.Status, ShouldEqual, "success")
+		})
+
+		Convey("The id of a finding should be the same in every scan and different for every finding", func() {
+			first := core.NewGitlabReport(sess)
+			second := core.NewGitlabReport(sess)
+			So(first.Vulnerabilities[0].ID, ShouldEqual, second.Vulnerabilities[0].ID)
+			So(first.Vulnerabilities[0].ID, ShouldNotEqual, first.Vulnerabilities[1].ID)
+		})
+
+		Convey("An interrupted scan should be reported as a failure", func() {
+			sess.Interrupt()
+			So(core.NewGitlabReport(sess).Scan.Status, ShouldEqual, "failure")
+		})
+	})
+}
@@ -0,0 +1,143 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wraith/core"
+)
+
+// fakeGitlabAPI serves a group with nested subgroups, one of which has its subgroups split over two pages, and a
+// project from another group that is shared with two of them
+func fakeGitlabAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Private-Token") != "0123456789ABCDEFGHIJ" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "401 Unauthorized"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	project := func(id int, name, namespace string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                  id,
+			"name":                name,
+			"name_with_namespace": namespace + " / " + name,
+			"http_url_to_repo":    "https://gitlab.example.com/" + namespace + "/" + name + ".git",
+			"web_url":             "https://gitlab.example.com/" + namespace + "/" + name,
+			"default_branch":      "main",
+			"visibility":          "private",
+			"namespace":           map[string]string{"full_path": namespace},
+		}
+	}
+	group := func(id int, fullPath string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "name": fullPath, "full_path": fullPath}
+	}
+	shared := project(20, "tools", "other")
+	fork := project(11, "site-fork", "acme")
+	fork["forked_from_project"] = map[string]int{"id": 10}
+
+	// like gitlab, projects shared with a group are listed unless they are asked to be left out
+	projects := func(list ...interface{}) {
+		if r.URL.Query().Get("with_shared") == "false" {
+			list = list[:1]
+		}
+		json.NewEncoder(w).Encode(list)
+	}
+
+	switch r.URL.Path {
+	case "/api/v4/groups/1":
+		json.NewEncoder(w).Encode(group(1, "acme"))
+	case "/api/v4/groups/1/subgroups":
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]interface{}{group(3, "acme/empty")})
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		json.NewEncoder(w).Encode([]interface{}{group(2, "acme/platform")})
+	case "/api/v4/groups/2/subgroups":
+		json.NewEncoder(w).Encode([]interface{}{group(4, "acme/platform/infra")})
+	case "/api/v4/groups/3/subgroups", "/api/v4/groups/4/subgroups", "/api/v4/groups/3/projects":
+		json.NewEncoder(w).Encode([]interface{}{})
+	case "/api/v4/groups/1/projects":
+		projects(project(10, "site", "acme"), fork)
+	case "/api/v4/groups/2/projects":
+		projects(project(12, "api", "acme/platform"), shared)
+	case "/api/v4/groups/4/projects":
+		projects(project(13, "terraform", "acme/platform/infra"), shared)
+	case "/api/v4/projects/10/languages":
+		json.NewEncoder(w).Encode(map[string]float64{"JavaScript": 20.5, "Ruby": 79.5})
+	case "/api/v4/projects/12/languages":
+		json.NewEncoder(w).Encode(map[string]float64{"Go": 91.2, "Shell": 8.8})
+	case "/api/v4/projects/13/languages", "/api/v4/projects/20/languages":
+		json.NewEncoder(w).Encode(map[string]float64{"HCL": 100})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "404 Not Found"})
+	}
+}
+
+// repositoryNames is the full name of each repository in the order they were gathered
+func repositoryNames(repos []*core.Repository) []string {
+	var names []string
+	for _, r := range repos {
+		names = append(names, *r.Owner+"/"+*r.Name)
+	}
+	return names
+}
+
+func TestGitlabClient(t *testing.T) {
+
+	Convey("Given the api of a gitlab instance with nested subgroups", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(fakeGitlabAPI))
+		defer server.Close()
+
+		Convey("The projects of every subgroup, and those shared with them, should be gathered once each", func() {
+			client, err := core.NewGitlabClient(server.URL, "0123456789ABCDEFGHIJ", true, true, &core.Logger{})
+			So(err, ShouldBeNil)
+
+			owner, err := client.GetUserOrganization("1")
+			So(err, ShouldBeNil)
+			So(*owner.Type, ShouldEqual, core.TargetTypeOrganization)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repositoryNames(repos), ShouldResemble, []string{
+				"acme/site",
+				"acme/platform/api",
+				"other/tools",
+				"acme/platform/infra/terraform",
+			})
+			So(*repos[1].CloneURL, ShouldEqual, "https://gitlab.example.com/acme/platform/api.git")
+		})
+
+		Convey("Only the projects directly in the group should be gathered when subgroups are not recursed", func() {
+			client, err := core.NewGitlabClient(server.URL, "0123456789ABCDEFGHIJ", false, true, &core.Logger{})
+			So(err, ShouldBeNil)
+
+			owner, err := client.GetUserOrganization("1")
+			So(err, ShouldBeNil)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repositoryNames(repos), ShouldResemble, []string{"acme/site"})
+		})
+
+		Convey("The projects shared with the groups should be left out when they are not wanted", func() {
+			client, err := core.NewGitlabClient(server.URL, "0123456789ABCDEFGHIJ", true, false, &core.Logger{})
+			So(err, ShouldBeNil)
+
+			owner, err := client.GetUserOrganization("1")
+			So(err, ShouldBeNil)
+
+			repos, err := client.GetRepositoriesFromOwner(*owner)
+			So(err, ShouldBeNil)
+			So(repositoryNames(repos), ShouldResemble, []string{
+				"acme/site",
+				"acme/platform/api",
+				"acme/platform/infra/terraform",
+			})
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCAuthConfig is how the gRPC api is protected. Anyone who can call it can start scans and stream back the secrets
+// they find, so it does not start unless callers have to send the token, the basic auth credentials of the web
+// interface, or a client certificate signed by the ca.
+type GRPCAuthConfig struct {
+	Token    string
+	Username string
+	Password string
+	CertFile string
+	KeyFile  string
+	ClientCA string
+}
+
+// GRPCAuth checks every call to the gRPC api before it is served
+type GRPCAuth struct {
+	token string
+	basic string
+	creds credentials.TransportCredentials
+}
+
+// NewGRPCAuth will create the authentication for the gRPC api from its config. The server is served over tls when a
+// certificate is given, and client certificates are required when a client ca is given.
+func NewGRPCAuth(cfg GRPCAuthConfig) (*GRPCAuth, error) {
+	if cfg.Token == "" && cfg.Username == "" && cfg.ClientCA == "" {
+		return nil, errors.New("grpc-token, web-username or grpc-client-ca is needed, as the gRPC api can start scans and stream back the secrets they find")
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("grpc-tls-cert and grpc-tls-key are needed together")
+	}
+	if cfg.ClientCA != "" && cfg.CertFile == "" {
+		return nil, errors.New("grpc-tls-cert and grpc-tls-key are needed for client certificates to be checked")
+	}
+
+	auth := &GRPCAuth{token: cfg.Token}
+	if cfg.Username != "" {
+		auth.basic = base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Password))
+	}
+	if cfg.CertFile == "" {
+		return auth, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if cfg.ClientCA != "" {
+		pem, err := ioutil.ReadFile(cfg.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("there are no certificates in %s", cfg.ClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	auth.creds = credentials.NewTLS(tlsConfig)
+	return auth, nil
+}
+
+// ServerOptions are the options the gRPC server is created with so every call goes through the authentication
+func (a *GRPCAuth) ServerOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := a.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := a.authorize(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+	if a.creds != nil {
+		opts = append(opts, grpc.Creds(a.creds))
+	}
+	return opts
+}
+
+// authorize will check the token, or the basic auth credentials of the web interface, a call was made with. Client
+// certificates have already been checked by the tls handshake by the time a call gets here.
+func (a *GRPCAuth) authorize(ctx context.Context) error {
+	if a.token == "" && a.basic == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		switch {
+		case a.token != "" && strings.HasPrefix(value, "Bearer "):
+			if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(value[len("Bearer "):])), []byte(a.token)) == 1 {
+				return nil
+			}
+		case a.basic != "" && strings.HasPrefix(value, "Basic "):
+			if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(value[len("Basic "):])), []byte(a.basic)) == 1 {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.Unauthenticated, "valid credentials are needed in the authorization metadata")
+}
@@ -0,0 +1,501 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"wraith/rpc"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StatusFailed is used for scans that were submitted through the api but could not be completed
+const StatusFailed = "failed"
+
+// ManagedScan is a scan that is tracked by the scan manager so it can be queried through the api
+type ManagedScan struct {
+	sync.Mutex
+
+	Error    string
+	ID       string
+	ScanType string
+	Schedule string // the name of the schedule that started the scan, if it was not started from the api
+	Session  *Session
+
+	// cancelled is set when the scan is cancelled before its session has been created, so it is stopped once it is
+	cancelled bool
+	// pinned is set for the scan started from the command line, which is never pruned
+	pinned bool
+}
+
+// ScanManager keeps track of the running session as well as any scans that are submitted through the api
+type ScanManager struct {
+	sync.Mutex
+
+	config  *viper.Viper
+	nextID  int
+	scans   map[string]*ManagedScan
+	running sync.WaitGroup
+}
+
+// NewScanManager will create a scan manager. New scans inherit every setting of the given config.
+func NewScanManager(v *viper.Viper) *ScanManager {
+	return &ScanManager{
+		config: v,
+		scans:  make(map[string]*ManagedScan),
+	}
+}
+
+// Add will start tracking an existing session and return the id assigned to it
+func (m *ScanManager) Add(sess *Session) string {
+	m.Lock()
+	defer m.Unlock()
+	id := strconv.Itoa(m.nextID)
+	m.nextID++
+	m.scans[id] = &ManagedScan{ID: id, ScanType: sess.ScanType, Session: sess, pinned: true}
+	return id
+}
+
+// Get will return the scan with the given id
+func (m *ScanManager) Get(id string) (*ManagedScan, bool) {
+	m.Lock()
+	defer m.Unlock()
+	scan, ok := m.scans[id]
+	return scan, ok
+}
+
+// List will return every tracked scan ordered by id
+func (m *ScanManager) List() []*ManagedScan {
+	m.Lock()
+	defer m.Unlock()
+	var scans []*ManagedScan
+	for _, s := range m.scans {
+		scans = append(scans, s)
+	}
+	sort.Slice(scans, func(i, j int) bool {
+		a, _ := strconv.Atoi(scans[i].ID)
+		b, _ := strconv.Atoi(scans[j].ID)
+		return a < b
+	})
+	return scans
+}
+
+// NewScanConfig will validate a scan request and build the configuration for it. Everything is inherited from the
+// base configuration with the fields of the request applied on top.
+func NewScanConfig(base *viper.Viper, req *rpc.SubmitScanRequest) (*viper.Viper, error) {
+	if len(req.Targets) == 0 {
+		return nil, errors.New("at least one target is required")
+	}
+
+	v := viper.New()
+	for _, k := range base.AllKeys() {
+		v.Set(k, base.Get(k))
+	}
+	v.Set("silent", true)
+	v.Set("grpc-port", 0)
+	v.Set("commit-depth", int(req.CommitDepth))
+	v.Set("scan-tests", req.ScanTests)
+	v.Set("no-expand-orgs", req.NoExpandOrgs)
+
+	// a request can ask for secrets to be hidden more than the server hides them, but never less
+	serverRedact := effectiveRedaction(base.GetString("redact"), base.GetBool("hide-secrets"))
+	redact := serverRedact
+	if req.Redact != "" {
+		if !ValidRedactMode(req.Redact) {
+			return nil, fmt.Errorf("unknown redact mode %s", req.Redact)
+		}
+		redact = req.Redact
+	}
+	redact = effectiveRedaction(redact, req.HideSecrets)
+	if !atLeastAsStrict(redact, serverRedact) {
+		return nil, fmt.Errorf("redact mode %s hides less than the %s redaction of the server", redact, serverRedact)
+	}
+	v.Set("redact", redact)
+	v.Set("hide-secrets", redact != RedactNone)
+
+	switch req.ScanType {
+	case "github":
+		v.Set("github-targets", req.Targets)
+		if req.ApiToken != "" {
+			// a token in the request is used over any github app the server is configured with
+			v.Set("github-api-token", req.ApiToken)
+			v.Set("github-app-id", 0)
+		}
+		if v.GetInt64("github-app-id") == 0 && !ValidGithubAPIToken(v.GetString("github-api-token")) {
+			return nil, errors.New("a valid github api token is required")
+		}
+	case "gitlab":
+		v.Set("gitlab-targets", req.Targets)
+		if req.ApiToken != "" {
+			v.Set("gitlab-api-token", req.ApiToken)
+		}
+		if !ValidGitlabAPIToken(v.GetString("gitlab-api-token")) {
+			return nil, errors.New("a valid gitlab api token is required")
+		}
+	case "bitbucket":
+		v.Set("bitbucket-targets", req.Targets)
+		if req.ApiToken != "" {
+			v.Set("bitbucket-oauth-token", req.ApiToken)
+		}
+		if !ValidBitbucketCredentials(v.GetString("bitbucket-username"), v.GetString("bitbucket-app-password"), v.GetString("bitbucket-oauth-token")) {
+			return nil, errors.New("a bitbucket app password or oauth token is required")
+		}
+	case "bitbucketServer":
+		v.Set("bitbucket-server-targets", req.Targets)
+		if req.ApiToken != "" {
+			v.Set("bitbucket-server-token", req.ApiToken)
+		}
+		if !ValidBitbucketServerToken(v.GetString("bitbucket-server-token")) || v.GetString("bitbucket-server-url") == "" {
+			return nil, errors.New("a bitbucket server http access token, and the bitbucket-server-url of the server, are required")
+		}
+	case "azureDevops":
+		v.Set("azure-devops-targets", req.Targets)
+		if req.ApiToken != "" {
+			v.Set("azure-devops-token", req.ApiToken)
+		}
+		if !ValidAzureDevopsToken(v.GetString("azure-devops-token")) {
+			return nil, errors.New("an azure devops personal access token is required")
+		}
+	case "gitea":
+		v.Set("gitea-targets", req.Targets)
+		if req.ApiToken != "" {
+			v.Set("gitea-api-token", req.ApiToken)
+		}
+		if !ValidGiteaToken(v.GetString("gitea-api-token")) || v.GetString("gitea-url") == "" {
+			return nil, errors.New("a gitea access token, and the gitea-url of the server, are required")
+		}
+	case "codeCommit":
+		v.Set("codecommit-targets", req.Targets)
+	case "dockerImage":
+		v.Set("docker-images", req.Targets)
+	case "s3":
+		v.Set("s3-targets", req.Targets)
+	case "kubernetes":
+		v.Set("kube-namespaces", req.Targets)
+	case "confluence":
+		v.Set("confluence-spaces", req.Targets)
+	case "localGit", "localPath":
+		v.Set("local-dirs", req.Targets)
+	default:
+		return nil, fmt.Errorf("unknown scan type %s", req.ScanType)
+	}
+
+	return v, nil
+}
+
+// Submit will validate a scan request, start the scan in the background and return its id. Local scans read the disk
+// of the server, so they can only be submitted when allow-local-scans is set.
+func (m *ScanManager) Submit(req *rpc.SubmitScanRequest) (string, error) {
+	if (req.ScanType == "localGit" || req.ScanType == "localPath") && !m.config.GetBool("allow-local-scans") {
+		return "", fmt.Errorf("%s scans read the disk of the server and can only be submitted with allow-local-scans", req.ScanType)
+	}
+	v, err := NewScanConfig(m.config, req)
+	if err != nil {
+		return "", err
+	}
+	return m.Start(v, req.ScanType, ""), nil
+}
+
+// Start will run a scan with the given configuration in the background and return its id. The schedule is the name
+// of the schedule the scan was started by, if any.
+func (m *ScanManager) Start(v *viper.Viper, scanType string, schedule string) string {
+	m.Lock()
+	id := strconv.Itoa(m.nextID)
+	m.nextID++
+	scan := &ManagedScan{ID: id, ScanType: scanType, Schedule: schedule}
+	m.prune()
+	m.scans[id] = scan
+	m.Unlock()
+
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		sess := &Session{managed: true}
+		sess.Initialize(v, scanType)
+		scan.Lock()
+		scan.Session = sess
+		if scan.cancelled {
+			sess.Interrupt()
+		}
+		scan.Unlock()
+
+		if err := RunScan(sess); err != nil {
+			scan.Lock()
+			scan.Error = err.Error()
+			scan.Unlock()
+		}
+		sess.Finish()
+	}()
+
+	return id
+}
+
+// prune will forget the oldest finished scans beyond scan-retention, along with their findings, so a long running
+// server does not keep every scan it ever ran in memory. The scan started from the command line and scans that are
+// still running are always kept. It is called with the manager locked.
+func (m *ScanManager) prune() {
+	keep := m.config.GetInt("scan-retention")
+	if keep <= 0 {
+		return
+	}
+
+	var finished []int
+	for id, scan := range m.scans {
+		st := scan.Status()
+		if scan.pinned || !(st.Status == StatusFailed || st.FinishedAt > 0) {
+			continue
+		}
+		n, _ := strconv.Atoi(id)
+		finished = append(finished, n)
+	}
+	if len(finished) < keep {
+		return
+	}
+	sort.Ints(finished)
+	// one more is dropped to make room for the scan being started, which will finish as well
+	for _, n := range finished[:len(finished)-keep+1] {
+		delete(m.scans, strconv.Itoa(n))
+	}
+}
+
+// Stop will cancel every scan the manager started that is still running and wait for them to finish what they are
+// scanning, so their findings are written out before the process exits
+func (m *ScanManager) Stop() {
+	for _, scan := range m.List() {
+		scan.Cancel()
+	}
+	m.running.Wait()
+}
+
+// Cancel will stop a scan from starting on anything new, what it is already scanning is finished and its status
+// becomes interrupted. A scan that has already finished can not be cancelled.
+func (s *ManagedScan) Cancel() error {
+	st := s.Status()
+	if st.FinishedAt > 0 || st.Status == StatusFailed {
+		return fmt.Errorf("scan %s has already stopped, it is %s", s.ID, st.Status)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.cancelled = true
+	if s.Session != nil {
+		s.Session.Interrupt()
+	}
+	return nil
+}
+
+// Status will build a snapshot of the current state of a scan
+func (s *ManagedScan) Status() *rpc.ScanStatus {
+	s.Lock()
+	defer s.Unlock()
+
+	st := &rpc.ScanStatus{
+		ScanId:   s.ID,
+		ScanType: s.ScanType,
+		Status:   StatusInitializing,
+		Error:    s.Error,
+		Schedule: s.Schedule,
+	}
+	if s.Session == nil {
+		return st
+	}
+
+	stats := s.Session.Stats
+	stats.Lock()
+	defer stats.Unlock()
+
+	st.Status = stats.Status
+	if s.Error != "" {
+		st.Status = StatusFailed
+	}
+	st.StartedAt = stats.StartedAt.Unix()
+	if !stats.FinishedAt.IsZero() {
+		st.FinishedAt = stats.FinishedAt.Unix()
+	}
+	st.Targets = int32(stats.Targets)
+	st.RepositoriesTotal = int32(stats.RepositoriesTotal)
+	st.RepositoriesScanned = int32(stats.RepositoriesScanned)
+	st.CommitsScanned = int32(stats.Commits)
+	st.FilesScanned = int32(stats.FilesScanned)
+	st.FindingsTotal = int32(stats.FindingsTotal)
+	return st
+}
+
+// findingsSince will return all findings after the given offset and whether or not the scan has completed. The
+// completion is checked first so that a finished scan is guaranteed to have returned all of its findings.
+func (s *ManagedScan) findingsSince(offset int) ([]*Finding, bool) {
+	st := s.Status()
+	done := st.Status == StatusFailed || (st.Status == StatusFinished && st.FinishedAt > 0)
+
+	s.Lock()
+	sess := s.Session
+	s.Unlock()
+	if sess == nil {
+		return nil, done
+	}
+
+	sess.Lock()
+	defer sess.Unlock()
+	if offset >= len(sess.Findings) {
+		return nil, done
+	}
+	findings := make([]*Finding, len(sess.Findings)-offset)
+	copy(findings, sess.Findings[offset:])
+	return findings, done
+}
+
+// grpcScanServer implements the ScanService defined in rpc/wraith.proto
+type grpcScanServer struct {
+	manager *ScanManager
+}
+
+// SubmitScan will queue a new scan and return its id immediately
+func (g *grpcScanServer) SubmitScan(ctx context.Context, req *rpc.SubmitScanRequest) (*rpc.SubmitScanResponse, error) {
+	id, err := g.manager.Submit(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &rpc.SubmitScanResponse{ScanId: id}, nil
+}
+
+// GetScanStatus returns the current status and statistics of a scan
+func (g *grpcScanServer) GetScanStatus(ctx context.Context, req *rpc.GetScanStatusRequest) (*rpc.ScanStatus, error) {
+	scan, ok := g.manager.Get(req.ScanId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "scan %s does not exist", req.ScanId)
+	}
+	return scan.Status(), nil
+}
+
+// ListScans returns the status of every scan known to the server
+func (g *grpcScanServer) ListScans(ctx context.Context, req *rpc.ListScansRequest) (*rpc.ListScansResponse, error) {
+	resp := &rpc.ListScansResponse{}
+	for _, scan := range g.manager.List() {
+		resp.Scans = append(resp.Scans, scan.Status())
+	}
+	return resp, nil
+}
+
+// StreamFindings sends every finding of a scan, and when follow is set keeps sending new findings until the scan finishes
+func (g *grpcScanServer) StreamFindings(req *rpc.StreamFindingsRequest, stream rpc.ScanService_StreamFindingsServer) error {
+	scan, ok := g.manager.Get(req.ScanId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "scan %s does not exist", req.ScanId)
+	}
+
+	sent := 0
+	for {
+		findings, done := scan.findingsSince(sent)
+		for _, f := range findings {
+			if err := stream.Send(f.toProto()); err != nil {
+				return err
+			}
+		}
+		sent += len(findings)
+
+		if !req.Follow || done {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// toProto will convert a finding into its api representation
+func (f *Finding) toProto() *rpc.Finding {
+	return &rpc.Finding{
+		Action:               f.Action,
+		Comment:              f.Comment,
+		CommitAuthor:         f.CommitAuthor,
+		CommitHash:           f.CommitHash,
+		CommitMessage:        f.CommitMessage,
+		CommitUrl:            f.CommitUrl,
+		Description:          f.Description,
+		FilePath:             f.FilePath,
+		FileUrl:              f.FileUrl,
+		LineNumber:           f.LineNumber,
+		RepositoryName:       f.RepositoryName,
+		RepositoryOwner:      f.RepositoryOwner,
+		RepositoryUrl:        f.RepositoryUrl,
+		SignatureId:          f.Signatureid,
+		SignaturesVersion:    f.SignaturesVersion,
+		SecretId:             f.SecretID,
+		WraithVersion:        f.WraithVersion,
+		Score:                int32(f.Score),
+		Labels:               f.Labels,
+		AtHead:               f.AtHead,
+		Verification:         f.Verification,
+		CodeOwners:           f.CodeOwners,
+		RepositoryVisibility: f.RepositoryVisibility,
+		RepositoryFork:       f.RepositoryFork,
+		RepositoryArchived:   f.RepositoryArchived,
+		RepositoryPushedAt:   f.RepositoryPushedAt,
+		RepositoryTopics:     f.RepositoryTopics,
+		Fingerprint:          f.Fingerprint,
+		Context:              contextToProto(f.Context),
+		CommitAuthorName:     f.CommitAuthorName,
+		CommitAuthorEmail:    f.CommitAuthorEmail,
+		CommitDate:           f.CommitDate,
+		CommitterName:        f.CommitterName,
+		CommitterEmail:       f.CommitterEmail,
+	}
+}
+
+// contextToProto will convert the context lines of a finding into their api representation
+func contextToProto(lines []ContextLine) []*rpc.ContextLine {
+	var context []*rpc.ContextLine
+	for _, l := range lines {
+		context = append(context, &rpc.ContextLine{LineNumber: int32(l.LineNumber), Content: l.Content})
+	}
+	return context
+}
+
+// NewGRPCServer will create the gRPC server of the scan orchestration api, checking every call with the auth
+func NewGRPCServer(manager *ScanManager, auth *GRPCAuth) *grpc.Server {
+	server := grpc.NewServer(auth.ServerOptions()...)
+	rpc.RegisterScanServiceServer(server, &grpcScanServer{manager: manager})
+	return server
+}
+
+// InitGRPCServer will start the scan orchestration api alongside the web interface. It shares the scans of the web
+// interface, where the running session is registered with the id 0 so its findings can be streamed like any other scan,
+// and callers can sign in to it with the basic auth credentials of the web interface as well as with its own.
+func (s *Session) InitGRPCServer(v *viper.Viper) {
+	auth, err := NewGRPCAuth(GRPCAuthConfig{
+		Token:    v.GetString("grpc-token"),
+		Username: v.GetString("web-username"),
+		Password: v.GetString("web-password"),
+		CertFile: SetHomeDir(v.GetString("grpc-tls-cert")),
+		KeyFile:  SetHomeDir(v.GetString("grpc-tls-key")),
+		ClientCA: SetHomeDir(v.GetString("grpc-client-ca")),
+	})
+	if err != nil {
+		s.Out.Fatal("Failed to set up authentication for the gRPC server: %s\n", err)
+	}
+
+	bind := fmt.Sprintf("%s:%d", s.BindAddress, v.GetInt("grpc-port"))
+	lis, err := net.Listen("tcp", bind)
+	if err != nil {
+		s.Out.Fatal("Error when starting gRPC server: %s\n", err)
+	}
+
+	server := NewGRPCServer(s.Scans, auth)
+	go func(sess *Session) {
+		if err := server.Serve(lis); err != nil {
+			sess.Out.Fatal("Error when running gRPC server: %s\n", err)
+		}
+	}(s)
+	s.Out.Important("gRPC API available at %s\n", bind)
+}
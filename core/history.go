@@ -0,0 +1,311 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryWeeks is the number of weeks of trend data returned when none is requested
+const HistoryWeeks = 12
+
+// historyLock keeps sessions in the same process, such as those started over gRPC, from overwriting each others scans
+var historyLock sync.Mutex
+
+// History is a record of previous scans kept on disk so findings can be tracked from one scan to the next
+type History struct {
+	Scans    []ScanSummary     `json:"scans"`
+	Findings []*HistoryFinding `json:"findings"`
+}
+
+// ScanSummary is the outcome of a single scan as it is kept in the history
+type ScanSummary struct {
+	Scope        string    `json:"scope"`
+	ScanType     string    `json:"scan_type"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	Targets      int       `json:"targets"`
+	Repositories int       `json:"repositories"`
+	Commits      int       `json:"commits"`
+	Files        int       `json:"files"`
+	Findings     int       `json:"findings"`
+	New          int       `json:"new"`
+	Resolved     int       `json:"resolved"`
+	RiskScore    int       `json:"risk_score"`
+}
+
+// HistoryFinding is a finding that has been seen by at least one scan. It is open for as long as each scan of the same
+// scope reports it again and resolved by the first scan that looked for it again and did not find it.
+type HistoryFinding struct {
+	Scope           string     `json:"scope"`
+	Fingerprint     string     `json:"fingerprint"`
+	SecretID        string     `json:"secret_id"`
+	Description     string     `json:"description"`
+	FilePath        string     `json:"file_path"`
+	RepositoryOwner string     `json:"repository_owner"`
+	RepositoryName  string     `json:"repository_name"`
+	FirstSeen       time.Time  `json:"first_seen"`
+	LastSeen        time.Time  `json:"last_seen"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Trends are the changes in findings over a number of weeks
+type Trends struct {
+	Weeks                 []TrendWeek   `json:"weeks"`
+	Open                  int           `json:"open"`
+	Resolved              int           `json:"resolved"`
+	MeanTimeToRemediation float64       `json:"mean_time_to_remediation_hours"`
+	Scans                 []ScanSummary `json:"scans"`
+}
+
+// TrendWeek is the number of findings that were first seen and resolved in the week starting on Monday at Start
+type TrendWeek struct {
+	Start    time.Time `json:"start"`
+	New      int       `json:"new"`
+	Resolved int       `json:"resolved"`
+}
+
+// LoadHistory will read the history from a file, a file that does not exist yet is an empty history
+func LoadHistory(path string) (*History, error) {
+	h := &History{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Save will write the history to a file, replacing it only once it has been written completely
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Record will add a scan to the history. Findings that are new to the scope are opened and those the last scan of the
+// scope reported but this one did not are resolved, when rescanned says this scan looked for them again. A nil
+// rescanned is a scan that looked at everything. Findings are known by their fingerprint, those recorded before it was
+// kept are matched by their secret id once and take on the fingerprint of the finding they matched.
+func (h *History) Record(summary ScanSummary, findings []*Finding, rescanned func(*HistoryFinding) bool) ScanSummary {
+	seen := map[string]bool{}
+	known := map[string]*HistoryFinding{}
+	legacy := map[string]*HistoryFinding{}
+	for _, hf := range h.Findings {
+		switch {
+		case hf.Scope != summary.Scope:
+		case hf.Fingerprint != "":
+			known[hf.Fingerprint] = hf
+		default:
+			legacy[hf.SecretID] = hf
+		}
+	}
+
+	for _, f := range findings {
+		if seen[f.Fingerprint] {
+			continue
+		}
+		seen[f.Fingerprint] = true
+
+		hf, ok := known[f.Fingerprint]
+		if !ok {
+			if hf, ok = legacy[f.SecretID]; ok {
+				delete(legacy, f.SecretID)
+				hf.Fingerprint = f.Fingerprint
+				known[f.Fingerprint] = hf
+			}
+		}
+		if !ok {
+			hf = &HistoryFinding{
+				Scope:           summary.Scope,
+				Fingerprint:     f.Fingerprint,
+				SecretID:        f.SecretID,
+				Description:     f.Description,
+				FilePath:        f.FilePath,
+				RepositoryOwner: f.RepositoryOwner,
+				RepositoryName:  f.RepositoryName,
+				FirstSeen:       summary.FinishedAt,
+			}
+			h.Findings = append(h.Findings, hf)
+			summary.New++
+		} else if hf.ResolvedAt != nil {
+			// the secret was put back, so it counts as new again
+			hf.FirstSeen = summary.FinishedAt
+			hf.ResolvedAt = nil
+			summary.New++
+		}
+		hf.LastSeen = summary.FinishedAt
+	}
+
+	for _, hf := range h.Findings {
+		if hf.Scope != summary.Scope || seen[hf.Fingerprint] || hf.ResolvedAt != nil {
+			continue
+		}
+		if rescanned == nil || rescanned(hf) {
+			resolved := summary.FinishedAt
+			hf.ResolvedAt = &resolved
+			summary.Resolved++
+		}
+	}
+
+	h.Scans = append(h.Scans, summary)
+	return summary
+}
+
+// Trends will summarize the history for the given number of weeks up to now
+func (h *History) Trends(weeks int, now time.Time) Trends {
+	if weeks <= 0 {
+		weeks = HistoryWeeks
+	}
+
+	// weeks start on monday so they line up with the way most teams plan their work
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	start := monday.AddDate(0, 0, -7*(weeks-1))
+
+	t := Trends{Weeks: make([]TrendWeek, weeks), Scans: []ScanSummary{}}
+	for i := range t.Weeks {
+		t.Weeks[i].Start = start.AddDate(0, 0, 7*i)
+	}
+	week := func(at time.Time) int {
+		if at.Before(start) {
+			return -1
+		}
+		return int(at.Sub(start).Hours() / (24 * 7))
+	}
+
+	var remediation time.Duration
+	for _, hf := range h.Findings {
+		if i := week(hf.FirstSeen); i >= 0 && i < weeks {
+			t.Weeks[i].New++
+		}
+		if hf.ResolvedAt == nil {
+			t.Open++
+			continue
+		}
+		if i := week(*hf.ResolvedAt); i >= 0 && i < weeks {
+			t.Weeks[i].Resolved++
+			t.Resolved++
+			remediation += hf.ResolvedAt.Sub(hf.FirstSeen)
+		}
+	}
+	if t.Resolved > 0 {
+		t.MeanTimeToRemediation = remediation.Hours() / float64(t.Resolved)
+	}
+
+	for _, scan := range h.Scans {
+		if !scan.FinishedAt.Before(start) {
+			t.Scans = append(t.Scans, scan)
+		}
+	}
+	return t
+}
+
+// Scope is what a session scanned, only scans of the same scope are compared to find resolved findings
+func (s *Session) Scope() string {
+	var targets []string
+	targets = append(targets, s.AzureDevopsTargets...)
+	targets = append(targets, s.GiteaTargets...)
+	targets = append(targets, s.BitbucketTargets...)
+	targets = append(targets, s.BitbucketServerTargets...)
+	targets = append(targets, s.CodeCommitTargets...)
+	targets = append(targets, s.DockerArchives...)
+	targets = append(targets, s.DockerImages...)
+	targets = append(targets, s.GithubPullRequests...)
+	targets = append(targets, s.GithubTargets...)
+	targets = append(targets, s.GitlabTargets...)
+	targets = append(targets, s.LocalDirs...)
+	targets = append(targets, s.LocalFiles...)
+	sort.Strings(targets)
+	return s.ScanType + ":" + strings.Join(targets, ",")
+}
+
+// historyRescanned will return whether the session looked for a finding of the history again, so not finding it means
+// it is gone. Nothing is looked for again by a scan that was interrupted, skipped the commits it had seen before, or
+// was narrowed down to some paths or commits. A baselined finding is left out before it is reported, and a finding in
+// a repository that was not scanned, or could not be scanned completely, may still be there.
+func (s *Session) historyRescanned() func(*HistoryFinding) bool {
+	if s.narrowedScan() {
+		return func(*HistoryFinding) bool { return false }
+	}
+
+	failed := map[string]bool{}
+	for _, e := range s.ScanErrors() {
+		failed[e.Name] = true
+	}
+	scanned := map[string]bool{}
+	for _, r := range s.Repositories {
+		if r.Owner != nil && r.Name != nil {
+			scanned[*r.Owner+"/"+*r.Name] = true
+		}
+	}
+
+	return func(hf *HistoryFinding) bool {
+		name := hf.RepositoryOwner + "/" + hf.RepositoryName
+		switch {
+		case s.Baseline.containsFingerprint(hf.Fingerprint), failed[name]:
+			return false
+		case len(scanned) > 0:
+			return scanned[name]
+		}
+		// scans of buckets, images and pages do not list what they scanned as repositories
+		return len(failed) == 0
+	}
+}
+
+// RecordHistory will add the session to the history file, if one has been configured
+func (s *Session) RecordHistory() {
+	if s.HistoryFile == "" {
+		return
+	}
+
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	h, err := LoadHistory(s.HistoryFile)
+	if err != nil {
+		s.Out.Error("Failed to load the history from %s: %s\n", s.HistoryFile, err)
+		return
+	}
+
+	s.Lock()
+	summary := ScanSummary{
+		Scope:        s.Scope(),
+		ScanType:     s.ScanType,
+		StartedAt:    s.Stats.StartedAt,
+		FinishedAt:   s.Stats.FinishedAt,
+		Targets:      s.Stats.Targets,
+		Repositories: s.Stats.RepositoriesScanned,
+		Commits:      s.Stats.Commits,
+		Files:        s.Stats.FilesScanned,
+		Findings:     len(s.Findings),
+		RiskScore:    NewRiskReport(s.Findings).Score,
+	}
+	summary = h.Record(summary, s.Findings, s.historyRescanned())
+	s.Unlock()
+
+	if err := h.Save(s.HistoryFile); err != nil {
+		s.Out.Error("Failed to write the history to %s: %s\n", s.HistoryFile, err)
+		return
+	}
+	s.Out.Important("History written to %s, %d new and %d resolved findings since the last scan\n", s.HistoryFile, summary.New, summary.Resolved)
+}
@@ -0,0 +1,178 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestHistory(t *testing.T) {
+
+	Convey("Given an empty history", t, func() {
+		h := &core.History{}
+		monday := time.Date(2020, 8, 3, 12, 0, 0, 0, time.UTC)
+		scan := func(at time.Time, ids ...string) core.ScanSummary {
+			var findings []*core.Finding
+			for _, id := range ids {
+				findings = append(findings, &core.Finding{Fingerprint: id, SecretID: id + "-in-c1"})
+			}
+			return h.Record(core.ScanSummary{Scope: "github:acme", FinishedAt: at}, findings, nil)
+		}
+
+		Convey("Every finding of the first scan should be new", func() {
+			s := scan(monday, "a", "b", "b")
+			So(s.New, ShouldEqual, 2)
+			So(s.Resolved, ShouldEqual, 0)
+			So(h.Findings, ShouldHaveLength, 2)
+		})
+
+		Convey("Findings missing from the next scan should be resolved", func() {
+			scan(monday, "a", "b")
+			s := scan(monday.AddDate(0, 0, 2), "b", "c")
+			So(s.New, ShouldEqual, 1)
+			So(s.Resolved, ShouldEqual, 1)
+			So(h.Scans, ShouldHaveLength, 2)
+		})
+
+		Convey("A resolved finding that comes back should be new again", func() {
+			scan(monday, "a")
+			scan(monday.AddDate(0, 0, 1))
+			s := scan(monday.AddDate(0, 0, 2), "a")
+			So(s.New, ShouldEqual, 1)
+			So(h.Findings, ShouldHaveLength, 1)
+			So(h.Findings[0].ResolvedAt, ShouldBeNil)
+		})
+
+		Convey("Scans of another scope should not resolve findings", func() {
+			scan(monday, "a")
+			s := h.Record(core.ScanSummary{Scope: "github:other", FinishedAt: monday.AddDate(0, 0, 1)}, nil, nil)
+			So(s.Resolved, ShouldEqual, 0)
+		})
+
+		Convey("Findings a scan did not look for again should stay open", func() {
+			scan(monday, "a", "b")
+			s := h.Record(core.ScanSummary{Scope: "github:acme", FinishedAt: monday.AddDate(0, 0, 1)}, nil, func(hf *core.HistoryFinding) bool {
+				return hf.Fingerprint == "b"
+			})
+			So(s.Resolved, ShouldEqual, 1)
+			So(h.Findings[0].ResolvedAt, ShouldBeNil)
+			So(h.Findings[1].ResolvedAt, ShouldNotBeNil)
+		})
+
+		Convey("A finding recorded by its secret id should take on its fingerprint", func() {
+			h.Findings = []*core.HistoryFinding{{Scope: "github:acme", SecretID: "a-in-c1", FirstSeen: monday}}
+			s := scan(monday.AddDate(0, 0, 1), "a")
+			So(s.New, ShouldEqual, 0)
+			So(h.Findings, ShouldHaveLength, 1)
+			So(h.Findings[0].Fingerprint, ShouldEqual, "a")
+			So(h.Findings[0].FirstSeen, ShouldEqual, monday)
+		})
+
+		Convey("The trends should be bucketed by week", func() {
+			scan(monday.AddDate(0, 0, -7), "a", "b")
+			scan(monday.AddDate(0, 0, 1), "b")
+			trends := h.Trends(4, monday.AddDate(0, 0, 3))
+
+			So(trends.Weeks, ShouldHaveLength, 4)
+			So(trends.Weeks[3].Start, ShouldEqual, time.Date(2020, 8, 3, 0, 0, 0, 0, time.UTC))
+			So(trends.Weeks[2].New, ShouldEqual, 2)
+			So(trends.Weeks[3].Resolved, ShouldEqual, 1)
+			So(trends.Open, ShouldEqual, 1)
+			So(trends.Resolved, ShouldEqual, 1)
+			So(trends.MeanTimeToRemediation, ShouldEqual, 8*24)
+			So(trends.Scans, ShouldHaveLength, 2)
+		})
+	})
+
+	Convey("Given a history file", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-history")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "nested", "history.json")
+
+		Convey("A file that does not exist should be an empty history", func() {
+			h, err := core.LoadHistory(path)
+			So(err, ShouldBeNil)
+			So(h.Scans, ShouldBeEmpty)
+		})
+
+		Convey("A saved history should load the same", func() {
+			h := &core.History{}
+			h.Record(core.ScanSummary{Scope: "localPath:/src", FinishedAt: time.Now()}, []*core.Finding{{Fingerprint: "a"}}, nil)
+			So(h.Save(path), ShouldBeNil)
+
+			loaded, err := core.LoadHistory(path)
+			So(err, ShouldBeNil)
+			So(loaded.Scans, ShouldHaveLength, 1)
+			So(loaded.Findings, ShouldHaveLength, 1)
+			So(loaded.Findings[0].Fingerprint, ShouldEqual, "a")
+		})
+	})
+
+	Convey("Given a history file with a finding in each of two repositories", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-history")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "history.json")
+
+		repo := func(owner, name string) *core.Repository {
+			return &core.Repository{Owner: &owner, Name: &name}
+		}
+		record := func(configure func(*core.Session)) *core.History {
+			sess := &core.Session{Out: &core.Logger{}, Stats: &core.Stats{FinishedAt: time.Now()}, HistoryFile: path,
+				ScanType: "github", Repositories: []*core.Repository{repo("acme", "api"), repo("acme", "web")}}
+			sess.Out.SetSilent(true)
+			configure(sess)
+			sess.RecordHistory()
+			h, err := core.LoadHistory(path)
+			So(err, ShouldBeNil)
+			return h
+		}
+		record(func(sess *core.Session) {
+			sess.Findings = []*core.Finding{
+				{Fingerprint: "a", RepositoryOwner: "acme", RepositoryName: "api"},
+				{Fingerprint: "b", RepositoryOwner: "acme", RepositoryName: "web"},
+			}
+		})
+		resolved := func(h *core.History) []bool {
+			return []bool{h.Findings[0].ResolvedAt != nil, h.Findings[1].ResolvedAt != nil}
+		}
+
+		Convey("A full scan that finds neither should resolve both", func() {
+			So(resolved(record(func(*core.Session) {})), ShouldResemble, []bool{true, true})
+		})
+
+		Convey("A scan that did not look at a repository again should leave its findings open", func() {
+			So(resolved(record(func(sess *core.Session) {
+				sess.Repositories = sess.Repositories[:1]
+			})), ShouldResemble, []bool{true, false})
+		})
+
+		Convey("A baselined finding should stay open", func() {
+			baseline := filepath.Join(dir, "baseline")
+			So(ioutil.WriteFile(baseline, []byte("b\n"), 0600), ShouldBeNil)
+			b, err := core.LoadBaseline(baseline)
+			So(err, ShouldBeNil)
+			So(resolved(record(func(sess *core.Session) {
+				sess.Baseline = b
+			})), ShouldResemble, []bool{true, false})
+		})
+
+		Convey("Incremental, path filtered, commit range and interrupted scans should not resolve anything", func() {
+			filter, err := core.NewPathFilter([]string{"src/"}, nil)
+			So(err, ShouldBeNil)
+			for _, configure := range []func(*core.Session){
+				func(sess *core.Session) { sess.ScanState = &core.ScanState{} },
+				func(sess *core.Session) { sess.PathFilter = filter },
+				func(sess *core.Session) { sess.CommitRange = &core.CommitRange{Since: "v1.0.0"} },
+				func(sess *core.Session) { sess.Interrupt() },
+			} {
+				So(resolved(record(configure)), ShouldResemble, []bool{false, false})
+			}
+		})
+	})
+}
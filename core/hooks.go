@@ -0,0 +1,162 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// HookTimeout is the longest a hook command is allowed to run before it is killed
+const HookTimeout = 60 * time.Second
+
+// These are the values of WRAITH_HOOK that tell a hook command why it was called
+const (
+	HookFinding        = "finding"
+	HookRepoComplete   = "repo-complete"
+	HookEnvironmentVar = "WRAITH_HOOK"
+)
+
+// JSONRepository is the stable representation of a repository and its findings handed to --on-repo-complete-exec
+type JSONRepository struct {
+	SchemaVersion string        `json:"schema_version"`
+	CloneURL      string        `json:"clone_url"`
+	DefaultBranch string        `json:"default_branch"`
+	FullName      string        `json:"full_name"`
+	Name          string        `json:"name"`
+	Owner         string        `json:"owner"`
+	RiskScore     int           `json:"risk_score"`
+	URL           string        `json:"url"`
+	Visibility    string        `json:"visibility,omitempty"`
+	Fork          bool          `json:"fork,omitempty"`
+	Archived      bool          `json:"archived,omitempty"`
+	PushedAt      string        `json:"pushed_at,omitempty"`
+	Topics        []string      `json:"topics,omitempty"`
+	Findings      []JSONFinding `json:"findings"`
+}
+
+// NewJSONRepository will convert a repository and the findings that belong to it into its stable json representation
+func NewJSONRepository(r *Repository, findings []*Finding) JSONRepository {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	jr := JSONRepository{
+		SchemaVersion: JSONSchemaVersion,
+		CloneURL:      deref(r.CloneURL),
+		DefaultBranch: deref(r.DefaultBranch),
+		FullName:      deref(r.FullName),
+		Name:          deref(r.Name),
+		Owner:         deref(r.Owner),
+		URL:           deref(r.URL),
+		Visibility:    deref(r.Visibility),
+		Fork:          r.Fork != nil && *r.Fork,
+		Archived:      r.Archived != nil && *r.Archived,
+		Topics:        r.Topics,
+		Findings:      []JSONFinding{},
+	}
+	if r.PushedAt != nil {
+		jr.PushedAt = r.PushedAt.UTC().Format(time.RFC3339)
+	}
+	for _, f := range findings {
+		jr.Findings = append(jr.Findings, NewJSONFinding(f))
+	}
+	if risk := NewRiskReport(findings); len(risk.Repositories) > 0 {
+		jr.RiskScore = risk.Repositories[0].Score
+	}
+	return jr
+}
+
+// runHook will run a user supplied command through the shell with the json payload on its stdin and its output going to
+// stdout
+func runHook(command string, hookType string, payload interface{}, stdout io.Writer) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", HookEnvironmentVar, hookType))
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", HookTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// runFindingHook will hand a single finding to the --on-finding-exec command if one is configured
+func (s *Session) runFindingHook(finding *Finding) {
+	if s.OnFindingExec == "" {
+		return
+	}
+
+	sink := AlertSink(HookFinding, s.OnFindingExec)
+	now := time.Now()
+	if !s.Alerts.ShouldAlert(sink, finding, now) {
+		s.Out.Debug("Finding hook already alerted for %s\n", finding.FilePath)
+		return
+	}
+
+	jf := NewJSONFinding(finding)
+	jf.SchemaVersion = JSONSchemaVersion
+	if err := runHook(s.OnFindingExec, HookFinding, jf, s.stdout()); err != nil {
+		s.Out.Error("Finding hook failed for %s: %s\n", finding.FilePath, err)
+		return
+	}
+	s.Alerts.Alerted(sink, finding, now)
+}
+
+// RunRepoCompleteHook will hand a repository and its findings to the --on-repo-complete-exec command if one is
+// configured. When alerts are tracked only the findings the command has not been alerted to are included.
+func (s *Session) RunRepoCompleteHook(repo *Repository) {
+	if s.OnRepoCompleteExec == "" {
+		return
+	}
+
+	sink := AlertSink(HookRepoComplete, s.OnRepoCompleteExec)
+	now := time.Now()
+
+	var findings []*Finding
+	s.Lock()
+	for _, f := range s.Findings {
+		if f.RepositoryOwner == *repo.Owner && f.RepositoryName == *repo.Name && s.Alerts.ShouldAlert(sink, f, now) {
+			findings = append(findings, f)
+		}
+	}
+	s.Unlock()
+
+	if err := runHook(s.OnRepoCompleteExec, HookRepoComplete, NewJSONRepository(repo, findings), s.stdout()); err != nil {
+		s.Out.Error("Repository hook failed for %s: %s\n", *repo.FullName, err)
+		return
+	}
+	for _, f := range findings {
+		s.Alerts.Alerted(sink, f, now)
+	}
+}
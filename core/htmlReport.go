@@ -0,0 +1,233 @@
+package core
+
+import (
+	"html/template"
+	"sort"
+)
+
+// HTMLReport is the data a standalone html report is rendered from. It is built from the json report so the two
+// always agree, and secrets in it are redacted the same way.
+type HTMLReport struct {
+	JSONReport
+	Name         string
+	Severities   []string
+	Counts       map[string]int
+	Repositories []HTMLRepository
+}
+
+// HTMLRepository is the findings of a single repository in an html report, grouped by the signature that found them
+type HTMLRepository struct {
+	Owner      string
+	Name       string
+	URL        string
+	Risk       int
+	Counts     map[string]int
+	Total      int
+	Signatures []HTMLSignature
+}
+
+// HTMLSignature is the findings of a single signature within a repository
+type HTMLSignature struct {
+	Description string
+	Severity    string
+	Remediation *Remediation
+	Findings    []HTMLFinding
+}
+
+// HTMLFinding is a finding along with the severity it is shown with
+type HTMLFinding struct {
+	JSONFinding
+	Severity string
+}
+
+// NewHTMLReport will build an html report from the current state of the session. Repositories are ordered from the
+// most to the least severe findings, and the signatures and findings within them the same way.
+func NewHTMLReport(s *Session) HTMLReport {
+	report := HTMLReport{
+		JSONReport: NewJSONReport(s),
+		Name:       Name,
+		Severities: []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow},
+		Counts:     map[string]int{},
+	}
+
+	risk := map[string]int{}
+	for _, r := range report.Risk.Repositories {
+		risk[r.Owner+"/"+r.Name] = r.Score
+	}
+
+	repos := map[string]*HTMLRepository{}
+	signatures := map[string]int{}
+	for _, f := range report.Findings {
+		severity := f.Severity
+		report.Counts[severity]++
+
+		key := f.RepositoryOwner + "/" + f.RepositoryName
+		repo, ok := repos[key]
+		if !ok {
+			repo = &HTMLRepository{
+				Owner:  f.RepositoryOwner,
+				Name:   f.RepositoryName,
+				URL:    f.RepositoryURL,
+				Risk:   risk[key],
+				Counts: map[string]int{},
+			}
+			repos[key] = repo
+		}
+		repo.Counts[severity]++
+		repo.Total++
+
+		sigKey := key + "\x00" + f.Description
+		i, ok := signatures[sigKey]
+		if !ok {
+			i = len(repo.Signatures)
+			repo.Signatures = append(repo.Signatures, HTMLSignature{Description: f.Description, Remediation: f.Remediation})
+			signatures[sigKey] = i
+		}
+		repo.Signatures[i].Findings = append(repo.Signatures[i].Findings, HTMLFinding{JSONFinding: f, Severity: severity})
+	}
+
+	for _, repo := range repos {
+		for i := range repo.Signatures {
+			sig := &repo.Signatures[i]
+			sort.SliceStable(sig.Findings, func(a, b int) bool {
+				return severityRanks[sig.Findings[a].Severity] > severityRanks[sig.Findings[b].Severity]
+			})
+			sig.Severity = sig.Findings[0].Severity
+		}
+		sort.SliceStable(repo.Signatures, func(a, b int) bool {
+			if severityRanks[repo.Signatures[a].Severity] != severityRanks[repo.Signatures[b].Severity] {
+				return severityRanks[repo.Signatures[a].Severity] > severityRanks[repo.Signatures[b].Severity]
+			}
+			return repo.Signatures[a].Description < repo.Signatures[b].Description
+		})
+		report.Repositories = append(report.Repositories, *repo)
+	}
+
+	sort.Slice(report.Repositories, func(a, b int) bool {
+		ra, rb := report.Repositories[a], report.Repositories[b]
+		for _, severity := range report.Severities {
+			if ra.Counts[severity] != rb.Counts[severity] {
+				return ra.Counts[severity] > rb.Counts[severity]
+			}
+		}
+		return ra.Owner+"/"+ra.Name < rb.Owner+"/"+rb.Name
+	})
+
+	return report
+}
+
+// WriteHTMLReport will write the session as a single html file that can be opened and shared without the web
+// interface. Everything it needs, including its styles, is inside the file.
+func WriteHTMLReport(location string, s *Session) error {
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	if err := htmlReportTemplate.Execute(fh, NewHTMLReport(s)); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"short": func(hash string) string {
+		if len(hash) > 8 {
+			return hash[:8]
+		}
+		return hash
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Name}} report {{.FinishedAt}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292e; margin: 2em auto; max-width: 1200px; padding: 0 1em; }
+h1 { margin-bottom: 0; }
+.meta { color: #586069; margin-top: .25em; }
+table { border-collapse: collapse; width: 100%; margin: .5em 0 1em; }
+th, td { text-align: left; padding: .35em .6em; border-bottom: 1px solid #e1e4e8; vertical-align: top; }
+th { background: #f6f8fa; }
+.num { text-align: right; }
+.badge { display: inline-block; border-radius: 3px; padding: 0 .45em; font-size: .8em; font-weight: 600; text-transform: uppercase; color: #fff; }
+.critical { background: #86181d; } .high { background: #d73a49; } .medium { background: #e36209; } .low { background: #6a737d; }
+.rollup td { font-size: 1.4em; font-weight: 600; }
+details { border: 1px solid #e1e4e8; border-radius: 6px; margin: .75em 0; padding: .5em 1em; }
+details details { border-style: dashed; }
+summary { cursor: pointer; font-weight: 600; }
+code { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: .9em; word-break: break-all; }
+pre.context { margin: .35em 0 0; padding: .35em .6em; background: #f6f8fa; white-space: pre-wrap; }
+pre.context mark { display: block; }
+.remediation { color: #586069; margin: .5em 0 0; }
+</style>
+</head>
+<body>
+<h1>{{.Name}} report</h1>
+<p class="meta">{{.ScanType}} scan from {{.StartedAt}} to {{.FinishedAt}} &middot; {{.Name}} {{.WraithVersion}}{{if .SignaturesVersion}} &middot; signatures {{.SignaturesVersion}}{{end}}</p>
+
+<h2>Summary</h2>
+<table class="rollup">
+<tr><th>Findings</th>{{range .Severities}}<th><span class="badge {{.}}">{{.}}</span></th>{{end}}<th>Risk</th></tr>
+<tr><td>{{len .Findings}}</td>{{range .Severities}}<td>{{index $.Counts .}}</td>{{end}}<td>{{.Risk.Score}}</td></tr>
+</table>
+<table>
+<tr><th>Targets</th><th>Repositories scanned</th><th>Commits scanned</th><th>Files scanned</th><th>Dirty files</th></tr>
+<tr><td>{{.Stats.Targets}}</td><td>{{.Stats.RepositoriesScanned}}</td><td>{{.Stats.CommitsScanned}}</td><td>{{.Stats.FilesScanned}}</td><td>{{.Stats.FilesDirty}}</td></tr>
+</table>
+
+{{if .Repositories}}
+<h2>Repositories</h2>
+<table>
+<tr><th>Repository</th>{{range .Severities}}<th class="num"><span class="badge {{.}}">{{.}}</span></th>{{end}}<th class="num">Risk</th></tr>
+{{range .Repositories}}<tr><td>{{if .URL}}<a href="{{.URL}}">{{.Owner}}/{{.Name}}</a>{{else}}{{.Owner}}/{{.Name}}{{end}}</td>{{$repo := .}}{{range $.Severities}}<td class="num">{{index $repo.Counts .}}</td>{{end}}<td class="num">{{.Risk}}</td></tr>
+{{end}}</table>
+{{if .Secrets}}
+<h2>Secrets</h2>
+<p class="meta">{{len .Secrets}} {{if eq (len .Secrets) 1}}secret{{else}}different secrets{{end}} in {{len .Findings}} findings, each secret once however many places it was found in.</p>
+<table>
+<tr><th>Severity</th><th>Secret</th><th>Signature</th><th class="num">Found</th><th>Repositories</th><th class="num">Files</th><th class="num">Commits</th><th>Committed</th><th>Verification</th></tr>
+{{range .Secrets}}<tr>
+<td><span class="badge {{.Severity}}">{{.Severity}}</span></td>
+<td><code title="{{.SecretHash}}">{{.Secret}}</code>{{if .AtHead}} <em>at head</em>{{end}}</td>
+<td>{{.Description}}</td>
+<td class="num">{{.Occurrences}}</td>
+<td>{{range $i, $r := .Repositories}}{{if $i}}, {{end}}{{$r}}{{end}}</td>
+<td class="num">{{.Files}}</td>
+<td class="num">{{.Commits}}</td>
+<td>{{if .FirstCommitDate}}<small>{{.FirstCommitDate}}{{if ne .FirstCommitDate .LastCommitDate}} to {{.LastCommitDate}}{{end}}</small>{{end}}</td>
+<td>{{.Verification}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+<h2>Findings</h2>
+{{range .Repositories}}<details open>
+<summary>{{.Owner}}/{{.Name}} &middot; {{.Total}} {{if eq .Total 1}}finding{{else}}findings{{end}}</summary>
+{{range .Signatures}}<details open>
+<summary><span class="badge {{.Severity}}">{{.Severity}}</span> {{.Description}} &middot; {{len .Findings}}</summary>
+{{with .Remediation}}<p class="remediation">{{with .RotationURL}}<a href="{{.}}">Rotate it</a>. {{end}}{{with .Vault}}{{.}} {{end}}{{with .DocsURL}}<a href="{{.}}">How to remediate</a>{{end}}</p>
+{{end}}<table>
+<tr><th>Severity</th><th>Confidence</th><th>File</th><th>Commit</th><th>Author</th><th>Match</th><th>Verification</th><th>Fingerprint</th></tr>
+{{range .Findings}}<tr>
+<td><span class="badge {{.Severity}}">{{.Severity}}</span></td>
+<td>{{.Confidence}}</td>
+<td>{{if .FileURL}}<a href="{{.FileURL}}">{{or .FilePath .Action}}</a>{{else}}{{or .FilePath .Action}}{{end}}{{if and .LineNumber (ne .LineNumber "0")}}:{{.LineNumber}}{{end}}{{if .AtHead}} <em>at head</em>{{end}}</td>
+<td>{{if .CommitURL}}<a href="{{.CommitURL}}"><code>{{short .CommitHash}}</code></a>{{else}}<code>{{short .CommitHash}}</code>{{end}}{{with .FirstSeen}}<br><small>first in {{if .CommitURL}}<a href="{{.CommitURL}}"><code>{{short .CommitHash}}</code></a>{{else}}<code>{{short .CommitHash}}</code>{{end}}</small>{{end}}{{with .LastSeen}}<br><small>last in {{if .CommitURL}}<a href="{{.CommitURL}}"><code>{{short .CommitHash}}</code></a>{{else}}<code>{{short .CommitHash}}</code>{{end}}</small>{{end}}{{if gt .Occurrences 1}}<br><small>found {{.Occurrences}} times</small>{{end}}</td>
+<td>{{if .CommitAuthorEmail}}<a href="mailto:{{.CommitAuthorEmail}}">{{or .CommitAuthorName .CommitAuthor}}</a>{{else}}{{.CommitAuthor}}{{end}}{{if .CommitDate}}<br><small>{{.CommitDate}}</small>{{end}}</td>
+<td><code>{{.Comment}}</code>{{if .Encoding}} <em>{{.Encoding}}</em>{{end}}{{with .JWT}}<br><small>jwt{{if .Issuer}} from {{.Issuer}}{{end}}{{if .Subject}} for {{.Subject}}{{end}}{{if .Expired}}, expired {{.ExpiresAt}}{{else if .ExpiresAt}}, expires {{.ExpiresAt}}{{end}}</small>{{end}}{{if .Context}}{{$line := .LineNumber}}
+<pre class="context">{{range .Context}}{{if eq (print .LineNumber) $line}}<mark>{{.LineNumber}}  {{.Content}}</mark>{{else}}{{.LineNumber}}  {{.Content}}
+{{end}}{{end}}</pre>{{end}}</td>
+<td>{{.Verification}}</td>
+<td><code title="{{.Fingerprint}}">{{short .Fingerprint}}</code></td>
+</tr>
+{{end}}</table>
+</details>
+{{end}}</details>
+{{end}}{{else}}
+<p>No findings.</p>
+{{end}}
+</body>
+</html>
+`))
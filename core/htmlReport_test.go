@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+func TestHTMLReport(t *testing.T) {
+
+	Convey("Given a session with findings in two repositories", t, func() {
+		sess := &core.Session{
+			Stats:    &core.Stats{},
+			ScanType: "github",
+			Findings: []*core.Finding{
+				{RepositoryOwner: "acme", RepositoryName: "api", Description: "AWS Access Key ID", Score: 3, FilePath: "a.env", CommitHash: "1111111111"},
+				{RepositoryOwner: "acme", RepositoryName: "web", Description: "GitHub token", Score: 3, FilePath: "b.js", Comment: "<script>alert(1)</script>"},
+				{RepositoryOwner: "acme", RepositoryName: "api", Description: "Private key", Score: 5, FilePath: "id_rsa", LineNumber: "1"},
+				{RepositoryOwner: "acme", RepositoryName: "api", Description: "AWS Access Key ID", Score: 4, FilePath: "c.env"},
+			},
+		}
+
+		Convey("Findings should be grouped by repository and signature, most severe first", func() {
+			report := core.NewHTMLReport(sess)
+			So(report.Counts, ShouldResemble, map[string]int{core.SeverityCritical: 1, core.SeverityHigh: 1, core.SeverityMedium: 2})
+			So(report.Repositories, ShouldHaveLength, 2)
+
+			api := report.Repositories[0]
+			So(api.Name, ShouldEqual, "api")
+			So(api.Total, ShouldEqual, 3)
+			So(api.Signatures, ShouldHaveLength, 2)
+			So(api.Signatures[0].Description, ShouldEqual, "Private key")
+			So(api.Signatures[0].Severity, ShouldEqual, core.SeverityCritical)
+			So(api.Signatures[1].Description, ShouldEqual, "AWS Access Key ID")
+			So(api.Signatures[1].Severity, ShouldEqual, core.SeverityHigh)
+			So(api.Signatures[1].Findings, ShouldHaveLength, 2)
+			So(api.Signatures[1].Findings[0].FilePath, ShouldEqual, "c.env")
+
+			So(report.Repositories[1].Name, ShouldEqual, "web")
+		})
+
+		Convey("The report should be a single html file with its content escaped", func() {
+			dir, err := ioutil.TempDir("", "wraith-html")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+
+			location := filepath.Join(dir, "report.html")
+			So(core.WriteHTMLReport(location, sess), ShouldBeNil)
+
+			data, err := ioutil.ReadFile(location)
+			So(err, ShouldBeNil)
+			html := string(data)
+			So(html, ShouldStartWith, "<!DOCTYPE html>")
+			So(html, ShouldContainSubstring, "acme/api")
+			So(html, ShouldContainSubstring, "id_rsa:1</td>")
+			So(html, ShouldContainSubstring, "<code>11111111</code>")
+			So(html, ShouldContainSubstring, "&lt;script&gt;alert(1)&lt;/script&gt;")
+			So(html, ShouldNotContainSubstring, "<script")
+			So(html, ShouldNotContainSubstring, "<link")
+		})
+	})
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/http/httpproxy"
+	gitclient "gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// HTTPConfig is how wraith connects to the apis and git servers it talks to, for networks where traffic has to go
+// through a proxy or where TLS is intercepted with a certificate authority of their own
+type HTTPConfig struct {
+	Proxy              string // a proxy url to use for http and https in place of HTTP_PROXY and HTTPS_PROXY
+	CABundle           string // a pem file of certificate authorities to trust on top of those of the system
+	InsecureSkipVerify bool
+}
+
+// NewHTTPConfig will read the proxy, ca-bundle and insecure-skip-verify settings
+func NewHTTPConfig(v *viper.Viper) HTTPConfig {
+	return HTTPConfig{
+		Proxy:              v.GetString("proxy"),
+		CABundle:           v.GetString("ca-bundle"),
+		InsecureSkipVerify: v.GetBool("insecure-skip-verify"),
+	}
+}
+
+// httpTransport is what every api client and http git clone connects with, it is set once a session is initialized
+var (
+	httpTransport     http.RoundTripper = http.DefaultTransport
+	httpProxy                           = http.ProxyFromEnvironment
+	httpTransportLock sync.RWMutex
+)
+
+// ConfigureHTTP will set up the proxy and certificate authorities used by every api client and http git clone. Without
+// a proxy the ones in HTTP_PROXY, HTTPS_PROXY and NO_PROXY are used, and hosts in NO_PROXY skip a proxy that is given.
+func ConfigureHTTP(cfg HTTPConfig) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("%s is not a proxy url, it should look like http://proxy.example.com:3128", cfg.Proxy)
+		}
+		env := httpproxy.FromEnvironment()
+		proxyFunc := (&httpproxy.Config{HTTPProxy: cfg.Proxy, HTTPSProxy: cfg.Proxy, NoProxy: env.NoProxy}).ProxyFunc()
+		proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := httpTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	httpTransportLock.Lock()
+	httpTransport = transport
+	httpProxy = proxy
+	httpTransportLock.Unlock()
+
+	// go-git clones over http with a client of its own unless another is installed for the protocol
+	gitClient := githttp.NewClient(&http.Client{Transport: transport})
+	gitclient.InstallProtocol("http", gitClient)
+	gitclient.InstallProtocol("https", gitClient)
+	return nil
+}
+
+// httpTLSConfig is the tls config of the transport, trusting the certificate authorities of the system and the bundle
+func httpTLSConfig(cfg HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CABundle == "" {
+		return tlsConfig, nil
+	}
+
+	bundle := SetHomeDir(cfg.CABundle)
+	pem, err := ioutil.ReadFile(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the ca bundle: %s", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("there are no pem certificates in %s", bundle)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// HTTPTransport is the transport set up by ConfigureHTTP, the default transport until it is called
+func HTTPTransport() http.RoundTripper {
+	httpTransportLock.RLock()
+	defer httpTransportLock.RUnlock()
+	return httpTransport
+}
+
+// HTTPProxy is the proxy set up by ConfigureHTTP, for transports that need a tls config of their own such as the ones
+// of kubernetes clusters
+func HTTPProxy() func(*http.Request) (*url.URL, error) {
+	httpTransportLock.RLock()
+	defer httpTransportLock.RUnlock()
+	return httpProxy
+}
+
+// NewHTTPClient will create a client that connects with the transport set up by ConfigureHTTP, a timeout of zero
+// means there is none
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: HTTPTransport()}
+}
@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"encoding/pem"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestConfigureHTTP(t *testing.T) {
+	defer core.ConfigureHTTP(core.HTTPConfig{})
+
+	Convey("Given a server with a certificate of its own certificate authority", t, func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		dir, _ := ioutil.TempDir("", "wraith")
+		defer os.RemoveAll(dir)
+		bundle := filepath.Join(dir, "ca.pem")
+		ioutil.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+		get := func() error {
+			resp, err := core.NewHTTPClient(5 * time.Second).Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}
+
+		Convey("It should only be trusted with the ca bundle or when certificates are not verified", func() {
+			So(core.ConfigureHTTP(core.HTTPConfig{}), ShouldBeNil)
+			So(get(), ShouldNotBeNil)
+
+			So(core.ConfigureHTTP(core.HTTPConfig{CABundle: bundle}), ShouldBeNil)
+			So(get(), ShouldBeNil)
+
+			So(core.ConfigureHTTP(core.HTTPConfig{InsecureSkipVerify: true}), ShouldBeNil)
+			So(get(), ShouldBeNil)
+		})
+
+		Convey("A ca bundle without certificates should fail", func() {
+			ioutil.WriteFile(bundle, []byte("not a certificate"), 0600)
+			err := core.ConfigureHTTP(core.HTTPConfig{CABundle: bundle})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "there are no pem certificates in "+bundle)
+		})
+	})
+
+	Convey("Given a proxy", t, func() {
+		var mu sync.Mutex
+		var proxied []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			proxied = append(proxied, r.URL.String())
+			mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer proxy.Close()
+		So(core.ConfigureHTTP(core.HTTPConfig{Proxy: proxy.URL}), ShouldBeNil)
+
+		Convey("Api requests and git clones should go through it", func() {
+			resp, err := core.NewHTTPClient(5 * time.Second).Get("http://api.wraith.example/repos")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+			_, err = git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: "http://git.wraith.example/acme/api.git"})
+			So(err, ShouldNotBeNil)
+
+			So(proxied, ShouldResemble, []string{
+				"http://api.wraith.example/repos",
+				"http://git.wraith.example/acme/api.git/info/refs?service=git-upload-pack",
+			})
+		})
+
+		Convey("A proxy that is not a url should fail", func() {
+			err := core.ConfigureHTTP(core.HTTPConfig{Proxy: "proxy.example.com"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "proxy.example.com is not a proxy url, it should look like http://proxy.example.com:3128")
+		})
+	})
+}
@@ -9,3 +9,9 @@ type IClient interface {
 	GetRepositoriesFromOwner(target Owner) ([]*Repository, error)
 	GetOrganizationMembers(target Owner) ([]*Owner, error)
 }
+
+// IDetectorPlugin interface is used by external detectors that are handed candidate files and report findings back.
+type IDetectorPlugin interface {
+	Name() string
+	Scan(req *PluginRequest) ([]PluginFinding, error)
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// InterruptedExitCode is the code a scan stopped by SIGINT or SIGTERM exits with, which is what a shell uses for an
+// interrupted command
+const InterruptedExitCode = 130
+
+// StatusInterrupted is the status of a scan that was stopped before it finished
+const StatusInterrupted = "interrupted"
+
+// HandleInterrupts will stop the scan of the session rather than exiting on the first SIGINT or SIGTERM, so the
+// repositories already being scanned are finished and everything found so far is written out when the session is
+// finished. A second signal exits straight away. The signals are handled until the session is finished.
+func (s *Session) HandleInterrupts() {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	s.signals = sig
+
+	go func() {
+		if _, ok := <-sig; !ok {
+			return
+		}
+		s.Interrupt()
+		s.Out.Warn("Stopping, the scans in progress are being finished and the findings so far written out. Interrupt again to quit now.\n")
+		if _, ok := <-sig; ok {
+			os.Exit(InterruptedExitCode)
+		}
+	}()
+}
+
+// stopHandlingInterrupts will give SIGINT and SIGTERM back their default behavior once a session is finished
+func (s *Session) stopHandlingInterrupts() {
+	if s.signals == nil {
+		return
+	}
+	signal.Stop(s.signals)
+	close(s.signals)
+	s.signals = nil
+}
+
+// Interrupt will stop a scan from starting on anything new, what is already being scanned is finished
+func (s *Session) Interrupt() {
+	atomic.StoreInt32(&s.interrupted, 1)
+}
+
+// Interrupted is whether the scan of the session was stopped before it finished
+func (s *Session) Interrupted() bool {
+	return atomic.LoadInt32(&s.interrupted) == 1
+}
@@ -0,0 +1,50 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestInterrupt(t *testing.T) {
+
+	Convey("Given a scan that is sent SIGTERM", t, func() {
+		dir, err := ioutil.TempDir("", "wraith")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		sess := scanSession(dir)
+		sess.ScanType = "localGit"
+		sess.Threads = 1
+		sess.JSONOutput = filepath.Join(dir, "report.json")
+		name := "repo"
+		sess.Repositories = []*core.Repository{{Owner: &name, Name: &name, FullName: &name, CloneURL: &dir}}
+
+		sess.HandleInterrupts()
+		So(syscall.Kill(os.Getpid(), syscall.SIGTERM), ShouldBeNil)
+		for i := 0; i < 100 && !sess.Interrupted(); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		So(sess.Interrupted(), ShouldBeTrue)
+
+		Convey("No more repositories should be scanned and the report should still be written", func() {
+			core.AnalyzeRepositories(sess)
+			So(sess.Stats.RepositoriesCloned, ShouldEqual, 0)
+
+			sess.Finish()
+			So(sess.Stats.Status, ShouldEqual, core.StatusInterrupted)
+			So(sess.ExitCode(), ShouldEqual, core.InterruptedExitCode)
+			data, err := ioutil.ReadFile(sess.JSONOutput)
+			So(err, ShouldBeNil)
+			var report core.JSONReport
+			So(json.Unmarshal(data, &report), ShouldBeNil)
+			So(report.Interrupted, ShouldBeTrue)
+		})
+	})
+}
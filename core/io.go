@@ -83,13 +83,50 @@ func realTimeOutput(finding *Finding, sess *Session) {
 
 		sess.Out.Warn(" %s\n", strings.ToUpper(finding.Description))
 		sess.Out.Info("  SignatureID..........: %s\n", finding.Signatureid)
+		sess.Out.Info("  Severity.............: %s\n", FindingSeverity(finding))
+		sess.Out.Info("  Confidence...........: %s\n", FindingConfidence(finding))
 		sess.Out.Info("  Repo.................: %s\n", finding.RepositoryName)
 		sess.Out.Info("  File Path............: %s\n", finding.FilePath)
 		sess.Out.Info("  Line Number..........: %s\n", finding.LineNumber)
+		if finding.Encoding != "" {
+			sess.Out.Info("  Encoding.............: %s\n", finding.Encoding)
+		}
+		if jwt := finding.JWT; jwt != nil {
+			sess.Out.Info("  JWT Issuer...........: %s\n", jwt.Issuer)
+			sess.Out.Info("  JWT Subject..........: %s\n", jwt.Subject)
+			if len(jwt.Scopes) > 0 {
+				sess.Out.Info("  JWT Scopes...........: %s\n", strings.Join(jwt.Scopes, " "))
+			}
+			if jwt.Expired {
+				sess.Out.Info("  JWT Expires..........: %s (expired)\n", jwt.ExpiresAt)
+			} else if jwt.ExpiresAt != "" {
+				sess.Out.Info("  JWT Expires..........: %s\n", jwt.ExpiresAt)
+			} else {
+				sess.Out.Info("  JWT Expires..........: never\n")
+			}
+		}
+		if r := finding.Remediation; r != nil {
+			if r.RotationURL != "" {
+				sess.Out.Info("  Rotate At............: %s\n", r.RotationURL)
+			}
+			if r.Vault != "" {
+				sess.Out.Info("  Vaulting.............: %s\n", r.Vault)
+			}
+			if r.DocsURL != "" {
+				sess.Out.Info("  Remediation Docs.....: %s\n", r.DocsURL)
+			}
+		}
 		sess.Out.Info("  Message..............: %s\n", TruncateString(finding.CommitMessage, 100))
 		sess.Out.Info("  Commit Hash..........: %s\n", TruncateString(finding.CommitHash, 100))
 		sess.Out.Info("  Author...............: %s\n", finding.CommitAuthor)
+		if finding.CommitDate != "" {
+			sess.Out.Info("  Date.................: %s\n", finding.CommitDate)
+		}
+		if committer := finding.Committer(); committer != "" && committer != finding.CommitAuthor {
+			sess.Out.Info("  Committer............: %s\n", committer)
+		}
 		sess.Out.Info("  SecretID.............: %v\n", finding.SecretID)
+		sess.Out.Info("  Fingerprint..........: %v\n", finding.Fingerprint)
 		sess.Out.Info("  Wraith Version.......: %s\n", version.AppVersion())
 		sess.Out.Info("  Signatures Version...: %v\n", finding.SignaturesVersion)
 		if len(finding.Comment) > 0 {
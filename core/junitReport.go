@@ -0,0 +1,207 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// junitCleanCase is the name of the passing test case of a repository without findings, so a clean scan shows up as
+// passing rather than as a report without any tests, which ci systems such as jenkins fail on
+const junitCleanCase = "no secrets found"
+
+// JUnitReport is the document written by --junit, with a test suite per repository and a failed test case per
+// finding, so ci systems show the findings of a scan in their test report ui
+type JUnitReport struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite holds the findings of one repository
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a finding, or the passing case of a repository without findings
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitFailure describes the secret of a finding that has not been triaged away
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a finding that was triaged as a false positive or as remediated
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitSuiteName is the name of the test suite of a repository, findings outside of a repository are in a suite named
+// after the tool
+func junitSuiteName(owner, name string) string {
+	if owner != "" && owner != "not-a-repo" {
+		return owner + "/" + name
+	}
+	if name != "" && name != "not-a-repo" {
+		return name
+	}
+	return Name
+}
+
+// NewJUnitTestCase will convert a finding into a test case of a junit report. The secret is the comment of the
+// finding, which is redacted the same way as in every other output.
+func NewJUnitTestCase(f *Finding) JUnitTestCase {
+	location := f.FilePath
+	if location == "" {
+		// findings in a commit message or note have no file
+		location = strings.ToLower(f.Action)
+	}
+	if f.LineNumber != "" && f.LineNumber != "0" {
+		location += ":" + f.LineNumber
+	}
+
+	tc := JUnitTestCase{
+		Name:      fmt.Sprintf("%s %s", f.Signatureid, location),
+		ClassName: junitSuiteName(f.RepositoryOwner, f.RepositoryName),
+		Time:      "0",
+	}
+	if f.Triage != nil && (f.Triage.Status == TriageFalsePositive || f.Triage.Status == TriageRemediated) {
+		tc.Skipped = &JUnitSkipped{Message: "triaged as " + strings.Replace(f.Triage.Status, "_", " ", -1)}
+		return tc
+	}
+
+	var body strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&body, "%-12s %s\n", name+":", value)
+		}
+	}
+	field("Signature", f.Signatureid)
+	field("Severity", FindingSeverity(f))
+	field("Confidence", FindingConfidence(f))
+	field("File", f.FilePath)
+	field("Line", f.LineNumber)
+	field("Commit", f.CommitHash)
+	field("Author", f.CommitAuthor)
+	field("Date", f.CommitDate)
+	field("Encoding", f.Encoding)
+	field("Match", f.Comment)
+	field("Fingerprint", f.Fingerprint)
+	field("Url", f.FileUrl)
+	if len(f.Context) > 0 {
+		body.WriteString("\n")
+		for _, line := range f.Context {
+			fmt.Fprintf(&body, "%6d  %s\n", line.LineNumber, line.Content)
+		}
+	}
+
+	tc.Failure = &JUnitFailure{
+		Message: fmt.Sprintf("%s found in %s (%s)", f.Description, location, FindingSeverity(f)),
+		Type:    f.Signatureid,
+		Body:    body.String(),
+	}
+	return tc
+}
+
+// NewJUnitReport will build a junit report from the current state of the session. Every repository that was scanned
+// has a test suite, those without findings a single passing test case.
+func NewJUnitReport(s *Session) JUnitReport {
+	s.Stats.Lock()
+	timestamp := s.Stats.StartedAt.UTC().Format(gitlabReportTime)
+	elapsed := fmt.Sprintf("%.3f", s.Stats.FinishedAt.Sub(s.Stats.StartedAt).Seconds())
+	s.Stats.Unlock()
+
+	suites := make(map[string]*JUnitTestSuite)
+	suite := func(name string) *JUnitTestSuite {
+		if suites[name] == nil {
+			suites[name] = &JUnitTestSuite{Name: name, Time: "0", Timestamp: timestamp}
+		}
+		return suites[name]
+	}
+
+	s.Lock()
+	for _, f := range s.Findings {
+		tc := NewJUnitTestCase(f)
+		ts := suite(tc.ClassName)
+		ts.Cases = append(ts.Cases, tc)
+	}
+	for _, r := range s.Repositories {
+		if r.Name == nil {
+			continue
+		}
+		owner := ""
+		if r.Owner != nil {
+			owner = *r.Owner
+		}
+		suite(junitSuiteName(owner, *r.Name))
+	}
+	s.Unlock()
+	if len(suites) == 0 {
+		suite(Name)
+	}
+
+	report := JUnitReport{Name: Name, Time: elapsed}
+	var names []string
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ts := suites[name]
+		if len(ts.Cases) == 0 {
+			ts.Cases = []JUnitTestCase{{Name: junitCleanCase, ClassName: name, Time: "0"}}
+		}
+		for _, tc := range ts.Cases {
+			ts.Tests++
+			if tc.Failure != nil {
+				ts.Failures++
+			}
+			if tc.Skipped != nil {
+				ts.Skipped++
+			}
+		}
+		report.Tests += ts.Tests
+		report.Failures += ts.Failures
+		report.Skipped += ts.Skipped
+		report.Suites = append(report.Suites, *ts)
+	}
+	return report
+}
+
+// WriteJUnitReport will write the findings of the session as a junit xml report, encrypted like the other reports
+// when they are
+func WriteJUnitReport(location string, s *Session) error {
+	data, err := xml.MarshalIndent(NewJUnitReport(s), "", "  ")
+	if err != nil {
+		return err
+	}
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	if _, err := fh.Write(append([]byte(xml.Header), append(data, '\n')...)); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
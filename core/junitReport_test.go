@@ -0,0 +1,104 @@
+package core_test
+
+import (
+	"encoding/xml"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestJUnitReport(t *testing.T) {
+
+	Convey("Given a session with findings in two repositories and a repository without any", t, func() {
+		str := func(s string) *string { return &s }
+		startedAt := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			Stats: &core.Stats{StartedAt: startedAt, FinishedAt: startedAt.Add(90 * time.Second)},
+			Findings: []*core.Finding{
+				{RepositoryOwner: "acme", RepositoryName: "web", FilePath: "config/prod.env", LineNumber: "3", CommitHash: "1111111111",
+					CommitAuthor: "Jane <jane@example.com>", Signatureid: "aws-id", Description: "AWS Access Key ID",
+					Severity: core.SeverityCritical, Comment: "AKIA****************", Fingerprint: "abc",
+					Context: []core.ContextLine{{LineNumber: 2, Content: "REGION=eu-west-1"}, {LineNumber: 3, Content: "KEY=AKIA****************"}}},
+				{RepositoryOwner: "acme", RepositoryName: "api", Action: core.ActionCommitMessage, LineNumber: "1", CommitHash: "2222222222",
+					Signatureid: "slack-token", Description: "Slack Token", Score: 2, Fingerprint: "def"},
+				{RepositoryOwner: "acme", RepositoryName: "api", FilePath: "test/fixture.json", LineNumber: "7", Signatureid: "aws-id",
+					Description: "AWS Access Key ID", Fingerprint: "ghi", Triage: &core.Triage{Status: core.TriageFalsePositive}},
+			},
+			Repositories: []*core.Repository{
+				{Owner: str("acme"), Name: str("api")},
+				{Owner: str("acme"), Name: str("docs")},
+				{Owner: str("acme"), Name: str("web")},
+			},
+		}
+
+		dir, err := ioutil.TempDir("", "wraith-junit-report")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		location := filepath.Join(dir, "wraith-junit.xml")
+
+		Convey("There should be a suite per repository with a failed case per finding", func() {
+			So(core.WriteJUnitReport(location, sess), ShouldBeNil)
+			data, err := ioutil.ReadFile(location)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldStartWith, xml.Header)
+			var report core.JUnitReport
+			So(xml.Unmarshal(data, &report), ShouldBeNil)
+
+			So(report.Tests, ShouldEqual, 4)
+			So(report.Failures, ShouldEqual, 2)
+			So(report.Skipped, ShouldEqual, 1)
+			So(report.Time, ShouldEqual, "90.000")
+			So(report.Suites, ShouldHaveLength, 3)
+
+			api := report.Suites[0]
+			So(api.Name, ShouldEqual, "acme/api")
+			So(api.Timestamp, ShouldEqual, "2020-08-03T09:00:00")
+			So(api.Tests, ShouldEqual, 2)
+			So(api.Failures, ShouldEqual, 1)
+			So(api.Skipped, ShouldEqual, 1)
+			So(api.Cases[0].Name, ShouldEqual, "slack-token commit message:1")
+			So(api.Cases[0].Failure.Message, ShouldEqual, "Slack Token found in commit message:1 (low)")
+			So(api.Cases[1].Failure, ShouldBeNil)
+			So(api.Cases[1].Skipped.Message, ShouldEqual, "triaged as false positive")
+
+			docs := report.Suites[1]
+			So(docs.Name, ShouldEqual, "acme/docs")
+			So(docs.Tests, ShouldEqual, 1)
+			So(docs.Failures, ShouldEqual, 0)
+			So(docs.Cases[0].Name, ShouldEqual, "no secrets found")
+
+			web := report.Suites[2].Cases[0]
+			So(web.Name, ShouldEqual, "aws-id config/prod.env:3")
+			So(web.ClassName, ShouldEqual, "acme/web")
+			So(web.Failure.Type, ShouldEqual, "aws-id")
+			So(web.Failure.Message, ShouldEqual, "AWS Access Key ID found in config/prod.env:3 (critical)")
+			So(web.Failure.Body, ShouldContainSubstring, "Match:       AKIA****************\n")
+			So(web.Failure.Body, ShouldContainSubstring, "Author:      Jane <jane@example.com>\n")
+			So(web.Failure.Body, ShouldContainSubstring, "     3  KEY=AKIA****************\n")
+		})
+
+		Convey("A scan without findings or repositories should still have a passing case", func() {
+			sess.Findings = nil
+			sess.Repositories = nil
+			report := core.NewJUnitReport(sess)
+			So(report.Tests, ShouldEqual, 1)
+			So(report.Failures, ShouldEqual, 0)
+			So(report.Suites[0].Name, ShouldEqual, "wraith")
+		})
+
+		Convey("Characters that are not valid in xml should be escaped", func() {
+			sess.Findings[0].Comment = `<password>&"`
+			So(core.WriteJUnitReport(location, sess), ShouldBeNil)
+			data, _ := ioutil.ReadFile(location)
+			So(strings.Contains(string(data), "<password>"), ShouldBeFalse)
+			var report core.JUnitReport
+			So(xml.Unmarshal(data, &report), ShouldBeNil)
+			So(report.Suites[2].Cases[0].Failure.Body, ShouldContainSubstring, `<password>&"`)
+		})
+	})
+}
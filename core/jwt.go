@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// jwtPattern finds a json web token in a match, three base64url parts of which the header and payload are json
+// objects. The signature is empty for an unsigned token.
+var jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// JWT is what the claims of a json web token found in a finding say about it, so it can be triaged without decoding
+// it by hand. The times are in RFC3339 and are empty when the token does not have the claim.
+type JWT struct {
+	Algorithm string   `json:"algorithm"`
+	Issuer    string   `json:"issuer,omitempty"`
+	Subject   string   `json:"subject,omitempty"`
+	Audience  []string `json:"audience,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	IssuedAt  string   `json:"issued_at,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+	Expired   bool     `json:"expired"`
+}
+
+// decodeJWTPart will decode a part of a token into a json object. Padding is allowed though tokens should not have
+// it.
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwtStrings will read a claim that can be a single string or a list of them, as aud can. A string is split on
+// spaces when split is set, as the scope claim of oauth is.
+func jwtStrings(claim interface{}, split bool) []string {
+	switch v := claim.(type) {
+	case string:
+		if split {
+			return strings.Fields(v)
+		}
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// jwtTime will read a claim that is a time in seconds since the epoch
+func jwtTime(claim interface{}) (time.Time, bool) {
+	seconds, ok := claim.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0).UTC(), true
+}
+
+// ParseJWT will decode the json web token in a match, if there is one, without checking its signature. A token is
+// expired when its exp claim is before now.
+func ParseJWT(match string, now time.Time) *JWT {
+	token := jwtPattern.FindString(match)
+	if token == "" {
+		return nil
+	}
+	parts := strings.Split(token, ".")
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Algorithm == "" {
+		return nil
+	}
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil
+	}
+
+	jwt := &JWT{Algorithm: header.Algorithm, Audience: jwtStrings(claims["aud"], false)}
+	jwt.Issuer, _ = claims["iss"].(string)
+	jwt.Subject, _ = claims["sub"].(string)
+	// oauth puts the scopes in scope, azure ad in scp and others in scopes
+	for _, claim := range []string{"scope", "scp", "scopes"} {
+		if scopes := jwtStrings(claims[claim], true); len(scopes) > 0 {
+			jwt.Scopes = scopes
+			break
+		}
+	}
+	if issuedAt, ok := jwtTime(claims["iat"]); ok {
+		jwt.IssuedAt = issuedAt.Format(time.RFC3339)
+	}
+	if expiresAt, ok := jwtTime(claims["exp"]); ok {
+		jwt.ExpiresAt = expiresAt.Format(time.RFC3339)
+		jwt.Expired = expiresAt.Before(now)
+	}
+	return jwt
+}
+
+// analyzeJWT will decode the json web token in the secret of a finding. An expired token can no longer be used, so it
+// is rated low severity, before a finding script or policy sees the finding so they can rate it again.
+func (s *Session) analyzeJWT(f *Finding) {
+	if f.secret == "" {
+		return
+	}
+	f.JWT = ParseJWT(f.secret, time.Now())
+	if f.JWT != nil && f.JWT.Expired {
+		f.Severity = SeverityLow
+	}
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// These bound the keywords taken from the regex of a signature. A keyword shorter than KeywordMinLength is in too much
+// of the content to leave anything out, and a regex with more than KeywordMaxCount alternatives is run on everything.
+const (
+	KeywordMinLength = 3
+	KeywordMaxCount  = 32
+)
+
+// KeywordMatcher finds whether any of a set of keywords is in some content, ignoring the case of ascii letters. It is
+// an Aho-Corasick automaton, so the content is read once however many keywords there are. A nil matcher matches
+// everything, for signatures without keywords.
+type KeywordMatcher struct {
+	delta [][256]int32 // the state reached from each state on each byte
+	out   []bool       // whether a keyword ends at each state
+}
+
+// NewKeywordMatcher will build the matcher for the keywords, or nil when there are none
+func NewKeywordMatcher(keywords []string) *KeywordMatcher {
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	m := &KeywordMatcher{delta: make([][256]int32, 1), out: make([]bool, 1)}
+	for _, keyword := range keywords {
+		if keyword == "" {
+			return nil
+		}
+		state := int32(0)
+		for i := 0; i < len(keyword); i++ {
+			c := lowerASCII(keyword[i])
+			if m.delta[state][c] == 0 {
+				m.delta = append(m.delta, [256]int32{})
+				m.out = append(m.out, false)
+				m.delta[state][c] = int32(len(m.delta) - 1)
+			}
+			state = m.delta[state][c]
+		}
+		m.out[state] = true
+	}
+
+	// fill in the failure transitions breadth first, so a state falls back to the longest keyword prefix it ends with
+	fail := make([]int32, len(m.delta))
+	var queue []int32
+	for c := 0; c < 256; c++ {
+		if next := m.delta[0][c]; next != 0 {
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.out[state] = m.out[state] || m.out[fail[state]]
+		for c := 0; c < 256; c++ {
+			next := m.delta[state][c]
+			if next == 0 {
+				m.delta[state][c] = m.delta[fail[state]][c]
+				continue
+			}
+			fail[next] = m.delta[fail[state]][c]
+			queue = append(queue, next)
+		}
+	}
+	return m
+}
+
+// Match reports whether any of the keywords is in the content
+func (m *KeywordMatcher) Match(content []byte) bool {
+	if m == nil {
+		return true
+	}
+	state := int32(0)
+	for _, b := range content {
+		state = m.delta[state][lowerASCII(b)]
+		if m.out[state] {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchString reports whether any of the keywords is in the content
+func (m *KeywordMatcher) MatchString(content string) bool {
+	if m == nil {
+		return true
+	}
+	state := int32(0)
+	for i := 0; i < len(content); i++ {
+		state = m.delta[state][lowerASCII(content[i])]
+		if m.out[state] {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerASCII is the lower case of an ascii letter, other bytes are left as they are
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// RegexKeywords returns the literal strings at least one of which is in anything the regex matches, in lower case,
+// such as akia for AKIA[0-9A-Z]{16}. Content without any of them can not match, so the regex does not have to be run
+// on it. It returns nil when the regex can match without a literal of KeywordMinLength or more.
+func RegexKeywords(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	keywords := requiredLiterals(re.Simplify())
+	if len(keywords) == 0 || len(keywords) > KeywordMaxCount {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var unique []string
+	for _, keyword := range keywords {
+		// a non ascii letter could be matched in another case than the one the keyword is in
+		if len(keyword) < KeywordMinLength || strings.IndexFunc(keyword, func(r rune) bool { return r > 127 }) >= 0 {
+			return nil
+		}
+		if !seen[keyword] {
+			seen[keyword] = true
+			unique = append(unique, keyword)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// requiredLiterals returns the literals at least one of which is in every match of the regex, or nil when there are
+// none it has to match
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			literals := requiredLiterals(sub)
+			if literals == nil {
+				return nil
+			}
+			union = append(union, literals...)
+		}
+		return union
+	case syntax.OpConcat:
+		// literals next to each other, or to a class of a few characters such as [Aa], are joined into longer ones
+		var best, run []string
+		for _, sub := range re.Sub {
+			if expanded := expandLiterals(run, sub); expanded != nil {
+				run = expanded
+				continue
+			}
+			best = betterLiterals(best, run)
+			run = nil
+			best = betterLiterals(best, requiredLiterals(sub))
+		}
+		return betterLiterals(best, run)
+	}
+	return nil
+}
+
+// expandLiterals will append a literal, or each of the characters of a small class, to every string of a run, or
+// return nil when the regex is neither or there would be too many strings
+func expandLiterals(run []string, re *syntax.Regexp) []string {
+	var suffixes []string
+	switch re.Op {
+	case syntax.OpLiteral:
+		suffixes = []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCharClass:
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(seen) > 4 {
+					return nil
+				}
+				seen[strings.ToLower(string(r))] = true
+			}
+		}
+		for suffix := range seen {
+			suffixes = append(suffixes, suffix)
+		}
+	default:
+		return nil
+	}
+	if len(run) == 0 {
+		run = []string{""}
+	}
+	if len(run)*len(suffixes) > KeywordMaxCount {
+		return nil
+	}
+	var expanded []string
+	for _, prefix := range run {
+		for _, suffix := range suffixes {
+			expanded = append(expanded, prefix+suffix)
+		}
+	}
+	return expanded
+}
+
+// betterLiterals returns whichever set of literals leaves out more content, the one whose shortest literal is longest
+func betterLiterals(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	shortest := func(literals []string) int {
+		n := len(literals[0])
+		for _, l := range literals[1:] {
+			if len(l) < n {
+				n = len(l)
+			}
+		}
+		return n
+	}
+	if shortest(b) > shortest(a) || (shortest(b) == shortest(a) && len(b) < len(a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// These are the actions of findings made in the values of kubernetes objects
+const (
+	ActionKubeSecret    = "Kubernetes Secret"
+	ActionKubeConfigMap = "Kubernetes ConfigMap"
+)
+
+// These are the types of secrets that are handled differently from the rest. Service account tokens are made by the
+// cluster itself so they are left out, and helm keeps each release gzipped inside of the secret.
+const (
+	KubeServiceAccountTokenType = "kubernetes.io/service-account-token"
+	KubeHelmReleaseType         = "helm.sh/release.v1"
+)
+
+// kubeListLimit is how many objects are asked for in each page of a list
+const kubeListLimit = 500
+
+// KubeSecret is the subset of a kubernetes secret that is scanned, the values of data are decoded from base64
+type KubeSecret struct {
+	Metadata KubeObjectMeta    `json:"metadata"`
+	Type     string            `json:"type"`
+	Data     map[string][]byte `json:"data"`
+}
+
+// KubeConfigMap is the subset of a kubernetes config map that is scanned
+type KubeConfigMap struct {
+	Metadata   KubeObjectMeta    `json:"metadata"`
+	Data       map[string]string `json:"data"`
+	BinaryData map[string][]byte `json:"binaryData"`
+}
+
+// kubeListMeta is the metadata of a page of a list, continue is set when there are more pages
+type kubeListMeta struct {
+	Continue string `json:"continue"`
+}
+
+// kubeListPath is the path of a list of core objects in a namespace, or in every namespace if it is empty
+func kubeListPath(namespace, resource, labelSelector, cont string) string {
+	path := "/api/v1"
+	if namespace != "" {
+		path += "/namespaces/" + url.PathEscape(namespace)
+	}
+	query := url.Values{"limit": {fmt.Sprint(kubeListLimit)}}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	if cont != "" {
+		query.Set("continue", cont)
+	}
+	return path + "/" + resource + "?" + query.Encode()
+}
+
+// ListSecrets will list the secrets in a namespace, or in every namespace if it is empty, that match a label selector
+func (k *KubeClient) ListSecrets(namespace, labelSelector string) ([]KubeSecret, error) {
+	var secrets []KubeSecret
+	cont := ""
+	for {
+		var list struct {
+			Metadata kubeListMeta `json:"metadata"`
+			Items    []KubeSecret `json:"items"`
+		}
+		if err := k.do("GET", kubeListPath(namespace, "secrets", labelSelector, cont), nil, &list); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, list.Items...)
+		if cont = list.Metadata.Continue; cont == "" {
+			return secrets, nil
+		}
+	}
+}
+
+// ListConfigMaps will list the config maps in a namespace, or in every namespace if it is empty, that match a label
+// selector
+func (k *KubeClient) ListConfigMaps(namespace, labelSelector string) ([]KubeConfigMap, error) {
+	var configMaps []KubeConfigMap
+	cont := ""
+	for {
+		var list struct {
+			Metadata kubeListMeta    `json:"metadata"`
+			Items    []KubeConfigMap `json:"items"`
+		}
+		if err := k.do("GET", kubeListPath(namespace, "configmaps", labelSelector, cont), nil, &list); err != nil {
+			return nil, err
+		}
+		configMaps = append(configMaps, list.Items...)
+		if cont = list.Metadata.Continue; cont == "" {
+			return configMaps, nil
+		}
+	}
+}
+
+// kubeValue is a value of a secret or config map, found at a path such as secrets/api-keys/AWS_SECRET_ACCESS_KEY
+type kubeValue struct {
+	action          string
+	path            string
+	key             string
+	resourceVersion string
+	content         []byte
+}
+
+// kubeSecretValues are the values of a secret that are scanned, with helm releases unpacked so the values the charts
+// were installed with are scanned rather than the gzip they are kept in
+func kubeSecretValues(secret KubeSecret) []kubeValue {
+	if secret.Type == KubeServiceAccountTokenType {
+		return nil
+	}
+	var values []kubeValue
+	for key, content := range secret.Data {
+		if secret.Type == KubeHelmReleaseType && key == "release" {
+			if release, err := decodeHelmRelease(content); err == nil {
+				key, content = "release.json", release
+			}
+		}
+		values = append(values, kubeValue{
+			action:          ActionKubeSecret,
+			path:            fmt.Sprintf("secrets/%s/%s", secret.Metadata.Name, key),
+			key:             key,
+			resourceVersion: secret.Metadata.ResourceVersion,
+			content:         content,
+		})
+	}
+	return values
+}
+
+// kubeConfigMapValues are the values of a config map, text and binary alike
+func kubeConfigMapValues(configMap KubeConfigMap) []kubeValue {
+	var values []kubeValue
+	add := func(key string, content []byte) {
+		values = append(values, kubeValue{
+			action:          ActionKubeConfigMap,
+			path:            fmt.Sprintf("configmaps/%s/%s", configMap.Metadata.Name, key),
+			key:             key,
+			resourceVersion: configMap.Metadata.ResourceVersion,
+			content:         content,
+		})
+	}
+	for key, content := range configMap.Data {
+		add(key, []byte(content))
+	}
+	for key, content := range configMap.BinaryData {
+		add(key, content)
+	}
+	return values
+}
+
+// decodeHelmRelease will unpack the release helm keeps in a secret, which is gzipped json encoded as base64 once more
+// on top of the encoding of the secret itself
+func decodeHelmRelease(content []byte) ([]byte, error) {
+	gz, err := base64.StdEncoding.DecodeString(string(content))
+	if err != nil {
+		return nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// newKubeScanClient will connect to the cluster of a scan through the api url when one is given, otherwise with a
+// kubeconfig file, falling back to the service account of the pod wraith is running in
+func newKubeScanClient(sess *Session) (*KubeClient, error) {
+	if sess.KubeAPI != "" {
+		return NewKubeClient(sess.KubeAPI)
+	}
+	if config := KubeConfigPath(sess.KubeConfig); config != "" {
+		return NewKubeClientFromConfig(config, sess.KubeContext)
+	}
+	return NewKubeClient("")
+}
+
+// ScanKubernetes will scan the values of the secrets and config maps in the namespaces of the session, or every
+// namespace when none are given. Each namespace is scanned as a repository of the cluster.
+func ScanKubernetes(sess *Session) error {
+	client, err := newKubeScanClient(sess)
+	if err != nil {
+		return fmt.Errorf("unable to connect to kubernetes: %s", err)
+	}
+
+	namespaces := sess.KubeNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	sess.SetStatus(StatusGathering)
+	values := map[string][]kubeValue{}
+	listed := 0
+	for _, namespace := range namespaces {
+		where := namespace
+		if where == "" {
+			where = "all namespaces"
+		}
+		sess.Out.Important("Listing the secrets and config maps in %s of %s\n", where, client.Cluster)
+		sess.Stats.IncrementTargets()
+
+		var secrets []KubeSecret
+		_, err := sess.withRetries(sess.Out, "list the secrets in "+where, func() (err error) {
+			secrets, err = client.ListSecrets(namespace, sess.KubeLabelSelector)
+			return err
+		})
+		if err != nil {
+			sess.Out.Error("Unable to list the secrets in %s: %s\n", where, err)
+		} else {
+			listed++
+		}
+		for _, secret := range secrets {
+			values[secret.Metadata.Namespace] = append(values[secret.Metadata.Namespace], kubeSecretValues(secret)...)
+		}
+
+		var configMaps []KubeConfigMap
+		_, err = sess.withRetries(sess.Out, "list the config maps in "+where, func() (err error) {
+			configMaps, err = client.ListConfigMaps(namespace, sess.KubeLabelSelector)
+			return err
+		})
+		if err != nil {
+			sess.Out.Error("Unable to list the config maps in %s: %s\n", where, err)
+		} else {
+			listed++
+		}
+		for _, configMap := range configMaps {
+			values[configMap.Metadata.Namespace] = append(values[configMap.Metadata.Namespace], kubeConfigMapValues(configMap)...)
+		}
+	}
+	if listed == 0 {
+		return errors.New("no secrets or config maps could be listed")
+	}
+
+	dir, err := ioutil.TempDir("", "wraith-kubernetes")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	sess.SetStatus(StatusAnalyzing)
+	var names []string
+	for namespace := range values {
+		names = append(names, namespace)
+	}
+	sort.Strings(names)
+	for _, namespace := range names {
+		if sess.Interrupted() {
+			break
+		}
+		sess.Stats.IncrementRepositoriesTotal()
+		scanKubeNamespace(client.Cluster, namespace, values[namespace], dir, sess)
+		sess.Stats.IncrementRepositoriesScanned()
+	}
+	return nil
+}
+
+// scanKubeNamespace will write each value of the objects in a namespace to a file and scan it
+func scanKubeNamespace(cluster, namespace string, values []kubeValue, dir string, sess *Session) {
+	sort.Slice(values, func(i, j int) bool { return values[i].path < values[j].path })
+	maxSize := sess.MaxFileSize * 1024 * 1024
+	for i, value := range values {
+		if sess.Interrupted() {
+			return
+		}
+		sess.Stats.IncrementFilesTotal()
+
+		matchFile := newMatchFile(value.key)
+		if matchFile.isSkippable(sess) {
+			sess.Out.Debug("%s is listed as skippable and is being ignored\n", value.path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if int64(len(value.content)) > maxSize {
+			sess.Out.Debug("%s is too large and being ignored\n", value.path)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		// the value is saved under its key so the signatures that match on file names still work
+		fullFilePath := filepath.Join(dir, fmt.Sprint(i), filepath.Base(value.key))
+		if err := os.MkdirAll(filepath.Dir(fullFilePath), 0700); err != nil {
+			sess.Out.Error("Unable to scan %s in %s: %s\n", value.path, namespace, err)
+			continue
+		}
+		if err := ioutil.WriteFile(fullFilePath, value.content, 0600); err != nil {
+			sess.Out.Error("Unable to scan %s in %s: %s\n", value.path, namespace, err)
+			continue
+		}
+		scanFile(fullFilePath, Finding{
+			Action:          value.action,
+			AtHead:          true,
+			FilePath:        value.path,
+			RepositoryName:  namespace,
+			RepositoryOwner: cluster,
+			CommitHash:      value.resourceVersion,
+		}, sess)
+		os.RemoveAll(filepath.Dir(fullFilePath))
+	}
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// KubeConfigEnv is the environment variable kubectl reads the kubeconfig files from
+const KubeConfigEnv = "KUBECONFIG"
+
+// kubeConfig is the subset of a kubeconfig file needed to connect to a cluster
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string       `yaml:"name"`
+		User kubeAuthInfo `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeAuthInfo is how a user of a kubeconfig file authenticates
+type kubeAuthInfo struct {
+	Token                 string `yaml:"token"`
+	TokenFile             string `yaml:"tokenFile"`
+	ClientCertificate     string `yaml:"client-certificate"`
+	ClientCertificateData string `yaml:"client-certificate-data"`
+	ClientKey             string `yaml:"client-key"`
+	ClientKeyData         string `yaml:"client-key-data"`
+	Exec                  *struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Command    string   `yaml:"command"`
+		Args       []string `yaml:"args"`
+		Env        []struct {
+			Name  string `yaml:"name"`
+			Value string `yaml:"value"`
+		} `yaml:"env"`
+	} `yaml:"exec"`
+	AuthProvider *struct {
+		Name string `yaml:"name"`
+	} `yaml:"auth-provider"`
+}
+
+// KubeConfigPath is the kubeconfig file to connect with, the one given, the first in KUBECONFIG that exists, or
+// ~/.kube/config. It is empty when there is none, such as inside of a pod.
+func KubeConfigPath(file string) string {
+	if file != "" {
+		return SetHomeDir(file)
+	}
+	candidates := filepath.SplitList(os.Getenv(KubeConfigEnv))
+	candidates = append(candidates, SetHomeDir("$HOME/.kube/config"))
+	for _, candidate := range candidates {
+		if candidate != "" && FileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// NewKubeClientFromConfig will create a client for a context of a kubeconfig file, or its current context when none is
+// given. Exec plugins, such as the ones of EKS and GKE, are run once for a token, auth providers are not supported.
+func NewKubeClientFromConfig(file, context string) (*KubeClient, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config kubeConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", file, err)
+	}
+	// the files named in a kubeconfig are relative to it
+	dir := filepath.Dir(file)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	if context == "" {
+		context = config.CurrentContext
+	}
+	if context == "" {
+		return nil, fmt.Errorf("%s has no current-context, one must be given", file)
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range config.Contexts {
+		if c.Name == context {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("there is no context %s in %s", context, file)
+	}
+
+	client := &KubeClient{Cluster: clusterName}
+	tlsConfig := &tls.Config{}
+	found = false
+	for _, c := range config.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		client.Host = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := kubeConfigData(c.Cluster.CertificateAuthorityData, resolve(c.Cluster.CertificateAuthority))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the certificate authority of %s: %s", clusterName, err)
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("unable to load the certificate authority of %s", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !found || client.Host == "" {
+		return nil, fmt.Errorf("there is no server for the cluster %s in %s", clusterName, file)
+	}
+
+	for _, u := range config.Users {
+		if u.Name != userName {
+			continue
+		}
+		token, cert, key, err := kubeUserCredential(u.User, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the credentials of %s: %s", userName, err)
+		}
+		client.Token = token
+		if cert != nil && key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load the client certificate of %s: %s", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	client.client = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{Proxy: HTTPProxy(), TLSClientConfig: tlsConfig},
+	}
+	return client, nil
+}
+
+// kubeUserCredential is the token, or the pem client certificate and key, a user of a kubeconfig authenticates with
+func kubeUserCredential(auth kubeAuthInfo, resolve func(string) string) (string, []byte, []byte, error) {
+	if auth.AuthProvider != nil {
+		return "", nil, nil, fmt.Errorf("the %s auth provider is not supported, use an exec plugin or a token", auth.AuthProvider.Name)
+	}
+	if auth.Exec != nil {
+		return kubeExecCredential(auth)
+	}
+
+	token := auth.Token
+	if token == "" && auth.TokenFile != "" {
+		data, err := ioutil.ReadFile(resolve(auth.TokenFile))
+		if err != nil {
+			return "", nil, nil, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	cert, err := kubeConfigData(auth.ClientCertificateData, resolve(auth.ClientCertificate))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	key, err := kubeConfigData(auth.ClientKeyData, resolve(auth.ClientKey))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return token, cert, key, nil
+}
+
+// kubeConfigData is the content of a field of a kubeconfig that is either base64 data or a file, nil when it is neither
+func kubeConfigData(data, file string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// kubeExecCredential will run the exec plugin of a user, which prints an ExecCredential with a token or a pem client
+// certificate and key to use
+func kubeExecCredential(auth kubeAuthInfo) (string, []byte, []byte, error) {
+	info, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": auth.Exec.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	cmd := exec.Command(auth.Exec.Command, auth.Exec.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range auth.Exec.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%s: %s", err, msg)
+		}
+		return "", nil, nil, fmt.Errorf("%s failed: %s", auth.Exec.Command, err)
+	}
+
+	var credential struct {
+		Status struct {
+			Token                 string `json:"token"`
+			ClientCertificateData string `json:"clientCertificateData"`
+			ClientKeyData         string `json:"clientKeyData"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &credential); err != nil {
+		return "", nil, nil, fmt.Errorf("%s did not print an ExecCredential: %s", auth.Exec.Command, err)
+	}
+	status := credential.Status
+	if status.Token == "" && status.ClientCertificateData == "" {
+		return "", nil, nil, fmt.Errorf("%s printed neither a token nor a client certificate", auth.Exec.Command)
+	}
+	var cert, key []byte
+	if status.ClientCertificateData != "" {
+		cert, key = []byte(status.ClientCertificateData), []byte(status.ClientKeyData)
+	}
+	return status.Token, cert, key, nil
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// These are the locations a pod finds its service account credentials and the api server at
+const (
+	KubeServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	KubeHostEnv           = "KUBERNETES_SERVICE_HOST"
+	KubePortEnv           = "KUBERNETES_SERVICE_PORT"
+)
+
+// KubeInCluster is the name of the cluster wraith is running in when it connects with its service account
+const KubeInCluster = "in-cluster"
+
+// KubeClient is a minimal client for the kubernetes api, it only covers what the operator and scanKubernetes need
+type KubeClient struct {
+	Host    string
+	Token   string
+	Cluster string // the name of the cluster, which the findings of scanKubernetes are reported under
+	client  *http.Client
+}
+
+// KubeError is a non successful response from the kubernetes api
+type KubeError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *KubeError) Error() string {
+	return fmt.Sprintf("kubernetes api returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsKubeNotFound will check if an error is a 404 from the kubernetes api
+func IsKubeNotFound(err error) bool {
+	var ke *KubeError
+	return errors.As(err, &ke) && ke.StatusCode == http.StatusNotFound
+}
+
+// IsKubeConflict will check if an error is a 409 from the kubernetes api, which is returned when an object already
+// exists or has been changed since it was read
+func IsKubeConflict(err error) bool {
+	var ke *KubeError
+	return errors.As(err, &ke) && ke.StatusCode == http.StatusConflict
+}
+
+// NewKubeClient will create a client for the given api url, such as the one served by `kubectl proxy`. If the url is
+// empty the service account of the pod wraith is running in is used.
+func NewKubeClient(apiURL string) (*KubeClient, error) {
+	if apiURL != "" {
+		cluster := apiURL
+		if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+			cluster = u.Host
+		}
+		return &KubeClient{
+			Host:    strings.TrimSuffix(apiURL, "/"),
+			Cluster: cluster,
+			client:  NewHTTPClient(30 * time.Second),
+		}, nil
+	}
+
+	host, port := os.Getenv(KubeHostEnv), os.Getenv(KubePortEnv)
+	if host == "" || port == "" {
+		return nil, errors.New("not running inside of kubernetes, an api url must be given")
+	}
+
+	token, err := ioutil.ReadFile(KubeServiceAccountDir + "/token")
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(KubeServiceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("unable to load the service account ca certificate")
+	}
+
+	return &KubeClient{
+		Host:    "https://" + net.JoinHostPort(host, port),
+		Token:   strings.TrimSpace(string(token)),
+		Cluster: KubeInCluster,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{Proxy: HTTPProxy(), TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+// KubeNamespace returns the namespace of the pod wraith is running in, or default outside of kubernetes
+func KubeNamespace() string {
+	ns, err := ioutil.ReadFile(KubeServiceAccountDir + "/namespace")
+	if err != nil {
+		return "default"
+	}
+	return strings.TrimSpace(string(ns))
+}
+
+// do will send a json request to the api and decode the response into out
+func (k *KubeClient) do(method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, k.Host+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if k.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.Token)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &status) != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(data))
+		}
+		return &KubeError{StatusCode: resp.StatusCode, Message: status.Message}
+	}
+
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"wraith/rpc"
+
+	"github.com/spf13/viper"
+)
+
+// Options configure a scan run from go code by Scan, in place of the flags and config file of the commands. Every
+// setting that does not have a field of its own can be given in Settings by the name of its flag, such as
+// "scan-issues" or "max-file-size".
+type Options struct {
+	ScanType       string   // the scan type of the command, such as github, gitlab, localGit or localPath
+	Targets        []string // the users, organizations, images, buckets, namespaces or directories to scan
+	Token          string   // the api token of the provider, when it has one
+	SignatureFiles []string // the signature files and directories to load, the default signatures when empty
+	MatchLevel     int      // the signatures below this match level are left out, the default when 0
+	Threads        int      // the number of repositories scanned at once, the number of cpus when 0
+	CommitDepth    int      // how many commits of each repository are scanned, all of them when 0
+	Redact         string   // how secrets are redacted in the findings, none when empty
+	Settings       map[string]interface{}
+
+	// Log receives the log of the scan, in LogFormat. It is thrown away when nil.
+	Log       io.Writer
+	LogFormat string
+
+	// OnFinding is called with each finding as it is found, from the thread that found it
+	OnFinding func(*Finding)
+}
+
+// errFatal is what a session made by NewScanSession panics with in place of exiting, it is recovered into an error
+type errFatal struct {
+	code int
+}
+
+// config will build the configuration of a scan from the options. It starts from the defaults rather than the config
+// file and environment of the user, so a program that embeds wraith only scans the way it was asked to.
+func (opts Options) config() (*viper.Viper, error) {
+	base := viper.New()
+	for key, value := range DefaultValues {
+		base.Set(key, value)
+	}
+	for key, value := range opts.Settings {
+		base.Set(key, value)
+	}
+	if len(opts.SignatureFiles) > 0 {
+		base.Set("signature-file", strings.Join(opts.SignatureFiles, ","))
+	}
+	if opts.MatchLevel > 0 {
+		base.Set("match-level", opts.MatchLevel)
+	}
+	if opts.Threads > 0 {
+		base.Set("num-threads", opts.Threads)
+	}
+	if opts.LogFormat != "" {
+		base.Set("log-format", opts.LogFormat)
+	}
+	commitDepth := opts.CommitDepth
+	if commitDepth == 0 {
+		commitDepth = base.GetInt("commit-depth")
+	}
+	redact := opts.Redact
+	if redact == "" {
+		redact = base.GetString("redact")
+	}
+
+	return NewScanConfig(base, &rpc.SubmitScanRequest{
+		ScanType:     opts.ScanType,
+		Targets:      opts.Targets,
+		ApiToken:     opts.Token,
+		CommitDepth:  int32(commitDepth),
+		HideSecrets:  base.GetBool("hide-secrets"),
+		ScanTests:    base.GetBool("scan-tests"),
+		NoExpandOrgs: base.GetBool("no-expand-orgs"),
+		Redact:       redact,
+	})
+}
+
+// NewScanSession will create a session from options, ready to be scanned with RunScan or step by step with
+// GatherScanRepositories and AnalyzeRepositories. Settings that the commands would exit on are returned as an error.
+// The signatures are shared by every session in the process, so sessions that are scanned at the same time should
+// load the same ones.
+func NewScanSession(opts Options) (sess *Session, err error) {
+	v, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		LogWriter: opts.Log,
+		OnFinding: opts.OnFinding,
+		exit:      func(code int) { panic(errFatal{code: code}) },
+	}
+	if s.LogWriter == nil {
+		s.LogWriter = ioutil.Discard
+	}
+
+	defer recoverFatal(s, &err)
+	s.Initialize(v, opts.ScanType)
+	return s, nil
+}
+
+// recoverFatal will recover the panic of a fatal message of a session made by NewScanSession into err. It must be
+// deferred itself rather than called from a deferred func, for recover to work, and any other panic is passed on.
+func recoverFatal(s *Session, err *error) {
+	if r := recover(); r != nil {
+		if _, ok := r.(errFatal); !ok {
+			panic(r)
+		}
+		*err = errors.New(s.Out.LastError())
+	}
+}
+
+// Scan will run a scan from go code and return its session, with the findings, repositories and stats of the scan,
+// once it has finished. Reports, webhooks and the other outputs given in the settings are written out at the end as
+// they are by the commands. Cancelling ctx stops the scan the same way as an interrupt, finishing what is already
+// being scanned, and returns the session along with the error of ctx. A setting or stage of the scan that the commands
+// would exit on is returned as an error along with the session.
+func Scan(ctx context.Context, opts Options) (sess *Session, err error) {
+	sess, err = NewScanSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer recoverFatal(sess, &err)
+
+	if ctx.Err() != nil {
+		sess.Interrupt()
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sess.Interrupt()
+		case <-done:
+		}
+	}()
+
+	err = RunScan(sess)
+	sess.Finish()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return sess, err
+}
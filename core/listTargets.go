@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ListTargets will gather the targets and repositories of a scan the same way the scan would, after every filter, and
+// write what would be scanned to w along with how large each repository is. Nothing is cloned or scanned.
+func ListTargets(sess *Session, w io.Writer) error {
+	if err := GatherScanRepositories(sess); err != nil {
+		return err
+	}
+	WriteTargetList(sess, w)
+	return nil
+}
+
+// WriteTargetList will write the targets and repositories gathered for a session, with the size of each repository
+// when it is known and the total of those that are
+func WriteTargetList(sess *Session, w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if len(sess.Targets) > 0 {
+		targets := append([]*Owner(nil), sess.Targets...)
+		sort.SliceStable(targets, func(i, j int) bool { return *targets[i].Login < *targets[j].Login })
+		fmt.Fprintln(tw, "TARGET\tTYPE")
+		for _, t := range targets {
+			kind := ""
+			if t.Type != nil {
+				kind = strings.ToLower(*t.Type)
+			}
+			if t.member {
+				kind += ", member"
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", *t.Login, kind)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	repos := append([]*Repository(nil), sess.Repositories...)
+	sort.SliceStable(repos, func(i, j int) bool { return *repos[i].FullName < *repos[j].FullName })
+	fmt.Fprintln(tw, "REPOSITORY\tSIZE\tBRANCH\tNOTES")
+	var total int64
+	unknown := 0
+	for _, repo := range repos {
+		size := "unknown"
+		if s := repositorySize(sess, repo); s != nil {
+			size = FormatSize(*s)
+			total += *s
+		} else {
+			unknown++
+		}
+		branch := ""
+		if repo.DefaultBranch != nil {
+			branch = *repo.DefaultBranch
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", *repo.FullName, size, branch, strings.Join(repositoryNotes(repo), ", "))
+	}
+	tw.Flush()
+
+	summary := fmt.Sprintf("\n%d %s", len(repos), Pluralize(len(repos), "repository", "repositories"))
+	if len(sess.Targets) > 0 {
+		summary += fmt.Sprintf(" in %d %s", len(sess.Targets), Pluralize(len(sess.Targets), "target", "targets"))
+	}
+	summary += fmt.Sprintf(", %s in total", FormatSize(total))
+	if unknown > 0 {
+		summary += fmt.Sprintf(", the size of %d is not known", unknown)
+	}
+	fmt.Fprintln(w, summary)
+}
+
+// repositoryNotes are what sets a repository apart from the rest in a list of targets
+func repositoryNotes(repo *Repository) []string {
+	var notes []string
+	if repo.gist {
+		notes = append(notes, "gist")
+	}
+	if repo.Visibility != nil && *repo.Visibility != "" {
+		notes = append(notes, strings.ToLower(*repo.Visibility))
+	}
+	if repo.Fork != nil && *repo.Fork {
+		notes = append(notes, "fork")
+	}
+	if repo.Archived != nil && *repo.Archived {
+		notes = append(notes, "archived")
+	}
+	return notes
+}
+
+// FormatSize is a size in bytes the way a person would write it, such as 12.5 MB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,94 @@
+package core_test
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+func TestListTargets(t *testing.T) {
+
+	Convey("Given the targets and repositories gathered for a scan", t, func() {
+		str := func(s string) *string { return &s }
+		size := func(n int64) *int64 { return &n }
+		yes := true
+		sess := &core.Session{
+			Stats: &core.Stats{},
+			Out:   &core.Logger{},
+			Targets: []*core.Owner{
+				{Login: str("acme"), Type: str("Organization")},
+				{Login: str("jane"), Type: str("User")},
+			},
+			Repositories: []*core.Repository{
+				{FullName: str("acme/web"), DefaultBranch: str("main"), Size: size(3 * 1024 * 1024), Archived: &yes},
+				{FullName: str("acme/api"), DefaultBranch: str("master"), Size: size(1536), Visibility: str("private")},
+				{FullName: str("jane/dotfiles"), DefaultBranch: str("main"), Fork: &yes},
+			},
+		}
+		sess.ScanType = "github"
+
+		Convey("Each should be listed with its size and the sizes that are known added up", func() {
+			var out bytes.Buffer
+			core.WriteTargetList(sess, &out)
+			So(out.String(), ShouldEqual, `TARGET  TYPE
+acme    organization
+jane    user
+
+REPOSITORY     SIZE     BRANCH  NOTES
+acme/api       1.5 KB   master  private
+acme/web       3.0 MB   main    archived
+jane/dotfiles  unknown  main    fork
+
+3 repositories in 2 targets, 3.0 MB in total, the size of 1 is not known
+`)
+		})
+	})
+
+	Convey("Given a list of repositories to scan", t, func() {
+		dir := gitRepo(t)
+		defer os.RemoveAll(dir)
+		listFile := filepath.Join(dir, "repos.txt")
+		ioutil.WriteFile(listFile, []byte(dir+" branch=main\n"), 0600)
+
+		sess := scanSession(dir)
+		sess.ScanType = "repoList"
+		sess.InputFile = listFile
+
+		Convey("The repositories should be listed without cloning them", func() {
+			var out bytes.Buffer
+			So(core.ListTargets(sess, &out), ShouldBeNil)
+			So(out.String(), ShouldContainSubstring, filepath.Base(dir)+"  unknown  main")
+			So(sess.Stats.RepositoriesCloned, ShouldEqual, 0)
+			So(sess.Findings, ShouldBeEmpty)
+		})
+
+		Convey("A scan that does not gather repositories should not be listed", func() {
+			sess.ScanType = "s3"
+			So(core.ListTargets(sess, &bytes.Buffer{}), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Listing the targets with a database should not add a session to it", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-list")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		dbPath := filepath.Join(dir, "wraith.db")
+
+		sess, err := core.NewScanSession(core.Options{ScanType: "localGit", Targets: []string{dir},
+			Settings: map[string]interface{}{"list-targets": true, "db-path": dbPath}})
+		So(err, ShouldBeNil)
+		So(sess.DB, ShouldBeNil)
+		_, err = os.Stat(dbPath)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+
+	Convey("Sizes should be written in the largest unit they make at least one of", t, func() {
+		So(core.FormatSize(512), ShouldEqual, "512 B")
+		So(core.FormatSize(2048), ShouldEqual, "2.0 KB")
+		So(core.FormatSize(5*1024*1024*1024+512*1024*1024), ShouldEqual, "5.5 GB")
+	})
+}
@@ -34,9 +34,19 @@ func Search(ctx context.Context, root string, skippablePath []string, sess *Sess
 			if os.IsPermission(err) {
 				return nil
 			}
+
+			// --path-include and --path-exclude are relative to the directory being scanned, and a directory that is
+			// excluded is not walked at all
+			rel, relErr := filepath.Rel(root, path)
+			if relErr == nil && fi.IsDir() && sess.PathFilter.skipsDir(rel) {
+				return filepath.SkipDir
+			}
 			if !fi.Mode().IsRegular() {
 				return nil
 			}
+			if relErr == nil && !sess.PathFilter.Scans(rel) {
+				return nil
+			}
 
 			select {
 			case paths <- path:
@@ -84,6 +94,12 @@ func DoFileScan(filename string, sess *Session) {
 		return
 	}
 
+	// Archives are opened and their files scanned instead, rather than matching against the compressed bytes
+	if sess.ArchiveDepth > 0 && archiveKind(filename) != "" {
+		ScanArchive(filename, sess)
+		return
+	}
+
 	if IsMaxFileSize(filename, sess) {
 		sess.Out.Debug("%s is too large and being ignored\n", filename)
 
@@ -91,16 +107,52 @@ func DoFileScan(filename string, sess *Session) {
 		return
 	}
 
+	scanFile(filename, localFileFinding(filename), sess)
+}
+
+// localFileFinding is the base of the findings in a file that is not part of a repository, reported at location
+func localFileFinding(location string) Finding {
+	return Finding{
+		Action:          `File Scan`,
+		AtHead:          true,
+		FilePath:        location,
+		RepositoryName:  `not-a-repo`,
+		RepositoryOwner: `not-a-repo`,
+	}
+}
+
+// scanFile will match a file on disk against the signatures and detector plugins. Findings are copied from base,
+// which holds where the file is reported to be, as that is not always where it is on disk, and what it belongs to.
+func scanFile(fullFilePath string, base Finding, sess *Session) {
+	matchFile := newMatchFile(fullFilePath)
+	location := base.FilePath
+
 	if sess.Debug {
 		// Print the filename of every file being scanned
-		sess.Out.Debug("Analyzing %s\n", filename)
+		sess.Out.Debug("Analyzing %s\n", location)
 	}
 
 	// Increment the number of files scanned
 	sess.Stats.IncrementFilesScanned()
 
+	// the content signatures are matched against the strings in a binary file rather than its raw bytes
+	binary := false
+	if sess.ScanBinaries {
+		content := pluginContent(fullFilePath, nil)
+		if binary = isBinaryContent(content); binary {
+			for _, finding := range scanBinaryStrings(sess, content, "", base) {
+				if sess.AddFinding(finding) {
+					realTimeOutput(finding, sess)
+				}
+			}
+		}
+	}
+
 	// Scan the file for know signatures
-	for _, signature := range Signatures {
+	for _, signature := range CurrentSignatures() {
+		if binary && signature.Part() == PartContent {
+			continue
+		}
 		bMatched, matchMap := signature.ExtractMatch(matchFile, sess, nil)
 
 		var content string   // this is because file matches are puking
@@ -109,48 +161,71 @@ func DoFileScan(filename string, sess *Session) {
 		// for every instance of the secret that matched the specific rule create a new finding
 		for k, v := range matchMap {
 
-			// Increment the total number of findings
-			sess.Stats.IncrementFindingsTotal()
-
 			cleanK := strings.SplitAfterN(k, "_", 2)
 
 			if matchMap == nil {
 				content = ""
-				genericID = "not-a-repo://" + filename + "_" + generateGenericID(content)
+				genericID = "not-a-repo://" + location + "_" + generateGenericID(content)
 			} else {
 				content = cleanK[1]
-				genericID = "not-a-repo://" + filename + "_" + generateGenericID(content)
-			}
-
-			// destroy the secret if the flag is set
-			if sess.HideSecrets {
-				content = ""
+				genericID = "not-a-repo://" + location + "_" + generateGenericID(content)
 			}
 
 			if bMatched {
-				newFinding := &Finding{
-					FilePath:          filename,
-					Action:            `File Scan`,
-					Description:       signature.Description(),
-					Signatureid:       signature.Signatureid(),
-					Comment:           content,
-					RepositoryOwner:   `not-a-repo`,
-					RepositoryName:    `not-a-repo`,
-					CommitHash:        ``,
-					CommitMessage:     ``,
-					CommitAuthor:      ``,
-					LineNumber:        strconv.Itoa(v),
-					SecretID:          genericID,
-					WraithVersion:     version.AppVersion(),
-					SignaturesVersion: sess.SignatureVersion,
-				}
+				newFinding := &Finding{}
+				*newFinding = base
+				newFinding.Description = signature.Description()
+				newFinding.Signatureid = signature.Signatureid()
+				newFinding.Score = signature.MatchLevel()
+				newFinding.Severity = signature.Severity()
+				newFinding.Confidence = signature.Confidence()
+				newFinding.Comment = Redact(content, sess.Redact)
+				newFinding.LineNumber = strconv.Itoa(v)
+				newFinding.SecretID = genericID
+				newFinding.WraithVersion = version.AppVersion()
+				newFinding.SignaturesVersion = sess.SignatureVersion
+				newFinding.secret = content
+				newFinding.setLine(fullFilePath, nil, v, content, sess)
 
 				// Add a new finding and increment the total
 				newFinding.Initialize(sess.ScanType)
-				sess.AddFinding(newFinding)
+				if sess.AddFinding(newFinding) {
+					// print the current finding to stdout
+					realTimeOutput(newFinding, sess)
+				}
+			}
+		}
+	}
 
-				// print the current finding to stdout
-				realTimeOutput(newFinding, sess)
+	// match the signatures against what the encoded blobs in the file decode to
+	if sess.Decode {
+		for _, finding := range scanDecoded(sess, pluginContent(fullFilePath, nil), base) {
+			if sess.AddFinding(finding) {
+				realTimeOutput(finding, sess)
+			}
+		}
+	}
+
+	// terraform state is parsed for the values that are secrets rather than only matched
+	if sess.ScanTerraformState && isTerraformState(location) {
+		for _, finding := range scanTerraformState(sess, pluginContent(fullFilePath, nil), "", base) {
+			if sess.AddFinding(finding) {
+				realTimeOutput(finding, sess)
+			}
+		}
+	}
+
+	// hand the file off to any external detector plugins the user has loaded
+	if len(sess.DetectorPlugins) > 0 {
+		req := &PluginRequest{
+			Content:    pluginContent(fullFilePath, nil),
+			FilePath:   location,
+			Repository: base.RepositoryName,
+			ScanType:   sess.ScanType,
+		}
+		for _, finding := range RunDetectorPlugins(req, base, sess) {
+			if sess.AddFinding(finding) {
+				realTimeOutput(finding, sess)
 			}
 		}
 	}
@@ -179,10 +254,13 @@ func ScanDir(path string, sess *Session) {
 		sem <- struct{}{}
 		go func() {
 			defer wg.Done()
+			defer func() { <-sem }()
+			if sess.Interrupted() {
+				return
+			}
 
 			// scan the specific file if it is found to be a valid candidate
 			DoFileScan(p, sess)
-			<-sem
 		}()
 	}
 
@@ -2,9 +2,13 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -28,12 +32,43 @@ var LogColors = map[int]*color.Color{
 	DEBUG:     color.New(color.FgCyan).Add(color.Faint),
 }
 
+// LogLevels are the names of the levels in json logs
+var LogLevels = map[int]string{
+	FATAL:     "fatal",
+	ERROR:     "error",
+	WARN:      "warn",
+	IMPORTANT: "notice",
+	INFO:      "info",
+	DEBUG:     "debug",
+}
+
+// These are the formats logs can be written in
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
 // Logger holds specific configuration data for the logging
 type Logger struct {
 	sync.Mutex
 
 	debug  bool
 	silent bool
+
+	// stderr is set to print text to stderr rather than stdout, so stdout is left for output meant for other programs
+	stderr bool
+
+	// json is set to write each message as a json object, to writer or stderr, rather than as text to stdout
+	json   bool
+	writer io.Writer
+
+	// fields are added to every json message, a logger made by With writes through its root with fields of its own
+	fields map[string]string
+	root   *Logger
+
+	// exit is called in place of os.Exit after a fatal message, so a program that embeds wraith gets an error back
+	exit      func(code int)
+	lastError string
 }
 
 // SetSilent will configure the logger to not display any realtime output to stdout
@@ -41,30 +76,158 @@ func (l *Logger) SetSilent(s bool) {
 	l.silent = s
 }
 
+// SetStderr will configure the logger to print its text output to stderr rather than stdout
+func (l *Logger) SetStderr(s bool) {
+	l.stderr = s
+}
+
 // SetDebug will configure the logger to enable debug output to be set to stdout
 func (l *Logger) SetDebug(d bool) {
 	l.debug = d
 }
 
+// SetOutput will configure the format of the logs, text or json, and the file they are appended to. Text is written
+// to stdout and json to stderr when there is no file.
+func (l *Logger) SetOutput(format string, file string) error {
+	switch format {
+	case "", LogFormatText:
+		l.json = false
+	case LogFormatJSON:
+		l.json = true
+	default:
+		return fmt.Errorf("the log format must be %s or %s", LogFormatText, LogFormatJSON)
+	}
+
+	if file != "" {
+		fh, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		l.writer = fh
+	}
+	return nil
+}
+
+// SetWriter will write the logs to w, in the format set with SetOutput, rather than to a file or the terminal
+func (l *Logger) SetWriter(w io.Writer) {
+	l.getRoot().writer = w
+}
+
+// SetExit will call exit in place of os.Exit once a fatal message has been logged. It must not return, such as by
+// panicking for the program to recover from.
+func (l *Logger) SetExit(exit func(code int)) {
+	l.getRoot().exit = exit
+}
+
+// LastError is the last error or fatal message that was logged
+func (l *Logger) LastError() string {
+	root := l.getRoot()
+	root.Lock()
+	defer root.Unlock()
+	return root.lastError
+}
+
+// Exit will end the program with the exit code, or call the function given to SetExit
+func (l *Logger) Exit(code int) {
+	if root := l.getRoot(); root.exit != nil {
+		root.exit(code)
+	}
+	os.Exit(code)
+}
+
+// SetField will add a field to every json message, such as the id of the session or the stage it is in
+func (l *Logger) SetField(key string, value string) {
+	root := l.getRoot()
+	root.Lock()
+	defer root.Unlock()
+	if root.fields == nil {
+		root.fields = map[string]string{}
+	}
+	root.fields[key] = value
+}
+
+// With will return a logger that adds a field to the json messages written through it, such as the repository being
+// scanned. It shares everything else with the logger it was made from.
+func (l *Logger) With(key string, value string) *Logger {
+	fields := map[string]string{}
+	if l.root != nil {
+		for k, v := range l.fields {
+			fields[k] = v
+		}
+	}
+	fields[key] = value
+	return &Logger{root: l.getRoot(), fields: fields}
+}
+
+// getRoot returns the logger that holds the settings and does the writing
+func (l *Logger) getRoot() *Logger {
+	if l.root != nil {
+		return l.root
+	}
+	return l
+}
+
 // Log is a generic printer for sending data to stdout. It does not do traditional syslog logging
 func (l *Logger) Log(level int, format string, args ...interface{}) {
-	l.Lock()
-	defer l.Unlock()
-	if level == DEBUG && l.debug == false {
-		return
-	} else if level < ERROR && l.silent == true {
+	root := l.getRoot()
+	root.Lock()
+	defer root.Unlock()
+	if level == DEBUG && root.debug == false {
 		return
 	}
+	if level >= ERROR {
+		root.lastError = strings.TrimSpace(fmt.Sprintf(format, args...))
+	}
 
-	if c, ok := LogColors[level]; ok {
-		c.Printf(format, args...)
-	} else {
-		fmt.Printf(format, args...)
+	switch {
+	case root.json:
+		root.writeJSON(level, fmt.Sprintf(format, args...), l.fields)
+	case root.writer != nil:
+		fmt.Fprintf(root.writer, format, args...)
+	case level < ERROR && root.silent == true:
+		// silent only keeps the terminal quiet, logs that go elsewhere are still written
+	default:
+		var w io.Writer = os.Stdout
+		if root.stderr {
+			w = os.Stderr
+		}
+		if c, ok := LogColors[level]; ok {
+			c.Fprintf(w, format, args...)
+		} else {
+			fmt.Fprintf(w, format, args...)
+		}
 	}
 
 	if level == FATAL {
-		os.Exit(1)
+		root.Exit(1)
+	}
+}
+
+// writeJSON will write a message as a json object on a line of its own, with the fields of the root logger and those
+// of the logger it was written through. Messages that are only there to space out the text output are left out.
+func (l *Logger) writeJSON(level int, msg string, fields map[string]string) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
+	}
+
+	entry := map[string]string{}
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = LogLevels[level]
+	entry["msg"] = msg
+
+	w := l.writer
+	if w == nil {
+		w = os.Stderr
 	}
+	data, _ := json.Marshal(entry)
+	w.Write(append(data, '\n'))
 }
 
 // Fatal prints a fatal level log message to stdout
@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"bufio"
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+// logEntries reads the json log messages in a file
+func logEntries(path string) []map[string]string {
+	var entries []map[string]string
+	fh, err := os.Open(path)
+	So(err, ShouldBeNil)
+	defer fh.Close()
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		var entry map[string]string
+		So(json.Unmarshal(scanner.Bytes(), &entry), ShouldBeNil)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogger(t *testing.T) {
+
+	Convey("Given a logger writing json to a file", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-log")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "wraith.log")
+
+		out := &core.Logger{}
+		So(out.SetOutput(core.LogFormatJSON, path), ShouldBeNil)
+		out.SetField("session", "0a1b2c3d")
+
+		Convey("Each message should be a json object with its level, time and the fields of the logger", func() {
+			out.Important("Gathering targets...\n")
+			out.Error(" Failed to retrieve repositories from %s: %s\n", "acme", "not found")
+			out.Important("\n")
+			out.Debug("Threads for repository gathering: %d\n", 4)
+
+			entries := logEntries(path)
+			So(entries, ShouldHaveLength, 2)
+			So(entries[0]["level"], ShouldEqual, "notice")
+			So(entries[0]["msg"], ShouldEqual, "Gathering targets...")
+			So(entries[0]["session"], ShouldEqual, "0a1b2c3d")
+			So(entries[0]["time"], ShouldNotBeEmpty)
+			So(entries[1]["level"], ShouldEqual, "error")
+			So(entries[1]["msg"], ShouldEqual, "Failed to retrieve repositories from acme: not found")
+		})
+
+		Convey("A logger made for a repository should add it to the fields of the logger it was made from", func() {
+			log := out.With("repo", "acme/api")
+			out.SetField("stage", core.StatusAnalyzing)
+			log.Warn("Unable to read git notes\n")
+			out.Info("Analyzing 2 repositories...\n")
+
+			entries := logEntries(path)
+			So(entries, ShouldHaveLength, 2)
+			So(entries[0]["repo"], ShouldEqual, "acme/api")
+			So(entries[0]["stage"], ShouldEqual, core.StatusAnalyzing)
+			So(entries[0]["session"], ShouldEqual, "0a1b2c3d")
+			So(entries[1], ShouldNotContainKey, "repo")
+		})
+
+		Convey("Messages should still be written when the terminal output is silenced", func() {
+			out.SetSilent(true)
+			out.Info("Retrieved 3 repositories from acme\n")
+			So(logEntries(path), ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("A log format other than text or json should be refused", t, func() {
+		So((&core.Logger{}).SetOutput("logfmt", ""), ShouldNotBeNil)
+		So((&core.Logger{}).SetOutput(core.LogFormatText, ""), ShouldBeNil)
+	})
+
+	Convey("The messages of a repository being analyzed should name it and the stage of the scan", t, func() {
+		dir := gitRepo(t)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "wraith.log")
+
+		sess := scanSession(dir)
+		sess.ScanType = "localGit"
+		sess.Threads = 1
+		So(sess.Out.SetOutput(core.LogFormatJSON, path), ShouldBeNil)
+		sess.Out.SetDebug(true)
+		owner, name, branch := "local", "repo", "master"
+		if out, err := exec.Command("git", "-C", dir, "symbolic-ref", "--short", "HEAD").Output(); err == nil {
+			branch = string(out[:len(out)-1])
+		}
+		sess.Repositories = []*core.Repository{{Owner: &owner, Name: &name, FullName: &name, CloneURL: &dir, DefaultBranch: &branch}}
+		sess.Stats.RepositoriesTotal = 1
+
+		core.AnalyzeRepositories(sess)
+		repoEntries := 0
+		for _, entry := range logEntries(path) {
+			So(entry["stage"], ShouldEqual, core.StatusAnalyzing)
+			if entry["repo"] == "repo" {
+				repoEntries++
+			}
+		}
+		So(repoEntries, ShouldBeGreaterThan, 0)
+	})
+}
@@ -0,0 +1,114 @@
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"wraith/core"
+
+	"github.com/google/go-github/github"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeGithubMembersAPI serves an organization with one member, who owns a repository, collaborates on another and
+// has a gist
+func fakeGithubMembersAPI(w http.ResponseWriter, r *http.Request) {
+	repo := func(id int, owner, name string) string {
+		return fmt.Sprintf(`{"id": %d, "name": "%s", "full_name": "%s/%s", "owner": {"login": "%s"}, "fork": false,
+  "clone_url": "https://github.com/%s/%s.git"}`, id, name, owner, name, owner, owner, name)
+	}
+	switch r.URL.Path {
+	case "/users/acme":
+		fmt.Fprint(w, `{"login": "acme", "id": 1, "type": "Organization"}`)
+	case "/users/bob":
+		fmt.Fprint(w, `{"login": "bob", "id": 2, "type": "User"}`)
+	case "/orgs/acme/members":
+		fmt.Fprint(w, `[{"login": "bob", "id": 2, "type": "User"}]`)
+	case "/users/acme/repos":
+		fmt.Fprint(w, "["+repo(10, "acme", "api")+"]")
+	case "/users/bob/repos":
+		switch r.URL.Query().Get("type") {
+		case "sources", core.MemberAffiliationOwner:
+			fmt.Fprint(w, "["+repo(20, "bob", "dotfiles")+"]")
+		case core.MemberAffiliationMember:
+			fmt.Fprint(w, "["+repo(30, "friend", "tool")+"]")
+		case core.MemberAffiliationAll:
+			fmt.Fprint(w, "["+repo(20, "bob", "dotfiles")+","+repo(30, "friend", "tool")+"]")
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	case "/users/bob/gists":
+		fmt.Fprint(w, `[{"id": "aa11", "public": true, "owner": {"login": "bob"}, "git_pull_url": "https://gist.github.com/aa11.git"}]`)
+	case "/users/acme/gists", "/user":
+		fmt.Fprint(w, `[]`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestOrganizationMembers(t *testing.T) {
+
+	Convey("Given a github organization with a member", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(fakeGithubMembersAPI))
+		defer server.Close()
+		apiClient := github.NewClient(nil)
+		apiClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+		sess := &core.Session{
+			Client:             core.NewGithubClient(apiClient, nil),
+			Out:                &core.Logger{},
+			Stats:              &core.Stats{},
+			ScanType:           "github",
+			GithubTargets:      []string{"acme"},
+			Threads:            1,
+			ExpandMembersRepos: true,
+			ExpandMembersGists: true,
+			MemberAffiliation:  core.MemberAffiliationOwner,
+		}
+		gathered := func() []string {
+			core.GatherTargets(sess)
+			core.GatherRepositories(sess)
+			return repositoryNames(sess.Repositories)
+		}
+
+		Convey("The repositories the member owns and their gists should be gathered along with those of the organization", func() {
+			So(gathered(), ShouldResemble, []string{"acme/api", "bob/dotfiles", "bob/aa11"})
+		})
+
+		Convey("The repositories the member collaborates on should be gathered by their affiliation", func() {
+			sess.MemberAffiliation = core.MemberAffiliationMember
+			So(gathered(), ShouldResemble, []string{"acme/api", "friend/tool", "bob/aa11"})
+		})
+
+		Convey("The repositories and the gists of the member should each be left out when asked", func() {
+			sess.ExpandMembersRepos = false
+			So(gathered(), ShouldResemble, []string{"acme/api", "bob/aa11"})
+		})
+
+		Convey("The gists of the member should be left out when asked", func() {
+			sess.ExpandMembersGists = false
+			So(gathered(), ShouldResemble, []string{"acme/api", "bob/dotfiles"})
+		})
+
+		Convey("Members should not be gathered when nothing would be gathered from them", func() {
+			sess.ExpandMembersRepos = false
+			sess.NoGists = true
+			So(gathered(), ShouldResemble, []string{"acme/api"})
+			So(sess.Targets, ShouldHaveLength, 1)
+		})
+
+		Convey("A member that was asked for on its own should have everything gathered", func() {
+			sess.GithubTargets = []string{"acme", "bob"}
+			sess.ExpandMembersRepos = false
+			sess.ExpandMembersGists = false
+			So(gathered(), ShouldResemble, []string{"acme/api", "bob/dotfiles", "bob/aa11"})
+		})
+	})
+
+	Convey("Only the known member affiliations should be valid", t, func() {
+		So(core.ValidMemberAffiliation(core.MemberAffiliationAll), ShouldBeTrue)
+		So(core.ValidMemberAffiliation("collaborator"), ShouldBeFalse)
+	})
+}
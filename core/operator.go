@@ -0,0 +1,416 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"wraith/rpc"
+)
+
+// These identify the ScanJob custom resource, see config/kubernetes/crd.yaml
+const (
+	ScanJobGroup      = "wraith.io"
+	ScanJobVersion    = "v1alpha1"
+	ScanJobKind       = "ScanJob"
+	ScanJobPlural     = "scanjobs"
+	ScanJobLabel      = "wraith.io/scanjob"
+	ScanJobTokenEnv   = "WRAITH_API_TOKEN"
+	ScanJobContainer  = "wraith"
+	ScanJobJobPrefix  = "wraith-"
+	ScanJobManagedBy  = "wraith-operator"
+	ScanJobAPIVersion = ScanJobGroup + "/" + ScanJobVersion
+)
+
+// These are the phases a ScanJob moves through
+const (
+	ScanJobPending   = ""
+	ScanJobRunning   = "Running"
+	ScanJobSucceeded = "Succeeded"
+	ScanJobFailed    = "Failed"
+)
+
+// ScanJobMaxFindings is the most findings written to the status of a ScanJob. Custom resources are stored in etcd
+// so large scans are truncated, the total is always reported.
+const ScanJobMaxFindings = 100
+
+// KubeObjectMeta is the subset of the kubernetes object metadata used by the operator
+type KubeObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	UID             string            `json:"uid,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+}
+
+// KubeSecretKeySelector points at a single key of a kubernetes secret
+type KubeSecretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// ScanJobSpec is the scan a user has declared
+type ScanJobSpec struct {
+	ScanType           string                 `json:"scanType"`
+	Targets            []string               `json:"targets"`
+	APITokenSecretRef  *KubeSecretKeySelector `json:"apiTokenSecretRef,omitempty"`
+	CommitDepth        int                    `json:"commitDepth,omitempty"`
+	Image              string                 `json:"image,omitempty"`
+	NoExpandOrgs       bool                   `json:"noExpandOrgs,omitempty"`
+	OnFindingExec      string                 `json:"onFindingExec,omitempty"`
+	OnRepoCompleteExec string                 `json:"onRepoCompleteExec,omitempty"`
+	Redact             string                 `json:"redact,omitempty"`
+	ScanTests          bool                   `json:"scanTests,omitempty"`
+}
+
+// ScanJobStatus is the progress and result of a ScanJob
+type ScanJobStatus struct {
+	Phase             string        `json:"phase,omitempty"`
+	JobName           string        `json:"jobName,omitempty"`
+	Message           string        `json:"message,omitempty"`
+	StartedAt         string        `json:"startedAt,omitempty"`
+	FinishedAt        string        `json:"finishedAt,omitempty"`
+	RepositoriesTotal int           `json:"repositoriesTotal,omitempty"`
+	FindingsTotal     int           `json:"findingsTotal"`
+	FindingsTruncated bool          `json:"findingsTruncated,omitempty"`
+	Findings          []JSONFinding `json:"findings,omitempty"`
+}
+
+// ScanJob is a scan declared as a kubernetes resource
+type ScanJob struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   KubeObjectMeta `json:"metadata"`
+	Spec       ScanJobSpec    `json:"spec"`
+	Status     ScanJobStatus  `json:"status,omitempty"`
+}
+
+// ScanJobList is the response when listing ScanJobs
+type ScanJobList struct {
+	Items []ScanJob `json:"items"`
+}
+
+// kubeJobStatus is the subset of a batch/v1 Job needed to tell if it has failed
+type kubeJobStatus struct {
+	Status struct {
+		Active    int `json:"active"`
+		Failed    int `json:"failed"`
+		Succeeded int `json:"succeeded"`
+	} `json:"status"`
+}
+
+func scanJobPath(namespace, name string) string {
+	path := "/apis/" + ScanJobAPIVersion
+	if namespace != "" {
+		path += "/namespaces/" + namespace
+	}
+	path += "/" + ScanJobPlural
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+// ListScanJobs will list the ScanJobs in a namespace, or in every namespace if it is empty
+func (k *KubeClient) ListScanJobs(namespace string) ([]ScanJob, error) {
+	var list ScanJobList
+	if err := k.do("GET", scanJobPath(namespace, ""), nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetScanJob will fetch a single ScanJob
+func (k *KubeClient) GetScanJob(namespace, name string) (*ScanJob, error) {
+	var sj ScanJob
+	if err := k.do("GET", scanJobPath(namespace, name), nil, &sj); err != nil {
+		return nil, err
+	}
+	return &sj, nil
+}
+
+// UpdateScanJobStatus will write the status of a ScanJob through the status subresource
+func (k *KubeClient) UpdateScanJobStatus(sj *ScanJob) error {
+	return k.do("PUT", scanJobPath(sj.Metadata.Namespace, sj.Metadata.Name)+"/status", sj, sj)
+}
+
+// CreateJob will create a batch/v1 Job from its manifest
+func (k *KubeClient) CreateJob(namespace string, job map[string]interface{}) error {
+	return k.do("POST", "/apis/batch/v1/namespaces/"+namespace+"/jobs", job, nil)
+}
+
+// getJobStatus will fetch the status of a batch/v1 Job
+func (k *KubeClient) getJobStatus(namespace, name string) (*kubeJobStatus, error) {
+	var job kubeJobStatus
+	if err := k.do("GET", "/apis/batch/v1/namespaces/"+namespace+"/jobs/"+name, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ScanJobOperator turns ScanJob resources into kubernetes Jobs that run the scan and report back to the ScanJob
+type ScanJobOperator struct {
+	Client         *KubeClient
+	Image          string
+	Namespace      string
+	Resync         time.Duration
+	ServiceAccount string
+	Out            *Logger
+
+	// AllowedImages are the images a ScanJob can set besides Image, and AllowExec lets it run hook commands. Anyone who
+	// can create a ScanJob could otherwise run any command with the service account of the scan Jobs.
+	AllowedImages []string
+	AllowExec     bool
+}
+
+// Run will reconcile every ScanJob until the stop channel is closed
+func (o *ScanJobOperator) Run(stop <-chan struct{}) {
+	for {
+		if err := o.Reconcile(); err != nil {
+			o.Out.Error("Failed to reconcile ScanJobs: %s\n", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(o.Resync):
+		}
+	}
+}
+
+// Reconcile will do a single pass over every ScanJob the operator is responsible for
+func (o *ScanJobOperator) Reconcile() error {
+	jobs, err := o.Client.ListScanJobs(o.Namespace)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Metadata.Namespace+"/"+jobs[i].Metadata.Name < jobs[j].Metadata.Namespace+"/"+jobs[j].Metadata.Name
+	})
+
+	for i := range jobs {
+		sj := &jobs[i]
+		if err := o.reconcileScanJob(sj); err != nil {
+			o.Out.Error("Failed to reconcile ScanJob %s/%s: %s\n", sj.Metadata.Namespace, sj.Metadata.Name, err)
+		}
+	}
+	return nil
+}
+
+// reconcileScanJob will start a Job for a new ScanJob or check on the Job of a running one. The scan itself marks
+// the ScanJob as succeeded, the operator only has to catch Jobs that died before they could.
+func (o *ScanJobOperator) reconcileScanJob(sj *ScanJob) error {
+	switch sj.Status.Phase {
+	case ScanJobPending:
+		if err := o.checkScanJob(sj); err != nil {
+			return o.failScanJob(sj, err.Error())
+		}
+		jobName := ScanJobJobPrefix + sj.Metadata.Name
+		if err := o.Client.CreateJob(sj.Metadata.Namespace, o.newJob(sj, jobName)); err != nil && !IsKubeConflict(err) {
+			return err
+		}
+		o.Out.Info("Started Job %s for ScanJob %s/%s\n", jobName, sj.Metadata.Namespace, sj.Metadata.Name)
+
+		sj.Status.Phase = ScanJobRunning
+		sj.Status.JobName = jobName
+		sj.Status.StartedAt = time.Now().UTC().Format(time.RFC3339)
+		return o.Client.UpdateScanJobStatus(sj)
+
+	case ScanJobRunning:
+		job, err := o.Client.getJobStatus(sj.Metadata.Namespace, sj.Status.JobName)
+		if IsKubeNotFound(err) {
+			return o.failScanJob(sj, fmt.Sprintf("Job %s no longer exists", sj.Status.JobName))
+		}
+		if err != nil {
+			return err
+		}
+		if job.Status.Failed > 0 && job.Status.Active == 0 {
+			return o.failScanJob(sj, fmt.Sprintf("Job %s failed, see its pod logs for details", sj.Status.JobName))
+		}
+	}
+	return nil
+}
+
+// checkScanJob will refuse a ScanJob that asks for an image or hook commands the operator does not allow
+func (o *ScanJobOperator) checkScanJob(sj *ScanJob) error {
+	if image := sj.Spec.Image; image != "" && image != o.Image {
+		allowed := false
+		for _, a := range o.AllowedImages {
+			allowed = allowed || a == image
+		}
+		if !allowed {
+			return fmt.Errorf("the image %s is not one of the allowed-images of the operator", image)
+		}
+	}
+	if !o.AllowExec && (sj.Spec.OnFindingExec != "" || sj.Spec.OnRepoCompleteExec != "") {
+		return errScanJobExec
+	}
+	return nil
+}
+
+// errScanJobExec is returned for a ScanJob with hook commands when the operator was not started with allow-exec
+var errScanJobExec = errors.New("onFindingExec and onRepoCompleteExec are only run when the operator is started with allow-exec")
+
+// failScanJob will mark a ScanJob as failed with the given reason
+func (o *ScanJobOperator) failScanJob(sj *ScanJob, message string) error {
+	o.Out.Error("ScanJob %s/%s failed: %s\n", sj.Metadata.Namespace, sj.Metadata.Name, message)
+	sj.Status.Phase = ScanJobFailed
+	sj.Status.Message = message
+	sj.Status.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	return o.Client.UpdateScanJobStatus(sj)
+}
+
+// newJob will build the manifest of the Job that runs a ScanJob. The Job is owned by the ScanJob so it is cleaned up
+// when the ScanJob is deleted.
+func (o *ScanJobOperator) newJob(sj *ScanJob, name string) map[string]interface{} {
+	image := sj.Spec.Image
+	if image == "" {
+		image = o.Image
+	}
+
+	var env []interface{}
+	if ref := sj.Spec.APITokenSecretRef; ref != nil {
+		env = append(env, map[string]interface{}{
+			"name": ScanJobTokenEnv,
+			"valueFrom": map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{"name": ref.Name, "key": ref.Key},
+			},
+		})
+	}
+
+	args := []string{"operator", "run", "--namespace", sj.Metadata.Namespace, "--name", sj.Metadata.Name}
+	if o.AllowExec {
+		args = append(args, "--allow-exec")
+	}
+	container := map[string]interface{}{
+		"name":  ScanJobContainer,
+		"image": image,
+		"args":  args,
+	}
+	if len(env) > 0 {
+		container["env"] = env
+	}
+
+	podSpec := map[string]interface{}{
+		"restartPolicy": "Never",
+		"containers":    []interface{}{container},
+	}
+	if o.ServiceAccount != "" {
+		podSpec["serviceAccountName"] = o.ServiceAccount
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": ScanJobManagedBy,
+		ScanJobLabel:                   sj.Metadata.Name,
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": sj.Metadata.Namespace,
+			"labels":    labels,
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion":         ScanJobAPIVersion,
+					"kind":               ScanJobKind,
+					"name":               sj.Metadata.Name,
+					"uid":                sj.Metadata.UID,
+					"controller":         true,
+					"blockOwnerDeletion": true,
+				},
+			},
+		},
+		"spec": map[string]interface{}{
+			"backoffLimit": 0,
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	}
+}
+
+// RunScanJob will run the scan declared by a ScanJob and write the outcome to its status. It is what runs inside of
+// the Job created by the operator, which passes allowExec on when hook commands are allowed. The spec is read again
+// here, so the hooks are checked again in case they were added after the operator checked the ScanJob.
+func RunScanJob(client *KubeClient, namespace, name string, allowExec bool) error {
+	sj, err := client.GetScanJob(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	req := &rpc.SubmitScanRequest{
+		ScanType:     sj.Spec.ScanType,
+		Targets:      sj.Spec.Targets,
+		ApiToken:     os.Getenv(ScanJobTokenEnv),
+		CommitDepth:  int32(sj.Spec.CommitDepth),
+		ScanTests:    sj.Spec.ScanTests,
+		NoExpandOrgs: sj.Spec.NoExpandOrgs,
+		Redact:       sj.Spec.Redact,
+	}
+
+	v, err := NewScanConfig(SetConfig(), req)
+	if err == nil && !allowExec && (sj.Spec.OnFindingExec != "" || sj.Spec.OnRepoCompleteExec != "") {
+		err = errScanJobExec
+	}
+	var sess *Session
+	if err == nil {
+		if sj.Spec.OnFindingExec != "" {
+			v.Set("on-finding-exec", sj.Spec.OnFindingExec)
+		}
+		if sj.Spec.OnRepoCompleteExec != "" {
+			v.Set("on-repo-complete-exec", sj.Spec.OnRepoCompleteExec)
+		}
+		sess = NewSession(v, sj.Spec.ScanType)
+		sess.HandleInterrupts()
+		err = RunScan(sess)
+		sess.Finish()
+		if err == nil && sess.Interrupted() {
+			err = fmt.Errorf("the scan was stopped before it finished")
+		}
+		if err == nil && len(sess.PolicyFailures) > 0 {
+			err = fmt.Errorf("policy failed: %s", strings.Join(sess.PolicyFailures, ", "))
+		}
+	}
+
+	// read the ScanJob again so the status is written on top of the latest version
+	sj, getErr := client.GetScanJob(namespace, name)
+	if getErr != nil {
+		return getErr
+	}
+
+	sj.Status.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	if err != nil {
+		sj.Status.Phase = ScanJobFailed
+		sj.Status.Message = err.Error()
+	} else {
+		sj.Status.Phase = ScanJobSucceeded
+		sj.Status.Message = ""
+	}
+
+	if sess != nil {
+		sess.Lock()
+		sj.Status.RepositoriesTotal = len(sess.Repositories)
+		sj.Status.FindingsTotal = len(sess.Findings)
+		sj.Status.Findings = nil
+		for i, f := range sess.Findings {
+			if i == ScanJobMaxFindings {
+				sj.Status.FindingsTruncated = true
+				break
+			}
+			// anyone who can read the ScanJob can read its status, and it is kept in etcd, so the secrets are
+			// redacted the way issues are whatever the output redacts
+			sj.Status.Findings = append(sj.Status.Findings, issueFinding(f, sess.Redact))
+		}
+		sess.Unlock()
+	}
+
+	if updateErr := client.UpdateScanJobStatus(sj); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+// fakeKubeAPI serves a single ScanJob and records the Jobs and status updates it receives
+type fakeKubeAPI struct {
+	sync.Mutex
+
+	scanJob  core.ScanJob
+	jobs     []map[string]interface{}
+	jobState string
+}
+
+func (f *fakeKubeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	const base = "/apis/wraith.io/v1alpha1/namespaces/security/scanjobs"
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/apis/wraith.io/v1alpha1/scanjobs":
+		json.NewEncoder(w).Encode(core.ScanJobList{Items: []core.ScanJob{f.scanJob}})
+	case r.Method == "GET" && r.URL.Path == base+"/nightly":
+		json.NewEncoder(w).Encode(f.scanJob)
+	case r.Method == "PUT" && r.URL.Path == base+"/nightly/status":
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &f.scanJob)
+		w.Write(body)
+	case r.Method == "POST" && r.URL.Path == "/apis/batch/v1/namespaces/security/jobs":
+		var job map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&job)
+		f.jobs = append(f.jobs, job)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "GET" && r.URL.Path == "/apis/batch/v1/namespaces/security/jobs/wraith-nightly":
+		if f.jobState == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(f.jobState))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestScanJobOperator(t *testing.T) {
+
+	Convey("Given an operator and a new ScanJob", t, func() {
+		api := &fakeKubeAPI{scanJob: core.ScanJob{
+			APIVersion: core.ScanJobAPIVersion,
+			Kind:       core.ScanJobKind,
+			Metadata:   core.KubeObjectMeta{Name: "nightly", Namespace: "security", UID: "1234"},
+			Spec: core.ScanJobSpec{
+				ScanType:          "github",
+				Targets:           []string{"acme"},
+				APITokenSecretRef: &core.KubeSecretKeySelector{Name: "github-token", Key: "token"},
+			},
+		}}
+		server := httptest.NewServer(api)
+		defer server.Close()
+
+		client, err := core.NewKubeClient(server.URL)
+		So(err, ShouldBeNil)
+
+		operator := &core.ScanJobOperator{
+			Client:         client,
+			Image:          "wraith:test",
+			Resync:         time.Second,
+			ServiceAccount: "wraith-scanner",
+			Out:            &core.Logger{},
+		}
+		operator.Out.SetSilent(true)
+
+		Convey("When it is reconciled", func() {
+			So(operator.Reconcile(), ShouldBeNil)
+
+			Convey("A Job owned by the ScanJob should be created", func() {
+				So(len(api.jobs), ShouldEqual, 1)
+				meta := api.jobs[0]["metadata"].(map[string]interface{})
+				So(meta["name"], ShouldEqual, "wraith-nightly")
+				owner := meta["ownerReferences"].([]interface{})[0].(map[string]interface{})
+				So(owner["uid"], ShouldEqual, "1234")
+
+				data, _ := json.Marshal(api.jobs[0])
+				So(string(data), ShouldContainSubstring, `"args":["operator","run","--namespace","security","--name","nightly"]`)
+				So(string(data), ShouldContainSubstring, `"secretKeyRef":{"key":"token","name":"github-token"}`)
+				So(string(data), ShouldContainSubstring, `"serviceAccountName":"wraith-scanner"`)
+			})
+
+			Convey("The ScanJob should be running", func() {
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobRunning)
+				So(api.scanJob.Status.JobName, ShouldEqual, "wraith-nightly")
+			})
+
+			Convey("When the Job fails the ScanJob should fail", func() {
+				api.jobState = `{"status": {"failed": 1}}`
+				So(operator.Reconcile(), ShouldBeNil)
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobFailed)
+				So(len(api.jobs), ShouldEqual, 1)
+			})
+
+			Convey("When the Job is still active the ScanJob should keep running", func() {
+				api.jobState = `{"status": {"active": 1}}`
+				So(operator.Reconcile(), ShouldBeNil)
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobRunning)
+			})
+		})
+
+		Convey("A ScanJob with an image the operator does not allow should fail without a Job", func() {
+			api.scanJob.Spec.Image = "evil/wraith:latest"
+			So(operator.Reconcile(), ShouldBeNil)
+			So(api.jobs, ShouldBeEmpty)
+			So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobFailed)
+			So(api.scanJob.Status.Message, ShouldContainSubstring, "allowed-images")
+
+			Convey("unless it is one of the allowed images", func() {
+				api.scanJob.Status = core.ScanJobStatus{}
+				operator.AllowedImages = []string{"evil/wraith:latest"}
+				So(operator.Reconcile(), ShouldBeNil)
+				So(api.jobs, ShouldHaveLength, 1)
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobRunning)
+			})
+		})
+
+		Convey("Given a ScanJob with a hook command", func() {
+			api.scanJob.Spec.OnFindingExec = "curl -d @- https://example.com"
+
+			Convey("It should fail without a Job unless the operator allows exec", func() {
+				So(operator.Reconcile(), ShouldBeNil)
+				So(api.jobs, ShouldBeEmpty)
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobFailed)
+				So(api.scanJob.Status.Message, ShouldContainSubstring, "allow-exec")
+			})
+
+			Convey("Its Job should be allowed to run the hook when the operator allows exec", func() {
+				operator.AllowExec = true
+				So(operator.Reconcile(), ShouldBeNil)
+				So(api.jobs, ShouldHaveLength, 1)
+				data, _ := json.Marshal(api.jobs[0])
+				So(string(data), ShouldContainSubstring, `"--name","nightly","--allow-exec"]`)
+			})
+
+			Convey("Running it without allow-exec should fail before scanning", func() {
+				err := core.RunScanJob(client, "security", "nightly", false)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "allow-exec")
+				So(api.scanJob.Status.Phase, ShouldEqual, core.ScanJobFailed)
+			})
+		})
+	})
+}
@@ -0,0 +1,306 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"time"
+)
+
+// JSONFinding is the stable representation of a finding used in all json and jsonl output. Fields may be added
+// in a minor schema version but never renamed or removed without a major version bump.
+type JSONFinding struct {
+	SchemaVersion        string            `json:"schema_version,omitempty"`
+	Action               string            `json:"action"`
+	AtHead               bool              `json:"at_head"`
+	CodeOwners           []string          `json:"code_owners,omitempty"`
+	Comment              string            `json:"comment"`
+	Confidence           string            `json:"confidence"`
+	Context              []JSONContextLine `json:"context,omitempty"`
+	CommitAuthor         string            `json:"commit_author"`
+	CommitAuthorEmail    string            `json:"commit_author_email,omitempty"`
+	CommitAuthorName     string            `json:"commit_author_name,omitempty"`
+	CommitDate           string            `json:"commit_date,omitempty"`
+	CommitHash           string            `json:"commit_hash"`
+	CommitMessage        string            `json:"commit_message"`
+	CommitURL            string            `json:"commit_url"`
+	CommitterEmail       string            `json:"committer_email,omitempty"`
+	CommitterName        string            `json:"committer_name,omitempty"`
+	Description          string            `json:"description"`
+	Encoding             string            `json:"encoding,omitempty"`
+	FilePath             string            `json:"file_path"`
+	FileURL              string            `json:"file_url"`
+	Fingerprint          string            `json:"fingerprint"`
+	FirstSeen            *SeenCommit       `json:"first_seen,omitempty"`
+	JWT                  *JWT              `json:"jwt,omitempty"`
+	Labels               map[string]string `json:"labels,omitempty"`
+	LastSeen             *SeenCommit       `json:"last_seen,omitempty"`
+	LineNumber           string            `json:"line_number"`
+	Occurrences          int               `json:"occurrences,omitempty"`
+	Remediation          *Remediation      `json:"remediation,omitempty"`
+	RepositoryArchived   bool              `json:"repository_archived,omitempty"`
+	RepositoryFork       bool              `json:"repository_fork,omitempty"`
+	RepositoryName       string            `json:"repository_name"`
+	RepositoryOwner      string            `json:"repository_owner"`
+	RepositoryPushedAt   string            `json:"repository_pushed_at,omitempty"`
+	RepositoryTopics     []string          `json:"repository_topics,omitempty"`
+	RepositoryURL        string            `json:"repository_url"`
+	RepositoryVisibility string            `json:"repository_visibility,omitempty"`
+	Score                int               `json:"score"`
+	SecretHash           string            `json:"secret_hash,omitempty"`
+	SecretID             string            `json:"secret_id"`
+	Severity             string            `json:"severity"`
+	SignatureID          string            `json:"signature_id"`
+	SignaturesVersion    string            `json:"signatures_version"`
+	Verification         string            `json:"verification,omitempty"`
+	WraithVersion        string            `json:"wraith_version"`
+}
+
+// JSONContextLine is the stable representation of a line of context around a finding
+type JSONContextLine struct {
+	LineNumber int    `json:"line_number"`
+	Content    string `json:"content"`
+}
+
+// JSONStats is the stable representation of the session statistics used in json output
+type JSONStats struct {
+	CommitsDirty        int `json:"commits_dirty"`
+	CommitsScanned      int `json:"commits_scanned"`
+	FilesDirty          int `json:"files_dirty"`
+	FilesIgnored        int `json:"files_ignored"`
+	FilesScanned        int `json:"files_scanned"`
+	FilesTotal          int `json:"files_total"`
+	FindingsTotal       int `json:"findings_total"`
+	RepositoriesCloned  int `json:"repositories_cloned"`
+	RepositoriesScanned int `json:"repositories_scanned"`
+	RepositoriesTotal   int `json:"repositories_total"`
+	Targets             int `json:"targets"`
+}
+
+// JSONReport is the document written by --json
+type JSONReport struct {
+	SchemaVersion     string        `json:"schema_version"`
+	WraithVersion     string        `json:"wraith_version"`
+	SignaturesVersion string        `json:"signatures_version"`
+	ScanType          string        `json:"scan_type"`
+	StartedAt         string        `json:"started_at"`
+	FinishedAt        string        `json:"finished_at"`
+	Interrupted       bool          `json:"interrupted,omitempty"`
+	Stats             JSONStats     `json:"stats"`
+	Risk              RiskReport    `json:"risk"`
+	Errors            []ScanError   `json:"errors,omitempty"`
+	Secrets           []SecretGroup `json:"secrets"`
+	Findings          []JSONFinding `json:"findings"`
+}
+
+// NewJSONFinding will convert a finding into its stable json representation
+func NewJSONFinding(f *Finding) JSONFinding {
+	return JSONFinding{
+		Action:               f.Action,
+		AtHead:               f.AtHead,
+		CodeOwners:           f.CodeOwners,
+		Comment:              f.Comment,
+		Confidence:           FindingConfidence(f),
+		Context:              newJSONContext(f.Context),
+		CommitAuthor:         f.CommitAuthor,
+		CommitAuthorEmail:    f.CommitAuthorEmail,
+		CommitAuthorName:     f.CommitAuthorName,
+		CommitDate:           f.CommitDate,
+		CommitHash:           f.CommitHash,
+		CommitMessage:        f.CommitMessage,
+		CommitURL:            f.CommitUrl,
+		CommitterEmail:       f.CommitterEmail,
+		CommitterName:        f.CommitterName,
+		Description:          f.Description,
+		Encoding:             f.Encoding,
+		FilePath:             f.FilePath,
+		FileURL:              f.FileUrl,
+		Fingerprint:          f.Fingerprint,
+		FirstSeen:            f.FirstSeen,
+		JWT:                  f.JWT,
+		Labels:               f.Labels,
+		LastSeen:             f.LastSeen,
+		LineNumber:           f.LineNumber,
+		Occurrences:          f.Occurrences,
+		Remediation:          f.Remediation,
+		RepositoryArchived:   f.RepositoryArchived,
+		RepositoryFork:       f.RepositoryFork,
+		RepositoryName:       f.RepositoryName,
+		RepositoryOwner:      f.RepositoryOwner,
+		RepositoryPushedAt:   f.RepositoryPushedAt,
+		RepositoryTopics:     f.RepositoryTopics,
+		RepositoryURL:        f.RepositoryUrl,
+		RepositoryVisibility: f.RepositoryVisibility,
+		Score:                f.Score,
+		SecretHash:           f.SecretHash,
+		SecretID:             f.SecretID,
+		Severity:             FindingSeverity(f),
+		SignatureID:          f.Signatureid,
+		SignaturesVersion:    f.SignaturesVersion,
+		Verification:         f.Verification,
+		WraithVersion:        f.WraithVersion,
+	}
+}
+
+// newJSONContext will convert the context lines of a finding into their stable json representation
+func newJSONContext(lines []ContextLine) []JSONContextLine {
+	var context []JSONContextLine
+	for _, l := range lines {
+		context = append(context, JSONContextLine{LineNumber: l.LineNumber, Content: l.Content})
+	}
+	return context
+}
+
+// NewJSONStats will convert the statistics of a session into their stable json representation
+func NewJSONStats(stats *Stats) JSONStats {
+	stats.Lock()
+	defer stats.Unlock()
+	return JSONStats{
+		CommitsDirty:        stats.CommitsDirty,
+		CommitsScanned:      stats.Commits,
+		FilesDirty:          stats.FilesDirty,
+		FilesIgnored:        stats.FilesIgnored,
+		FilesScanned:        stats.FilesScanned,
+		FilesTotal:          stats.FilesTotal,
+		FindingsTotal:       stats.FindingsTotal,
+		RepositoriesCloned:  stats.RepositoriesCloned,
+		RepositoriesScanned: stats.RepositoriesScanned,
+		RepositoriesTotal:   stats.RepositoriesTotal,
+		Targets:             stats.Targets,
+	}
+}
+
+// NewJSONReport will build a json report from the current state of the session
+func NewJSONReport(s *Session) JSONReport {
+	stats := NewJSONStats(s.Stats)
+	s.Stats.Lock()
+	startedAt := s.Stats.StartedAt.Format(time.RFC3339)
+	finishedAt := s.Stats.FinishedAt.Format(time.RFC3339)
+	s.Stats.Unlock()
+
+	report := JSONReport{
+		SchemaVersion:     JSONSchemaVersion,
+		WraithVersion:     s.Version,
+		SignaturesVersion: s.SignatureVersion,
+		ScanType:          s.ScanType,
+		StartedAt:         startedAt,
+		FinishedAt:        finishedAt,
+		Interrupted:       s.Interrupted(),
+		Stats:             stats,
+		Risk:              s.RiskReport(),
+		Errors:            s.ScanErrors(),
+		Secrets:           s.SecretGroups(),
+		Findings:          []JSONFinding{},
+	}
+
+	s.Lock()
+	for _, f := range s.Findings {
+		report.Findings = append(report.Findings, NewJSONFinding(f))
+	}
+	s.Unlock()
+
+	return report
+}
+
+// WriteJSONReport will write the full session report as a single json document
+func WriteJSONReport(location string, s *Session) error {
+	data, err := json.MarshalIndent(NewJSONReport(s), "", "  ")
+	if err != nil {
+		return err
+	}
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	if _, err := fh.Write(data); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// WriteJSONLReport will write one json document per finding, each carrying its own schema version
+func WriteJSONLReport(location string, s *Session) error {
+	fh, err := s.createReport(location)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	w := bufio.NewWriter(fh)
+	enc := json.NewEncoder(w)
+
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range s.Findings {
+		jf := NewJSONFinding(f)
+		jf.SchemaVersion = JSONSchemaVersion
+		if err := enc.Encode(jf); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return fh.Close()
+}
+
+// WriteReports will write out every report file the user asked for at the end of a session, and upload them to the
+// bucket of report-s3
+func (s *Session) WriteReports() {
+	var written []string
+	if s.JSONOutput != "" {
+		if err := WriteJSONReport(s.JSONOutput, s); err != nil {
+			s.Out.Error("Failed to write json report to %s: %s\n", s.JSONOutput, err)
+		} else {
+			s.Out.Important("JSON report written to %s\n", s.ReportEncryption.Path(s.JSONOutput))
+			written = append(written, s.ReportEncryption.Path(s.JSONOutput))
+		}
+	}
+
+	if s.JSONLOutput != "" {
+		if err := WriteJSONLReport(s.JSONLOutput, s); err != nil {
+			s.Out.Error("Failed to write jsonl report to %s: %s\n", s.JSONLOutput, err)
+		} else {
+			s.Out.Important("JSONL report written to %s\n", s.ReportEncryption.Path(s.JSONLOutput))
+			written = append(written, s.ReportEncryption.Path(s.JSONLOutput))
+		}
+	}
+
+	if s.HTMLOutput != "" {
+		if err := WriteHTMLReport(s.HTMLOutput, s); err != nil {
+			s.Out.Error("Failed to write html report to %s: %s\n", s.HTMLOutput, err)
+		} else {
+			s.Out.Important("HTML report written to %s\n", s.ReportEncryption.Path(s.HTMLOutput))
+			written = append(written, s.ReportEncryption.Path(s.HTMLOutput))
+		}
+	}
+
+	if s.CSVOutput != "" {
+		if err := WriteCSVReport(s.CSVOutput, s); err != nil {
+			s.Out.Error("Failed to write csv report to %s: %s\n", s.CSVOutput, err)
+		} else {
+			s.Out.Important("CSV report written to %s\n", s.ReportEncryption.Path(s.CSVOutput))
+			written = append(written, s.ReportEncryption.Path(s.CSVOutput))
+		}
+	}
+
+	if s.GitlabReportOutput != "" {
+		if err := WriteGitlabReport(s.GitlabReportOutput, s); err != nil {
+			s.Out.Error("Failed to write gitlab report to %s: %s\n", s.GitlabReportOutput, err)
+		} else {
+			s.Out.Important("GitLab report written to %s\n", s.ReportEncryption.Path(s.GitlabReportOutput))
+			written = append(written, s.ReportEncryption.Path(s.GitlabReportOutput))
+		}
+	}
+
+	if s.JUnitOutput != "" {
+		if err := WriteJUnitReport(s.JUnitOutput, s); err != nil {
+			s.Out.Error("Failed to write junit report to %s: %s\n", s.JUnitOutput, err)
+		} else {
+			s.Out.Important("JUnit report written to %s\n", s.ReportEncryption.Path(s.JUnitOutput))
+			written = append(written, s.ReportEncryption.Path(s.JUnitOutput))
+		}
+	}
+
+	s.UploadReports(written)
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// PathFilter picks the paths inside a repository or directory that are scanned, so a team that owns a part of a
+// monorepo can scan just that part. The patterns follow the same rules as a .gitignore file, so services/payments/**
+// is everything below services/payments and *.tf is every terraform file wherever it is.
+type PathFilter struct {
+	include []gitignore.Pattern
+	exclude []gitignore.Pattern
+}
+
+// NewPathFilter will parse the patterns of --path-include and --path-exclude. It returns nil when there are none, so
+// every path is scanned.
+func NewPathFilter(include, exclude []string) (*PathFilter, error) {
+	f := &PathFilter{}
+	for _, list := range []struct {
+		patterns []string
+		parsed   *[]gitignore.Pattern
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, pattern := range list.patterns {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if strings.HasPrefix(pattern, "!") {
+				return nil, fmt.Errorf("%s can not be negated, exclude the path with --path-exclude instead", pattern)
+			}
+			*list.parsed = append(*list.parsed, gitignore.ParsePattern(pattern, nil))
+		}
+	}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// pathParts splits a path relative to the root of a repository or directory into its parts
+func pathParts(path string) []string {
+	return strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+}
+
+// matchesAny will check whether a path matches any of a list of patterns
+func matchesAny(patterns []gitignore.Pattern, parts []string, isDir bool) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(parts, isDir) == gitignore.Exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// Scans will check whether a file is scanned, its path relative to the root of the repository or directory it is in.
+// A file is scanned when it matches an include pattern, or there are none, and matches no exclude pattern.
+func (f *PathFilter) Scans(path string) bool {
+	if f == nil {
+		return true
+	}
+	parts := pathParts(path)
+	if len(f.include) > 0 && !matchesAny(f.include, parts, false) {
+		return false
+	}
+	return !matchesAny(f.exclude, parts, false)
+}
+
+// skipsDir will check whether a whole directory is excluded, so a walk of the files on disk does not go into it
+func (f *PathFilter) skipsDir(path string) bool {
+	if f == nil || path == "." || path == "" {
+		return false
+	}
+	return matchesAny(f.exclude, pathParts(path), true)
+}
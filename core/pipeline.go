@@ -0,0 +1,788 @@
+package core
+
+import (
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"wraith/version"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// DefaultPipelineQueueSize is how many changes, or the findings of as many changes, can wait between two stages
+const DefaultPipelineQueueSize = 100
+
+// pipeline scans repositories in stages connected by bounded queues, so a stage that waits on the network, such as
+// cloning, and one that keeps a cpu busy, such as matching, each run as many at a time as suits them:
+//
+//	enumerate -> clone -> walk -> match -> report
+//
+// The walk reads the changes of every commit out of the clone and the match stage runs the signatures over them. The
+// findings are added to the session by a single reporter, which finishes a repository once all of its changes are in.
+type pipeline struct {
+	sess    *Session
+	cloned  chan *repositoryScan
+	changes chan *matchJob
+	results chan *matchResult
+}
+
+// repositoryScan is a repository that has been cloned and is moving through the rest of the pipeline
+type repositoryScan struct {
+	repo     *Repository
+	clone    *git.Repository
+	path     string
+	inMem    bool
+	reserved int64
+	log      *Logger
+	heads    []BranchHead
+	branches map[plumbing.Hash]string
+
+	// a submodule is walked from a clone of its own, its findings are made in the repository it is a submodule of
+	// under its path, and link to webURL as they can not be linked to in that repository
+	url       string
+	submodule string
+	webURL    string
+	depth     int
+	parent    *repositoryScan
+
+	// pending counts the changes that are still being matched or reported, plus one held by the walk until it has
+	// queued them all, the repository is finished by whichever stage brings it to zero
+	pending int64
+
+	sync.Mutex
+	dirty map[plumbing.Hash]bool
+}
+
+// matchJob is a change of a commit to match the signatures against, or when change is nil, the message and note of
+// the commit
+type matchJob struct {
+	scan         *repositoryScan
+	commit       *object.Commit
+	change       *object.Change
+	note         string
+	filePath     string
+	fullFilePath string
+	// originalPath is the path the file was first added under when git detects it has been renamed since
+	originalPath string
+}
+
+// matchResult holds the findings of a job in the order they were matched
+type matchResult struct {
+	job      *matchJob
+	findings []pipelineFinding
+}
+
+// pipelineFinding is a finding waiting to be reported. The findings of content signatures are only reported when the
+// same secret has not been found in the commit yet.
+type pipelineFinding struct {
+	*Finding
+	checkCommit bool
+}
+
+// stageThreads is how many of the workers of a stage run at a time, each stage that is not set runs as many as
+// --num-threads
+func (s *Session) stageThreads(n int) int {
+	if n > 0 {
+		return n
+	}
+	if s.Threads > 0 {
+		return s.Threads
+	}
+	return 1
+}
+
+// runPipeline will scan the repositories and return once every one of them has been finished
+func runPipeline(sess *Session, repositories []*Repository) {
+	queueSize := sess.PipelineQueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultPipelineQueueSize
+	}
+	cloneThreads := sess.stageThreads(sess.CloneThreads)
+	walkThreads := sess.stageThreads(sess.WalkThreads)
+	matchThreads := sess.stageThreads(sess.MatchThreads)
+	// there is no point in more clone or walk workers than repositories
+	if cloneThreads > len(repositories) {
+		cloneThreads = len(repositories)
+	}
+	if walkThreads > len(repositories) {
+		walkThreads = len(repositories)
+	}
+	sess.Out.Debug("Pipeline workers: %d clone, %d walk, %d match, queues of %d\n", cloneThreads, walkThreads, matchThreads, queueSize)
+
+	p := &pipeline{
+		sess: sess,
+		// a cloned repository takes up disk or memory, so only as many wait as there are workers to walk them
+		cloned:  make(chan *repositoryScan, walkThreads),
+		changes: make(chan *matchJob, queueSize),
+		results: make(chan *matchResult, queueSize),
+	}
+
+	enumerated := make(chan *Repository, queueSize)
+	go func() {
+		for _, repo := range repositories {
+			enumerated <- repo
+		}
+		close(enumerated)
+	}()
+
+	p.stage(cloneThreads, func(tid int) {
+		for repo := range enumerated {
+			// once the scan is stopped the repositories left are skipped so the workers finish what they have
+			if sess.Interrupted() {
+				sess.Out.Debug("[THREAD #%d][%s] Skipping, the scan has been stopped\n", tid, *repo.CloneURL)
+				continue
+			}
+			if scan := p.clone(repo, tid); scan != nil {
+				p.cloned <- scan
+			}
+		}
+	}, func() { close(p.cloned) })
+
+	p.stage(walkThreads, func(tid int) {
+		for scan := range p.cloned {
+			p.walk(scan, tid)
+		}
+	}, func() { close(p.changes) })
+
+	p.stage(matchThreads, func(tid int) {
+		for job := range p.changes {
+			p.match(job)
+		}
+	}, func() { close(p.results) })
+
+	// a single reporter adds the findings, so they are added in the order each repository was matched in
+	for result := range p.results {
+		p.report(result)
+	}
+}
+
+// stage will start the workers of a stage and call done once all of them have returned
+func (p *pipeline) stage(threads int, work func(tid int), done func()) {
+	var wg sync.WaitGroup
+	wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func(tid int) {
+			defer wg.Done()
+			work(tid)
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		done()
+	}()
+}
+
+// clone will clone a repository, into memory when it fits in what is left of the budget for in memory clones
+func (p *pipeline) clone(repo *Repository, tid int) *repositoryScan {
+	sess := p.sess
+	log := sess.Out.With("repo", *repo.FullName)
+
+	inMem, reserved := cloneInMemory(sess, repo)
+	// path is the directory the repository was cloned to, or where it would be when it is cloned into memory
+	clone, path, err := cloneRepository(sess, repo, inMem, tid)
+	if err != nil {
+		sess.releaseCloneMemory(reserved)
+		if err.Error() != "remote repository is empty" {
+			log.Error("Error cloning repository %s: %s\n", *repo.FullName, err)
+		}
+		return nil
+	}
+	return &repositoryScan{
+		repo:     repo,
+		clone:    clone,
+		path:     path,
+		inMem:    inMem,
+		reserved: reserved,
+		log:      log,
+		url:      *repo.CloneURL,
+		pending:  1,
+		dirty:    map[plumbing.Hash]bool{},
+	}
+}
+
+// discard will remove the clone of a repository that is not going to be finished
+func (p *pipeline) discard(scan *repositoryScan, tid int) {
+	where := "disk"
+	if scan.inMem {
+		where = "memory"
+	}
+	if err := os.RemoveAll(scan.path); err != nil {
+		scan.log.Error("[THREAD #%d][%s] Error removing path from %s: %s\n", tid, *scan.repo.CloneURL, where, err)
+	}
+	p.sess.releaseCloneMemory(scan.reserved)
+}
+
+// walk will queue the changes of every commit being scanned in a repository, leaving out the files that are ignored
+func (p *pipeline) walk(scan *repositoryScan, tid int) {
+	sess, repo, log := p.sess, scan.repo, scan.log
+
+	// a repository that was cloned but not walked yet when the scan was stopped is scanned again when it is resumed
+	if sess.Interrupted() {
+		log.Debug("[THREAD #%d][%s] Skipping, the scan has been stopped\n", tid, *repo.CloneURL)
+		p.discard(scan, tid)
+		return
+	}
+
+	repo.codeOwners = LoadCodeOwners(scan.clone)
+
+	var notes map[plumbing.Hash]string
+	var err error
+	if sess.ScanNotes {
+		if notes, err = GetNotes(scan.clone); err != nil {
+			log.Warn("[THREAD #%d][%s] Unable to read git notes: %s\n", tid, *repo.CloneURL, err)
+		}
+	}
+
+	// Get the commit history of each branch being scanned, leaving out what an incremental scan has seen
+	lastCommits := sess.ScanState.LastCommits(repo)
+	heads, err := GetBranchHeads(scan.clone, sess.ScanBranches)
+	var history []*object.Commit
+	since := lastCommits
+	if err == nil {
+		// --since-commit and --until-commit narrow the history down further
+		heads, since, err = sess.CommitRange.Heads(scan.clone, heads, lastCommits)
+	}
+	if err == nil {
+		history, scan.branches, err = GetBranchHistory(scan.clone, heads, since)
+		history = sess.CommitRange.Filter(history)
+	}
+	if err != nil {
+		log.Error("[THREAD #%d][%s] Error getting commit history: %s\n", tid, *repo.CloneURL, err)
+		sess.recordScanError(*repo.FullName, ScanErrorHistory, 1, err)
+		p.discard(scan, tid)
+		return
+	}
+	scan.heads = heads
+	log.Debug("[THREAD #%d][%s] Number of commits: %d in %d %s\n", tid, *repo.CloneURL, len(history), len(heads), Pluralize(len(heads), "branch", "branches"))
+	if len(since) > 0 {
+		log.Debug("[THREAD #%d][%s] Only scanning commits added since %s\n", tid, *repo.CloneURL, strings.Join(since, ", "))
+	}
+
+	p.walkHistory(scan, history, notes, tid)
+	if sess.ScanSubmodules {
+		p.walkSubmodules(scan, tid)
+	}
+	p.done(scan)
+}
+
+// walkHistory will queue the changes of the commits of a repository, and their messages and notes
+func (p *pipeline) walkHistory(scan *repositoryScan, history []*object.Commit, notes map[plumbing.Hash]string, tid int) {
+	sess, log := p.sess, scan.log
+	origins := renameOrigins(scan.clone, history)
+	for _, commit := range history {
+		log.Debug("[THREAD #%d][%s] Analyzing commit: %s\n", tid, scan.url, commit.Hash)
+		sess.Stats.IncrementCommits()
+
+		changes, _ := GetChanges(commit, scan.clone)
+		log.Debug("[THREAD #%d][%s] %s changes in %d\n", tid, scan.url, commit.Hash, len(changes))
+
+		// the objects of the changes are read into memory for the match stage, as a clone can not be read from by
+		// more than one worker at a time, and all of them are read before any is queued for the same reason
+		objects := newChangeObjects()
+		var jobs []*matchJob
+		for _, change := range changes {
+			fPath := GetChangePath(change)
+			fullFilePath := scan.path + "/" + fPath
+			filePath := path.Join(scan.submodule, fPath)
+
+			if p.ignored(scan, change, filePath, fullFilePath) {
+				continue
+			}
+			detached, err := objects.detach(change, scan.clone.Storer)
+			if err != nil {
+				log.Error("Unable to read the file %s in %s: %s\n", filePath, commit.Hash, err)
+				continue
+			}
+			job := &matchJob{scan: scan, commit: commit, change: detached, filePath: filePath, fullFilePath: fullFilePath}
+			if origin, ok := origins[commit.Hash][fPath]; ok {
+				job.originalPath = path.Join(scan.submodule, origin)
+			}
+			jobs = append(jobs, job)
+		}
+		for _, job := range jobs {
+			p.queue(job)
+		}
+
+		// credentials get pasted into commit messages and notes as well as files
+		if note, ok := notes[commit.Hash]; sess.ScanCommitMessages || ok {
+			p.queue(&matchJob{scan: scan, commit: commit, note: note})
+		}
+	}
+}
+
+// walkSubmodules will clone and walk the submodules declared in the head of a repository, and theirs in turn down to
+// --submodule-depth. The history of a submodule is walked from the commit it is pinned at.
+func (p *pipeline) walkSubmodules(scan *repositoryScan, tid int) {
+	sess, log := p.sess, scan.log
+	if scan.depth >= sess.SubmoduleDepth || len(scan.heads) == 0 {
+		return
+	}
+	head, err := scan.clone.CommitObject(scan.heads[0].Hash)
+	if err != nil {
+		return
+	}
+	submodules, err := GetSubmodules(head)
+	if err != nil {
+		log.Warn("[THREAD #%d][%s] Unable to read the submodules: %s\n", tid, scan.url, err)
+		return
+	}
+
+	for _, submodule := range submodules {
+		if sess.Interrupted() {
+			return
+		}
+		cloneURL := ResolveSubmoduleURL(scan.url, submodule.URL)
+		subPath := path.Join(scan.submodule, submodule.Path)
+		subLog := log.With("submodule", subPath)
+		if !AllowedSubmoduleURL(scan.url, cloneURL) {
+			subLog.Warn("[THREAD #%d][%s] Not cloning the submodule %s from %s, only submodules on a server are cloned for a repository on one\n", tid, scan.url, subPath, cloneURL)
+			continue
+		}
+		clone, dir, err := cloneSubmodule(sess, scan.repo, cloneURL, subLog)
+		if err != nil {
+			subLog.Error("Unable to clone the submodule %s from %s: %s\n", subPath, cloneURL, err)
+			sess.recordScanError(*scan.repo.FullName, ScanErrorClone, 1, err)
+			continue
+		}
+		subLog.Debug("[THREAD #%d][%s] Cloned submodule %s to: %s\n", tid, cloneURL, subPath, dir)
+
+		child := &repositoryScan{
+			repo:      scan.repo,
+			clone:     clone,
+			path:      dir,
+			log:       subLog,
+			url:       cloneURL,
+			submodule: subPath,
+			webURL:    submoduleWebURL(cloneURL),
+			depth:     scan.depth + 1,
+			parent:    scan,
+			pending:   1,
+			dirty:     map[plumbing.Hash]bool{},
+		}
+		atomic.AddInt64(&scan.pending, 1)
+
+		// the commit a submodule is pinned at may not have been pushed to a branch, its default branch is scanned then
+		heads := []BranchHead{{Hash: submodule.Commit}}
+		if _, err := clone.CommitObject(submodule.Commit); err != nil {
+			heads, err = GetBranchHeads(clone, nil)
+			if err != nil {
+				subLog.Error("[THREAD #%d][%s] Error getting commit history: %s\n", tid, cloneURL, err)
+				p.discard(child, tid)
+				p.done(scan)
+				continue
+			}
+		}
+		history, branches, err := GetBranchHistory(clone, heads, nil)
+		if err != nil {
+			subLog.Error("[THREAD #%d][%s] Error getting commit history: %s\n", tid, cloneURL, err)
+			sess.recordScanError(*scan.repo.FullName, ScanErrorHistory, 1, err)
+			p.discard(child, tid)
+			p.done(scan)
+			continue
+		}
+		child.heads, child.branches = heads, branches
+		subLog.Debug("[THREAD #%d][%s] Number of commits in submodule %s: %d\n", tid, cloneURL, subPath, len(history))
+
+		p.walkHistory(child, history, nil, tid)
+		p.walkSubmodules(child, tid)
+		p.done(child)
+	}
+}
+
+// ignored will count a changed file and return whether it is left out of the scan
+func (p *pipeline) ignored(scan *repositoryScan, change *object.Change, fPath, fullFilePath string) bool {
+	sess, log := p.sess, scan.log
+	sess.Stats.IncrementFilesTotal()
+
+	if !sess.PathFilter.Scans(fPath) {
+		sess.Stats.IncrementFilesIgnored()
+		log.Debug("%s is outside of the paths being scanned and being ignored\n", fPath)
+		return true
+	}
+
+	// If the file is likely a test then ignore it
+	if !sess.ScanTests && isTestFileOrPath(fullFilePath) {
+		sess.Stats.IncrementFilesIgnored()
+		log.Debug("%s is a test file and being ignored\n", fPath)
+		return true
+	}
+
+	if isMaxChangeSize(fullFilePath, change, sess) {
+		sess.Stats.IncrementFilesIgnored()
+		log.Debug("%s is too large and being ignored\n", fPath)
+		return true
+	}
+
+	// If the file matches a file extension or other method that precludes it from a scan
+	matchFile := newMatchFile(fullFilePath)
+	if matchFile.isSkippable(sess) {
+		sess.Stats.IncrementFilesIgnored()
+		log.Debug("%s is skippable and being ignored\n", fPath)
+		return true
+	}
+	sess.Stats.IncrementFilesTotal()
+
+	// We are now finally at the point where we are going to scan a file
+	sess.Stats.IncrementFilesScanned()
+	return false
+}
+
+// queue will hand a job to the match stage, waiting while its queue is full
+func (p *pipeline) queue(job *matchJob) {
+	atomic.AddInt64(&job.scan.pending, 1)
+	p.changes <- job
+}
+
+// match will run the signatures, and whatever else looks at the content of files, over a job
+func (p *pipeline) match(job *matchJob) {
+	var findings []pipelineFinding
+	if job.change == nil {
+		findings = p.matchCommitText(job)
+	} else {
+		findings = p.matchChange(job)
+	}
+	if len(findings) == 0 {
+		p.done(job.scan)
+		return
+	}
+	p.results <- &matchResult{job: job, findings: findings}
+}
+
+// matchChange will match the file of a change
+func (p *pipeline) matchChange(job *matchJob) []pipelineFinding {
+	sess, scan, repo, commit, change := p.sess, job.scan, job.scan.repo, job.commit, job.change
+	fPath := job.filePath
+	changeAction := GetChangeAction(change)
+	matchFile := newMatchFile(job.fullFilePath)
+
+	var findings []pipelineFinding
+	add := func(found []*Finding) {
+		for _, finding := range found {
+			finding.setRepositoryMetadata(repo)
+			findings = append(findings, pipelineFinding{Finding: finding})
+		}
+	}
+
+	base := Finding{
+		Action:          changeAction,
+		FilePath:        fPath,
+		RepositoryName:  *repo.Name,
+		RepositoryOwner: *repo.Owner,
+		originalPath:    job.originalPath,
+	}
+	base.setCommit(commit)
+	base.setRepositoryMetadata(repo)
+	base.setBranch(scan.branches[commit.Hash], scan.heads)
+	base.setSubmodule(scan.submodule)
+	base.webURL = scan.webURL
+
+	// a binary file has no patch for the content signatures to match, the strings in it are matched instead
+	binary := false
+	if sess.ScanBinaries {
+		before, after, err := changeContents(change)
+		if err != nil {
+			scan.log.Error("Unable to read the file %s in %s: %s\n", fPath, commit.Hash, err)
+		}
+		if binary = isBinaryContent(after); binary {
+			add(scanBinaryStrings(sess, after, before, base))
+		}
+	}
+
+	// for each signature that is loaded scan the file as a whole and generate a map of the match and the line number the match was found on
+	for _, signature := range CurrentSignatures() {
+		if binary && signature.Part() == PartContent {
+			continue
+		}
+
+		bMatched, matchMap := signature.ExtractMatch(matchFile, sess, change)
+		if !bMatched {
+			continue
+		}
+		sess.Stats.IncrementFilesDirty()
+
+		// the matches in the change come before those in the file on disk, so a secret that is in both is reported on the
+		// line it has in the commit rather than the one it has in the checkout
+		keys := make([]string, 0, len(matchMap))
+		for k := range matchMap {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		// for every instance of the secret that matched the specific signatures create a new finding
+		for _, k := range keys {
+			v := matchMap[k]
+			content := strings.SplitAfterN(k, "_", 2)[1]
+			genericID := *repo.Name + "://" + fPath + "_" + generateGenericID(content)
+
+			finding := &Finding{
+				Action:            changeAction,
+				Comment:           Redact(content, sess.Redact),
+				Description:       signature.Description(),
+				FilePath:          fPath,
+				WraithVersion:     version.AppVersion(),
+				LineNumber:        strconv.Itoa(v),
+				RepositoryName:    *repo.Name,
+				RepositoryOwner:   *repo.Owner,
+				Score:             signature.MatchLevel(),
+				Severity:          signature.Severity(),
+				Confidence:        signature.Confidence(),
+				Signatureid:       signature.Signatureid(),
+				SignaturesVersion: sess.SignatureVersion,
+				SecretID:          genericID,
+				secret:            content,
+				webURL:            scan.webURL,
+				originalPath:      job.originalPath,
+			}
+			finding.setCommit(commit)
+			finding.setLine(matchFile.Path, change, v, content, sess)
+
+			// Get a proper uid for the finding
+			finding.Initialize(sess.ScanType)
+			finding.setRepositoryMetadata(repo)
+			finding.setBranch(scan.branches[commit.Hash], scan.heads)
+			finding.setSubmodule(scan.submodule)
+			findings = append(findings, pipelineFinding{Finding: finding, checkCommit: true})
+		}
+	}
+
+	// match the signatures against what the encoded blobs in the file decode to
+	if sess.Decode {
+		add(scanDecoded(sess, pluginContent(job.fullFilePath, change), base))
+	}
+
+	// terraform state is parsed rather than matched, only the values the commit added are flagged
+	if sess.ScanTerraformState && isTerraformState(fPath) {
+		before, after, err := changeContents(change)
+		if err != nil {
+			scan.log.Error("Unable to read the terraform state %s in %s: %s\n", fPath, commit.Hash, err)
+		}
+		add(scanTerraformState(sess, after, before, base))
+	}
+
+	// hand the file off to any external detector plugins the user has loaded
+	if len(sess.DetectorPlugins) > 0 {
+		req := &PluginRequest{
+			CommitHash: commit.Hash.String(),
+			Content:    pluginContent(job.fullFilePath, change),
+			FilePath:   fPath,
+			Repository: *repo.FullName,
+			ScanType:   sess.ScanType,
+		}
+		for _, finding := range RunDetectorPlugins(req, base, sess) {
+			findings = append(findings, pipelineFinding{Finding: finding})
+		}
+	}
+	return findings
+}
+
+// matchCommitText will match the message and the note of a commit
+func (p *pipeline) matchCommitText(job *matchJob) []pipelineFinding {
+	sess, scan, repo, commit := p.sess, job.scan, job.scan.repo, job.commit
+	text := Finding{
+		RepositoryName:  *repo.Name,
+		RepositoryOwner: *repo.Owner,
+	}
+	text.setCommit(commit)
+	text.setBranch(scan.branches[commit.Hash], scan.heads)
+	text.setSubmodule(scan.submodule)
+
+	var findings []pipelineFinding
+	if sess.ScanCommitMessages {
+		text.Action = ActionCommitMessage
+		for _, finding := range matchText(sess, repo, commit.Message, text, scan.webURL) {
+			findings = append(findings, pipelineFinding{Finding: finding})
+		}
+	}
+	if job.note != "" {
+		text.Action = ActionGitNote
+		for _, finding := range matchText(sess, repo, job.note, text, scan.webURL) {
+			findings = append(findings, pipelineFinding{Finding: finding})
+		}
+	}
+	return findings
+}
+
+// report will add the findings of a job to the session
+func (p *pipeline) report(result *matchResult) {
+	sess, scan := p.sess, result.job.scan
+	for _, finding := range result.findings {
+		if finding.checkCommit && sess.hasCommitSecret(finding.Finding) {
+			continue
+		}
+		// Add it to the session unless the finding script drops it
+		if !sess.AddFinding(finding.Finding) {
+			continue
+		}
+		if finding.checkCommit {
+			sess.Stats.IncrementCommits()
+		}
+		scan.Lock()
+		scan.dirty[result.job.commit.Hash] = true
+		scan.Unlock()
+
+		//print realtime data to stdout
+		realTimeOutput(finding.Finding, sess)
+	}
+	p.done(scan)
+}
+
+// hasCommitSecret will check whether the secret of a finding has already been found by the same signature in its commit
+func (s *Session) hasCommitSecret(finding *Finding) bool {
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range s.Findings {
+		if f.CommitHash == finding.CommitHash && f.SecretID == finding.SecretID && f.Description == finding.Description {
+			return true
+		}
+	}
+	return false
+}
+
+// done will let go of a hold on a repository, finishing it when it was the last one
+func (p *pipeline) done(scan *repositoryScan) {
+	if atomic.AddInt64(&scan.pending, -1) == 0 {
+		p.finish(scan)
+	}
+}
+
+// finish will do what is left once the history of a repository has been scanned and remove its clone
+func (p *pipeline) finish(scan *repositoryScan) {
+	sess, repo, log := p.sess, scan.repo, scan.log
+	log.Debug("[%s] Done analyzing commits\n", scan.url)
+
+	// Increment the number of commits that were found to be dirty
+	for range scan.dirty {
+		sess.Stats.IncrementCommitsDirty()
+	}
+
+	// what is left is done for the repository a submodule is in once it is finished itself
+	if scan.parent != nil {
+		sess.markFindingsAt(scan.clone, scan.heads[0].Hash, repo, scan.submodule)
+		if err := os.RemoveAll(scan.path); err != nil {
+			log.Error("Could not remove path from disk: %s", err.Error())
+		}
+		p.done(scan.parent)
+		return
+	}
+
+	// remember how far each branch has been scanned so the next incremental scan starts from here
+	for i, head := range scan.heads {
+		if i == 0 {
+			sess.ScanState.Scanned(repo, head.Hash.String())
+		} else {
+			sess.ScanState.ScannedBranch(repo, head.Name, head.Hash.String())
+		}
+	}
+
+	// record which findings are still present in the latest commit so they can be prioritized, those in submodules have
+	// been marked at the commit the submodule is pinned at
+	sess.MarkFindingsAtHead(scan.clone, repo)
+
+	// a local repository can have secrets that were never committed in its checkout
+	if sess.ScanType == "localGit" && (sess.ScanWorkingTree || sess.ScanStash) {
+		scanUncommitted(sess, repo, 0)
+	}
+
+	// issues and comments are not in the clone, they are read through the api
+	if sess.ScanIssues {
+		scanRepositoryTexts(sess, repo)
+	}
+	if sess.ScanWorkflowLogs {
+		scanWorkflowLogs(sess, repo)
+	}
+	if sess.ScanReleaseAssets {
+		scanReleaseAssets(sess, repo)
+	}
+
+	if err := os.RemoveAll(scan.path); err != nil {
+		log.Error("Could not remove path from disk: %s", err.Error())
+	}
+	log.Debug("[%s] Deleted %s\n", *repo.CloneURL, scan.path)
+	sess.releaseCloneMemory(scan.reserved)
+
+	sess.Stats.IncrementRepositoriesScanned()
+	sess.checkpointRepository(repo)
+	sess.RunRepoCompleteHook(repo)
+	sess.sendSlackRepoSummary(repo)
+	sess.fileGithubIssues(repo)
+}
+
+// changeObjects holds the trees and files the changes of a commit are read from once they are out of the clone
+type changeObjects struct {
+	storage *memory.Storage
+	trees   map[plumbing.Hash]*object.Tree
+}
+
+func newChangeObjects() *changeObjects {
+	return &changeObjects{storage: memory.NewStorage(), trees: map[plumbing.Hash]*object.Tree{}}
+}
+
+// detach will copy the trees and files of a change out of a clone and return the change reading them from the copies
+func (c *changeObjects) detach(change *object.Change, clone storer.EncodedObjectStorer) (*object.Change, error) {
+	detached := &object.Change{From: change.From, To: change.To}
+	for _, entry := range []*object.ChangeEntry{&detached.From, &detached.To} {
+		if entry.Tree == nil {
+			continue
+		}
+		if entry.TreeEntry.Mode.IsFile() {
+			if err := copyObject(clone, c.storage, entry.TreeEntry.Hash); err != nil {
+				return nil, err
+			}
+		}
+		tree, ok := c.trees[entry.Tree.Hash]
+		if !ok {
+			encoded := &plumbing.MemoryObject{}
+			if err := entry.Tree.Encode(encoded); err != nil {
+				return nil, err
+			}
+			var err error
+			if tree, err = object.DecodeTree(c.storage, encoded); err != nil {
+				return nil, err
+			}
+			c.trees[entry.Tree.Hash] = tree
+		}
+		entry.Tree = tree
+	}
+	return detached, nil
+}
+
+// copyObject will read an object out of one storage and write it into another
+func copyObject(from, to storer.EncodedObjectStorer, hash plumbing.Hash) error {
+	if to.HasEncodedObject(hash) == nil {
+		return nil
+	}
+	obj, err := from.EncodedObject(plumbing.AnyObject, hash)
+	if err != nil {
+		return err
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	copied := to.NewEncodedObject()
+	copied.SetType(obj.Type())
+	copied.SetSize(obj.Size())
+	w, err := copied.Writer()
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, err = to.SetEncodedObject(copied)
+	return err
+}
@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"wraith/core"
+)
+
+func TestPipeline(t *testing.T) {
+
+	Convey("Given repositories with a key in the files of several commits", t, func() {
+		var repos []*core.Repository
+		for i, name := range []string{"api", "web", "worker"} {
+			repo := secretRepo(t, int64(i+1), name)
+			defer os.RemoveAll(*repo.CloneURL)
+			dir := *repo.CloneURL
+			for _, file := range []string{"deploy.sh", "settings.ini", "notes.md"} {
+				ioutil.WriteFile(filepath.Join(dir, file), []byte(fmt.Sprintf("key=AKIA%sEXAMPLE%d\n", "IOSFODNN", i)), 0600)
+			}
+			for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "more config"}} {
+				if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %s", args, out)
+				}
+			}
+			repos = append(repos, repo)
+		}
+
+		dir, err := ioutil.TempDir("", "wraith-pipeline")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		sess := scanSession(dir)
+		sess.ScanType = "github"
+		sess.Repositories = repos
+
+		scan := func() []string {
+			core.AnalyzeRepositories(sess)
+			var found []string
+			for _, f := range sess.Findings {
+				found = append(found, f.RepositoryName+"/"+f.FilePath)
+			}
+			sort.Strings(found)
+			return found
+		}
+		expected := []string{
+			"api/config.env", "api/deploy.sh", "api/notes.md", "api/settings.ini",
+			"web/config.env", "web/deploy.sh", "web/notes.md", "web/settings.ini",
+			"worker/config.env", "worker/deploy.sh", "worker/notes.md", "worker/settings.ini",
+		}
+
+		Convey("Every stage running its own number of workers over small queues should find all of them", func() {
+			sess.Threads = 1
+			sess.CloneThreads = 2
+			sess.WalkThreads = 1
+			sess.MatchThreads = 4
+			sess.PipelineQueueSize = 1
+			So(scan(), ShouldResemble, expected)
+			So(sess.Stats.RepositoriesScanned, ShouldEqual, 3)
+			So(sess.Stats.CommitsDirty, ShouldEqual, 6)
+			So(sess.Stats.FilesScanned, ShouldEqual, 12)
+		})
+
+		Convey("The stages that are not set should run as many workers as --num-threads", func() {
+			sess.Threads = 2
+			So(scan(), ShouldResemble, expected)
+			So(sess.Stats.RepositoriesScanned, ShouldEqual, 3)
+		})
+	})
+}
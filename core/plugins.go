@@ -0,0 +1,191 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"wraith/version"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// ExecPlugin is an external executable that is handed a candidate file on stdin and returns any findings on stdout.
+// This allows teams to add their own detection logic without having to fork wraith.
+type ExecPlugin struct {
+	name    string
+	Path    string
+	Timeout time.Duration
+}
+
+// PluginRequest is the json document written to the stdin of a detector plugin for every candidate file
+type PluginRequest struct {
+	CommitHash string `json:"commit_hash"`
+	Content    string `json:"content"`
+	FilePath   string `json:"file_path"`
+	Repository string `json:"repository"`
+	ScanType   string `json:"scan_type"`
+}
+
+// PluginFinding is a single finding reported back by a detector plugin
+type PluginFinding struct {
+	Description string `json:"description"`
+	LineNumber  int    `json:"line_number"`
+	Match       string `json:"match"`
+	Signatureid string `json:"signatureid"`
+}
+
+// PluginResponse is the json document a detector plugin is expected to write to stdout
+type PluginResponse struct {
+	Findings []PluginFinding `json:"findings"`
+}
+
+// NewExecPlugin will validate that the given path is an executable file and create a plugin from it
+func NewExecPlugin(path string, timeout int) (*ExecPlugin, error) {
+	path = SetHomeDir(strings.TrimSpace(path))
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return nil, fmt.Errorf("%s is not an executable file", path)
+	}
+
+	if timeout <= 0 {
+		timeout = 30
+	}
+
+	return &ExecPlugin{
+		name:    filepath.Base(path),
+		Path:    path,
+		Timeout: time.Duration(timeout) * time.Second,
+	}, nil
+}
+
+// Name returns the name of the executable the plugin was loaded from
+func (p *ExecPlugin) Name() string {
+	return p.name
+}
+
+// Scan will execute the plugin, write the request to its stdin and parse any findings from its stdout
+func (p *ExecPlugin) Scan(req *PluginRequest) ([]PluginFinding, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("plugin %s timed out after %s", p.name, p.Timeout)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %s: %s", p.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// a plugin that has nothing to report is allowed to stay quiet
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, nil
+	}
+
+	return parsePluginResponse(p.name, stdout.Bytes())
+}
+
+// parsePluginResponse will decode the output of a plugin and make sure every finding can be traced back to the
+// plugin that reported it
+func parsePluginResponse(name string, output []byte) ([]PluginFinding, error) {
+	var resp PluginResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid json: %s", name, err)
+	}
+
+	for i := range resp.Findings {
+		if resp.Findings[i].Signatureid == "" {
+			resp.Findings[i].Signatureid = "plugin:" + name
+		}
+		if resp.Findings[i].Description == "" {
+			resp.Findings[i].Description = "Finding reported by plugin " + name
+		}
+	}
+	return resp.Findings, nil
+}
+
+// LoadDetectorPlugins will create a plugin for each of the comma separated executables given by the user
+func LoadDetectorPlugins(plugins string, timeout int, sess *Session) []IDetectorPlugin {
+	var loaded []IDetectorPlugin
+	if plugins == "" {
+		return loaded
+	}
+
+	for _, p := range strings.Split(plugins, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		plugin, err := NewExecPlugin(p, timeout)
+		if err != nil {
+			sess.Out.Error("Failed to load detector plugin %s: %s\n", p, err)
+			sess.Out.Exit(2)
+		}
+		loaded = append(loaded, plugin)
+	}
+	return loaded
+}
+
+// pluginContent will get the content of a candidate file to hand to a plugin. A file of a change is read from the blob
+// the commit has, as the file on disk is the one at the head of the branch and is only read when the blob can not be.
+// A file that is not in a commit, as with a directory or a pull request, is read from disk.
+func pluginContent(fullFilePath string, change *object.Change) string {
+	if change != nil {
+		if _, after, err := changeContents(change); err == nil {
+			return after
+		}
+	}
+	if data, err := ioutil.ReadFile(fullFilePath); err == nil {
+		return string(data)
+	}
+	return ""
+}
+
+// RunDetectorPlugins will pass a candidate file through every loaded plugin and turn the results into findings.
+// The findings are not added to the session, this is left to the caller so they can be handled like any other match.
+func RunDetectorPlugins(req *PluginRequest, base Finding, sess *Session) []*Finding {
+	var findings []*Finding
+
+	for _, plugin := range sess.DetectorPlugins {
+		results, err := plugin.Scan(req)
+		if err != nil {
+			sess.Out.Error("%s\n", err)
+			continue
+		}
+
+		for _, r := range results {
+			finding := base
+			finding.Comment = Redact(r.Match, sess.Redact)
+			finding.secret = r.Match
+			finding.Description = r.Description
+			finding.LineNumber = strconv.Itoa(r.LineNumber)
+			finding.Signatureid = r.Signatureid
+			finding.SignaturesVersion = sess.SignatureVersion
+			finding.WraithVersion = version.AppVersion()
+			finding.Initialize(sess.ScanType)
+
+			findings = append(findings, &finding)
+		}
+	}
+	return findings
+}
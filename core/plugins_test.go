@@ -0,0 +1,125 @@
+package core_test
+
+import (
+	"bufio"
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"wraith/core"
+)
+
+// detectorPlugin will write a plugin that records every request it is handed and reports the token it looks for
+func detectorPlugin(dir string) string {
+	path := filepath.Join(dir, "token-detector")
+	ioutil.WriteFile(path, []byte(`#!/bin/sh
+input=$(cat)
+printf "%s\n" "$input" >> "`+filepath.Join(dir, "requests")+`"
+case "$input" in
+*ACME-TOKEN-*) echo '{"findings": [{"match": "ACME-TOKEN-0001", "line_number": 1}]}' ;;
+esac
+`), 0700)
+	return path
+}
+
+func TestDetectorPlugins(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	Convey("Given an executable detector plugin", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-plugins")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		plugin, err := core.NewExecPlugin(detectorPlugin(dir), 5)
+		So(err, ShouldBeNil)
+
+		Convey("The findings it reports should be traced back to it", func() {
+			findings, err := plugin.Scan(&core.PluginRequest{Content: "token ACME-TOKEN-0001", FilePath: "config.yml"})
+			So(err, ShouldBeNil)
+			So(findings, ShouldResemble, []core.PluginFinding{{Match: "ACME-TOKEN-0001", LineNumber: 1,
+				Signatureid: "plugin:token-detector", Description: "Finding reported by plugin token-detector"}})
+		})
+
+		Convey("A plugin with nothing to report should be able to stay quiet", func() {
+			findings, err := plugin.Scan(&core.PluginRequest{Content: "nothing here", FilePath: "config.yml"})
+			So(err, ShouldBeNil)
+			So(findings, ShouldBeEmpty)
+		})
+
+		Convey("A plugin that fails, answers with something that is not json or takes too long should be an error", func() {
+			for _, script := range []string{"echo broken >&2; exit 1", "echo not json", "exec sleep 5"} {
+				path := filepath.Join(dir, "bad")
+				So(ioutil.WriteFile(path, []byte("#!/bin/sh\ncat > /dev/null\n"+script+"\n"), 0700), ShouldBeNil)
+				bad, err := core.NewExecPlugin(path, 1)
+				So(err, ShouldBeNil)
+				_, err = bad.Scan(&core.PluginRequest{Content: "token ACME-TOKEN-0001"})
+				So(err, ShouldNotBeNil)
+			}
+		})
+
+		Convey("A file that can not be run should not be loaded", func() {
+			path := filepath.Join(dir, "notes.txt")
+			So(ioutil.WriteFile(path, []byte("not a plugin"), 0600), ShouldBeNil)
+			_, err := core.NewExecPlugin(path, 5)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a repository with a token that a later commit removed", t, func() {
+		dir := gitRepo(t)
+		defer os.RemoveAll(dir)
+		pluginDir, err := ioutil.TempDir("", "wraith-plugins")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(pluginDir)
+
+		commit := func(content, message string) {
+			So(ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0600), ShouldBeNil)
+			So(exec.Command("git", "-C", dir, "commit", "-q", "-a", "-m", message).Run(), ShouldBeNil)
+		}
+		commit("token ACME-TOKEN-0001\n", "add the token")
+		commit("token from the vault\n", "remove the token")
+
+		sess := scanSession(dir)
+		sess.ScanType = "localGit"
+		sess.Threads = 1
+		sess.InMemClone = false
+		sess.ScanCommitMessages = false
+		plugin, err := core.NewExecPlugin(detectorPlugin(pluginDir), 5)
+		So(err, ShouldBeNil)
+		sess.DetectorPlugins = []core.IDetectorPlugin{plugin}
+		owner, name, branch := "local", "repo", "master"
+		if out, err := exec.Command("git", "-C", dir, "symbolic-ref", "--short", "HEAD").Output(); err == nil {
+			branch = string(out[:len(out)-1])
+		}
+		sess.Repositories = []*core.Repository{{Owner: &owner, Name: &name, FullName: &name, CloneURL: &dir, DefaultBranch: &branch}}
+		core.AnalyzeRepositories(sess)
+
+		Convey("The plugin should be handed the file as each commit has it, not as the checkout has it", func() {
+			f, err := os.Open(filepath.Join(pluginDir, "requests"))
+			So(err, ShouldBeNil)
+			defer f.Close()
+			var contents []string
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var req core.PluginRequest
+				So(json.Unmarshal(scanner.Bytes(), &req), ShouldBeNil)
+				So(req.FilePath, ShouldEqual, "a.txt")
+				So(req.Repository, ShouldEqual, "repo")
+				contents = append(contents, req.Content)
+			}
+			sort.Strings(contents)
+			So(contents, ShouldResemble, []string{"token ACME-TOKEN-0001\n", "token from the vault\n"})
+		})
+
+		Convey("The token should be found in the commit that added it", func() {
+			So(sess.Findings, ShouldHaveLength, 1)
+			So(sess.Findings[0].CommitMessage, ShouldEqual, "add the token")
+			So(sess.Findings[0].Signatureid, ShouldEqual, "plugin:token-detector")
+		})
+	})
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// PolicyTimeout is the longest a policy is allowed to take to evaluate a single finding or scan
+const PolicyTimeout = 5 * time.Second
+
+// PolicyFailedExitCode is the exit code of a silent scan that has been failed by its policy, it is kept apart from
+// the codes used for errors so a pipeline can tell the two apart
+const PolicyFailedExitCode = 3
+
+// These are the rules wraith looks for in the wraith package of a policy, any that are left out are skipped
+const (
+	PolicySuppressRule = "data.wraith.suppress"
+	PolicySeverityRule = "data.wraith.severity"
+	PolicyFailRule     = "data.wraith.fail"
+)
+
+// Policy is a set of rego rules that decide which findings are kept, what their severity is and if a scan passes
+type Policy struct {
+	sync.Mutex
+	path     string
+	suppress rego.PreparedEvalQuery
+	severity rego.PreparedEvalQuery
+	fail     rego.PreparedEvalQuery
+}
+
+// policyFindingInput is what the suppress and severity rules are given as input
+type policyFindingInput struct {
+	Finding    JSONFinding     `json:"finding"`
+	Repository *JSONRepository `json:"repository,omitempty"`
+}
+
+// NewPolicy will load and compile every rego file in path, which can be a single file or a directory. Files ending in
+// _test.rego are skipped.
+func NewPolicy(path string) (*Policy, error) {
+	skipTests := func(abspath string, info os.FileInfo, depth int) bool {
+		return !info.IsDir() && strings.HasSuffix(info.Name(), "_test.rego")
+	}
+
+	p := &Policy{path: path}
+	prepare := func(query string) (rego.PreparedEvalQuery, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), PolicyTimeout)
+		defer cancel()
+		return rego.New(rego.Query(query), rego.Load([]string{path}, skipTests)).PrepareForEval(ctx)
+	}
+
+	var err error
+	if p.suppress, err = prepare(PolicySuppressRule); err != nil {
+		return nil, err
+	}
+	if p.severity, err = prepare(PolicySeverityRule); err != nil {
+		return nil, err
+	}
+	if p.fail, err = prepare(PolicyFailRule); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// eval will run a prepared rule and return its value, or nil when the rule is undefined for the input
+func (p *Policy) eval(query rego.PreparedEvalQuery, input interface{}) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), PolicyTimeout)
+	defer cancel()
+
+	p.Lock()
+	defer p.Unlock()
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, nil
+	}
+	return rs[0].Expressions[0].Value, nil
+}
+
+// Process will run the finding rules of the policy. It returns false if the finding should be suppressed and updates
+// its severity, and its score when the severity is a number, when the policy overrides it.
+func (p *Policy) Process(f *Finding, repo *Repository) (bool, error) {
+	input := policyFindingInput{Finding: NewJSONFinding(f)}
+	if repo != nil {
+		jr := NewJSONRepository(repo, nil)
+		input.Repository = &jr
+	}
+
+	suppress, err := p.eval(p.suppress, input)
+	if err != nil {
+		return true, err
+	}
+	if suppress == true {
+		return false, nil
+	}
+
+	severity, err := p.eval(p.severity, input)
+	if err != nil {
+		return true, err
+	}
+	switch v := severity.(type) {
+	case nil:
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return true, fmt.Errorf("severity must be a whole number, not %s", v)
+		}
+		f.Score = int(n)
+		f.Severity = ScoreSeverity(f.Score)
+	case string:
+		if !ValidSeverity(v) {
+			return true, fmt.Errorf("severity must be one of: %s, not %s", strings.Join(Severities, ", "), v)
+		}
+		f.Severity = v
+	default:
+		return true, fmt.Errorf("severity must be a number or a named severity, not %v", v)
+	}
+	return true, nil
+}
+
+// Evaluate will run the fail rule of the policy against the json report of a finished scan. The rule can either be a
+// boolean or a set of messages, each of which explains why the scan failed.
+func (p *Policy) Evaluate(report JSONReport) ([]string, error) {
+	fail, err := p.eval(p.fail, report)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := fail.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if v {
+			return []string{"failed by " + p.path}, nil
+		}
+		return nil, nil
+	case []interface{}:
+		var messages []string
+		for _, m := range v {
+			messages = append(messages, fmt.Sprint(m))
+		}
+		sort.Strings(messages)
+		return messages, nil
+	default:
+		return nil, fmt.Errorf("fail must be a boolean or a set of messages, not %v", v)
+	}
+}
+
+// findRepository returns the repository a finding was made in, or nil when it was not made in a repository
+func (s *Session) findRepository(f *Finding) *Repository {
+	s.Lock()
+	defer s.Unlock()
+	for _, r := range s.Repositories {
+		if r.Owner != nil && r.Name != nil && *r.Owner == f.RepositoryOwner && *r.Name == f.RepositoryName {
+			return r
+		}
+	}
+	return nil
+}
+
+// EvaluatePolicy will decide if a finished scan passes its policy, if one has been configured
+func (s *Session) EvaluatePolicy() {
+	if s.Policy == nil {
+		return
+	}
+
+	failures, err := s.Policy.Evaluate(NewJSONReport(s))
+	if err != nil {
+		s.Out.Error("Failed to evaluate the policy: %s\n", err)
+		failures = []string{err.Error()}
+	}
+
+	s.Lock()
+	s.PolicyFailures = failures
+	s.Unlock()
+
+	for _, msg := range failures {
+		s.Out.Error("Policy failed: %s\n", msg)
+	}
+	if len(failures) == 0 {
+		s.Out.Important("Policy passed\n")
+	}
+}
@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+const testPolicy = `package wraith
+
+suppress {
+	startswith(input.finding.file_path, "test/")
+}
+
+severity = 5 {
+	input.finding.verification == "verified"
+}
+
+fail[msg] {
+	f := input.findings[_]
+	f.score >= 5
+	startswith(f.signature_id, "aws")
+	msg := sprintf("verified secret in %s", [f.file_path])
+}
+`
+
+func TestPolicy(t *testing.T) {
+
+	Convey("Given a policy", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-policy")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "wraith.rego"), []byte(testPolicy), 0644), ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "wraith_test.rego"), []byte("not rego"), 0644), ShouldBeNil)
+
+		p, err := core.NewPolicy(dir)
+		So(err, ShouldBeNil)
+
+		Convey("Findings matching the suppress rule should be dropped", func() {
+			keep, err := p.Process(&core.Finding{FilePath: "test/fixture.env"}, nil)
+			So(err, ShouldBeNil)
+			So(keep, ShouldBeFalse)
+		})
+
+		Convey("The severity rule should override the score", func() {
+			f := &core.Finding{FilePath: "config.env", Score: 2, Verification: core.VerificationVerified}
+			keep, err := p.Process(f, nil)
+			So(err, ShouldBeNil)
+			So(keep, ShouldBeTrue)
+			So(f.Score, ShouldEqual, 5)
+		})
+
+		Convey("An undefined severity should leave the score alone", func() {
+			f := &core.Finding{FilePath: "config.env", Score: 2}
+			_, err := p.Process(f, nil)
+			So(err, ShouldBeNil)
+			So(f.Score, ShouldEqual, 2)
+		})
+
+		Convey("The fail rule should return its messages", func() {
+			report := core.JSONReport{Findings: []core.JSONFinding{{FilePath: "config.env", Score: 5, SignatureID: "aws-id"}}}
+			failures, err := p.Evaluate(report)
+			So(err, ShouldBeNil)
+			So(failures, ShouldResemble, []string{"verified secret in config.env"})
+
+			report.Findings[0].SignatureID = "github-token"
+			failures, err = p.Evaluate(report)
+			So(err, ShouldBeNil)
+			So(failures, ShouldBeEmpty)
+
+			failures, err = p.Evaluate(core.JSONReport{})
+			So(err, ShouldBeNil)
+			So(failures, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a policy that does not compile", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-policy")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "wraith.rego"), []byte("package wraith\nsuppress {"), 0644), ShouldBeNil)
+
+		_, err = core.NewPolicy(dir)
+		So(err, ShouldNotBeNil)
+	})
+}
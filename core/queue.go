@@ -0,0 +1,510 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// QueueDefaultName is the name the keys of a distributed scan start with unless --queue-name is set
+const QueueDefaultName = "wraith"
+
+// queuePollSeconds is how long a blocking pop waits for the queue, so a scan that has been stopped is noticed
+const queuePollSeconds = 5
+
+// queuePushBatch is the most jobs sent to the queue in a single command
+const queuePushBatch = 500
+
+// queueResultsTTL is how long the results of a scan are kept for a coordinator that has stopped waiting for them
+const queueResultsTTL = 24 * time.Hour
+
+// queueHeartbeat is how often a worker tells the queue it is still alive, and queueWorkerTTL how long it is thought to
+// be once it has stopped, after which the repositories it was scanning are put back on the queue
+const (
+	queueHeartbeat = 10 * time.Second
+	queueWorkerTTL = time.Minute
+)
+
+// QueueScanTypes are the scans whose repositories can be handed to workers, the ones that clone repositories
+var QueueScanTypes = []string{"github", "gitlab", "bitbucket", "bitbucketServer", "azureDevops", "gitea", "localGit"}
+
+// QueueJob is a repository a coordinator has put on the queue for a worker to scan
+type QueueJob struct {
+	ID         string          `json:"id"`
+	Scan       string          `json:"scan"`
+	ScanType   string          `json:"scan_type"`
+	Repository QueueRepository `json:"repository"`
+
+	// Redact is how the coordinator redacts secrets, the worker redacts them the same way before they are put on the
+	// queue. As the secrets do not come back, Verify has the worker verify them in place of the coordinator.
+	Redact string `json:"redact"`
+	Verify bool   `json:"verify,omitempty"`
+}
+
+// QueueRepository is a repository as it is sent to a worker, along with what its provider set that is not exported
+type QueueRepository struct {
+	*Repository
+	WebURL          string `json:"web_url,omitempty"`
+	Gist            bool   `json:"gist,omitempty"`
+	Gitea           bool   `json:"gitea,omitempty"`
+	BitbucketServer bool   `json:"bitbucket_server,omitempty"`
+}
+
+// QueueResult is sent back to the coordinator by a worker, once for each finding in a repository and then once when
+// the repository is done with what it took to scan it. The finding is redacted as the coordinator redacts findings.
+type QueueResult struct {
+	Job     string      `json:"job"`
+	Worker  string      `json:"worker"`
+	Finding *Finding    `json:"finding,omitempty"`
+	Done    bool        `json:"done,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Stats   *QueueStats `json:"stats,omitempty"`
+}
+
+// QueueStats are the stats of scanning a single repository, added to those of the coordinator
+type QueueStats struct {
+	Commits             int `json:"commits"`
+	CommitsDirty        int `json:"commits_dirty"`
+	FilesDirty          int `json:"files_dirty"`
+	FilesIgnored        int `json:"files_ignored"`
+	FilesScanned        int `json:"files_scanned"`
+	FilesTotal          int `json:"files_total"`
+	FindingsSuppressed  int `json:"findings_suppressed"`
+	RepositoriesCloned  int `json:"repositories_cloned"`
+	RepositoriesScanned int `json:"repositories_scanned"`
+}
+
+// queueJobsKey is the list the jobs of every coordinator using a queue name are pushed to
+func queueJobsKey(name string) string {
+	return name + ":jobs"
+}
+
+// queueResultsKey is the list the workers push the results of a single scan to
+func queueResultsKey(name, scan string) string {
+	return name + ":results:" + scan
+}
+
+// queueWorkersKey is the set of the workers taking jobs off a queue
+func queueWorkersKey(name string) string {
+	return name + ":workers"
+}
+
+// queueWorkerKey is the heartbeat of a worker, which expires once the worker has stopped sending it
+func queueWorkerKey(name, worker string) string {
+	return name + ":worker:" + worker
+}
+
+// queueProcessingKey is the list of the jobs a worker has taken and not finished yet
+func queueProcessingKey(name, worker string) string {
+	return name + ":processing:" + worker
+}
+
+// newRandomID returns a random id for a session, which is also the id of its distributed scan so coordinators sharing a
+// queue get their own results
+func newRandomID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// newQueueStats will take the stats of a repository from the stats of the session that scanned it
+func newQueueStats(s *Stats) *QueueStats {
+	s.Lock()
+	defer s.Unlock()
+	return &QueueStats{
+		Commits:             s.Commits,
+		CommitsDirty:        s.CommitsDirty,
+		FilesDirty:          s.FilesDirty,
+		FilesIgnored:        s.FilesIgnored,
+		FilesScanned:        s.FilesScanned,
+		FilesTotal:          s.FilesTotal,
+		FindingsSuppressed:  s.FindingsSuppressed,
+		RepositoriesCloned:  s.RepositoriesCloned,
+		RepositoriesScanned: s.RepositoriesScanned,
+	}
+}
+
+// addQueueStats will add the stats a worker sent for a repository to those of the session
+func (s *Stats) addQueueStats(q *QueueStats) {
+	s.Lock()
+	defer s.Unlock()
+	s.Commits += q.Commits
+	s.CommitsDirty += q.CommitsDirty
+	s.FilesDirty += q.FilesDirty
+	s.FilesIgnored += q.FilesIgnored
+	s.FilesScanned += q.FilesScanned
+	s.FilesTotal += q.FilesTotal
+	s.Files += q.FilesTotal
+	s.FindingsSuppressed += q.FindingsSuppressed
+	s.RepositoriesCloned += q.RepositoriesCloned
+	s.RepositoriesScanned += q.RepositoriesScanned
+}
+
+// queuedRepository is a repository a coordinator is waiting on a worker for
+type queuedRepository struct {
+	repo    *Repository
+	payload string
+}
+
+// DistributeRepositories will put the repositories of a session on the queue for workers to clone and scan, and wait
+// for their findings. The findings go through the session as if it had found them itself, so baselines, policies,
+// hooks and every output work the same as in a scan on a single host.
+func DistributeRepositories(sess *Session) {
+	client, err := DialRedis(sess.QueueURL)
+	if err != nil {
+		sess.Out.Fatal("Unable to connect to the queue: %s\n", err)
+	}
+	defer client.Close()
+
+	scan := sess.ID
+	if scan == "" {
+		scan = newRandomID()
+	}
+	jobs := queueJobsKey(sess.QueueName)
+	results := queueResultsKey(sess.QueueName, scan)
+
+	pending := map[string]*queuedRepository{}
+	var payloads []string
+	for i, repo := range sess.Repositories {
+		job := QueueJob{
+			ID:       strconv.Itoa(i),
+			Scan:     scan,
+			ScanType: sess.ScanType,
+			Repository: QueueRepository{Repository: repo, WebURL: repo.webURL, Gist: repo.gist, Gitea: repo.gitea,
+				BitbucketServer: repo.bitbucketServer},
+			Redact: sess.Redact,
+			Verify: sess.Verifier != nil,
+		}
+		payload, err := json.Marshal(job)
+		if err != nil {
+			sess.Out.Error("Unable to queue %s: %s\n", *repo.FullName, err)
+			continue
+		}
+		pending[job.ID] = &queuedRepository{repo: repo, payload: string(payload)}
+		payloads = append(payloads, string(payload))
+	}
+	for len(payloads) > 0 {
+		n := len(payloads)
+		if n > queuePushBatch {
+			n = queuePushBatch
+		}
+		if _, err := client.Do(append([]string{"RPUSH", jobs}, payloads[:n]...)...); err != nil {
+			sess.Out.Fatal("Unable to queue the repositories: %s\n", err)
+		}
+		payloads = payloads[n:]
+	}
+	defer client.Do("DEL", results)
+
+	sess.Out.Important("Queued %d %s for the workers on %s\n", len(pending), Pluralize(len(pending), "repository", "repositories"), jobs)
+
+	// the repositories no worker has taken yet are taken back off the queue when the scan stops waiting for them
+	withdraw := func() {
+		for id, q := range pending {
+			if n, err := client.Do("LREM", jobs, "0", q.payload); err == nil && n != int64(0) {
+				delete(pending, id)
+			}
+		}
+	}
+
+	withdrawn := false
+	heard := time.Now()
+	for len(pending) > 0 {
+		if sess.Interrupted() && !withdrawn {
+			withdrawn = true
+			withdraw()
+			if len(pending) > 0 {
+				sess.Out.Warn("Waiting for the workers to finish %d %s\n", len(pending), Pluralize(len(pending), "repository", "repositories"))
+			}
+			continue
+		}
+
+		reply, err := client.BLPop(results, queuePollSeconds)
+		if err == ErrRedisNil {
+			if sess.QueueTimeout > 0 && time.Since(heard) > sess.QueueTimeout {
+				withdraw()
+				sess.Out.Error("No word from the workers in %s, %d %s were not scanned\n", sess.QueueTimeout, len(pending), Pluralize(len(pending), "repository", "repositories"))
+				return
+			}
+			continue
+		}
+		if err != nil {
+			sess.Out.Error("Unable to read the results from the queue, %d %s were not scanned: %s\n", len(pending), Pluralize(len(pending), "repository", "repositories"), err)
+			return
+		}
+		heard = time.Now()
+
+		var result QueueResult
+		if err := json.Unmarshal([]byte(reply), &result); err != nil {
+			sess.Out.Error("Unable to read a result from the queue: %s\n", err)
+			continue
+		}
+		q, ok := pending[result.Job]
+		if !ok {
+			continue
+		}
+
+		if f := result.Finding; f != nil {
+			if sess.AddFinding(f) {
+				realTimeOutput(f, sess)
+			}
+		}
+		if result.Error != "" {
+			sess.Out.Error("[%s][%s] %s\n", result.Worker, *q.repo.CloneURL, result.Error)
+		}
+		if result.Done {
+			if result.Stats != nil {
+				sess.Stats.addQueueStats(result.Stats)
+			}
+			sess.Out.Debug("[%s][%s] Done\n", result.Worker, *q.repo.CloneURL)
+			delete(pending, result.Job)
+			sess.RunRepoCompleteHook(q.repo)
+			sess.sendSlackRepoSummary(q.repo)
+			sess.fileGithubIssues(q.repo)
+		}
+	}
+}
+
+// Worker takes repositories off the queue of distributed scans one at a time, scans them and sends back what it
+// found. The signatures, credentials and scan options are those of its own config.
+type Worker struct {
+	ID     string
+	Client *RedisClient
+	Config *viper.Viper
+	Name   string
+	Out    *Logger
+
+	// sessions are kept for each scan type, so the signatures and api clients are only set up once
+	sessions map[string]*Session
+	// verify is the verifier of the jobs that are verified, it is only set up once one is
+	verify *Verifier
+}
+
+// NewWorker will connect a worker to the queue set in its config
+func NewWorker(v *viper.Viper, out *Logger) (*Worker, error) {
+	client, err := DialRedis(v.GetString("queue-url"))
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+
+	// findings are sent back as they are found and the coordinator does everything else with them
+	v.Set("silent", true)
+
+	return &Worker{
+		ID:       fmt.Sprintf("%s-%d", host, os.Getpid()),
+		Client:   client,
+		Config:   v,
+		Name:     v.GetString("queue-name"),
+		Out:      out,
+		sessions: map[string]*Session{},
+	}, nil
+}
+
+// Run will scan the repositories put on the queue until stop is closed, finishing the repository it is scanning first.
+// A job stays in the processing list of the worker until its results are sent, and the jobs of workers that have
+// stopped sending their heartbeat are put back on the queue, so a worker that dies does not lose what it was scanning.
+func (w *Worker) Run(stop <-chan struct{}) error {
+	defer w.Client.Close()
+	jobs := queueJobsKey(w.Name)
+	processing := queueProcessingKey(w.Name, w.ID)
+
+	// the heartbeat is there before the worker is listed, so it is never taken for a worker that has died
+	if err := w.heartbeat(); err != nil {
+		return err
+	}
+	if _, err := w.Client.Do("SADD", queueWorkersKey(w.Name), w.ID); err != nil {
+		return err
+	}
+	defer func() {
+		w.Client.Do("SREM", queueWorkersKey(w.Name), w.ID)
+		w.Client.Do("DEL", queueWorkerKey(w.Name, w.ID))
+	}()
+
+	beating := make(chan struct{})
+	defer close(beating)
+	go func() {
+		for {
+			select {
+			case <-beating:
+				return
+			case <-time.After(queueHeartbeat):
+				if err := w.heartbeat(); err != nil {
+					w.Out.Error("Unable to send the heartbeat of the worker: %s\n", err)
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		if err := w.requeue(); err != nil {
+			return err
+		}
+		reply, err := w.Client.BLMove(jobs, processing, queuePollSeconds)
+		if err == ErrRedisNil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var job QueueJob
+		if err := json.Unmarshal([]byte(reply), &job); err != nil || job.Repository.Repository == nil {
+			w.Out.Error("Unable to read a job from the queue: %v\n", err)
+		} else if err := w.scan(&job); err != nil {
+			return err
+		}
+		if _, err := w.Client.Do("LREM", processing, "1", reply); err != nil {
+			return err
+		}
+	}
+}
+
+// heartbeat will tell the queue the worker is still alive for another queueWorkerTTL
+func (w *Worker) heartbeat() error {
+	_, err := w.Client.Do("SET", queueWorkerKey(w.Name, w.ID), strconv.FormatInt(time.Now().Unix(), 10), "EX", strconv.Itoa(int(queueWorkerTTL.Seconds())))
+	return err
+}
+
+// requeue will put the jobs of the workers whose heartbeat has expired back on the front of the queue, so a repository
+// a worker died part way through is scanned by another
+func (w *Worker) requeue() error {
+	reply, err := w.Client.Do("SMEMBERS", queueWorkersKey(w.Name))
+	if err != nil {
+		return err
+	}
+	workers, _ := reply.([]interface{})
+	for _, item := range workers {
+		id, _ := item.(string)
+		if id == "" || id == w.ID {
+			continue
+		}
+		alive, err := w.Client.Do("EXISTS", queueWorkerKey(w.Name, id))
+		if err != nil {
+			return err
+		}
+		if alive != int64(0) {
+			continue
+		}
+
+		n := 0
+		for {
+			_, err := w.Client.Do("LMOVE", queueProcessingKey(w.Name, id), queueJobsKey(w.Name), "RIGHT", "LEFT")
+			if err == ErrRedisNil {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			n++
+		}
+		if _, err := w.Client.Do("SREM", queueWorkersKey(w.Name), id); err != nil {
+			return err
+		}
+		if n > 0 {
+			w.Out.Warn("Worker %s stopped part way through, %d %s put back on the queue\n", id, n, Pluralize(n, "repository was", "repositories were"))
+		}
+	}
+	return nil
+}
+
+// scan will scan the repository of a job and push its findings, and then that it is done, to the results of its scan
+func (w *Worker) scan(job *QueueJob) error {
+	repo := job.Repository.Repository
+	repo.webURL = job.Repository.WebURL
+	repo.gist = job.Repository.Gist
+	repo.gitea = job.Repository.Gitea
+	repo.bitbucketServer = job.Repository.BitbucketServer
+	done := QueueResult{Job: job.ID, Worker: w.ID, Done: true}
+	var payloads []string
+
+	if !queueScanType(job.ScanType) {
+		done.Error = fmt.Sprintf("%s scans can not be run by a worker", job.ScanType)
+	} else {
+		log := w.Out.With("session", job.Scan).With("repo", *repo.FullName)
+		log.Info("Scanning %s\n", *repo.CloneURL)
+		sess := w.session(job.ScanType)
+		// the logs of the worker carry the id of the session on the coordinator the repository is scanned for
+		sess.ID = job.Scan
+		sess.Out.SetField("session", job.Scan)
+		// the findings are redacted here, so the secrets are never put on the queue
+		sess.Redact = job.Redact
+		if !ValidRedactMode(sess.Redact) {
+			sess.Redact = RedactFull
+		}
+		sess.Verifier = nil
+		if job.Verify {
+			sess.Verifier = w.verifier()
+		}
+		sess.Findings = nil
+		sess.findingIndex = nil
+		sess.Repositories = []*Repository{repo}
+		sess.Stats = nil
+		sess.InitStats()
+
+		runPipeline(sess, []*Repository{repo})
+
+		for _, f := range sess.Findings {
+			payload, err := json.Marshal(QueueResult{Job: job.ID, Worker: w.ID, Finding: f})
+			if err != nil {
+				return err
+			}
+			payloads = append(payloads, string(payload))
+		}
+		done.Stats = newQueueStats(sess.Stats)
+		log.Info("Scanned %s, %d %s\n", *repo.CloneURL, len(sess.Findings), Pluralize(len(sess.Findings), "finding", "findings"))
+	}
+
+	payload, err := json.Marshal(done)
+	if err != nil {
+		return err
+	}
+	payloads = append(payloads, string(payload))
+	results := queueResultsKey(w.Name, job.Scan)
+	if _, err := w.Client.Do(append([]string{"RPUSH", results}, payloads...)...); err != nil {
+		return err
+	}
+	_, err = w.Client.Do("EXPIRE", results, strconv.Itoa(int(queueResultsTTL.Seconds())))
+	return err
+}
+
+// session returns the session of the worker for a scan type, setting it up the first time it is needed
+func (w *Worker) session(scanType string) *Session {
+	if sess, ok := w.sessions[scanType]; ok {
+		return sess
+	}
+	sess := NewSession(w.Config, scanType)
+	w.sessions[scanType] = sess
+	return sess
+}
+
+// verifier returns the verifier the secrets of jobs are verified with, setting it up the first time it is needed
+func (w *Worker) verifier() *Verifier {
+	if w.verify == nil {
+		w.verify = NewVerifier(nil)
+	}
+	return w.verify
+}
+
+// queueScanType will check if the repositories of a scan type can be handed to workers
+func queueScanType(scanType string) bool {
+	for _, t := range QueueScanTypes {
+		if scanType == t {
+			return true
+		}
+	}
+	return false
+}
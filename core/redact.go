@@ -0,0 +1,118 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// These are the ways a secret can be redacted before it is written to any output
+const (
+	RedactNone    = "none"
+	RedactFull    = "full"
+	RedactPartial = "partial"
+	RedactHash    = "hash"
+	RedactFormat  = "format"
+)
+
+// RedactMask is what replaces a secret, or all but the ends of it, when it is masked
+const RedactMask = "********"
+
+// RedactPartialKeep is how many characters partial redaction keeps at each end of a secret
+const RedactPartialKeep = 4
+
+// RedactModes is every supported redaction mode
+var RedactModes = []string{RedactNone, RedactFull, RedactPartial, RedactHash, RedactFormat}
+
+// ValidRedactMode will check if the given mode is one that wraith knows how to apply
+func ValidRedactMode(mode string) bool {
+	for _, m := range RedactModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// redactStrictness ranks the redaction modes by how much of a secret they hide. Hash and format hide different things,
+// so neither is stricter than the other.
+var redactStrictness = map[string]int{RedactNone: 0, RedactPartial: 1, RedactFormat: 2, RedactHash: 2, RedactFull: 3}
+
+// atLeastAsStrict will check if a redaction mode hides at least as much of a secret as another
+func atLeastAsStrict(mode, than string) bool {
+	return mode == than || redactStrictness[mode] > redactStrictness[than]
+}
+
+// effectiveRedaction is the mode secrets are redacted with once hide-secrets is applied, which hides them fully when
+// nothing else was asked for
+func effectiveRedaction(mode string, hideSecrets bool) string {
+	if mode == "" {
+		mode = RedactNone
+	}
+	if hideSecrets && mode == RedactNone {
+		return RedactFull
+	}
+	return mode
+}
+
+// Redact will hide a secret using the given mode:
+//
+//	none     the secret is left as is
+//	full     the secret is replaced by a fixed mask so even its length is hidden
+//	partial  the first and last 4 characters are kept so a secret can be matched to a known credential, only the last
+//	         4 for secrets shorter than 16 characters and none for those of 8 or less, so at least half is hidden
+//	hash     the secret is replaced by its sha256 so the same secret can be tracked across findings
+//	format   letters and digits are replaced by x, X and 0 so the shape of the secret is kept
+func Redact(secret string, mode string) string {
+	if secret == "" {
+		return ""
+	}
+
+	switch mode {
+	case RedactFull:
+		return RedactMask
+	case RedactPartial:
+		runes := []rune(secret)
+		n := len(runes)
+		switch {
+		case n <= 2*RedactPartialKeep:
+			return RedactMask
+		case n < 4*RedactPartialKeep:
+			return RedactMask + string(runes[n-RedactPartialKeep:])
+		}
+		return string(runes[:RedactPartialKeep]) + RedactMask + string(runes[n-RedactPartialKeep:])
+	case RedactHash:
+		return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(secret)))
+	case RedactFormat:
+		return strings.Map(func(r rune) rune {
+			switch {
+			case unicode.IsDigit(r):
+				return '0'
+			case unicode.IsUpper(r):
+				return 'X'
+			case unicode.IsLetter(r):
+				return 'x'
+			}
+			return r
+		}, secret)
+	}
+	return secret
+}
+
+// redactContent will replace every secret the session has found with its redacted form. It is used for any raw
+// content, such as a file shown in the web interface, that may contain a secret outside of a finding.
+func (s *Session) redactContent(content string) string {
+	if s.Redact == RedactNone {
+		return content
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range s.Findings {
+		if f.secret != "" {
+			content = strings.ReplaceAll(content, f.secret, Redact(f.secret, s.Redact))
+		}
+	}
+	return content
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisDefaultPort is used when a queue url does not have a port
+const RedisDefaultPort = "6379"
+
+// redisDialTimeout is how long connecting to redis, and a reply to anything but a blocking command, can take
+const redisDialTimeout = 10 * time.Second
+
+// ErrRedisNil is returned for a nil reply, such as from a blocking pop that timed out
+var ErrRedisNil = errors.New("redis: nil")
+
+// RedisError is an error reply from redis
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisClient is a connection to redis that sends one command at a time. Only what the distributed queue needs is
+// supported: commands are sent as arrays of strings and replies are read back as strings, integers and arrays.
+type RedisClient struct {
+	sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// DialRedis will connect to redis at a url such as redis://:password@host:6379/0, using tls for rediss:// urls. The
+// password and database are sent once connected when the url has them.
+func DialRedis(rawURL string) (*RedisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("the queue url must start with redis:// or rediss://, not %s://", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), RedisDefaultPort)
+	}
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var conn net.Conn
+	if u.Scheme == "rediss" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	} else {
+		conn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &RedisClient{conn: conn, r: bufio.NewReader(conn)}
+
+	if password, ok := u.User.Password(); ok {
+		args := []string{"AUTH", password}
+		if user := u.User.Username(); user != "" {
+			args = []string{"AUTH", user, password}
+		}
+		if _, err := c.Do(args...); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" && db != "0" {
+		if _, err := c.Do("SELECT", db); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Close will close the connection
+func (c *RedisClient) Close() error {
+	return c.conn.Close()
+}
+
+// Do will send a command and wait for its reply
+func (c *RedisClient) Do(args ...string) (interface{}, error) {
+	return c.DoTimeout(redisDialTimeout, args...)
+}
+
+// DoTimeout will send a command and wait up to a timeout for its reply, which must be longer than the timeout of a
+// blocking command
+func (c *RedisClient) DoTimeout(timeout time.Duration, args ...string) (interface{}, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	c.conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := io.WriteString(c.conn, cmd.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.r)
+}
+
+// readRedisReply will read a reply in the redis protocol. Bulk strings are returned as strings, integers as int64 and
+// arrays as []interface{}, and a nil reply as ErrRedisNil.
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrRedisNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrRedisNil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readRedisReply(r)
+			if err != nil && err != ErrRedisNil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply %q", line)
+}
+
+// BLPop will wait up to a number of seconds for an item to be pushed to a list and take it off the front of the list,
+// returning ErrRedisNil when there was none
+func (c *RedisClient) BLPop(key string, seconds int) (string, error) {
+	reply, err := c.DoTimeout(time.Duration(seconds)*time.Second+redisDialTimeout, "BLPOP", key, strconv.Itoa(seconds))
+	if err != nil {
+		return "", err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return "", fmt.Errorf("redis: unexpected reply to BLPOP %v", reply)
+	}
+	item, ok := items[1].(string)
+	if !ok {
+		return "", fmt.Errorf("redis: unexpected reply to BLPOP %v", reply)
+	}
+	return item, nil
+}
+
+// BLMove will wait up to a number of seconds for an item to be pushed to a list and move it from the front of that
+// list to the back of another, returning ErrRedisNil when there was none. The item stays in the other list until it is
+// removed, so it is not lost when whoever took it dies before it is done with it.
+func (c *RedisClient) BLMove(src, dst string, seconds int) (string, error) {
+	reply, err := c.DoTimeout(time.Duration(seconds)*time.Second+redisDialTimeout, "BLMOVE", src, dst, "LEFT", "RIGHT", strconv.Itoa(seconds))
+	if err != nil {
+		return "", err
+	}
+	item, ok := reply.(string)
+	if !ok {
+		return "", fmt.Errorf("redis: unexpected reply to BLMOVE %v", reply)
+	}
+	return item, nil
+}
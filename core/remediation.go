@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remediation is how to deal with the kind of secret a signature finds, set with remediation in the signature file. It
+// is carried into every finding the signature makes, so whoever fixes the finding knows where to rotate the secret
+// and where to keep the new one.
+type Remediation struct {
+	RotationURL string `yaml:"rotation-url,omitempty" json:"rotation_url,omitempty"`
+	DocsURL     string `yaml:"docs-url,omitempty" json:"docs_url,omitempty"`
+	Vault       string `yaml:"vault,omitempty" json:"vault,omitempty"`
+}
+
+// validate will check that the links of a remediation are http or https urls, as they are shown as links in the
+// reports and the web interface
+func (r *Remediation) validate() error {
+	if r == nil {
+		return nil
+	}
+	for name, link := range map[string]string{"rotation-url": r.RotationURL, "docs-url": r.DocsURL} {
+		if link == "" {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("the %s %s is not an http or https url", name, link)
+		}
+	}
+	return nil
+}
+
+// empty reports whether a remediation has nothing in it, which is left out of findings
+func (r *Remediation) empty() bool {
+	return r == nil || (r.RotationURL == "" && r.DocsURL == "" && r.Vault == "")
+}
+
+// Solution will describe a remediation in a few sentences, for outputs that only take text
+func (r *Remediation) Solution() string {
+	if r.empty() {
+		return ""
+	}
+	var sentences []string
+	if r.RotationURL != "" {
+		sentences = append(sentences, "Rotate it at "+r.RotationURL+".")
+	}
+	if r.Vault != "" {
+		sentences = append(sentences, strings.TrimSuffix(r.Vault, ".")+".")
+	}
+	if r.DocsURL != "" {
+		sentences = append(sentences, "See "+r.DocsURL+".")
+	}
+	return strings.Join(sentences, " ")
+}
+
+// signatureRemediation returns the remediation of the signature in use with the id, or nil when it has none
+func signatureRemediation(signatureid string) *Remediation {
+	if signatureid == "" {
+		return nil
+	}
+	for _, signature := range CurrentSignatures() {
+		if signature.Signatureid() == signatureid {
+			if r := signature.Remediation(); !r.empty() {
+				return r
+			}
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepositoryFilterConfig holds the settings that scope which of the repositories of a target are scanned
+type RepositoryFilterConfig struct {
+	IncludeRepos    string
+	ExcludeRepos    string
+	ExcludeArchived bool
+	OnlyPrivate     bool
+	OnlyPublic      bool
+	Languages       []string
+	Topics          []string
+	PushedAfter     string
+}
+
+// RepositoryFilter leaves out repositories as they are gathered from a target, before anything is cloned, by their
+// name and the attributes the provider returns for them
+type RepositoryFilter struct {
+	Include         *regexp.Regexp
+	Exclude         *regexp.Regexp
+	ExcludeArchived bool
+	Visibility      string // VisibilityPublic or VisibilityPrivate, where private takes in internal repositories too
+	Languages       []string
+	Topics          []string
+	PushedAfter     time.Time
+}
+
+// NewRepositoryFilter will create a filter from its settings, there is no filter when none of them are set
+func NewRepositoryFilter(config RepositoryFilterConfig, now time.Time) (*RepositoryFilter, error) {
+	f := &RepositoryFilter{ExcludeArchived: config.ExcludeArchived}
+	var err error
+
+	if config.IncludeRepos != "" {
+		if f.Include, err = regexp.Compile(config.IncludeRepos); err != nil {
+			return nil, fmt.Errorf("include-repos is not a valid regular expression: %s", err)
+		}
+	}
+	if config.ExcludeRepos != "" {
+		if f.Exclude, err = regexp.Compile(config.ExcludeRepos); err != nil {
+			return nil, fmt.Errorf("exclude-repos is not a valid regular expression: %s", err)
+		}
+	}
+
+	switch {
+	case config.OnlyPrivate && config.OnlyPublic:
+		return nil, fmt.Errorf("only-private and only-public can not be used together")
+	case config.OnlyPrivate:
+		f.Visibility = VisibilityPrivate
+	case config.OnlyPublic:
+		f.Visibility = VisibilityPublic
+	}
+
+	for _, l := range config.Languages {
+		if l = strings.TrimSpace(l); l != "" {
+			f.Languages = append(f.Languages, l)
+		}
+	}
+	for _, t := range config.Topics {
+		if t = strings.TrimSpace(t); t != "" {
+			f.Topics = append(f.Topics, t)
+		}
+	}
+
+	if config.PushedAfter != "" {
+		if f.PushedAfter, err = parsePushedAfter(config.PushedAfter, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Include == nil && f.Exclude == nil && !f.ExcludeArchived && f.Visibility == "" && len(f.Languages) == 0 &&
+		len(f.Topics) == 0 && f.PushedAfter.IsZero() {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// parsePushedAfter will read a date such as 2020-08-03, a time in RFC3339, or a number of days before now such as 90d
+func parsePushedAfter(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("pushed-after must be a date such as 2020-08-03, a time in RFC3339, or a number of days such as 90d")
+}
+
+// containsFold will check if any of the values is one of the wanted values, ignoring case
+func containsFold(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(v, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipAttributes returns why a repository is left out by everything but its language, or nothing when it is not
+func (f *RepositoryFilter) skipAttributes(repo *Repository) string {
+	name := *repo.Owner + "/" + *repo.Name
+	switch {
+	case f.Include != nil && !f.Include.MatchString(name):
+		return "it does not match include-repos"
+	case f.Exclude != nil && f.Exclude.MatchString(name):
+		return "it matches exclude-repos"
+	case f.ExcludeArchived && repo.Archived != nil && *repo.Archived:
+		return "it is archived"
+	case f.Visibility == VisibilityPublic && (repo.Visibility == nil || *repo.Visibility != VisibilityPublic):
+		return "it is not public"
+	case f.Visibility == VisibilityPrivate && (repo.Visibility == nil || *repo.Visibility == VisibilityPublic):
+		return "it is public"
+	case len(f.Topics) > 0 && !containsFold(repo.Topics, f.Topics):
+		return "it has none of the topics"
+	case !f.PushedAfter.IsZero() && (repo.PushedAt == nil || repo.PushedAt.Before(f.PushedAfter)):
+		return "it was last pushed to before " + f.PushedAfter.Format("2006-01-02")
+	}
+	return ""
+}
+
+// skipLanguage returns why a repository is left out by its language, or nothing when it is not
+func (f *RepositoryFilter) skipLanguage(repo *Repository) string {
+	if len(f.Languages) > 0 && !containsFold(repo.Languages, f.Languages) {
+		return "it is not in any of the languages"
+	}
+	return ""
+}
+
+// Skip returns why a repository is left out by the filter, or nothing when it is scanned
+func (f *RepositoryFilter) Skip(repo *Repository) string {
+	if reason := f.skipAttributes(repo); reason != "" {
+		return reason
+	}
+	return f.skipLanguage(repo)
+}
+
+// skipRepository returns why a gathered repository is left out by the repository filters of the session, or nothing
+// when it is scanned. The languages of a repository are only looked up once it passes every other filter, for the
+// providers that take a request for each repository to list them.
+func (s *Session) skipRepository(repo *Repository) string {
+	f := s.RepoFilter
+	if f == nil {
+		return ""
+	}
+	if reason := f.skipAttributes(repo); reason != "" {
+		return reason
+	}
+
+	if len(f.Languages) > 0 && repo.Languages == nil {
+		if lister, ok := s.Client.(interface {
+			GetRepositoryLanguages(repo *Repository) ([]string, error)
+		}); ok {
+			languages, err := lister.GetRepositoryLanguages(repo)
+			if err != nil {
+				s.Out.Error(" Failed to retrieve the languages of %s: %s\n", *repo.FullName, err)
+			}
+			repo.Languages = languages
+		}
+	}
+	return f.skipLanguage(repo)
+}
@@ -0,0 +1,140 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+// filterRepository is a repository with the attributes the repository filters look at
+func filterRepository(owner, name, visibility string, archived bool, pushedAt time.Time, topics, languages []string) *core.Repository {
+	id := int64(len(owner + name))
+	fullName := owner + "/" + name
+	return &core.Repository{
+		Owner:      &owner,
+		ID:         &id,
+		Name:       &name,
+		FullName:   &fullName,
+		CloneURL:   &fullName,
+		Visibility: &visibility,
+		Archived:   &archived,
+		PushedAt:   &pushedAt,
+		Topics:     topics,
+		Languages:  languages,
+	}
+}
+
+func TestRepositoryFilter(t *testing.T) {
+	now := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+
+	Convey("Given the repositories of an organization", t, func() {
+		api := filterRepository("acme", "api", core.VisibilityPrivate, false, now.AddDate(0, 0, -2), []string{"payments"}, []string{"Go"})
+		site := filterRepository("acme", "site", core.VisibilityPublic, false, now.AddDate(0, -1, 0), []string{"web"}, []string{"JavaScript"})
+		legacy := filterRepository("acme", "legacy-api", core.VisibilityInternal, true, now.AddDate(-2, 0, 0), nil, []string{"Java"})
+		repos := []*core.Repository{api, site, legacy}
+
+		kept := func(config core.RepositoryFilterConfig) []string {
+			f, err := core.NewRepositoryFilter(config, now)
+			So(err, ShouldBeNil)
+			So(f, ShouldNotBeNil)
+			names := []string{}
+			for _, r := range repos {
+				if f.Skip(r) == "" {
+					names = append(names, *r.Name)
+				}
+			}
+			return names
+		}
+
+		Convey("Repositories should be included and excluded by their owner and name", func() {
+			So(kept(core.RepositoryFilterConfig{IncludeRepos: `^acme/.*api$`}), ShouldResemble, []string{"api", "legacy-api"})
+			So(kept(core.RepositoryFilterConfig{IncludeRepos: `api`, ExcludeRepos: `legacy`}), ShouldResemble, []string{"api"})
+		})
+
+		Convey("Archived repositories should be left out", func() {
+			So(kept(core.RepositoryFilterConfig{ExcludeArchived: true}), ShouldResemble, []string{"api", "site"})
+		})
+
+		Convey("Internal repositories should count as private", func() {
+			So(kept(core.RepositoryFilterConfig{OnlyPrivate: true}), ShouldResemble, []string{"api", "legacy-api"})
+			So(kept(core.RepositoryFilterConfig{OnlyPublic: true}), ShouldResemble, []string{"site"})
+		})
+
+		Convey("Languages and topics should match any of those given, ignoring case", func() {
+			So(kept(core.RepositoryFilterConfig{Languages: []string{"go", "java"}}), ShouldResemble, []string{"api", "legacy-api"})
+			So(kept(core.RepositoryFilterConfig{Topics: []string{"Web", "payments"}}), ShouldResemble, []string{"api", "site"})
+		})
+
+		Convey("Repositories should be kept when they were pushed to after a date or a number of days ago", func() {
+			So(kept(core.RepositoryFilterConfig{PushedAfter: "2020-01-01"}), ShouldResemble, []string{"api", "site"})
+			So(kept(core.RepositoryFilterConfig{PushedAfter: "7d"}), ShouldResemble, []string{"api"})
+			So(kept(core.RepositoryFilterConfig{PushedAfter: "2020-08-01T00:00:00Z"}), ShouldResemble, []string{"api"})
+		})
+
+		Convey("Every filter should have to match", func() {
+			So(kept(core.RepositoryFilterConfig{OnlyPrivate: true, ExcludeArchived: true, PushedAfter: "90d"}), ShouldResemble, []string{"api"})
+		})
+	})
+
+	Convey("There should be no filter without settings, and settings that do not make sense should be refused", t, func() {
+		f, err := core.NewRepositoryFilter(core.RepositoryFilterConfig{Languages: []string{""}}, now)
+		So(err, ShouldBeNil)
+		So(f, ShouldBeNil)
+
+		_, err = core.NewRepositoryFilter(core.RepositoryFilterConfig{IncludeRepos: "acme/("}, now)
+		So(err, ShouldNotBeNil)
+		_, err = core.NewRepositoryFilter(core.RepositoryFilterConfig{OnlyPrivate: true, OnlyPublic: true}, now)
+		So(err, ShouldNotBeNil)
+		_, err = core.NewRepositoryFilter(core.RepositoryFilterConfig{PushedAfter: "last week"}, now)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given a gitlab group", t, func() {
+		var lookups []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/languages") {
+				lookups = append(lookups, r.URL.Path)
+			}
+			fakeGitlabAPI(w, r)
+		}))
+		defer server.Close()
+
+		client, err := core.NewGitlabClient(server.URL, "0123456789ABCDEFGHIJ", true, true, &core.Logger{})
+		So(err, ShouldBeNil)
+		owner, err := client.GetUserOrganization("1")
+		So(err, ShouldBeNil)
+
+		sess := &core.Session{
+			Client:  client,
+			Out:     &core.Logger{},
+			Stats:   &core.Stats{},
+			Targets: []*core.Owner{owner},
+			Threads: 1,
+		}
+
+		Convey("Only the projects in the languages asked for should be gathered, which are looked up for each project", func() {
+			sess.RepoFilter, err = core.NewRepositoryFilter(core.RepositoryFilterConfig{Languages: []string{"go", "ruby"}}, now)
+			So(err, ShouldBeNil)
+
+			core.GatherRepositories(sess)
+			So(repositoryNames(sess.Repositories), ShouldResemble, []string{"acme/site", "acme/platform/api"})
+			So(sess.Repositories[0].Languages, ShouldResemble, []string{"Ruby", "JavaScript"})
+			So(sess.Stats.RepositoriesTotal, ShouldEqual, 2)
+			So(lookups, ShouldHaveLength, 4)
+		})
+
+		Convey("The languages should not be looked up for projects the other filters leave out", func() {
+			sess.RepoFilter, err = core.NewRepositoryFilter(core.RepositoryFilterConfig{IncludeRepos: "infra", Languages: []string{"hcl"}}, now)
+			So(err, ShouldBeNil)
+
+			core.GatherRepositories(sess)
+			So(repositoryNames(sess.Repositories), ShouldResemble, []string{"acme/platform/infra/terraform"})
+			So(sess.Repositories[0].Languages, ShouldResemble, []string{"HCL"})
+			So(lookups, ShouldResemble, []string{"/api/v4/projects/13/languages"})
+		})
+	})
+}
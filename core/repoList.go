@@ -0,0 +1,195 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// RepoListUser is who repositories from a list are cloned as when neither the line nor the url names a user
+const RepoListUser = "git"
+
+// RepoListEntry is a line of the file read by scanRepoList, a git url and the hints on how it is cloned
+type RepoListEntry struct {
+	Line     int
+	URL      string
+	Branch   string // the branch to scan, whatever HEAD points at when empty
+	Username string
+	TokenEnv string // the environment variable the token or password of the url is read from
+	SSHKey   string // a private key file to clone an ssh url with, rather than the keys of ssh-agent
+}
+
+// repoListAuth is what a repository from a list is cloned with
+type repoListAuth struct {
+	username string
+	token    string
+	sshKey   string
+}
+
+// ParseRepoList will read a list of git urls, one per line. Each url can be followed by hints such as
+// branch=main or token-env=GITLAB_TOKEN, and blank lines and lines starting with # are left out.
+func ParseRepoList(r io.Reader) ([]RepoListEntry, error) {
+	var entries []RepoListEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		entry := RepoListEntry{Line: line, URL: fields[0]}
+		for _, hint := range fields[1:] {
+			kv := strings.SplitN(hint, "=", 2)
+			if len(kv) != 2 || kv[1] == "" {
+				return nil, fmt.Errorf("line %d: %s is not a hint, they are written as name=value", line, hint)
+			}
+			switch kv[0] {
+			case "branch":
+				entry.Branch = kv[1]
+			case "username":
+				entry.Username = kv[1]
+			case "token-env":
+				entry.TokenEnv = kv[1]
+			case "ssh-key":
+				entry.SSHKey = kv[1]
+			default:
+				return nil, fmt.Errorf("line %d: unknown hint %s, it can be branch, username, token-env or ssh-key", line, kv[0])
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ReadRepoList will read the list of git urls in a file
+func ReadRepoList(file string) ([]RepoListEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRepoList(f)
+}
+
+// NewRepoListRepository will turn a line of a list into a repository, reading the credentials it names so a line that
+// can not be cloned is found before anything is scanned
+func NewRepoListRepository(entry RepoListEntry) (*Repository, error) {
+	endpoint, err := transport.NewEndpoint(entry.URL)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %s", entry.Line, err)
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(endpoint.Path, "/"), ".git")
+	if repoPath == "" {
+		return nil, fmt.Errorf("line %d: %s does not name a repository", entry.Line, entry.URL)
+	}
+
+	auth := &repoListAuth{username: entry.Username, sshKey: SetHomeDir(entry.SSHKey)}
+	if auth.username == "" {
+		auth.username = endpoint.User
+	}
+	if auth.username == "" {
+		auth.username = RepoListUser
+	}
+	if entry.TokenEnv != "" {
+		auth.token = os.Getenv(entry.TokenEnv)
+		if auth.token == "" {
+			return nil, fmt.Errorf("line %d: the environment variable %s is not set", entry.Line, entry.TokenEnv)
+		}
+	}
+	if auth.sshKey != "" {
+		if _, err := gitssh.NewPublicKeysFromFile(auth.username, auth.sshKey, ""); err != nil {
+			return nil, fmt.Errorf("line %d: unable to read the ssh key %s: %s", entry.Line, auth.sshKey, err)
+		}
+	}
+
+	dir, name := path.Split(repoPath)
+	owner := strings.Trim(path.Join(endpoint.Host, dir), "/")
+	fullName := strings.Trim(owner+"/"+name, "/")
+	cloneURL := entry.URL
+	branch := entry.Branch
+	id := stringID(entry.URL)
+	return &Repository{
+		Owner:         &owner,
+		ID:            &id,
+		Name:          &name,
+		FullName:      &fullName,
+		CloneURL:      &cloneURL,
+		URL:           &cloneURL,
+		DefaultBranch: &branch,
+		webURL:        repoListWebURL(endpoint, repoPath),
+		listed:        auth,
+	}, nil
+}
+
+// repoListWebURL is where a repository from a list is shown in a browser, guessed from its url. Repositories that are
+// not served over http or ssh have none.
+func repoListWebURL(endpoint *transport.Endpoint, repoPath string) string {
+	switch endpoint.Protocol {
+	case "https", "http":
+		host := endpoint.Host
+		if endpoint.Port != 0 {
+			host += ":" + strconv.Itoa(endpoint.Port)
+		}
+		return fmt.Sprintf("%s://%s/%s", endpoint.Protocol, host, repoPath)
+	case "ssh", "git":
+		// the port of ssh is not the port of the web interface, so it is assumed to be served on the default port
+		return fmt.Sprintf("https://%s/%s", endpoint.Host, repoPath)
+	}
+	return ""
+}
+
+// setRepoListUrls will link a finding to a repository from a list on the hosts whose links are known, the rest are
+// only linked to the repository
+func (f *Finding) setRepoListUrls(webURL string) {
+	if webURL == "" {
+		return
+	}
+	f.RepositoryUrl = webURL
+	host := strings.SplitN(strings.SplitN(webURL, "://", 2)[1], "/", 2)[0]
+	switch {
+	case host == "github.com":
+		f.FileUrl = fmt.Sprintf("%s/blob/%s/%s", webURL, f.CommitHash, f.FilePath)
+		f.CommitUrl = fmt.Sprintf("%s/commit/%s", webURL, f.CommitHash)
+	case strings.Contains(host, "gitlab"):
+		f.FileUrl = fmt.Sprintf("%s/-/blob/%s/%s", webURL, f.CommitHash, f.FilePath)
+		f.CommitUrl = fmt.Sprintf("%s/-/commit/%s", webURL, f.CommitHash)
+	case host == "bitbucket.org":
+		f.FileUrl = fmt.Sprintf("%s/src/%s/%s", webURL, f.CommitHash, f.FilePath)
+		f.CommitUrl = fmt.Sprintf("%s/commits/%s", webURL, f.CommitHash)
+	}
+}
+
+// GatherRepoList will add every repository in the list of git urls of the session to it
+func GatherRepoList(sess *Session) error {
+	sess.SetStatus(StatusGathering)
+	sess.Out.Important("Gathering Repositories from %s...\n", sess.InputFile)
+
+	entries, err := ReadRepoList(sess.InputFile)
+	if err != nil {
+		return err
+	}
+	sess.Stats.Targets = len(entries)
+	for _, entry := range entries {
+		repo, err := NewRepoListRepository(entry)
+		if err != nil {
+			return err
+		}
+		sess.AddRepository(repo)
+	}
+	return nil
+}
+
+// CloneRepoListRepository will create either an in memory clone of a repository from a list or clone it to a temp dir
+func CloneRepoListRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
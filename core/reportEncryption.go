@@ -0,0 +1,364 @@
+package core
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/openpgp"
+	// a gpg key that does not list the hashes it prefers can only be encrypted to with ripemd160
+	_ "golang.org/x/crypto/ripemd160"
+)
+
+// These are the extensions given to report files encrypted with age or gpg
+const (
+	AgeExtension = ".age"
+	PGPExtension = ".gpg"
+)
+
+// These are the parts of the age v1 format, https://age-encryption.org/v1, that wraith writes
+const (
+	ageVersionLine = "age-encryption.org/v1"
+	ageX25519Label = "age-encryption.org/v1/X25519"
+	ageChunkSize   = 64 * 1024
+)
+
+// ReportEncryption encrypts report files to the public keys of the people allowed to read them, so a report full of
+// live secrets is not left readable on whatever host the scan ran on. It is set with --encrypt-report.
+type ReportEncryption struct {
+	ageRecipients [][]byte // the x25519 public keys of age recipients
+	pgpRecipients openpgp.EntityList
+}
+
+// ParseReportRecipient will read the recipient reports are encrypted to. It is either an age public key such as
+// age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p, a file of age public keys one per line, or a file
+// with an exported gpg public key, armored or not.
+func ParseReportRecipient(recipient string) (*ReportEncryption, error) {
+	recipient = strings.TrimSpace(recipient)
+	if strings.HasPrefix(recipient, "age1") {
+		key, err := parseAgeRecipient(recipient)
+		if err != nil {
+			return nil, err
+		}
+		return &ReportEncryption{ageRecipients: [][]byte{key}}, nil
+	}
+
+	data, err := ioutil.ReadFile(SetHomeDir(recipient))
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither an age public key nor a readable key file: %s", recipient, err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "-----BEGIN PGP") {
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the gpg key in %s: %s", recipient, err)
+		}
+		return &ReportEncryption{pgpRecipients: keys}, nil
+	}
+
+	// a file of age recipients has one key per line, with comments starting with #
+	e := &ReportEncryption{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "age1") {
+			e.ageRecipients = nil
+			break
+		}
+		key, err := parseAgeRecipient(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", recipient, err)
+		}
+		e.ageRecipients = append(e.ageRecipients, key)
+	}
+	if len(e.ageRecipients) > 0 {
+		return e, nil
+	}
+
+	keys, err := openpgp.ReadKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s has neither age public keys nor a gpg public key", recipient)
+	}
+	return &ReportEncryption{pgpRecipients: keys}, nil
+}
+
+// Extension is the extension added to the files that are encrypted, so they are not mistaken for plain reports
+func (e *ReportEncryption) Extension() string {
+	if len(e.pgpRecipients) > 0 {
+		return PGPExtension
+	}
+	return AgeExtension
+}
+
+// Path is where a report meant for location is written, with the extension of the encryption unless it already has it
+func (e *ReportEncryption) Path(location string) string {
+	if e == nil || strings.HasSuffix(location, e.Extension()) {
+		return location
+	}
+	return location + e.Extension()
+}
+
+// Encrypt will return a writer that encrypts what is written to it into w. The encryption is only complete once the
+// writer is closed, which does not close w.
+func (e *ReportEncryption) Encrypt(w io.Writer, name string) (io.WriteCloser, error) {
+	if len(e.pgpRecipients) > 0 {
+		return openpgp.Encrypt(w, e.pgpRecipients, nil, &openpgp.FileHints{FileName: name}, nil)
+	}
+	return newAgeWriter(w, e.ageRecipients)
+}
+
+// reportFile is a report file that is being written, through the encryption of the session when it has one
+type reportFile struct {
+	io.Writer
+	fh        *os.File
+	encrypted io.WriteCloser
+	closed    bool
+}
+
+// createReport will create the file of a report at location, or at location with the extension of the encryption of
+// the session when reports are encrypted. Nothing is written to the file unencrypted.
+func (s *Session) createReport(location string) (*reportFile, error) {
+	fh, err := os.OpenFile(s.ReportEncryption.Path(location), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if s.ReportEncryption == nil {
+		return &reportFile{Writer: fh, fh: fh}, nil
+	}
+	encrypted, err := s.ReportEncryption.Encrypt(fh, location)
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	return &reportFile{Writer: encrypted, fh: fh, encrypted: encrypted}, nil
+}
+
+// Close will finish the encryption of the report, if it is encrypted, and close its file. Closing it again does nothing,
+// so it can be deferred as well as closed to check the error.
+func (r *reportFile) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.encrypted != nil {
+		if err := r.encrypted.Close(); err != nil {
+			r.fh.Close()
+			return err
+		}
+	}
+	return r.fh.Close()
+}
+
+// parseAgeRecipient will decode the x25519 public key of an age recipient from its bech32 encoding
+func parseAgeRecipient(recipient string) ([]byte, error) {
+	hrp, data, err := bech32Decode(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an age public key: %s", recipient, err)
+	}
+	if hrp != "age" || len(data) != curve25519.PointSize {
+		return nil, fmt.Errorf("%s is not an age public key", recipient)
+	}
+	return data, nil
+}
+
+// bech32Charset is the alphabet of bech32, each character is 5 bits
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode will decode a bech32 string into its human readable part and its data, checking its checksum. Unlike
+// bitcoin addresses the length is not limited to 90, as age identities are longer.
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("separator in the wrong place")
+	}
+	hrp := s[:pos]
+
+	var values []byte
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+
+	chk := uint32(1)
+	for _, c := range hrp {
+		chk = bech32Polymod(chk, byte(c>>5))
+	}
+	chk = bech32Polymod(chk, 0)
+	for _, c := range hrp {
+		chk = bech32Polymod(chk, byte(c&31))
+	}
+	for _, v := range values {
+		chk = bech32Polymod(chk, v)
+	}
+	if chk != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+
+	// the 5 bit groups, less the 6 of the checksum, are regrouped into bytes
+	var data []byte
+	acc, bits := uint32(0), uint(0)
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, errors.New("invalid padding")
+	}
+	return hrp, data, nil
+}
+
+// bech32Polymod will add a 5 bit value to the checksum of a bech32 string
+func bech32Polymod(chk uint32, v byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	top := chk >> 25
+	chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+	for i := 0; i < 5; i++ {
+		if (top>>uint(i))&1 == 1 {
+			chk ^= generator[i]
+		}
+	}
+	return chk
+}
+
+// ageWriter encrypts to age recipients in chunks of 64KiB, the last of which is only sealed when it is closed
+type ageWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+// newAgeWriter will write the header of an age file for the recipients to w, wrapping a new file key for each of them
+func newAgeWriter(w io.Writer, recipients [][]byte) (*ageWriter, error) {
+	fileKey := make([]byte, 16)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(ageVersionLine + "\n")
+	for _, recipient := range recipients {
+		ephemeral := make([]byte, curve25519.ScalarSize)
+		if _, err := rand.Read(ephemeral); err != nil {
+			return nil, err
+		}
+		share, err := curve25519.X25519(ephemeral, curve25519.Basepoint)
+		if err != nil {
+			return nil, err
+		}
+		shared, err := curve25519.X25519(ephemeral, recipient)
+		if err != nil {
+			return nil, err
+		}
+		wrapKey, err := ageHKDF(shared, append(append([]byte{}, share...), recipient...), ageX25519Label)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := chacha20poly1305.New(wrapKey)
+		if err != nil {
+			return nil, err
+		}
+		body := aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil)
+		fmt.Fprintf(&header, "-> X25519 %s\n%s\n", base64.RawStdEncoding.EncodeToString(share), base64.RawStdEncoding.EncodeToString(body))
+	}
+
+	// the mac covers the header up to the dashes of its last line
+	header.WriteString("---")
+	hmacKey, err := ageHKDF(fileKey, nil, "header")
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(header.Bytes())
+	fmt.Fprintf(&header, " %s\n", base64.RawStdEncoding.EncodeToString(mac.Sum(nil)))
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	payloadKey, err := ageHKDF(fileKey, nonce, "payload")
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(payloadKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Write(nonce)
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return nil, err
+	}
+	return &ageWriter{w: w, aead: aead, buf: make([]byte, 0, ageChunkSize)}, nil
+}
+
+// ageHKDF will derive a 32 byte key with HKDF-SHA256
+func ageHKDF(secret, salt []byte, info string) ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Write will encrypt each chunk once it is full and there is more to come after it
+func (a *ageWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		if len(a.buf) == ageChunkSize {
+			if err := a.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(a.buf[len(a.buf):ageChunkSize], p)
+		a.buf = a.buf[:len(a.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close will encrypt the last chunk, which is marked as the last so a file that is cut short does not decrypt
+func (a *ageWriter) Close() error {
+	return a.seal(true)
+}
+
+// seal will encrypt the chunk in the buffer with a nonce of its number and whether it is the last
+func (a *ageWriter) seal(last bool) error {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], a.counter)
+	if last {
+		nonce[11] = 1
+	}
+	a.counter++
+	_, err := a.w.Write(a.aead.Seal(nil, nonce, a.buf, nil))
+	a.buf = a.buf[:0]
+	return err
+}
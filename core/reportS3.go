@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// These are the server side encryption a report can be stored in a bucket with, the default encryption of the bucket
+// when none is given
+const (
+	S3EncryptionAES256 = "AES256"
+	S3EncryptionKMS    = "aws:kms"
+)
+
+// S3Encryptions are the values report-s3-sse can be set to
+var S3Encryptions = []string{S3EncryptionAES256, S3EncryptionKMS}
+
+// validS3Encryption will check that a server side encryption is one of those supported
+func validS3Encryption(encryption string) bool {
+	for _, e := range S3Encryptions {
+		if e == encryption {
+			return true
+		}
+	}
+	return false
+}
+
+// reportContentTypes are the content types the reports are uploaded with, so a browser opens the html report from the
+// bucket rather than downloading it. Encrypted reports are uploaded as binary.
+var reportContentTypes = map[string]string{
+	".csv":   "text/csv",
+	".html":  "text/html; charset=utf-8",
+	".json":  "application/json",
+	".jsonl": "application/x-ndjson",
+	".xml":   "application/xml",
+}
+
+// ReportS3 uploads the reports of a scan to a bucket, or an S3 compatible service, once they have been written
+type ReportS3 struct {
+	Target     S3Target
+	Encryption string
+	KMSKeyID   string
+	client     *S3Client
+}
+
+// NewReportS3 will create an uploader for the reports of a scan to a target such as s3://acme-security/wraith. The
+// encryption is empty, AES256 or aws:kms, and the kms key is only given with aws:kms to use a key other than the aws
+// managed one.
+func NewReportS3(target, endpoint, region, encryption, kmsKeyID string, creds AWSCredentials) (*ReportS3, error) {
+	t, err := ParseS3Target(target)
+	if err != nil {
+		return nil, err
+	}
+	if encryption != "" && !validS3Encryption(encryption) {
+		return nil, fmt.Errorf("unknown server side encryption %s, it must be one of: %s", encryption, strings.Join(S3Encryptions, ", "))
+	}
+	if kmsKeyID != "" && encryption != S3EncryptionKMS {
+		return nil, fmt.Errorf("a kms key can only be given with %s encryption", S3EncryptionKMS)
+	}
+	return &ReportS3{
+		Target:     t,
+		Encryption: encryption,
+		KMSKeyID:   kmsKeyID,
+		client:     NewS3Client(endpoint, awsRegion(region), creds),
+	}, nil
+}
+
+// Key is the key a report is uploaded to, under the prefix of the target and the id of the session so the reports of
+// one scan are kept together and a scheduled scan does not overwrite those of the one before
+func (r *ReportS3) Key(sessionID, location string) string {
+	return path.Join(r.Target.Prefix, sessionID, filepath.Base(location))
+}
+
+// Upload will upload a report that has been written to a file, returning the s3 url it was uploaded to
+func (r *ReportS3) Upload(sessionID, location string) (string, error) {
+	content, err := ioutil.ReadFile(location)
+	if err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	contentType, ok := reportContentTypes[filepath.Ext(location)]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	if r.Encryption != "" {
+		header.Set("X-Amz-Server-Side-Encryption", r.Encryption)
+	}
+	if r.KMSKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", r.KMSKeyID)
+	}
+
+	key := r.Key(sessionID, location)
+	if err := r.client.PutObject(r.Target.Bucket, key, content, header); err != nil {
+		return "", err
+	}
+	return "s3://" + r.Target.Bucket + "/" + key, nil
+}
+
+// UploadReports will upload the reports that were written at the end of a session to the bucket of report-s3, if it
+// is set
+func (s *Session) UploadReports(locations []string) {
+	if s.ReportS3 == nil {
+		return
+	}
+	for _, location := range locations {
+		uploaded, err := s.ReportS3.Upload(s.ID, location)
+		if err != nil {
+			s.Out.Error("Failed to upload %s to %s: %s\n", location, s.ReportS3.Target, err)
+			continue
+		}
+		s.Out.Important("Report uploaded to %s\n", uploaded)
+	}
+}
+
+// awsRegion is the region given, or the one set in the environment the way the AWS cli reads it
+func awsRegion(region string) string {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region == "" {
+			region = os.Getenv(env)
+		}
+	}
+	return region
+}
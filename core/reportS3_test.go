@@ -0,0 +1,79 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"wraith/core"
+)
+
+func TestReportS3(t *testing.T) {
+
+	Convey("Given a bucket that reports are uploaded to with kms encryption", t, func() {
+		var mu sync.Mutex
+		uploads := map[string]*http.Request{}
+		bodies := map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			uploads[r.URL.Path] = r
+			bodies[r.URL.Path] = string(body)
+		}))
+		defer server.Close()
+
+		dir, err := ioutil.TempDir("", "wraith-report-s3")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		creds := core.AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+		reportS3, err := core.NewReportS3("s3://acme-security/wraith/", server.URL, "eu-west-1", core.S3EncryptionKMS, "alias/reports", creds)
+		So(err, ShouldBeNil)
+
+		sess := scanSession(dir)
+		sess.ID = "scan-1"
+		sess.ReportS3 = reportS3
+		sess.JSONOutput = filepath.Join(dir, "results.json")
+		sess.HTMLOutput = filepath.Join(dir, "report.html")
+
+		Convey("Every report that is written should be uploaded under the id of the scan", func() {
+			sess.WriteReports()
+			So(uploads, ShouldHaveLength, 2)
+
+			json := uploads["/acme-security/wraith/scan-1/results.json"]
+			So(json, ShouldNotBeNil)
+			So(json.Method, ShouldEqual, http.MethodPut)
+			So(json.Header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(json.Header.Get("X-Amz-Server-Side-Encryption"), ShouldEqual, "aws:kms")
+			So(json.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"), ShouldEqual, "alias/reports")
+			So(json.Header.Get("Authorization"), ShouldContainSubstring, "/eu-west-1/s3/aws4_request")
+			So(json.Header.Get("Authorization"), ShouldContainSubstring,
+				"SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-server-side-encryption;x-amz-server-side-encryption-aws-kms-key-id,")
+			written, _ := ioutil.ReadFile(sess.JSONOutput)
+			So(bodies["/acme-security/wraith/scan-1/results.json"], ShouldEqual, string(written))
+
+			html := uploads["/acme-security/wraith/scan-1/report.html"]
+			So(html, ShouldNotBeNil)
+			So(html.Header.Get("Content-Type"), ShouldStartWith, "text/html")
+		})
+	})
+
+	Convey("The server side encryption should be checked", t, func() {
+		_, err := core.NewReportS3("s3://acme-security", "", "", "aws:fsx", "", core.AWSCredentials{})
+		So(err, ShouldNotBeNil)
+		So(strings.Contains(err.Error(), "AES256, aws:kms"), ShouldBeTrue)
+
+		_, err = core.NewReportS3("s3://acme-security", "", "", core.S3EncryptionAES256, "alias/reports", core.AWSCredentials{})
+		So(err, ShouldNotBeNil)
+
+		r, err := core.NewReportS3("acme-security", "", "", "", "", core.AWSCredentials{})
+		So(err, ShouldBeNil)
+		So(r.Key("scan-1", "/tmp/results.json"), ShouldEqual, "scan-1/results.json")
+	})
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/xanzy/go-gitlab"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// These are the defaults of --retries and --retry-backoff
+const (
+	DefaultRetries      = 3
+	DefaultRetryBackoff = 2 * time.Second
+)
+
+// These are the stages of a scan a repository or target can fail in
+const (
+	ScanErrorTarget       = "target"       // the target could not be looked up
+	ScanErrorRepositories = "repositories" // the repositories or gists of a target could not be listed
+	ScanErrorClone        = "clone"        // the repository could not be cloned or fetched
+	ScanErrorHistory      = "history"      // the branches or commits of the clone could not be read
+)
+
+// ScanError is a repository or target that could not be scanned, so a scan that carries on past it still says what
+// was left out of it
+type ScanError struct {
+	Name     string `json:"name"`
+	Stage    string `json:"stage"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// permanentCloneErrors are the errors a clone fails with every time it is tried, so they are not tried again
+var permanentCloneErrors = []error{
+	transport.ErrRepositoryNotFound,
+	transport.ErrEmptyRemoteRepository,
+	transport.ErrAuthenticationRequired,
+	transport.ErrAuthorizationFailed,
+	transport.ErrInvalidAuthMethod,
+	plumbing.ErrReferenceNotFound,
+}
+
+// apiStatusPattern matches the status in the errors the api clients of this package fail with
+var apiStatusPattern = regexp.MustCompile(`returned (\d{3})`)
+
+// apiStatus will find the http status an api call failed with, or 0 when it was not answered
+func apiStatus(err error) int {
+	var githubErr *github.ErrorResponse
+	var gitlabErr *gitlab.ErrorResponse
+	var kubeErr *KubeError
+	switch {
+	case errors.As(err, &githubErr) && githubErr.Response != nil:
+		return githubErr.Response.StatusCode
+	case errors.As(err, &gitlabErr) && gitlabErr.Response != nil:
+		return gitlabErr.Response.StatusCode
+	case errors.As(err, &kubeErr):
+		return kubeErr.StatusCode
+	}
+	if m := apiStatusPattern.FindStringSubmatch(err.Error()); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		return status
+	}
+	return 0
+}
+
+// retryable will check whether a clone, fetch or api call that failed is worth trying again. The clone errors are
+// matched by their message as the clones do not always return them as they are. An api call the server refused, as
+// one for a target that does not exist or with a token that can not see it, is answered the same every time.
+func retryable(err error) bool {
+	for _, permanent := range permanentCloneErrors {
+		if strings.Contains(err.Error(), permanent.Error()) {
+			return false
+		}
+	}
+	status := apiStatus(err)
+	return status < 400 || status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
+
+// withRetries will call fn until it succeeds, fails in a way that trying again does not fix, or the retries run out.
+// The first retry waits RetryBackoff and each one after it twice as long as the one before. It returns how many
+// attempts were made, along with the error of the last of them.
+func (s *Session) withRetries(log *Logger, what string, fn func() error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > s.Retries || !retryable(err) || s.Interrupted() {
+			return attempt, err
+		}
+		wait := s.RetryBackoff << uint(attempt-1)
+		log.Warn("Failed to %s, trying again in %s: %s\n", what, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// recordScanError will keep a repository or target that could not be scanned for the summary at the end of the scan
+func (s *Session) recordScanError(name, stage string, attempts int, err error) {
+	s.scanErrorsLock.Lock()
+	defer s.scanErrorsLock.Unlock()
+	s.scanErrors = append(s.scanErrors, ScanError{Name: name, Stage: stage, Error: err.Error(), Attempts: attempts})
+}
+
+// ScanErrors are the repositories and targets that could not be scanned, in the order they failed
+func (s *Session) ScanErrors() []ScanError {
+	s.scanErrorsLock.Lock()
+	defer s.scanErrorsLock.Unlock()
+	return append([]ScanError(nil), s.scanErrors...)
+}
+
+// PrintScanErrors will print what could not be scanned at the end of a scan, so the gaps it left are not missed
+func PrintScanErrors(sess *Session) {
+	failed := sess.ScanErrors()
+	if len(failed) == 0 {
+		return
+	}
+	sess.Out.Important("-------Errors--------\n")
+	for _, e := range failed {
+		sess.Out.Warn("%s (%s, %d %s): %s\n", e.Name, e.Stage, e.Attempts, Pluralize(e.Attempts, "attempt", "attempts"), e.Error)
+	}
+	sess.Out.Important("\n")
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// These weights decide how much a single finding adds to a risk score. A finding starts out with the weight of its
+// severity, which is then multiplied by its verification and whether it is still present.
+const (
+	RiskDefaultSeverity = 3    // used for findings without a score, such as those from plugins
+	RiskMaxSeverity     = 5    // the highest match level a signature can have
+	RiskVerifiedWeight  = 3    // the secret has been verified and still works
+	RiskInactiveWeight  = 0.25 // the secret has been verified and no longer works
+	RiskAtHeadWeight    = 2    // the secret is still in the latest commit rather than only in the history
+	RiskSaturation      = 25.0 // the total weight at which a score reaches 63, larger totals approach 100
+	RiskScoreMax        = 100
+)
+
+// RiskScore is the aggregated risk of a repository or an organization
+type RiskScore struct {
+	Owner        string `json:"owner"`
+	Name         string `json:"name,omitempty"`
+	Score        int    `json:"score"`
+	Findings     int    `json:"findings"`
+	Repositories int    `json:"repositories,omitempty"`
+}
+
+// RiskReport is the risk of a session as a whole, along with each organization and repository ordered from the most
+// to the least risky
+type RiskReport struct {
+	Score         int         `json:"score"`
+	Organizations []RiskScore `json:"organizations"`
+	Repositories  []RiskScore `json:"repositories"`
+}
+
+// FindingRiskWeight returns how much a single finding adds to the risk of the repository and organization it is in
+func FindingRiskWeight(f *Finding) float64 {
+	// severities rank from 1 for low to 4 for critical, which are shifted onto the match levels they are rated from
+	// so a critical finding weighs RiskMaxSeverity. A severity that is not known falls back to the match level.
+	var severity float64
+	if rank := severityRanks[FindingSeverity(f)]; rank > 0 {
+		severity = float64(rank + 1)
+	} else {
+		severity = float64(f.Score)
+		if f.Score <= 0 {
+			severity = RiskDefaultSeverity
+		}
+	}
+	severity = math.Min(severity, RiskMaxSeverity)
+
+	weight := severity
+	switch f.Verification {
+	case VerificationVerified:
+		weight *= RiskVerifiedWeight
+	case VerificationInactive:
+		weight *= RiskInactiveWeight
+	}
+	if f.AtHead {
+		weight *= RiskAtHeadWeight
+	}
+	return weight
+}
+
+// RiskScoreFromWeight will turn the total weight of a set of findings into a score from 0 to 100. The score grows
+// quickly for the first few findings and then levels off, so a single live key is not hidden by a large org.
+func RiskScoreFromWeight(total float64) int {
+	return int(math.Round(RiskScoreMax * (1 - math.Exp(-total/RiskSaturation))))
+}
+
+// NewRiskReport will aggregate the risk of a set of findings per repository and per organization
+func NewRiskReport(findings []*Finding) RiskReport {
+	type bucket struct {
+		score RiskScore
+		total float64
+		repos map[string]bool
+	}
+	repos := map[string]*bucket{}
+	orgs := map[string]*bucket{}
+	var total float64
+
+	for _, f := range findings {
+		w := FindingRiskWeight(f)
+		total += w
+
+		key := f.RepositoryOwner + "/" + f.RepositoryName
+		r, ok := repos[key]
+		if !ok {
+			r = &bucket{score: RiskScore{Owner: f.RepositoryOwner, Name: f.RepositoryName}}
+			repos[key] = r
+		}
+		r.total += w
+		r.score.Findings++
+
+		o, ok := orgs[f.RepositoryOwner]
+		if !ok {
+			o = &bucket{score: RiskScore{Owner: f.RepositoryOwner}, repos: map[string]bool{}}
+			orgs[f.RepositoryOwner] = o
+		}
+		o.total += w
+		o.score.Findings++
+		o.repos[key] = true
+	}
+
+	sorted := func(buckets map[string]*bucket) []RiskScore {
+		scores := []RiskScore{}
+		for _, b := range buckets {
+			b.score.Score = RiskScoreFromWeight(b.total)
+			b.score.Repositories = len(b.repos)
+			scores = append(scores, b.score)
+		}
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].Score != scores[j].Score {
+				return scores[i].Score > scores[j].Score
+			}
+			return scores[i].Owner+"/"+scores[i].Name < scores[j].Owner+"/"+scores[j].Name
+		})
+		return scores
+	}
+
+	return RiskReport{
+		Score:         RiskScoreFromWeight(total),
+		Organizations: sorted(orgs),
+		Repositories:  sorted(repos),
+	}
+}
+
+// RiskReport will aggregate the risk of every finding in the session
+func (s *Session) RiskReport() RiskReport {
+	s.Lock()
+	defer s.Unlock()
+	return NewRiskReport(s.Findings)
+}
+
+// MarkFindingsAtHead will flag the findings of a repository whose secret can still be found in the latest commit.
+// Findings without a secret, such as a matched file name, are flagged if the file still exists.
+func (s *Session) MarkFindingsAtHead(clone *git.Repository, repo *Repository) {
+	ref, err := clone.Head()
+	if err != nil {
+		return
+	}
+	s.markFindingsAt(clone, ref.Hash(), repo, "")
+}
+
+// markFindingsAt will flag the findings of a repository, or of one of its submodules, whose secret can still be found
+// in a commit. The paths of the findings in a submodule start with the path of the submodule.
+func (s *Session) markFindingsAt(clone *git.Repository, hash plumbing.Hash, repo *Repository, submodule string) {
+	commit, err := clone.CommitObject(hash)
+	if err != nil {
+		return
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range s.Findings {
+		if f.RepositoryOwner != *repo.Owner || f.RepositoryName != *repo.Name || f.Labels[SubmoduleLabel] != submodule {
+			continue
+		}
+
+		filePath := f.FilePath
+		if submodule != "" {
+			filePath = strings.TrimPrefix(filePath, submodule+"/")
+		}
+		file, err := tree.File(filePath)
+		if err != nil {
+			f.AtHead = false
+			continue
+		}
+		if f.secret == "" {
+			f.AtHead = true
+			continue
+		}
+		content, err := file.Contents()
+		f.AtHead = err == nil && strings.Contains(content, f.secret)
+	}
+}
@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"wraith/core"
+)
+
+func TestRiskScore(t *testing.T) {
+
+	Convey("Given a finding", t, func() {
+		f := &core.Finding{Score: 4}
+
+		Convey("Its weight should start out as its score", func() {
+			So(core.FindingRiskWeight(f), ShouldEqual, 4)
+		})
+
+		Convey("Its weight should follow a severity changed by a finding script or policy rather than its score", func() {
+			f.Severity = core.SeverityLow
+			So(core.FindingRiskWeight(f), ShouldEqual, 2)
+			f.Severity = core.SeverityCritical
+			So(core.FindingRiskWeight(f), ShouldEqual, core.RiskMaxSeverity)
+		})
+
+		Convey("A finding with a severity that is not known should fall back to its score", func() {
+			f.Severity = "urgent"
+			So(core.FindingRiskWeight(f), ShouldEqual, 4)
+		})
+
+		Convey("A finding without a score should use the default severity", func() {
+			So(core.FindingRiskWeight(&core.Finding{}), ShouldEqual, core.RiskDefaultSeverity)
+		})
+
+		Convey("A verified secret should weigh more and an inactive one less", func() {
+			f.Verification = core.VerificationVerified
+			So(core.FindingRiskWeight(f), ShouldEqual, 4*core.RiskVerifiedWeight)
+			f.Verification = core.VerificationInactive
+			So(core.FindingRiskWeight(f), ShouldEqual, 4*core.RiskInactiveWeight)
+		})
+
+		Convey("A secret still at HEAD should weigh more", func() {
+			f.AtHead = true
+			So(core.FindingRiskWeight(f), ShouldEqual, 4*core.RiskAtHeadWeight)
+		})
+	})
+
+	Convey("Given a total weight", t, func() {
+
+		Convey("The score should stay between 0 and 100", func() {
+			So(core.RiskScoreFromWeight(0), ShouldEqual, 0)
+			So(core.RiskScoreFromWeight(core.RiskSaturation), ShouldEqual, 63)
+			So(core.RiskScoreFromWeight(10000), ShouldEqual, core.RiskScoreMax)
+		})
+	})
+
+	Convey("Given findings across several repositories", t, func() {
+		findings := []*core.Finding{
+			{RepositoryOwner: "acme", RepositoryName: "api", Score: 5, AtHead: true, Verification: core.VerificationVerified},
+			{RepositoryOwner: "acme", RepositoryName: "web", Score: 1},
+			{RepositoryOwner: "acme", RepositoryName: "web", Score: 1},
+			{RepositoryOwner: "other", RepositoryName: "docs", Score: 5},
+		}
+		report := core.NewRiskReport(findings)
+
+		Convey("Repositories should be ordered from the most to the least risky", func() {
+			So(report.Repositories, ShouldHaveLength, 3)
+			So(report.Repositories[0].Name, ShouldEqual, "api")
+			So(report.Repositories[0].Findings, ShouldEqual, 1)
+			So(report.Repositories[2].Name, ShouldEqual, "web")
+			So(report.Repositories[2].Findings, ShouldEqual, 2)
+		})
+
+		Convey("Organizations should count their findings and repositories", func() {
+			So(report.Organizations, ShouldHaveLength, 2)
+			So(report.Organizations[0].Owner, ShouldEqual, "acme")
+			So(report.Organizations[0].Findings, ShouldEqual, 3)
+			So(report.Organizations[0].Repositories, ShouldEqual, 2)
+		})
+
+		Convey("The overall score should be at least that of the riskiest organization", func() {
+			So(report.Score, ShouldBeGreaterThanOrEqualTo, report.Organizations[0].Score)
+		})
+	})
+
+	Convey("Given no findings the report should be empty", t, func() {
+		report := core.NewRiskReport(nil)
+		So(report.Score, ShouldEqual, 0)
+		So(report.Repositories, ShouldBeEmpty)
+		So(report.Organizations, ShouldBeEmpty)
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	"github.com/gin-contrib/secure"
@@ -57,7 +60,7 @@ func BinaryFileSystem(root string) *binaryFileSystem {
 // NewRouter will create an instance of the web frontend, setting the necessary parameters.
 func NewRouter(s *Session) *gin.Engine {
 
-	if s.ScanType == "github" {
+	if s.ScanType == "github" || s.ScanType == "githubPR" {
 		IsGithub = true
 	}
 
@@ -68,6 +71,9 @@ func NewRouter(s *Session) *gin.Engine {
 	}
 
 	router := gin.New()
+	if s.WebAuth != nil {
+		router.Use(s.WebAuth.Middleware())
+	}
 	router.Use(static.Serve("/", BinaryFileSystem("static")))
 	router.Use(secure.New(secure.Config{
 		SSLRedirect:           false,
@@ -82,7 +88,13 @@ func NewRouter(s *Session) *gin.Engine {
 		c.JSON(200, s.Stats)
 	})
 	router.GET("/findings", func(c *gin.Context) {
-		c.JSON(200, s.Findings)
+		s.Lock()
+		findings := append([]*Finding(nil), s.Findings...)
+		s.Unlock()
+		webFindings(c, findings)
+	})
+	router.POST("/findings/:fingerprint/triage", func(c *gin.Context) {
+		triageFinding(c, s)
 	})
 	router.GET("/targets", func(c *gin.Context) {
 		c.JSON(200, s.Targets)
@@ -90,23 +102,421 @@ func NewRouter(s *Session) *gin.Engine {
 	router.GET("/repositories", func(c *gin.Context) {
 		c.JSON(200, s.Repositories)
 	})
-	router.GET("/files/:owner/:repo/:commit/*path", fetchFile)
+	router.GET("/risk", func(c *gin.Context) {
+		c.JSON(200, s.RiskReport())
+	})
+	router.GET("/secrets", func(c *gin.Context) {
+		c.JSON(200, s.SecretGroups())
+	})
+	router.GET("/trends", func(c *gin.Context) {
+		fetchTrends(c, s)
+	})
+	router.GET("/files/:owner/:repo/:commit/*path", func(c *gin.Context) {
+		fetchFile(c, s)
+	})
+	router.GET("/sessions", func(c *gin.Context) {
+		fetchSessions(c, s)
+	})
+	router.GET("/sessions/:id", func(c *gin.Context) {
+		fetchSession(c, s, "")
+	})
+	router.GET("/sessions/:id/findings", func(c *gin.Context) {
+		fetchSession(c, s, "findings")
+	})
+	router.GET("/sessions/:id/targets", func(c *gin.Context) {
+		fetchSession(c, s, "targets")
+	})
+
+	api := router.Group(APIPath)
+	api.GET("/stats", func(c *gin.Context) {
+		c.JSON(200, NewJSONStats(s.Stats))
+	})
+	api.GET("/findings", func(c *gin.Context) {
+		apiFindings(c, s)
+	})
+	api.GET("/secrets", func(c *gin.Context) {
+		c.JSON(200, s.SecretGroups())
+	})
+	api.GET("/scans", func(c *gin.Context) {
+		apiScans(c, s)
+	})
+	api.POST("/scans", func(c *gin.Context) {
+		submitScan(c, s)
+	})
+	api.GET("/scans/:id", func(c *gin.Context) {
+		apiScan(c, s)
+	})
+	api.DELETE("/scans/:id", func(c *gin.Context) {
+		cancelScan(c, s)
+	})
+	api.GET("/scans/:id/findings", func(c *gin.Context) {
+		apiScanFindings(c, s)
+	})
+	api.GET("/schedules", func(c *gin.Context) {
+		apiSchedules(c, s)
+	})
+	api.GET("/sessions", func(c *gin.Context) {
+		fetchSessions(c, s)
+	})
+	api.GET("/sessions/:id", func(c *gin.Context) {
+		fetchSession(c, s, "")
+	})
+	api.GET("/sessions/:id/findings", func(c *gin.Context) {
+		apiSessionFindings(c, s)
+	})
+	api.POST("/signatures/reload", func(c *gin.Context) {
+		reloadSignatures(c, s)
+	})
 
 	return router
 }
 
-// TODO this will fail for other target types and must be converted to a switch for scalability
+// fetchTrends returns the trends of the history file, which includes the current session once it has finished
+func fetchTrends(c *gin.Context, s *Session) {
+	if s.HistoryFile == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "History is not enabled, set history-file to track trends",
+		})
+		return
+	}
+
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(HistoryWeeks)))
+	if err != nil || weeks < 1 || weeks > 520 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "weeks must be a number from 1 to 520",
+		})
+		return
+	}
+
+	h, err := LoadHistory(s.HistoryFile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, h.Trends(weeks, time.Now()))
+}
+
+// fetchSessions returns every session in the database, including the current one
+func fetchSessions(c *gin.Context, s *Session) {
+	if s.DB == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "The database is not enabled, set db-path to keep sessions",
+		})
+		return
+	}
+
+	sessions, err := s.DB.Sessions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// storedSession will check the session in the url is in the database, responding with an error when it is not
+func storedSession(c *gin.Context, s *Session) (int64, bool) {
+	if s.DB == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "The database is not enabled, set db-path to keep sessions",
+		})
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "The session id must be a number",
+		})
+		return 0, false
+	}
+	session, err := s.DB.Session(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return 0, false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": fmt.Sprintf("There is no session %d", id),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// storedFindings returns the findings of a session in the database. A stored finding shows how it is triaged now, not
+// how it was when it was saved.
+func (s *Session) storedFindings(id int64) ([]*Finding, error) {
+	findings, err := s.DB.Findings(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range findings {
+		f.Triage = s.Triage.Get(f.Fingerprint)
+	}
+	return findings, nil
+}
+
+// fetchSession returns a session from the database, or its findings or targets
+func fetchSession(c *gin.Context, s *Session, part string) {
+	id, ok := storedSession(c, s)
+	if !ok {
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch part {
+	case "findings":
+		var findings []*Finding
+		if findings, err = s.storedFindings(id); err == nil {
+			webFindings(c, findings)
+			return
+		}
+	case "targets":
+		result, err = s.DB.Targets(id)
+	default:
+		result, err = s.DB.Session(id)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// WebFindings is a page of the findings shown by the web interface, along with every repository and signature that has
+// findings so they can be offered as filters
+type WebFindings struct {
+	Total        int
+	Offset       int
+	Limit        int
+	Findings     []*Finding
+	Repositories []string
+	Signatures   []string
+}
+
+// webFindings responds with the findings that match the filters of the request, the same filters as the api. They are
+// all returned as a list unless a page is asked for with offset or limit, which the web interface does so a scan with
+// a great many findings is not rendered at once.
+func webFindings(c *gin.Context, findings []*Finding) {
+	filtered, err := filterFindings(c, findings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if c.Query("offset") == "" && c.Query("limit") == "" {
+		c.JSON(http.StatusOK, filtered)
+		return
+	}
+	offset, limit, err := findingsPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	page := WebFindings{Total: len(filtered), Offset: offset, Limit: limit, Findings: []*Finding{},
+		Repositories: []string{}, Signatures: []string{}}
+	if offset < len(filtered) {
+		end := offset + limit
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		page.Findings = filtered[offset:end]
+	}
+
+	repositories := map[string]bool{}
+	signatures := map[string]bool{}
+	for _, f := range findings {
+		repositories[f.RepositoryOwner+"/"+f.RepositoryName] = true
+		signatures[f.Signatureid] = true
+	}
+	for repository := range repositories {
+		page.Repositories = append(page.Repositories, repository)
+	}
+	for signature := range signatures {
+		page.Signatures = append(page.Signatures, signature)
+	}
+	sort.Strings(page.Repositories)
+	sort.Strings(page.Signatures)
+	c.JSON(http.StatusOK, page)
+}
+
+// filterTriage returns the findings triaged with a status, or those that have not been triaged when it is
+// "untriaged". Every finding is returned when there is no status.
+func filterTriage(findings []*Finding, status string) []*Finding {
+	if status == "" {
+		return findings
+	}
+	filtered := []*Finding{}
+	for _, f := range findings {
+		if (f.Triage == nil && status == "untriaged") || (f.Triage != nil && f.Triage.Status == status) {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// triageFinding records what the user decided about a finding, given as json with a status and an optional note.
+// Only json is accepted so a form on another site can not triage findings for a signed in user.
+func triageFinding(c *gin.Context, s *Session) {
+	var req struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if c.ContentType() != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"message": "The triage must be sent as application/json",
+		})
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if req.Status != "" && !ValidTriageStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("The status must be one of: %s, or empty to clear it", strings.Join(TriageStatuses, ", ")),
+		})
+		return
+	}
+
+	triage := &Triage{Status: req.Status, Note: req.Note, User: c.GetString(gin.AuthUserKey)}
+	n, err := s.TriageFinding(c.Param("fingerprint"), triage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if n == 0 && s.TriageFile == "" && s.DB == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "There is no finding with that fingerprint",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, triage)
+}
+
 // fetchFile returns a given path to a file that can be cicked on by a user
-func fetchFile(c *gin.Context) {
+func fetchFile(c *gin.Context, s *Session) {
+	if s.ScanType == "dockerImage" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "files inside docker images can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "s3" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "objects in s3 buckets can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "kubernetes" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "values of kubernetes objects can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "confluence" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "confluence pages and attachments can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "repoList" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "files in repositories from a list can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "codeCommit" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "files in codecommit repositories can not be fetched",
+		})
+		return
+	}
+
+	if s.ScanType == "azureDevops" {
+		fileUrl, err := azureDevopsFileURL(s.AzureDevopsURL, c.Param("owner")+"/"+c.Param("repo")+"/"+c.Param("commit")+c.Param("path"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		serveFile(c, s, fileUrl, func(req *http.Request) {
+			req.SetBasicAuth(AzureDevopsPATUser, s.AzureDevopsToken)
+		})
+		return
+	}
+
+	if s.ScanType == "bitbucketServer" {
+		fileUrl := bitbucketServerFileURL(s.BitbucketServerURL, c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
+		serveFile(c, s, fileUrl, func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+s.BitbucketServerToken)
+		})
+		return
+	}
+
+	if s.ScanType == "gitea" {
+		fileUrl := giteaFileURL(s.GiteaURL, c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
+		serveFile(c, s, fileUrl, func(req *http.Request) {
+			req.Header.Set("Authorization", "token "+s.GiteaAccessToken)
+		})
+		return
+	}
+
 	fileUrl := func() string {
-		if IsGithub {
+		switch {
+		case IsGithub && s.isGist(c.Param("owner"), c.Param("repo")):
+			return fmt.Sprintf("%s/%s/%s/raw/%s%s", GistRawBaseURL, c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
+		case IsGithub:
 			return fmt.Sprintf("%s/%s/%s/%s%s", GithubBaseUri, c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
-		} else {
+		case s.ScanType == "bitbucket":
+			return fmt.Sprintf("%s/%s/%s/raw/%s%s", BitbucketBaseURL, c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
+		default:
 			results := CleanUrlSpaces(c.Param("owner"), c.Param("repo"), c.Param("commit"), c.Param("path"))
 			return fmt.Sprintf("%s/%s/%s/%s/%s%s", GitLabBaseUri, results[0], results[1], "/-/raw/", results[2], results[3])
 		}
 	}()
-	resp, err := http.Head(fileUrl)
+	serveFile(c, s, fileUrl, nil)
+}
+
+// serveFile will fetch a file from the provider of the scan and return it to the web interface, authorize adds the
+// credentials of the scan to each request for providers whose files are not public
+func serveFile(c *gin.Context, s *Session, fileUrl string, authorize func(req *http.Request)) {
+	do := func(method string) (*http.Response, error) {
+		req, err := http.NewRequest(method, fileUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		if authorize != nil {
+			authorize(req)
+		}
+		return NewHTTPClient(0).Do(req)
+	}
+
+	resp, err := do(http.MethodHead)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": err,
@@ -128,7 +538,7 @@ func fetchFile(c *gin.Context) {
 		return
 	}
 
-	resp, err = http.Get(fileUrl)
+	resp, err = do(http.MethodGet)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": err,
@@ -145,5 +555,5 @@ func fetchFile(c *gin.Context) {
 		return
 	}
 
-	c.String(http.StatusOK, string(body[:]))
+	c.String(http.StatusOK, s.redactContent(string(body[:])))
 }
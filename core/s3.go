@@ -0,0 +1,505 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// These are the defaults used to talk to S3
+const (
+	S3DefaultRegion = "us-east-1"
+	S3Owner         = "s3"
+)
+
+// awsEmptyPayloadHash is the sha256 of an empty body, which every request to S3 made here has
+const awsEmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Target is a bucket, or the objects in a bucket under a prefix, to be scanned
+type S3Target struct {
+	Bucket string
+	Prefix string
+}
+
+// ParseS3Target will split a target such as s3://acme-backups/db/ or acme-backups/db/ into its bucket and prefix
+func ParseS3Target(target string) (S3Target, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(target), "s3://")
+	parts := strings.SplitN(name, "/", 2)
+	t := S3Target{Bucket: parts[0]}
+	if len(parts) == 2 {
+		t.Prefix = parts[1]
+	}
+	if t.Bucket == "" {
+		return t, fmt.Errorf("%s is not an s3 bucket", target)
+	}
+	return t, nil
+}
+
+// String is the target as an s3 url
+func (t S3Target) String() string {
+	return "s3://" + t.Bucket + "/" + t.Prefix
+}
+
+// AWSCredentials are the keys requests to AWS are signed with. Requests are sent unsigned when they are empty, which
+// is enough for public buckets, and the SessionToken is set for the temporary credentials of roles.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Object is an object listed in a bucket
+type S3Object struct {
+	Key  string `xml:"Key"`
+	ETag string `xml:"ETag"`
+	Size int64  `xml:"Size"`
+}
+
+// s3ListResult is a page of the objects in a bucket as returned by ListObjectsV2
+type s3ListResult struct {
+	Contents              []S3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// s3Error is the body of a request to S3 that failed
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// S3Client lists and reads the objects of buckets. Requests go to the endpoint of each bucket in its region, or to a
+// custom endpoint, such as minio, with the bucket in the path.
+type S3Client struct {
+	sync.Mutex
+	Endpoint    string
+	Region      string
+	Credentials AWSCredentials
+	regions     map[string]string
+	client      *http.Client
+}
+
+// NewS3Client will create a client for S3, or for an S3 compatible service when the endpoint is set
+func NewS3Client(endpoint, region string, creds AWSCredentials) *S3Client {
+	if region == "" {
+		region = S3DefaultRegion
+	}
+	return &S3Client{
+		Endpoint:    strings.TrimSuffix(endpoint, "/"),
+		Region:      region,
+		Credentials: creds,
+		regions:     map[string]string{},
+		client:      NewHTTPClient(30 * time.Minute),
+	}
+}
+
+// bucketRegion is the region requests for a bucket are signed for, once S3 has said which region the bucket is in
+func (c *S3Client) bucketRegion(bucket string) string {
+	c.Lock()
+	defer c.Unlock()
+	if region, ok := c.regions[bucket]; ok {
+		return region
+	}
+	return c.Region
+}
+
+// bucketURL is where the requests for a bucket are sent. Buckets with a dot in their name do not match the
+// certificate of their own host name, so they are put in the path instead.
+func (c *S3Client) bucketURL(bucket string) string {
+	if c.Endpoint != "" {
+		return c.Endpoint + "/" + awsURIEncode(bucket, true)
+	}
+	region := c.bucketRegion(bucket)
+	if strings.Contains(bucket, ".") {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s", region, bucket)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+}
+
+// get will make a signed request for a bucket, following S3 to the region of the bucket once when it is in another
+func (c *S3Client) get(bucket, key string, query url.Values) (*http.Response, error) {
+	return c.do(http.MethodGet, bucket, key, query, nil, nil)
+}
+
+// do will make a signed request for a bucket with a body and headers, following S3 to the region of the bucket once
+// when it is in another
+func (c *S3Client) do(method, bucket, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		u, err := url.Parse(c.bucketURL(bucket) + "/" + awsURIEncode(key, false))
+		if err != nil {
+			return nil, err
+		}
+		u.RawQuery = awsCanonicalQuery(query)
+
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, u.String(), reader)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("User-Agent", UserAgent)
+		signAWSServiceRequest(req, "s3", c.bucketRegion(bucket), c.Credentials, body, time.Now().UTC())
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		region := resp.Header.Get("X-Amz-Bucket-Region")
+		if attempt == 0 && region != "" && region != c.bucketRegion(bucket) {
+			c.Lock()
+			c.regions[bucket] = region
+			c.Unlock()
+			continue
+		}
+
+		var apiErr s3Error
+		if xml.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+			return nil, fmt.Errorf("s3 returned %d %s for %s: %s", resp.StatusCode, apiErr.Code, "s3://"+bucket+"/"+key, apiErr.Message)
+		}
+		return nil, fmt.Errorf("s3 returned %s for %s", resp.Status, "s3://"+bucket+"/"+key)
+	}
+}
+
+// ListObjects will list every object in a bucket under a prefix, a page at a time
+func (c *S3Client) ListObjects(bucket, prefix string) ([]S3Object, error) {
+	var objects []S3Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.get(bucket, "", query)
+		if err != nil {
+			return nil, err
+		}
+		var page s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the objects of s3://%s: %s", bucket, err)
+		}
+
+		objects = append(objects, page.Contents...)
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// GetObject will copy the content of an object to w, failing once more than limit bytes have been copied
+func (c *S3Client) GetObject(bucket, key string, w io.Writer, limit int64) error {
+	resp, err := c.get(bucket, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("s3://%s/%s is larger than %d bytes", bucket, key, limit)
+	}
+	return nil
+}
+
+// PutObject will upload the content of an object, with the headers it is stored with such as its content type and
+// server side encryption
+func (c *S3Client) PutObject(bucket, key string, content []byte, header http.Header) error {
+	resp, err := c.do(http.MethodPut, bucket, key, nil, content, header)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// awsURIEncode will percent encode everything but the unreserved characters, the way signature version 4 expects,
+// leaving slashes alone in a path
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// awsCanonicalQuery will encode a query with its parameters in order, which is how it is signed
+func awsCanonicalQuery(query url.Values) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			params = append(params, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// signAWSServiceRequest will sign a request to an AWS service with signature version 4, leaving it unsigned when there are
+// no credentials. The payload is the body the request is sent with, and every x-amz header set on the request is signed
+// along with it, as S3 rejects those that are not.
+func signAWSServiceRequest(req *http.Request, service, region string, creds AWSCredentials, payload []byte, now time.Time) {
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := awsEmptyPayloadHash
+	if len(payload) > 0 {
+		payloadHash = awsHash(string(payload))
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	names := []string{"host"}
+	values := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if strings.HasPrefix(name, "x-amz-") && len(v) > 0 {
+			names = append(names, name)
+			values[name] = strings.TrimSpace(v[0])
+		}
+	}
+	sort.Strings(names)
+	var headers string
+	for _, name := range names {
+		headers += name + ":" + values[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope, signature := awsSignature(canonicalRequest, service, region, creds, amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// awsSignature will sign a canonical request for a service in a region at the time of amzDate, returning the scope of
+// the signature along with it
+func awsSignature(canonicalRequest, service, region string, creds AWSCredentials, amzDate string) (string, string) {
+	date := amzDate[:8]
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, awsHash(canonicalRequest)}, "\n")
+
+	key := awsMAC([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = awsMAC(key, region)
+	key = awsMAC(key, service)
+	key = awsMAC(key, "aws4_request")
+	return scope, hex.EncodeToString(awsMAC(key, stringToSign))
+}
+
+// awsHash is the hex encoded sha256 of s
+func awsHash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// awsMAC is the HMAC-SHA256 of s with key
+func awsMAC(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// ScanS3 will scan the objects of every bucket and prefix that is a target of the session
+func ScanS3(sess *Session) error {
+	if len(sess.S3Targets) == 0 {
+		return errors.New("you must set at least one s3 bucket to scan")
+	}
+
+	var targets []S3Target
+	for _, name := range sess.S3Targets {
+		target, err := ParseS3Target(name)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	creds, err := LoadAWSCredentials(sess.AWSProfile)
+	switch {
+	case IsNoAWSCredentials(err) && sess.S3Anonymous:
+	case IsNoAWSCredentials(err):
+		return fmt.Errorf("%s, set s3-anonymous to scan public buckets without credentials", err)
+	case err != nil:
+		return fmt.Errorf("unable to load aws credentials: %s", err)
+	}
+	client := NewS3Client(sess.S3Endpoint, awsRegion(sess.S3Region), creds)
+
+	sess.SetStatus(StatusAnalyzing)
+	scanned := 0
+	for _, target := range targets {
+		if sess.Interrupted() {
+			break
+		}
+		if err := scanS3Target(client, target, sess); err != nil {
+			sess.Out.Error("Unable to scan %s: %s\n", target, err)
+			continue
+		}
+		scanned++
+	}
+
+	if scanned == 0 && !sess.Interrupted() {
+		return errors.New("no buckets could be scanned")
+	}
+	return nil
+}
+
+// scanS3Target will download each object under the prefix of a target to a temp dir and scan it, with as many
+// objects at a time as there are threads
+func scanS3Target(client *S3Client, target S3Target, sess *Session) error {
+	sess.Out.Important("Listing %s\n", target)
+	var objects []S3Object
+	_, err := sess.withRetries(sess.Out, "list "+target.String(), func() (err error) {
+		objects, err = client.ListObjects(target.Bucket, target.Prefix)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	sess.Stats.IncrementTargets()
+	sess.Stats.IncrementRepositoriesTotal()
+	sess.Out.Info(" Retrieved %d %s from %s\n", len(objects), Pluralize(len(objects), "object", "objects"), target)
+
+	dir, err := ioutil.TempDir("", "wraith-s3")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	maxSize := sess.MaxFileSize * 1024 * 1024
+	sem := make(chan struct{}, sess.Threads)
+	var wg sync.WaitGroup
+	for i, object := range objects {
+		if sess.Interrupted() {
+			break
+		}
+		// objects that only stand in for a folder have no content
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+		sess.Stats.IncrementFilesTotal()
+
+		matchFile := newMatchFile(object.Key)
+		if matchFile.isSkippable(sess) {
+			sess.Out.Debug("%s is listed as skippable and is being ignored\n", object.Key)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if !sess.ScanTests && isTestFileOrPath(object.Key) {
+			sess.Out.Debug("%s is a test file and being ignored\n", object.Key)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+		if object.Size > maxSize {
+			sess.Out.Debug("%s is too large and being ignored\n", object.Key)
+			sess.Stats.IncrementFilesIgnored()
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, object S3Object) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// the object is saved under its own name so the signatures that match on file names still work
+			fullFilePath := filepath.Join(dir, fmt.Sprint(i), filepath.Base(object.Key))
+			if err := scanS3Object(client, target.Bucket, object, fullFilePath, maxSize, sess); err != nil {
+				sess.Out.Error("Unable to scan s3://%s/%s: %s\n", target.Bucket, object.Key, err)
+			}
+		}(i, object)
+	}
+	wg.Wait()
+
+	sess.Stats.IncrementRepositoriesScanned()
+	return nil
+}
+
+// scanS3Object will download an object to a file and scan it, removing the file afterwards
+func scanS3Object(client *S3Client, bucket string, object S3Object, fullFilePath string, maxSize int64, sess *Session) error {
+	if err := os.MkdirAll(filepath.Dir(fullFilePath), 0700); err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(fullFilePath))
+
+	fh, err := os.OpenFile(fullFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = client.GetObject(bucket, object.Key, fh, maxSize)
+	fh.Close()
+	if err != nil {
+		return err
+	}
+
+	scanFile(fullFilePath, Finding{
+		Action:          `S3 Object`,
+		AtHead:          true,
+		FilePath:        object.Key,
+		RepositoryName:  bucket,
+		RepositoryOwner: S3Owner,
+		CommitHash:      strings.Trim(object.ETag, `"`),
+	}, sess)
+	return nil
+}
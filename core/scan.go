@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+)
+
+// RunScan will run every stage of a scan for the type of the given session. Unlike the individual commands it will
+// return an error rather than exiting, so it is safe to use for scans that are started by a long running server.
+func RunScan(sess *Session) error {
+	switch sess.ScanType {
+	case "githubPR":
+		return ScanGithubPullRequests(sess)
+	case "dockerImage":
+		return ScanDockerImages(sess)
+	case "s3":
+		return ScanS3(sess)
+	case "kubernetes":
+		return ScanKubernetes(sess)
+	case "confluence":
+		return ScanConfluence(sess)
+	case "localPath":
+		sess.SetStatus(StatusAnalyzing)
+		for _, fl := range sess.LocalFiles {
+			if fl != "" && PathExists(fl, sess) {
+				DoFileScan(fl, sess)
+			}
+		}
+		for _, pth := range sess.LocalDirs {
+			if pth != "" && PathExists(pth, sess) {
+				ScanDir(pth, sess)
+			}
+		}
+		return nil
+	}
+
+	if err := GatherScanRepositories(sess); err != nil {
+		return err
+	}
+	return AnalyzeRepositories(sess)
+}
+
+// GatherScanRepositories will gather the repositories of a scan that clones repositories, after every filter, without
+// cloning any of them
+func GatherScanRepositories(sess *Session) error {
+	switch sess.ScanType {
+	case "github", "gitlab", "bitbucket", "bitbucketServer", "azureDevops", "gitea", "codeCommit":
+		GatherTargets(sess)
+		GatherRepositories(sess)
+	case "localGit":
+		for _, pth := range sess.LocalDirs {
+			if !PathExists(pth, sess) {
+				return fmt.Errorf("%s does not exist", pth)
+			}
+		}
+		GatherLocalRepositories(sess)
+	case "repoList":
+		return GatherRepoList(sess)
+	default:
+		return fmt.Errorf("unknown scan type %s", sess.ScanType)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// scanStateLock keeps sessions in the same process from overwriting each others repositories when the state is saved
+var scanStateLock sync.Mutex
+
+// ScanState remembers the last commit scanned in each repository so an incremental scan only has to walk the commits
+// that were added since
+type ScanState struct {
+	sync.Mutex
+
+	// Repositories holds the hash of the last commit scanned, keyed by the clone url of the repository. Branches other
+	// than the default branch are kept under the clone url followed by # and the name of the branch.
+	Repositories map[string]string `json:"repositories"`
+}
+
+// LoadScanState will read the scan state from a file, a file that does not exist yet has not scanned anything
+func LoadScanState(path string) (*ScanState, error) {
+	st := &ScanState{Repositories: map[string]string{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	if st.Repositories == nil {
+		st.Repositories = map[string]string{}
+	}
+	return st, nil
+}
+
+// LastCommit returns the hash of the last commit scanned in a repository, or an empty string if it has not been
+// scanned. A nil state has not scanned anything.
+func (st *ScanState) LastCommit(repo *Repository) string {
+	if st == nil {
+		return ""
+	}
+	st.Lock()
+	defer st.Unlock()
+
+	return st.Repositories[*repo.CloneURL]
+}
+
+// Scanned will record the last commit scanned in a repository
+func (st *ScanState) Scanned(repo *Repository, hash string) {
+	if st == nil {
+		return
+	}
+	st.Lock()
+	defer st.Unlock()
+
+	st.Repositories[*repo.CloneURL] = hash
+}
+
+// LastCommits returns the last commit scanned on the default branch and on every other branch of a repository that
+// has been scanned
+func (st *ScanState) LastCommits(repo *Repository) []string {
+	if st == nil {
+		return nil
+	}
+	st.Lock()
+	defer st.Unlock()
+
+	var hashes []string
+	if hash, ok := st.Repositories[*repo.CloneURL]; ok {
+		hashes = append(hashes, hash)
+	}
+	var branches []string
+	for key := range st.Repositories {
+		if strings.HasPrefix(key, *repo.CloneURL+"#") {
+			branches = append(branches, key)
+		}
+	}
+	sort.Strings(branches)
+	for _, key := range branches {
+		hashes = append(hashes, st.Repositories[key])
+	}
+	return hashes
+}
+
+// ScannedBranch will record the last commit scanned on a branch of a repository other than its default branch
+func (st *ScanState) ScannedBranch(repo *Repository, branch string, hash string) {
+	if st == nil {
+		return
+	}
+	st.Lock()
+	defer st.Unlock()
+
+	st.Repositories[*repo.CloneURL+"#"+branch] = hash
+}
+
+// Save will write the state to a file. Repositories written to the file since it was loaded are merged in, so
+// concurrent sessions scanning different targets do not lose each others progress.
+func (st *ScanState) Save(path string) error {
+	scanStateLock.Lock()
+	defer scanStateLock.Unlock()
+
+	current, err := LoadScanState(path)
+	if err != nil {
+		return err
+	}
+
+	st.Lock()
+	for url, hash := range st.Repositories {
+		current.Repositories[url] = hash
+	}
+	st.Unlock()
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveScanState will write the scan state of the session to its file, if the scan is incremental
+func (s *Session) SaveScanState() {
+	if s.ScanState == nil {
+		return
+	}
+	if err := s.ScanState.Save(s.ScanStateFile); err != nil {
+		s.Out.Error("Failed to write the scan state to %s: %s\n", s.ScanStateFile, err)
+	}
+}
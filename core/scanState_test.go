@@ -0,0 +1,180 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestScanState(t *testing.T) {
+
+	Convey("Given a scan state", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-state")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "state.json")
+
+		st, err := core.LoadScanState(path)
+		So(err, ShouldBeNil)
+
+		url := "https://github.com/acme/api.git"
+		repo := &core.Repository{CloneURL: &url}
+
+		Convey("A repository that has not been scanned should have no last commit", func() {
+			So(st.LastCommit(repo), ShouldEqual, "")
+		})
+
+		Convey("The last commit should be kept when the state is saved", func() {
+			st.Scanned(repo, "abc")
+			So(st.Save(path), ShouldBeNil)
+
+			loaded, err := core.LoadScanState(path)
+			So(err, ShouldBeNil)
+			So(loaded.LastCommit(repo), ShouldEqual, "abc")
+		})
+
+		Convey("Saving should merge in repositories written by another session", func() {
+			otherURL := "https://github.com/acme/web.git"
+			other, err := core.LoadScanState(path)
+			So(err, ShouldBeNil)
+			other.Scanned(&core.Repository{CloneURL: &otherURL}, "def")
+			So(other.Save(path), ShouldBeNil)
+
+			st.Scanned(repo, "abc")
+			So(st.Save(path), ShouldBeNil)
+
+			loaded, err := core.LoadScanState(path)
+			So(err, ShouldBeNil)
+			So(loaded.Repositories, ShouldHaveLength, 2)
+		})
+
+		Convey("A nil state should not have scanned anything", func() {
+			var none *core.ScanState
+			none.Scanned(repo, "abc")
+			So(none.LastCommit(repo), ShouldEqual, "")
+		})
+
+		Convey("The last commit of every branch should be returned, the default branch first", func() {
+			st.ScannedBranch(repo, "feature", "fff")
+			st.Scanned(repo, "abc")
+			st.ScannedBranch(&core.Repository{CloneURL: &url}, "bugfix", "bbb")
+			So(st.LastCommits(repo), ShouldResemble, []string{"abc", "bbb", "fff"})
+			So(st.LastCommit(repo), ShouldEqual, "abc")
+		})
+	})
+
+	Convey("Given a repository with three commits", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-history")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		r, err := git.PlainInit(dir, false)
+		So(err, ShouldBeNil)
+		wt, err := r.Worktree()
+		So(err, ShouldBeNil)
+
+		var hashes []plumbing.Hash
+		for _, name := range []string{"a", "b", "c"} {
+			So(ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0600), ShouldBeNil)
+			_, err := wt.Add(name)
+			So(err, ShouldBeNil)
+			hash, err := wt.Commit(name, &git.CommitOptions{
+				Author: &object.Signature{Name: "jane", Email: "jane@example.com", When: time.Now()},
+			})
+			So(err, ShouldBeNil)
+			hashes = append(hashes, hash)
+		}
+
+		Convey("Only the commits added since the last scan should be returned", func() {
+			commits, err := core.GetRepositoryHistorySince(r, hashes[0].String())
+			So(err, ShouldBeNil)
+			So(commits, ShouldHaveLength, 2)
+			So(commits[0].Hash, ShouldEqual, hashes[2])
+			So(commits[1].Hash, ShouldEqual, hashes[1])
+		})
+
+		Convey("Nothing should be returned when there are no new commits", func() {
+			commits, err := core.GetRepositoryHistorySince(r, hashes[2].String())
+			So(err, ShouldBeNil)
+			So(commits, ShouldBeEmpty)
+		})
+
+		Convey("The whole history should be returned when the last commit is gone", func() {
+			commits, err := core.GetRepositoryHistorySince(r, "0123456789abcdef0123456789abcdef01234567")
+			So(err, ShouldBeNil)
+			So(commits, ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("Given a clone of a repository with a feature branch", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-branches")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		origin := filepath.Join(dir, "origin")
+		r, err := git.PlainInit(origin, false)
+		So(err, ShouldBeNil)
+		wt, err := r.Worktree()
+		So(err, ShouldBeNil)
+
+		commit := func(name string) plumbing.Hash {
+			So(ioutil.WriteFile(filepath.Join(origin, name), []byte(name), 0600), ShouldBeNil)
+			_, err := wt.Add(name)
+			So(err, ShouldBeNil)
+			hash, err := wt.Commit(name, &git.CommitOptions{
+				Author: &object.Signature{Name: "jane", Email: "jane@example.com", When: time.Now()},
+			})
+			So(err, ShouldBeNil)
+			return hash
+		}
+
+		base := commit("a")
+		So(wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}), ShouldBeNil)
+		feature := commit("f")
+		So(wt.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}), ShouldBeNil)
+		master := commit("b")
+
+		clone, err := git.PlainClone(filepath.Join(dir, "clone"), false, &git.CloneOptions{URL: origin, Tags: git.NoTags})
+		So(err, ShouldBeNil)
+
+		Convey("Only the default branch should be scanned unless branches are asked for", func() {
+			heads, err := core.GetBranchHeads(clone, nil)
+			So(err, ShouldBeNil)
+			So(heads, ShouldResemble, []core.BranchHead{{Name: "master", Hash: master}})
+
+			heads, err = core.GetBranchHeads(clone, []string{"release"})
+			So(err, ShouldBeNil)
+			So(heads, ShouldHaveLength, 1)
+		})
+
+		Convey("Every branch should be walked once, with each commit on the first branch it was reached from", func() {
+			heads, err := core.GetBranchHeads(clone, []string{core.ScanBranchesAll})
+			So(err, ShouldBeNil)
+			So(heads, ShouldResemble, []core.BranchHead{{Name: "master", Hash: master}, {Name: "feature", Hash: feature}})
+
+			commits, branches, err := core.GetBranchHistory(clone, heads, nil)
+			So(err, ShouldBeNil)
+			So(commits, ShouldHaveLength, 3)
+			So(branches[base], ShouldEqual, "master")
+			So(branches[master], ShouldEqual, "master")
+			So(branches[feature], ShouldEqual, "feature")
+		})
+
+		Convey("Commits reachable from the last commit of any branch should be left out", func() {
+			heads, err := core.GetBranchHeads(clone, []string{"feature"})
+			So(err, ShouldBeNil)
+
+			commits, _, err := core.GetBranchHistory(clone, heads, []string{base.String(), feature.String()})
+			So(err, ShouldBeNil)
+			So(commits, ShouldHaveLength, 1)
+			So(commits[0].Hash, ShouldEqual, master)
+		})
+	})
+}
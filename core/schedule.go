@@ -0,0 +1,249 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"wraith/rpc"
+
+	"github.com/spf13/viper"
+)
+
+// ScanTypeServe is the scan type of the session of the serve command, which does not scan anything itself and only
+// runs the scans of its schedules
+const ScanTypeServe = "serve"
+
+// scheduleMaxWait is the longest the scheduler sleeps for, so a change of the clock or a suspended machine only
+// delays a scan by this much
+const scheduleMaxWait = time.Minute
+
+// Schedule is a scan that the serve command runs whenever its cron expression matches. The settings are applied on
+// top of those of the server, so a schedule only has to give what is different about it.
+type Schedule struct {
+	Name     string                 `mapstructure:"name"`
+	Cron     string                 `mapstructure:"cron"`
+	TimeZone string                 `mapstructure:"time-zone"`
+	ScanType string                 `mapstructure:"scan-type"`
+	Targets  []string               `mapstructure:"targets"`
+	Settings map[string]interface{} `mapstructure:"settings"`
+
+	cron     *CronSchedule
+	location *time.Location
+
+	// the state of the schedule, which is guarded by the scheduler
+	next     time.Time
+	lastRun  time.Time
+	lastScan string
+	skipped  int
+}
+
+// ScheduleStatus is a schedule as it is shown by the api, along with when it runs next and the last scan it ran
+type ScheduleStatus struct {
+	Name     string     `json:"name"`
+	Cron     string     `json:"cron"`
+	TimeZone string     `json:"time_zone"`
+	ScanType string     `json:"scan_type"`
+	Targets  []string   `json:"targets"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	LastScan *APIScan   `json:"last_scan,omitempty"`
+	Skipped  int        `json:"skipped"`
+}
+
+// LoadSchedules will read the schedules of a config file and check that each of them can be run, so a mistake is
+// found when the server starts rather than in the middle of the night
+func LoadSchedules(v *viper.Viper) ([]*Schedule, error) {
+	var schedules []*Schedule
+	if err := v.UnmarshalKey("schedules", &schedules); err != nil {
+		return nil, fmt.Errorf("the schedules are not valid: %s", err)
+	}
+
+	names := make(map[string]bool)
+	for i, sched := range schedules {
+		if sched.Name == "" {
+			return nil, fmt.Errorf("schedule %d does not have a name", i+1)
+		}
+		if names[sched.Name] {
+			return nil, fmt.Errorf("there is more than one schedule named %s", sched.Name)
+		}
+		names[sched.Name] = true
+
+		cron, err := ParseCron(sched.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %s", sched.Name, err)
+		}
+		sched.cron = cron
+
+		sched.location = time.Local
+		if sched.TimeZone != "" {
+			if sched.location, err = time.LoadLocation(sched.TimeZone); err != nil {
+				return nil, fmt.Errorf("schedule %s: unknown time zone %s", sched.Name, sched.TimeZone)
+			}
+		}
+
+		if _, err := sched.Config(v); err != nil {
+			return nil, fmt.Errorf("schedule %s: %s", sched.Name, err)
+		}
+	}
+	return schedules, nil
+}
+
+// Config will build the configuration of a scan of the schedule, which is the configuration of the server with the
+// settings of the schedule applied on top. It is checked the same way as a scan submitted through the api.
+func (sched *Schedule) Config(base *viper.Viper) (*viper.Viper, error) {
+	if sched.ScanType == "" {
+		return nil, errors.New("a scan-type is required")
+	}
+
+	v := viper.New()
+	for _, k := range base.AllKeys() {
+		if k != "schedules" {
+			v.Set(k, base.Get(k))
+		}
+	}
+	for k, value := range sched.Settings {
+		v.Set(k, value)
+	}
+
+	return NewScanConfig(v, &rpc.SubmitScanRequest{
+		ScanType:     sched.ScanType,
+		Targets:      sched.Targets,
+		CommitDepth:  int32(v.GetInt("commit-depth")),
+		HideSecrets:  v.GetBool("hide-secrets"),
+		ScanTests:    v.GetBool("scan-tests"),
+		NoExpandOrgs: v.GetBool("no-expand-orgs"),
+		Redact:       v.GetString("redact"),
+	})
+}
+
+// Scheduler starts the scans of the schedules of the serve command when they are due
+type Scheduler struct {
+	sync.Mutex
+
+	base      *viper.Viper
+	manager   *ScanManager
+	out       *Logger
+	schedules []*Schedule
+}
+
+// NewScheduler will create a scheduler for the given schedules, working out when each of them runs first from now.
+// The scans inherit the configuration of base and are tracked by the manager.
+func NewScheduler(base *viper.Viper, manager *ScanManager, schedules []*Schedule, out *Logger, now time.Time) *Scheduler {
+	for _, sched := range schedules {
+		sched.next = sched.cron.Next(now.In(sched.location))
+	}
+	return &Scheduler{
+		base:      base,
+		manager:   manager,
+		out:       out,
+		schedules: schedules,
+	}
+}
+
+// Run will start the scans of the schedules as they become due until stop is closed
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		wait := scheduleMaxWait
+		if next := s.nextRun(); !next.IsZero() && time.Until(next) < wait {
+			wait = time.Until(next)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			s.Tick(now)
+		}
+	}
+}
+
+// nextRun is the earliest time any of the schedules is due, the zero time when none of them ever are
+func (s *Scheduler) nextRun() time.Time {
+	s.Lock()
+	defer s.Unlock()
+	var next time.Time
+	for _, sched := range s.schedules {
+		if !sched.next.IsZero() && (next.IsZero() || sched.next.Before(next)) {
+			next = sched.next
+		}
+	}
+	return next
+}
+
+// Tick will start the scan of every schedule that is due at now. A schedule whose last scan is still running is
+// skipped rather than scanning the same targets twice at once. A schedule that was due more than once since the last
+// tick, such as after the machine was suspended, only runs once.
+func (s *Scheduler) Tick(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, sched := range s.schedules {
+		if sched.next.IsZero() || now.Before(sched.next) {
+			continue
+		}
+		sched.next = sched.cron.Next(now.In(sched.location))
+
+		if s.running(sched) {
+			sched.skipped++
+			s.out.Warn("Skipping the %s scan of schedule %s, its last scan %s is still running\n", sched.ScanType, sched.Name, sched.lastScan)
+			continue
+		}
+
+		v, err := sched.Config(s.base)
+		if err != nil {
+			s.out.Error("Unable to start the scan of schedule %s: %s\n", sched.Name, err)
+			continue
+		}
+		sched.lastRun = now
+		sched.lastScan = s.manager.Start(v, sched.ScanType, sched.Name)
+		s.out.Important("Started scan %s of schedule %s\n", sched.lastScan, sched.Name)
+	}
+}
+
+// running will check whether the last scan a schedule started has yet to stop
+func (s *Scheduler) running(sched *Schedule) bool {
+	if sched.lastScan == "" {
+		return false
+	}
+	scan, ok := s.manager.Get(sched.lastScan)
+	if !ok {
+		return false
+	}
+	st := scan.Status()
+	return st.Status != StatusFailed && st.FinishedAt == 0
+}
+
+// Schedules will return the state of every schedule, in the order of the config file
+func (s *Scheduler) Schedules() []ScheduleStatus {
+	s.Lock()
+	defer s.Unlock()
+
+	statuses := []ScheduleStatus{}
+	for _, sched := range s.schedules {
+		st := ScheduleStatus{
+			Name:     sched.Name,
+			Cron:     sched.Cron,
+			TimeZone: sched.location.String(),
+			ScanType: sched.ScanType,
+			Targets:  sched.Targets,
+			Skipped:  sched.skipped,
+		}
+		if !sched.next.IsZero() {
+			next := sched.next
+			st.NextRun = &next
+		}
+		if !sched.lastRun.IsZero() {
+			lastRun := sched.lastRun
+			st.LastRun = &lastRun
+		}
+		if scan, ok := s.manager.Get(sched.lastScan); ok {
+			apiScan := scan.apiScan()
+			st.LastScan = &apiScan
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
@@ -0,0 +1,260 @@
+package core
+
+// JSONSchemaVersion is the version of the json and jsonl output format. The minor version is bumped when fields are
+// added and the major version when fields are renamed, removed, or change meaning.
+const JSONSchemaVersion = "1.17.0"
+
+// JSONSchema is the published JSON Schema for the json and jsonl output, printed by `wraith schema`. It must be kept
+// in sync with docs/schema/wraith-output.schema.json.
+const JSONSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/N0MoreSecr3ts/wraith/blob/master/docs/schema/wraith-output.schema.json",
+  "title": "wraith scan report",
+  "description": "The document written by --json. Each line written by --jsonl and the document handed to --on-finding-exec is a finding as described in #/definitions/finding. The document handed to --on-repo-complete-exec is described in #/definitions/repository.",
+  "type": "object",
+  "required": ["schema_version", "wraith_version", "signatures_version", "scan_type", "started_at", "finished_at", "stats", "risk", "findings"],
+  "properties": {
+    "schema_version": {
+      "description": "The version of this schema the document conforms to. Minor versions only add fields.",
+      "type": "string",
+      "const": "1.17.0"
+    },
+    "wraith_version": {"type": "string"},
+    "signatures_version": {"type": "string"},
+    "scan_type": {
+      "description": "The command that produced the report, for example github, gitlab, localGit, or localPath",
+      "type": "string"
+    },
+    "started_at": {"type": "string", "format": "date-time"},
+    "finished_at": {"type": "string", "format": "date-time"},
+    "interrupted": {
+      "description": "Set when the scan was stopped by SIGINT or SIGTERM before it finished, so the findings are only those made until then",
+      "type": "boolean"
+    },
+    "stats": {"$ref": "#/definitions/stats"},
+    "risk": {"$ref": "#/definitions/risk"},
+    "errors": {
+      "description": "The repositories and targets that could not be scanned, in the order they failed. Absent when everything was scanned",
+      "type": "array",
+      "items": {"$ref": "#/definitions/scan_error"}
+    },
+    "secrets": {
+      "description": "The findings grouped by their secret, ordered from the most severe and then from the most widespread. Findings without a secret, such as a matched file name, are not in a group",
+      "type": "array",
+      "items": {"$ref": "#/definitions/secret_group"}
+    },
+    "findings": {
+      "type": "array",
+      "items": {"$ref": "#/definitions/finding"}
+    }
+  },
+  "definitions": {
+    "stats": {
+      "type": "object",
+      "required": ["commits_dirty", "commits_scanned", "files_dirty", "files_ignored", "files_scanned", "files_total", "findings_total", "repositories_cloned", "repositories_scanned", "repositories_total", "targets"],
+      "properties": {
+        "commits_dirty": {"type": "integer", "description": "Commits that contained at least one finding"},
+        "commits_scanned": {"type": "integer"},
+        "files_dirty": {"type": "integer", "description": "Files that contained at least one finding"},
+        "files_ignored": {"type": "integer", "description": "Files skipped due to size, extension, path, or being a test file"},
+        "files_scanned": {"type": "integer"},
+        "files_total": {"type": "integer"},
+        "findings_total": {"type": "integer"},
+        "repositories_cloned": {"type": "integer"},
+        "repositories_scanned": {"type": "integer"},
+        "repositories_total": {"type": "integer"},
+        "targets": {"type": "integer", "description": "Users, orgs, groups, or directories that were enumerated"}
+      }
+    },
+    "risk": {
+      "type": "object",
+      "required": ["score", "organizations", "repositories"],
+      "properties": {
+        "score": {"type": "integer", "minimum": 0, "maximum": 100, "description": "The risk of every finding in the scan"},
+        "organizations": {
+          "description": "Ordered from the most to the least risky",
+          "type": "array",
+          "items": {"$ref": "#/definitions/risk_score"}
+        },
+        "repositories": {
+          "description": "Ordered from the most to the least risky",
+          "type": "array",
+          "items": {"$ref": "#/definitions/risk_score"}
+        }
+      }
+    },
+    "risk_score": {
+      "type": "object",
+      "required": ["owner", "score", "findings"],
+      "properties": {
+        "owner": {"type": "string"},
+        "name": {"type": "string", "description": "Only present for repositories"},
+        "score": {"type": "integer", "minimum": 0, "maximum": 100},
+        "findings": {"type": "integer"},
+        "repositories": {"type": "integer", "description": "Only present for organizations"}
+      }
+    },
+    "scan_error": {
+      "type": "object",
+      "required": ["name", "stage", "error", "attempts"],
+      "properties": {
+        "name": {"type": "string", "description": "The full name of the repository, or the login of the target"},
+        "stage": {"type": "string", "enum": ["target", "repositories", "clone", "history"], "description": "What failed, looking up the target, listing its repositories, cloning the repository or reading its history"},
+        "error": {"type": "string"},
+        "attempts": {"type": "integer", "minimum": 1, "description": "How many times it was tried, more than once when a clone was retried with --retries"}
+      }
+    },
+    "secret_group": {
+      "type": "object",
+      "required": ["secret_hash", "secret", "description", "signature_ids", "severity", "at_head", "occurrences", "repositories", "files", "commits"],
+      "properties": {
+        "secret_hash": {"type": "string", "description": "The secret_hash of the findings in the group"},
+        "secret": {"type": "string", "description": "The secret, redacted the same way as the comment of each finding"},
+        "description": {"type": "string", "description": "The description of the signature of the most severe finding"},
+        "signature_ids": {"type": "array", "items": {"type": "string"}},
+        "severity": {"type": "string", "enum": ["low", "medium", "high", "critical"], "description": "The severity of the most severe finding"},
+        "verification": {"type": "string", "enum": ["verified", "inactive", "unknown"], "description": "The most telling result of verifying the findings, verified before inactive before unknown"},
+        "at_head": {"type": "boolean", "description": "Whether the secret is still in the latest commit of any repository"},
+        "occurrences": {"type": "integer", "description": "The number of findings of the secret"},
+        "repositories": {"type": "array", "items": {"type": "string"}, "description": "The repositories the secret was found in as owner/name"},
+        "files": {"type": "integer", "description": "The number of files the secret was found in across the repositories"},
+        "commits": {"type": "integer", "description": "The number of commits the secret was found in across the repositories"},
+        "first_commit_date": {"type": "string", "format": "date-time", "description": "The date of the earliest commit the secret was found in"},
+        "last_commit_date": {"type": "string", "format": "date-time", "description": "The date of the latest commit the secret was found in"}
+      }
+    },
+    "repository": {
+      "type": "object",
+      "required": ["schema_version", "clone_url", "default_branch", "full_name", "name", "owner", "risk_score", "url", "findings"],
+      "properties": {
+        "schema_version": {"type": "string"},
+        "clone_url": {"type": "string"},
+        "default_branch": {"type": "string"},
+        "full_name": {"type": "string"},
+        "name": {"type": "string"},
+        "owner": {"type": "string"},
+        "risk_score": {"type": "integer", "minimum": 0, "maximum": 100},
+        "url": {"type": "string"},
+        "visibility": {"type": "string", "enum": ["public", "private", "internal"], "description": "Absent when it is not known, such as for local repositories"},
+        "fork": {"type": "boolean"},
+        "archived": {"type": "boolean"},
+        "pushed_at": {"type": "string", "format": "date-time", "description": "The last push to github or activity on gitlab"},
+        "topics": {"type": "array", "items": {"type": "string"}},
+        "findings": {
+          "type": "array",
+          "items": {"$ref": "#/definitions/finding"}
+        }
+      }
+    },
+    "finding": {
+      "type": "object",
+      "required": ["action", "at_head", "comment", "confidence", "commit_author", "commit_hash", "commit_message", "commit_url", "description", "file_path", "file_url", "fingerprint", "line_number", "repository_name", "repository_owner", "repository_url", "score", "secret_id", "severity", "signature_id", "signatures_version", "wraith_version"],
+      "properties": {
+        "schema_version": {"type": "string", "description": "Only present when a finding is written on its own"},
+        "action": {"type": "string", "description": "How the file was changed in the commit, for example Insert, Modify, or Delete, or File Scan outside of git"},
+        "at_head": {"type": "boolean", "description": "The secret is still present in the latest commit, always true for local path scans"},
+        "code_owners": {"type": "array", "items": {"type": "string"}, "description": "The owners of the file from the CODEOWNERS file of the repository"},
+        "comment": {"type": "string", "description": "The matched secret after the --redact mode has been applied"},
+        "confidence": {"type": "string", "enum": ["low", "medium", "high"], "description": "How likely the match is to be a real secret, the confidence of the signature unless changed by a finding script"},
+        "context": {
+          "type": "array",
+          "description": "The matched line and the --context-lines around it, with secrets redacted the same way as the comment. Absent when the line is not known",
+          "items": {
+            "type": "object",
+            "required": ["line_number", "content"],
+            "properties": {
+              "line_number": {"type": "integer", "minimum": 1},
+              "content": {"type": "string"}
+            }
+          }
+        },
+        "commit_author": {"type": "string"},
+        "commit_author_email": {"type": "string", "description": "The email of the author of the commit the secret was introduced in, or of the git user for staged and working tree findings"},
+        "commit_author_name": {"type": "string"},
+        "commit_date": {"type": "string", "format": "date-time", "description": "When the commit the secret was introduced in was written, in the time zone of its author. Absent outside of git history"},
+        "commit_hash": {"type": "string", "description": "Empty for local path scans"},
+        "commit_message": {"type": "string"},
+        "commit_url": {"type": "string"},
+        "committer_email": {"type": "string", "description": "The email of whoever committed the commit, which differs from the author when it was rebased, cherry picked or applied from a patch"},
+        "committer_name": {"type": "string"},
+        "description": {"type": "string", "description": "The description of the signature that matched"},
+        "encoding": {"type": "string", "enum": ["base64", "hex", "url", "utf-16le"], "description": "The encoding the secret was decoded from when it was found with --decode, or utf-16le when it was found in a utf-16 string of a binary file with --scan-binaries. Absent when it was found as it is"},
+        "file_path": {"type": "string"},
+        "file_url": {"type": "string"},
+        "fingerprint": {"type": "string", "description": "A sha256 of the repository, file, signature, secret and line that is the same in every commit and scan the secret is found in"},
+        "first_seen": {
+          "type": "object",
+          "description": "The earliest commit the same secret was found in the same place in, when it was found in more than one and it is not the commit of the finding. It is where the secret was introduced",
+          "required": ["commit_hash"],
+          "properties": {
+            "commit_hash": {"type": "string"},
+            "commit_date": {"type": "string", "format": "date-time"},
+            "commit_author": {"type": "string"},
+            "commit_url": {"type": "string"},
+            "file_url": {"type": "string"}
+          }
+        },
+        "jwt": {
+          "type": "object",
+          "description": "The claims of the json web token in the secret, decoded without checking its signature. Absent when the secret is not a token",
+          "required": ["algorithm", "expired"],
+          "properties": {
+            "algorithm": {"type": "string", "description": "The alg of the header of the token"},
+            "issuer": {"type": "string"},
+            "subject": {"type": "string"},
+            "audience": {"type": "array", "items": {"type": "string"}},
+            "scopes": {"type": "array", "items": {"type": "string"}, "description": "The scope, scp or scopes claim of the token"},
+            "issued_at": {"type": "string", "format": "date-time"},
+            "expires_at": {"type": "string", "format": "date-time", "description": "Absent when the token does not expire"},
+            "expired": {"type": "boolean", "description": "The token had expired when it was found, which rates the finding low severity"}
+          }
+        },
+        "labels": {
+          "type": "object",
+          "description": "Extra information added by a finding script",
+          "additionalProperties": {"type": "string"}
+        },
+        "last_seen": {
+          "type": "object",
+          "description": "The latest commit the same secret was found in the same place in, when it was found in more than one and it is not the commit of the finding",
+          "required": ["commit_hash"],
+          "properties": {
+            "commit_hash": {"type": "string"},
+            "commit_date": {"type": "string", "format": "date-time"},
+            "commit_author": {"type": "string"},
+            "commit_url": {"type": "string"},
+            "file_url": {"type": "string"}
+          }
+        },
+        "line_number": {"type": "string", "description": "The line the match was found on, 0 when unknown"},
+        "occurrences": {"type": "integer", "minimum": 1, "description": "How many commits, branches or layers the same secret was found in the same place in, merged into this finding. Absent when findings are not deduplicated"},
+        "remediation": {
+          "type": "object",
+          "description": "How to deal with the kind of secret the signature finds, from the remediation of the signature. Absent when the signature has none",
+          "properties": {
+            "rotation_url": {"type": "string", "format": "uri", "description": "Where the secret is revoked and replaced"},
+            "docs_url": {"type": "string", "format": "uri", "description": "The documentation on rotating the secret"},
+            "vault": {"type": "string", "description": "Where the new secret should be kept rather than in the code"}
+          }
+        },
+        "repository_archived": {"type": "boolean"},
+        "repository_fork": {"type": "boolean"},
+        "repository_name": {"type": "string"},
+        "repository_owner": {"type": "string"},
+        "repository_pushed_at": {"type": "string", "format": "date-time"},
+        "repository_topics": {"type": "array", "items": {"type": "string"}},
+        "repository_url": {"type": "string"},
+        "repository_visibility": {"type": "string", "enum": ["public", "private", "internal"]},
+        "score": {"type": "integer", "description": "The match level of the signature unless changed by a finding script, 0 for plugin findings"},
+        "secret_hash": {"type": "string", "description": "The hmac-sha256 of the secret with the whitespace and quotes around it left out, keyed with the key of the install, shared by every finding of the same secret. Absent for findings without a secret"},
+        "secret_id": {"type": "string", "description": "A sha1 identifying the finding"},
+        "severity": {"type": "string", "enum": ["low", "medium", "high", "critical"], "description": "The severity of the signature unless changed by a finding script or policy"},
+        "signature_id": {"type": "string"},
+        "signatures_version": {"type": "string"},
+        "verification": {"type": "string", "enum": ["verified", "inactive", "unknown"], "description": "the result of checking the secret against the service it belongs to when --verify is set, absent when it was not checked"},
+        "wraith_version": {"type": "string"}
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,39 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"testing"
+	"wraith/core"
+)
+
+func TestJSONSchema(t *testing.T) {
+
+	Convey("Given the embedded JSON Schema", t, func() {
+
+		Convey("When it is parsed", func() {
+			var doc map[string]interface{}
+			err := json.Unmarshal([]byte(core.JSONSchema), &doc)
+
+			Convey("It should be valid json", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("The schema version should match the version written to the output", func() {
+				props := doc["properties"].(map[string]interface{})
+				version := props["schema_version"].(map[string]interface{})
+				So(version["const"], ShouldEqual, core.JSONSchemaVersion)
+			})
+		})
+
+		Convey("When it is compared to the published schema", func() {
+			published, err := ioutil.ReadFile("../docs/schema/wraith-output.schema.json")
+
+			Convey("They should be identical", func() {
+				So(err, ShouldBeNil)
+				So(string(published), ShouldEqual, core.JSONSchema)
+			})
+		})
+	})
+}
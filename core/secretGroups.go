@@ -0,0 +1,212 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// SecretGroup is one secret along with every place it was found. A leaked key is usually committed to several files,
+// branches and repositories, so its findings are grouped by the hash of the secret to triage it once.
+type SecretGroup struct {
+	SecretHash      string   `json:"secret_hash"`
+	Secret          string   `json:"secret"`
+	Description     string   `json:"description"`
+	SignatureIDs    []string `json:"signature_ids"`
+	Severity        string   `json:"severity"`
+	Verification    string   `json:"verification,omitempty"`
+	AtHead          bool     `json:"at_head"`
+	Occurrences     int      `json:"occurrences"`
+	Repositories    []string `json:"repositories"`
+	Files           int      `json:"files"`
+	Commits         int      `json:"commits"`
+	FirstCommitDate string   `json:"first_commit_date,omitempty"`
+	LastCommitDate  string   `json:"last_commit_date,omitempty"`
+}
+
+// verificationRanks orders the results of verification so a group shows the most telling one of its findings
+var verificationRanks = map[string]int{"": 0, VerificationUnknown: 1, VerificationInactive: 2, VerificationVerified: 3}
+
+// normalizeSecret will leave out the whitespace and quotes around a secret, so the same value is the same secret
+// whether it was quoted in a config file or not
+func normalizeSecret(secret string) string {
+	return strings.Trim(strings.TrimSpace(secret), `"'`)
+}
+
+// SecretHashKeyFile is where the key the secrets of findings are hashed with is kept, so a secret has the same hash in
+// every scan made on the install
+const SecretHashKeyFile = "$HOME/.wraith/secret-hash.key"
+
+// secretHashKey is the key of SecretHashKeyFile, created the first time it is needed. When it can not be read or
+// written a key of the process is used instead.
+var secretHashKey struct {
+	sync.Once
+	key []byte
+}
+
+// loadSecretHashKey will read the key secrets are hashed with, creating it if the install does not have one yet
+func loadSecretHashKey() []byte {
+	secretHashKey.Do(func() {
+		key := make([]byte, 32)
+		rand.Read(key)
+		secretHashKey.key = key
+
+		home, err := homedir.Dir()
+		if err != nil {
+			return
+		}
+		file := strings.Replace(SecretHashKeyFile, "$HOME", home, 1)
+		if existing, err := ioutil.ReadFile(file); err == nil && len(existing) >= 32 {
+			secretHashKey.key = existing
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			return
+		}
+		fh, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			// another process made the key first
+			if existing, err := ioutil.ReadFile(file); err == nil && len(existing) >= 32 {
+				secretHashKey.key = existing
+			}
+			return
+		}
+		defer fh.Close()
+		fh.Write(key)
+	})
+	return secretHashKey.key
+}
+
+// setSecretHash will identify the secret of a finding by an hmac of it, so its findings can be grouped. A plain sha256
+// would let anyone with a report check a guess at the secret however it was redacted, so the hmac is keyed with the
+// key of the install. Findings without a secret, such as a matched file name, are left without one.
+func (f *Finding) setSecretHash() {
+	if secret := normalizeSecret(f.secret); secret != "" {
+		f.SecretHash = SecretHash(secret)
+	}
+}
+
+// SecretHash is the hash the findings of a secret are grouped by
+func SecretHash(secret string) string {
+	mac := hmac.New(sha256.New, loadSecretHashKey())
+	mac.Write([]byte(secret))
+	return fmt.Sprintf("hmac-sha256:%x", mac.Sum(nil))
+}
+
+// NewSecretGroups will group findings by their secret. Secrets are ordered from the most severe and then from the most
+// widespread, so a key leaked in hundreds of places comes before one found once.
+func NewSecretGroups(findings []*Finding) []SecretGroup {
+	type tally struct {
+		group        *SecretGroup
+		signatures   map[string]bool
+		repositories map[string]bool
+		files        map[string]bool
+		commits      map[string]bool
+		first, last  time.Time
+	}
+
+	var order []string
+	tallies := map[string]*tally{}
+	for _, f := range findings {
+		if f.SecretHash == "" {
+			continue
+		}
+		t, ok := tallies[f.SecretHash]
+		if !ok {
+			t = &tally{
+				group:        &SecretGroup{SecretHash: f.SecretHash, Secret: f.Comment, Description: f.Description},
+				signatures:   map[string]bool{},
+				repositories: map[string]bool{},
+				files:        map[string]bool{},
+				commits:      map[string]bool{},
+			}
+			tallies[f.SecretHash] = t
+			order = append(order, f.SecretHash)
+		}
+
+		g := t.group
+		g.Occurrences += f.occurrences()
+		g.AtHead = g.AtHead || f.AtHead
+		if severity := FindingSeverity(f); severityRanks[severity] > severityRanks[g.Severity] {
+			g.Severity = severity
+			g.Description = f.Description
+		}
+		if verificationRanks[f.Verification] > verificationRanks[g.Verification] {
+			g.Verification = f.Verification
+		}
+		repository := f.RepositoryOwner + "/" + f.RepositoryName
+		t.signatures[f.Signatureid] = true
+		t.repositories[repository] = true
+		t.files[repository+"\x00"+f.FilePath] = true
+		seen := []*SeenCommit{f.seenCommit()}
+		if f.FirstSeen != nil {
+			seen = append(seen, f.FirstSeen)
+		}
+		if f.LastSeen != nil {
+			seen = append(seen, f.LastSeen)
+		}
+		for _, c := range seen {
+			if c.CommitHash != "" {
+				t.commits[repository+"\x00"+c.CommitHash] = true
+			}
+			if date, err := time.Parse(time.RFC3339, c.CommitDate); err == nil {
+				if t.first.IsZero() || date.Before(t.first) {
+					t.first = date
+				}
+				if date.After(t.last) {
+					t.last = date
+				}
+			}
+		}
+	}
+
+	groups := []SecretGroup{}
+	for _, hash := range order {
+		t := tallies[hash]
+		g := t.group
+		g.SignatureIDs = sortedKeys(t.signatures)
+		g.Repositories = sortedKeys(t.repositories)
+		g.Files = len(t.files)
+		g.Commits = len(t.commits)
+		if !t.first.IsZero() {
+			g.FirstCommitDate = t.first.UTC().Format(time.RFC3339)
+			g.LastCommitDate = t.last.UTC().Format(time.RFC3339)
+		}
+		groups = append(groups, *g)
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		if severityRanks[groups[a].Severity] != severityRanks[groups[b].Severity] {
+			return severityRanks[groups[a].Severity] > severityRanks[groups[b].Severity]
+		}
+		return groups[a].Occurrences > groups[b].Occurrences
+	})
+	return groups
+}
+
+// SecretGroups will group the findings of the session by their secret
+func (s *Session) SecretGroups() []SecretGroup {
+	s.Lock()
+	defer s.Unlock()
+	return NewSecretGroups(s.Findings)
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,6 +4,7 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"runtime"
@@ -34,75 +35,450 @@ var defaultIgnoreExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp",
 var defaultIgnorePaths = []string{"node_modules/", "vendor/bundle", "vendor/cache", "/proc/"}
 
 var DefaultValues = map[string]interface{}{
-	"bind-address":     "127.0.0.1",
-	"bind-port":        9393,
-	"commit-depth":     0,
-	"config-file":      "$HOME/.wraith/config.yaml",
-	"debug":            false,
-	"github-targets":   "",
-	"github-api-token": "0123456789ABCDEFGHIJKLMNOPQRSTUVWXVZabcd",
-	"gitlab-targets":   "",
-	"gitlab-api-token": "0123456789ABCDEFGHIJ",
-	"ignore-extension": "",
-	"ignore-path":      "",
-	"in-mem-clone":     false,
-	"max-file-size":    50,
-	"num-threads":      0,
-	"local-dirs":       nil,
-	"local-files":      nil,
-	"scan-forks":       true,
-	"scan-tests":       false,
-	"scan-type":        "",
-	"silent":           false,
-	"csv":              false,
-	"json":             false,
-	"match-level":      3,
-	"signature-file":   "$HOME/.wraith/signatures/default.yml",
-	"signature-path":   "$HOME/.wraith/signatures/",
-	"signature-url":    "",
-	"scan-dir":         "",
-	"scan-file":        "",
-	"hide-secrets":     false,
+	"alert-state-file":          "",
+	"allow-local-scans":         false,
+	"api-scans":                 false,
+	"aws-profile":               "",
+	"azure-devops-targets":      "",
+	"azure-devops-token":        "",
+	"archive-depth":             2,
+	"archive-max-size":          100,
+	"azure-devops-url":          AzureDevopsCloudURL,
+	"bind-address":              "127.0.0.1",
+	"bind-port":                 9393,
+	"binary-min-length":         8,
+	"bitbucket-app-password":    "",
+	"bitbucket-oauth-token":     "",
+	"bitbucket-server-targets":  "",
+	"bitbucket-server-token":    "",
+	"bitbucket-server-url":      "",
+	"bitbucket-server-username": "",
+	"bitbucket-targets":         "",
+	"bitbucket-username":        "",
+	"ca-bundle":                 "",
+	"ci":                        false,
+	"clone-cache":               "",
+	"clone-over-ssh":            false,
+	"clone-threads":             0,
+	"checkpoint":                "",
+	"checkpoint-interval":       int(DefaultCheckpointInterval / time.Second),
+	"codecommit-endpoint":       "",
+	"codecommit-role-arns":      "",
+	"codecommit-targets":        "",
+	"commit-depth":              0,
+	"config-file":               "$HOME/.wraith/config.yaml",
+	"confluence-attachments":    true,
+	"confluence-spaces":         "",
+	"confluence-token":          "",
+	"confluence-url":            "",
+	"confluence-username":       "",
+	"context-lines":             2,
+	"dedup-findings":            true,
+	"debug":                     false,
+	"debug-server":              "",
+	"debug-server-public":       false,
+	"decode":                    false,
+	"decode-min-length":         20,
+	"defectdojo-close-old":      false,
+	"defectdojo-engagement":     "",
+	"defectdojo-product":        "",
+	"defectdojo-test-title":     Name,
+	"defectdojo-token":          "",
+	"defectdojo-url":            "",
+	"detector-plugins":          "",
+	"docker-archives":           "",
+	"docker-images":             "",
+	"docker-password":           "",
+	"docker-platform":           DockerDefaultPlatform,
+	"docker-username":           "",
+	"elasticsearch-api-key":     "",
+	"elasticsearch-index":       "wraith",
+	"elasticsearch-password":    "",
+	"elasticsearch-url":         "",
+	"elasticsearch-username":    "",
+	"encrypt-report":            "",
+	"entropy":                   false,
+	"entropy-base64-min-length": 20,
+	"entropy-base64-threshold":  4.5,
+	"entropy-hex-min-length":    20,
+	"entropy-hex-threshold":     3.0,
+	"exclude-archived":          false,
+	"expand-members-gists":      true,
+	"expand-members-repos":      true,
+	"exclude-repos":             "",
+	"fail-on":                   "",
+	"finding-script":            "",
+	"gitea-api-token":           "",
+	"gitea-targets":             "",
+	"gitea-url":                 "",
+	"github-pull-requests":      "",
+	"github-targets":            "",
+	"github-api-url":            "",
+	"all-orgs":                  false,
+	"all-users":                 false,
+	"github-api-token":          "0123456789ABCDEFGHIJKLMNOPQRSTUVWXVZabcd",
+	"github-api-tokens":         nil,
+	"github-app-id":             0,
+	"github-installation-id":    0,
+	"github-app-private-key":    "",
+	"gitlab-targets":            "",
+	"gitlab-api-token":          "0123456789ABCDEFGHIJ",
+	"grpc-port":                 0,
+	"grpc-client-ca":            "",
+	"grpc-tls-cert":             "",
+	"grpc-tls-key":              "",
+	"grpc-token":                "",
+	"history-file":              "",
+	"ignore-extension":          "",
+	"ignore-path":               "",
+	"include-repos":             "",
+	"incremental":               false,
+	"input-file":                "",
+	"in-mem-clone":              false,
+	"in-mem-clone-budget":       1024,
+	"insecure-skip-verify":      false,
+	"kube-api":                  "",
+	"kube-context":              "",
+	"kube-label-selector":       "",
+	"kube-namespaces":           "",
+	"kubeconfig":                "",
+	"log-file":                  "",
+	"log-format":                LogFormatText,
+	"language":                  nil,
+	"list-targets":              false,
+	"max-file-size":             50,
+	"match-threads":             0,
+	"member-affiliation":        MemberAffiliationOwner,
+	"min-confidence":            "",
+	"min-severity":              "",
+	"no-gists":                  false,
+	"num-threads":               0,
+	"oidc-allowed-emails":       "",
+	"oidc-client-id":            "",
+	"oidc-client-secret":        "",
+	"oidc-issuer":               "",
+	"oidc-redirect-url":         "",
+	"on-finding-exec":           "",
+	"on-repo-complete-exec":     "",
+	"only-private":              false,
+	"only-public":               false,
+	"path-exclude":              nil,
+	"path-include":              nil,
+	"pipeline-queue-size":       DefaultPipelineQueueSize,
+	"plugin-timeout":            30,
+	"policy":                    "",
+	"proxy":                     "",
+	"pushed-after":              "",
+	"queue-name":                QueueDefaultName,
+	"queue-timeout":             60,
+	"queue-url":                 "",
+	"realert-interval":          0,
+	"recurse-groups":            true,
+	"report-html":               "",
+	"report-s3":                 "",
+	"report-s3-endpoint":        "",
+	"report-s3-kms-key-id":      "",
+	"report-s3-region":          "",
+	"report-s3-sse":             "",
+	"retries":                   DefaultRetries,
+	"retry-backoff":             int(DefaultRetryBackoff / time.Second),
+	"wasm-plugin-dir":           "",
+	"webhook-header":            nil,
+	"webhook-secret":            "",
+	"webhook-url":               "",
+	"web-password":              "",
+	"web-username":              "",
+	"walk-threads":              0,
+	"workflow-log-runs":         10,
+	"release-count":             5,
+	"release-asset-max-size":    100,
+	"resume":                    "",
+	"local-dirs":                nil,
+	"local-files":               nil,
+	"s3-anonymous":              false,
+	"s3-endpoint":               "",
+	"s3-region":                 "",
+	"s3-targets":                "",
+	"scan-binaries":             false,
+	"scan-commit-messages":      true,
+	"scan-forks":                true,
+	"scan-issues":               false,
+	"scan-workflow-logs":        false,
+	"scan-release-assets":       false,
+	"scan-notes":                true,
+	"scan-stash":                false,
+	"scan-retention":            50,
+	"scan-submodules":           false,
+	"scan-terraform-state":      true,
+	"scan-tests":                false,
+	"scan-type":                 "",
+	"scan-working-tree":         false,
+	"shared-projects":           true,
+	"ssh-key":                   "",
+	"ssh-key-passphrase":        "",
+	"ssh-known-hosts":           "",
+	"silent":                    false,
+	"stream":                    false,
+	"submodule-depth":           DefaultSubmoduleDepth,
+	"slack-channel":             "",
+	"slack-token":               "",
+	"slack-webhook":             "",
+	"splunk-index":              "",
+	"splunk-sourcetype":         "wraith",
+	"splunk-token":              "",
+	"splunk-url":                "",
+	"suppression-markers":       DefaultSuppressionMarker,
+	"topic":                     nil,
+	"until-commit":              "",
+	"triage-file":               "",
+	"verify":                    false,
+	"csv":                       "",
+	"gitlab-report":             "",
+	"junit":                     "",
+	"json":                      "",
+	"jsonl":                     "",
+	"match-level":               3,
+	"signature-file":            "$HOME/.wraith/signatures/default.yml",
+	"signature-path":            "$HOME/.wraith/signatures/",
+	"signature-tags":            "",
+	"signature-url":             "",
+	"scan-branches":             "",
+	"since-commit":              "",
+	"since-date":                "",
+	"scan-dir":                  "",
+	"scan-file":                 "",
+	"scan-state-file":           "$HOME/.wraith/scan-state.json",
+	"disable-signatures":        "",
+	"enable-signatures":         "",
+	"hide-secrets":              false,
+	"file-issues":               false,
+	"issue-body-template":       "",
+	"issue-labels":              nil,
+	"issue-public-repos":        false,
+	"issue-title-template":      "",
+	"redact":                    RedactNone,
 }
 
 // Session contains all the necessary values and parameters used during a scan
 type Session struct {
 	sync.Mutex
 
-	BindAddress       string
-	BindPort          int
-	Client            IClient `json:"-"`
-	CommitDepth       int
-	CSV               bool
-	Debug             bool
-	Findings          []*Finding
-	GithubAccessToken string
-	GithubTargets     []string
-	GitlabAccessToken string
-	GitlabTargets     []string
-	HideSecrets       bool
-	InMemClone        bool
-	JSON              bool
-	MaxFileSize       int64
-	NoExpandOrgs      bool
-	Out               *Logger `json:"-"`
-	LocalDirs         []string
-	LocalFiles        []string
-	Repositories      []*Repository
-	Router            *gin.Engine `json:"-"`
-	SignatureVersion  string
-	ScanFork          bool
-	ScanTests         bool
-	ScanType          string
-	Signatures        []*Signature
-	Silent            bool
-	SkippableExt      []string
-	SkippablePath     []string
-	Stats             *Stats
-	Targets           []*Owner
-	Threads           int
-	Version           string
-	MatchLevel        int
+	Alerts                  *AlertState `json:"-"`
+	AlertStateFile          string
+	APIScans                bool
+	AWSProfile              string
+	AzureDevopsTargets      []string
+	AzureDevopsToken        string
+	AzureDevopsURL          string
+	Baseline                *Baseline `json:"-"`
+	BindAddress             string
+	BindPort                int
+	BinaryMinLength         int
+	ArchiveDepth            int
+	ArchiveMaxSize          int64
+	BitbucketAppPassword    string
+	BitbucketOAuthToken     string
+	BitbucketServerTargets  []string
+	BitbucketServerToken    string
+	BitbucketServerURL      string
+	BitbucketServerUsername string
+	BitbucketTargets        []string
+	BitbucketUsername       string
+	CI                      bool
+	Client                  IClient `json:"-"`
+	CloneCache              string
+	CloneOverSSH            bool
+	CloneThreads            int
+	CheckpointFile          string
+	CheckpointInterval      time.Duration
+	CodeCommitEndpoint      string
+	CodeCommitRoleARNs      []string
+	CodeCommitTargets       []string
+	ConfluenceAttachments   bool
+	ConfluenceSpaces        []string
+	ConfluenceToken         string
+	ConfluenceURL           string
+	ConfluenceUsername      string
+	CommitDepth             int
+	CommitRange             *CommitRange `json:"-"`
+	ContextLines            int
+	DedupFindings           bool
+	CSVOutput               string
+	DB                      *Database `json:"-"`
+	DBPath                  string
+	DBSessionID             int64
+	Debug                   bool
+	DebugServer             string
+	DebugServerPublic       bool
+	Decode                  bool
+	DecodeMinLength         int
+	DefectDojo              *DefectDojo       `json:"-"`
+	FindingScript           *FindingScript    `json:"-"`
+	DetectorPlugins         []IDetectorPlugin `json:"-"`
+	DockerArchives          []string
+	DockerImages            []string
+	DockerPassword          string
+	DockerPlatform          string
+	DockerUsername          string
+	Elasticsearch           *Elasticsearch `json:"-"`
+	ExpandMembersGists      bool
+	ExpandMembersRepos      bool
+	Findings                []*Finding
+	GiteaAccessToken        string
+	GiteaTargets            []string
+	GiteaURL                string
+	GithubAccessToken       string
+	GithubAccessTokens      []string
+	GithubApp               *GithubApp `json:"-"`
+	GithubPullRequests      []string
+	GithubTargets           []string
+	GithubAPIURL            string
+	GithubAllOrgs           bool
+	GithubAllUsers          bool
+	GitlabAccessToken       string
+	GitlabTargets           []string
+	HistoryFile             string
+	RecurseGroups           bool
+	SharedProjects          bool
+	RepoFilter              *RepositoryFilter `json:"-"`
+	Redact                  string
+	InMemClone              bool
+	InMemCloneBudget        int64
+	GitlabReportOutput      string
+	JSONOutput              string
+	JSONLOutput             string
+	JUnitOutput             string
+	HTMLOutput              string
+	ID                      string
+	InputFile               string
+	IssueFiler              *IssueFiler `json:"-"`
+	KubeAPI                 string
+	KubeConfig              string
+	KubeContext             string
+	KubeLabelSelector       string
+	KubeNamespaces          []string
+	MaxFileSize             int64
+	MemberAffiliation       string
+	MinConfidence           string
+	MinSeverity             string
+	NoExpandOrgs            bool
+	NoGists                 bool
+	OnFinding               func(*Finding) `json:"-"`
+	OnFindingExec           string
+	OnRepoCompleteExec      string
+	Out                     *Logger     `json:"-"`
+	PathFilter              *PathFilter `json:"-"`
+	PipelineQueueSize       int
+	Policy                  *Policy `json:"-"`
+	PolicyFailures          []string
+	QueueName               string
+	QueueTimeout            time.Duration
+	QueueURL                string
+	ListTargets             bool
+	LocalDirs               []string
+	LogFile                 string
+	LogFormat               string
+	LogWriter               io.Writer `json:"-"`
+	LocalFiles              []string
+	ReportEncryption        *ReportEncryption `json:"-"`
+	ReportS3                *ReportS3         `json:"-"`
+	Repositories            []*Repository
+	Retries                 int
+	RetryBackoff            time.Duration
+	Router                  *gin.Engine `json:"-"`
+	SignatureVersion        string
+	S3Anonymous             bool
+	S3Endpoint              string
+	S3Region                string
+	S3Targets               []string
+	ScanBranches            []string
+	ScanBinaries            bool
+	ScanCommitMessages      bool
+	ScanFork                bool
+	ScanIssues              bool
+	ScanWorkflowLogs        bool
+	ScanReleaseAssets       bool
+	ScanNotes               bool
+	ScanStash               bool
+	ScanSubmodules          bool
+	ScanTerraformState      bool
+	ScanTests               bool
+	ScanWorkingTree         bool
+	ScanState               *ScanState `json:"-"`
+	ScanStateFile           string
+	ScanType                string
+	Scans                   *ScanManager `json:"-"`
+	Scheduler               *Scheduler   `json:"-"`
+	Signatures              []*Signature
+	SignatureFiles          []string
+	SignatureFilter         *SignatureFilter `json:"-"`
+	Silent                  bool
+	SkippableExt            []string
+	SkippablePath           []string
+	SSHKey                  string
+	SSHKeyPassphrase        string `json:"-"`
+	SSHKnownHosts           string
+	Splunk                  *Splunk `json:"-"`
+	Stats                   *Stats
+	Stream                  bool
+	StreamWriter            io.Writer `json:"-"`
+	SubmoduleDepth          int
+	SuppressionMarkers      []string
+	Targets                 []*Owner
+	Threads                 int
+	Thresholds              []Threshold
+	ThresholdFailures       []string
+	Triage                  *TriageState `json:"-"`
+	TriageFile              string
+	Verifier                *Verifier `json:"-"`
+	Version                 string
+	WalkThreads             int
+	WebAuth                 *WebAuth `json:"-"`
+	Webhook                 *Webhook `json:"-"`
+	Slack                   *Slack   `json:"-"`
+	MatchLevel              int
+	MatchThreads            int
+	WorkflowLogRuns         int
+	ReleaseCount            int
+	ReleaseAssetMaxSize     int64
+	Resumed                 *Checkpoint `json:"-"`
+
+	// completed are the repositories scanned to the end, kept for the checkpoint along with the targets once
+	// targetsGathered is set. The checkpoint was last written at checkpointSaved.
+	completed       []*CheckpointRepository
+	targetsGathered bool
+	checkpointSaved time.Time
+
+	// interrupted is set once the scan has been stopped early, and signals receives the signals that stop it
+	interrupted int32
+	signals     chan os.Signal
+
+	// streamLock keeps the lines of findings streamed by different threads from being written into each other
+	streamLock sync.Mutex
+
+	// scanErrors are the repositories and targets that could not be scanned, for the summary at the end of the scan
+	scanErrors     []ScanError
+	scanErrorsLock sync.Mutex
+
+	// findingIndex is the first finding of each fingerprint, which later findings of it are merged into
+	findingIndex map[string]*dedupEntry
+
+	// suppressed are the secrets left out because of a suppression comment, so each is only counted once
+	suppressed     map[string]bool
+	suppressedLock sync.Mutex
+
+	// exit is called in place of os.Exit after a fatal message, it is set for sessions made by NewScanSession
+	exit func(code int)
+
+	// entropySignatures are added on top of the signatures of SignatureFiles each time they are loaded
+	entropySignatures []Signature
+
+	// cloneMemory is how many bytes the repositories being cloned in memory are estimated to take
+	cloneMemory int64
+
+	// managed is set for the scans a server starts, which use the http settings and signatures the server loaded
+	// rather than replacing them under the other scans it is running
+	managed bool
 }
 
 // setConfig will set the defaults, and load a config file and environment variables if they are present
@@ -135,26 +511,143 @@ func SetConfig() *viper.Viper {
 // Initialize will set the initial values and options used during a scan session
 func (s *Session) Initialize(v *viper.Viper, scanType string) {
 
+	s.ArchiveDepth = v.GetInt("archive-depth")
+	s.ArchiveMaxSize = v.GetInt64("archive-max-size")
+	s.AWSProfile = v.GetString("aws-profile")
+	s.AzureDevopsTargets = v.GetStringSlice("azure-devops-targets")
+	s.AzureDevopsToken = v.GetString("azure-devops-token")
+	s.AzureDevopsURL = v.GetString("azure-devops-url")
 	s.BindAddress = v.GetString("bind-address")
 	s.BindPort = v.GetInt("bind-port")
+	s.BinaryMinLength = v.GetInt("binary-min-length")
+	s.BitbucketAppPassword = v.GetString("bitbucket-app-password")
+	s.BitbucketOAuthToken = v.GetString("bitbucket-oauth-token")
+	s.BitbucketServerTargets = v.GetStringSlice("bitbucket-server-targets")
+	s.BitbucketServerToken = v.GetString("bitbucket-server-token")
+	s.BitbucketServerURL = v.GetString("bitbucket-server-url")
+	s.BitbucketServerUsername = v.GetString("bitbucket-server-username")
+	s.BitbucketTargets = v.GetStringSlice("bitbucket-targets")
+	s.BitbucketUsername = v.GetString("bitbucket-username")
+	s.CodeCommitEndpoint = v.GetString("codecommit-endpoint")
+	s.CodeCommitRoleARNs = v.GetStringSlice("codecommit-role-arns")
+	s.CodeCommitTargets = v.GetStringSlice("codecommit-targets")
 	s.CommitDepth = setCommitDepth(v.GetInt("commit-depth"))
-	//s.CSVOutput = v.GetBool("csv")
+	s.ContextLines = v.GetInt("context-lines")
+	s.DedupFindings = v.GetBool("dedup-findings")
+	s.CSVOutput = v.GetString("csv")
 	s.Debug = v.GetBool("debug")
+	s.DebugServer = v.GetString("debug-server")
+	s.DebugServerPublic = v.GetBool("debug-server-public")
+	s.Decode = v.GetBool("decode")
+	s.DecodeMinLength = v.GetInt("decode-min-length")
+	s.DockerArchives = v.GetStringSlice("docker-archives")
+	s.DockerImages = v.GetStringSlice("docker-images")
+	s.DockerPassword = v.GetString("docker-password")
+	s.DockerPlatform = v.GetString("docker-platform")
+	s.DockerUsername = v.GetString("docker-username")
+	s.GiteaAccessToken = v.GetString("gitea-api-token")
+	s.GiteaTargets = v.GetStringSlice("gitea-targets")
+	s.GiteaURL = v.GetString("gitea-url")
 	s.GithubAccessToken = v.GetString("github-api-token")
+	s.GithubAccessTokens = v.GetStringSlice("github-api-tokens")
+	s.GithubPullRequests = v.GetStringSlice("github-pull-requests")
 	s.GithubTargets = v.GetStringSlice("github-targets")
+	s.GithubAPIURL = v.GetString("github-api-url")
+	s.GithubAllOrgs = v.GetBool("all-orgs")
+	s.GithubAllUsers = v.GetBool("all-users")
 	s.GitlabAccessToken = v.GetString("gitlab-api-token")
 	s.GitlabTargets = v.GetStringSlice("gitlab-targets")
-	s.HideSecrets = v.GetBool("hide-secrets")
+	s.RecurseGroups = v.GetBool("recurse-groups")
+	s.SharedProjects = v.GetBool("shared-projects")
+	// hide-secrets is kept for existing configs and means a full mask
+	s.Redact = effectiveRedaction(v.GetString("redact"), v.GetBool("hide-secrets"))
+	s.Retries = v.GetInt("retries")
+	s.RetryBackoff = time.Duration(v.GetInt("retry-backoff")) * time.Second
+
+	if historyFile := v.GetString("history-file"); historyFile != "" {
+		s.HistoryFile = SetHomeDir(historyFile)
+	}
 	s.InMemClone = v.GetBool("in-mem-clone")
-	//s.JSONOutput = v.GetBool("json")
+	s.InMemCloneBudget = v.GetInt64("in-mem-clone-budget")
+	s.LogFile = SetHomeDir(v.GetString("log-file"))
+	s.LogFormat = v.GetString("log-format")
+	s.GitlabReportOutput = v.GetString("gitlab-report")
+	s.JSONOutput = v.GetString("json")
+	s.JSONLOutput = v.GetString("jsonl")
+	s.JUnitOutput = v.GetString("junit")
+	s.HTMLOutput = v.GetString("report-html")
+	s.InputFile = SetHomeDir(v.GetString("input-file"))
 	s.LocalDirs = v.GetStringSlice("local-dirs")
 	s.MaxFileSize = v.GetInt64("max-file-size")
+	s.MemberAffiliation = v.GetString("member-affiliation")
 	s.MatchLevel = v.GetInt("match-level")
+	s.MinConfidence = strings.ToLower(v.GetString("min-confidence"))
+	s.MinSeverity = strings.ToLower(v.GetString("min-severity"))
+	s.NoExpandOrgs = v.GetBool("no-expand-orgs")
+	s.NoGists = v.GetBool("no-gists")
+	s.ExpandMembersGists = v.GetBool("expand-members-gists")
+	s.ExpandMembersRepos = v.GetBool("expand-members-repos")
+	s.OnFindingExec = v.GetString("on-finding-exec")
+	s.OnRepoCompleteExec = v.GetString("on-repo-complete-exec")
+	s.QueueName = v.GetString("queue-name")
+	s.QueueTimeout = time.Duration(v.GetInt("queue-timeout")) * time.Minute
+	s.QueueURL = v.GetString("queue-url")
+	for _, branches := range v.GetStringSlice("scan-branches") {
+		for _, b := range strings.Split(branches, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				s.ScanBranches = append(s.ScanBranches, b)
+			}
+		}
+	}
+	s.S3Anonymous = v.GetBool("s3-anonymous")
+	s.S3Endpoint = v.GetString("s3-endpoint")
+	s.S3Region = v.GetString("s3-region")
+	s.S3Targets = v.GetStringSlice("s3-targets")
+	s.KubeAPI = v.GetString("kube-api")
+	s.KubeConfig = v.GetString("kubeconfig")
+	s.KubeContext = v.GetString("kube-context")
+	s.KubeLabelSelector = v.GetString("kube-label-selector")
+	s.KubeNamespaces = v.GetStringSlice("kube-namespaces")
+	s.ConfluenceAttachments = v.GetBool("confluence-attachments")
+	s.ConfluenceSpaces = v.GetStringSlice("confluence-spaces")
+	s.ConfluenceToken = v.GetString("confluence-token")
+	s.ConfluenceURL = v.GetString("confluence-url")
+	s.ConfluenceUsername = v.GetString("confluence-username")
+	s.ScanBinaries = v.GetBool("scan-binaries")
+	s.ScanCommitMessages = v.GetBool("scan-commit-messages")
 	s.ScanFork = v.GetBool("scan-forks") //TODO Need to implement
+	s.ScanIssues = v.GetBool("scan-issues")
+	s.ScanWorkflowLogs = v.GetBool("scan-workflow-logs")
+	s.WorkflowLogRuns = v.GetInt("workflow-log-runs")
+	s.ScanReleaseAssets = v.GetBool("scan-release-assets")
+	s.ReleaseCount = v.GetInt("release-count")
+	s.ReleaseAssetMaxSize = v.GetInt64("release-asset-max-size")
+	s.ScanNotes = v.GetBool("scan-notes")
+	s.ScanStash = v.GetBool("scan-stash")
+	s.ScanSubmodules = v.GetBool("scan-submodules")
+	s.SubmoduleDepth = v.GetInt("submodule-depth")
+	s.ScanTerraformState = v.GetBool("scan-terraform-state")
 	s.ScanTests = v.GetBool("scan-tests")
+	s.ScanWorkingTree = v.GetBool("scan-working-tree")
 	s.ScanType = scanType
 	s.Silent = v.GetBool("silent")
+	s.Stream = v.GetBool("stream")
+	s.SuppressionMarkers = v.GetStringSlice("suppression-markers")
+	// a ci scan runs without the web interface and only prints a summary once it has finished
+	s.CI = v.GetBool("ci")
+	if s.CI {
+		s.Silent = true
+	}
+	// listing the targets only prints what would be scanned, so there is nothing to show in the web interface
+	s.ListTargets = v.GetBool("list-targets")
+	if s.ListTargets {
+		s.Silent = true
+	}
 	s.Threads = v.GetInt("num-threads")
+	s.CloneThreads = v.GetInt("clone-threads")
+	s.WalkThreads = v.GetInt("walk-threads")
+	s.MatchThreads = v.GetInt("match-threads")
+	s.PipelineQueueSize = v.GetInt("pipeline-queue-size")
 	s.Version = version.AppVersion()
 	v.GetStringSlice("scan-dir")
 	v.GetStringSlice("scan-file")
@@ -194,29 +687,411 @@ func (s *Session) Initialize(v *viper.Viper, scanType string) {
 
 	s.InitStats()
 	s.InitLogger()
+
+	if s.CloneThreads < 0 || s.WalkThreads < 0 || s.MatchThreads < 0 {
+		s.Out.Fatal("clone-threads, walk-threads and match-threads can not be negative\n")
+	}
+	if s.PipelineQueueSize < 1 {
+		s.Out.Fatal("pipeline-queue-size must be at least 1\n")
+	}
+	commitRange, err := NewCommitRange(strings.TrimSpace(v.GetString("since-commit")), strings.TrimSpace(v.GetString("since-date")),
+		strings.TrimSpace(v.GetString("until-commit")), time.Now())
+	if err != nil {
+		s.Out.Fatal("%s\n", err)
+	}
+	s.CommitRange = commitRange
+
 	s.InitThreads()
+
+	if s.DebugServer != "" && !s.managed {
+		StartDebugServer(s.DebugServer, s.DebugServerPublic, s.Out)
+	}
+	if s.Debug {
+		s.Out.Debug("Runtime: %s\n", ReadRuntimeStats())
+		LogRuntimeStats(s.Out)
+	}
+
+	// every api client and http clone is made after this so they all go through the proxy and trust the ca bundle
+	// which a server does once for every scan it runs
+	if !s.managed {
+		if err := ConfigureHTTP(NewHTTPConfig(v)); err != nil {
+			s.Out.Fatal("Failed to configure http: %s\n", err)
+		}
+	}
+	if v.GetBool("insecure-skip-verify") {
+		s.Out.Warn("TLS certificates are not being verified, anyone between wraith and the servers it connects to can read the credentials it sends\n")
+	}
+
+	if s.GithubAPIURL != "" {
+		if _, err := githubBaseURL(s.GithubAPIURL); err != nil {
+			s.Out.Fatal("Invalid github-api-url: %s\n", err)
+		}
+	}
+	// every organization or user is only gathered from an enterprise instance, github.com has millions of them
+	if (s.GithubAllOrgs || s.GithubAllUsers) && !isGithubEnterprise(s.GithubAPIURL) {
+		s.Out.Fatal("all-orgs and all-users can only be used with the github-api-url of a github enterprise instance\n")
+	}
+
+	// a github app is used in place of the api token when one is configured
+	if appID := v.GetInt64("github-app-id"); appID != 0 && (scanType == "github" || scanType == "githubPR") {
+		app, err := LoadGithubApp(s.GithubAPIURL, appID, SetHomeDir(v.GetString("github-app-private-key")), v.GetInt64("github-installation-id"))
+		if err != nil {
+			s.Out.Fatal("Failed to load github app %d: %s\n", appID, err)
+		}
+		s.GithubApp = app
+	}
+
 	s.InitAPIClient()
 
+	if alertStateFile := v.GetString("alert-state-file"); alertStateFile != "" {
+		s.AlertStateFile = SetHomeDir(alertStateFile)
+		alerts, err := LoadAlertState(s.AlertStateFile, time.Duration(v.GetInt("realert-interval"))*time.Hour)
+		if err != nil {
+			s.Out.Fatal("Failed to load the alert state from %s: %s\n", s.AlertStateFile, err)
+		}
+		s.Alerts = alerts
+	}
+
+	if v.GetBool("incremental") {
+		s.ScanStateFile = SetHomeDir(v.GetString("scan-state-file"))
+		state, err := LoadScanState(s.ScanStateFile)
+		if err != nil {
+			s.Out.Fatal("Failed to load the scan state from %s: %s\n", s.ScanStateFile, err)
+		}
+		s.ScanState = state
+	}
+
+	s.CheckpointFile = SetHomeDir(v.GetString("checkpoint"))
+	s.CheckpointInterval = time.Duration(v.GetInt("checkpoint-interval")) * time.Second
+	if s.CheckpointInterval < 0 {
+		s.Out.Fatal("checkpoint-interval can not be less than 0\n")
+	}
+	if resume := v.GetString("resume"); resume != "" {
+		path := SetHomeDir(resume)
+		checkpoint, err := LoadCheckpoint(path)
+		if err != nil {
+			s.Out.Fatal("Failed to load the checkpoint %s: %s\n", path, err)
+		}
+		if checkpoint.ScanType != scanType {
+			s.Out.Fatal("The checkpoint %s is of a %s scan rather than a %s scan\n", path, checkpoint.ScanType, scanType)
+		}
+		// the resumed scan carries on writing to the checkpoint it was resumed from
+		if s.CheckpointFile == "" {
+			s.CheckpointFile = path
+		}
+		s.Resume(checkpoint)
+	}
+
+	if s.ContextLines < 0 {
+		s.Out.Fatal("context-lines can not be less than 0\n")
+	}
+
+	if s.ScanWorkflowLogs && (s.WorkflowLogRuns < 1 || s.WorkflowLogRuns > 100) {
+		s.Out.Fatal("workflow-log-runs must be from 1 to 100\n")
+	}
+
+	if s.ScanReleaseAssets && (s.ReleaseCount < 1 || s.ReleaseCount > 100) {
+		s.Out.Fatal("release-count must be from 1 to 100\n")
+	}
+
+	if s.ScanSubmodules && s.SubmoduleDepth < 1 {
+		s.Out.Fatal("submodule-depth must be at least 1\n")
+	}
+
+	if cloneCache := v.GetString("clone-cache"); cloneCache != "" {
+		if s.InMemClone {
+			s.Out.Fatal("clone-cache can not be used with in-mem-clone\n")
+		}
+		s.CloneCache = SetHomeDir(cloneCache)
+		if err := os.MkdirAll(s.CloneCache, 0700); err != nil {
+			s.Out.Fatal("Unable to create the clone cache %s: %s\n", s.CloneCache, err)
+		}
+	}
+
+	s.CloneOverSSH = v.GetBool("clone-over-ssh")
+	s.SSHKey = SetHomeDir(v.GetString("ssh-key"))
+	s.SSHKeyPassphrase = v.GetString("ssh-key-passphrase")
+	s.SSHKnownHosts = SetHomeDir(v.GetString("ssh-known-hosts"))
+	if err := CheckSSHConfig(s.SSHKey, s.SSHKeyPassphrase, s.SSHKnownHosts); err != nil {
+		s.Out.Fatal("%s\n", err)
+	}
+
+	pathFilter, err := NewPathFilter(v.GetStringSlice("path-include"), v.GetStringSlice("path-exclude"))
+	if err != nil {
+		s.Out.Fatal("Invalid path filter: %s\n", err)
+	}
+	s.PathFilter = pathFilter
+
+	if s.MinSeverity != "" && !ValidSeverity(s.MinSeverity) {
+		s.Out.Fatal("Unknown min-severity %s, it must be one of: %s\n", s.MinSeverity, strings.Join(Severities, ", "))
+	}
+	if s.MinConfidence != "" && !ValidConfidence(s.MinConfidence) {
+		s.Out.Fatal("Unknown min-confidence %s, it must be one of: %s\n", s.MinConfidence, strings.Join(Confidences, ", "))
+	}
+
+	if !ValidRedactMode(s.Redact) {
+		s.Out.Fatal("Unknown redact mode %s, it must be one of: %s\n", s.Redact, strings.Join(RedactModes, ", "))
+	}
+
+	if !ValidMemberAffiliation(s.MemberAffiliation) {
+		s.Out.Fatal("Unknown member-affiliation %s, it must be one of: %s\n", s.MemberAffiliation, strings.Join(MemberAffiliations, ", "))
+	}
+
+	if scanType == "repoList" && s.InputFile == "" {
+		s.Out.Fatal("A file of git urls to scan must be given with input-file\n")
+	}
+
+	s.DetectorPlugins = LoadDetectorPlugins(v.GetString("detector-plugins"), v.GetInt("plugin-timeout"), s)
+
+	if wasmDir := v.GetString("wasm-plugin-dir"); wasmDir != "" {
+		wasmPlugins, err := NewWasmPluginDir(wasmDir, v.GetInt("plugin-timeout"), s.Out)
+		if err != nil {
+			s.Out.Fatal("Failed to load WASM plugins from %s: %s\n", wasmDir, err)
+		}
+
+		// hot load new or updated modules for as long as the web server is running
+		if !s.Silent {
+			if err := wasmPlugins.Watch(); err != nil {
+				s.Out.Error("Unable to watch %s for WASM plugin changes: %s\n", wasmDir, err)
+			}
+		}
+		s.DetectorPlugins = append(s.DetectorPlugins, wasmPlugins)
+	}
+
+	if script := v.GetString("finding-script"); script != "" {
+		fs, err := NewFindingScript(script, s.Out)
+		if err != nil {
+			s.Out.Fatal("Failed to load finding script %s: %s\n", script, err)
+		}
+		s.FindingScript = fs
+	}
+
+	if webhookURL := v.GetString("webhook-url"); webhookURL != "" {
+		wh, err := NewWebhook(webhookURL, v.GetStringSlice("webhook-header"), v.GetString("webhook-secret"))
+		if err != nil {
+			s.Out.Fatal("Invalid webhook %s: %s\n", webhookURL, err)
+		}
+		s.Webhook = wh
+	}
+
+	if esURL := v.GetString("elasticsearch-url"); esURL != "" {
+		es, err := NewElasticsearch(esURL, v.GetString("elasticsearch-index"), v.GetString("elasticsearch-username"),
+			v.GetString("elasticsearch-password"), v.GetString("elasticsearch-api-key"))
+		if err != nil {
+			s.Out.Fatal("Invalid elasticsearch settings: %s\n", err)
+		}
+		s.Elasticsearch = es
+	}
+
+	if slackWebhook, slackToken := v.GetString("slack-webhook"), v.GetString("slack-token"); slackWebhook != "" || slackToken != "" {
+		slack, err := NewSlack(slackWebhook, slackToken, v.GetString("slack-channel"))
+		if err != nil {
+			s.Out.Fatal("Invalid slack settings: %s\n", err)
+		}
+		s.Slack = slack
+	}
+
+	if splunkURL := v.GetString("splunk-url"); splunkURL != "" {
+		splunk, err := NewSplunk(splunkURL, v.GetString("splunk-token"), v.GetString("splunk-index"), v.GetString("splunk-sourcetype"))
+		if err != nil {
+			s.Out.Fatal("Invalid splunk settings: %s\n", err)
+		}
+		s.Splunk = splunk
+	}
+
+	if defectDojoURL := v.GetString("defectdojo-url"); defectDojoURL != "" {
+		defectDojo, err := NewDefectDojo(defectDojoURL, v.GetString("defectdojo-token"), v.GetString("defectdojo-product"),
+			v.GetString("defectdojo-engagement"), v.GetString("defectdojo-test-title"), v.GetBool("defectdojo-close-old"))
+		if err != nil {
+			s.Out.Fatal("Invalid defectdojo settings: %s\n", err)
+		}
+		s.DefectDojo = defectDojo
+	}
+
+	if v.GetBool("file-issues") {
+		filer, err := NewIssueFiler(v.GetString("issue-title-template"), SetHomeDir(v.GetString("issue-body-template")), v.GetStringSlice("issue-labels"))
+		if err != nil {
+			s.Out.Fatal("Invalid issue settings: %s\n", err)
+		}
+		filer.PublicRepos = v.GetBool("issue-public-repos")
+		s.IssueFiler = filer
+	}
+
+	if recipient := v.GetString("encrypt-report"); recipient != "" {
+		encryption, err := ParseReportRecipient(recipient)
+		if err != nil {
+			s.Out.Fatal("Invalid encrypt-report: %s\n", err)
+		}
+		s.ReportEncryption = encryption
+	}
+
+	if target := v.GetString("report-s3"); target != "" {
+		creds, err := LoadAWSCredentials(s.AWSProfile)
+		if err != nil {
+			s.Out.Fatal("Unable to load aws credentials for report-s3: %s\n", err)
+		}
+		reportS3, err := NewReportS3(target, v.GetString("report-s3-endpoint"), v.GetString("report-s3-region"),
+			v.GetString("report-s3-sse"), v.GetString("report-s3-kms-key-id"), creds)
+		if err != nil {
+			s.Out.Fatal("Invalid report-s3 settings: %s\n", err)
+		}
+		if s.JSONOutput == "" && s.JSONLOutput == "" && s.HTMLOutput == "" && s.CSVOutput == "" && s.GitlabReportOutput == "" && s.JUnitOutput == "" {
+			s.Out.Fatal("report-s3 needs a report to upload, such as one written with json or report-html\n")
+		}
+		s.ReportS3 = reportS3
+	}
+
+	if baseline := v.GetString("baseline"); baseline != "" {
+		b, err := LoadBaseline(SetHomeDir(baseline))
+		if err != nil {
+			s.Out.Fatal("Failed to load baseline %s: %s\n", baseline, err)
+		}
+		s.Baseline = b
+		s.Out.Debug("Loaded %d baselined findings from %s\n", b.Len(), baseline)
+		if n := b.SecretIDs(); n > 0 {
+			s.Out.Warn("The baseline %s has %d secret %s, which are no longer matched, rebuild it from the fingerprints of a report\n", baseline, n, Pluralize(n, "id", "ids"))
+		}
+	}
+
+	failOn := v.GetString("fail-on")
+	if failOn == "" && s.CI {
+		failOn = SeverityAny
+	}
+	thresholds, err := ParseThresholds(failOn)
+	if err != nil {
+		s.Out.Fatal("Invalid fail-on %s: %s\n", failOn, err)
+	}
+	s.Thresholds = thresholds
+
+	repoFilter, err := NewRepositoryFilter(RepositoryFilterConfig{
+		IncludeRepos:    v.GetString("include-repos"),
+		ExcludeRepos:    v.GetString("exclude-repos"),
+		ExcludeArchived: v.GetBool("exclude-archived"),
+		OnlyPrivate:     v.GetBool("only-private"),
+		OnlyPublic:      v.GetBool("only-public"),
+		Languages:       v.GetStringSlice("language"),
+		Topics:          v.GetStringSlice("topic"),
+		PushedAfter:     v.GetString("pushed-after"),
+	}, time.Now())
+	if err != nil {
+		s.Out.Fatal("Invalid repository filters: %s\n", err)
+	}
+	s.RepoFilter = repoFilter
+
+	if v.GetBool("verify") {
+		s.Verifier = NewVerifier(nil)
+	}
+
+	if policy := v.GetString("policy"); policy != "" {
+		p, err := NewPolicy(SetHomeDir(policy))
+		if err != nil {
+			s.Out.Fatal("Failed to load policy %s: %s\n", policy, err)
+		}
+		s.Policy = p
+	}
+
+	// listing the targets scans nothing, so it would only leave a session in the database that never finishes
+	if dbPath := v.GetString("db-path"); dbPath != "" && !s.ListTargets {
+		s.DBPath = SetHomeDir(dbPath)
+		db, err := OpenDatabase(s.DBPath)
+		if err != nil {
+			s.Out.Fatal("Failed to open the database %s: %s\n", s.DBPath, err)
+		}
+		// the server of scheduled scans only shows the database, each of its scans adds a session of its own
+		if scanType != ScanTypeServe {
+			if s.DBSessionID, err = db.StartSession(s); err != nil {
+				s.Out.Fatal("Failed to add the session to the database %s: %s\n", s.DBPath, err)
+			}
+		}
+		s.DB = db
+	}
+
+	if triageFile := v.GetString("triage-file"); triageFile != "" {
+		s.TriageFile = SetHomeDir(triageFile)
+	}
+	if err := s.LoadTriage(); err != nil {
+		s.Out.Fatal("Failed to load the triage of findings: %s\n", err)
+	}
+
 	if !s.Silent {
+		auth, err := NewWebAuth(WebAuthConfig{
+			Username:      v.GetString("web-username"),
+			Password:      v.GetString("web-password"),
+			Issuer:        v.GetString("oidc-issuer"),
+			ClientID:      v.GetString("oidc-client-id"),
+			ClientSecret:  v.GetString("oidc-client-secret"),
+			RedirectURL:   v.GetString("oidc-redirect-url"),
+			AllowedEmails: v.GetStringSlice("oidc-allowed-emails"),
+		})
+		if err != nil {
+			s.Out.Fatal("Failed to set up authentication for the web interface: %s\n", err)
+		}
+		s.WebAuth = auth
+		s.APIScans = v.GetBool("api-scans")
+		if s.APIScans && auth == nil {
+			s.Out.Fatal("api-scans needs web-username or oidc-issuer, as the scans it starts run with the credentials of the server\n")
+		}
+		s.Scans = NewScanManager(v)
+		if scanType != ScanTypeServe {
+			s.Scans.Add(s)
+		}
 		s.InitRouter()
+
+		if v.GetInt("grpc-port") > 0 {
+			s.InitGRPCServer(v)
+		}
+	}
+
+	s.SignatureFilter = NewSignatureFilter(
+		v.GetStringSlice("signature-tags"),
+		v.GetStringSlice("enable-signatures"),
+		v.GetStringSlice("disable-signatures"),
+	)
+
+	// the scans a server starts match against the signatures of the server, which it reloads for all of them
+	if s.managed {
+		s.SignatureVersion = CurrentSignaturesVersion()
+		return
 	}
 
-	var curSig []Signature
 	var combinedSig []Signature
+	var safeSig []SafeFunctionSignature
 	SignaturesFile := v.GetString("signature-file")
 	if SignaturesFile != "" {
-		Signatures := strings.Split(SignaturesFile, ",") // TODO make slice
+		s.SignatureFiles = strings.Split(SignaturesFile, ",") // TODO make slice
+		loaded, safe, err := readSignatureFiles(s.SignatureFiles, s.MatchLevel, s)
+		if err != nil {
+			s.Out.Error("Failed to load signatures: %s\n", err)
+			s.Out.Exit(2)
+		}
+		combinedSig, safeSig = loaded, safe
+	}
 
-		for _, f := range Signatures {
-			f = strings.TrimSpace(f)
-			h := SetHomeDir(f)
-			if PathExists(h, s) {
-				curSig = LoadSignatures(h, s.MatchLevel, s)
-				combinedSig = append(combinedSig, curSig...)
-			}
+	if v.GetBool("entropy") {
+		s.entropySignatures = s.loadEntropySignatures(
+			v.GetFloat64("entropy-base64-threshold"),
+			v.GetInt("entropy-base64-min-length"),
+			v.GetFloat64("entropy-hex-threshold"),
+			v.GetInt("entropy-hex-min-length"),
+		)
+		combinedSig = append(combinedSig, s.entropySignatures...)
+	}
+	if filtered := s.SignatureFilter.Filter(combinedSig); len(filtered) < len(combinedSig) {
+		s.Out.Info("Using %d of the %d signatures loaded, the rest are left out by signature-tags or disable-signatures\n", len(filtered), len(combinedSig))
+		if len(filtered) == 0 {
+			s.Out.Warn("None of the signatures are left to match with, nothing will be found\n")
 		}
-	} // TODO need to catch this error here
-	Signatures = combinedSig
+		combinedSig = filtered
+	}
+	setSignatureSet(combinedSig, safeSig)
+	setSignaturesVersion(s.SignatureVersion)
+
+	// reload the signatures when their files change for as long as the web server is running
+	if !s.Silent && len(s.SignatureFiles) > 0 {
+		if err := s.WatchSignatures(); err != nil {
+			s.Out.Error("Unable to watch the signature files for changes: %s\n", err)
+		}
+	}
 }
 
 // setCommitDepth will set the commit depth to go to during a sess. This is an ugly way of doing it but for the moment it works fine.
@@ -230,8 +1105,27 @@ func setCommitDepth(c int) int {
 // Finish is called at the end of a scan session and used to generate discrete data points
 // for a given scan session including setting the status of a scan to finished.
 func (s *Session) Finish() {
+	s.stopHandlingInterrupts()
 	s.Stats.FinishedAt = time.Now()
-	s.Stats.Status = StatusFinished
+	if s.Interrupted() {
+		s.SetStatus(StatusInterrupted)
+	} else {
+		s.SetStatus(StatusFinished)
+	}
+	s.EvaluatePolicy()
+	s.EvaluateThresholds()
+	s.WriteReports()
+	s.SendWebhookSummary()
+	s.SendSlackDigest()
+	s.ExportElasticsearch()
+	s.ExportSplunk()
+	s.ExportDefectDojo()
+	s.RecordHistory()
+	s.SaveAlertState()
+	s.SaveScanState()
+	s.finishCheckpoint()
+	s.FinishDatabase()
+	s.Out.Debug("Runtime at the end of the scan: %s\n", ReadRuntimeStats())
 }
 
 // AddTarget will add a new target to a session to be scanned during that session
@@ -240,11 +1134,21 @@ func (s *Session) AddTarget(target *Owner) {
 	defer s.Unlock()
 	for _, t := range s.Targets {
 		if *target.ID == *t.ID {
+			// a member that was also asked for on its own is gathered as a target
+			t.member = t.member && target.member
 			return
 		}
 	}
 	s.Targets = append(s.Targets, target)
 	s.Stats.IncrementTargets()
+	s.recordTarget(target)
+}
+
+// narrowedScan will check if the session only looked at part of what it scans, because it was interrupted, skipped
+// the commits it had seen before, or was narrowed down to some paths or commits. Not finding something again in such a
+// scan does not mean it is gone.
+func (s *Session) narrowedScan() bool {
+	return s.Interrupted() || s.ScanState != nil || s.PathFilter != nil || s.CommitRange != nil
 }
 
 // AddRepository will add a given repository to be scanned to a session. This counts as
@@ -263,13 +1167,92 @@ func (s *Session) AddRepository(repository *Repository) {
 }
 
 // AddFinding will add a finding that has been discovered during a session to the list of findings
-// for that session
-func (s *Session) AddFinding(finding *Finding) {
+// for that session. It returns false if the finding was suppressed by the finding script or the policy.
+func (s *Session) AddFinding(finding *Finding) bool {
+	if finding.Fingerprint == "" {
+		finding.setFingerprint()
+	}
+	if finding.SecretHash == "" {
+		finding.setSecretHash()
+	}
+	if finding.Remediation == nil {
+		finding.Remediation = signatureRemediation(finding.Signatureid)
+	}
+	// a secret pasted into a commit message would otherwise be shown in full with every finding of the commit
+	finding.CommitMessage = redactLine(finding.CommitMessage, s, finding.secret)
+
+	if s.Baseline.Contains(finding) {
+		s.Out.Debug("Finding in %s suppressed by the baseline\n", finding.FilePath)
+		s.Stats.IncrementFindingsBaselined()
+		return false
+	}
+
+	// a finding of a secret in a place it was already found in is merged before it is verified, so the secret is not
+	// verified again for every commit it is in
+	s.Lock()
+	entry, duplicate := s.dedupFinding(finding)
+	if duplicate {
+		s.Stats.IncrementFindingsDuplicate()
+	}
+	s.Unlock()
+	if duplicate {
+		s.Out.Debug("Finding in %s at %s is the same as one already found\n", finding.FilePath, finding.CommitHash)
+		return false
+	}
+
+	s.verifyFinding(finding)
+	s.analyzeJWT(finding)
+	s.applyTriage(finding)
+
+	if s.FindingScript != nil {
+		keep, err := s.FindingScript.Process(finding)
+		if err != nil {
+			s.Out.Error("Finding script failed for %s: %s\n", finding.FilePath, err)
+		}
+		if !keep {
+			s.Out.Debug("Finding in %s suppressed by the finding script\n", finding.FilePath)
+			s.dropDedup(entry)
+			return false
+		}
+	}
+
+	if s.Policy != nil {
+		keep, err := s.Policy.Process(finding, s.findRepository(finding))
+		if err != nil {
+			s.Out.Error("Policy failed for %s: %s\n", finding.FilePath, err)
+		}
+		if !keep {
+			s.Out.Debug("Finding in %s suppressed by the policy\n", finding.FilePath)
+			s.dropDedup(entry)
+			return false
+		}
+	}
+
+	// the minimums are checked last as a finding script or policy can change how a finding is rated
+	if !s.meetsMinimums(finding) {
+		s.Out.Debug("Finding in %s is rated below the minimum severity or confidence\n", finding.FilePath)
+		s.dropDedup(entry)
+		return false
+	}
+
 	s.Lock()
-	defer s.Unlock()
 	const MaxStrLen = 100
+	seq := len(s.Findings)
 	s.Findings = append(s.Findings, finding)
 	s.Stats.IncrementFindingsTotal()
+	s.Unlock()
+
+	s.recordFinding(seq, finding)
+	s.streamFinding(finding)
+
+	// hooks run outside of the lock so a slow command does not stall the other threads
+	s.runFindingHook(finding)
+	s.sendFindingWebhook(finding)
+	if s.OnFinding != nil {
+		s.OnFinding(finding)
+	}
+	s.publishDedup(entry)
+	return true
 }
 
 // InitStats will set the initial values for a session
@@ -294,27 +1277,87 @@ func (s *Session) InitStats() {
 	}
 }
 
-// InitLogger will initialize the logger for the session
+// InitLogger will initialize the logger for the session. Json logs carry the id of the session and the stage it is in.
 func (s *Session) InitLogger() {
 	s.Out = &Logger{}
 	s.Out.SetDebug(s.Debug)
 	s.Out.SetSilent(s.Silent)
+	// streamed findings are the only thing written to stdout
+	s.Out.SetStderr(s.Stream)
+	if s.exit != nil {
+		s.Out.SetExit(s.exit)
+	}
+	if err := s.Out.SetOutput(s.LogFormat, s.LogFile); err != nil {
+		s.Out.Fatal("Unable to log to %s: %s\n", s.LogFile, err)
+	}
+	if s.LogWriter != nil {
+		s.Out.SetWriter(s.LogWriter)
+	}
+
+	if s.ID == "" {
+		s.ID = newRandomID()
+	}
+	s.Out.SetField("session", s.ID)
+	s.Out.SetField("stage", StatusInitializing)
+}
+
+// SetStatus will move the session on to the next stage of the scan
+func (s *Session) SetStatus(status string) {
+	s.Stats.Status = status
+	s.Out.SetField("stage", status)
 }
 
-// InitAPIClient will create a new gitlab or github api client based on the session identifier
+// InitAPIClient will create a new gitlab, github, bitbucket, bitbucket server, azure devops, gitea or codecommit api client based on the session identifier
 func (s *Session) InitAPIClient() {
+	check := func(err error) {
+		if err != nil {
+			s.Out.Error("%s\n", err)
+			s.Out.Exit(2)
+		}
+	}
 
 	switch s.ScanType {
-	case "github":
-		CheckGithubAPIToken(s.GithubAccessToken, s)
-		s.Client = githubClient.NewClient(githubClient{}, s.GithubAccessToken)
+	case "github", "githubPR":
+		if s.GithubApp != nil {
+			s.Client = NewGithubAppClient(s.GithubApp, s.Out)
+			break
+		}
+		// several tokens take the place of the single one, so their rate limits can be used up in turn
+		if len(s.GithubAccessTokens) > 0 {
+			for _, t := range s.GithubAccessTokens {
+				check(CheckGithubAPIToken(t))
+			}
+			s.Client = withGithubAPIURL(NewGithubTokenPoolClient(s.GithubAccessTokens, s.Out), s.GithubAPIURL)
+			break
+		}
+		check(CheckGithubAPIToken(s.GithubAccessToken))
+		s.Client = withGithubAPIURL(githubClient.NewClient(githubClient{}, s.GithubAccessToken, s.Out), s.GithubAPIURL)
 	case "gitlab":
-		CheckGitlabAPIToken(s.GitlabAccessToken, s)
+		check(CheckGitlabAPIToken(s.GitlabAccessToken))
 		var err error
-		s.Client, err = gitlabClient.NewClient(gitlabClient{}, s.GitlabAccessToken, s.Out)
+		s.Client, err = gitlabClient.NewClient(gitlabClient{recurseGroups: s.RecurseGroups, sharedProjects: s.SharedProjects}, s.GitlabAccessToken, s.Out)
 		if err != nil {
 			s.Out.Fatal("Error initializing GitLab client: %s", err)
 		}
+	case "bitbucket":
+		check(CheckBitbucketCredentials(s))
+		s.Client = bitbucketClient.NewClient(bitbucketClient{}, s.BitbucketUsername, s.BitbucketAppPassword, s.BitbucketOAuthToken, s.Out)
+	case "bitbucketServer":
+		check(CheckBitbucketServerCredentials(s))
+		s.Client = bitbucketServerClient.NewClient(bitbucketServerClient{}, s.BitbucketServerURL, s.BitbucketServerToken, s.Out)
+	case "azureDevops":
+		check(CheckAzureDevopsCredentials(s))
+		s.Client = azureDevopsClient.NewClient(azureDevopsClient{}, s.AzureDevopsURL, s.AzureDevopsToken, s.Out)
+	case "gitea":
+		check(CheckGiteaCredentials(s))
+		s.Client = giteaClient.NewClient(giteaClient{}, s.GiteaURL, s.GiteaAccessToken, s.Out)
+	case "codeCommit":
+		creds, err := CheckCodeCommitCredentials(s)
+		check(err)
+		s.Client, err = NewCodeCommitClient(s.CodeCommitEndpoint, creds, s.CodeCommitRoleARNs, s.Out)
+		if err != nil {
+			s.Out.Fatal("Error initializing CodeCommit client: %s\n", err)
+		}
 	default:
 		// TODO put something in here when needed
 	}
@@ -326,13 +1369,24 @@ func (s *Session) InitThreads() {
 		numCPUs := runtime.NumCPU()
 		s.Threads = numCPUs
 	}
-	runtime.GOMAXPROCS(s.Threads + 2) // thread count + main + web server
+	// the stages of the pipeline repositories are scanned in that are not set run as many workers as --num-threads
+	s.CloneThreads = s.stageThreads(s.CloneThreads)
+	s.WalkThreads = s.stageThreads(s.WalkThreads)
+	s.MatchThreads = s.stageThreads(s.MatchThreads)
+	procs := s.Threads
+	if s.MatchThreads > procs {
+		procs = s.MatchThreads
+	}
+	runtime.GOMAXPROCS(procs + 2) // thread count + main + web server
 }
 
 // InitRouter will configure and start the webserver for graphical output and status messages
 func (s *Session) InitRouter() {
 	bind := fmt.Sprintf("%s:%d", s.BindAddress, s.BindPort)
 	s.Router = NewRouter(s)
+	if s.WebAuth == nil && !isLoopback(s.BindAddress) {
+		s.Out.Warn("The web interface on %s shows every finding without authentication, set web-username or oidc-issuer to protect it\n", bind)
+	}
 	go func(sess *Session) {
 		if err := sess.Router.Run(bind); err != nil {
 			sess.Out.Fatal("Error when starting web server: %s\n", err)
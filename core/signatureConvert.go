@@ -0,0 +1,277 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// These are the formats of the rule files of other secret scanners that can be converted into signatures
+const (
+	RuleFormatGitleaks   = "gitleaks"
+	RuleFormatTrufflehog = "trufflehog"
+)
+
+// RuleFormats are the formats rule files can be converted from
+var RuleFormats = []string{RuleFormatGitleaks, RuleFormatTrufflehog}
+
+// RuleConversion is a rule file converted into signatures. Notes are the rules that were converted with something left
+// out, such as an allowlist, and Skipped are those that could not be converted at all, each with the id of the rule
+// and why.
+type RuleConversion struct {
+	Format     string
+	Rules      int
+	Signatures []SignatureDef
+	Notes      []string
+	Skipped    []string
+}
+
+// gitleaksConfig is a gitleaks config file, as written for gitleaks 7 and 8
+type gitleaksConfig struct {
+	Rules []gitleaksRule `toml:"rules"`
+}
+
+// gitleaksRule is a rule of a gitleaks config. File and Entropies are only in gitleaks 7, ID, SecretGroup, Entropy and
+// Keywords only in gitleaks 8.
+type gitleaksRule struct {
+	ID          string                   `toml:"id"`
+	Description string                   `toml:"description"`
+	Regex       string                   `toml:"regex"`
+	SecretGroup int64                    `toml:"secretGroup"`
+	Entropy     float64                  `toml:"entropy"`
+	Path        string                   `toml:"path"`
+	File        string                   `toml:"file"`
+	Tags        []string                 `toml:"tags"`
+	Keywords    []string                 `toml:"keywords"`
+	Allowlist   map[string]interface{}   `toml:"allowlist"`
+	Allowlists  []map[string]interface{} `toml:"allowlists"`
+	Entropies   []map[string]interface{} `toml:"Entropies"`
+}
+
+// trufflehogConfig is a file of trufflehog 3 custom detectors
+type trufflehogConfig struct {
+	Detectors []trufflehogDetector `yaml:"detectors"`
+}
+
+// trufflehogDetector is a custom detector of trufflehog 3, which finds a secret when every one of its regexes matches
+type trufflehogDetector struct {
+	Name                string            `yaml:"name"`
+	Keywords            []string          `yaml:"keywords"`
+	Regex               map[string]string `yaml:"regex"`
+	Entropy             float64           `yaml:"entropy"`
+	Verify              []interface{}     `yaml:"verify"`
+	ExcludeWords        []string          `yaml:"exclude_words"`
+	ExcludeRegexesMatch []string          `yaml:"exclude_regexes_match"`
+}
+
+// signatureParts are the names a signature file gives the parts of a file, which are not the names of the parts
+// themselves
+var signatureParts = map[string]string{
+	PartPath:     "partpath",
+	PartFilename: "partfilename",
+	PartContent:  "content",
+}
+
+// ruleIDChars are the characters that are left out of the ids made from the names of rules
+var ruleIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// RuleFormat will guess the format of a rule file from its extension, gitleaks configs are toml and trufflehog rules
+// are json or yaml
+func RuleFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		return RuleFormatGitleaks
+	case ".json", ".yml", ".yaml":
+		return RuleFormatTrufflehog
+	}
+	return ""
+}
+
+// ConvertRules will convert the rules of a gitleaks config or a trufflehog rule file into pattern signatures at a match
+// level. The id of each signature is that of its rule prefixed with the format, so a converted rule does not override
+// a signature of the same name when the files are loaded together.
+func ConvertRules(data []byte, format string, matchLevel int) (*RuleConversion, error) {
+	c := &RuleConversion{Format: format}
+	switch format {
+	case RuleFormatGitleaks:
+		var cfg gitleaksConfig
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("unable to read the gitleaks config: %s", err)
+		}
+		for _, rule := range cfg.Rules {
+			c.addGitleaksRule(rule, matchLevel)
+		}
+	case RuleFormatTrufflehog:
+		// trufflehog 2 took a json object of rule names and regexes, trufflehog 3 takes a yaml list of detectors
+		var rules map[string]string
+		if err := json.Unmarshal(data, &rules); err == nil {
+			names := make([]string, 0, len(rules))
+			for name := range rules {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				c.addTrufflehogDetector(trufflehogDetector{Name: name, Regex: map[string]string{"": rules[name]}}, matchLevel)
+			}
+			break
+		}
+		var cfg trufflehogConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("unable to read the trufflehog rules: %s", err)
+		}
+		for _, detector := range cfg.Detectors {
+			c.addTrufflehogDetector(detector, matchLevel)
+		}
+	default:
+		return nil, fmt.Errorf("unknown rule format %s, it must be one of: %s", format, strings.Join(RuleFormats, ", "))
+	}
+	if c.Rules == 0 {
+		return nil, fmt.Errorf("there are no %s rules in the file", format)
+	}
+	return c, nil
+}
+
+// addGitleaksRule will convert a gitleaks rule into a signature, matching its regex against the content of files or,
+// when it has none, its path or file against their path or name
+func (c *RuleConversion) addGitleaksRule(rule gitleaksRule, matchLevel int) {
+	c.Rules++
+	name := rule.ID
+	if name == "" {
+		name = rule.Description
+	}
+
+	sig := SignatureDef{
+		Description: rule.Description,
+		Enable:      1,
+		Entropy:     rule.Entropy,
+		MatchLevel:  matchLevel,
+		Tags:        ruleTags(RuleFormatGitleaks, rule.Tags),
+	}
+	if rule.Regex != "" {
+		sig.Keywords = rule.Keywords
+	}
+	if sig.Description == "" {
+		sig.Description = rule.ID
+	}
+
+	var notes []string
+	switch {
+	case rule.Regex != "":
+		sig.Match, sig.Part = rule.Regex, PartContent
+		if rule.Path != "" || rule.File != "" {
+			notes = append(notes, "it is matched against every file, not only those its path or file matches")
+		}
+	case rule.Path != "":
+		sig.Match, sig.Part = rule.Path, PartPath
+	case rule.File != "":
+		sig.Match, sig.Part = rule.File, PartFilename
+	}
+	if rule.SecretGroup > 0 {
+		notes = append(notes, fmt.Sprintf("the whole match is the secret rather than group %d", rule.SecretGroup))
+	}
+	if len(rule.Entropies) > 0 {
+		notes = append(notes, "its entropy ranges are not converted")
+	}
+	if len(rule.Allowlist) > 0 || len(rule.Allowlists) > 0 {
+		notes = append(notes, "its allowlist is not converted")
+	}
+	c.add(name, sig, notes)
+}
+
+// addTrufflehogDetector will convert a trufflehog detector into a signature for each of its regexes
+func (c *RuleConversion) addTrufflehogDetector(detector trufflehogDetector, matchLevel int) {
+	c.Rules++
+	if len(detector.Regex) == 0 {
+		c.Skipped = append(c.Skipped, fmt.Sprintf("%s: it has no regex", detector.Name))
+		return
+	}
+
+	var notes []string
+	if len(detector.Regex) > 1 {
+		notes = append(notes, "each of its regexes is a signature of its own rather than all of them having to match")
+	}
+	if len(detector.Verify) > 0 {
+		notes = append(notes, "its verification is not converted")
+	}
+	if len(detector.ExcludeWords) > 0 || len(detector.ExcludeRegexesMatch) > 0 {
+		notes = append(notes, "its exclusions are not converted")
+	}
+
+	keys := make([]string, 0, len(detector.Regex))
+	for key := range detector.Regex {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, description := detector.Name, detector.Name
+		if len(keys) > 1 {
+			name, description = detector.Name+" "+key, detector.Name+" ("+key+")"
+		}
+		c.add(name, SignatureDef{
+			Description: description,
+			Enable:      1,
+			Entropy:     detector.Entropy,
+			Match:       detector.Regex[key],
+			MatchLevel:  matchLevel,
+			Part:        PartContent,
+			Tags:        ruleTags(RuleFormatTrufflehog, nil),
+			Keywords:    detector.Keywords,
+		}, notes)
+	}
+}
+
+// add will add the signature converted from a rule, unless it has nothing to match or its match is not a regex wraith
+// can use
+func (c *RuleConversion) add(name string, sig SignatureDef, notes []string) {
+	sig.Signatureid = c.Format + "-" + strings.Trim(ruleIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	sig.Comment = fmt.Sprintf("converted from the %s rule %s", c.Format, name)
+	if sig.Match == "" {
+		c.Skipped = append(c.Skipped, fmt.Sprintf("%s: it has nothing to match", name))
+		return
+	}
+	if _, err := regexp.Compile(sig.Match); err != nil {
+		c.Skipped = append(c.Skipped, fmt.Sprintf("%s: its regex is not one wraith can use: %s", name, err))
+		return
+	}
+	for _, s := range c.Signatures {
+		if s.Signatureid == sig.Signatureid {
+			c.Skipped = append(c.Skipped, fmt.Sprintf("%s: its id %s is taken by a rule before it", name, sig.Signatureid))
+			return
+		}
+	}
+	sig.Part = signatureParts[sig.Part]
+	for _, note := range notes {
+		c.Notes = append(c.Notes, fmt.Sprintf("%s: %s", name, note))
+	}
+	c.Signatures = append(c.Signatures, sig)
+}
+
+// ruleTags are the tags of a converted rule, lowercased and with the format they were converted from
+func ruleTags(format string, tags []string) []string {
+	converted := []string{format}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && tag != format {
+			converted = append(converted, tag)
+		}
+	}
+	return converted
+}
+
+// YAML will write the converted signatures as a signature file, with a comment saying where they came from
+func (c *RuleConversion) YAML(source string) ([]byte, error) {
+	data, err := yaml.Marshal(struct {
+		PatternSignatures []SignatureDef `yaml:"PatternSignatures"`
+	}{c.Signatures})
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Converted from the %s rules in %s by %s convertSignatures\n", c.Format, filepath.Base(source), Name)
+	return append([]byte(header), data...), nil
+}
@@ -0,0 +1,130 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+const gitleaksRules = `
+title = "acme"
+
+[[rules]]
+id = "aws-access-key"
+description = "AWS Access Key"
+regex = '''AKIA[0-9A-Z]{16}'''
+tags = ["AWS", "key"]
+keywords = ["akia"]
+
+[[rules]]
+id = "slack-token"
+description = "Slack Token"
+regex = '''xox[bp]-([0-9a-zA-Z-]{10,48})'''
+secretGroup = 1
+[rules.allowlist]
+regexes = ['''xoxb-example''']
+
+[[rules]]
+id = "pkcs12-file"
+description = "PKCS12 file"
+path = '''\.p12$'''
+
+[[rules]]
+id = "generic-password"
+description = "Generic password"
+regex = '''password\s*=\s*(?=[a-z])'''
+
+[[rules]]
+id = "empty"
+description = "A rule with nothing to match"
+`
+
+func TestConvertRules(t *testing.T) {
+
+	Convey("Given a gitleaks config", t, func() {
+		c, err := core.ConvertRules([]byte(gitleaksRules), core.RuleFormatGitleaks, 3)
+		So(err, ShouldBeNil)
+
+		Convey("Its rules should be converted into signatures, with what was left out of them noted", func() {
+			So(c.Rules, ShouldEqual, 5)
+			So(c.Signatures, ShouldHaveLength, 3)
+			So(c.Signatures[0].Signatureid, ShouldEqual, "gitleaks-aws-access-key")
+			So(c.Signatures[0].Match, ShouldEqual, "AKIA[0-9A-Z]{16}")
+			So(c.Signatures[0].Tags, ShouldResemble, []string{"gitleaks", "aws", "key"})
+			So(c.Signatures[0].Keywords, ShouldResemble, []string{"akia"})
+			So(c.Notes, ShouldResemble, []string{
+				"slack-token: the whole match is the secret rather than group 1",
+				"slack-token: its allowlist is not converted",
+			})
+			So(c.Skipped, ShouldHaveLength, 2)
+			So(c.Skipped[0], ShouldStartWith, "generic-password: its regex is not one wraith can use")
+			So(c.Skipped[1], ShouldEqual, "empty: it has nothing to match")
+		})
+
+		Convey("The signature file written should load, with a rule of a path matching paths", func() {
+			data, err := c.YAML("gitleaks.toml")
+			So(err, ShouldBeNil)
+			dir, err := ioutil.TempDir("", "wraith-convert")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			sess := scanSession(dir)
+			sigFile := filepath.Join(dir, "gitleaks.yml")
+			So(ioutil.WriteFile(sigFile, data, 0600), ShouldBeNil)
+
+			signatures := core.LoadSignatures(sigFile, 1, sess)
+			So(signatures, ShouldHaveLength, 3)
+			So(signatures[0].Part(), ShouldEqual, core.PartContent)
+			So(signatures[2].Signatureid(), ShouldEqual, "gitleaks-pkcs12-file")
+			So(signatures[2].Part(), ShouldEqual, core.PartPath)
+		})
+	})
+
+	Convey("Given the rules of trufflehog 2", t, func() {
+		c, err := core.ConvertRules([]byte(`{"Slack Token": "xox[bp]-[0-9a-zA-Z-]{10,48}", "AWS API Key": "AKIA[0-9A-Z]{16}"}`),
+			core.RuleFormatTrufflehog, 4)
+		So(err, ShouldBeNil)
+
+		Convey("Each rule should be a content signature", func() {
+			So(c.Signatures, ShouldHaveLength, 2)
+			So(c.Signatures[0].Signatureid, ShouldEqual, "trufflehog-aws-api-key")
+			So(c.Signatures[0].MatchLevel, ShouldEqual, 4)
+			So(c.Signatures[1].Description, ShouldEqual, "Slack Token")
+			So(c.Skipped, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given the custom detectors of trufflehog 3", t, func() {
+		c, err := core.ConvertRules([]byte(`detectors:
+  - name: acme
+    keywords: [acme]
+    regex:
+      id: acme_[0-9a-f]{8}
+      secret: acmesecret_[0-9a-f]{32}
+    verify:
+      - endpoint: https://verify.acme.test
+  - name: nothing
+    keywords: [nothing]
+`), core.RuleFormatTrufflehog, 3)
+		So(err, ShouldBeNil)
+
+		Convey("Each regex of a detector should be a signature, with what was left out of it noted", func() {
+			So(c.Rules, ShouldEqual, 2)
+			So(c.Signatures, ShouldHaveLength, 2)
+			So(c.Signatures[0].Signatureid, ShouldEqual, "trufflehog-acme-id")
+			So(c.Signatures[1].Signatureid, ShouldEqual, "trufflehog-acme-secret")
+			So(c.Signatures[1].Keywords, ShouldResemble, []string{"acme"})
+			So(c.Notes, ShouldContain, "acme id: its verification is not converted")
+			So(c.Skipped, ShouldResemble, []string{"nothing: it has no regex"})
+		})
+	})
+
+	Convey("A file of an unknown format should not be converted", t, func() {
+		_, err := core.ConvertRules([]byte("rules: []"), "semgrep", 3)
+		So(err, ShouldNotBeNil)
+		So(core.RuleFormat("gitleaks.toml"), ShouldEqual, core.RuleFormatGitleaks)
+		So(core.RuleFormat("rules.json"), ShouldEqual, core.RuleFormatTrufflehog)
+	})
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"strings"
+)
+
+// SignatureFilter picks which of the signatures that are loaded are used in a scan, without changing the signature
+// files, which are often shared by everyone scanning. It is set with --signature-tags, --enable-signatures and
+// --disable-signatures.
+type SignatureFilter struct {
+	Tags     []string // only signatures with one of these tags are used, unless they are enabled by id
+	Enabled  []string // ids of signatures to use even when they are turned off in their file
+	Disabled []string // ids of signatures to leave out
+}
+
+// NewSignatureFilter will create a filter from comma separated lists of tags and ids. It is nil when nothing is
+// filtered, which uses every signature that is turned on.
+func NewSignatureFilter(tags, enabled, disabled []string) *SignatureFilter {
+	f := &SignatureFilter{
+		Tags:     splitCommaList(tags),
+		Enabled:  splitCommaList(enabled),
+		Disabled: splitCommaList(disabled),
+	}
+	if len(f.Tags) == 0 && len(f.Enabled) == 0 && len(f.Disabled) == 0 {
+		return nil
+	}
+	return f
+}
+
+// splitCommaList will split each of the values on commas, leaving out the ones that are empty
+func splitCommaList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+// turnsOn will check whether a signature of a file is used, either because it is turned on in the file or because it
+// is enabled by its id
+func (f *SignatureFilter) turnsOn(def SignatureDef) bool {
+	if def.Enable > 0 {
+		return true
+	}
+	return f != nil && def.Signatureid != "" && containsFold(f.Enabled, []string{def.Signatureid})
+}
+
+// Allows will check whether a signature that is turned on is used. A signature that is disabled by its id never is,
+// one that is enabled by its id always is, and the rest have to have one of the tags when any are given.
+func (f *SignatureFilter) Allows(sig Signature) bool {
+	if f == nil {
+		return true
+	}
+	id := []string{sig.Signatureid()}
+	if containsFold(f.Disabled, id) {
+		return false
+	}
+	return len(f.Tags) == 0 || containsFold(f.Enabled, id) || containsFold(f.Tags, sig.Tags())
+}
+
+// Filter will return the signatures the filter allows, in the same order
+func (f *SignatureFilter) Filter(signatures []Signature) []Signature {
+	if f == nil {
+		return signatures
+	}
+	var allowed []Signature
+	for _, sig := range signatures {
+		if f.Allows(sig) {
+			allowed = append(allowed, sig)
+		}
+	}
+	return allowed
+}
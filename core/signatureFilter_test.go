@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"wraith/core"
+)
+
+func TestSignatureFilter(t *testing.T) {
+
+	Convey("Given signatures with tags, one of them turned off in its file", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-signature-filter")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		sess := scanSession(dir)
+		signatureFile := filepath.Join(dir, "default.yml")
+		So(ioutil.WriteFile(signatureFile, []byte(`PatternSignatures:
+  - description: AWS Access Key ID
+    enable: 1
+    match: AKIA[0-9A-Z]{16}
+    match-level: 3
+    signatureid: aws-id
+    tags: [aws, cloud]
+  - description: GCP API Key
+    enable: 1
+    match: AIza[0-9A-Za-z_-]{35}
+    match-level: 3
+    signatureid: gcp-api-key
+    tags: [gcp, cloud]
+  - description: Generic Password
+    enable: 0
+    match: password\s*=\s*\S+
+    match-level: 3
+    signatureid: GENERIC-001
+    tags: [generic]
+  - description: Slack Token
+    enable: 1
+    match: xox[bp]-[0-9a-z-]+
+    match-level: 3
+    signatureid: slack-token
+`), 0600), ShouldBeNil)
+
+		load := func(tags, enabled, disabled string) []string {
+			sess.SignatureFilter = core.NewSignatureFilter([]string{tags}, []string{enabled}, []string{disabled})
+			signatures := core.LoadSignatureFiles([]string{signatureFile}, 3, sess)
+			var ids []string
+			for _, s := range sess.SignatureFilter.Filter(signatures) {
+				ids = append(ids, s.Signatureid())
+			}
+			sort.Strings(ids)
+			return ids
+		}
+
+		Convey("Every signature that is turned on should be used when nothing is filtered", func() {
+			So(core.NewSignatureFilter(nil, []string{""}, nil), ShouldBeNil)
+			So(load("", "", ""), ShouldResemble, []string{"aws-id", "gcp-api-key", "slack-token"})
+		})
+
+		Convey("Only the signatures with one of the tags should be used", func() {
+			So(load("aws, GCP", "", ""), ShouldResemble, []string{"aws-id", "gcp-api-key"})
+			So(load("cloud", "", "gcp-api-key"), ShouldResemble, []string{"aws-id"})
+		})
+
+		Convey("A signature that is turned off in its file should be used when it is enabled by its id", func() {
+			So(load("", "generic-001", ""), ShouldResemble, []string{"GENERIC-001", "aws-id", "gcp-api-key", "slack-token"})
+			So(load("aws", "GENERIC-001", ""), ShouldResemble, []string{"GENERIC-001", "aws-id"})
+		})
+
+		Convey("A signature that is disabled by its id should be left out even when it is enabled", func() {
+			So(load("", "GENERIC-001", "slack-token,GENERIC-001"), ShouldResemble, []string{"aws-id", "gcp-api-key"})
+		})
+
+		Convey("The entropy signatures should be picked by the entropy tag", func() {
+			filter := core.NewSignatureFilter([]string{"entropy"}, nil, nil)
+			So(filter.Allows(core.NewEntropySignature(core.EntropyCharsetHex, 3, 20)), ShouldBeTrue)
+			So(core.NewSignatureFilter([]string{"aws"}, nil, nil).Allows(core.NewEntropySignature(core.EntropyCharsetHex, 3, 20)), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+)
+
+// ReloadSignatures will read the signature files of the session again and match against them in everything scanned
+// from then on. When they can not be loaded the signatures in use are kept. It returns how many signatures are in use.
+func (s *Session) ReloadSignatures() (int, error) {
+	var signatures []Signature
+	var safe []SafeFunctionSignature
+	if len(s.SignatureFiles) > 0 {
+		loaded, loadedSafe, err := readSignatureFiles(s.SignatureFiles, s.MatchLevel, s)
+		if err != nil {
+			return 0, err
+		}
+		signatures, safe = loaded, loadedSafe
+	}
+	signatures = s.SignatureFilter.Filter(append(signatures, s.entropySignatures...))
+	setSignatureSet(signatures, safe)
+	return len(signatures), nil
+}
+
+// WatchSignatures will reload the signatures whenever a yaml file in one of the signature directories, or in the
+// directory of one of the signature files, changes
+func (s *Session) WatchSignatures() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	watched := map[string]bool{}
+	for _, p := range s.SignatureFiles {
+		p = SetHomeDir(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		dir := p
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			dir = filepath.Dir(p)
+		}
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+		watched[dir] = true
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isSignatureFile(event.Name) {
+					continue
+				}
+				count, err := s.ReloadSignatures()
+				if err != nil {
+					s.Out.Error("Failed to reload signatures, the ones loaded before are still in use: %s\n", err)
+					continue
+				}
+				s.Out.Info("Reloaded %d %s\n", count, Pluralize(count, "signature", "signatures"))
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.Out.Error("Error watching the signature files: %s\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadSignatures reloads the signatures of the server, such as after updateSignatures has been run on a machine
+// where the signature directory is not watched
+func reloadSignatures(c *gin.Context, s *Session) {
+	count, err := s.ReloadSignatures()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"signatures": count,
+	})
+}
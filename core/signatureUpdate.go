@@ -0,0 +1,250 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// These are the defaults used by the updateSignatures command. A signed bundle keeps a sha256sum formatted checksums
+// file of its signature files alongside them, with its ed25519 signature in base64.
+const (
+	DefaultSignaturesDir     = "$HOME/.wraith/signatures"
+	DefaultSignaturesPath    = "signatures"
+	SignatureChecksumsFile   = "checksums.txt"
+	SignatureSignatureFile   = "checksums.txt.sig"
+	signatureSignatureSuffix = ".sig"
+)
+
+// SignatureSource is where updateSignatures fetches signatures from, either a git repository or a single signature file
+// served over https
+type SignatureSource struct {
+	URL       string
+	Ref       string // the branch, tag or commit of a git repository, whatever HEAD points at when empty
+	Path      string // the directory of a git repository the signature files are in
+	SHA256    string // the checksum a signature file served over https must have
+	PublicKey string // the base64 ed25519 key the signature files are signed with
+}
+
+// isGitSignatureSource is whether signatures are cloned from a git repository rather than downloaded as a single file,
+// anything other than an http url that does not end in .git is cloned
+func isGitSignatureSource(u string) bool {
+	if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return true
+	}
+	return strings.HasSuffix(strings.TrimSuffix(u, "/"), ".git")
+}
+
+// isSignatureFile is whether a file is a yaml signature file
+func isSignatureFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// FetchSignatures will fetch the signature files of a source into memory, keyed by their names, verifying them against
+// the checksum or public key of the source when one is given
+func FetchSignatures(src SignatureSource) (map[string][]byte, error) {
+	if src.URL == "" {
+		return nil, errors.New("no signature url was given")
+	}
+	if isGitSignatureSource(src.URL) {
+		if src.SHA256 != "" {
+			return nil, errors.New("a sha256 can only be checked for a signature file served over https, pin a git repository with a commit as its ref")
+		}
+		return fetchGitSignatures(src)
+	}
+	return fetchHTTPSignatures(src)
+}
+
+// fetchHTTPSignatures will download a single signature file, along with its signature at the same url ending in .sig
+// when there is a public key
+func fetchHTTPSignatures(src SignatureSource) (map[string][]byte, error) {
+	u, err := url.Parse(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(u.Path)
+	if !isSignatureFile(name) {
+		return nil, fmt.Errorf("%s is not a yaml signature file", src.URL)
+	}
+
+	data, err := fetchRelease(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	if src.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(strings.TrimSpace(src.SHA256)) {
+			return nil, fmt.Errorf("the checksum of %s does not match %s", name, src.SHA256)
+		}
+	}
+	if src.PublicKey != "" {
+		u.Path += signatureSignatureSuffix
+		signature, err := fetchRelease(u.String())
+		if err != nil {
+			return nil, fmt.Errorf("unable to download the signature of %s: %s", name, err)
+		}
+		if err := verifyEd25519(data, signature, src.PublicKey, "signatures", "signatures in "+name); err != nil {
+			return nil, err
+		}
+	}
+	return map[string][]byte{name: data}, nil
+}
+
+// fetchGitSignatures will clone a git repository in memory and read the signature files in its signature path at the
+// ref of the source. With a public key the files must be listed in the signed checksums kept alongside them.
+func fetchGitSignatures(src SignatureSource) (map[string][]byte, error) {
+	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: src.URL})
+	if err != nil {
+		return nil, fmt.Errorf("unable to clone %s: %s", src.URL, err)
+	}
+
+	var hash *plumbing.Hash
+	if src.Ref == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		h := head.Hash()
+		hash = &h
+	} else {
+		// branches are only kept as remote branches of a clone
+		for _, rev := range []string{src.Ref, "origin/" + src.Ref} {
+			if hash, err = repo.ResolveRevision(plumbing.Revision(rev)); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("there is no branch, tag or commit %s in %s", src.Ref, src.URL)
+		}
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.Trim(src.Path, "/")
+	if dir == "" {
+		dir = DefaultSignaturesPath
+	}
+	if dir != "." {
+		if tree, err = tree.Tree(dir); err != nil {
+			return nil, fmt.Errorf("there is no directory %s in %s", dir, src.URL)
+		}
+	}
+
+	files := map[string][]byte{}
+	var checksums, signature []byte
+	for _, entry := range tree.Entries {
+		if !entry.Mode.IsFile() || (!isSignatureFile(entry.Name) && entry.Name != SignatureChecksumsFile && entry.Name != SignatureSignatureFile) {
+			continue
+		}
+		content, err := gitBlobContent(tree, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch entry.Name {
+		case SignatureChecksumsFile:
+			checksums = content
+		case SignatureSignatureFile:
+			signature = content
+		default:
+			files[entry.Name] = content
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("there are no signature files in %s of %s", dir, src.URL)
+	}
+
+	if src.PublicKey != "" {
+		if checksums == nil || signature == nil {
+			return nil, fmt.Errorf("%s of %s is not signed, it has no %s or %s", dir, src.URL, SignatureChecksumsFile, SignatureSignatureFile)
+		}
+		if err := verifyEd25519(checksums, signature, src.PublicKey, "signatures", "signature checksums"); err != nil {
+			return nil, err
+		}
+		for name, content := range files {
+			if err := verifyChecksum(content, checksums, name, "signature"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// gitBlobContent is the content of a file in a tree
+func gitBlobContent(tree *object.Tree, name string) ([]byte, error) {
+	file, err := tree.File(name)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// InstallSignatures will check that each signature file loads and then move them into the directory together, so a
+// running server that is watching it never loads a file that is half written or that does not load. It returns the
+// paths of the files installed.
+func InstallSignatures(files map[string][]byte, dir string) ([]string, error) {
+	dir = SetHomeDir(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	staging, err := ioutil.TempDir(dir, ".update")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	var names []string
+	for name := range files {
+		if name != filepath.Base(name) || !isSignatureFile(name) {
+			return nil, fmt.Errorf("%s is not a yaml signature file", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		staged := filepath.Join(staging, name)
+		if err := ioutil.WriteFile(staged, files[name], 0600); err != nil {
+			return nil, err
+		}
+		// the session only takes the version of the signatures from a file, which is not needed to check it loads, and
+		// logs what it finds in them at the debug level
+		if _, _, _, err := loadSignatureFile(staged, 0, &Session{Out: &Logger{}}); err != nil {
+			return nil, fmt.Errorf("%s can not be loaded: %s", name, err)
+		}
+	}
+
+	var installed []string
+	for _, name := range names {
+		target := filepath.Join(dir, name)
+		if err := os.Rename(filepath.Join(staging, name), target); err != nil {
+			return installed, err
+		}
+		installed = append(installed, target)
+	}
+	return installed, nil
+}
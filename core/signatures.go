@@ -11,9 +11,11 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // this are the various items that we are attempting to match against using either regex's or simple pattern matches.
@@ -27,8 +29,60 @@ const (
 // Signatures holds a list of all signatures used during the session
 var Signatures []Signature
 
-// SafeFunctionSignatures is a collection of safe function sigs
-var SafeFunctionSignatures = []SafeFunctionSignature{}
+// signaturesLock guards Signatures, which a running server can reload while scans are matching against them
+var signaturesLock sync.RWMutex
+
+// CurrentSignatures returns the signatures in use, a reload replaces them rather than changing them in place so they
+// can be ranged over after the lock is released
+func CurrentSignatures() []Signature {
+	signaturesLock.RLock()
+	defer signaturesLock.RUnlock()
+	return Signatures
+}
+
+// SetSignatures will replace the signatures in use
+func SetSignatures(signatures []Signature) {
+	signaturesLock.Lock()
+	defer signaturesLock.Unlock()
+	Signatures = signatures
+}
+
+// setSignatureSet will replace the signatures in use along with the safe function signatures loaded from the same
+// files, so a scan never matches against the signatures of one load and the safe text of another
+func setSignatureSet(signatures []Signature, safe []SafeFunctionSignature) {
+	signaturesLock.Lock()
+	defer signaturesLock.Unlock()
+	Signatures = signatures
+	safeFunctionSignatures = safe
+}
+
+// signaturesVersion is the version of the signature files that were loaded for the process
+var signaturesVersion string
+
+// CurrentSignaturesVersion returns the version of the signatures that were loaded for the process
+func CurrentSignaturesVersion() string {
+	signaturesLock.RLock()
+	defer signaturesLock.RUnlock()
+	return signaturesVersion
+}
+
+// setSignaturesVersion will record the version of the signatures that were loaded for the process
+func setSignaturesVersion(version string) {
+	signaturesLock.Lock()
+	defer signaturesLock.Unlock()
+	signaturesVersion = version
+}
+
+// safeFunctionSignatures match text that is known to be safe (aka not a password), it is guarded by signaturesLock
+// and replaced along with the signatures
+var safeFunctionSignatures []SafeFunctionSignature
+
+// currentSafeFunctionSignatures returns the safe function signatures in use
+func currentSafeFunctionSignatures() []SafeFunctionSignature {
+	signaturesLock.RLock()
+	defer signaturesLock.RUnlock()
+	return safeFunctionSignatures
+}
 
 // loadSignatureSet will read in the defined signatures from an external source
 func loadSignatureSet(filename string) (SignatureConfig, error) {
@@ -85,6 +139,10 @@ type Signature interface {
 	MatchLevel() int
 	Part() string
 	Signatureid() string // TODO change id -> ID
+	Severity() string
+	Confidence() string
+	Tags() []string
+	Remediation() *Remediation
 }
 
 // SignaturesMetaData is used by updateSignatures to determine if/how to update the signatures
@@ -104,6 +162,10 @@ type SafeFunctionSignature struct {
 	matchLevel  int
 	part        string
 	signatureid string
+	severity    string
+	confidence  string
+	tags        []string
+	remediation *Remediation
 }
 
 // SimpleSignature holds the information about a simple signature which is used to match a path or filename
@@ -116,6 +178,10 @@ type SimpleSignature struct {
 	matchLevel  int
 	part        string
 	signatureid string
+	severity    string
+	confidence  string
+	tags        []string
+	remediation *Remediation
 }
 
 // PatternSignature holds the information about a pattern signature which is a regex used to match content within a file
@@ -128,18 +194,48 @@ type PatternSignature struct {
 	matchLevel  int
 	part        string
 	signatureid string
+	severity    string
+	confidence  string
+	tags        []string
+	remediation *Remediation
+	keywords    *KeywordMatcher // content without any of the keywords is not run through the regex
 }
 
 // SignatureDef maps to a signature within the yaml file
 type SignatureDef struct {
-	Comment     string  `yaml:"comment"`
-	Description string  `yaml:"description"`
-	Enable      int     `yaml:"enable"`
-	Entropy     float64 `yaml:"entropy"`
-	Match       string  `yaml:"match"`
-	MatchLevel  int     `yaml:"match-level"`
-	Part        string  `yaml:"part"`
-	Signatureid string  `yaml:"signatureid"`
+	Comment     string   `yaml:"comment,omitempty"`
+	Description string   `yaml:"description"`
+	Enable      int      `yaml:"enable"`
+	Entropy     float64  `yaml:"entropy,omitempty"`
+	Match       string   `yaml:"match"`
+	MatchLevel  int      `yaml:"match-level"`
+	Part        string   `yaml:"part"`
+	Signatureid string   `yaml:"signatureid"`
+	Severity    string   `yaml:"severity,omitempty"`
+	Confidence  string   `yaml:"confidence,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Keywords    []string `yaml:"keywords,omitempty"`
+
+	Remediation *Remediation `yaml:"remediation,omitempty"`
+}
+
+// ratings returns the severity and confidence of a signature. Either one that is not set is taken from the match
+// level, so signatures written before they existed keep rating findings as they did.
+func (d SignatureDef) ratings() (string, string, error) {
+	severity := strings.ToLower(strings.TrimSpace(d.Severity))
+	if severity == "" {
+		severity = ScoreSeverity(d.MatchLevel)
+	} else if !ValidSeverity(severity) {
+		return "", "", fmt.Errorf("unknown severity %s for %s, it must be one of: %s", d.Severity, d.Description, strings.Join(Severities, ", "))
+	}
+
+	confidence := strings.ToLower(strings.TrimSpace(d.Confidence))
+	if confidence == "" {
+		confidence = ScoreConfidence(d.MatchLevel)
+	} else if !ValidConfidence(confidence) {
+		return "", "", fmt.Errorf("unknown confidence %s for %s, it must be one of: %s", d.Confidence, d.Description, strings.Join(Confidences, ", "))
+	}
+	return severity, confidence, nil
 }
 
 // SignatureConfig holds the base file structure for the signatures file
@@ -200,10 +296,30 @@ func (s SimpleSignature) Signatureid() string {
 	return s.signatureid
 }
 
+// Severity is how much harm the secrets the signature finds can do
+func (s SimpleSignature) Severity() string {
+	return s.severity
+}
+
+// Confidence is how likely a match of the signature is to be a real secret
+func (s SimpleSignature) Confidence() string {
+	return s.confidence
+}
+
+// Tags are the categories the signature is in, such as aws or database, which --signature-tags picks signatures by
+func (s SimpleSignature) Tags() []string {
+	return s.tags
+}
+
+// Remediation returns how to rotate and store the secrets the signature finds, or nil
+func (s SimpleSignature) Remediation() *Remediation {
+	return s.remediation
+}
+
 // IsSafeText check against known "safe" (aka not a password) list
 func IsSafeText(sMatchString *string) bool {
 	bResult := false
-	for _, safeSig := range SafeFunctionSignatures {
+	for _, safeSig := range currentSafeFunctionSignatures() {
 		if safeSig.match.MatchString(*sMatchString) {
 			bResult = true
 		}
@@ -226,6 +342,9 @@ func confirmEntropy(thisMatch string, iSessionEntropy float64) bool {
 	return bResult
 }
 
+// DefaultSuppressionMarker is the comment that leaves out the findings on its own line and on the line after it
+const DefaultSuppressionMarker = "wraith:ignore"
+
 // ExtractMatch will try and find a match within the content of the file.
 func (s PatternSignature) ExtractMatch(file MatchFile, sess *Session, change *object.Change) (bool, map[string]int) {
 
@@ -245,74 +364,106 @@ func (s PatternSignature) ExtractMatch(file MatchFile, sess *Session, change *ob
 		bResult = s.match.MatchString(*haystack)
 	case PartContent:
 		haystack := &file.Path
-		if PathExists(*haystack, sess) {
-			if _, err := os.Stat(*haystack); err == nil {
-				data, err := ioutil.ReadFile(*haystack)
+		// a file on another branch, or one that has since been deleted, is only in the change and not on disk
+		onDisk := PathExists(*haystack, sess)
+		if onDisk || change != nil {
+			var data []byte
+			var err error
+			if onDisk {
+				data, err = ioutil.ReadFile(*haystack)
 				if err != nil {
 					sErrAppend := fmt.Sprintf("ERROR --- Unable to open file for scanning: <%s> \nError Message: <%s>", *haystack, err)
 					results[sErrAppend] = 0 // set to zero due to error, we never have a line 0 so we can always ignore that or error on it
 					return false, results
 				}
+			}
 
-				r := s.match // this is the regex that we are going to try and match against
-
-				var contextMatches []string
-
-				if r.Match(data) {
-					for _, curRegexMatch := range r.FindAll(data, -1) {
-						contextMatches = append(contextMatches, string(curRegexMatch))
-					}
-					if len(contextMatches) > 0 {
-						bResult = true
-						for i, curMatch := range contextMatches {
-							thisMatch := string(curMatch[:])
-							thisMatch = strings.TrimSuffix(thisMatch, "\n")
-
-							bResult = confirmEntropy(thisMatch, s.entropy)
+			r := s.match // this is the regex that we are going to try and match against
 
-							if bResult {
-								linesOfScannedFile := strings.Split(string(data), "\n")
-								linesOfScannedFile = linesOfScannedFile[:len(linesOfScannedFile)] // TODO Is this needed?
+			var contextMatches []string
+			suppressed := map[string]bool{} // the secrets left out because of a suppression comment
 
-								num := fetchLineNumber(&linesOfScannedFile, thisMatch, i)
-								results[strconv.Itoa(i)+"_"+thisMatch] = num
+			if s.keywords.Match(data) && r.Match(data) {
+				for _, curRegexMatch := range r.FindAll(data, -1) {
+					contextMatches = append(contextMatches, string(curRegexMatch))
+				}
+				if len(contextMatches) > 0 {
+					bResult = true
+					occurrences := map[string]int{} // how many times each secret has been seen, to find the line of the next one
+					for i, curMatch := range contextMatches {
+						thisMatch := string(curMatch[:])
+						thisMatch = strings.TrimSuffix(thisMatch, "\n")
+						occurrence := occurrences[thisMatch]
+						occurrences[thisMatch]++
+
+						bResult = confirmEntropy(thisMatch, s.entropy)
+
+						if bResult {
+							linesOfScannedFile := strings.Split(string(data), "\n")
+							linesOfScannedFile = linesOfScannedFile[:len(linesOfScannedFile)] // TODO Is this needed?
+
+							num := fetchLineNumber(&linesOfScannedFile, thisMatch, occurrence)
+							if isSuppressed(linesOfScannedFile, num, sess) {
+								suppressed[thisMatch] = true
+								continue
 							}
+							results["d"+strconv.Itoa(i)+"_"+thisMatch] = num
 						}
-						//return bResult, results
 					}
+					//return bResult, results
 				}
+			}
 
-				content, err := GetChangeContent(change)
+			// files that are not part of a commit, such as in a path or pull request scan, have no change
+			var content string
+			if change != nil {
+				content, err = GetChangeContent(change)
 				if err != nil {
 					sess.Out.Error("Error retrieving content in commit %s, change %s:  %s", "commit.String()", change.String(), err)
 				} // TODO bring in the commit
+			}
 
-				if r.Match([]byte(content)) {
-					for _, curRegexMatch := range r.FindAll([]byte(content), -1) {
-						contextMatches = append(contextMatches, string(curRegexMatch))
-					}
-					if len(contextMatches) > 0 {
-						bResult = true
-						for i, curMatch := range contextMatches {
-							thisMatch := string(curMatch[:])
-							thisMatch = strings.TrimSuffix(thisMatch, "\n")
-
-							bResult = confirmEntropy(thisMatch, s.entropy)
-
-							if bResult {
-								linesOfScannedFile := strings.Split(content, "\n")
-								linesOfScannedFile = linesOfScannedFile[:len(linesOfScannedFile)] // TODO Is this needed?
-
-								num := fetchLineNumber(&linesOfScannedFile, thisMatch, i)
-								results[strconv.Itoa(i)+"_"+thisMatch] = num
+			// the matches in the change are numbered by their own lines, not those of the file on disk, and are keyed
+			// apart from those so the same secret found in both keeps both of its lines
+			contextMatches = nil
+			if s.keywords.MatchString(content) && r.Match([]byte(content)) {
+				for _, curRegexMatch := range r.FindAll([]byte(content), -1) {
+					contextMatches = append(contextMatches, string(curRegexMatch))
+				}
+				if len(contextMatches) > 0 {
+					bResult = true
+					occurrences := map[string]int{} // how many times each secret has been seen, to find the line of the next one
+					for i, curMatch := range contextMatches {
+						thisMatch := string(curMatch[:])
+						thisMatch = strings.TrimSuffix(thisMatch, "\n")
+						occurrence := occurrences[thisMatch]
+						occurrences[thisMatch]++
+
+						bResult = confirmEntropy(thisMatch, s.entropy)
+
+						if bResult {
+							linesOfScannedFile := strings.Split(content, "\n")
+							linesOfScannedFile = linesOfScannedFile[:len(linesOfScannedFile)] // TODO Is this needed?
+
+							num := fetchLineNumber(&linesOfScannedFile, thisMatch, occurrence)
+							if isSuppressed(linesOfScannedFile, num, sess) {
+								suppressed[thisMatch] = true
+								continue
 							}
+							results["c"+strconv.Itoa(i)+"_"+thisMatch] = num
 						}
-						//return bResult, results //Lk e nubmer is alway zero
-						//meed to depr
 					}
+					//return bResult, results //Lk e nubmer is alway zero
+					//meed to depr
 				}
-				return bResult, results
 			}
+			for secret := range suppressed {
+				sess.countSuppressed(secret)
+			}
+			if len(suppressed) > 0 && len(results) == 0 {
+				bResult = false
+			}
+			return bResult, results
 		}
 		//default:
 		//	return bResult, results
@@ -320,7 +471,38 @@ func (s PatternSignature) ExtractMatch(file MatchFile, sess *Session, change *ob
 	return bResult, results
 }
 
-// fetchLineNumber will read a file in line by line and when the match is found, save the line number. It manages multiple matches in a file by way of the count and an index
+// isSuppressed will check whether the line a secret was found on, or the line before it, has one of the suppression
+// markers of the session, such as a // wraith:ignore comment. Line numbers start at 1.
+func isSuppressed(lines []string, lineNumber int, sess *Session) bool {
+	for _, n := range []int{lineNumber, lineNumber - 1} {
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		for _, marker := range sess.SuppressionMarkers {
+			if marker != "" && strings.Contains(lines[n-1], marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countSuppressed will count a secret left out because of a suppression comment, once however many commits, files and
+// signatures it is found in
+func (s *Session) countSuppressed(secret string) {
+	s.suppressedLock.Lock()
+	defer s.suppressedLock.Unlock()
+	if s.suppressed == nil {
+		s.suppressed = map[string]bool{}
+	}
+	if !s.suppressed[secret] {
+		s.suppressed[secret] = true
+		s.Stats.IncrementFindingsSuppressed()
+	}
+}
+
+// fetchLineNumber will read a file in line by line and when the match is found, save the line number. It manages multiple matches in a file by way of the count and an index,
+// which is the occurrence of that same match in the file
 func fetchLineNumber(input *[]string, thisMatch string, idx int) int {
 	linesOfScannedFile := *input
 	lineNumIndexMap := make(map[int]int)
@@ -362,6 +544,26 @@ func (s PatternSignature) Signatureid() string {
 	return s.signatureid
 }
 
+// Severity is how much harm the secrets the signature finds can do
+func (s PatternSignature) Severity() string {
+	return s.severity
+}
+
+// Confidence is how likely a match of the signature is to be a real secret
+func (s PatternSignature) Confidence() string {
+	return s.confidence
+}
+
+// Tags are the categories the signature is in, such as aws or database, which --signature-tags picks signatures by
+func (s PatternSignature) Tags() []string {
+	return s.tags
+}
+
+// Remediation returns how to rotate and store the secrets the signature finds, or nil
+func (s PatternSignature) Remediation() *Remediation {
+	return s.remediation
+}
+
 // Enable sets whether as signature is active or not
 func (s SafeFunctionSignature) Enable() int {
 	return s.enable
@@ -387,6 +589,26 @@ func (s SafeFunctionSignature) Signatureid() string {
 	return s.signatureid
 }
 
+// Severity is how much harm the secrets the signature finds can do
+func (s SafeFunctionSignature) Severity() string {
+	return s.severity
+}
+
+// Confidence is how likely a match of the signature is to be a real secret
+func (s SafeFunctionSignature) Confidence() string {
+	return s.confidence
+}
+
+// Tags are the categories the signature is in, such as aws or database, which --signature-tags picks signatures by
+func (s SafeFunctionSignature) Tags() []string {
+	return s.tags
+}
+
+// Remediation returns how to rotate and store the secrets the signature finds, or nil
+func (s SafeFunctionSignature) Remediation() *Remediation {
+	return s.remediation
+}
+
 // ExtractMatch is a placeholder to ensure min code complexity and allow the reuse of the functions
 func (s SafeFunctionSignature) ExtractMatch(file MatchFile, sess *Session, change *object.Change) (bool, map[string]int) {
 	var results map[string]int
@@ -396,14 +618,25 @@ func (s SafeFunctionSignature) ExtractMatch(file MatchFile, sess *Session, chang
 
 // LoadSignatures will load all known signatures for the various match types into the session
 func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { // TODO we don't need to bring in session here
+	signatures, _, _, err := loadSignatureFile(filePath, mLevel, sess)
+	if err != nil {
+		sess.Out.Error("Failed to load signatures file %s: %s\n", SetHomeDir(filePath), err.Error())
+		sess.Out.Exit(2)
+	}
+	return signatures
+}
+
+// loadSignatureFile will load the signatures and safe function signatures in a file, along with the ids of those in it
+// that are turned off or below the match level, so they can take the place of a signature with the same id in a file
+// loaded before
+func loadSignatureFile(filePath string, mLevel int, sess *Session) ([]Signature, []SafeFunctionSignature, []string, error) {
 
 	// ensure that we have the proper home directory
 	filePath = SetHomeDir(filePath)
 
 	c, err := loadSignatureSet(filePath)
 	if err != nil {
-		sess.Out.Error("Failed to load signatures file %s: %s\n", filePath, err.Error())
-		os.Exit(2)
+		return nil, nil, nil, err
 	}
 
 	signaturesMetaData := SignaturesMetaData{
@@ -412,13 +645,25 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 		Time:    c.Meta.Time,
 	}
 
-	sess.SignatureVersion = signaturesMetaData.Version
+	if signaturesMetaData.Version != "" {
+		sess.SignatureVersion = signaturesMetaData.Version
+	}
 
+	var leftOut []string
+	// the content signatures without keywords are matched against every file
+	var withoutKeywords int
 	SimpleSignatures := []SimpleSignature{}   // TODO change this variable name
 	PatternSignatures := []PatternSignature{} // TODO change this variable name
 	for _, curSig := range c.SimpleSignatures {
 
-		if curSig.Enable > 0 && curSig.MatchLevel >= mLevel {
+		if sess.SignatureFilter.turnsOn(curSig) && curSig.MatchLevel >= mLevel {
+			severity, confidence, err := curSig.ratings()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if err := curSig.Remediation.validate(); err != nil {
+				return nil, nil, nil, fmt.Errorf("the remediation of %s is not valid: %s", curSig.Signatureid, err)
+			}
 
 			var part string
 			switch strings.ToLower(curSig.Part) {
@@ -443,12 +688,25 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 				curSig.MatchLevel,
 				part,
 				curSig.Signatureid,
+				severity,
+				confidence,
+				curSig.Tags,
+				curSig.Remediation,
 			})
+		} else {
+			leftOut = append(leftOut, curSig.Signatureid)
 		}
 	}
 
 	for _, curSig := range c.PatternSignatures {
-		if curSig.Enable > 0 && curSig.MatchLevel >= mLevel {
+		if sess.SignatureFilter.turnsOn(curSig) && curSig.MatchLevel >= mLevel {
+			severity, confidence, err := curSig.ratings()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if err := curSig.Remediation.validate(); err != nil {
+				return nil, nil, nil, fmt.Errorf("the remediation of %s is not valid: %s", curSig.Signatureid, err)
+			}
 			var part string
 			switch strings.ToLower(curSig.Part) {
 			case "partpath":
@@ -463,7 +721,23 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 				part = PartContent
 			}
 
-			match := regexp.MustCompile(curSig.Match)
+			match, err := regexp.Compile(curSig.Match)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("the match of %s is not a valid regular expression: %s", curSig.Signatureid, err)
+			}
+
+			// the keywords of the signature file are used as they are, otherwise they are worked out from the regex
+			var keywords *KeywordMatcher
+			if part == PartContent {
+				if len(curSig.Keywords) > 0 {
+					keywords = NewKeywordMatcher(curSig.Keywords)
+				} else {
+					keywords = NewKeywordMatcher(RegexKeywords(curSig.Match))
+				}
+				if keywords == nil {
+					withoutKeywords++
+				}
+			}
 			PatternSignatures = append(PatternSignatures, PatternSignature{
 				curSig.Comment,
 				curSig.Description,
@@ -473,11 +747,26 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 				curSig.MatchLevel,
 				part,
 				curSig.Signatureid,
+				severity,
+				confidence,
+				curSig.Tags,
+				curSig.Remediation,
+				keywords,
 			})
+		} else {
+			leftOut = append(leftOut, curSig.Signatureid)
 		}
 	}
+	var safe []SafeFunctionSignature
 	for _, curSig := range c.SafeFunctionSignatures {
-		if curSig.Enable > 0 && curSig.MatchLevel >= mLevel {
+		if sess.SignatureFilter.turnsOn(curSig) && curSig.MatchLevel >= mLevel {
+			severity, confidence, err := curSig.ratings()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if err := curSig.Remediation.validate(); err != nil {
+				return nil, nil, nil, fmt.Errorf("the remediation of %s is not valid: %s", curSig.Signatureid, err)
+			}
 			var part string
 			switch strings.ToLower(curSig.Part) {
 			case "partpath":
@@ -492,8 +781,11 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 				part = PartContent
 			}
 
-			match := regexp.MustCompile(curSig.Match)
-			SafeFunctionSignatures = append(SafeFunctionSignatures, SafeFunctionSignature{
+			match, err := regexp.Compile(curSig.Match)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("the match of %s is not a valid regular expression: %s", curSig.Signatureid, err)
+			}
+			safe = append(safe, SafeFunctionSignature{
 				curSig.Comment,
 				curSig.Description,
 				curSig.Enable,
@@ -502,6 +794,10 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 				curSig.MatchLevel,
 				part,
 				curSig.Signatureid,
+				severity,
+				confidence,
+				curSig.Tags,
+				curSig.Remediation,
 			})
 		}
 	}
@@ -520,7 +816,118 @@ func LoadSignatures(filePath string, mLevel int, sess *Session) []Signature { //
 		jdx++
 	}
 
-	// TODO are we loading the safe ones somewhere
+	if withoutKeywords > 0 {
+		sess.Out.Debug("%d of the signatures in %s have no keywords, every file is matched against them\n", withoutKeywords, filePath)
+	}
 
-	return Signatures
+	return Signatures, safe, leftOut, nil
+}
+
+// signatureFiles will expand the signature files given into the files to load, in the order they are given. A
+// directory stands for the yaml files in it, in the order of their names. Files that do not exist are left out.
+func signatureFiles(paths []string, sess *Session) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		p = SetHomeDir(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		info, err := os.Stat(p)
+		if os.IsNotExist(err) {
+			sess.Out.Warn("Signature file %s does not exist\n", p)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		entries, err := ioutil.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			ext := strings.ToLower(filepath.Ext(e.Name()))
+			if !e.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				files = append(files, filepath.Join(p, e.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// LoadSignatureFiles will load the signatures in each of the files and directories given and merge them. The
+// signatures in a file take the place of those with the same ids loaded before, and one that is turned off removes
+// them, so a file kept alongside the defaults can change or turn off any of them.
+func LoadSignatureFiles(paths []string, mLevel int, sess *Session) []Signature {
+	signatures, _, err := readSignatureFiles(paths, mLevel, sess)
+	if err != nil {
+		sess.Out.Error("Failed to load signatures: %s\n", err)
+		sess.Out.Exit(2)
+	}
+	return signatures
+}
+
+// ReadSignatureFiles will load and merge the signatures in each of the files and directories given like
+// LoadSignatureFiles, returning an error rather than exiting when one of them can not be loaded
+func ReadSignatureFiles(paths []string, mLevel int, sess *Session) ([]Signature, error) {
+	signatures, _, err := readSignatureFiles(paths, mLevel, sess)
+	return signatures, err
+}
+
+// readSignatureFiles will load and merge the signatures in each of the files and directories given, along with the
+// safe function signatures in them
+func readSignatureFiles(paths []string, mLevel int, sess *Session) ([]Signature, []SafeFunctionSignature, error) {
+	files, err := signatureFiles(paths, sess)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var merged []Signature
+	var mergedSafe []SafeFunctionSignature
+	for _, file := range files {
+		signatures, safe, leftOut, err := loadSignatureFile(file, mLevel, sess)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", file, err)
+		}
+
+		ids := map[string]bool{}
+		for _, sig := range signatures {
+			ids[sig.Signatureid()] = true
+		}
+		for _, id := range leftOut {
+			ids[id] = true
+		}
+		delete(ids, "")
+
+		var kept []Signature
+		for _, sig := range merged {
+			if !ids[sig.Signatureid()] {
+				kept = append(kept, sig)
+			}
+		}
+		if overridden := len(merged) - len(kept); overridden > 0 {
+			sess.Out.Info("Loaded %d %s from %s, overriding %d loaded before\n", len(signatures),
+				Pluralize(len(signatures), "signature", "signatures"), file, overridden)
+		} else {
+			sess.Out.Info("Loaded %d %s from %s\n", len(signatures), Pluralize(len(signatures), "signature", "signatures"), file)
+		}
+		merged = append(kept, signatures...)
+
+		for _, sig := range safe {
+			ids[sig.Signatureid()] = true
+		}
+		delete(ids, "")
+		var keptSafe []SafeFunctionSignature
+		for _, sig := range mergedSafe {
+			if !ids[sig.Signatureid()] {
+				keptSafe = append(keptSafe, sig)
+			}
+		}
+		mergedSafe = append(keptSafe, safe...)
+	}
+	return merged, mergedSafe, nil
 }
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SlackAPIURL is where messages are posted with a bot token
+const SlackAPIURL = "https://slack.com/api/chat.postMessage"
+
+// SlackRepoFindings is the most findings listed in the message about a repository, the rest are only counted
+const SlackRepoFindings = 10
+
+// HookSlack is the name slack is tracked under in the alert state
+const HookSlack = "slack"
+
+// Slack posts a summary of the findings in each repository, and a digest of the scan once it is done, to a slack
+// channel. Messages are sent to an incoming webhook or, with a bot token, to a channel through the api.
+type Slack struct {
+	WebhookURL string
+	Token      string
+	Channel    string
+	APIURL     string
+	client     *http.Client
+}
+
+// NewSlack will create a slack integration from an incoming webhook url, or from a bot token and the channel to post to
+func NewSlack(webhookURL, token, channel string) (*Slack, error) {
+	if webhookURL != "" {
+		u, err := url.Parse(webhookURL)
+		if err != nil {
+			return nil, err
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s is not an http or https url", webhookURL)
+		}
+	} else if token == "" || channel == "" {
+		return nil, fmt.Errorf("slack needs a webhook url, or a bot token and a channel")
+	}
+
+	return &Slack{
+		WebhookURL: webhookURL,
+		Token:      token,
+		Channel:    channel,
+		APIURL:     SlackAPIURL,
+		client:     NewHTTPClient(WebhookTimeout),
+	}, nil
+}
+
+// sink is the name the messages are tracked under in the alert state, the webhook or the channel they are posted to
+func (s *Slack) sink() string {
+	if s.WebhookURL != "" {
+		return AlertSink(HookSlack, s.WebhookURL)
+	}
+	return AlertSink(HookSlack, s.Channel)
+}
+
+// Send will post a message, trying again when the request fails or slack asks for it to be sent later
+func (s *Slack) Send(text string) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(text)
+		if err == nil || !retry || attempt == WebhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// post will make a single request to slack, returning whether it is worth trying again when it fails
+func (s *Slack) post(text string) (bool, error) {
+	endpoint := s.WebhookURL
+	payload := map[string]interface{}{"text": text, "unfurl_links": false}
+	if endpoint == "" {
+		endpoint = s.APIURL
+		payload["channel"] = s.Channel
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", UserAgent)
+	if s.WebhookURL == "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("slack returned %s", resp.Status)
+	}
+	// the api answers every request with a 200 and says in the body whether the message was posted
+	if s.WebhookURL == "" {
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return false, err
+		}
+		if !result.OK {
+			return result.Error == "ratelimited", fmt.Errorf("slack returned %s", result.Error)
+		}
+	}
+	return false, nil
+}
+
+// slackEscape will escape the characters slack treats as markup in the text of a message
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackSeverityCounts will list the number of findings of each severity, from the most severe down, leaving out the
+// severities with none
+func slackSeverityCounts(findings []*Finding) string {
+	counts := SeverityCounts(findings)
+	var parts []string
+	for i := len(Severities) - 1; i >= 0; i-- {
+		if n := counts[Severities[i]]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, Severities[i]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SlackRepoMessage is the message about the findings in a repository. Secrets are left out, each finding is listed by
+// its signature and where it was found.
+func SlackRepoMessage(repo *Repository, findings []*Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*: %d %s (%s)", slackEscape(*repo.FullName), len(findings), Pluralize(len(findings), "finding", "findings"), slackSeverityCounts(findings))
+	for i, f := range findings {
+		if i == SlackRepoFindings {
+			fmt.Fprintf(&b, "\n… and %d more", len(findings)-SlackRepoFindings)
+			break
+		}
+		location := f.FilePath
+		if location == "" {
+			location = f.Action
+		}
+		if f.LineNumber != "" && f.LineNumber != "0" {
+			location += ":" + f.LineNumber
+		}
+		location = slackEscape(location)
+		if f.FileUrl != "" {
+			location = "<" + f.FileUrl + "|" + location + ">"
+		} else {
+			location = "`" + location + "`"
+		}
+		fmt.Fprintf(&b, "\n• %s %s in %s", strings.ToUpper(FindingSeverity(f)), slackEscape(f.Description), location)
+	}
+	return b.String()
+}
+
+// SlackDigestMessage is the message sent once a scan is done, with how many findings it made by severity and whether
+// it failed any thresholds
+func SlackDigestMessage(s *Session) string {
+	var b strings.Builder
+	status := "finished"
+	if s.Interrupted() {
+		status = "was interrupted"
+	}
+	fmt.Fprintf(&b, "*%s %s scan %s*: %d %s", Name, s.ScanType, status, len(s.Findings), Pluralize(len(s.Findings), "finding", "findings"))
+	if counts := slackSeverityCounts(s.Findings); counts != "" {
+		fmt.Fprintf(&b, " (%s)", counts)
+	}
+	if s.Stats.RepositoriesTotal > 0 {
+		fmt.Fprintf(&b, ", %d of %d repositories scanned", s.Stats.RepositoriesScanned, s.Stats.RepositoriesTotal)
+	} else {
+		fmt.Fprintf(&b, ", %d files scanned", s.Stats.FilesScanned)
+	}
+	fmt.Fprintf(&b, "\nStarted %s, took %s", s.Stats.StartedAt.Format(time.RFC3339), s.Stats.FinishedAt.Sub(s.Stats.StartedAt).Round(time.Second))
+	if len(s.ThresholdFailures) > 0 {
+		fmt.Fprintf(&b, "\nFailed thresholds: %s", slackEscape(strings.Join(s.ThresholdFailures, ", ")))
+	}
+	return b.String()
+}
+
+// sendSlackRepoSummary will post the findings in a repository to slack if it is configured. Nothing is posted for a
+// repository without findings, and when alerts are tracked only the findings slack has not been sent are included.
+func (s *Session) sendSlackRepoSummary(repo *Repository) {
+	if s.Slack == nil {
+		return
+	}
+
+	sink := s.Slack.sink()
+	now := time.Now()
+
+	var findings []*Finding
+	s.Lock()
+	for _, f := range s.Findings {
+		if f.RepositoryOwner == *repo.Owner && f.RepositoryName == *repo.Name && s.Alerts.ShouldAlert(sink, f, now) {
+			findings = append(findings, f)
+		}
+	}
+	s.Unlock()
+	if len(findings) == 0 {
+		return
+	}
+
+	if err := s.Slack.Send(SlackRepoMessage(repo, findings)); err != nil {
+		s.Out.Error("Slack failed for %s: %s\n", *repo.FullName, err)
+		return
+	}
+	for _, f := range findings {
+		s.Alerts.Alerted(sink, f, now)
+	}
+}
+
+// SendSlackDigest will post the digest of a finished scan to slack if it is configured
+func (s *Session) SendSlackDigest() {
+	if s.Slack == nil {
+		return
+	}
+	if err := s.Slack.Send(SlackDigestMessage(s)); err != nil {
+		s.Out.Error("Slack failed for the scan digest: %s\n", err)
+	}
+}
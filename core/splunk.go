@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// SplunkEventPath is the path of the http event collector endpoint that takes json events
+const SplunkEventPath = "/services/collector/event"
+
+// SplunkSource is the source of every event wraith sends to splunk
+const SplunkSource = "wraith"
+
+// These are the suffixes added to splunk-sourcetype for the sourcetype of findings and the sourcetype of sessions
+const (
+	SplunkFindingSuffix = ":finding"
+	SplunkSessionSuffix = ":session"
+)
+
+// SplunkFinding is a finding as it is sent to splunk, with the session it was found in
+type SplunkFinding struct {
+	SessionID string `json:"session_id"`
+	ScanType  string `json:"scan_type"`
+	JSONFinding
+}
+
+// SplunkSession is the summary of a session as it is sent to splunk
+type SplunkSession struct {
+	SessionID       string `json:"session_id"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Interrupted     bool   `json:"interrupted"`
+	JSONSummary
+}
+
+// SplunkEvent is an event in the format of the http event collector, the metadata splunk indexes it with around the
+// event itself
+type SplunkEvent struct {
+	Time       int64       `json:"time"`
+	Host       string      `json:"host,omitempty"`
+	Source     string      `json:"source"`
+	Sourcetype string      `json:"sourcetype"`
+	Index      string      `json:"index,omitempty"`
+	Event      interface{} `json:"event"`
+}
+
+// Splunk sends the findings and the summary of a scan to a splunk http event collector once it is done
+type Splunk struct {
+	URL        string
+	Token      string
+	Index      string
+	Sourcetype string
+	client     *http.Client
+}
+
+// NewSplunk will create an exporter for an http event collector. The url can be the collector itself, such as
+// https://splunk.example.com:8088, or the full url of its event endpoint. Without an index the events go to the
+// default index of the token.
+func NewSplunk(endpoint, token, index, sourcetype string) (*Splunk, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", endpoint)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a token is needed to send events to the http event collector")
+	}
+	if sourcetype == "" {
+		return nil, fmt.Errorf("the sourcetype can not be empty")
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = SplunkEventPath
+	}
+
+	return &Splunk{
+		URL:        u.String(),
+		Token:      token,
+		Index:      index,
+		Sourcetype: sourcetype,
+		client:     NewHTTPClient(WebhookTimeout * 3),
+	}, nil
+}
+
+// Send will send an event to the collector, trying again when the request fails or the collector returns a 429 or
+// 5xx, which it does when its queue is full
+func (sp *Splunk) Send(body []byte) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := sp.request(body)
+		if err == nil || !retry || attempt == WebhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// request will send an event once, returning whether it is worth trying again when it fails
+func (sp *Splunk) request(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, sp.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Authorization", "Splunk "+sp.Token)
+
+	resp, err := sp.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("the http event collector returned %s: %s", resp.Status, splunkError(data))
+}
+
+// splunkError is the text of an error response of the collector, or the body itself
+func splunkError(data []byte) string {
+	var body struct {
+		Text string `json:"text"`
+		Code int    `json:"code"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Text != "" {
+		return fmt.Sprintf("%s (code %d)", body.Text, body.Code)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SendEvents will send events one request each, stopping at the first event that could not be sent. The collector may
+// have indexed part of a batch that fails, and events have no ids to leave out the ones it already has, so sending
+// them one at a time keeps a retry from adding the rest of a batch a second time.
+func (sp *Splunk) SendEvents(events []SplunkEvent) error {
+	for i, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err := sp.Send(body); err != nil {
+			unsent := len(events) - i
+			return fmt.Errorf("%d of the %d %s %s not sent, %s", unsent, len(events), Pluralize(len(events), "event", "events"),
+				Pluralize(unsent, "was", "were"), err)
+		}
+	}
+	return nil
+}
+
+// NewSplunkEvents will build the events of a finished session, an event for each finding and one for the summary of
+// the session, all timed when the scan finished
+func NewSplunkEvents(sp *Splunk, s *Session) []SplunkEvent {
+	summary := NewJSONSummary(s)
+	s.Stats.Lock()
+	timestamp := s.Stats.FinishedAt.Unix()
+	duration := int64(s.Stats.FinishedAt.Sub(s.Stats.StartedAt).Seconds())
+	s.Stats.Unlock()
+	host, _ := os.Hostname()
+
+	event := func(sourcetype string, body interface{}) SplunkEvent {
+		return SplunkEvent{
+			Time:       timestamp,
+			Host:       host,
+			Source:     SplunkSource,
+			Sourcetype: sp.Sourcetype + sourcetype,
+			Index:      sp.Index,
+			Event:      body,
+		}
+	}
+
+	var events []SplunkEvent
+	s.Lock()
+	for _, f := range s.Findings {
+		jf := NewJSONFinding(f)
+		jf.SchemaVersion = JSONSchemaVersion
+		events = append(events, event(SplunkFindingSuffix, SplunkFinding{SessionID: s.ID, ScanType: s.ScanType, JSONFinding: jf}))
+	}
+	s.Unlock()
+
+	return append(events, event(SplunkSessionSuffix, SplunkSession{
+		SessionID:       s.ID,
+		DurationSeconds: duration,
+		Interrupted:     s.Interrupted(),
+		JSONSummary:     summary,
+	}))
+}
+
+// ExportSplunk will send the findings and summary of a finished scan to splunk if it is configured
+func (s *Session) ExportSplunk() {
+	if s.Splunk == nil {
+		return
+	}
+	events := NewSplunkEvents(s.Splunk, s)
+	if err := s.Splunk.SendEvents(events); err != nil {
+		s.Out.Error("Failed to send to splunk: %s\n", err)
+		return
+	}
+	// every event but the last, which is the summary of the session, is a finding
+	findings := len(events) - 1
+	s.Out.Info("Sent %d %s to splunk\n", findings, Pluralize(findings, "finding", "findings"))
+}
@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestSplunk(t *testing.T) {
+
+	Convey("Given a splunk http event collector", t, func() {
+		var mu sync.Mutex
+		var events []string
+		var paths []string
+		var auth string
+		failures, status, response := 0, http.StatusServiceUnavailable, `{"text":"Server is busy","code":9}`
+		// the collector refuses every event after the first accept of them
+		accept := -1
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			paths = append(paths, r.Method+" "+r.URL.Path)
+			auth = r.Header.Get("Authorization")
+			if failures > 0 || len(events) == accept {
+				if failures > 0 {
+					failures--
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(response))
+				return
+			}
+			events = append(events, string(body))
+			w.Write([]byte(`{"text":"Success","code":0}`))
+		}))
+		defer server.Close()
+
+		splunk, err := core.NewSplunk(server.URL, "0d6b3b0e-5a3e-4c31-9a4f-2a8f0c1e7d11", "security", "wraith")
+		So(err, ShouldBeNil)
+
+		startedAt := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+		sess := &core.Session{
+			ID:       "0a1b2c3d",
+			Version:  "1.2.3",
+			ScanType: "github",
+			Stats:    &core.Stats{StartedAt: startedAt, FinishedAt: startedAt.Add(90 * time.Second)},
+			Out:      &core.Logger{},
+			Silent:   true,
+			Splunk:   splunk,
+		}
+		for i := 0; i < 3; i++ {
+			sess.Findings = append(sess.Findings, &core.Finding{RepositoryOwner: "acme", RepositoryName: "api",
+				FilePath: "config/prod.env", LineNumber: fmt.Sprint(i + 1), Signatureid: "aws-id", Description: "AWS Access Key ID",
+				Labels: map[string]string{"team": "payments"}})
+		}
+
+		Convey("Every finding and the session should be sent as events", func() {
+			sess.ExportSplunk()
+			So(auth, ShouldEqual, "Splunk 0d6b3b0e-5a3e-4c31-9a4f-2a8f0c1e7d11")
+			So(paths, ShouldHaveLength, 4)
+			So(paths[0], ShouldEqual, "POST "+core.SplunkEventPath)
+			So(events, ShouldHaveLength, 4)
+
+			var finding struct {
+				Time       int64                  `json:"time"`
+				Source     string                 `json:"source"`
+				Sourcetype string                 `json:"sourcetype"`
+				Index      string                 `json:"index"`
+				Event      map[string]interface{} `json:"event"`
+			}
+			So(json.Unmarshal([]byte(events[0]), &finding), ShouldBeNil)
+			So(finding.Time, ShouldEqual, startedAt.Add(90*time.Second).Unix())
+			So(finding.Source, ShouldEqual, "wraith")
+			So(finding.Sourcetype, ShouldEqual, "wraith:finding")
+			So(finding.Index, ShouldEqual, "security")
+			So(finding.Event["session_id"], ShouldEqual, "0a1b2c3d")
+			So(finding.Event["signature_id"], ShouldEqual, "aws-id")
+			So(finding.Event["labels"], ShouldResemble, map[string]interface{}{"team": "payments"})
+
+			var session struct {
+				Sourcetype string                 `json:"sourcetype"`
+				Event      map[string]interface{} `json:"event"`
+			}
+			So(json.Unmarshal([]byte(events[3]), &session), ShouldBeNil)
+			So(session.Sourcetype, ShouldEqual, "wraith:session")
+			So(session.Event["duration_seconds"], ShouldEqual, 90)
+			So(session.Event["wraith_version"], ShouldEqual, "1.2.3")
+			So(session.Event["stats"], ShouldContainKey, "findings_total")
+		})
+
+		Convey("A collector that is busy should be sent the event again, and only that event", func() {
+			failures = 1
+			So(splunk.SendEvents(core.NewSplunkEvents(splunk, sess)), ShouldBeNil)
+			So(paths, ShouldHaveLength, 5)
+			So(events, ShouldHaveLength, 4)
+		})
+
+		Convey("A token the collector refuses should fail the export without trying again", func() {
+			failures, status, response = 1, http.StatusForbidden, `{"text":"Invalid token","code":4}`
+			err := splunk.SendEvents(core.NewSplunkEvents(splunk, sess))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "4 of the 4 events were not sent, the http event collector returned 403 Forbidden: Invalid token (code 4)")
+			So(paths, ShouldHaveLength, 1)
+		})
+
+		Convey("Only the events that were not sent should be counted", func() {
+			accept, status, response = 3, http.StatusBadRequest, `{"text":"Incorrect index","code":7}`
+			err := splunk.SendEvents(core.NewSplunkEvents(splunk, sess))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "1 of the 4 events was not sent")
+			So(events, ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("Settings that can not work should be refused", t, func() {
+		_, err := core.NewSplunk("splunk:8088", "token", "", "wraith")
+		So(err, ShouldNotBeNil)
+		_, err = core.NewSplunk("https://splunk:8088", "", "", "wraith")
+		So(err, ShouldNotBeNil)
+		splunk, err := core.NewSplunk("https://splunk:8088/services/collector/event/1.0", "token", "", "wraith")
+		So(err, ShouldBeNil)
+		So(splunk.URL, ShouldEqual, "https://splunk:8088/services/collector/event/1.0")
+	})
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// SSHUser is who an ssh url is cloned as when neither the url nor the configuration names anyone
+const SSHUser = "git"
+
+// isSSHURL reports whether a git url is cloned over ssh, either as ssh://host/path or as user@host:path
+func isSSHURL(u string) bool {
+	endpoint, err := transport.NewEndpoint(u)
+	return err == nil && endpoint.Protocol == "ssh"
+}
+
+// CheckSSHConfig will read the private key and known hosts file given to clone over ssh, so a key that can not be
+// used is found before anything is cloned rather than once for every repository
+func CheckSSHConfig(key, passphrase, knownHosts string) error {
+	if key != "" {
+		if _, err := gitssh.NewPublicKeysFromFile(SSHUser, key, passphrase); err != nil {
+			return fmt.Errorf("unable to read the ssh key %s: %s", key, err)
+		}
+	}
+	if knownHosts != "" {
+		if _, err := gitssh.NewKnownHostsCallback(knownHosts); err != nil {
+			return fmt.Errorf("unable to read the known hosts file %s: %s", knownHosts, err)
+		}
+	}
+	return nil
+}
+
+// sshAuth is what a configuration clones an ssh url with, its private key when it has one or else the keys of
+// ssh-agent. The host keys are checked against the known hosts file of the configuration, or ~/.ssh/known_hosts.
+func (c *CloneConfiguration) sshAuth() transport.AuthMethod {
+	user := ""
+	if c.Username != nil {
+		user = *c.Username
+	}
+	if endpoint, err := transport.NewEndpoint(*c.Url); user == "" && err == nil {
+		user = endpoint.User
+	}
+	if user == "" {
+		user = SSHUser
+	}
+
+	var auth transport.AuthMethod
+	var hostKeys *gitssh.HostKeyCallbackHelper
+	if c.SSHKey != nil && *c.SSHKey != "" {
+		passphrase := ""
+		if c.SSHKeyPassphrase != nil {
+			passphrase = *c.SSHKeyPassphrase
+		}
+		// the key was read when it was given, so it only fails here if it has since gone
+		keys, err := gitssh.NewPublicKeysFromFile(user, *c.SSHKey, passphrase)
+		if err != nil {
+			return nil
+		}
+		auth, hostKeys = keys, &keys.HostKeyCallbackHelper
+	} else {
+		agent, err := gitssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil
+		}
+		auth, hostKeys = agent, &agent.HostKeyCallbackHelper
+	}
+
+	if c.SSHKnownHosts != nil && *c.SSHKnownHosts != "" {
+		callback, err := gitssh.NewKnownHostsCallback(*c.SSHKnownHosts)
+		if err != nil {
+			return nil
+		}
+		hostKeys.HostKeyCallback = callback
+	}
+	return auth
+}
+
+// sshClone will point a clone configuration at the ssh url of a repository when it is cloned over ssh, reporting
+// whether it is. That is when its url is an ssh one, or when clone-over-ssh is set and its provider gave an ssh url.
+// The key of the session is used unless the repository names its own, as the lines of a list can.
+func (s *Session) sshClone(repo *Repository, cloneConfig *CloneConfiguration) bool {
+	if cloneConfig.Url == nil {
+		return false
+	}
+	cloneURL := *cloneConfig.Url
+	if s.CloneOverSSH && repo.sshURL != "" {
+		// the credentials of the provider are for its https urls
+		cloneURL = repo.sshURL
+		cloneConfig.Username = nil
+		cloneConfig.Token = nil
+		cloneConfig.Bearer = false
+	}
+	if !isSSHURL(cloneURL) {
+		return false
+	}
+
+	cloneConfig.Url = &cloneURL
+	if cloneConfig.SSHKey == nil || *cloneConfig.SSHKey == "" {
+		cloneConfig.SSHKey = &s.SSHKey
+		cloneConfig.SSHKeyPassphrase = &s.SSHKeyPassphrase
+	}
+	cloneConfig.SSHKnownHosts = &s.SSHKnownHosts
+	return true
+}
+
+// CloneSSHRepository will create either an in memory clone of a repository over ssh or clone it to a temp dir
+func CloneSSHRepository(cloneConfig *CloneConfiguration) (*git.Repository, string, error) {
+	return gitClone(cloneConfig)
+}
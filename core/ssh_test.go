@@ -0,0 +1,70 @@
+package core_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wraith/core"
+)
+
+// sshKeyFile will write a new rsa private key to a file in dir, encrypted with the passphrase when there is one
+func sshKeyFile(dir, name, passphrase string) (string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return "", err
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if passphrase != "" {
+		block, err = x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte(passphrase), x509.PEMCipherAES256)
+		if err != nil {
+			return "", err
+		}
+	}
+	path := filepath.Join(dir, name)
+	return path, ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600)
+}
+
+func TestCheckSSHConfig(t *testing.T) {
+
+	Convey("Given a private key and one encrypted with a passphrase", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-ssh")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		key, err := sshKeyFile(dir, "id_rsa", "")
+		So(err, ShouldBeNil)
+		encrypted, err := sshKeyFile(dir, "id_rsa_encrypted", "hunter2")
+		So(err, ShouldBeNil)
+
+		Convey("Keys that can be read should be accepted", func() {
+			So(core.CheckSSHConfig("", "", ""), ShouldBeNil)
+			So(core.CheckSSHConfig(key, "", ""), ShouldBeNil)
+			So(core.CheckSSHConfig(encrypted, "hunter2", ""), ShouldBeNil)
+		})
+
+		Convey("A key that can not be decrypted or read should be refused", func() {
+			err := core.CheckSSHConfig(encrypted, "wrong", "")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "unable to read the ssh key "+encrypted)
+
+			err = core.CheckSSHConfig(filepath.Join(dir, "missing"), "", "")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "unable to read the ssh key "+filepath.Join(dir, "missing"))
+		})
+
+		Convey("A known hosts file should be read when it is given", func() {
+			knownHosts := filepath.Join(dir, "known_hosts")
+			So(ioutil.WriteFile(knownHosts, []byte("git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"), 0600), ShouldBeNil)
+			So(core.CheckSSHConfig(key, "", knownHosts), ShouldBeNil)
+
+			err := core.CheckSSHConfig(key, "", filepath.Join(dir, "missing_hosts"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "unable to read the known hosts file")
+		})
+	})
+}
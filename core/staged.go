@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PreCommitHookMarker is the comment that marks a pre-commit hook as written by wraith, so it can be replaced
+const PreCommitHookMarker = "# installed by wraith installHook"
+
+// runGit will run a git command in a directory and return what it printed to stdout
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// diffPath will take the path out of a ---/+++ line of a diff, which git quotes when it has unusual characters
+func diffPath(path string) string {
+	return strings.TrimPrefix(unquotePath(path), "b/")
+}
+
+// unquotePath will undo the quotes git puts around a path in a diff when it has unusual characters
+func unquotePath(path string) string {
+	path = strings.TrimSuffix(path, "\t")
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+	}
+	return path
+}
+
+// diffHeaderPath will take the path out of the diff --git line that starts each file, for files without a +++ line
+// such as binary files. Only a path that is the same on both sides and is not quoted can be told apart.
+func diffHeaderPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	n := (len(rest) - len("a/ b/")) / 2
+	if n <= 0 || !strings.HasPrefix(rest, "a/") || rest[2+n:] != " b/"+rest[2:2+n] {
+		return ""
+	}
+	return rest[2 : 2+n]
+}
+
+// ParseDiff will split the output of git diff into the files it changes. Deleted files are left out as they add
+// nothing, and files without a text diff, such as binary files, have an empty patch. A file git detected was renamed
+// keeps the path it had before, and is left out when it was renamed without a change.
+func ParseDiff(diff string) []*PullRequestFile {
+	var files []*PullRequestFile
+	var file *PullRequestFile
+	var patch strings.Builder
+	inHunks := false
+
+	flush := func() {
+		if file != nil && file.Path != "" && file.Status != "removed" {
+			file.Patch = patch.String()
+			files = append(files, file)
+		}
+		patch.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file = &PullRequestFile{Path: diffHeaderPath(line), Status: "modified"}
+			inHunks = false
+		case file == nil:
+		case inHunks || strings.HasPrefix(line, "@@"):
+			inHunks = true
+			patch.WriteString(line + "\n")
+		case strings.HasPrefix(line, "new file mode"):
+			file.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.Status = "removed"
+		case strings.HasPrefix(line, "rename from "):
+			file.PreviousPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "+++ "):
+			if path := line[4:]; path != "/dev/null" {
+				file.Path = diffPath(path)
+			}
+		}
+	}
+	flush()
+	return files
+}
+
+// setGitUser will attribute a finding that is not committed yet to the git user of a repository, who is the one that
+// is about to commit it
+func (f *Finding) setGitUser(dir string) {
+	name, _ := runGit(dir, "config", "user.name")
+	email, _ := runGit(dir, "config", "user.email")
+	f.CommitAuthor = strings.TrimSpace(name)
+	f.CommitAuthorName = strings.TrimSpace(name)
+	f.CommitAuthorEmail = strings.TrimSpace(email)
+}
+
+// StagedFiles will return the files staged for the next commit of the repository a directory is in, along with the
+// lines staging them adds
+func StagedFiles(dir string) ([]*PullRequestFile, error) {
+	return gitDiff(dir, "diff", "--cached")
+}
+
+// gitDiff will run a git command that prints a diff, such as diff or diff-tree, and return the files it adds lines to.
+// The diff is asked for in the form ParseDiff reads whatever the git config of the repository says, and with renames
+// detected so a file that is only moved adds nothing.
+func gitDiff(dir, command string, args ...string) ([]*PullRequestFile, error) {
+	diff, err := runGit(dir, append([]string{"-c", "core.quotePath=false", command, "--no-color", "--no-ext-diff",
+		"--find-renames", "--unified=0", "--diff-filter=d", "--src-prefix=a/", "--dst-prefix=b/"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(diff), nil
+}
+
+// ScanStagedChanges will scan the lines staged for the next commit of the repository a directory is in. Only what
+// the commit would add is scanned, so it is quick enough to run before every commit.
+func ScanStagedChanges(sess *Session, dir string) error {
+	top, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	top = strings.TrimSpace(top)
+
+	files, err := StagedFiles(top)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(top)
+	repo := &Repository{Name: &name, FullName: &name, CloneURL: &top, URL: &top}
+	sess.AddRepository(repo)
+	sess.Stats.Targets = 1
+	sess.SetStatus(StatusAnalyzing)
+
+	base := Finding{
+		AtHead:         true,
+		RepositoryName: name,
+	}
+	base.setGitUser(top)
+	sess.Out.Debug("[%s] Scanning %d staged %s\n", name, len(files), Pluralize(len(files), "file", "files"))
+	return scanAddedLines(sess, repo, files, base)
+}
+
+// InstallPreCommitHook will write a pre-commit hook that runs a command into the repository a directory is in and
+// return its path. A hook that wraith did not write is only replaced when force is set.
+func InstallPreCommitHook(dir, command string, force bool) (string, error) {
+	hooks, err := runGit(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooks = strings.TrimSpace(hooks)
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	path := filepath.Join(hooks, "pre-commit")
+
+	if existing, err := ioutil.ReadFile(path); err == nil && !force && !bytes.Contains(existing, []byte(PreCommitHookMarker)) {
+		return "", fmt.Errorf("%s already exists, use --force to replace it", path)
+	}
+
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		return "", err
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s\n# skip with git commit --no-verify\nexec %s\n", PreCommitHookMarker, command)
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", err
+	}
+	// WriteFile leaves the mode of a file that already exists alone
+	return path, os.Chmod(path, 0755)
+}
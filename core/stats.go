@@ -24,6 +24,9 @@ type Stats struct { // TODO alpha sort this
 	FilesTotal          int       // The total number of files that were processed
 	FilesDirty          int
 	FindingsTotal       int // The total number of findings. There can be more than one finding per file and more than one finding of the same type in a file
+	FindingsBaselined   int // The number of findings left out because they are in the baseline
+	FindingsSuppressed  int // The number of findings left out because of a suppression comment on or above their line
+	FindingsDuplicate   int // The number of findings merged into one already found of the same secret in the same place
 	Users               int // Github users
 	Targets             int // The number of dirs, people, orgs, etc on the command line or config file (what do you want wraith to enumerate on)
 	Repositories        int // This will point to Repositories Scanned
@@ -72,6 +75,27 @@ func (s *Stats) IncrementFindingsTotal() {
 	s.Findings++
 }
 
+// IncrementFindingsBaselined will bump the number of findings that were left out because they are in the baseline.
+func (s *Stats) IncrementFindingsBaselined() {
+	s.Lock()
+	defer s.Unlock()
+	s.FindingsBaselined++
+}
+
+// IncrementFindingsDuplicate will bump the number of findings that were merged into one that was already found.
+func (s *Stats) IncrementFindingsDuplicate() {
+	s.Lock()
+	defer s.Unlock()
+	s.FindingsDuplicate++
+}
+
+// IncrementFindingsSuppressed will bump the number of findings that were left out because of a suppression comment.
+func (s *Stats) IncrementFindingsSuppressed() {
+	s.Lock()
+	defer s.Unlock()
+	s.FindingsSuppressed++
+}
+
 // IncrementRepositoriesTotal will bump the total number of repositories that have been discovered.
 // This will include empty ones as well as those that had errors
 func (s *Stats) IncrementRepositoriesTotal() {
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// streamFinding will write a finding to the stream as a json line the moment it is made, when --stream is set. The
+// line is the same as one of the jsonl report, and each one is written whole even when threads make findings at once.
+func (s *Session) streamFinding(finding *Finding) {
+	if !s.Stream {
+		return
+	}
+
+	jf := NewJSONFinding(finding)
+	jf.SchemaVersion = JSONSchemaVersion
+	data, err := json.Marshal(jf)
+	if err != nil {
+		s.Out.Error("Failed to stream a finding in %s: %s\n", finding.FilePath, err)
+		return
+	}
+
+	s.streamLock.Lock()
+	defer s.streamLock.Unlock()
+	w := s.StreamWriter
+	if w == nil {
+		w = os.Stdout
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		s.Out.Error("Failed to stream a finding in %s: %s\n", finding.FilePath, err)
+	}
+}
+
+// stdout is where output meant for people is printed. It is stderr while findings are streamed to stdout, so the
+// stream can be piped into another program without anything else mixed into it.
+func (s *Session) stdout() io.Writer {
+	if s.Stream && s.StreamWriter == nil {
+		return os.Stderr
+	}
+	return os.Stdout
+}
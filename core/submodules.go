@@ -0,0 +1,185 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// SubmoduleLabel is the label a finding made in a submodule is given, naming the path of the submodule in the
+// repository that was scanned
+const SubmoduleLabel = "submodule"
+
+// DefaultSubmoduleDepth is how deep submodules are followed, only those of the repositories being scanned
+const DefaultSubmoduleDepth = 1
+
+// Submodule is a submodule declared in the .gitmodules of a commit
+type Submodule struct {
+	Name string
+	Path string
+	URL  string
+
+	// Commit is the commit of the repository of the submodule that the commit declaring it is pinned at
+	Commit plumbing.Hash
+}
+
+// GetSubmodules will read the submodules declared in the .gitmodules of a commit, in order of their path, along with
+// the commit each is pinned at. A submodule that is not in the tree of the commit, or whose path is not valid, is left
+// out.
+func GetSubmodules(commit *object.Commit) ([]Submodule, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	file, err := tree.File(".gitmodules")
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return nil, err
+	}
+
+	var submodules []Submodule
+	for _, m := range modules.Submodules {
+		if m.Validate() != nil {
+			continue
+		}
+		entry, err := tree.FindEntry(m.Path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			continue
+		}
+		submodules = append(submodules, Submodule{Name: m.Name, Path: m.Path, URL: m.URL, Commit: entry.Hash})
+	}
+	sort.Slice(submodules, func(i, j int) bool { return submodules[i].Path < submodules[j].Path })
+	return submodules, nil
+}
+
+// ResolveSubmoduleURL will resolve the url of a submodule, which git allows to be relative to the url of the
+// repository it is declared in, such as ../lib.git
+func ResolveSubmoduleURL(parent, submodule string) string {
+	if !strings.HasPrefix(submodule, "./") && !strings.HasPrefix(submodule, "../") {
+		return submodule
+	}
+	if u, err := url.Parse(parent); err == nil && u.Scheme != "" && u.Host != "" {
+		u.Path = path.Join(u.Path, submodule)
+		return u.String()
+	}
+	// an scp like url, such as git@github.com:acme/api.git
+	if i := strings.Index(parent, ":"); i > 0 && isSSHURL(parent) {
+		return parent[:i+1] + path.Join(parent[i+1:], submodule)
+	}
+	return filepath.Join(parent, submodule)
+}
+
+// submoduleProtocols are the protocols the submodules of a repository cloned from a server are cloned over
+var submoduleProtocols = map[string]bool{"http": true, "https": true, "ssh": true, "git": true}
+
+// AllowedSubmoduleURL will check whether the submodule of a repository can be cloned from its url. Like git, which
+// only allows the file protocol for submodules the user asked for, a repository cloned from a server can not have
+// wraith clone a path or file:// url on the host it runs on, only a submodule on a server. The submodules of a local
+// repository can be anywhere.
+func AllowedSubmoduleURL(parent, submodule string) bool {
+	if !remoteURL(parent) {
+		return true
+	}
+	return remoteURL(submodule)
+}
+
+// remoteURL will check whether a clone url is of a repository on a server rather than on disk
+func remoteURL(cloneURL string) bool {
+	endpoint, err := transport.NewEndpoint(cloneURL)
+	return err == nil && submoduleProtocols[endpoint.Protocol]
+}
+
+// submoduleWebURL is where the repository of a submodule can be seen, its https url without the .git, as the findings
+// in it can not be linked to in the repository that was scanned
+func submoduleWebURL(cloneURL string) string {
+	if u, err := url.Parse(cloneURL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		u.User = nil
+		u.Path = strings.TrimSuffix(u.Path, ".git")
+		return u.String()
+	}
+	if endpoint, err := transport.NewEndpoint(cloneURL); err == nil && endpoint.Protocol == "ssh" {
+		return "https://" + endpoint.Host + "/" + strings.TrimSuffix(strings.Trim(endpoint.Path, "/"), ".git")
+	}
+	return cloneURL
+}
+
+// urlHost is the host of a clone url, empty for a local path
+func urlHost(cloneURL string) string {
+	endpoint, err := transport.NewEndpoint(cloneURL)
+	if err != nil {
+		return ""
+	}
+	return endpoint.Host
+}
+
+// cloneSubmodule will clone the repository of a submodule of repo to a temp dir. The credentials repo is cloned with
+// are only sent when the submodule is on the same host, so a .gitmodules can not hand them to another server.
+func cloneSubmodule(sess *Session, repo *Repository, cloneURL string, log *Logger) (*git.Repository, string, error) {
+	cloneConfig, _, err := cloneConfiguration(sess, repo, false)
+	if err != nil {
+		return nil, "", err
+	}
+	if urlHost(cloneURL) != urlHost(*repo.CloneURL) {
+		cloneConfig.Username = nil
+		cloneConfig.Token = nil
+		cloneConfig.Bearer = false
+	}
+	cloneConfig.Url = &cloneURL
+	if isSSHURL(cloneURL) && (cloneConfig.SSHKey == nil || *cloneConfig.SSHKey == "") {
+		cloneConfig.SSHKey = &sess.SSHKey
+		cloneConfig.SSHKeyPassphrase = &sess.SSHKeyPassphrase
+		cloneConfig.SSHKnownHosts = &sess.SSHKnownHosts
+	}
+
+	var clone *git.Repository
+	var dir string
+	_, err = sess.withRetries(log, "clone "+cloneURL, func() error {
+		var err error
+		if dir, err = ioutil.TempDir("", "wraith"); err != nil {
+			return err
+		}
+		// every branch is cloned, as the commit the submodule is pinned at need not be on its default branch
+		clone, err = git.PlainClone(dir, false, &git.CloneOptions{URL: cloneURL, Auth: cloneConfig.auth(), Tags: git.NoTags})
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return clone, dir, nil
+}
+
+// setSubmodule will label a finding made in a submodule with the path of the submodule
+func (f *Finding) setSubmodule(submodule string) {
+	if submodule == "" {
+		return
+	}
+	labels := map[string]string{SubmoduleLabel: submodule}
+	for k, v := range f.Labels {
+		labels[k] = v
+	}
+	f.Labels = labels
+}
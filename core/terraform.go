@@ -0,0 +1,384 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"wraith/version"
+)
+
+// These are the signature ids of the findings made in terraform state files, by why the value was flagged
+const (
+	TerraformSensitiveID  = "terraform-state-sensitive"
+	TerraformCredentialID = "terraform-state-credential"
+	TerraformConnectionID = "terraform-state-connection-string"
+)
+
+// These are the labels a finding in a terraform state file is given, the address of the resource or output the value
+// belongs to and the attribute it is in, such as module.db.aws_db_instance.main and password
+const (
+	TerraformAddressLabel   = "terraform_address"
+	TerraformAttributeLabel = "terraform_attribute"
+)
+
+// terraformMatchLevel is the match level of the findings in state files, a value there is in use by real infrastructure
+const terraformMatchLevel = 4
+
+// terraformKinds describe each of the reasons a value of a state file is flagged
+var terraformKinds = map[string]struct {
+	description string
+	confidence  string
+}{
+	TerraformSensitiveID:  {"Value marked sensitive in Terraform state", ConfidenceHigh},
+	TerraformCredentialID: {"Credential attribute in Terraform state", ConfidenceMedium},
+	TerraformConnectionID: {"Connection string with a password in Terraform state", ConfidenceHigh},
+}
+
+// terraformCredentialName matches the names of attributes that hold credentials, and terraformNotCredentialName the
+// ones that only describe them, such as the arn of a secret or the length of a password
+var (
+	terraformCredentialName    = regexp.MustCompile(`(^|_)(password|passwd|secret|secret_key|secret_string|private_key|access_key|api_key|auth_token|token|client_secret|connection_string|primary_key|secondary_key|sas_key|shared_key|master_key)(_|$)`)
+	terraformNotCredentialName = regexp.MustCompile(`(_(arn|id|ids|name|names|version|length|policy|enabled|type|path|count|expiry|expiration|rotation|hash)$)|^(key_name|public_key)`)
+)
+
+// terraformConnectionString matches a url with a password in it, or a connection string with a password setting
+var terraformConnectionString = regexp.MustCompile(`(?i)([a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s@]+@)|((^|;)\s*(password|pwd)\s*=\s*[^;\s])`)
+
+// terraformSecretAttributes are attributes that hold secrets under names that do not say so
+var terraformSecretAttributes = map[string][]string{
+	"random_password": {"result", "bcrypt_hash"},
+}
+
+// terraformState is what is scanned of a state file. Version 4 is written by terraform 0.12 and later, the modules
+// are from version 3 and earlier.
+type terraformState struct {
+	Outputs map[string]struct {
+		Value     interface{} `json:"value"`
+		Sensitive bool        `json:"sensitive"`
+	} `json:"outputs"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey            interface{}            `json:"index_key"`
+			Attributes          map[string]interface{} `json:"attributes"`
+			SensitiveAttributes json.RawMessage        `json:"sensitive_attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+	Modules []struct {
+		Path    []string `json:"path"`
+		Outputs map[string]struct {
+			Value     interface{} `json:"value"`
+			Sensitive bool        `json:"sensitive"`
+		} `json:"outputs"`
+		Resources map[string]struct {
+			Primary struct {
+				Attributes map[string]string `json:"attributes"`
+			} `json:"primary"`
+		} `json:"resources"`
+	} `json:"modules"`
+}
+
+// terraformPathStep is a step of the path to a sensitive attribute, either the name of an attribute or an index
+type terraformPathStep struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// terraformValue is a value of a state file that is flagged, along with the address and attribute it was found at.
+// key is the name the value has in the json, which is used to find the line it is on.
+type terraformValue struct {
+	kind      string
+	address   string
+	attribute string
+	key       string
+	value     string
+}
+
+// isTerraformState will check whether a file is a terraform state file, or the backup terraform keeps of one
+func isTerraformState(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".tfstate") || strings.HasSuffix(path, ".tfstate.backup")
+}
+
+// terraformAddress is the address of a resource, such as module.db.aws_db_instance.main["a"]
+func terraformAddress(module, mode, typ, name string, indexKey interface{}) string {
+	address := typ + "." + name
+	if mode == "data" {
+		address = "data." + address
+	}
+	if module != "" {
+		address = module + "." + address
+	}
+	switch key := indexKey.(type) {
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	}
+	return address
+}
+
+// terraformSensitivePaths will read the paths of the sensitive attributes of an instance, each as the names and
+// indexes along it joined with a nul. Terraform 0.15 and later record them in the state.
+func terraformSensitivePaths(raw json.RawMessage) map[string]bool {
+	var paths [][]terraformPathStep
+	if len(raw) == 0 || json.Unmarshal(raw, &paths) != nil {
+		return nil
+	}
+	sensitive := map[string]bool{}
+	for _, path := range paths {
+		var segments []string
+		for _, step := range path {
+			var name string
+			if json.Unmarshal(step.Value, &name) == nil {
+				segments = append(segments, name)
+				continue
+			}
+			var index struct {
+				Value interface{} `json:"value"`
+			}
+			json.Unmarshal(step.Value, &index)
+			switch v := index.Value.(type) {
+			case string:
+				segments = append(segments, v)
+			case float64:
+				segments = append(segments, strconv.Itoa(int(v)))
+			}
+		}
+		sensitive[strings.Join(segments, "\x00")] = true
+	}
+	return sensitive
+}
+
+// walkTerraformValue will call visit with each string in a value of a state file, along with the attribute it is at
+// such as connection[0].password, the segments of its path, and the name of the last attribute along it
+func walkTerraformValue(value interface{}, attribute string, segments []string, name string, visit func(attribute string, segments []string, name, value string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if attribute != "" {
+				child = attribute + "." + key
+			}
+			walkTerraformValue(v[key], child, append(segments[:len(segments):len(segments)], key), key, visit)
+		}
+	case []interface{}:
+		for i, item := range v {
+			index := strconv.Itoa(i)
+			walkTerraformValue(item, attribute+"["+index+"]", append(segments[:len(segments):len(segments)], index), name, visit)
+		}
+	case string:
+		visit(attribute, segments, name, v)
+	}
+}
+
+// terraformKind will decide why a value is flagged, if it is at all. A value terraform knows is sensitive is flagged
+// whatever it is called, the rest when they are named like a credential or are connection strings with a password.
+func terraformKind(resourceType, name, value string, sensitive bool) string {
+	if value == "" {
+		return ""
+	}
+	if sensitive {
+		return TerraformSensitiveID
+	}
+	for _, attribute := range terraformSecretAttributes[resourceType] {
+		if name == attribute {
+			return TerraformSensitiveID
+		}
+	}
+	if terraformConnectionString.MatchString(value) {
+		return TerraformConnectionID
+	}
+	name = strings.ToLower(name)
+	if terraformCredentialName.MatchString(name) && !terraformNotCredentialName.MatchString(name) {
+		return TerraformCredentialID
+	}
+	return ""
+}
+
+// isSensitivePath will check whether the path of a value, or any path it is under, is one of the sensitive ones
+func isSensitivePath(sensitive map[string]bool, segments []string) bool {
+	for i := 1; i <= len(segments); i++ {
+		if sensitive[strings.Join(segments[:i], "\x00")] {
+			return true
+		}
+	}
+	return false
+}
+
+// terraformStateValues will parse a state file and return the values of its resources and outputs that are flagged
+func terraformStateValues(content string) ([]terraformValue, error) {
+	var state terraformState
+	if err := json.Unmarshal([]byte(content), &state); err != nil {
+		return nil, err
+	}
+
+	var values []terraformValue
+	outputs := func(module string, names []string, value func(string) (interface{}, bool)) {
+		sort.Strings(names)
+		for _, name := range names {
+			address := "output." + name
+			if module != "" {
+				address = module + "." + address
+			}
+			v, sensitive := value(name)
+			walkTerraformValue(v, "", nil, name, func(attribute string, segments []string, key, s string) {
+				if kind := terraformKind("", key, s, sensitive); kind != "" {
+					values = append(values, terraformValue{kind: kind, address: address, attribute: attribute, key: key, value: s})
+				}
+			})
+		}
+	}
+
+	var names []string
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	outputs("", names, func(name string) (interface{}, bool) {
+		return state.Outputs[name].Value, state.Outputs[name].Sensitive
+	})
+
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			address := terraformAddress(resource.Module, resource.Mode, resource.Type, resource.Name, instance.IndexKey)
+			sensitive := terraformSensitivePaths(instance.SensitiveAttributes)
+			walkTerraformValue(instance.Attributes, "", nil, "", func(attribute string, segments []string, key, s string) {
+				if kind := terraformKind(resource.Type, key, s, isSensitivePath(sensitive, segments)); kind != "" {
+					values = append(values, terraformValue{kind: kind, address: address, attribute: attribute, key: key, value: s})
+				}
+			})
+		}
+	}
+
+	// before terraform 0.12 the attributes were flattened into keys such as connection.0.password
+	for _, module := range state.Modules {
+		var path string
+		for _, name := range module.Path {
+			if name != "root" {
+				path += "module." + name + "."
+			}
+		}
+		path = strings.TrimSuffix(path, ".")
+
+		names = nil
+		for name := range module.Outputs {
+			names = append(names, name)
+		}
+		outputs(path, names, func(name string) (interface{}, bool) {
+			return module.Outputs[name].Value, module.Outputs[name].Sensitive
+		})
+
+		var addresses []string
+		for address := range module.Resources {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		for _, resource := range addresses {
+			address := resource
+			if path != "" {
+				address = path + "." + resource
+			}
+			attributes := module.Resources[resource].Primary.Attributes
+			var keys []string
+			for key := range attributes {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			resourceType := strings.TrimPrefix(resource, "data.")
+			resourceType = resourceType[:strings.Index(resourceType+".", ".")]
+			for _, key := range keys {
+				name := key[strings.LastIndex(key, ".")+1:]
+				if kind := terraformKind(resourceType, name, attributes[key], false); kind != "" {
+					values = append(values, terraformValue{kind: kind, address: address, attribute: key, key: key, value: attributes[key]})
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// terraformLine will find the line of a state file a value is on, looking for it along with its name first as the
+// same value can be in a state file more than once
+func terraformLine(lines []string, key, value string) int {
+	encodedKey, _ := json.Marshal(key)
+	encodedValue, _ := json.Marshal(value)
+	for _, needle := range []string{string(encodedKey) + ": " + string(encodedValue), string(encodedKey) + ":" + string(encodedValue), string(encodedValue)} {
+		for i, line := range lines {
+			if strings.Contains(line, needle) {
+				return i + 1
+			}
+		}
+	}
+	return 1
+}
+
+// scanTerraformState will parse a terraform state file and flag the values in it that are secrets, by what terraform
+// records about them and by the attributes they are in, rather than matching the signatures against the json. The
+// findings are copied from base and labelled with the address and attribute of each value. Values that are also in
+// previous, the state file before the commit being scanned, are left out as they were found in an earlier commit.
+// The findings are not added to the session, this is left to the caller so they can be handled like any other match.
+func scanTerraformState(sess *Session, content, previous string, base Finding) []*Finding {
+	// a state file that was deleted has nothing left in it
+	if content == "" {
+		return nil
+	}
+	values, err := terraformStateValues(content)
+	if err != nil {
+		sess.Out.Debug("%s is not a terraform state file that can be read: %s\n", base.FilePath, err)
+		return nil
+	}
+
+	seen := map[string]bool{}
+	if previous != "" {
+		before, _ := terraformStateValues(previous)
+		for _, v := range before {
+			seen[v.address+"\x00"+v.attribute+"\x00"+v.value] = true
+		}
+	}
+
+	var findings []*Finding
+	lines := strings.Split(content, "\n")
+	for _, v := range values {
+		if seen[v.address+"\x00"+v.attribute+"\x00"+v.value] {
+			continue
+		}
+		seen[v.address+"\x00"+v.attribute+"\x00"+v.value] = true
+		line := terraformLine(lines, v.key, v.value)
+		if isSuppressed(lines, line, sess) {
+			continue
+		}
+
+		kind := terraformKinds[v.kind]
+		finding := base
+		finding.Comment = Redact(v.value, sess.Redact)
+		finding.Description = kind.description
+		finding.LineNumber = strconv.Itoa(line)
+		finding.Score = terraformMatchLevel
+		finding.Severity = ScoreSeverity(terraformMatchLevel)
+		finding.Confidence = kind.confidence
+		finding.Signatureid = v.kind
+		finding.SignaturesVersion = sess.SignatureVersion
+		finding.WraithVersion = version.AppVersion()
+		finding.secret = v.value
+		finding.Labels = map[string]string{TerraformAddressLabel: v.address, TerraformAttributeLabel: v.attribute}
+		for label, value := range base.Labels {
+			finding.Labels[label] = value
+		}
+		finding.setContext(lines, line, sess)
+
+		finding.Initialize(sess.ScanType)
+		findings = append(findings, &finding)
+	}
+	return findings
+}
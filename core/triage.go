@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// These are the statuses a finding can be triaged with in the web interface, a finding without one is untriaged
+const (
+	TriageFalsePositive = "false_positive"
+	TriageConfirmed     = "confirmed"
+	TriageRemediated    = "remediated"
+)
+
+// TriageStatuses are the statuses a finding can be triaged with
+var TriageStatuses = []string{TriageFalsePositive, TriageConfirmed, TriageRemediated}
+
+// triageLock keeps sessions in the same process from overwriting each others triage when the file is saved
+var triageLock sync.Mutex
+
+// Triage is what someone decided about a finding after looking at it
+type Triage struct {
+	Status    string    `json:"status"`
+	Note      string    `json:"note,omitempty"`
+	User      string    `json:"user,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TriageState holds the triage of findings by their fingerprint, so a decision about a secret carries over to the
+// same secret in every commit and every later scan
+type TriageState struct {
+	sync.Mutex
+	Findings map[string]*Triage `json:"triage"`
+}
+
+// ValidTriageStatus will check if a status is one a finding can be triaged with
+func ValidTriageStatus(status string) bool {
+	for _, s := range TriageStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTriageState returns a state where nothing has been triaged
+func NewTriageState() *TriageState {
+	return &TriageState{Findings: map[string]*Triage{}}
+}
+
+// LoadTriageState will read the triage of findings from a file, a file that does not exist yet has nothing triaged
+func LoadTriageState(path string) (*TriageState, error) {
+	t := NewTriageState()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	if t.Findings == nil {
+		t.Findings = map[string]*Triage{}
+	}
+	return t, nil
+}
+
+// Get returns the triage of a finding by its fingerprint, nil when it has not been triaged or the state is nil
+func (t *TriageState) Get(fingerprint string) *Triage {
+	if t == nil {
+		return nil
+	}
+	t.Lock()
+	defer t.Unlock()
+	return t.Findings[fingerprint]
+}
+
+// Set will record the triage of a finding, the latest of two triages is kept. A triage without a status clears it.
+func (t *TriageState) Set(fingerprint string, triage *Triage) {
+	t.Lock()
+	defer t.Unlock()
+
+	if current := t.Findings[fingerprint]; current != nil && current.UpdatedAt.After(triage.UpdatedAt) {
+		return
+	}
+	if triage.Status == "" {
+		delete(t.Findings, fingerprint)
+		return
+	}
+	t.Findings[fingerprint] = triage
+}
+
+// SaveTriage will write the triage of a single finding to a file. The rest of the file is read again first so
+// sessions sharing it do not lose each others triage.
+func SaveTriage(path, fingerprint string, triage *Triage) error {
+	triageLock.Lock()
+	defer triageLock.Unlock()
+
+	current, err := LoadTriageState(path)
+	if err != nil {
+		return err
+	}
+	if triage.Status == "" {
+		delete(current.Findings, fingerprint)
+	} else {
+		current.Findings[fingerprint] = triage
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// TriageFinding will triage every finding of the session with a fingerprint and keep the decision in the triage file
+// and database, whichever have been configured. It returns how many findings of the session were triaged.
+func (s *Session) TriageFinding(fingerprint string, triage *Triage) (int, error) {
+	if triage.Status != "" && !ValidTriageStatus(triage.Status) {
+		return 0, fmt.Errorf("unknown triage status %s, it must be one of: %s", triage.Status, strings.Join(TriageStatuses, ", "))
+	}
+	if triage.UpdatedAt.IsZero() {
+		triage.UpdatedAt = time.Now().UTC()
+	}
+
+	var triaged []int
+	s.Lock()
+	for i, f := range s.Findings {
+		if f.Fingerprint != fingerprint {
+			continue
+		}
+		if triage.Status == "" {
+			f.Triage = nil
+		} else {
+			f.Triage = triage
+		}
+		triaged = append(triaged, i)
+	}
+	findings := s.Findings
+	s.Unlock()
+
+	if len(triaged) == 0 && s.TriageFile == "" && s.DB == nil {
+		return 0, nil
+	}
+
+	if s.Triage == nil {
+		s.Triage = NewTriageState()
+	}
+	s.Triage.Set(fingerprint, triage)
+
+	if s.TriageFile != "" {
+		if err := SaveTriage(s.TriageFile, fingerprint, triage); err != nil {
+			return len(triaged), fmt.Errorf("unable to save the triage to %s: %s", s.TriageFile, err)
+		}
+	}
+	if s.DB != nil {
+		if err := s.DB.SaveTriage(fingerprint, triage); err != nil {
+			return len(triaged), fmt.Errorf("unable to save the triage to %s: %s", s.DBPath, err)
+		}
+		for _, i := range triaged {
+			s.recordFinding(i, findings[i])
+		}
+	}
+	return len(triaged), nil
+}
+
+// applyTriage will set the triage a finding was given in an earlier scan, if it has one
+func (s *Session) applyTriage(f *Finding) {
+	if triage := s.Triage.Get(f.Fingerprint); triage != nil {
+		f.Triage = triage
+	}
+}
+
+// LoadTriage will read the triage of findings from the triage file and database of the session, keeping the latest
+// decision about each finding when both have one
+func (s *Session) LoadTriage() error {
+	state := NewTriageState()
+	if s.TriageFile != "" {
+		fromFile, err := LoadTriageState(s.TriageFile)
+		if err != nil {
+			return err
+		}
+		state = fromFile
+	}
+	if s.DB != nil {
+		fromDB, err := s.DB.Triage()
+		if err != nil {
+			return err
+		}
+		for fingerprint, triage := range fromDB {
+			state.Set(fingerprint, triage)
+		}
+	}
+	s.Triage = state
+	return nil
+}
@@ -0,0 +1,423 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// triageKeys are the keys of the triage command and what they do
+const triageKeys = "[f] false positive  [c] confirmed  [r] remediated  [x] clear  [n] note  [s] skip  [p] previous  [q] quit"
+
+// ctrl-c and ctrl-d are read as these bytes rather than as a signal and the end of the input while the terminal is in
+// raw mode, and quit like q does
+const (
+	triageKeyInterrupt  = 0x03
+	triageKeyEndOfInput = 0x04
+)
+
+// TerminalTriage works through the findings of a scan one at a time in a terminal, keeping each decision in the triage
+// file and database the same way the web interface does. False positives are also added to the baseline, so later
+// scans leave them out.
+type TerminalTriage struct {
+	TriageFile string
+	Baseline   string
+	DB         *Database
+	User       string
+	All        bool // show findings that have already been triaged, not only those that have not
+	In         io.Reader
+	Out        io.Writer
+	Fd         int // the terminal the keys are read from, -1 when In is not a terminal and a line is read per key
+
+	findings []JSONFinding
+	places   map[string]int // how many places each fingerprint was found in by the scan
+	state    *TriageState
+	reader   *bufio.Reader
+	raw      *terminal.State
+	note     string
+	order    []string          // the fingerprints in the order they were triaged
+	decided  map[string]string // the status each fingerprint was triaged with, empty when it was cleared
+}
+
+// LoadJSONFindings will read the findings of a json report, or of the json lines written by --jsonl or --stream
+func LoadJSONFindings(path string) ([]JSONFinding, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report map[string]json.RawMessage
+	if json.Unmarshal(data, &report) == nil && report["findings"] != nil {
+		var findings []JSONFinding
+		err := json.Unmarshal(report["findings"], &findings)
+		return findings, err
+	}
+
+	var findings []JSONFinding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var f JSONFinding
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, scanner.Err()
+}
+
+// NewTerminalTriage will set up the triage of findings. A triage belongs to a fingerprint, so each fingerprint is shown
+// once however many commits and branches it was found in, and the most severe findings come first.
+func NewTerminalTriage(findings []JSONFinding) *TerminalTriage {
+	t := &TerminalTriage{Fd: -1, places: map[string]int{}, decided: map[string]string{}}
+	for _, f := range findings {
+		if f.Fingerprint == "" {
+			continue
+		}
+		if t.places[f.Fingerprint] == 0 {
+			t.findings = append(t.findings, f)
+		}
+		// a finding deduplicated in the scan stands for every place it was found
+		if f.Occurrences > 1 {
+			t.places[f.Fingerprint] += f.Occurrences
+		} else {
+			t.places[f.Fingerprint]++
+		}
+	}
+	sort.SliceStable(t.findings, func(a, b int) bool {
+		return severityRanks[t.findings[a].Severity] > severityRanks[t.findings[b].Severity]
+	})
+	return t
+}
+
+// Run will show the findings until every one has been shown or the user quits, then add the false positives to the
+// baseline and print what was triaged
+func (t *TerminalTriage) Run() error {
+	if err := t.loadState(); err != nil {
+		return err
+	}
+
+	var queue []JSONFinding
+	for _, f := range t.findings {
+		if t.All || t.state.Get(f.Fingerprint) == nil {
+			queue = append(queue, f)
+		}
+	}
+	if len(queue) == 0 {
+		fmt.Fprintf(t.Out, "Nothing left to triage, %d findings have been triaged already\n", len(t.findings))
+		return nil
+	}
+
+	t.reader = bufio.NewReader(t.In)
+	if t.Fd >= 0 && terminal.IsTerminal(t.Fd) {
+		raw, err := terminal.MakeRaw(t.Fd)
+		if err != nil {
+			return err
+		}
+		t.raw = raw
+		defer terminal.Restore(t.Fd, raw)
+	}
+
+	for i := 0; i < len(queue); {
+		f := queue[i]
+		t.show(f, i, len(queue))
+		key, err := t.readKey()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case 'f', 'c', 'r', 'x':
+			status := map[rune]string{'f': TriageFalsePositive, 'c': TriageConfirmed, 'r': TriageRemediated}[key]
+			if err := t.decide(f, status); err != nil {
+				return err
+			}
+			i++
+		case 'n':
+			if t.note, err = t.readLine("Note: "); err != nil {
+				return err
+			}
+		case 's', 'j', ' ', '\r', '\n':
+			i++
+		case 'p', 'k':
+			if i > 0 {
+				i--
+			}
+		case 'q', triageKeyInterrupt, triageKeyEndOfInput:
+			i = len(queue)
+		default:
+			t.printf("%s\n", triageKeys)
+		}
+	}
+
+	if t.raw != nil {
+		terminal.Restore(t.Fd, t.raw)
+		t.raw = nil
+	}
+	added, err := t.writeBaseline()
+	if err != nil {
+		return fmt.Errorf("unable to add the false positives to %s: %s", t.Baseline, err)
+	}
+	t.summary(added)
+	return nil
+}
+
+// loadState will read how findings have been triaged from the triage file and database, keeping the latest decision
+// about each finding when both have one
+func (t *TerminalTriage) loadState() error {
+	t.state = NewTriageState()
+	if t.TriageFile != "" {
+		fromFile, err := LoadTriageState(t.TriageFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the triage in %s: %s", t.TriageFile, err)
+		}
+		t.state = fromFile
+	}
+	if t.DB != nil {
+		fromDB, err := t.DB.Triage()
+		if err != nil {
+			return fmt.Errorf("unable to read the triage in the database: %s", err)
+		}
+		for fingerprint, triage := range fromDB {
+			t.state.Set(fingerprint, triage)
+		}
+	}
+	return nil
+}
+
+// show will print a finding with the lines around it and how it has been triaged
+func (t *TerminalTriage) show(f JSONFinding, i, total int) {
+	t.printf("\n── %d of %d %s\n", i+1, total, strings.Repeat("─", 40))
+	t.printf("%s  %s (%s)", f.Severity, f.Description, f.SignatureID)
+	if f.Verification != "" {
+		t.printf("  %s", f.Verification)
+	}
+	t.printf("\n")
+
+	location := f.FilePath
+	if f.LineNumber != "" && f.LineNumber != "0" {
+		location += ":" + f.LineNumber
+	}
+	if f.RepositoryOwner != "" || f.RepositoryName != "" {
+		location = f.RepositoryOwner + "/" + f.RepositoryName + "  " + location
+	}
+	t.printf("%s", location)
+	if places := t.places[f.Fingerprint]; places > 1 {
+		t.printf("  found in %d places", places)
+	}
+	t.printf("\n")
+
+	if f.CommitHash != "" {
+		hash := f.CommitHash
+		if len(hash) > 8 {
+			hash = hash[:8]
+		}
+		t.printf("commit %s by %s %s\n", hash, f.CommitAuthor, f.CommitDate)
+	}
+	if f.FileURL != "" {
+		t.printf("%s\n", f.FileURL)
+	}
+
+	if len(f.Context) > 0 {
+		t.printf("\n")
+		for _, l := range f.Context {
+			marker := " "
+			if fmt.Sprint(l.LineNumber) == f.LineNumber {
+				marker = ">"
+			}
+			t.printf("%s %5d | %s\n", marker, l.LineNumber, strings.TrimRight(l.Content, "\r\n"))
+		}
+	}
+	t.printf("\nSecret: %s\n", f.Comment)
+	if solution := f.Remediation.Solution(); solution != "" {
+		t.printf("Remediation: %s\n", solution)
+	}
+
+	if triage := t.state.Get(f.Fingerprint); triage != nil {
+		t.printf("Triaged %s", triage.Status)
+		if triage.User != "" {
+			t.printf(" by %s", triage.User)
+		}
+		if triage.Note != "" {
+			t.printf(": %s", triage.Note)
+		}
+		t.printf("\n")
+	}
+	if t.note != "" {
+		t.printf("Note: %s\n", t.note)
+	}
+	t.printf("%s ", triageKeys)
+}
+
+// decide will triage a finding and keep the decision in the triage file and database, a status of "" clears it
+func (t *TerminalTriage) decide(f JSONFinding, status string) error {
+	triage := &Triage{Status: status, User: t.User, UpdatedAt: time.Now().UTC()}
+	if status != "" {
+		triage.Note = t.note
+	}
+	t.note = ""
+	t.state.Set(f.Fingerprint, triage)
+
+	if t.TriageFile != "" {
+		if err := SaveTriage(t.TriageFile, f.Fingerprint, triage); err != nil {
+			return fmt.Errorf("unable to save the triage to %s: %s", t.TriageFile, err)
+		}
+	}
+	if t.DB != nil {
+		if err := t.DB.SaveTriage(f.Fingerprint, triage); err != nil {
+			return fmt.Errorf("unable to save the triage to the database: %s", err)
+		}
+	}
+	if _, ok := t.decided[f.Fingerprint]; !ok {
+		t.order = append(t.order, f.Fingerprint)
+	}
+	t.decided[f.Fingerprint] = status
+	t.printf("\n")
+	return nil
+}
+
+// writeBaseline will add the fingerprints of the findings triaged as false positives to the baseline, each after a
+// comment saying what it is, leaving out those already in it. A baseline whose last line was not ended has it ended
+// first, so the first comment is not joined to it. It returns how many were added.
+func (t *TerminalTriage) writeBaseline() (int, error) {
+	if t.Baseline == "" {
+		return 0, nil
+	}
+	existing := &Baseline{fingerprints: map[string]bool{}}
+	unended := false
+	if data, err := ioutil.ReadFile(t.Baseline); err == nil {
+		if existing, err = LoadBaseline(t.Baseline); err != nil {
+			return 0, err
+		}
+		unended = len(data) > 0 && data[len(data)-1] != '\n'
+	} else if !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	byFingerprint := map[string]JSONFinding{}
+	for _, f := range t.findings {
+		byFingerprint[f.Fingerprint] = f
+	}
+	var lines []string
+	for _, fingerprint := range t.order {
+		if t.decided[fingerprint] != TriageFalsePositive || existing.fingerprints[fingerprint] {
+			continue
+		}
+		f := byFingerprint[fingerprint]
+		comment := fmt.Sprintf("# %s in %s/%s %s", f.SignatureID, f.RepositoryOwner, f.RepositoryName, f.FilePath)
+		if note := t.state.Get(fingerprint); note != nil && note.Note != "" {
+			comment += ": " + note.Note
+		}
+		lines = append(lines, strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return ' '
+			}
+			return r
+		}, comment), fingerprint)
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	file, err := os.OpenFile(t.Baseline, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	if unended {
+		lines[0] = "\n" + lines[0]
+	}
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		file.Close()
+		return 0, err
+	}
+	return len(lines) / 2, file.Close()
+}
+
+// summary will print how many findings were triaged with each status
+func (t *TerminalTriage) summary(baselined int) {
+	counts := map[string]int{}
+	for _, status := range t.decided {
+		counts[status]++
+	}
+	var parts []string
+	for _, status := range TriageStatuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], strings.Replace(status, "_", " ", -1)))
+		}
+	}
+	if counts[""] > 0 {
+		parts = append(parts, fmt.Sprintf("%d cleared", counts[""]))
+	}
+	if len(parts) == 0 {
+		fmt.Fprintf(t.Out, "\nNothing was triaged\n")
+		return
+	}
+	fmt.Fprintf(t.Out, "\nTriaged %d findings: %s\n", len(t.decided), strings.Join(parts, ", "))
+	if baselined > 0 {
+		fmt.Fprintf(t.Out, "Added %d false positives to %s\n", baselined, t.Baseline)
+	}
+}
+
+// readKey will read the key pressed in a terminal, or the first letter of a line when the input is not a terminal.
+// The end of the input, ctrl-c and ctrl-d quit.
+func (t *TerminalTriage) readKey() (rune, error) {
+	if t.raw != nil {
+		b, err := t.reader.ReadByte()
+		if err == io.EOF {
+			return 'q', nil
+		}
+		return unicode.ToLower(rune(b)), err
+	}
+
+	line, err := t.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return 'q', nil
+	}
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return '\n', nil
+	}
+	return unicode.ToLower([]rune(line)[0]), nil
+}
+
+// readLine will read a line of text, taking the terminal out of raw mode while it is typed
+func (t *TerminalTriage) readLine(prompt string) (string, error) {
+	if t.raw != nil {
+		terminal.Restore(t.Fd, t.raw)
+		defer func() {
+			t.raw, _ = terminal.MakeRaw(t.Fd)
+		}()
+	}
+	fmt.Fprintf(t.Out, "\n%s", prompt)
+	line, err := t.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// printf will write to the output, with the carriage returns a terminal in raw mode needs to start a new line
+func (t *TerminalTriage) printf(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	if t.raw != nil {
+		s = strings.Replace(s, "\n", "\r\n", -1)
+	}
+	io.WriteString(t.Out, s)
+}
@@ -0,0 +1,153 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+func TestTriage(t *testing.T) {
+
+	Convey("Given a session with the same secret in two commits and another in a third", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-triage")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		sess := &core.Session{
+			Stats:      &core.Stats{},
+			TriageFile: filepath.Join(dir, "triage.json"),
+			Findings: []*core.Finding{
+				{Fingerprint: "aaa", CommitHash: "1"},
+				{Fingerprint: "aaa", CommitHash: "2"},
+				{Fingerprint: "bbb", CommitHash: "3"},
+			},
+		}
+
+		Convey("Triaging a finding should triage every commit of it and be kept in the triage file", func() {
+			n, err := sess.TriageFinding("aaa", &core.Triage{Status: core.TriageFalsePositive, Note: "a sample key"})
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 2)
+			So(sess.Findings[0].Triage.Status, ShouldEqual, core.TriageFalsePositive)
+			So(sess.Findings[1].Triage.Note, ShouldEqual, "a sample key")
+			So(sess.Findings[2].Triage, ShouldBeNil)
+
+			state, err := core.LoadTriageState(sess.TriageFile)
+			So(err, ShouldBeNil)
+			So(state.Get("aaa").Status, ShouldEqual, core.TriageFalsePositive)
+			So(state.Get("bbb"), ShouldBeNil)
+
+			Convey("and clearing it should take it out of both", func() {
+				_, err := sess.TriageFinding("aaa", &core.Triage{})
+				So(err, ShouldBeNil)
+				So(sess.Findings[0].Triage, ShouldBeNil)
+
+				state, err := core.LoadTriageState(sess.TriageFile)
+				So(err, ShouldBeNil)
+				So(state.Findings, ShouldBeEmpty)
+			})
+		})
+
+		Convey("Another session sharing the triage file should not lose what was triaged before it saved", func() {
+			So(core.SaveTriage(sess.TriageFile, "ccc", &core.Triage{Status: core.TriageConfirmed}), ShouldBeNil)
+			_, err := sess.TriageFinding("bbb", &core.Triage{Status: core.TriageRemediated})
+			So(err, ShouldBeNil)
+
+			state, err := core.LoadTriageState(sess.TriageFile)
+			So(err, ShouldBeNil)
+			So(state.Findings, ShouldHaveLength, 2)
+		})
+
+		Convey("An unknown status should be refused", func() {
+			_, err := sess.TriageFinding("aaa", &core.Triage{Status: "wontfix"})
+			So(err, ShouldNotBeNil)
+			So(sess.Findings[0].Triage, ShouldBeNil)
+		})
+
+		Convey("The triage should be kept in the database and the latest of the two kept when loading", func() {
+			db, err := core.OpenDatabase(filepath.Join(dir, "wraith.db"))
+			So(err, ShouldBeNil)
+			sess.DB = db
+			sess.ScanType = "localPath"
+			sess.DBSessionID, err = db.StartSession(sess)
+			So(err, ShouldBeNil)
+
+			earlier := time.Date(2020, 8, 3, 9, 0, 0, 0, time.UTC)
+			So(core.SaveTriage(sess.TriageFile, "aaa", &core.Triage{Status: core.TriageConfirmed, UpdatedAt: earlier}), ShouldBeNil)
+			_, err = sess.TriageFinding("aaa", &core.Triage{Status: core.TriageRemediated, User: "jane", UpdatedAt: earlier.Add(time.Hour)})
+			So(err, ShouldBeNil)
+			So(core.SaveTriage(sess.TriageFile, "aaa", &core.Triage{Status: core.TriageConfirmed, UpdatedAt: earlier}), ShouldBeNil)
+
+			stored, err := db.Triage()
+			So(err, ShouldBeNil)
+			So(stored["aaa"].User, ShouldEqual, "jane")
+			findings, err := db.Findings(sess.DBSessionID)
+			So(err, ShouldBeNil)
+			So(findings[1].Triage.Status, ShouldEqual, core.TriageRemediated)
+
+			So(sess.LoadTriage(), ShouldBeNil)
+			So(sess.Triage.Get("aaa").Status, ShouldEqual, core.TriageRemediated)
+		})
+	})
+
+	Convey("Given the web interface of a session behind basic auth", t, func() {
+		auth, err := core.NewWebAuth(core.WebAuthConfig{Username: "jane", Password: "secret"})
+		So(err, ShouldBeNil)
+		sess := &core.Session{
+			Stats:   &core.Stats{},
+			WebAuth: auth,
+			Findings: []*core.Finding{
+				{Fingerprint: "aaa", FilePath: "a.env"},
+				{Fingerprint: "bbb", FilePath: "b.env"},
+			},
+		}
+		router := core.NewRouter(sess)
+
+		request := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.SetBasicAuth("jane", "secret")
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+		paths := func(w *httptest.ResponseRecorder) []string {
+			var findings []core.Finding
+			So(json.Unmarshal(w.Body.Bytes(), &findings), ShouldBeNil)
+			var paths []string
+			for _, f := range findings {
+				paths = append(paths, f.FilePath)
+			}
+			return paths
+		}
+
+		Convey("A finding should be triaged as the user that is signed in", func() {
+			w := request(http.MethodPost, "/findings/aaa/triage", "application/json", `{"status": "confirmed", "note": "rotate it"}`)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(sess.Findings[0].Triage.User, ShouldEqual, "jane")
+			So(sess.Findings[0].Triage.Note, ShouldEqual, "rotate it")
+
+			Convey("and the findings should be filtered by their triage", func() {
+				So(paths(request(http.MethodGet, "/findings?triage=untriaged", "", "")), ShouldResemble, []string{"b.env"})
+				So(paths(request(http.MethodGet, "/findings?triage=confirmed", "", "")), ShouldResemble, []string{"a.env"})
+				So(paths(request(http.MethodGet, "/findings", "", "")), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("A triage that is not json, has an unknown status or is for no finding should be refused", func() {
+			So(request(http.MethodPost, "/findings/aaa/triage", "application/x-www-form-urlencoded", "status=confirmed").Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			So(request(http.MethodPost, "/findings/aaa/triage", "application/json", `{"status": "wontfix"}`).Code, ShouldEqual, http.StatusBadRequest)
+			So(request(http.MethodPost, "/findings/ccc/triage", "application/json", `{"status": "confirmed"}`).Code, ShouldEqual, http.StatusNotFound)
+			So(sess.Findings[0].Triage, ShouldBeNil)
+		})
+	})
+}
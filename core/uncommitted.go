@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// These are the actions of findings in changes that have not been committed
+const (
+	ActionWorkingTree = "Working Tree"
+	ActionStash       = "Stash"
+)
+
+// WorkingTreeFiles will return the files of a checkout that differ from its HEAD, staged or not, along with the files
+// git does not track and does not ignore. Untracked files that are binary or too large to scan are left out.
+func WorkingTreeFiles(dir string, sess *Session) ([]*PullRequestFile, error) {
+	files, err := gitDiff(dir, "diff", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := runGit(dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		fullFilePath := filepath.Join(dir, filepath.FromSlash(path))
+		if IsMaxFileSize(fullFilePath, sess) {
+			sess.Out.Debug("%s is too large and being ignored\n", path)
+			continue
+		}
+		content, err := ioutil.ReadFile(fullFilePath)
+		if err != nil || bytes.IndexByte(content, 0) >= 0 {
+			continue
+		}
+		files = append(files, &PullRequestFile{Path: path, Status: "added", Patch: addedPatch(string(content))})
+	}
+	return files, nil
+}
+
+// addedPatch will write the hunk of a diff that adds a whole file, so an untracked file can be scanned like a diff
+func addedPatch(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var patch strings.Builder
+	fmt.Fprintf(&patch, "@@ -0,0 +1,%d @@\n", len(lines))
+	for _, line := range lines {
+		patch.WriteString("+" + strings.TrimSuffix(line, "\n") + "\n")
+	}
+	return patch.String()
+}
+
+// Stash is an entry of git stash
+type Stash struct {
+	Hash     string
+	Name     string
+	Message  string
+	HasFiles bool
+}
+
+// Stashes will return the entries of git stash in a checkout, newest first
+func Stashes(dir string) ([]Stash, error) {
+	out, err := runGit(dir, "stash", "list", "--format=%H%x00%gd%x00%gs%x00%P")
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		// a stash made with --include-untracked has the untracked files as its third parent
+		stashes = append(stashes, Stash{
+			Hash:     fields[0],
+			Name:     fields[1],
+			Message:  fields[2],
+			HasFiles: len(strings.Fields(fields[3])) == 3,
+		})
+	}
+	return stashes, nil
+}
+
+// StashFiles will return the files a stash changes from the commit it was made on, including the untracked files it
+// holds
+func StashFiles(dir string, stash Stash) ([]*PullRequestFile, error) {
+	files, err := gitDiff(dir, "diff", stash.Hash+"^1", stash.Hash)
+	if err != nil || !stash.HasFiles {
+		return files, err
+	}
+	untracked, err := gitDiff(dir, "diff-tree", "-r", "-p", "--root", "--no-commit-id", stash.Hash+"^3")
+	if err != nil {
+		return nil, err
+	}
+	return append(files, untracked...), nil
+}
+
+// scanUncommitted will scan what a local repository has that its history does not, the changes in its working tree
+// and the entries of its stash, as the session asks. Secrets are often only ever in a developer's own checkout.
+func scanUncommitted(sess *Session, repo *Repository, tid int) {
+	dir := *repo.CloneURL
+	base := Finding{
+		RepositoryName:  *repo.Name,
+		RepositoryOwner: *repo.Owner,
+	}
+
+	if sess.ScanWorkingTree {
+		files, err := WorkingTreeFiles(dir, sess)
+		if err != nil {
+			sess.Out.Error("[THREAD #%d][%s] Unable to read the working tree: %s\n", tid, dir, err)
+		} else {
+			tree := base
+			tree.Action = ActionWorkingTree
+			tree.AtHead = true
+			tree.setGitUser(dir)
+			sess.Out.Debug("[THREAD #%d][%s] Scanning %d uncommitted %s\n", tid, dir, len(files), Pluralize(len(files), "file", "files"))
+			if err := scanAddedLines(sess, repo, files, tree); err != nil {
+				sess.Out.Error("[THREAD #%d][%s] Unable to scan the working tree: %s\n", tid, dir, err)
+			}
+		}
+	}
+
+	if !sess.ScanStash {
+		return
+	}
+	stashes, err := Stashes(dir)
+	if err != nil {
+		sess.Out.Error("[THREAD #%d][%s] Unable to list the stash: %s\n", tid, dir, err)
+		return
+	}
+	for _, stash := range stashes {
+		files, err := StashFiles(dir, stash)
+		if err != nil {
+			sess.Out.Error("[THREAD #%d][%s] Unable to read %s: %s\n", tid, dir, stash.Name, err)
+			continue
+		}
+		entry := base
+		entry.Action = ActionStash
+		entry.CommitHash = stash.Hash
+		entry.CommitMessage = stash.Name + ": " + stash.Message
+		sess.Out.Debug("[THREAD #%d][%s] Scanning %d %s in %s\n", tid, dir, len(files), Pluralize(len(files), "file", "files"), stash.Name)
+		if err := scanAddedLines(sess, repo, files, entry); err != nil {
+			sess.Out.Error("[THREAD #%d][%s] Unable to scan %s: %s\n", tid, dir, stash.Name, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are the defaults used by the update command. A mirror must serve a release document in the same format as
+// the github releases api, along with the binaries, checksums and signature listed in it.
+const (
+	DefaultReleaseURL     = "https://api.github.com/repos/N0MoreSecr3ts/wraith/releases/latest"
+	ReleaseChecksumsAsset = "checksums.txt"
+	ReleaseSignatureAsset = "checksums.txt.sig"
+	ReleaseHTTPTimeout    = 5 * time.Minute
+
+	// ReleaseVersionPrefix starts the line of the checksums file that names the version they are for, which sha256sum
+	// skips as a comment
+	ReleaseVersionPrefix = "# version "
+)
+
+// ReleasePublicKey is the base64 encoded ed25519 key that release checksums are signed with. It is set when a release
+// is built with -ldflags "-X wraith/core.ReleasePublicKey=..." and can be overridden with --public-key.
+var ReleasePublicKey = ""
+
+// ReleaseAsset is a single file that is attached to a release
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is the subset of a github release that is needed to update wraith
+type Release struct {
+	Assets  []ReleaseAsset `json:"assets"`
+	HTMLURL string         `json:"html_url"`
+	TagName string         `json:"tag_name"`
+}
+
+// ReleaseBinaryName returns the name of the release asset built for the given platform
+func ReleaseBinaryName(goos, goarch string) string {
+	name := fmt.Sprintf("wraith-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Asset will return the download url of the named asset
+func (r *Release) Asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// Version returns the release tag without the leading v
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// fetchRelease will download a url into memory
+func fetchRelease(url string) ([]byte, error) {
+	client := NewHTTPClient(ReleaseHTTPTimeout)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// FetchLatestRelease will get the release document from the github api or an internal mirror
+func FetchLatestRelease(url string) (*Release, error) {
+	data, err := fetchRelease(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("unable to parse release from %s: %s", url, err)
+	}
+	if r.TagName == "" {
+		return nil, fmt.Errorf("release from %s does not have a tag", url)
+	}
+	return &r, nil
+}
+
+// CompareVersions will compare two dotted versions, returning -1, 0 or 1. Anything after a - or + is ignored.
+func CompareVersions(a, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var parts []int
+		for _, p := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(p)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// VerifyReleaseChecksums will check the signature of the checksums file against the base64 encoded public key
+func VerifyReleaseChecksums(checksums, signature []byte, publicKey string) error {
+	return verifyEd25519(checksums, signature, publicKey, "release", "release checksums")
+}
+
+// VerifyReleaseVersion will check that signed checksums are those of the given version, so the signed checksums of an
+// older release can not be served as a newer one to roll a binary back to a version with known flaws
+func VerifyReleaseVersion(checksums []byte, version string) error {
+	version = strings.TrimPrefix(version, "v")
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, ReleaseVersionPrefix) {
+			continue
+		}
+		signed := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(line, ReleaseVersionPrefix)), "v")
+		if signed != version {
+			return fmt.Errorf("the signed checksums are for v%s, not v%s", signed, version)
+		}
+		return nil
+	}
+	return errors.New("the signed checksums do not name the version they are for")
+}
+
+// VerifyReleaseBinary will check the binary against its entry in a sha256sum formatted checksums file
+func VerifyReleaseBinary(binary, checksums []byte, name string) error {
+	return verifyChecksum(binary, checksums, name, "release")
+}
+
+// verifyEd25519 will check a signature of data against a base64 encoded public key. What the key is for and what was
+// signed are named in the errors.
+func verifyEd25519(data, signature []byte, publicKey, keyName, signedName string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("the %s public key is not a valid base64 encoded ed25519 key", keyName)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("unable to decode the %s signature: %s", keyName, err)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+		return fmt.Errorf("the %s do not match their signature", signedName)
+	}
+	return nil
+}
+
+// verifyChecksum will check data against the entry of its name in a sha256sum formatted checksums file
+func verifyChecksum(data, checksums []byte, name, listName string) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(fields[0]) {
+			return fmt.Errorf("the checksum of %s does not match the signed checksums", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not listed in the %s checksums", name, listName)
+}
+
+// DownloadRelease will download the binary for the running platform and verify it against the signed checksums of the
+// version of the release
+func DownloadRelease(r *Release, publicKey string) ([]byte, error) {
+	if publicKey == "" {
+		return nil, errors.New("no release public key is available, one must be given to verify the download")
+	}
+
+	name := ReleaseBinaryName(runtime.GOOS, runtime.GOARCH)
+	assets := map[string][]byte{}
+	for _, n := range []string{ReleaseChecksumsAsset, ReleaseSignatureAsset, name} {
+		url, ok := r.Asset(n)
+		if !ok {
+			return nil, fmt.Errorf("release %s does not contain %s", r.TagName, n)
+		}
+		data, err := fetchRelease(url)
+		if err != nil {
+			return nil, err
+		}
+		assets[n] = data
+	}
+
+	if err := VerifyReleaseChecksums(assets[ReleaseChecksumsAsset], assets[ReleaseSignatureAsset], publicKey); err != nil {
+		return nil, err
+	}
+	if err := VerifyReleaseVersion(assets[ReleaseChecksumsAsset], r.Version()); err != nil {
+		return nil, err
+	}
+	if err := VerifyReleaseBinary(assets[name], assets[ReleaseChecksumsAsset], name); err != nil {
+		return nil, err
+	}
+	return assets[name], nil
+}
+
+// ReplaceExecutable will atomically swap the running binary for the new one. The new binary is written next to the
+// old one so the final rename never crosses a filesystem.
+func ReplaceExecutable(binary []byte) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(exe)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(exe), ".wraith-update-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return "", err
+	}
+
+	// a running executable can not be replaced on windows, but it can be moved out of the way
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return "", err
+	}
+	return exe, nil
+}
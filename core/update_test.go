@@ -0,0 +1,81 @@
+package core_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"wraith/core"
+)
+
+func TestCompareVersions(t *testing.T) {
+
+	Convey("Given two versions", t, func() {
+
+		Convey("A newer patch, minor or major should compare greater", func() {
+			So(core.CompareVersions("0.0.2", "0.0.1"), ShouldEqual, 1)
+			So(core.CompareVersions("v0.1.0", "0.0.9"), ShouldEqual, 1)
+			So(core.CompareVersions("1.0", "0.9.9"), ShouldEqual, 1)
+		})
+
+		Convey("Equal versions should compare equal regardless of prefix or suffix", func() {
+			So(core.CompareVersions("v0.0.4", "0.0.4"), ShouldEqual, 0)
+			So(core.CompareVersions("0.0.4-rc1", "0.0.4"), ShouldEqual, 0)
+		})
+
+		Convey("An older version should compare less", func() {
+			So(core.CompareVersions("0.0.3", "0.0.10"), ShouldEqual, -1)
+		})
+	})
+}
+
+func TestVerifyRelease(t *testing.T) {
+
+	Convey("Given a signed release", t, func() {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		So(err, ShouldBeNil)
+		publicKey := base64.StdEncoding.EncodeToString(pub)
+
+		binary := []byte("a new wraith binary")
+		checksums := []byte(fmt.Sprintf("# version 0.0.5\n%x  wraith-linux-amd64\n", sha256.Sum256(binary)))
+		signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, checksums)))
+
+		Convey("The checksums should verify against the public key", func() {
+			So(core.VerifyReleaseChecksums(checksums, signature, publicKey), ShouldBeNil)
+		})
+
+		Convey("The checksums should not verify against another key", func() {
+			other, _, _ := ed25519.GenerateKey(rand.Reader)
+			So(core.VerifyReleaseChecksums(checksums, signature, base64.StdEncoding.EncodeToString(other)), ShouldNotBeNil)
+		})
+
+		Convey("Tampered checksums should not verify", func() {
+			tampered := append([]byte("0"), checksums[1:]...)
+			So(core.VerifyReleaseChecksums(tampered, signature, publicKey), ShouldNotBeNil)
+		})
+
+		Convey("The checksums should be those of the release", func() {
+			So(core.VerifyReleaseVersion(checksums, "v0.0.5"), ShouldBeNil)
+			So(core.VerifyReleaseVersion(checksums, "0.0.6"), ShouldNotBeNil)
+		})
+
+		Convey("Checksums that do not name their version should be rejected", func() {
+			So(core.VerifyReleaseVersion(checksums[len("# version 0.0.5\n"):], "0.0.5"), ShouldNotBeNil)
+		})
+
+		Convey("The binary should match its checksum", func() {
+			So(core.VerifyReleaseBinary(binary, checksums, "wraith-linux-amd64"), ShouldBeNil)
+		})
+
+		Convey("A modified binary should not match its checksum", func() {
+			So(core.VerifyReleaseBinary([]byte("evil"), checksums, "wraith-linux-amd64"), ShouldNotBeNil)
+		})
+
+		Convey("A binary that is not listed should be rejected", func() {
+			So(core.VerifyReleaseBinary(binary, checksums, "wraith-darwin-amd64"), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// These are the results of verifying a secret against the service it belongs to. A finding that was not checked,
+// because verification is off or there is no way to check its kind of secret, has an empty verification.
+const (
+	VerificationVerified = "verified" // the service accepted the secret
+	VerificationInactive = "inactive" // the service rejected the secret, it has been revoked or has expired
+	VerificationUnknown  = "unknown"  // the secret could not be checked, such as when the service could not be reached
+)
+
+// VerifyTimeout is the longest a single verification request is allowed to take
+const VerifyTimeout = 10 * time.Second
+
+// These are the calls made to check a secret. Each only reads who the secret belongs to and changes nothing.
+const (
+	VerifyAWSURL    = "https://sts.amazonaws.com/"
+	VerifyGithubURL = "https://api.github.com/user"
+	VerifySlackURL  = "https://slack.com/api/auth.test"
+	VerifyStripeURL = "https://api.stripe.com/v1/balance"
+)
+
+// These find the part of a match that is the secret for each kind of secret that can be verified
+var (
+	awsKeyIDPattern     = regexp.MustCompile(`\b(AKIA[0-9A-Z]{16})\b`)
+	awsSecretKeyPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9/+])([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`)
+	githubTokenPattern  = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,251})\b`)
+	slackTokenPattern   = regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`)
+	stripeKeyPattern    = regexp.MustCompile(`\b((?:sk|rk)_(?:live|test)_[A-Za-z0-9]{10,})\b`)
+)
+
+// Verifier checks whether secrets still work by making a harmless call to the service they belong to. Each secret is
+// only checked once per session no matter how many findings it appears in.
+type Verifier struct {
+	sync.Mutex
+	client  *http.Client
+	results map[string]string
+}
+
+// secretVerifier is a kind of secret and how to check it
+type secretVerifier struct {
+	pattern *regexp.Regexp
+	verify  func(v *Verifier, secret string) string
+}
+
+// secretVerifiers are the kinds of secrets that can be checked from a single match, AWS keys need two and are checked
+// separately
+var secretVerifiers = []secretVerifier{
+	{githubTokenPattern, (*Verifier).verifyGithub},
+	{slackTokenPattern, (*Verifier).verifySlack},
+	{stripeKeyPattern, (*Verifier).verifyStripe},
+}
+
+// NewVerifier creates a verifier that makes its calls with client, or with a client that uses VerifyTimeout when nil
+func NewVerifier(client *http.Client) *Verifier {
+	if client == nil {
+		client = NewHTTPClient(VerifyTimeout)
+	}
+	return &Verifier{client: client, results: map[string]string{}}
+}
+
+// Verify will check a secret found in a match. It returns an empty result if the match is not a kind of secret that
+// can be checked on its own.
+func (v *Verifier) Verify(match string) string {
+	for _, sv := range secretVerifiers {
+		if m := sv.pattern.FindStringSubmatch(match); m != nil {
+			return v.cached(m[1], func() string { return sv.verify(v, m[1]) })
+		}
+	}
+	return ""
+}
+
+// VerifyAWS will check an AWS access key id and its secret access key by asking STS who they belong to
+func (v *Verifier) VerifyAWS(keyID, secretKey string) string {
+	return v.cached(keyID+":"+secretKey, func() string {
+		body := "Action=GetCallerIdentity&Version=2011-06-15"
+		req, err := http.NewRequest(http.MethodPost, VerifyAWSURL, strings.NewReader(body))
+		if err != nil {
+			return VerificationUnknown
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		signAWSServiceRequest(req, "sts", S3DefaultRegion, AWSCredentials{AccessKeyID: keyID, SecretAccessKey: secretKey}, []byte(body), time.Now().UTC())
+		return v.status(req, []int{http.StatusOK}, []int{http.StatusForbidden})
+	})
+}
+
+// cached will return the result of an earlier check of the same secret, or run check and remember its result
+func (v *Verifier) cached(key string, check func() string) string {
+	v.Lock()
+	result, ok := v.results[key]
+	v.Unlock()
+	if ok {
+		return result
+	}
+
+	result = check()
+
+	v.Lock()
+	v.results[key] = result
+	v.Unlock()
+	return result
+}
+
+// status will make a request and turn its status code into a result
+func (v *Verifier) status(req *http.Request, verified, inactive []int) string {
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return VerificationUnknown
+	}
+	resp.Body.Close()
+
+	for _, code := range verified {
+		if resp.StatusCode == code {
+			return VerificationVerified
+		}
+	}
+	for _, code := range inactive {
+		if resp.StatusCode == code {
+			return VerificationInactive
+		}
+	}
+	return VerificationUnknown
+}
+
+func (v *Verifier) verifyGithub(token string) string {
+	req, err := http.NewRequest(http.MethodGet, VerifyGithubURL, nil)
+	if err != nil {
+		return VerificationUnknown
+	}
+	req.Header.Set("Authorization", "token "+token)
+	return v.status(req, []int{http.StatusOK}, []int{http.StatusUnauthorized})
+}
+
+func (v *Verifier) verifyStripe(key string) string {
+	req, err := http.NewRequest(http.MethodGet, VerifyStripeURL, nil)
+	if err != nil {
+		return VerificationUnknown
+	}
+	req.SetBasicAuth(key, "")
+	// a restricted key without access to the balance is still a working key
+	return v.status(req, []int{http.StatusOK, http.StatusForbidden}, []int{http.StatusUnauthorized})
+}
+
+func (v *Verifier) verifySlack(token string) string {
+	req, err := http.NewRequest(http.MethodPost, VerifySlackURL, nil)
+	if err != nil {
+		return VerificationUnknown
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return VerificationUnknown
+	}
+	defer resp.Body.Close()
+
+	// slack answers every call with a 200 and puts the outcome in the body
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return VerificationUnknown
+	}
+	switch {
+	case result.OK:
+		return VerificationVerified
+	case result.Error == "invalid_auth", result.Error == "account_inactive", result.Error == "token_revoked",
+		result.Error == "token_expired", result.Error == "not_authed":
+		return VerificationInactive
+	default:
+		return VerificationUnknown
+	}
+}
+
+// awsParts returns the AWS access key id and secret access key in a finding, either of which can be empty. A secret
+// access key is only recognized when the finding is about AWS, as any 40 character string would match otherwise.
+func awsParts(f *Finding) (string, string) {
+	var keyID, secretKey string
+	if m := awsKeyIDPattern.FindStringSubmatch(f.secret); m != nil {
+		keyID = m[1]
+	}
+	if strings.Contains(strings.ToLower(f.secret+" "+f.Description), "aws") {
+		if m := awsSecretKeyPattern.FindStringSubmatch(f.secret); m != nil && m[1] != keyID {
+			secretKey = m[1]
+		}
+	}
+	return keyID, secretKey
+}
+
+// sameLocation checks if two findings were made in the same file of the same commit
+func sameLocation(a, b *Finding) bool {
+	return a.RepositoryOwner == b.RepositoryOwner && a.RepositoryName == b.RepositoryName &&
+		a.CommitHash == b.CommitHash && a.FilePath == b.FilePath
+}
+
+// verifyFinding will check the secret of a finding, if verification has been turned on. An AWS key id and its secret
+// key are usually matched by different signatures, so when a finding only has one of them the other is looked for in
+// the findings already made in the same file and both findings are given the result.
+func (s *Session) verifyFinding(f *Finding) {
+	if s.Verifier == nil || f.secret == "" {
+		return
+	}
+
+	if result := s.Verifier.Verify(f.secret); result != "" {
+		f.Verification = result
+		return
+	}
+
+	keyID, secretKey := awsParts(f)
+	if keyID == "" && secretKey == "" {
+		return
+	}
+
+	var partner *Finding
+	if keyID == "" || secretKey == "" {
+		s.Lock()
+		for _, other := range s.Findings {
+			if !sameLocation(f, other) {
+				continue
+			}
+			otherID, otherKey := awsParts(other)
+			if keyID == "" && otherID != "" {
+				keyID, partner = otherID, other
+				break
+			}
+			if secretKey == "" && otherKey != "" {
+				secretKey, partner = otherKey, other
+				break
+			}
+		}
+		s.Unlock()
+	}
+
+	if keyID == "" || secretKey == "" {
+		f.Verification = VerificationUnknown
+		return
+	}
+
+	f.Verification = s.Verifier.VerifyAWS(keyID, secretKey)
+	if partner != nil {
+		s.Lock()
+		partner.Verification = f.Verification
+		s.Unlock()
+	}
+}
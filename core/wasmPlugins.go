@@ -0,0 +1,307 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// These are the names that make up the host interface between wraith and a WebAssembly detector module.
+//
+// A module must export its memory along with:
+//
+//	wraith_alloc(size i32) i32              reserve size bytes of guest memory and return a pointer to them
+//	wraith_detect(ptr i32, len i32) i64     scan the PluginRequest json at ptr and return the PluginResponse json
+//	                                        packed as (pointer << 32 | length)
+//
+// A module may import:
+//
+//	wraith.log(ptr i32, len i32)            write a message to the wraith debug output
+const (
+	WasmHostModule     = "wraith"
+	WasmAllocFunction  = "wraith_alloc"
+	WasmDetectFunction = "wraith_detect"
+	WasmLogFunction    = "log"
+	WasmMemoryLimit    = 1024 // in 64KiB pages, so 64MiB per module instance
+)
+
+// WasmPluginDir is a directory of sandboxed WebAssembly detector modules. Each module is instantiated fresh for
+// every candidate file with no access to the filesystem or network, so no state can leak between files or repos.
+type WasmPluginDir struct {
+	sync.RWMutex
+
+	dir     string
+	logger  *Logger
+	modules map[string]wazero.CompiledModule
+	runtime wazero.Runtime
+	timeout time.Duration
+
+	// compiled are the loaded modules by the sha256 of their content. The runtime keeps one compiled module for the
+	// same content, so closing one that is loaded again would close the new one as well.
+	compiled map[[sha256.Size]byte]wazero.CompiledModule
+
+	// scans is held for reading by every scan while it runs the modules it started with, a reload waits for it before
+	// closing the modules it replaced
+	scans sync.RWMutex
+}
+
+// NewWasmPluginDir will create a runtime with the wraith host interface and compile every module in the directory
+func NewWasmPluginDir(dir string, timeout int, logger *Logger) (*WasmPluginDir, error) {
+	dir = SetHomeDir(strings.TrimSpace(dir))
+
+	if timeout <= 0 {
+		timeout = 30
+	}
+
+	ctx := context.Background()
+	cfg := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(WasmMemoryLimit)
+	r := wazero.NewRuntimeWithConfig(ctx, cfg)
+
+	// WASI is provided so modules built with common toolchains will link, but no directories are mounted
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		return nil, err
+	}
+
+	_, err := r.NewHostModuleBuilder(WasmHostModule).
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, ptr, length uint32) {
+			if msg, ok := m.Memory().Read(ptr, length); ok {
+				logger.Debug("[WASM] %s\n", string(msg))
+			}
+		}).
+		Export(WasmLogFunction).
+		Instantiate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &WasmPluginDir{
+		dir:      dir,
+		logger:   logger,
+		modules:  map[string]wazero.CompiledModule{},
+		runtime:  r,
+		timeout:  time.Duration(timeout) * time.Second,
+		compiled: map[[sha256.Size]byte]wazero.CompiledModule{},
+	}
+
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Name returns the directory the modules are loaded from
+func (d *WasmPluginDir) Name() string {
+	return "wasm:" + d.dir
+}
+
+// Modules returns the sorted names of all the modules that are currently loaded
+func (d *WasmPluginDir) Modules() []string {
+	d.RLock()
+	defer d.RUnlock()
+	var names []string
+	for name := range d.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reload will compile every module in the directory and swap them in place of the current set. A module that has not
+// changed is kept as it was compiled, and one that was replaced is closed once the scans already running are done with
+// it. If a module fails to compile the current set is kept so a bad deploy does not take down a running server.
+func (d *WasmPluginDir) Reload() error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.wasm"))
+	if err != nil {
+		return err
+	}
+
+	d.RLock()
+	loaded := d.compiled
+	d.RUnlock()
+
+	ctx := context.Background()
+	modules := map[string]wazero.CompiledModule{}
+	compiled := map[[sha256.Size]byte]wazero.CompiledModule{}
+	// closeUnused will close the compiled modules of one set that are not in the other
+	closeUnused := func(set, other map[[sha256.Size]byte]wazero.CompiledModule) {
+		for sum, m := range set {
+			if _, ok := other[sum]; !ok {
+				_ = m.Close(ctx)
+			}
+		}
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			closeUnused(compiled, loaded)
+			return err
+		}
+		sum := sha256.Sum256(data)
+		module, ok := compiled[sum]
+		if !ok {
+			module, ok = loaded[sum]
+		}
+		if !ok {
+			if module, err = d.runtime.CompileModule(ctx, data); err != nil {
+				closeUnused(compiled, loaded)
+				return fmt.Errorf("failed to compile %s: %s", f, err)
+			}
+		}
+		compiled[sum] = module
+		modules[strings.TrimSuffix(filepath.Base(f), ".wasm")] = module
+	}
+
+	d.Lock()
+	d.modules = modules
+	d.compiled = compiled
+	d.Unlock()
+
+	// the scans started before the swap may still instantiate or be running the modules that were replaced
+	d.scans.Lock()
+	closeUnused(loaded, compiled)
+	d.scans.Unlock()
+	return nil
+}
+
+// Watch will reload the modules whenever a .wasm file in the directory is created, changed or removed. This is
+// used when the web server is running so new detectors can be dropped in without a restart.
+func (d *WasmPluginDir) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(d.dir); err != nil {
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(event.Name) != ".wasm" {
+					continue
+				}
+				if err := d.Reload(); err != nil {
+					d.logger.Error("Failed to reload WASM plugins: %s\n", err)
+					continue
+				}
+				d.logger.Info("Reloaded WASM plugins: %s\n", strings.Join(d.Modules(), ", "))
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				d.logger.Error("Error watching WASM plugin directory: %s\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Scan will run the request through every loaded module and combine their findings
+func (d *WasmPluginDir) Scan(req *PluginRequest) ([]PluginFinding, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	d.scans.RLock()
+	defer d.scans.RUnlock()
+	d.RLock()
+	modules := make(map[string]wazero.CompiledModule, len(d.modules))
+	for name, m := range d.modules {
+		modules[name] = m
+	}
+	d.RUnlock()
+
+	var findings []PluginFinding
+	var errs []string
+	for name, compiled := range modules {
+		output, err := d.run(compiled, payload)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("plugin %s failed: %s", name, err))
+			continue
+		}
+		if len(output) == 0 {
+			continue
+		}
+		results, err := parsePluginResponse(name, output)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		findings = append(findings, results...)
+	}
+
+	if len(errs) > 0 {
+		return findings, errors.New(strings.Join(errs, "; "))
+	}
+	return findings, nil
+}
+
+// run will instantiate a single module, copy the request into its memory and read back the response
+func (d *WasmPluginDir) run(compiled wazero.CompiledModule, payload []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	mod, err := d.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, err
+	}
+	defer mod.Close(context.Background())
+
+	alloc := mod.ExportedFunction(WasmAllocFunction)
+	detect := mod.ExportedFunction(WasmDetectFunction)
+	if alloc == nil || detect == nil || mod.Memory() == nil {
+		return nil, fmt.Errorf("module must export memory, %s and %s", WasmAllocFunction, WasmDetectFunction)
+	}
+
+	res, err := alloc.Call(ctx, uint64(len(payload)))
+	if err != nil {
+		return nil, err
+	}
+	ptr := uint32(res[0])
+	if !mod.Memory().Write(ptr, payload) {
+		return nil, fmt.Errorf("request of %d bytes does not fit in module memory", len(payload))
+	}
+
+	res, err = detect.Call(ctx, uint64(ptr), uint64(len(payload)))
+	if err != nil {
+		return nil, err
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	if outLen == 0 {
+		return nil, nil
+	}
+
+	out, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("response is outside of module memory")
+	}
+
+	// the memory view is invalid once the module is closed so take a copy
+	response := make([]byte, len(out))
+	copy(response, out)
+	return response, nil
+}
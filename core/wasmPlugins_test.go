@@ -0,0 +1,153 @@
+package core_test
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wraith/core"
+)
+
+// wasmSection is a section of a WebAssembly module, its id followed by the length of its content
+func wasmSection(id byte, content ...[]byte) []byte {
+	var body []byte
+	for _, c := range content {
+		body = append(body, c...)
+	}
+	return append(append([]byte{id}, wasmLEB(uint64(len(body)))...), body...)
+}
+
+// wasmLEB is an unsigned number in the LEB128 encoding of WebAssembly
+func wasmLEB(n uint64) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// wasmName is a name in a WebAssembly module, its length followed by its bytes
+func wasmName(name string) []byte {
+	return append(wasmLEB(uint64(len(name))), name...)
+}
+
+// wasmSLEB is a signed number in the LEB128 encoding of WebAssembly
+func wasmSLEB(n int64) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if (n == 0 && b&0x40 == 0) || (n == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// wasmDetector will build a module that answers every request with the same response, which is kept at the start of
+// its memory. Requests are written after it.
+func wasmDetector(response string) []byte {
+	return wasmSlowDetector(response, 0)
+}
+
+// wasmSlowDetector will build a module like wasmDetector that counts to spins before it answers
+func wasmSlowDetector(response string, spins int32) []byte {
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, wasmSection(1, []byte{0x02,
+		0x60, 0x01, 0x7f, 0x01, 0x7f, // (i32) -> i32
+		0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, // (i32, i32) -> i64
+	})...)
+	module = append(module, wasmSection(3, []byte{0x02, 0x00, 0x01})...)
+	module = append(module, wasmSection(5, []byte{0x01, 0x00, 0x01})...)
+	module = append(module, wasmSection(7, []byte{0x03},
+		wasmName("memory"), []byte{0x02, 0x00},
+		wasmName(core.WasmAllocFunction), []byte{0x00, 0x00},
+		wasmName(core.WasmDetectFunction), []byte{0x00, 0x01},
+	)...)
+	alloc := []byte{0x00, 0x41, 0x80, 0x08, 0x0b} // i32.const 1024
+	detect := []byte{0x01, 0x01, 0x7f}            // a counter
+	if spins > 0 {
+		detect = append(detect, 0x03, 0x40, 0x20, 0x02, 0x41, 0x01, 0x6a, 0x22, 0x02, 0x41) // loop: counter += 1
+		detect = append(append(detect, wasmSLEB(int64(spins))...), 0x49, 0x0d, 0x00, 0x0b)  // while counter < spins
+	}
+	detect = append(append(append(detect, 0x42), wasmLEB(uint64(len(response)))...), 0x0b) // i64.const len(response)
+	module = append(module, wasmSection(10, []byte{0x02},
+		wasmLEB(uint64(len(alloc))), alloc,
+		wasmLEB(uint64(len(detect))), detect,
+	)...)
+	return append(module, wasmSection(11, []byte{0x01, 0x00, 0x41, 0x00, 0x0b}, wasmName(response))...)
+}
+
+func TestWasmPlugins(t *testing.T) {
+
+	Convey("Given a directory of WebAssembly detector modules", t, func() {
+		dir, err := ioutil.TempDir("", "wraith-wasm")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "tokens.wasm"), wasmDetector(`{"findings": [{"match": "ACME-TOKEN-0001", "line_number": 3}]}`), 0600), ShouldBeNil)
+		plugins, err := core.NewWasmPluginDir(dir, 5, &core.Logger{})
+		So(err, ShouldBeNil)
+
+		Convey("Every module should be run for a file and its findings traced back to it", func() {
+			So(plugins.Modules(), ShouldResemble, []string{"tokens"})
+			findings, err := plugins.Scan(&core.PluginRequest{Content: "token ACME-TOKEN-0001", FilePath: "config.yml"})
+			So(err, ShouldBeNil)
+			So(findings, ShouldResemble, []core.PluginFinding{{Match: "ACME-TOKEN-0001", LineNumber: 3,
+				Signatureid: "plugin:tokens", Description: "Finding reported by plugin tokens"}})
+		})
+
+		Convey("A module that does not export the host interface should be an error, but not stop the others", func() {
+			So(ioutil.WriteFile(filepath.Join(dir, "empty.wasm"), []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, 0600), ShouldBeNil)
+			So(plugins.Reload(), ShouldBeNil)
+			findings, err := plugins.Scan(&core.PluginRequest{Content: "token ACME-TOKEN-0001"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, core.WasmDetectFunction)
+			So(findings, ShouldHaveLength, 1)
+		})
+
+		Convey("A module that does not compile should keep the modules that were loaded", func() {
+			So(ioutil.WriteFile(filepath.Join(dir, "broken.wasm"), []byte("not a module"), 0600), ShouldBeNil)
+			So(plugins.Reload(), ShouldNotBeNil)
+			So(plugins.Modules(), ShouldResemble, []string{"tokens"})
+		})
+
+		Convey("A scan that is running while its modules are replaced should finish with the modules it started with", func() {
+			for _, name := range []string{"tokens", "keys"} {
+				So(ioutil.WriteFile(filepath.Join(dir, name+".wasm"), wasmSlowDetector(`{"findings": []}`, 10000000), 0600), ShouldBeNil)
+			}
+			So(plugins.Reload(), ShouldBeNil)
+			started := time.Now()
+			_, err := plugins.Scan(&core.PluginRequest{Content: "token"})
+			So(err, ShouldBeNil)
+			took := time.Since(started)
+
+			scanned := make(chan error)
+			go func() {
+				_, err := plugins.Scan(&core.PluginRequest{Content: "token"})
+				scanned <- err
+			}()
+			// the modules are replaced while the scan is in the first of them
+			time.Sleep(took / 4)
+			for _, name := range []string{"tokens", "keys"} {
+				So(ioutil.WriteFile(filepath.Join(dir, name+".wasm"), wasmSlowDetector(`{"findings": [] }`, 1), 0600), ShouldBeNil)
+			}
+			So(plugins.Reload(), ShouldBeNil)
+			So(<-scanned, ShouldBeNil)
+		})
+
+		Convey("A module dropped into the directory should be loaded while watching it", func() {
+			So(plugins.Watch(), ShouldBeNil)
+			So(ioutil.WriteFile(filepath.Join(dir, "keys.wasm"), wasmDetector(`{"findings": []}`), 0600), ShouldBeNil)
+			for i := 0; i < 100 && len(plugins.Modules()) < 2; i++ {
+				time.Sleep(20 * time.Millisecond)
+			}
+			So(plugins.Modules(), ShouldResemble, []string{"keys", "tokens"})
+		})
+	})
+}
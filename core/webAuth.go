@@ -0,0 +1,343 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// These are the paths the web interface uses to sign a user in and out with an OIDC provider
+const (
+	WebAuthLoginPath    = "/auth/login"
+	WebAuthCallbackPath = "/auth/callback"
+	WebAuthLogoutPath   = "/auth/logout"
+)
+
+// WebSessionLifetime is how long a user stays signed in to the web interface after signing in with an OIDC provider
+const WebSessionLifetime = 12 * time.Hour
+
+// These are the cookies used to sign a user in with an OIDC provider
+const (
+	webSessionCookie = "wraith_session"
+	webStateCookie   = "wraith_oidc_state"
+)
+
+// WebAuthConfig is how the web interface and its api are protected. Basic auth is used when Username is set and OIDC
+// when Issuer is set, the web interface is left open when neither is.
+type WebAuthConfig struct {
+	Username string
+	Password string
+
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	AllowedEmails []string
+
+	// HTTPClient is used to talk to the OIDC provider, a client made with NewHTTPClient when nil
+	HTTPClient *http.Client
+}
+
+// WebAuth checks every request to the web interface and its api before it is served
+type WebAuth struct {
+	basic gin.HandlerFunc
+
+	oauth         *oauth2.Config
+	userInfoURL   string
+	allowedEmails []string
+	client        *http.Client
+	key           []byte
+	secureCookies bool
+}
+
+// oidcDiscovery is the part of the discovery document of an OIDC provider that is needed to sign a user in
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// oidcUserInfo is who the OIDC provider says signed in
+type oidcUserInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+}
+
+// NewWebAuth will create the authentication for the web interface from its config. It returns nil if no
+// authentication has been configured. Setting up OIDC fetches the discovery document of the provider, so an issuer
+// that cannot be reached is an error.
+func NewWebAuth(cfg WebAuthConfig) (*WebAuth, error) {
+	switch {
+	case cfg.Username != "" && cfg.Issuer != "":
+		return nil, errors.New("use either basic auth or OIDC for the web interface, not both")
+	case cfg.Username != "":
+		if cfg.Password == "" {
+			return nil, errors.New("web-password is needed when web-username is set")
+		}
+		return &WebAuth{basic: gin.BasicAuthForRealm(gin.Accounts{cfg.Username: cfg.Password}, Name)}, nil
+	case cfg.Issuer != "":
+		return newOIDCWebAuth(cfg)
+	default:
+		return nil, nil
+	}
+}
+
+func newOIDCWebAuth(cfg WebAuthConfig) (*WebAuth, error) {
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("oidc-client-id and oidc-redirect-url are needed when oidc-issuer is set")
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = NewHTTPClient(0)
+	}
+
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+	resp, err := client.Get(issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the discovery document of %s returned %s", issuer, resp.Status)
+	}
+
+	var d oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("the discovery document of %s is for a different issuer %s", issuer, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("the discovery document of %s is missing an endpoint", issuer)
+	}
+
+	// sessions are signed with a key that only lives as long as the process, restarting signs everyone out
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	var allowed []string
+	for _, e := range cfg.AllowedEmails {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			allowed = append(allowed, e)
+		}
+	}
+
+	return &WebAuth{
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     oauth2.Endpoint{AuthURL: d.AuthorizationEndpoint, TokenURL: d.TokenEndpoint},
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+		},
+		userInfoURL:   d.UserInfoEndpoint,
+		allowedEmails: allowed,
+		client:        client,
+		key:           key,
+		secureCookies: strings.HasPrefix(cfg.RedirectURL, "https://"),
+	}, nil
+}
+
+// Middleware returns the handler that is run before every route of the web interface
+func (a *WebAuth) Middleware() gin.HandlerFunc {
+	if a.basic != nil {
+		return a.basic
+	}
+	return a.oidc
+}
+
+// oidc will let through requests with a valid session, handle the steps of signing in and out, and send everyone else
+// to the provider to sign in. Requests for the api are refused rather than redirected, so the web interface does not
+// try to parse the login page of the provider as json.
+func (a *WebAuth) oidc(c *gin.Context) {
+	switch c.Request.URL.Path {
+	case WebAuthCallbackPath:
+		a.callback(c)
+		return
+	case WebAuthLogoutPath:
+		a.setCookie(c, webSessionCookie, "", -1)
+		c.String(http.StatusOK, "Signed out of %s\n", Name)
+		c.Abort()
+		return
+	case WebAuthLoginPath:
+		a.login(c)
+		return
+	}
+
+	if cookie, err := c.Cookie(webSessionCookie); err == nil {
+		if email, ok := a.verifySession(cookie, time.Now()); ok {
+			c.Set("user", email)
+			c.Next()
+			return
+		}
+	}
+
+	if strings.Contains(c.GetHeader("Accept"), "text/html") {
+		a.login(c)
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"message": "Sign in at " + WebAuthLoginPath,
+	})
+}
+
+// login will send the user to the provider to sign in
+func (a *WebAuth) login(c *gin.Context) {
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	s := hex.EncodeToString(state)
+	a.setCookie(c, webStateCookie, s, 600)
+	c.Redirect(http.StatusFound, a.oauth.AuthCodeURL(s))
+	c.Abort()
+}
+
+// callback is where the provider sends the user back to once they have signed in
+func (a *WebAuth) callback(c *gin.Context) {
+	state, err := c.Cookie(webStateCookie)
+	if err != nil || state == "" || !hmac.Equal([]byte(state), []byte(c.Query("state"))) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "The sign in has expired, try again"})
+		return
+	}
+	a.setCookie(c, webStateCookie, "", -1)
+
+	if e := c.Query("error"); e != "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Sign in failed: " + e})
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), oauth2.HTTPClient, a.client)
+	token, err := a.oauth.Exchange(ctx, c.Query("code"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Sign in failed: " + err.Error()})
+		return
+	}
+
+	user, err := a.userInfo(ctx, token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Sign in failed: " + err.Error()})
+		return
+	}
+	if !a.allowed(user) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": user.Email + " is not allowed to use " + Name})
+		return
+	}
+
+	a.setCookie(c, webSessionCookie, a.signSession(user.Email, time.Now().Add(WebSessionLifetime)), int(WebSessionLifetime.Seconds()))
+	c.Redirect(http.StatusFound, "/")
+	c.Abort()
+}
+
+// userInfo will ask the provider who the token belongs to
+func (a *WebAuth) userInfo(ctx context.Context, token *oauth2.Token) (*oidcUserInfo, error) {
+	resp, err := a.oauth.Client(ctx, token).Get(a.userInfoURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the userinfo endpoint returned %s", resp.Status)
+	}
+
+	var user oidcUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+	if user.Subject == "" {
+		return nil, errors.New("the userinfo endpoint did not return a subject")
+	}
+	return &user, nil
+}
+
+// allowed checks if a user may use the web interface. Anyone the provider signs in is allowed when there is no list
+// of allowed emails, otherwise their verified email has to be in it or end with a domain in it such as @example.com.
+func (a *WebAuth) allowed(user *oidcUserInfo) bool {
+	if len(a.allowedEmails) == 0 {
+		return true
+	}
+	if user.Email == "" || (user.EmailVerified != nil && !*user.EmailVerified) {
+		return false
+	}
+	email := strings.ToLower(user.Email)
+	for _, e := range a.allowedEmails {
+		if email == e || (strings.HasPrefix(e, "@") && strings.HasSuffix(email, e)) {
+			return true
+		}
+	}
+	return false
+}
+
+// signSession will create the value of a session cookie for a user that is valid until expires
+func (a *WebAuth) signSession(email string, expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(email + "|" + strconv.FormatInt(expires.Unix(), 10)))
+	return payload + "." + a.mac(payload)
+}
+
+// verifySession will check a session cookie and return the email of the user it belongs to
+func (a *WebAuth) verifySession(cookie string, now time.Time) (string, bool) {
+	parts := strings.SplitN(cookie, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(a.mac(parts[0]))) {
+		return "", false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	i := strings.LastIndex(string(data), "|")
+	if i < 0 {
+		return "", false
+	}
+	expires, err := strconv.ParseInt(string(data[i+1:]), 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return "", false
+	}
+	return string(data[:i]), true
+}
+
+func (a *WebAuth) mac(payload string) string {
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (a *WebAuth) setCookie(c *gin.Context, name, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   a.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// isLoopback checks if the web interface is only reachable from the machine it runs on
+func isLoopback(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
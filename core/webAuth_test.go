@@ -0,0 +1,170 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"wraith/core"
+)
+
+// fakeOIDCProvider signs in whoever it is told to and checks the code it handed out is the one exchanged
+type fakeOIDCProvider struct {
+	url   string
+	email string
+}
+
+func (p *fakeOIDCProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.url,
+			"authorization_endpoint": p.url + "/authorize",
+			"token_endpoint":         p.url + "/token",
+			"userinfo_endpoint":      p.url + "/userinfo",
+		})
+	case "/token":
+		if r.FormValue("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "Bearer", "expires_in": 3600})
+	case "/userinfo":
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"sub": "1", "email": p.email, "email_verified": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestWebAuth(t *testing.T) {
+
+	get := func(router http.Handler, path string, cookies []*http.Cookie, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Convey("No authentication should be set up when none is configured", t, func() {
+		auth, err := core.NewWebAuth(core.WebAuthConfig{})
+		So(err, ShouldBeNil)
+		So(auth, ShouldBeNil)
+	})
+
+	Convey("Basic auth and OIDC should not be configured together", t, func() {
+		_, err := core.NewWebAuth(core.WebAuthConfig{Username: "admin", Password: "pw", Issuer: "https://id.example.com"})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given a web interface protected by basic auth", t, func() {
+		auth, err := core.NewWebAuth(core.WebAuthConfig{Username: "admin", Password: "s3cret"})
+		So(err, ShouldBeNil)
+		router := core.NewRouter(&core.Session{Stats: &core.Stats{}, WebAuth: auth})
+
+		Convey("The findings and the interface itself should need credentials", func() {
+			w := get(router, "/findings", nil, nil)
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			So(w.Header().Get("WWW-Authenticate"), ShouldContainSubstring, "Basic")
+			So(get(router, "/", nil, nil).Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("The right credentials should be let through", func() {
+			req := httptest.NewRequest(http.MethodGet, "/findings", nil)
+			req.SetBasicAuth("admin", "s3cret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			req.SetBasicAuth("admin", "wrong")
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+	})
+
+	Convey("Given a web interface protected by OIDC", t, func() {
+		provider := &fakeOIDCProvider{email: "jane@example.com"}
+		server := httptest.NewServer(provider)
+		defer server.Close()
+		provider.url = server.URL
+
+		auth, err := core.NewWebAuth(core.WebAuthConfig{
+			Issuer:        server.URL,
+			ClientID:      "wraith",
+			ClientSecret:  "secret",
+			RedirectURL:   "http://127.0.0.1:9393/auth/callback",
+			AllowedEmails: []string{"@example.com"},
+		})
+		So(err, ShouldBeNil)
+		router := core.NewRouter(&core.Session{Stats: &core.Stats{}, WebAuth: auth})
+
+		signIn := func(code string) *httptest.ResponseRecorder {
+			w := get(router, "/", nil, map[string]string{"Accept": "text/html"})
+			So(w.Code, ShouldEqual, http.StatusFound)
+			location, err := url.Parse(w.Header().Get("Location"))
+			So(err, ShouldBeNil)
+			So(location.Path, ShouldEqual, "/authorize")
+			state := location.Query().Get("state")
+			return get(router, "/auth/callback?code="+code+"&state="+state, w.Result().Cookies(), nil)
+		}
+
+		Convey("A browser should be sent to the provider and the api should be refused", func() {
+			So(get(router, "/findings", nil, nil).Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("A user that signs in should be able to use the api", func() {
+			w := signIn("good-code")
+			So(w.Code, ShouldEqual, http.StatusFound)
+			So(w.Header().Get("Location"), ShouldEqual, "/")
+
+			var session []*http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == "wraith_session" && c.Value != "" {
+					session = append(session, c)
+				}
+			}
+			So(session, ShouldHaveLength, 1)
+			So(get(router, "/findings", session, nil).Code, ShouldEqual, http.StatusOK)
+
+			session[0].Value = strings.Replace(session[0].Value, "a", "b", 1) + "x"
+			So(get(router, "/findings", session, nil).Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("A code the provider does not accept should not sign in", func() {
+			So(signIn("bad-code").Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("A user whose email is not allowed should be refused", func() {
+			provider.email = "mallory@evil.example"
+			So(signIn("good-code").Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("A callback without the state it was sent with should be refused", func() {
+			So(get(router, "/auth/callback?code=good-code&state=guess", nil, nil).Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+
+	Convey("An issuer whose discovery document names another issuer should be an error", t, func() {
+		provider := &fakeOIDCProvider{url: "https://someone.else"}
+		server := httptest.NewServer(provider)
+		defer server.Close()
+
+		_, err := core.NewWebAuth(core.WebAuthConfig{Issuer: server.URL, ClientID: "wraith", RedirectURL: "http://localhost/auth/callback"})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookTimeout is the longest a single webhook request is allowed to take
+const WebhookTimeout = 10 * time.Second
+
+// WebhookAttempts is how many times a webhook request is sent before it is given up on
+const WebhookAttempts = 3
+
+// These are the values of the X-Wraith-Event header that tell the endpoint what the payload is, and the header the
+// signature of the payload is sent in when a webhook secret is set
+const (
+	WebhookFinding         = "finding"
+	WebhookSummary         = "summary"
+	WebhookEventHeader     = "X-Wraith-Event"
+	WebhookSignatureHeader = "X-Wraith-Signature-256"
+)
+
+// JSONSummary is the stable representation of a finished scan sent to the webhook once it is done
+type JSONSummary struct {
+	SchemaVersion     string      `json:"schema_version"`
+	WraithVersion     string      `json:"wraith_version"`
+	SignaturesVersion string      `json:"signatures_version"`
+	ScanType          string      `json:"scan_type"`
+	StartedAt         string      `json:"started_at"`
+	FinishedAt        string      `json:"finished_at"`
+	Stats             JSONStats   `json:"stats"`
+	Risk              RiskReport  `json:"risk"`
+	Errors            []ScanError `json:"errors,omitempty"`
+	ThresholdFailures []string    `json:"threshold_failures"`
+}
+
+// NewJSONSummary will build the summary of a session, which is its json report without the findings
+func NewJSONSummary(s *Session) JSONSummary {
+	report := NewJSONReport(s)
+	return JSONSummary{
+		SchemaVersion:     report.SchemaVersion,
+		WraithVersion:     report.WraithVersion,
+		SignaturesVersion: report.SignaturesVersion,
+		ScanType:          report.ScanType,
+		StartedAt:         report.StartedAt,
+		FinishedAt:        report.FinishedAt,
+		Stats:             report.Stats,
+		Risk:              report.Risk,
+		Errors:            report.Errors,
+		ThresholdFailures: append([]string{}, s.ThresholdFailures...),
+	}
+}
+
+// Webhook posts findings and the summary of a scan as json to an http endpoint
+type Webhook struct {
+	URL     string
+	Headers http.Header
+	Secret  string
+	client  *http.Client
+}
+
+// NewWebhook will create a webhook for a url, with extra headers given as "Name: value". When the secret is set each
+// payload is signed with it.
+func NewWebhook(endpoint string, headers []string, secret string) (*Webhook, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http or https url", endpoint)
+	}
+
+	h := http.Header{}
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("header %q is not in the form Name: value", header)
+		}
+		h.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	return &Webhook{
+		URL:     endpoint,
+		Headers: h,
+		Secret:  secret,
+		client:  NewHTTPClient(WebhookTimeout),
+	}, nil
+}
+
+// Sign will return the hex encoded HMAC-SHA256 of a payload, prefixed with the name of the hash
+func (w *Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send will post a payload to the webhook, trying again when the request fails or the endpoint returns a 429 or 5xx
+func (w *Webhook) Send(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(event, data)
+		if err == nil || !retry || attempt == WebhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// post will make a single request to the webhook, returning whether it is worth trying again when it fails
+func (w *Webhook) post(event string, data []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	for name, values := range w.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(WebhookEventHeader, event)
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, w.Sign(data))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("%s returned %s", w.URL, resp.Status)
+}
+
+// sendFindingWebhook will post a single finding to the webhook if one is configured
+func (s *Session) sendFindingWebhook(finding *Finding) {
+	if s.Webhook == nil {
+		return
+	}
+
+	sink := AlertSink(WebhookFinding, s.Webhook.URL)
+	now := time.Now()
+	if !s.Alerts.ShouldAlert(sink, finding, now) {
+		s.Out.Debug("Webhook already alerted for %s\n", finding.FilePath)
+		return
+	}
+
+	jf := NewJSONFinding(finding)
+	jf.SchemaVersion = JSONSchemaVersion
+	if err := s.Webhook.Send(WebhookFinding, jf); err != nil {
+		s.Out.Error("Webhook failed for %s: %s\n", finding.FilePath, err)
+		return
+	}
+	s.Alerts.Alerted(sink, finding, now)
+}
+
+// SendWebhookSummary will post the summary of a finished scan to the webhook if one is configured
+func (s *Session) SendWebhookSummary() {
+	if s.Webhook == nil {
+		return
+	}
+	if err := s.Webhook.Send(WebhookSummary, NewJSONSummary(s)); err != nil {
+		s.Out.Error("Webhook failed for the scan summary: %s\n", err)
+	}
+}
@@ -0,0 +1,103 @@
+package core_test
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"wraith/core"
+)
+
+// webhookRequest is what the test endpoint received in a single request
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhook(t *testing.T) {
+
+	Convey("Given a webhook endpoint", t, func() {
+		var mu sync.Mutex
+		var received []webhookRequest
+		status := []int{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, webhookRequest{header: r.Header, body: body})
+			if len(status) > 0 {
+				w.WriteHeader(status[0])
+				status = status[1:]
+			}
+		}))
+		defer server.Close()
+
+		wh, err := core.NewWebhook(server.URL+"/hook", []string{"Authorization: Bearer t0k3n", "X-Team:  security "}, "s3cret")
+		So(err, ShouldBeNil)
+
+		sess := &core.Session{
+			Stats:    &core.Stats{},
+			Out:      &core.Logger{},
+			Silent:   true,
+			ScanType: "localPath",
+			Webhook:  wh,
+		}
+
+		Convey("Each finding should be posted with the configured headers and signed", func() {
+			So(sess.AddFinding(&core.Finding{FilePath: "a.env", Description: "AWS Access Key ID", SecretID: "abc", Score: 3}), ShouldBeTrue)
+			So(received, ShouldHaveLength, 1)
+
+			req := received[0]
+			So(req.header.Get("Authorization"), ShouldEqual, "Bearer t0k3n")
+			So(req.header.Get("X-Team"), ShouldEqual, "security")
+			So(req.header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(req.header.Get(core.WebhookEventHeader), ShouldEqual, core.WebhookFinding)
+			So(req.header.Get(core.WebhookSignatureHeader), ShouldEqual, wh.Sign(req.body))
+			So(req.header.Get(core.WebhookSignatureHeader), ShouldStartWith, "sha256=")
+
+			var jf core.JSONFinding
+			So(json.Unmarshal(req.body, &jf), ShouldBeNil)
+			So(jf.FilePath, ShouldEqual, "a.env")
+			So(jf.SchemaVersion, ShouldEqual, core.JSONSchemaVersion)
+		})
+
+		Convey("The summary should be posted without the findings", func() {
+			sess.AddFinding(&core.Finding{FilePath: "a.env", Description: "AWS Access Key ID", SecretID: "abc", Score: 3})
+			sess.SendWebhookSummary()
+			So(received, ShouldHaveLength, 2)
+			So(received[1].header.Get(core.WebhookEventHeader), ShouldEqual, core.WebhookSummary)
+
+			var summary map[string]interface{}
+			So(json.Unmarshal(received[1].body, &summary), ShouldBeNil)
+			So(summary["scan_type"], ShouldEqual, "localPath")
+			So(summary["stats"].(map[string]interface{})["findings_total"], ShouldEqual, 1)
+			So(summary, ShouldNotContainKey, "findings")
+		})
+
+		Convey("A request should be sent again when the endpoint fails, but not when it rejects it", func() {
+			status = []int{http.StatusServiceUnavailable}
+			So(wh.Send(core.WebhookSummary, map[string]string{}), ShouldBeNil)
+			So(received, ShouldHaveLength, 2)
+
+			status = []int{http.StatusBadRequest}
+			So(wh.Send(core.WebhookSummary, map[string]string{}), ShouldNotBeNil)
+			So(received, ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("A webhook should only be created for an http url and well formed headers", t, func() {
+		_, err := core.NewWebhook("ftp://example.com", nil, "")
+		So(err, ShouldNotBeNil)
+
+		_, err = core.NewWebhook("https://example.com", []string{"no colon"}, "")
+		So(err, ShouldNotBeNil)
+
+		wh, err := core.NewWebhook("https://example.com", nil, "")
+		So(err, ShouldBeNil)
+		So(wh.Headers, ShouldBeEmpty)
+	})
+}
@@ -63,11 +63,76 @@ var Finding = Backbone.Model.extend({
             error: error
         });
     },
+    severityBadges: {
+        "critical": "badge-danger",
+        "high": "badge-warning",
+        "medium": "badge-info",
+        "low": "badge-secondary",
+    },
+    severityBadge: function () {
+        return this.severityBadges[this.get("Severity")];
+    },
+    triageLabels: {
+        "false_positive": "False positive",
+        "confirmed": "Confirmed",
+        "remediated": "Remediated",
+    },
+    triageBadges: {
+        "false_positive": "badge-secondary",
+        "confirmed": "badge-danger",
+        "remediated": "badge-success",
+    },
+    triageStatus: function () {
+        var triage = this.get("Triage");
+        return triage ? triage.status : "";
+    },
+    triageLabel: function () {
+        return this.triageLabels[this.triageStatus()];
+    },
+    triageBadge: function () {
+        return this.triageBadges[this.triageStatus()];
+    },
+    triage: function (status, note, callback, error) {
+        var fingerprint = this.get("Fingerprint");
+        $.ajax({
+            url: "/findings/" + fingerprint + "/triage",
+            method: "POST",
+            contentType: "application/json",
+            data: JSON.stringify({status: status, note: note}),
+            success: function (triage) {
+                // the same secret in other commits shares its fingerprint, and so its triage
+                findings.each(function (finding) {
+                    if (finding.get("Fingerprint") === fingerprint) {
+                        finding.set("Triage", status === "" ? null : triage);
+                    }
+                });
+                callback();
+            },
+            error: error
+        });
+    },
 });
 
 var Findings = Backbone.Collection.extend({
     url: "/findings",
     model: Finding,
+    pageSize: 100,
+    offset: 0,
+    total: 0,
+    filters: {},
+    repositories: [],
+    signatures: [],
+    parse: function (page) {
+        this.total = page.Total;
+        this.repositories = page.Repositories;
+        this.signatures = page.Signatures;
+        return page.Findings;
+    },
+    fetchPage: function () {
+        // the server filters and pages the findings, a scan can have far too many to render at once
+        var data = _.extend({offset: this.offset, limit: this.pageSize}, _.pick(this.filters, _.identity));
+        return this.fetch({reset: true, data: data});
+    },
 });
 
 window.findings = new Findings();
@@ -184,11 +249,19 @@ var FindingView = Backbone.View.extend({
         "click td.col-path a": "showFinding",
     },
     template: _.template($("#template_finding").html()),
+    initialize: function () {
+        this.listenTo(this.model, "change:Triage", function () {
+            this.render();
+            findingsView.filterFinding(this.$el);
+        });
+    },
     render: function () {
         this.$el.html(this.template(this.model.attributes)).data("finding", this.model);
         if (this.model.isTestRelated()) {
             this.$el.addClass("test-related");
         }
+        var status = this.model.triageStatus();
+        this.$el.toggleClass("triaged", status === "false_positive" || status === "remediated");
         return this;
     },
     formattedFilePath: function () {
@@ -230,11 +303,18 @@ var FindingView = Backbone.View.extend({
 var FindingsView = Backbone.View.extend({
     collection: findings,
     initialize: function () {
-        this.listenTo(this.collection, "add", this.renderFinding);
+        this.listenTo(this.collection, "reset", this.render);
         this.listenTo(stats, "change:Findings", _.debounce(this.update, 500));
-        $("#findings_search").on("keyup", _.debounce(this.searchFindings, 200));
+        $("#findings_search, #findings_path").on("keyup", _.debounce(this.filterFindings, 300));
+        $("#findings_triage, #findings_severity, #findings_repository, #findings_signature").on("change", this.filterFindings);
+        $("#findings_previous").on("click", this.previousPage);
+        $("#findings_next").on("click", this.nextPage);
         $("#finding_modal").on("show.bs.modal", function (event) {
             $(document).on("keydown", function (e) {
+                // the arrow keys move the cursor when typing a triage note
+                if ($(e.target).is("input")) {
+                    return;
+                }
                 switch (e.keyCode) {
                     case 37:
                         var finding = findingsView.previousFinding();
@@ -262,14 +342,70 @@ var FindingsView = Backbone.View.extend({
             .on("hidden.bs.modal", function (event) {
                 $(document).unbind("keydown");
             });
+        this.update();
     },
     update: function () {
-        this.collection.fetch();
+        // the page is left alone while a finding is open, so the arrow keys keep moving through the same rows
+        if ($("#finding_modal").hasClass("show")) {
+            return;
+        }
+        this.collection.fetchPage();
+    },
+    render: function () {
+        var findings = this.collection;
+        if (findings.length === 0 && findings.offset > 0 && findings.total > 0) {
+            // fewer findings match than before, go to the last page that has any
+            findings.offset = Math.floor((findings.total - 1) / findings.pageSize) * findings.pageSize;
+            findings.fetchPage();
+            return;
+        }
+        this.$el.empty();
+        findings.each(this.renderFinding, this);
+        this.renderFilter("#findings_repository", findings.repositories);
+        this.renderFilter("#findings_signature", findings.signatures);
+        this.renderPages();
     },
     renderFinding: function (finding) {
         var findingEl = new FindingView({model: finding}).render().el;
         $(findingEl).appendTo(this.$el);
     },
+    renderFilter: function (id, values) {
+        var select = $(id);
+        var selected = select.val();
+        select.find("option[value!='']").remove();
+        _.each(values, function (value) {
+            $("<option>").val(value).text(value).appendTo(select);
+        });
+        select.val(selected);
+    },
+    renderPages: function () {
+        var findings = this.collection;
+        var end = Math.min(findings.offset + findings.pageSize, findings.total);
+        if (findings.total === 0) {
+            $("#findings_range").text("No findings");
+        } else {
+            $("#findings_range").text((findings.offset + 1).toLocaleString() + "–" + end.toLocaleString() + " of " +
+                findings.total.toLocaleString());
+        }
+        $("#findings_previous").parent().toggleClass("disabled", findings.offset === 0);
+        $("#findings_next").parent().toggleClass("disabled", end >= findings.total);
+    },
+    previousPage: function (e) {
+        e.preventDefault();
+        if (findings.offset === 0) {
+            return;
+        }
+        findings.offset = Math.max(0, findings.offset - findings.pageSize);
+        findings.fetchPage();
+    },
+    nextPage: function (e) {
+        e.preventDefault();
+        if (findings.offset + findings.pageSize >= findings.total) {
+            return;
+        }
+        findings.offset += findings.pageSize;
+        findings.fetchPage();
+    },
     activeFinding: function () {
         return this.$el.find("tr.table-selected");
     },
@@ -279,26 +415,120 @@ var FindingsView = Backbone.View.extend({
     previousFinding: function () {
         return this.activeFinding().prevAll("tr").not(".d-none").first();
     },
-    searchFindings: function () {
-        var needle = $.trim($("#findings_search").val()).toLowerCase();
-        if (needle == "") {
-            $("#table_findings tbody tr").removeClass("d-none");
-            return;
+    filterFindings: function () {
+        findings.filters = {
+            q: $.trim($("#findings_search").val()),
+            path: $.trim($("#findings_path").val()),
+            triage: $("#findings_triage").val(),
+            severity: $("#findings_severity").val(),
+            repository: $("#findings_repository").val(),
+            signature: $("#findings_signature").val(),
+            secret: findings.filters.secret,
+        };
+        findings.offset = 0;
+        findings.fetchPage();
+    },
+    filterFinding: function (row) {
+        // a finding triaged out of the filter is hidden until the page is fetched again
+        var triage = $("#findings_triage").val();
+        if (triage != "") {
+            var status = row.data("finding").triageStatus();
+            row.toggleClass("d-none", triage === "untriaged" ? status !== "" : status !== triage);
         }
-        $("#table_findings tbody tr").each(function () {
-            var path = $(this).find("td.col-path").text().toLowerCase();
-            var commit = $(this).find("td.col-commit").text().toLowerCase();
-            var repository = $(this).find("td.col-repository").text().toLowerCase();
-            if (path.indexOf(needle) > -1 || commit.indexOf(needle) > -1 || repository.indexOf(needle) > -1) {
-                $(this).removeClass("d-none");
-            } else {
-                $(this).addClass("d-none");
-            }
-        });
     }
 });
 window.findingsView = new FindingsView({el: "#table_findings tbody"});
 
+var Risk = Backbone.Model.extend({
+    url: "/risk",
+    defaults: {
+        "score": 0,
+        "organizations": [],
+        "repositories": [],
+    },
+});
+window.risk = new Risk;
+
+var RiskView = Backbone.View.extend({
+    model: risk,
+    maxRepositories: 10,
+    initialize: function () {
+        this.listenTo(this.model, "change", this.render);
+        this.listenTo(stats, "change:Findings", _.debounce(this.update, 500));
+    },
+    update: function () {
+        risk.fetch();
+    },
+    render: function () {
+        $("#card_risk_value").text(this.model.get("score"));
+        var repositories = this.model.get("repositories");
+        this.$el.empty();
+        _.each(_.first(repositories, this.maxRepositories), function (repository) {
+            var row = $("<tr>");
+            $("<td>").addClass("col-risk").text(repository.score).appendTo(row);
+            $("<td>").addClass("col-repository").text(repository.owner + "/" + repository.name).appendTo(row);
+            $("<td>").addClass("col-findings").text(repository.findings).appendTo(row);
+            row.appendTo(this.$el);
+        }, this);
+        $("#page_risk").toggleClass("d-none", repositories.length === 0);
+    }
+});
+window.riskView = new RiskView({el: "#table_risk tbody"});
+
+var Secrets = Backbone.Collection.extend({
+    url: "/secrets",
+});
+window.secrets = new Secrets();
+
+var SecretsView = Backbone.View.extend({
+    collection: secrets,
+    maxSecrets: 10,
+    events: {
+        "click tr": "showFindings",
+    },
+    initialize: function () {
+        this.listenTo(this.collection, "reset", this.render);
+        this.listenTo(stats, "change:Findings", _.debounce(this.update, 500));
+        $("#secrets_clear").on("click", _.bind(this.clearFilter, this));
+    },
+    update: function () {
+        secrets.fetch({reset: true});
+    },
+    render: function () {
+        // a secret found only once is already a single row in the findings
+        var repeated = this.collection.filter(function (secret) {
+            return secret.get("occurrences") > 1;
+        });
+        this.$el.empty();
+        _.each(_.first(repeated, this.maxSecrets), function (secret) {
+            var row = $("<tr>").attr("data-secret", secret.get("secret_hash"));
+            $("<td>").addClass("col-severity").text(secret.get("severity")).appendTo(row);
+            $("<td>").addClass("col-signature").text(secret.get("description")).appendTo(row);
+            $("<td>").addClass("col-secret").append($("<code>").text(secret.get("secret"))).appendTo(row);
+            $("<td>").addClass("col-findings").text(secret.get("occurrences")).appendTo(row);
+            $("<td>").addClass("col-repository").text(secret.get("repositories").join(", ")).appendTo(row);
+            row.toggleClass("table-selected", findings.filters.secret === secret.get("secret_hash"));
+            row.appendTo(this.$el);
+        }, this);
+        $("#page_secrets").toggleClass("d-none", repeated.length === 0);
+    },
+    showFindings: function (e) {
+        this.filter($(e.currentTarget).data("secret"));
+    },
+    clearFilter: function (e) {
+        e.preventDefault();
+        this.filter("");
+    },
+    filter: function (secret) {
+        findings.filters = _.extend({}, findings.filters, {secret: secret});
+        findings.offset = 0;
+        findings.fetchPage();
+        $("#secrets_clear").toggleClass("d-none", secret === "");
+        this.render();
+    }
+});
+window.secretsView = new SecretsView({el: "#table_secrets tbody"});
+
 var FindingModal = Backbone.View.extend({
     template: _.template($("#template_finding_modal").html()),
     interestingStringPatterns: [
@@ -312,17 +542,32 @@ var FindingModal = Backbone.View.extend({
         /(cred(s|ential))/gmi,
         /(access(_|-|.)?token)/gmi,
     ],
+    triageTemplate: _.template($("#template_finding_triage").html()),
     events: {
         "click #finding_view_raw": "showRawContents",
         "click #finding_view_hexdump": "showHexDumpContents",
+        "click .finding-triage": "triage",
     },
     render: function () {
         this.$el.html(this.template(this.model.attributes));
+        this.renderTriage();
         new ClipboardJS('.btn', {
             container: document.getElementById('finding_modal')
         });
         return this;
     },
+    renderTriage: function () {
+        $("#finding_triage").html(this.triageTemplate(this.model.attributes));
+    },
+    triage: function (e) {
+        var status = $(e.currentTarget).attr("data-status");
+        $(".finding-triage").prop("disabled", true);
+        this.model.triage(status, $("#finding_triage_note").val(), _.bind(this.renderTriage, this), function (xhr) {
+            $(".finding-triage").prop("disabled", false);
+            var message = xhr.responseJSON ? xhr.responseJSON.message : xhr.statusText;
+            $("#finding_triage").append($("<div class='text-danger'>").text("Unable to triage: " + message));
+        });
+    },
     showRawContents: function () {
         $("#finding_view_raw").addClass("active");
         $("#finding_view_hexdump").removeClass("active");
@@ -337,6 +582,8 @@ var FindingModal = Backbone.View.extend({
     },
     getHostName: function () {
         if (this.model.get("CommitUrl").indexOf("github") !== -1) return "Github";
+        if (this.model.get("CommitUrl").indexOf("bitbucket") !== -1) return "Bitbucket";
+        if (this.model.get("CommitUrl").indexOf("/_git/") !== -1) return "Azure DevOps";
         return "GitLab";
     },
     truncatedCommitMessage: function () {
@@ -346,6 +593,16 @@ var FindingModal = Backbone.View.extend({
         }
         return _.escape(message.substr(0, 150)) + "…";
     },
+    formattedContext: function () {
+        var matched = parseInt(this.model.get("LineNumber"), 10);
+        var width = String(_.last(this.model.get("Context")).LineNumber).length;
+        return _.map(this.model.get("Context"), function (line) {
+            var number = String(line.LineNumber);
+            while (number.length < width) number = " " + number;
+            var text = _.escape(number + "  " + line.Content);
+            return line.LineNumber === matched ? "<mark>" + text + "</mark>" : text;
+        }).join("\n");
+    },
     isTestRelated: function () {
         return this.model.isTestRelated();
     },
@@ -412,3 +669,108 @@ var FindingModal = Backbone.View.extend({
         });
     }
 });
+
+var Trends = Backbone.Model.extend({
+    url: "/trends",
+    defaults: {
+        "weeks": [],
+        "open": 0,
+        "resolved": 0,
+        "mean_time_to_remediation_hours": 0,
+        "scans": [],
+    },
+});
+window.trends = new Trends;
+
+var TrendsView = Backbone.View.extend({
+    model: trends,
+    chartHeight: 140,
+    initialize: function () {
+        this.listenTo(this.model, "sync", this.render);
+        this.listenTo(stats, "change:Status", this.update);
+        this.update();
+    },
+    update: function () {
+        // the history only changes when a scan finishes, and is not available at all unless it has been enabled
+        if (stats.get("Status") === "initializing" || stats.isFinished()) {
+            trends.fetch();
+        }
+    },
+    render: function () {
+        var weeks = this.model.get("weeks");
+        var svg = this.$el.get(0);
+        var height = this.chartHeight;
+        var max = _.max(_.map(weeks, function (week) {
+            return Math.max(week.new, week.resolved);
+        }).concat([1]));
+        var width = 100 / weeks.length;
+
+        $(svg).empty();
+        _.each(weeks, function (week, i) {
+            _.each(["new", "resolved"], function (kind, j) {
+                var bar = document.createElementNS("http://www.w3.org/2000/svg", "rect");
+                var barHeight = week[kind] / max * height;
+                bar.setAttribute("class", "bar-" + kind);
+                bar.setAttribute("x", (i * width + j * width * 0.4 + width * 0.1) + "%");
+                bar.setAttribute("y", height - barHeight);
+                bar.setAttribute("width", (width * 0.4) + "%");
+                bar.setAttribute("height", barHeight);
+                svg.appendChild(bar);
+            });
+            var label = document.createElementNS("http://www.w3.org/2000/svg", "text");
+            label.setAttribute("x", (i * width + width * 0.1) + "%");
+            label.setAttribute("y", height + 15);
+            label.textContent = week.start.substr(5, 5);
+            svg.appendChild(label);
+        });
+
+        var mttr = this.model.get("mean_time_to_remediation_hours");
+        $("#trends_summary").text(this.model.get("open") + " open, " + this.model.get("resolved") +
+            " resolved, " + (mttr / 24).toFixed(1) + " days to remediate on average");
+        $("#page_trends").removeClass("d-none");
+    }
+});
+window.trendsView = new TrendsView({el: "#chart_trends"});
+
+var Sessions = Backbone.Collection.extend({
+    url: "/sessions",
+});
+window.sessions = new Sessions();
+
+var SessionsView = Backbone.View.extend({
+    collection: sessions,
+    events: {
+        "change": "selectSession",
+    },
+    initialize: function () {
+        this.listenTo(this.collection, "sync", this.render);
+        this.listenTo(stats, "change:Status", this.update);
+        this.update();
+    },
+    update: function () {
+        // sessions are only kept when a database has been configured, otherwise this is a 404 and stays hidden
+        if (stats.get("Status") === "initializing" || stats.isFinished()) {
+            sessions.fetch();
+        }
+    },
+    render: function () {
+        var select = this.$el;
+        var selected = select.val();
+        select.find("option[value!='']").remove();
+        this.collection.each(function (session) {
+            var label = "#" + session.get("id") + " " + session.get("scan_type") + " " +
+                session.get("started_at").substr(0, 16).replace("T", " ") + " (" + session.get("findings") + ")";
+            $("<option>").val(session.get("id")).text(label).appendTo(select);
+        });
+        select.val(selected);
+        select.removeClass("d-none");
+    },
+    selectSession: function () {
+        var id = this.$el.val();
+        findings.url = id === "" ? "/findings" : "/sessions/" + id + "/findings";
+        // the repositories and signatures of one session may not be in another
+        $("#findings_repository, #findings_signature").val("");
+        findingsView.filterFindings();
+    }
+});
+window.sessionsView = new SessionsView({el: "#findings_session"});